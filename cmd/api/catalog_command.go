@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"smart-hub/internal/application/service"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/infrastructure/catalogfile"
+)
+
+// command is a one-shot task that runs against the configured database
+// instead of starting the server, e.g. "smart-hub import catalog.csv".
+type command struct {
+	run func(ctx context.Context, a *App) error
+	// ownDatabase commands run before the database is set up, and so before
+	// it is migrated, and connect by themselves.
+	ownDatabase bool
+}
+
+func parseCommand(args []string) (*command, error) {
+	switch args[0] {
+	case "import":
+		return parseImportCommand(args[1:])
+	case "export":
+		return parseExportCommand(args[1:])
+	case "migrate":
+		return parseMigrateCommand(args[1:], os.Stdout)
+	default:
+		return nil, fmt.Errorf("unknown command %q, expected import, export or migrate", args[0])
+	}
+}
+
+func parseImportCommand(args []string) (*command, error) {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "", "catalog file to import, - for stdin")
+	format := flags.String("format", "", "json, yaml or csv; inferred from the file extension by default")
+	dryRun := flags.Bool("dry-run", false, "validate and report without writing anything")
+	batchSize := flags.Int("batch-size", 0, "rows per transaction; 0 imports everything in one transaction")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if *file == "" {
+		return nil, errors.New("import: -file is required")
+	}
+	catalogFormat, err := resolveFormat(*format, *file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &command{run: func(ctx context.Context, a *App) error {
+		var r io.Reader = os.Stdin
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+
+		entries, err := catalogfile.Decode(r, catalogFormat)
+		if err != nil {
+			return err
+		}
+
+		catalogService := service.NewCatalogService(a.modelRepo, a.featureRepo, a.uow, a.outbox)
+		report, err := catalogService.Import(ctx, entries, models.ImportOptions{DryRun: *dryRun, BatchSize: *batchSize})
+		if err != nil {
+			return err
+		}
+
+		printImportReport(os.Stdout, report)
+		if report.Failed > 0 || report.RolledBack > 0 {
+			return fmt.Errorf("%d of %d rows were not imported", report.Failed+report.RolledBack, len(report.Rows))
+		}
+		return nil
+	}}, nil
+}
+
+func parseExportCommand(args []string) (*command, error) {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	file := flags.String("file", "-", "file to write, - for stdout")
+	format := flags.String("format", "", "json, yaml or csv; inferred from the file extension, json for stdout")
+	manufacturer := flags.String("manufacturer", "", "only export models of this manufacturer")
+	modelType := flags.String("type", "", "only export models of this type")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if *format == "" && *file == "-" {
+		*format = string(catalogfile.JSON)
+	}
+	catalogFormat, err := resolveFormat(*format, *file)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter models.ExportFilter
+	if *manufacturer != "" {
+		filter.Manufacturer = manufacturer
+	}
+	if *modelType != "" {
+		t := models.ModelType(*modelType)
+		filter.Type = &t
+	}
+
+	return &command{run: func(ctx context.Context, a *App) error {
+		var entries []*models.CatalogEntry
+		catalogService := service.NewCatalogService(a.modelRepo, a.featureRepo, a.uow, a.outbox)
+		err := catalogService.Export(ctx, filter, func(entry *models.CatalogEntry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if *file == "-" {
+			return catalogfile.Encode(os.Stdout, catalogFormat, entries)
+		}
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		if err := catalogfile.Encode(f, catalogFormat, entries); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}}, nil
+}
+
+func resolveFormat(format, file string) (catalogfile.Format, error) {
+	if format != "" {
+		return catalogfile.ParseFormat(format)
+	}
+	if file == "-" {
+		return "", errors.New("-format is required when reading from stdin")
+	}
+	return catalogfile.FormatFromPath(file)
+}
+
+func printImportReport(w io.Writer, report *models.ImportReport) {
+	if report.DryRun {
+		fmt.Fprintln(w, "Dry run, nothing was written.")
+	}
+	for _, row := range report.Rows {
+		if row.Status == models.ImportCreated || row.Status == models.ImportUpdated {
+			continue
+		}
+		fmt.Fprintf(w, "row %d: %s\n", row.Row, row.Status)
+		for _, message := range row.Errors {
+			fmt.Fprintf(w, "  %s\n", message)
+		}
+	}
+	fmt.Fprintf(w, "created: %d, updated: %d, failed: %d, rolled back: %d\n",
+		report.Created, report.Updated, report.Failed, report.RolledBack)
+}
@@ -4,33 +4,100 @@ import (
 	"context"
 	"fmt"
 	"github.com/kelseyhightower/envconfig"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"net"
 	"os"
 	"os/signal"
 	"smart-hub/config"
+	pbAutomation "smart-hub/gen/proto/automation/v1"
+	pbCapability "smart-hub/gen/proto/capability/v1"
+	pbCatalog "smart-hub/gen/proto/catalog/v1"
 	pbHealth "smart-hub/gen/proto/health/v1"
+	pbRelationship "smart-hub/gen/proto/relationship/v1"
+	pbScene "smart-hub/gen/proto/scene/v1"
+	pbSchedule "smart-hub/gen/proto/schedule/v1"
+	pbShadow "smart-hub/gen/proto/shadow/v1"
 	pbFeature "smart-hub/gen/proto/smart_feature/v1"
 	pbModel "smart-hub/gen/proto/smart_model/v1"
+	pbTelemetry "smart-hub/gen/proto/telemetry/v1"
+	pbWebhook "smart-hub/gen/proto/webhook/v1"
 	"smart-hub/internal/application/service"
 	"smart-hub/internal/common/database"
 	"smart-hub/internal/common/database/migrations"
 	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/infrastructure/cache"
+	"smart-hub/internal/infrastructure/database/memory"
 	"smart-hub/internal/infrastructure/database/postgres"
+	"smart-hub/internal/infrastructure/database/sqlite"
+	"smart-hub/internal/infrastructure/messaging"
 	"smart-hub/internal/presentation/grpc/handler"
+	"smart-hub/internal/presentation/grpc/interceptor"
 	"smart-hub/internal/presentation/grpc/mapper"
 	"syscall"
+	"time"
 )
 
+// sqliteChangePollInterval is how often the SQLite backend looks for catalog
+// changes to wake up watchers.
+const sqliteChangePollInterval = time.Second
+
+// storage is the backend selected by DATABASE_DRIVER.
+type storage interface {
+	Ping(ctx context.Context) error
+	Close()
+}
+
 type App struct {
-	cfg        config.Config
-	grpcServer *grpc.Server
-	db         database.Database
+	cfg              config.Config
+	grpcServer       *grpc.Server
+	db               storage
+	tracerShutdown   tracing.ShutdownFunc
+	meterShutdown    tracing.ShutdownFunc
+	uow              interfaces.UnitOfWork
+	outbox           interfaces.OutboxRepository
+	modelRepo        interfaces.SmartModelRepository
+	featureRepo      interfaces.SmartFeatureRepository
+	webhookRepo      interfaces.WebhookRepository
+	telemetryRepo    interfaces.TelemetryRepository
+	shadowRepo       interfaces.ShadowRepository
+	automationRepo   interfaces.AutomationRepository
+	scheduleRepo     interfaces.ScheduleRepository
+	sceneRepo        interfaces.SceneRepository
+	relationshipRepo interfaces.RelationshipRepository
+	dependencyRepo   interfaces.FeatureDependencyRepository
+	capabilityRepo   interfaces.FeatureCapabilityRepository
+	changes          interfaces.CatalogChangeRepository
+	changeListener   interfaces.ChangeListener
+	// shadowListener is only set for Postgres. The other backends have a
+	// single writer, and the shadow service wakes its watchers itself.
+	shadowListener interfaces.ChangeListener
+	// scheduleElector is only set for Postgres, the only backend that can
+	// be shared by several replicas.
+	scheduleElector interfaces.LeaderElector
+	publisher       interfaces.EventPublisher
+	cacheStore      cache.Store
+	stopCache       context.CancelFunc
+	stopRelay       context.CancelFunc
+	watcher         *service.CatalogWatchService
+	stopWatcher     context.CancelFunc
+	stopRetention   context.CancelFunc
+	stopShadows     context.CancelFunc
+	stopAutomation  context.CancelFunc
+	stopSchedules   context.CancelFunc
+	telemetry       *service.TelemetryService
+	shadows         *service.ShadowService
 }
 
 func NewApp() *App {
 	return &App{
-		grpcServer: grpc.NewServer(),
+		grpcServer: grpc.NewServer(
+			grpc.StatsHandler(otelgrpc.NewServerHandler()),
+			grpc.ChainUnaryInterceptor(interceptor.UnaryLogging(), interceptor.UnaryReadConsistency()),
+			grpc.ChainStreamInterceptor(interceptor.StreamLogging(), interceptor.StreamReadConsistency()),
+		),
 	}
 }
 
@@ -42,37 +109,345 @@ func (a *App) loggerSetup() error {
 	return logger.InitLogger(&a.cfg.Log)
 }
 
+func (a *App) tracingSetup(ctx context.Context) error {
+	shutdown, err := tracing.InitTracer(ctx, &a.cfg.Tracing, a.cfg.Service.Name)
+	if err != nil {
+		return err
+	}
+	a.tracerShutdown = shutdown
+	return nil
+}
+
+func (a *App) metricsSetup(ctx context.Context) error {
+	shutdown, err := tracing.InitMeter(ctx, &a.cfg.Metrics, a.cfg.Service.Name)
+	if err != nil {
+		return err
+	}
+	a.meterShutdown = shutdown
+	return nil
+}
+
 func (a *App) databaseSetup(ctx context.Context) error {
+	if err := a.cfg.Database.Validate(); err != nil {
+		return err
+	}
+
+	switch a.cfg.Database.Driver {
+	case config.MemoryDriver:
+		a.memorySetup()
+		return nil
+	case config.SQLiteDriver:
+		return a.sqliteSetup(ctx)
+	default:
+		return a.postgresSetup(ctx)
+	}
+}
+
+func (a *App) postgresSetup(ctx context.Context) error {
 	// Migrate database
-	if err := migrations.RunMigrations(a.cfg.Database.GetURL()); err != nil {
-		return fmt.Errorf("database migrations error: %w", err)
+	if a.cfg.Database.AutoMigrate {
+		if err := migrations.RunMigrations(ctx, a.cfg.Database.GetDSN()); err != nil {
+			return fmt.Errorf("database migrations error: %w", err)
+		}
 	}
 
 	// Connect to database
-	db, err := database.NewPostgresDatabase(ctx, &database.PostgreConfig{DSN: a.cfg.Database.GetDSN()})
+	db, err := database.NewPostgresDatabase(ctx, &database.PostgreConfig{
+		DSN:                  a.cfg.Database.GetDSN(),
+		ReplicaDSNs:          a.cfg.Database.GetReplicaDSNs(),
+		MaxReplicaLag:        a.cfg.Database.MaxReplicaLag,
+		ReplicaCheckInterval: a.cfg.Database.ReplicaCheckInterval,
+	})
 	if err != nil {
 		return fmt.Errorf("database connection error: %w", err)
 	}
 	a.db = db
+	a.uow = postgres.NewPGUnitOfWork(db)
+	a.outbox = postgres.NewPGOutboxRepository(db)
+	a.modelRepo = postgres.NewPGSmartModelRepository(db)
+	a.featureRepo = postgres.NewPGSmartFeatureRepository(db)
+	a.webhookRepo = postgres.NewPGWebhookRepository(db)
+	a.telemetryRepo = postgres.NewPGTelemetryRepository(db)
+	a.shadowRepo = postgres.NewPGShadowRepository(db)
+	a.automationRepo = postgres.NewPGAutomationRepository(db)
+	a.scheduleRepo = postgres.NewPGScheduleRepository(db)
+	a.sceneRepo = postgres.NewPGSceneRepository(db)
+	a.relationshipRepo = postgres.NewPGRelationshipRepository(db)
+	a.dependencyRepo = postgres.NewPGFeatureDependencyRepository(db)
+	a.capabilityRepo = postgres.NewPGFeatureCapabilityRepository(db)
+	a.changes = postgres.NewPGCatalogChangeRepository(db)
+	a.changeListener = postgres.NewPGChangeListener(a.cfg.Database.GetDSN())
+	a.shadowListener = postgres.NewPGShadowDeltaListener(a.cfg.Database.GetDSN())
+	a.scheduleElector = postgres.NewPGSchedulerElector(a.cfg.Database.GetDSN())
 	return nil
 }
 
+func (a *App) sqliteSetup(ctx context.Context) error {
+	// Migrate database
+	if a.cfg.Database.AutoMigrate {
+		if err := migrations.RunSQLiteMigrations(a.cfg.Database.Path); err != nil {
+			return fmt.Errorf("database migrations error: %w", err)
+		}
+	}
+
+	// Open database
+	db, err := database.NewSQLiteDatabase(ctx, &database.SQLiteConfig{Path: a.cfg.Database.Path})
+	if err != nil {
+		return fmt.Errorf("database connection error: %w", err)
+	}
+	a.db = db
+	a.uow = sqlite.NewSQLiteUnitOfWork(db)
+	a.outbox = sqlite.NewSQLiteOutboxRepository(db)
+	a.modelRepo = sqlite.NewSQLiteSmartModelRepository(db)
+	a.featureRepo = sqlite.NewSQLiteSmartFeatureRepository(db)
+	a.webhookRepo = sqlite.NewSQLiteWebhookRepository(db)
+	a.telemetryRepo = sqlite.NewSQLiteTelemetryRepository(db)
+	a.shadowRepo = sqlite.NewSQLiteShadowRepository(db)
+	a.automationRepo = sqlite.NewSQLiteAutomationRepository(db)
+	a.scheduleRepo = sqlite.NewSQLiteScheduleRepository(db)
+	a.sceneRepo = sqlite.NewSQLiteSceneRepository(db)
+	a.relationshipRepo = sqlite.NewSQLiteRelationshipRepository(db)
+	a.dependencyRepo = sqlite.NewSQLiteFeatureDependencyRepository(db)
+	a.capabilityRepo = sqlite.NewSQLiteFeatureCapabilityRepository(db)
+	a.changes = sqlite.NewSQLiteCatalogChangeRepository(db)
+	a.changeListener = sqlite.NewSQLiteChangeListener(db, sqliteChangePollInterval)
+	return nil
+}
+
+func (a *App) memorySetup() {
+	logger.Warn("Using the in-memory database; data is lost on restart")
+
+	store := memory.NewStore()
+	a.db = store
+	a.uow = memory.NewMemUnitOfWork(store)
+	a.outbox = memory.NewMemOutboxRepository(store)
+	a.modelRepo = memory.NewMemSmartModelRepository(store)
+	a.featureRepo = memory.NewMemSmartFeatureRepository(store)
+	a.webhookRepo = memory.NewMemWebhookRepository(store)
+	a.telemetryRepo = memory.NewMemTelemetryRepository(store)
+	a.shadowRepo = memory.NewMemShadowRepository(store)
+	a.automationRepo = memory.NewMemAutomationRepository(store)
+	a.scheduleRepo = memory.NewMemScheduleRepository(store)
+	a.sceneRepo = memory.NewMemSceneRepository(store)
+	a.relationshipRepo = memory.NewMemRelationshipRepository(store)
+	a.dependencyRepo = memory.NewMemFeatureDependencyRepository(store)
+	a.capabilityRepo = memory.NewMemFeatureCapabilityRepository(store)
+	a.changes = memory.NewMemCatalogChangeRepository(store)
+	a.changeListener = memory.NewMemChangeListener(store)
+}
+
+func (a *App) eventsSetup(ctx context.Context) error {
+	sink, err := messaging.NewPublisher(&a.cfg.Events)
+	if err != nil {
+		return err
+	}
+	if channel, ok := sink.(*messaging.ChannelPublisher); ok && channel.Subscribers() == 0 {
+		logger.Warn("The channel event sink has no subscribers; domain events only reach webhook subscriptions", "sink", a.cfg.Events.Sink)
+	}
+
+	webhooks := a.cfg.Webhooks
+	dispatcher := service.NewWebhookDispatcher(
+		a.webhookRepo,
+		messaging.NewHTTPWebhookSender(webhooks.Timeout),
+		webhooks.DispatchInterval,
+		webhooks.BatchSize,
+		webhooks.Timeout,
+		webhooks.MaxAttempts,
+		webhooks.InitialBackoff,
+		webhooks.MaxBackoff,
+	)
+	a.publisher = messaging.NewMultiPublisher(sink, dispatcher)
+
+	relayCtx, cancel := context.WithCancel(ctx)
+	a.stopRelay = cancel
+
+	events := a.cfg.Events
+	relay := service.NewOutboxRelay(a.uow, a.outbox, a.publisher,
+		events.RelayInterval, events.RelayBatchSize, events.RelayMaxAttempts, events.RelayLease)
+	go relay.Run(relayCtx)
+	go dispatcher.Run(relayCtx)
+
+	logger.Info("Outbox relay started", "sink", a.cfg.Events.Sink)
+	return nil
+}
+
+// cacheSetup puts the cache selected by CACHE_BACKEND in front of the model
+// and feature repositories. Every replica follows the catalog change log to
+// drop entries that other replicas made stale.
+func (a *App) cacheSetup(ctx context.Context) error {
+	store, err := cache.NewStore(&a.cfg.Cache)
+	if err != nil || store == nil {
+		return err
+	}
+	a.cacheStore = store
+	a.uow = cache.NewCachedUnitOfWork(a.uow, store)
+	a.modelRepo = cache.NewCachedSmartModelRepository(a.modelRepo, store)
+	a.featureRepo = cache.NewCachedSmartFeatureRepository(a.featureRepo, store)
+
+	cacheCtx, cancel := context.WithCancel(ctx)
+	a.stopCache = cancel
+	invalidator := cache.NewInvalidator(store, a.changes, a.changeListener, a.cfg.Watch.PollInterval)
+	go invalidator.Run(cacheCtx)
+
+	logger.Info("Catalog cache enabled", "backend", a.cfg.Cache.Backend, "ttl", a.cfg.Cache.TTL.String())
+	return nil
+}
+
+func (a *App) watchSetup(ctx context.Context) {
+	a.watcher = service.NewCatalogWatchService(
+		a.changes,
+		a.changeListener,
+		a.modelRepo,
+		a.featureRepo,
+		a.cfg.Watch.BufferSize,
+		a.cfg.Watch.PollInterval,
+		a.cfg.Watch.Retention,
+	)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	a.stopWatcher = cancel
+	go a.watcher.Run(watchCtx)
+}
+
 func (a *App) smartFeatureSetup() {
-	smartFeatureRepo := postgres.NewPGSmartFeatureRepository(a.db)
-	smartFeatureService := service.NewSmartFeatureService(smartFeatureRepo)
+	smartFeatureService := service.NewSmartFeatureService(a.featureRepo, a.modelRepo, a.uow, a.outbox, a.dependencyRepo)
 	smartFeatureMapper := mapper.NewSmartFeatureMapper()
-	smartFeatureHandler := handler.NewSmartFeatureHandler(smartFeatureService, smartFeatureMapper)
+	smartFeatureHandler := handler.NewSmartFeatureHandler(smartFeatureService, a.watcher, smartFeatureMapper)
 	pbFeature.RegisterSmartFeatureServiceServer(a.grpcServer, smartFeatureHandler)
 }
 
 func (a *App) smartModelSetup() {
-	smartModelRepo := postgres.NewPGSmartModelRepository(a.db)
-	smartModelService := service.NewSmartModelService(smartModelRepo)
+	smartModelService := service.NewSmartModelService(a.modelRepo, a.featureRepo, a.uow, a.outbox)
 	smartModelMapper := mapper.NewSmartModelMapper()
-	smartModelHandler := handler.NewSmartModelHandler(smartModelService, smartModelMapper)
+	smartModelHandler := handler.NewSmartModelHandler(smartModelService, a.watcher, smartModelMapper)
 	pbModel.RegisterSmartModelServiceServer(a.grpcServer, smartModelHandler)
 }
 
+func (a *App) webhookSetup() {
+	webhookService := service.NewWebhookService(a.webhookRepo)
+	webhookMapper := mapper.NewWebhookMapper()
+	webhookHandler := handler.NewWebhookHandler(webhookService, webhookMapper)
+	pbWebhook.RegisterWebhookServiceServer(a.grpcServer, webhookHandler)
+}
+
+func (a *App) telemetrySetup(ctx context.Context) {
+	telemetry := a.cfg.Telemetry
+	telemetryService := service.NewTelemetryService(
+		a.telemetryRepo,
+		a.featureRepo,
+		telemetry.Retention,
+		telemetry.MaxPoints,
+		telemetry.IngestBatchSize,
+	)
+	telemetryMapper := mapper.NewTelemetryMapper()
+	telemetryHandler := handler.NewTelemetryHandler(telemetryService, telemetryMapper)
+	pbTelemetry.RegisterTelemetryServiceServer(a.grpcServer, telemetryHandler)
+	a.telemetry = telemetryService
+
+	retentionCtx, cancel := context.WithCancel(ctx)
+	a.stopRetention = cancel
+	go telemetryService.RunRetention(retentionCtx, telemetry.RetentionInterval)
+}
+
+func (a *App) shadowSetup(ctx context.Context) {
+	shadowService := service.NewShadowService(
+		a.shadowRepo,
+		a.featureRepo,
+		a.uow,
+		a.shadowListener,
+		a.cfg.Watch.BufferSize,
+		a.cfg.Watch.PollInterval,
+		a.cfg.Watch.Retention,
+	)
+	shadowMapper := mapper.NewShadowMapper()
+	shadowHandler := handler.NewShadowHandler(shadowService, shadowMapper)
+	pbShadow.RegisterDeviceShadowServiceServer(a.grpcServer, shadowHandler)
+	a.shadows = shadowService
+
+	shadowCtx, cancel := context.WithCancel(ctx)
+	a.stopShadows = cancel
+	go shadowService.Run(shadowCtx)
+}
+
+// automationSetup must run after telemetrySetup and shadowSetup: the engine
+// observes both services and invokes features through the shadows.
+func (a *App) automationSetup(ctx context.Context) {
+	automation := a.cfg.Automation
+	engine := service.NewAutomationEngine(
+		a.automationRepo,
+		a.shadowRepo,
+		a.shadows,
+		automation.QueueSize,
+		automation.RefreshInterval,
+		automation.ActionTimeout,
+		automation.ExecutionRetention,
+	)
+	a.telemetry.AddObserver(engine)
+	a.shadows.AddObserver(engine)
+
+	automationService := service.NewAutomationService(a.automationRepo, a.featureRepo, engine)
+	automationMapper := mapper.NewAutomationMapper()
+	automationHandler := handler.NewAutomationHandler(automationService, automationMapper)
+	pbAutomation.RegisterAutomationServiceServer(a.grpcServer, automationHandler)
+
+	automationCtx, cancel := context.WithCancel(ctx)
+	a.stopAutomation = cancel
+	go engine.Run(automationCtx)
+}
+
+// scheduleSetup must run after shadowSetup: schedules invoke features
+// through the shadows.
+func (a *App) scheduleSetup(ctx context.Context) {
+	scheduler := a.cfg.Scheduler
+	scheduleService := service.NewScheduleService(
+		a.scheduleRepo,
+		a.featureRepo,
+		a.uow,
+		a.shadows,
+		a.scheduleElector,
+		scheduler.TickInterval,
+		scheduler.RunTimeout,
+		scheduler.RunRetention,
+	)
+	scheduleMapper := mapper.NewScheduleMapper()
+	scheduleHandler := handler.NewScheduleHandler(scheduleService, scheduleMapper)
+	pbSchedule.RegisterScheduleServiceServer(a.grpcServer, scheduleHandler)
+
+	scheduleCtx, cancel := context.WithCancel(ctx)
+	a.stopSchedules = cancel
+	go scheduleService.Run(scheduleCtx)
+}
+
+// sceneSetup must run after shadowSetup: scene steps invoke features
+// through the shadows.
+func (a *App) sceneSetup() {
+	sceneService := service.NewSceneService(a.sceneRepo, a.featureRepo, a.shadows, a.cfg.Scenes.StepTimeout)
+	sceneMapper := mapper.NewSceneMapper()
+	sceneHandler := handler.NewSceneHandler(sceneService, sceneMapper)
+	pbScene.RegisterSceneServiceServer(a.grpcServer, sceneHandler)
+}
+
+func (a *App) relationshipSetup() {
+	relationshipService := service.NewRelationshipService(a.relationshipRepo, a.modelRepo, a.uow)
+	relationshipMapper := mapper.NewRelationshipMapper()
+	relationshipHandler := handler.NewRelationshipHandler(relationshipService, relationshipMapper)
+	pbRelationship.RegisterRelationshipServiceServer(a.grpcServer, relationshipHandler)
+}
+
+func (a *App) capabilitySetup() {
+	capabilityService := service.NewCapabilityService(a.capabilityRepo, a.featureRepo, a.modelRepo, a.uow)
+	capabilityMapper := mapper.NewCapabilityMapper()
+	capabilityHandler := handler.NewCapabilityHandler(capabilityService, capabilityMapper)
+	pbCapability.RegisterCapabilityServiceServer(a.grpcServer, capabilityHandler)
+}
+
+func (a *App) catalogSetup() {
+	catalogService := service.NewCatalogService(a.modelRepo, a.featureRepo, a.uow, a.outbox)
+	catalogMapper := mapper.NewCatalogMapper()
+	catalogHandler := handler.NewCatalogHandler(catalogService, catalogMapper)
+	pbCatalog.RegisterCatalogServiceServer(a.grpcServer, catalogHandler)
+}
+
 func (a *App) healthSetup() {
 	healthHandler := handler.NewHealthHandler(a.db)
 	pbHealth.RegisterHealthServer(a.grpcServer, healthHandler)
@@ -80,10 +455,52 @@ func (a *App) healthSetup() {
 
 func (a *App) shutdown() {
 	logger.Info("Shutting down server...")
+	if a.stopWatcher != nil {
+		// Open watches would otherwise hold GracefulStop forever.
+		a.stopWatcher()
+	}
+	if a.stopShadows != nil {
+		a.stopShadows()
+	}
 	a.grpcServer.GracefulStop()
+	if a.stopAutomation != nil {
+		a.stopAutomation()
+	}
+	if a.stopSchedules != nil {
+		a.stopSchedules()
+	}
+	if a.stopRetention != nil {
+		a.stopRetention()
+	}
+	if a.stopRelay != nil {
+		a.stopRelay()
+	}
+	if a.publisher != nil {
+		if err := a.publisher.Close(); err != nil {
+			logger.Error("Event publisher close error", err)
+		}
+	}
+	if a.stopCache != nil {
+		a.stopCache()
+	}
+	if a.cacheStore != nil {
+		if err := a.cacheStore.Close(); err != nil {
+			logger.Error("Cache close error", err)
+		}
+	}
 	if a.db != nil {
 		a.db.Close()
 	}
+	if a.tracerShutdown != nil {
+		if err := a.tracerShutdown(context.Background()); err != nil {
+			logger.Error("Tracer shutdown error", err)
+		}
+	}
+	if a.meterShutdown != nil {
+		if err := a.meterShutdown(context.Background()); err != nil {
+			logger.Error("Meter shutdown error", err)
+		}
+	}
 	logger.Info("Server stopped")
 }
 
@@ -103,15 +520,75 @@ func main() {
 		os.Exit(1)
 	}
 
+	var cmd *command
+	if len(os.Args) > 1 {
+		var err error
+		if cmd, err = parseCommand(os.Args[1:]); err != nil {
+			logger.Error("Command error", err)
+			os.Exit(2)
+		}
+	}
+
+	if err := app.tracingSetup(ctx); err != nil {
+		logger.Error("Tracing setup error", err)
+		os.Exit(1)
+	}
+
+	if err := app.metricsSetup(ctx); err != nil {
+		logger.Error("Metrics setup error", err)
+		app.shutdown()
+		os.Exit(1)
+	}
+
+	if cmd != nil && cmd.ownDatabase {
+		if err := cmd.run(ctx, app); err != nil {
+			logger.Error("Command failed", err)
+			app.shutdown()
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := app.databaseSetup(ctx); err != nil {
 		logger.Error("Database setup error", err)
 		os.Exit(1)
 	}
 
+	if cmd != nil {
+		// Events written by the command stay in the outbox until the
+		// server relays them.
+		if err := cmd.run(ctx, app); err != nil {
+			logger.Error("Command failed", err)
+			app.shutdown()
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := app.eventsSetup(ctx); err != nil {
+		logger.Error("Events setup error", err)
+		os.Exit(1)
+	}
+
+	if err := app.cacheSetup(ctx); err != nil {
+		logger.Error("Cache setup error", err)
+		os.Exit(1)
+	}
+
 	// Initialize modules
+	app.watchSetup(ctx)
 	app.healthSetup()
 	app.smartModelSetup()
 	app.smartFeatureSetup()
+	app.webhookSetup()
+	app.catalogSetup()
+	app.relationshipSetup()
+	app.capabilitySetup()
+	app.telemetrySetup(ctx)
+	app.shadowSetup(ctx)
+	app.automationSetup(ctx)
+	app.scheduleSetup(ctx)
+	app.sceneSetup()
 
 	// Start server
 	address := fmt.Sprintf(":%s", app.cfg.Service.Port)
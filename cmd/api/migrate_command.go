@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"smart-hub/config"
+	"smart-hub/internal/common/database/migrations"
+	"strconv"
+)
+
+const migrateUsage = "usage: migrate up | down [N] | goto VERSION | force VERSION | status"
+
+// parseMigrateCommand parses "migrate <action> [argument]". Migrations run
+// against the configured Postgres or SQLite database, whatever
+// DATABASE_AUTO_MIGRATE says, and the result is written to out.
+func parseMigrateCommand(args []string, out io.Writer) (*command, error) {
+	if len(args) == 0 {
+		return nil, errors.New(migrateUsage)
+	}
+
+	var action func(m *migrations.Migrator) error
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return nil, errors.New(migrateUsage)
+		}
+		action = (*migrations.Migrator).Up
+	case "down":
+		steps := 1
+		if len(args) > 2 {
+			return nil, errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("migrate down: %q is not a positive number of steps", args[1])
+			}
+			steps = n
+		}
+		action = func(m *migrations.Migrator) error { return m.Down(steps) }
+	case "goto":
+		if len(args) != 2 {
+			return nil, errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("migrate goto: invalid version %q", args[1])
+		}
+		action = func(m *migrations.Migrator) error { return m.Goto(uint(version)) }
+	case "force":
+		if len(args) != 2 {
+			return nil, errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < -1 {
+			return nil, fmt.Errorf("migrate force: invalid version %q", args[1])
+		}
+		action = func(m *migrations.Migrator) error { return m.Force(version) }
+	case "status":
+		if len(args) != 1 {
+			return nil, errors.New(migrateUsage)
+		}
+		action = func(m *migrations.Migrator) error {
+			status, err := m.Status()
+			if err != nil {
+				return err
+			}
+			printMigrationStatus(out, status)
+			return nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown migrate action %q; %s", args[0], migrateUsage)
+	}
+
+	return &command{ownDatabase: true, run: func(ctx context.Context, a *App) error {
+		m, err := newMigrator(ctx, a.cfg.Database)
+		if err != nil {
+			return err
+		}
+		err = action(m)
+		if closeErr := m.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		if args[0] != "status" {
+			fmt.Fprintln(out, "done")
+		}
+		return nil
+	}}, nil
+}
+
+func newMigrator(ctx context.Context, cfg config.DatabaseConfig) (*migrations.Migrator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Driver {
+	case config.SQLiteDriver:
+		return migrations.NewSQLiteMigrator(cfg.Path)
+	case config.PostgresDriver:
+		return migrations.NewPostgresMigrator(ctx, cfg.GetDSN())
+	default:
+		return nil, fmt.Errorf("the %s driver has no migrations", cfg.Driver)
+	}
+}
+
+func printMigrationStatus(w io.Writer, status *migrations.Status) {
+	version := "none"
+	if status.Version > 0 {
+		version = strconv.FormatUint(uint64(status.Version), 10)
+	}
+	fmt.Fprintf(w, "version: %s\n", version)
+	if status.Dirty {
+		fmt.Fprintln(w, "dirty: yes, the last migration failed halfway; fix the schema and run migrate force")
+	}
+	fmt.Fprintf(w, "latest: %d\n", status.Latest)
+	if len(status.Pending) == 0 {
+		fmt.Fprintln(w, "pending: none")
+		return
+	}
+	fmt.Fprintf(w, "pending: %d\n", len(status.Pending))
+	for _, version := range status.Pending {
+		fmt.Fprintf(w, "  %d\n", version)
+	}
+}
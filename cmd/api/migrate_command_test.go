@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"path/filepath"
+	"smart-hub/config"
+	"smart-hub/internal/common/database/migrations"
+	"testing"
+)
+
+func TestParseMigrateCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "up", args: []string{"up"}},
+		{name: "down", args: []string{"down"}},
+		{name: "down N", args: []string{"down", "3"}},
+		{name: "goto", args: []string{"goto", "7"}},
+		{name: "force", args: []string{"force", "7"}},
+		{name: "force no version", args: []string{"force", "-1"}},
+		{name: "status", args: []string{"status"}},
+		{name: "no action", args: nil, wantErr: migrateUsage},
+		{name: "unknown action", args: []string{"redo"}, wantErr: `unknown migrate action "redo"`},
+		{name: "up with argument", args: []string{"up", "2"}, wantErr: migrateUsage},
+		{name: "down zero", args: []string{"down", "0"}, wantErr: `"0" is not a positive number of steps`},
+		{name: "down negative", args: []string{"down", "-2"}, wantErr: `"-2" is not a positive number of steps`},
+		{name: "down not a number", args: []string{"down", "all"}, wantErr: `"all" is not a positive number of steps`},
+		{name: "down too many arguments", args: []string{"down", "1", "2"}, wantErr: migrateUsage},
+		{name: "goto without version", args: []string{"goto"}, wantErr: migrateUsage},
+		{name: "goto negative", args: []string{"goto", "-1"}, wantErr: `invalid version "-1"`},
+		{name: "force without version", args: []string{"force"}, wantErr: migrateUsage},
+		{name: "force below -1", args: []string{"force", "-2"}, wantErr: `invalid version "-2"`},
+		{name: "status with argument", args: []string{"status", "now"}, wantErr: migrateUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := parseMigrateCommand(tt.args, io.Discard)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Nil(t, cmd)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, cmd.ownDatabase)
+		})
+	}
+}
+
+func TestPrintMigrationStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status migrations.Status
+		want   string
+	}{
+		{
+			name:   "fresh database",
+			status: migrations.Status{Latest: 2, Pending: []uint{1, 2}},
+			want:   "version: none\nlatest: 2\npending: 2\n  1\n  2\n",
+		},
+		{
+			name:   "up to date",
+			status: migrations.Status{Version: 2, Latest: 2},
+			want:   "version: 2\nlatest: 2\npending: none\n",
+		},
+		{
+			name:   "dirty",
+			status: migrations.Status{Version: 1, Dirty: true, Latest: 2, Pending: []uint{2}},
+			want: "version: 1\n" +
+				"dirty: yes, the last migration failed halfway; fix the schema and run migrate force\n" +
+				"latest: 2\npending: 1\n  2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			printMigrationStatus(&out, &tt.status)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestMigrateCommand_SQLite(t *testing.T) {
+	app := &App{cfg: config.Config{Database: config.DatabaseConfig{
+		Driver: config.SQLiteDriver,
+		Path:   filepath.Join(t.TempDir(), "smart-hub.db"),
+	}}}
+
+	migrate := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd, err := parseMigrateCommand(args, &out)
+		require.NoError(t, err)
+		require.NoError(t, cmd.run(context.Background(), app))
+		return out.String()
+	}
+
+	assert.Contains(t, migrate("status"), "version: none\n")
+	assert.Equal(t, "done\n", migrate("up"))
+	assert.Contains(t, migrate("status"), "pending: none\n")
+
+	migrate("goto", "2")
+	assert.Contains(t, migrate("status"), "version: 2\n")
+
+	migrate("down")
+	assert.Contains(t, migrate("status"), "version: 1\n")
+
+	migrate("force", "3")
+	assert.Contains(t, migrate("status"), "version: 3\n")
+
+	migrate("force", "-1")
+	assert.Contains(t, migrate("status"), "version: none\n")
+}
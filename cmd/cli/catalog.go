@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"os"
+	pbCatalog "smart-hub/gen/proto/catalog/v1"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/infrastructure/catalogfile"
+	"smart-hub/internal/presentation/grpc/mapper"
+	"strings"
+)
+
+func importCommand() *command {
+	return &command{
+		name:  "import",
+		usage: "import FILE [-format F] [-dry-run] [-batch-size N]",
+		run:   runImport,
+	}
+}
+
+func exportCommand() *command {
+	return &command{
+		name:  "export",
+		usage: "export [-file FILE] [-format F] [-manufacturer M] [-type T]",
+		run:   runExport,
+	}
+}
+
+// resolveCatalogFormat is the -format of import and export, inferred from
+// the file extension when unset.
+func resolveCatalogFormat(format, file string) (catalogfile.Format, error) {
+	if format != "" {
+		return catalogfile.ParseFormat(format)
+	}
+	if file == "-" {
+		return "", errors.New("-format is required when reading from stdin")
+	}
+	return catalogfile.FormatFromPath(file)
+}
+
+// runImport streams a catalog file to ImportCatalog. It fails when any row
+// was not imported, after printing the report.
+func runImport(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("import")
+	format := flags.String("format", "", "json, yaml or csv; inferred from the file extension by default")
+	dryRun := flags.Bool("dry-run", false, "validate and report without writing anything")
+	batchSize := flags.Int("batch-size", 0, "rows per transaction; 0 imports everything in one transaction")
+	positional, err := parseFlags(flags, args, 1, "FILE (- for stdin)")
+	if err != nil {
+		return err
+	}
+	file := positional[0]
+	catalogFormat, err := resolveCatalogFormat(*format, file)
+	if err != nil {
+		return &usageError{err: err}
+	}
+
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	entries, err := catalogfile.Decode(r, catalogFormat)
+	if err != nil {
+		return err
+	}
+
+	stream, err := pbCatalog.NewCatalogServiceClient(c.conn).ImportCatalog(ctx)
+	if err != nil {
+		return err
+	}
+	err = stream.Send(&pbCatalog.ImportCatalogRequest{Item: &pbCatalog.ImportCatalogRequest_Options{
+		Options: &pbCatalog.ImportOptions{DryRun: *dryRun, BatchSize: int32(*batchSize)},
+	}})
+	if err != nil {
+		return importSendError(stream, err)
+	}
+	catalogMapper := mapper.NewCatalogMapper()
+	for _, entry := range entries {
+		model, err := catalogMapper.ToProto(entry)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&pbCatalog.ImportCatalogRequest{Item: &pbCatalog.ImportCatalogRequest_Model{Model: model}}); err != nil {
+			return importSendError(stream, err)
+		}
+	}
+	report, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+
+	if c.output == tableOutput {
+		printImportReport(c.out, report)
+	} else if err := c.print(report, nil); err != nil {
+		return err
+	}
+	if report.Failed > 0 || report.RolledBack > 0 {
+		return fmt.Errorf("%d of %d rows were not imported", report.Failed+report.RolledBack, len(report.Rows))
+	}
+	return nil
+}
+
+// importSendError returns the status the server ended the stream with when
+// a send fails with io.EOF.
+func importSendError(stream pbCatalog.CatalogService_ImportCatalogClient, err error) error {
+	if errors.Is(err, io.EOF) {
+		_, err = stream.CloseAndRecv()
+	}
+	return err
+}
+
+func printImportReport(w io.Writer, report *pbCatalog.ImportCatalogResponse) {
+	if report.DryRun {
+		fmt.Fprintln(w, "Dry run, nothing was written.")
+	}
+	for _, row := range report.Rows {
+		if row.Status == pbCatalog.ImportRowStatus_CREATED || row.Status == pbCatalog.ImportRowStatus_UPDATED {
+			continue
+		}
+		fmt.Fprintf(w, "row %d: %s\n", row.Row, strings.ToLower(row.Status.String()))
+		for _, message := range row.Errors {
+			fmt.Fprintf(w, "  %s\n", message)
+		}
+	}
+	fmt.Fprintf(w, "created: %d, updated: %d, failed: %d, rolled back: %d\n",
+		report.Created, report.Updated, report.Failed, report.RolledBack)
+}
+
+// runExport writes the models streamed by ExportCatalog to a catalog file,
+// in any of the formats import reads. -o does not apply; -format does.
+func runExport(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("export")
+	file := flags.String("file", "-", "file to write, - for stdout")
+	format := flags.String("format", "", "json, yaml or csv; inferred from the file extension, json for stdout")
+	manufacturer := flags.String("manufacturer", "", "only export models of this manufacturer")
+	modelType := flags.String("type", "", "only export models of this type")
+	if _, err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if *format == "" && *file == "-" {
+		*format = string(catalogfile.JSON)
+	}
+	catalogFormat, err := resolveCatalogFormat(*format, *file)
+	if err != nil {
+		return &usageError{err: err}
+	}
+
+	req := &pbCatalog.ExportCatalogRequest{}
+	if *manufacturer != "" {
+		req.Manufacturer = manufacturer
+	}
+	if *modelType != "" {
+		v, err := parseEnum("model type", *modelType, pbCatalog.ModelType_value)
+		if err != nil {
+			return err
+		}
+		req.Type = pbCatalog.ModelType(v).Enum()
+	}
+
+	stream, err := pbCatalog.NewCatalogServiceClient(c.conn).ExportCatalog(ctx, req)
+	if err != nil {
+		return err
+	}
+	catalogMapper := mapper.NewCatalogMapper()
+	var entries []*models.CatalogEntry
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		entry, err := catalogMapper.ToDomain(resp.Model)
+		if err != nil {
+			return err
+		}
+		// ToDomain is for imports, which ignore IDs; exports keep them.
+		if id, err := uuid.Parse(resp.Model.Id); err == nil {
+			entry.Model.ID = id
+		}
+		entries = append(entries, entry)
+	}
+
+	if *file == "-" {
+		return catalogfile.Encode(c.out, catalogFormat, entries)
+	}
+	f, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+	if err := catalogfile.Encode(f, catalogFormat, entries); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d models to %s.\n", len(entries), *file)
+	return nil
+}
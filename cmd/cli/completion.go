@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func completionCommand() *command {
+	return &command{
+		name:    "completion",
+		usage:   "completion bash|zsh",
+		offline: true,
+		run:     runCompletion,
+	}
+}
+
+// runCompletion prints a completion script, to be loaded with e.g.
+// source <(smart-hub-cli completion bash). The zsh script is the bash one
+// run through bashcompinit.
+func runCompletion(ctx context.Context, c *cli, args []string) error {
+	positional, err := parseFlags(newFlagSet("completion"), args, 1, "bash or zsh")
+	if err != nil {
+		return err
+	}
+	switch positional[0] {
+	case "bash":
+		writeBashCompletion(c.out)
+	case "zsh":
+		fmt.Fprintln(c.out, "autoload -U +X bashcompinit && bashcompinit")
+		writeBashCompletion(c.out)
+	default:
+		return usagef("completion: unknown shell %q, expected bash or zsh", positional[0])
+	}
+	return nil
+}
+
+// writeBashCompletion completes commands and subcommands from the command
+// tree, profile names after -profile, output formats after -o and file
+// names for import.
+func writeBashCompletion(w io.Writer) {
+	fmt.Fprint(w, `_smart_hub_cli() {
+  local cur prev cmd="" sub="" i word
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  prev="${COMP_WORDS[COMP_CWORD-1]}"
+  COMPREPLY=()
+
+  case "$prev" in
+    -profile) COMPREPLY=($(compgen -W "$(smart-hub-cli profiles list -q 2>/dev/null)" -- "$cur")); return ;;
+    -o|-output) COMPREPLY=($(compgen -W "table json yaml" -- "$cur")); return ;;
+    -format) COMPREPLY=($(compgen -W "json yaml csv" -- "$cur")); return ;;
+    -file|-config) COMPREPLY=($(compgen -f -- "$cur")); return ;;
+  esac
+
+  for ((i = 1; i < COMP_CWORD; i++)); do
+    word="${COMP_WORDS[i]}"
+    case "$word" in
+      -profile|-config|-address|-timeout|-o) ((i++)) ;;
+      -*) ;;
+      *) if [[ -z "$cmd" ]]; then cmd="$word"; elif [[ -z "$sub" ]]; then sub="$word"; fi ;;
+    esac
+  done
+
+  if [[ -z "$cmd" && "$cur" == -* ]]; then
+    COMPREPLY=($(compgen -W "-config -profile -address -tls -timeout -o -primary" -- "$cur"))
+    return
+  fi
+
+  case "$cmd" in
+`)
+	fmt.Fprintf(w, "    \"\") COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", strings.Join(commands.names(), " "))
+	for _, cmd := range commands.subcommands {
+		switch {
+		case len(cmd.subcommands) > 0:
+			fmt.Fprintf(w, "    %s) [[ -z \"$sub\" ]] && COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", cmd.name, strings.Join(cmd.names(), " "))
+		case cmd.name == "import":
+			fmt.Fprintf(w, "    %s) COMPREPLY=($(compgen -f -- \"$cur\")) ;;\n", cmd.name)
+		case cmd.name == "completion":
+			fmt.Fprintf(w, "    %s) [[ -z \"$sub\" ]] && COMPREPLY=($(compgen -W \"bash zsh\" -- \"$cur\")) ;;\n", cmd.name)
+		}
+	}
+	fmt.Fprint(w, `  esac
+}
+complete -o default -F _smart_hub_cli smart-hub-cli
+`)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRunCompletion(t *testing.T) {
+	var bash bytes.Buffer
+	require.NoError(t, runCompletion(context.Background(), &cli{out: &bash}, []string{"bash"}))
+	script := bash.String()
+
+	assert.True(t, strings.HasSuffix(script, "complete -o default -F _smart_hub_cli smart-hub-cli\n"))
+	assert.Contains(t, script, `"") COMPREPLY=($(compgen -W "`+strings.Join(commands.names(), " ")+`" -- "$cur")) ;;`)
+	for _, cmd := range commands.subcommands {
+		if len(cmd.subcommands) > 0 {
+			assert.Contains(t, script, cmd.name+`) [[ -z "$sub" ]] && COMPREPLY=($(compgen -W "`+strings.Join(cmd.names(), " ")+`"`)
+		}
+	}
+	assert.Contains(t, script, `import) COMPREPLY=($(compgen -f -- "$cur")) ;;`)
+	assert.Contains(t, script, `completion) [[ -z "$sub" ]] && COMPREPLY=($(compgen -W "bash zsh" -- "$cur")) ;;`)
+
+	if path, err := exec.LookPath("bash"); err == nil {
+		check := exec.Command(path, "-n")
+		check.Stdin = strings.NewReader(script)
+		output, err := check.CombinedOutput()
+		assert.NoError(t, err, string(output))
+	}
+
+	var zsh bytes.Buffer
+	require.NoError(t, runCompletion(context.Background(), &cli{out: &zsh}, []string{"zsh"}))
+	assert.Equal(t, "autoload -U +X bashcompinit && bashcompinit\n"+script, zsh.String())
+
+	var usageErr *usageError
+	assert.ErrorAs(t, runCompletion(context.Background(), &cli{out: &bytes.Buffer{}}, []string{"fish"}), &usageErr)
+	assert.ErrorAs(t, runCompletion(context.Background(), &cli{out: &bytes.Buffer{}}, nil), &usageErr)
+}
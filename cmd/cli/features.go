@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	pb "smart-hub/gen/proto/smart_feature/v1"
+)
+
+func featuresCommand() *command {
+	return &command{
+		name: "features",
+		subcommands: []*command{
+			{name: "list", usage: "features list MODEL_ID", run: runFeaturesList},
+			{name: "get", usage: "features get ID", run: runFeaturesGet},
+			{name: "create", usage: "features create -model-id ID -name N -description D -protocol P -interface-path PATH [-parameters JSON]", run: runFeaturesCreate},
+			{name: "update", usage: "features update ID [-name N] [-protocol P] [-interface-path PATH] [-description D] [-parameters JSON]", run: runFeaturesUpdate},
+			{name: "delete", usage: "features delete ID", run: runFeaturesDelete},
+		},
+	}
+}
+
+var featureHeader = []string{"ID", "MODEL ID", "NAME", "PROTOCOL", "INTERFACE PATH", "PARAMETERS"}
+
+func featureTable(features ...*pb.SmartFeature) *table {
+	t := &table{header: featureHeader}
+	for _, feature := range features {
+		t.rows = append(t.rows, []string{
+			feature.Id, feature.ModelId, feature.Name, feature.Protocol.String(),
+			feature.InterfacePath, structString(feature.Parameters),
+		})
+	}
+	return t
+}
+
+func runFeaturesList(ctx context.Context, c *cli, args []string) error {
+	positional, err := parseFlags(newFlagSet("features list"), args, 1, "MODEL_ID")
+	if err != nil {
+		return err
+	}
+
+	resp, err := pb.NewSmartFeatureServiceClient(c.conn).GetFeaturesByModelID(ctx, &pb.GetFeaturesByModelIDRequest{ModelId: positional[0]})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, featureTable(resp.Features...))
+}
+
+func runFeaturesGet(ctx context.Context, c *cli, args []string) error {
+	positional, err := parseFlags(newFlagSet("features get"), args, 1, "ID")
+	if err != nil {
+		return err
+	}
+
+	resp, err := pb.NewSmartFeatureServiceClient(c.conn).GetSmartFeature(ctx, &pb.GetSmartFeatureRequest{Id: positional[0]})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, featureTable(resp.Feature))
+}
+
+// featureFlags are the flags of features create and update.
+type featureFlags struct {
+	name, protocol, interfacePath, description, parameters *string
+}
+
+func newFeatureFlags(name string) (*flag.FlagSet, *featureFlags) {
+	flags := newFlagSet(name)
+	return flags, &featureFlags{
+		name:          flags.String("name", "", "feature name"),
+		protocol:      flags.String("protocol", "", "REST, GRPC, MQTT or WEBSOCKET"),
+		interfacePath: flags.String("interface-path", "", "interface path, e.g. /sensors/temperature"),
+		description:   flags.String("description", "", "description"),
+		parameters:    flags.String("parameters", "", "parameters as a JSON object"),
+	}
+}
+
+func runFeaturesCreate(ctx context.Context, c *cli, args []string) error {
+	flags, ff := newFeatureFlags("features create")
+	modelID := flags.String("model-id", "", "ID of the model the feature belongs to")
+	if _, err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if err := requireFlags(flags, "model-id", "name", "description", "protocol", "interface-path"); err != nil {
+		return err
+	}
+
+	input := &pb.CreateSmartFeatureInput{
+		ModelId:       *modelID,
+		Name:          *ff.name,
+		Description:   *ff.description,
+		InterfacePath: *ff.interfacePath,
+	}
+	protocol, err := parseEnum("protocol", *ff.protocol, pb.ProtocolType_value)
+	if err != nil {
+		return err
+	}
+	input.Protocol = pb.ProtocolType(protocol)
+	if *ff.parameters != "" {
+		if input.Parameters, err = parseStruct("parameters", *ff.parameters); err != nil {
+			return err
+		}
+	}
+
+	resp, err := pb.NewSmartFeatureServiceClient(c.conn).CreateSmartFeature(ctx, &pb.CreateSmartFeatureRequest{Feature: input})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, featureTable(resp.Feature))
+}
+
+// runFeaturesUpdate changes only the fields given on the command line, like
+// runModelsUpdate.
+func runFeaturesUpdate(ctx context.Context, c *cli, args []string) error {
+	flags, ff := newFeatureFlags("features update")
+	positional, err := parseFlags(flags, args, 1, "ID")
+	if err != nil {
+		return err
+	}
+	set := visited(flags)
+	if len(set) == 0 {
+		return usagef("features update: nothing to change")
+	}
+
+	client := pb.NewSmartFeatureServiceClient(c.conn)
+	current, err := client.GetSmartFeature(ctx, &pb.GetSmartFeatureRequest{Id: positional[0]})
+	if err != nil {
+		return err
+	}
+	feature := current.Feature
+	input := &pb.UpdateSmartFeatureInput{
+		Id:            feature.Id,
+		Name:          feature.Name,
+		Description:   feature.Description,
+		Protocol:      feature.Protocol,
+		InterfacePath: feature.InterfacePath,
+		Parameters:    feature.Parameters,
+	}
+	if set["name"] {
+		input.Name = *ff.name
+	}
+	if set["protocol"] {
+		protocol, err := parseEnum("protocol", *ff.protocol, pb.ProtocolType_value)
+		if err != nil {
+			return err
+		}
+		input.Protocol = pb.ProtocolType(protocol)
+	}
+	if set["interface-path"] {
+		input.InterfacePath = *ff.interfacePath
+	}
+	if set["description"] {
+		input.Description = *ff.description
+	}
+	if set["parameters"] {
+		if input.Parameters, err = parseStruct("parameters", *ff.parameters); err != nil {
+			return err
+		}
+	}
+
+	resp, err := client.UpdateSmartFeature(ctx, &pb.UpdateSmartFeatureRequest{Feature: input})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, featureTable(resp.Feature))
+}
+
+func runFeaturesDelete(ctx context.Context, c *cli, args []string) error {
+	positional, err := parseFlags(newFlagSet("features delete"), args, 1, "ID")
+	if err != nil {
+		return err
+	}
+
+	resp, err := pb.NewSmartFeatureServiceClient(c.conn).DeleteSmartFeature(ctx, &pb.DeleteSmartFeatureRequest{Id: positional[0]})
+	if err != nil {
+		return err
+	}
+	if c.output == tableOutput {
+		_, err := fmt.Fprintf(c.out, "Deleted feature %s.\n", positional[0])
+		return err
+	}
+	return c.print(resp, nil)
+}
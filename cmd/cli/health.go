@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	pb "smart-hub/gen/proto/health/v1"
+	"strings"
+)
+
+func healthCommand() *command {
+	return &command{
+		name:  "health",
+		usage: "health [-service NAME]",
+		run:   runHealth,
+	}
+}
+
+// runHealth fails unless the server reports SERVING, so that it can be used
+// in scripts.
+func runHealth(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("health")
+	service := flags.String("service", "", "service to check; empty checks the server")
+	if _, err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	resp, err := pb.NewHealthClient(c.conn).Check(ctx, &pb.HealthCheckRequest{Service: *service})
+	if err != nil {
+		return err
+	}
+	state := strings.TrimPrefix(resp.Status.String(), "SERVING_STATUS_")
+	t := &table{header: []string{"ADDRESS", "STATUS"}, rows: [][]string{{c.profile.Address, state}}}
+	if err := c.print(resp, t); err != nil {
+		return err
+	}
+	if resp.Status != pb.HealthCheckResponse_SERVING_STATUS_SERVING {
+		return fmt.Errorf("server at %s is %s", c.profile.Address, strings.ToLower(strings.ReplaceAll(state, "_", " ")))
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+// Command smart-hub-cli manages the catalog of a running smart-hub server over
+// gRPC: models, features, search, bulk import and export, and health checks.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io"
+	"os"
+	"smart-hub/internal/presentation/grpc/interceptor"
+	"strings"
+	"time"
+)
+
+// usageError marks errors in the command line, which exit with status 2.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+// command is a node of the command tree. Leaves have run, inner nodes have
+// subcommands.
+type command struct {
+	name  string
+	usage string
+	// offline commands do not talk to the server.
+	offline     bool
+	run         func(ctx context.Context, c *cli, args []string) error
+	subcommands []*command
+}
+
+func (cmd *command) find(name string) *command {
+	for _, sub := range cmd.subcommands {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (cmd *command) names() []string {
+	names := make([]string, len(cmd.subcommands))
+	for i, sub := range cmd.subcommands {
+		names[i] = sub.name
+	}
+	return names
+}
+
+// commands is the root of the command tree. It is filled in by init to
+// break the initialization cycle with completionCommand, which walks it.
+var commands *command
+
+func init() {
+	commands = &command{
+		name: "smart-hub-cli",
+		subcommands: []*command{
+			modelsCommand(),
+			featuresCommand(),
+			searchCommand(),
+			importCommand(),
+			exportCommand(),
+			healthCommand(),
+			profilesCommand(),
+			completionCommand(),
+		},
+	}
+}
+
+// cli is the state shared by all commands.
+type cli struct {
+	out     io.Writer
+	output  outputFormat
+	profile *profile
+	config  *profileConfig
+	// configPath is where config is saved by the profiles commands.
+	configPath string
+	conn       *grpc.ClientConn
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	if err == nil {
+		return
+	}
+
+	var usageErr *usageError
+	switch {
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(os.Stderr, "error:", err)
+		fmt.Fprintln(os.Stderr, "run smart-hub-cli -h for usage")
+		os.Exit(2)
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(0)
+	default:
+		if s, ok := status.FromError(err); ok && s.Code() != 0 {
+			err = fmt.Errorf("%s: %s", s.Code(), s.Message())
+		}
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("smart-hub-cli", flag.ContinueOnError)
+	configPath := flags.String("config", "", "profiles file (default $SMART_HUB_CLI_CONFIG or <user config dir>/smart-hub/cli.yaml)")
+	profileName := flags.String("profile", "", "profile to use (default $SMART_HUB_PROFILE or the current profile)")
+	address := flags.String("address", "", "server address, overrides the profile")
+	useTLS := flags.Bool("tls", false, "connect with TLS, overrides the profile")
+	timeout := flags.Duration("timeout", 0, "deadline for the whole command, overrides the profile; 0 waits forever")
+	output := flags.String("o", "", "output format: table, json or yaml")
+	primary := flags.Bool("primary", false, "read from the primary database instead of a replica")
+	flags.Usage = func() { printUsage(flags) }
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{err: err}
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		printUsage(flags)
+		return usagef("no command given")
+	}
+
+	c := &cli{out: out}
+	var err error
+	if c.configPath, err = resolveConfigPath(*configPath); err != nil {
+		return err
+	}
+	if c.config, err = loadProfileConfig(c.configPath); err != nil {
+		return err
+	}
+	if *profileName == "" {
+		*profileName = os.Getenv("SMART_HUB_PROFILE")
+	}
+	if c.profile, err = c.config.resolve(*profileName); err != nil {
+		return err
+	}
+
+	set := visited(flags)
+	if set["address"] {
+		c.profile.Address = *address
+	}
+	if set["tls"] {
+		c.profile.TLS = *useTLS
+	}
+	if set["timeout"] {
+		c.profile.Timeout = *timeout
+	}
+	if set["o"] {
+		c.profile.Output = *output
+	}
+	if c.output, err = parseOutputFormat(c.profile.Output); err != nil {
+		return &usageError{err: err}
+	}
+
+	cmd, args, err := resolveCommand(commands, args)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if c.profile.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.profile.Timeout)
+		defer cancel()
+	}
+	if *primary {
+		ctx = metadata.AppendToOutgoingContext(ctx, interceptor.ReadConsistencyHeader, interceptor.ReadConsistencyPrimary)
+	}
+
+	if !cmd.offline {
+		if c.conn, err = dial(c.profile); err != nil {
+			return err
+		}
+		defer c.conn.Close()
+	}
+	return cmd.run(ctx, c, args)
+}
+
+// resolveCommand walks args down the command tree to a leaf and returns it
+// with the remaining arguments.
+func resolveCommand(cmd *command, args []string) (*command, []string, error) {
+	path := []string{}
+	for cmd.run == nil {
+		if len(args) == 0 {
+			return nil, nil, usagef("%s needs a subcommand: %s", strings.Join(path, " "), strings.Join(cmd.names(), ", "))
+		}
+		sub := cmd.find(args[0])
+		if sub == nil {
+			if len(path) == 0 {
+				return nil, nil, usagef("unknown command %q, expected one of %s", args[0], strings.Join(cmd.names(), ", "))
+			}
+			return nil, nil, usagef("unknown %s subcommand %q, expected one of %s",
+				strings.Join(path, " "), args[0], strings.Join(cmd.names(), ", "))
+		}
+		path = append(path, sub.name)
+		cmd, args = sub, args[1:]
+	}
+	return cmd, args, nil
+}
+
+func dial(p *profile) (*grpc.ClientConn, error) {
+	if p.Address == "" {
+		return nil, errors.New("no server address, set one with -address or in the profile")
+	}
+	creds := insecure.NewCredentials()
+	if p.TLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	return grpc.NewClient(p.Address, grpc.WithTransportCredentials(creds))
+}
+
+func printUsage(flags *flag.FlagSet) {
+	w := flags.Output()
+	fmt.Fprintln(w, "usage: smart-hub-cli [global flags] <command> [subcommand] [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands.subcommands {
+		if cmd.run != nil {
+			fmt.Fprintf(w, "  %s\n", cmd.usage)
+		}
+		for _, sub := range cmd.subcommands {
+			fmt.Fprintf(w, "  %s\n", sub.usage)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	flags.PrintDefaults()
+}
+
+// parseFlags parses args with flags, allowing flags after positional
+// arguments, e.g. "models get ID -full". It returns the positional arguments
+// and fails unless there are exactly want of them; want < 0 accepts any
+// number.
+func parseFlags(flags *flag.FlagSet, args []string, want int, names ...string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &usageError{err: err}
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if want >= 0 && len(positional) != want {
+		if want == 0 {
+			return nil, usagef("%s takes no arguments", flags.Name())
+		}
+		return nil, usagef("%s expects %s", flags.Name(), strings.Join(names, " "))
+	}
+	return positional, nil
+}
+
+// visited returns the names of the flags that were set on the command line.
+func visited(flags *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
+
+// requireFlags fails unless all the named flags were set.
+func requireFlags(flags *flag.FlagSet, names ...string) error {
+	set := visited(flags)
+	for _, name := range names {
+		if !set[name] {
+			return usagef("%s: -%s is required", flags.Name(), name)
+		}
+	}
+	return nil
+}
+
+// newFlagSet returns a flag set named after the command path, with errors
+// reported to the caller instead of exiting.
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
+
+// formatTime formats a timestamp for table output, in local time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.DateTime)
+}
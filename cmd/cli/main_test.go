@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+func TestResolveCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCmd  string
+		wantArgs []string
+		wantErr  string
+	}{
+		{name: "leaf", args: []string{"health"}, wantCmd: "health", wantArgs: []string{}},
+		{name: "subcommand with arguments", args: []string{"models", "get", "ID", "-full"}, wantCmd: "get", wantArgs: []string{"ID", "-full"}},
+		{name: "missing subcommand", args: []string{"models"}, wantErr: "models needs a subcommand: list, get"},
+		{name: "unknown command", args: []string{"devices"}, wantErr: `unknown command "devices", expected one of models, features`},
+		{name: "unknown subcommand", args: []string{"profiles", "rename"}, wantErr: `unknown profiles subcommand "rename", expected one of list, use, set, delete`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, args, err := resolveCommand(commands, tt.args)
+			if tt.wantErr != "" {
+				var usageErr *usageError
+				require.ErrorAs(t, err, &usageErr)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCmd, cmd.name)
+			assert.NotNil(t, cmd.run)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		want       int
+		positional []string
+		full       bool
+		limit      int
+		wantErr    string
+	}{
+		{name: "flags first", args: []string{"-full", "-limit", "5", "ID"}, want: 1, positional: []string{"ID"}, full: true, limit: 5},
+		{name: "flags after positional", args: []string{"ID", "-full", "-limit", "5"}, want: 1, positional: []string{"ID"}, full: true, limit: 5},
+		{name: "flags between positionals", args: []string{"A", "-full", "B"}, want: 2, positional: []string{"A", "B"}, full: true},
+		{name: "any number", args: []string{"A", "B", "C"}, want: -1, positional: []string{"A", "B", "C"}},
+		{name: "none wanted", args: []string{"-full"}, want: 0, full: true},
+		{name: "double dash ends flags", args: []string{"--", "-full"}, want: 1, positional: []string{"-full"}},
+		{name: "missing positional", args: []string{"-full"}, want: 1, wantErr: "test expects ID"},
+		{name: "unexpected positional", args: []string{"ID"}, want: 0, wantErr: "test takes no arguments"},
+		{name: "unknown flag", args: []string{"ID", "-verbose"}, want: 1, wantErr: "flag provided but not defined: -verbose"},
+		{name: "bad value", args: []string{"ID", "-limit", "many"}, want: 1, wantErr: `invalid value "many" for flag -limit`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := newFlagSet("test")
+			flags.SetOutput(io.Discard)
+			full := flags.Bool("full", false, "")
+			limit := flags.Int("limit", 0, "")
+
+			positional, err := parseFlags(flags, tt.args, tt.want, "ID")
+			if tt.wantErr != "" {
+				var usageErr *usageError
+				require.ErrorAs(t, err, &usageErr)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.positional, positional)
+			assert.Equal(t, tt.full, *full)
+			assert.Equal(t, tt.limit, *limit)
+		})
+	}
+
+	flags := newFlagSet("test")
+	flags.SetOutput(io.Discard)
+	_, err := parseFlags(flags, []string{"ID", "-h"}, 1, "ID")
+	assert.ErrorIs(t, err, flag.ErrHelp)
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	t.Setenv("SMART_HUB_CLI_CONFIG", t.TempDir()+"/cli.yaml")
+
+	var usageErr *usageError
+	assert.ErrorAs(t, run(nil, io.Discard), &usageErr)
+	assert.ErrorAs(t, run([]string{"-o", "xml", "health"}, io.Discard), &usageErr)
+	assert.ErrorAs(t, run([]string{"-unknown"}, io.Discard), &usageErr)
+	assert.ErrorAs(t, run([]string{"models"}, io.Discard), &usageErr)
+	assert.ErrorIs(t, run([]string{"-h"}, io.Discard), flag.ErrHelp)
+	assert.EqualError(t, run([]string{"-address", "", "health"}, &bytes.Buffer{}), "no server address, set one with -address or in the profile")
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	pb "smart-hub/gen/proto/smart_model/v1"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func modelsCommand() *command {
+	return &command{
+		name: "models",
+		subcommands: []*command{
+			{name: "list", usage: "models list [-full]", run: runModelsList},
+			{name: "get", usage: "models get ID [-full]", run: runModelsGet},
+			{name: "create", usage: "models create -name N -description D -type T -category C [-manufacturer M] [-model-number N] [-metadata JSON]", run: runModelsCreate},
+			{name: "update", usage: "models update ID [-name N] [-type T] [-category C] [-manufacturer M] [-model-number N] [-description D] [-metadata JSON]", run: runModelsUpdate},
+			{name: "delete", usage: "models delete ID", run: runModelsDelete},
+		},
+	}
+}
+
+// parseEnum looks value up case-insensitively among the names of a proto
+// enum, e.g. pb.ModelType_value.
+func parseEnum(kind, value string, values map[string]int32) (int32, error) {
+	if v, ok := values[strings.ToUpper(value)]; ok {
+		return v, nil
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return 0, usagef("unknown %s %q, expected one of %s", kind, value, strings.Join(names, ", "))
+}
+
+// parseStruct parses a JSON object flag, e.g. -metadata '{"color": "red"}'.
+func parseStruct(name, value string) (*structpb.Struct, error) {
+	s := &structpb.Struct{}
+	if err := protojson.Unmarshal([]byte(value), s); err != nil {
+		return nil, usagef("-%s is not a JSON object: %v", name, err)
+	}
+	return s, nil
+}
+
+func modelView(full bool) pb.SmartModelView {
+	if full {
+		return pb.SmartModelView_FULL
+	}
+	return pb.SmartModelView_BASIC
+}
+
+func modelTable(full bool, smartModels ...*pb.SmartModel) *table {
+	t := &table{header: []string{"ID", "NAME", "TYPE", "CATEGORY", "MANUFACTURER", "MODEL NUMBER", "UPDATED"}}
+	if full {
+		t.header = append(t.header, "FEATURES")
+	}
+	for _, model := range smartModels {
+		row := []string{
+			model.Id, model.Name, model.Type.String(), model.Category.String(),
+			model.Manufacturer, model.ModelNumber, timestampString(model.UpdatedAt),
+		}
+		if full {
+			row = append(row, strconv.Itoa(len(model.Features)))
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t
+}
+
+func runModelsList(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("models list")
+	full := flags.Bool("full", false, "include the features of each model")
+	if _, err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	resp, err := pb.NewSmartModelServiceClient(c.conn).ListSmartModels(ctx, &pb.ListSmartModelsRequest{View: modelView(*full)})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, modelTable(*full, resp.Models...))
+}
+
+func runModelsGet(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("models get")
+	full := flags.Bool("full", false, "include the features of the model")
+	positional, err := parseFlags(flags, args, 1, "ID")
+	if err != nil {
+		return err
+	}
+
+	resp, err := pb.NewSmartModelServiceClient(c.conn).GetSmartModel(ctx, &pb.GetSmartModelRequest{Id: positional[0], View: modelView(*full)})
+	if err != nil {
+		return err
+	}
+	if *full && c.output == tableOutput {
+		if err := c.printTable(modelTable(true, resp.Model)); err != nil {
+			return err
+		}
+		fmt.Fprintln(c.out)
+		return c.printTable(modelFeatureTable(resp.Model.Features))
+	}
+	return c.print(resp, modelTable(*full, resp.Model))
+}
+
+// modelFlags are the flags of models create and update.
+type modelFlags struct {
+	name, modelType, category, manufacturer, modelNumber, description, metadata *string
+}
+
+func newModelFlags(name string) (*flag.FlagSet, *modelFlags) {
+	flags := newFlagSet(name)
+	return flags, &modelFlags{
+		name:         flags.String("name", "", "model name"),
+		modelType:    flags.String("type", "", "DEVICE or SERVICE"),
+		category:     flags.String("category", "", "WEARABLE, CAMERA, WEATHER or ENTERTAINMENT"),
+		manufacturer: flags.String("manufacturer", "", "manufacturer"),
+		modelNumber:  flags.String("model-number", "", "model number, unique per manufacturer"),
+		description:  flags.String("description", "", "description"),
+		metadata:     flags.String("metadata", "", "metadata as a JSON object"),
+	}
+}
+
+func runModelsCreate(ctx context.Context, c *cli, args []string) error {
+	flags, mf := newModelFlags("models create")
+	if _, err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if err := requireFlags(flags, "name", "description", "type", "category"); err != nil {
+		return err
+	}
+
+	input := &pb.CreateSmartModelInput{
+		Name:         *mf.name,
+		Manufacturer: *mf.manufacturer,
+		ModelNumber:  *mf.modelNumber,
+		Description:  *mf.description,
+	}
+	modelType, err := parseEnum("model type", *mf.modelType, pb.ModelType_value)
+	if err != nil {
+		return err
+	}
+	input.Type = pb.ModelType(modelType)
+	category, err := parseEnum("model category", *mf.category, pb.ModelCategory_value)
+	if err != nil {
+		return err
+	}
+	input.Category = pb.ModelCategory(category)
+	if *mf.metadata != "" {
+		if input.Metadata, err = parseStruct("metadata", *mf.metadata); err != nil {
+			return err
+		}
+	}
+
+	resp, err := pb.NewSmartModelServiceClient(c.conn).CreateSmartModel(ctx, &pb.CreateSmartModelRequest{Model: input})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, modelTable(false, resp.Model))
+}
+
+// runModelsUpdate changes only the fields given on the command line. The
+// server replaces the whole model, so the others are read from it first.
+func runModelsUpdate(ctx context.Context, c *cli, args []string) error {
+	flags, mf := newModelFlags("models update")
+	positional, err := parseFlags(flags, args, 1, "ID")
+	if err != nil {
+		return err
+	}
+	set := visited(flags)
+	if len(set) == 0 {
+		return usagef("models update: nothing to change")
+	}
+
+	client := pb.NewSmartModelServiceClient(c.conn)
+	current, err := client.GetSmartModel(ctx, &pb.GetSmartModelRequest{Id: positional[0]})
+	if err != nil {
+		return err
+	}
+	model := current.Model
+	input := &pb.UpdateSmartModelInput{
+		Id:           model.Id,
+		Name:         model.Name,
+		Type:         model.Type,
+		Category:     model.Category,
+		Manufacturer: model.Manufacturer,
+		ModelNumber:  model.ModelNumber,
+		Description:  model.Description,
+		Metadata:     model.Metadata,
+	}
+	if set["name"] {
+		input.Name = *mf.name
+	}
+	if set["type"] {
+		modelType, err := parseEnum("model type", *mf.modelType, pb.ModelType_value)
+		if err != nil {
+			return err
+		}
+		input.Type = pb.ModelType(modelType)
+	}
+	if set["category"] {
+		category, err := parseEnum("model category", *mf.category, pb.ModelCategory_value)
+		if err != nil {
+			return err
+		}
+		input.Category = pb.ModelCategory(category)
+	}
+	if set["manufacturer"] {
+		input.Manufacturer = *mf.manufacturer
+	}
+	if set["model-number"] {
+		input.ModelNumber = *mf.modelNumber
+	}
+	if set["description"] {
+		input.Description = *mf.description
+	}
+	if set["metadata"] {
+		if input.Metadata, err = parseStruct("metadata", *mf.metadata); err != nil {
+			return err
+		}
+	}
+
+	resp, err := client.UpdateSmartModel(ctx, &pb.UpdateSmartModelRequest{Model: input})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, modelTable(false, resp.Model))
+}
+
+func runModelsDelete(ctx context.Context, c *cli, args []string) error {
+	positional, err := parseFlags(newFlagSet("models delete"), args, 1, "ID")
+	if err != nil {
+		return err
+	}
+
+	resp, err := pb.NewSmartModelServiceClient(c.conn).DeleteSmartModel(ctx, &pb.DeleteSmartModelRequest{Id: positional[0]})
+	if err != nil {
+		return err
+	}
+	if c.output == tableOutput {
+		_, err := fmt.Fprintf(c.out, "Deleted model %s.\n", positional[0])
+		return err
+	}
+	return c.print(resp, nil)
+}
+
+// modelFeatureTable is featureTable for the features embedded in a model.
+func modelFeatureTable(features []*pb.SmartFeature) *table {
+	t := &table{header: featureHeader}
+	for _, feature := range features {
+		t.rows = append(t.rows, []string{
+			feature.Id, feature.ModelId, feature.Name, feature.Protocol.String(),
+			feature.InterfacePath, structString(feature.Parameters),
+		})
+	}
+	return t
+}
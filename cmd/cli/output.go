@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/yaml.v3"
+	"strings"
+	"text/tabwriter"
+)
+
+type outputFormat string
+
+const (
+	tableOutput outputFormat = "table"
+	jsonOutput  outputFormat = "json"
+	yamlOutput  outputFormat = "yaml"
+)
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch strings.ToLower(s) {
+	case "", "table":
+		return tableOutput, nil
+	case "json":
+		return jsonOutput, nil
+	case "yaml", "yml":
+		return yamlOutput, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q, expected table, json or yaml", s)
+	}
+}
+
+// table is the table output of a command.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// protoJSON marshals responses with the field names of the proto files.
+// Unpopulated fields are included because the zero value of every enum is a
+// real value, e.g. DEVICE.
+var protoJSON = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+// print writes msg, the response of a call, as JSON or YAML, or t as a
+// table.
+func (c *cli) print(msg proto.Message, t *table) error {
+	if c.output == tableOutput {
+		return c.printTable(t)
+	}
+	data, err := protoJSON.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.printJSON(data)
+}
+
+// printValue is print for values that are not proto messages.
+func (c *cli) printValue(v interface{}, t *table) error {
+	if c.output == tableOutput {
+		return c.printTable(t)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.printJSON(data)
+}
+
+func (c *cli) printTable(t *table) error {
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// printJSON pretty-prints data, or converts it to YAML keeping the order of
+// the keys.
+func (c *cli) printJSON(data []byte) error {
+	if c.output == jsonOutput {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(c.out, indented.String())
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+	encoder := yaml.NewEncoder(c.out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle drops the flow and quoting styles that parsing JSON leaves on
+// the nodes, so that they are written as plain block YAML. Strings that would
+// read back as another type are still quoted.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// structString formats a Struct compactly for a table cell.
+func structString(s *structpb.Struct) string {
+	if s == nil || len(s.Fields) == 0 {
+		return ""
+	}
+	data, err := protojson.Marshal(s)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func timestampString(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	return formatTime(ts.AsTime())
+}
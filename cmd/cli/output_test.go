@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+	"testing"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    outputFormat
+		wantErr bool
+	}{
+		{in: "", want: tableOutput},
+		{in: "table", want: tableOutput},
+		{in: "JSON", want: jsonOutput},
+		{in: "yaml", want: yamlOutput},
+		{in: "yml", want: yamlOutput},
+		{in: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseOutputFormat(tt.in)
+		if tt.wantErr {
+			assert.Error(t, err, tt.in)
+			continue
+		}
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
+
+func TestPrintValue(t *testing.T) {
+	entries := []profileEntry{
+		{Name: "local", Current: true, Address: "localhost:50051"},
+		{Name: "prod", Address: "hub.example.com:443", TLS: true, Timeout: "30s"},
+	}
+	tbl := &table{
+		header: []string{"NAME", "ADDRESS"},
+		rows:   [][]string{{"local", "localhost:50051"}, {"prod", "hub.example.com:443"}},
+	}
+
+	tests := []struct {
+		output outputFormat
+		want   string
+	}{
+		{
+			output: tableOutput,
+			want: "NAME   ADDRESS\n" +
+				"local  localhost:50051\n" +
+				"prod   hub.example.com:443\n",
+		},
+		{
+			output: jsonOutput,
+			want: `[
+  {
+    "name": "local",
+    "current": true,
+    "address": "localhost:50051",
+    "tls": false
+  },
+  {
+    "name": "prod",
+    "current": false,
+    "address": "hub.example.com:443",
+    "tls": true,
+    "timeout": "30s"
+  }
+]
+`,
+		},
+		{
+			output: yamlOutput,
+			want: `- name: local
+  current: true
+  address: localhost:50051
+  tls: false
+- name: prod
+  current: false
+  address: hub.example.com:443
+  tls: true
+  timeout: 30s
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.output), func(t *testing.T) {
+			var out bytes.Buffer
+			c := &cli{out: &out, output: tt.output}
+			require.NoError(t, c.printValue(entries, tbl))
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestPrint_Proto(t *testing.T) {
+	msg, err := structpb.NewStruct(map[string]interface{}{"version": "1.0", "enabled": true})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	c := &cli{out: &out, output: yamlOutput}
+	require.NoError(t, c.print(msg, nil))
+	// Strings that would read back as another type stay quoted.
+	assert.Equal(t, "enabled: true\nversion: \"1.0\"\n", out.String())
+
+	out.Reset()
+	c.output = jsonOutput
+	require.NoError(t, c.print(msg, nil))
+	assert.JSONEq(t, `{"enabled": true, "version": "1.0"}`, out.String())
+}
+
+func TestStructString(t *testing.T) {
+	assert.Empty(t, structString(nil))
+	assert.Empty(t, structString(&structpb.Struct{}))
+
+	s, err := structpb.NewStruct(map[string]interface{}{"unit": "C"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"unit": "C"}`, structString(s))
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// defaultProfileName is the profile used when the file names no current
+// profile.
+const defaultProfileName = "default"
+
+// profile holds the connection settings for one environment, e.g. local,
+// staging or production.
+type profile struct {
+	Address string        `yaml:"address"`
+	TLS     bool          `yaml:"tls,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	Output  string        `yaml:"output,omitempty"`
+}
+
+// profileConfig is the profiles file:
+//
+//	current: local
+//	profiles:
+//	  local:
+//	    address: localhost:50051
+//	  production:
+//	    address: hub.example.com:443
+//	    tls: true
+//	    timeout: 30s
+type profileConfig struct {
+	Current  string              `yaml:"current,omitempty"`
+	Profiles map[string]*profile `yaml:"profiles"`
+}
+
+// profileEntry is a profile as printed by "profiles list".
+type profileEntry struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+	Address string `json:"address"`
+	TLS     bool   `json:"tls"`
+	Timeout string `json:"timeout,omitempty"`
+	Output  string `json:"output,omitempty"`
+}
+
+func defaultProfile() *profile {
+	return &profile{Address: "localhost:50051", Timeout: 30 * time.Second}
+}
+
+func resolveConfigPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	if path = os.Getenv("SMART_HUB_CLI_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate the profiles file, set -config: %w", err)
+	}
+	return filepath.Join(dir, "smart-hub", "cli.yaml"), nil
+}
+
+// loadProfileConfig reads the profiles file at path. A missing file is an
+// empty configuration.
+func loadProfileConfig(path string) (*profileConfig, error) {
+	config := &profileConfig{Profiles: map[string]*profile{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid profiles file %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]*profile{}
+	}
+	return config, nil
+}
+
+func (pc *profileConfig) save(path string) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(pc); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
+
+// resolve returns a copy of the named profile, or of the current one when
+// name is empty. Without a current profile it falls back to the "default"
+// profile and then to the built-in defaults, which talk to a local server.
+func (pc *profileConfig) resolve(name string) (*profile, error) {
+	explicit := name != ""
+	if name == "" {
+		name = pc.Current
+	}
+	if name == "" {
+		name = defaultProfileName
+	}
+
+	p, ok := pc.Profiles[name]
+	switch {
+	case ok:
+		resolved := *p
+		return &resolved, nil
+	case explicit || pc.Current != "":
+		return nil, fmt.Errorf("unknown profile %q", name)
+	default:
+		return defaultProfile(), nil
+	}
+}
+
+func (pc *profileConfig) names() []string {
+	names := make([]string, 0, len(pc.Profiles))
+	for name := range pc.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func profilesCommand() *command {
+	return &command{
+		name: "profiles",
+		subcommands: []*command{
+			{name: "list", usage: "profiles list [-q]", offline: true, run: runProfilesList},
+			{name: "use", usage: "profiles use NAME", offline: true, run: runProfilesUse},
+			{name: "set", usage: "profiles set NAME [-address ADDR] [-tls] [-timeout D] [-output FORMAT]", offline: true, run: runProfilesSet},
+			{name: "delete", usage: "profiles delete NAME", offline: true, run: runProfilesDelete},
+		},
+	}
+}
+
+func runProfilesList(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("profiles list")
+	quiet := flags.Bool("q", false, "print the profile names only")
+	if _, err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	names := c.config.names()
+	if *quiet {
+		for _, name := range names {
+			fmt.Fprintln(c.out, name)
+		}
+		return nil
+	}
+
+	entries := make([]profileEntry, len(names))
+	t := &table{header: []string{"CURRENT", "NAME", "ADDRESS", "TLS", "TIMEOUT", "OUTPUT"}}
+	for i, name := range names {
+		p := c.config.Profiles[name]
+		entries[i] = profileEntry{Name: name, Current: name == c.config.Current, Address: p.Address, TLS: p.TLS, Output: p.Output}
+		if p.Timeout > 0 {
+			entries[i].Timeout = p.Timeout.String()
+		}
+		current := ""
+		if entries[i].Current {
+			current = "*"
+		}
+		t.rows = append(t.rows, []string{current, name, p.Address, strconv.FormatBool(p.TLS), entries[i].Timeout, p.Output})
+	}
+	return c.printValue(entries, t)
+}
+
+func runProfilesUse(ctx context.Context, c *cli, args []string) error {
+	positional, err := parseFlags(newFlagSet("profiles use"), args, 1, "NAME")
+	if err != nil {
+		return err
+	}
+	name := positional[0]
+	if _, ok := c.config.Profiles[name]; !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	c.config.Current = name
+	return c.config.save(c.configPath)
+}
+
+// runProfilesSet creates a profile or changes the given settings of an
+// existing one. The first profile created becomes the current one.
+func runProfilesSet(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("profiles set")
+	address := flags.String("address", "", "server address, host:port")
+	useTLS := flags.Bool("tls", false, "connect with TLS")
+	timeout := flags.Duration("timeout", 0, "deadline for each command; 0 waits forever")
+	output := flags.String("output", "", "default output format: table, json or yaml")
+	positional, err := parseFlags(flags, args, 1, "NAME")
+	if err != nil {
+		return err
+	}
+	name := positional[0]
+	set := visited(flags)
+	if set["output"] {
+		if _, err := parseOutputFormat(*output); err != nil {
+			return &usageError{err: err}
+		}
+	}
+
+	p, ok := c.config.Profiles[name]
+	if !ok {
+		p = defaultProfile()
+		c.config.Profiles[name] = p
+	}
+	if set["address"] {
+		p.Address = *address
+	}
+	if set["tls"] {
+		p.TLS = *useTLS
+	}
+	if set["timeout"] {
+		p.Timeout = *timeout
+	}
+	if set["output"] {
+		p.Output = *output
+	}
+	if c.config.Current == "" {
+		c.config.Current = name
+	}
+	return c.config.save(c.configPath)
+}
+
+func runProfilesDelete(ctx context.Context, c *cli, args []string) error {
+	positional, err := parseFlags(newFlagSet("profiles delete"), args, 1, "NAME")
+	if err != nil {
+		return err
+	}
+	name := positional[0]
+	if _, ok := c.config.Profiles[name]; !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	delete(c.config.Profiles, name)
+	if c.config.Current == name {
+		c.config.Current = ""
+	}
+	return c.config.save(c.configPath)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadProfileConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	config, err := loadProfileConfig(filepath.Join(dir, "missing.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, config.Current)
+	assert.NotNil(t, config.Profiles)
+
+	path := filepath.Join(dir, "cli.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("current: prod\nprofiles:\n  prod:\n    address: hub.example.com:443\n    tls: true\n    timeout: 30s\n"), 0o600))
+	config, err = loadProfileConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", config.Current)
+	assert.Equal(t, &profile{Address: "hub.example.com:443", TLS: true, Timeout: 30 * time.Second}, config.Profiles["prod"])
+
+	require.NoError(t, os.WriteFile(path, []byte("current: prod\n"), 0o600))
+	config, err = loadProfileConfig(path)
+	require.NoError(t, err)
+	assert.NotNil(t, config.Profiles)
+
+	require.NoError(t, os.WriteFile(path, []byte("profiles: [oops"), 0o600))
+	_, err = loadProfileConfig(path)
+	assert.ErrorContains(t, err, "invalid profiles file")
+}
+
+func TestProfileConfig_Save(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "smart-hub", "cli.yaml")
+	config := &profileConfig{Current: "local", Profiles: map[string]*profile{
+		"local": {Address: "localhost:50051", Output: "json"},
+		"prod":  {Address: "hub.example.com:443", TLS: true, Timeout: time.Minute},
+	}}
+
+	require.NoError(t, config.save(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the file may hold addresses of private servers")
+
+	loaded, err := loadProfileConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, config, loaded)
+}
+
+func TestProfileConfig_Resolve(t *testing.T) {
+	local := &profile{Address: "localhost:50051"}
+	prod := &profile{Address: "hub.example.com:443", TLS: true}
+	fallback := &profile{Address: "fallback:50051"}
+
+	tests := []struct {
+		name    string
+		config  profileConfig
+		profile string
+		want    *profile
+		wantErr string
+	}{
+		{name: "named", config: profileConfig{Current: "local", Profiles: map[string]*profile{"local": local, "prod": prod}}, profile: "prod", want: prod},
+		{name: "current", config: profileConfig{Current: "local", Profiles: map[string]*profile{"local": local, "prod": prod}}, want: local},
+		{name: "default profile", config: profileConfig{Profiles: map[string]*profile{"default": fallback}}, want: fallback},
+		{name: "built-in defaults", config: profileConfig{Profiles: map[string]*profile{}}, want: defaultProfile()},
+		{name: "unknown named", config: profileConfig{Profiles: map[string]*profile{"local": local}}, profile: "staging", wantErr: `unknown profile "staging"`},
+		{name: "unknown current", config: profileConfig{Current: "staging", Profiles: map[string]*profile{}}, wantErr: `unknown profile "staging"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.resolve(tt.profile)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// The resolved profile is a copy that flags can override.
+	config := profileConfig{Current: "local", Profiles: map[string]*profile{"local": local}}
+	got, err := config.resolve("")
+	require.NoError(t, err)
+	got.Address = "elsewhere:1"
+	assert.Equal(t, "localhost:50051", local.Address)
+}
+
+func TestResolveConfigPath(t *testing.T) {
+	t.Setenv("SMART_HUB_CLI_CONFIG", "/etc/smart-hub/cli.yaml")
+
+	path, err := resolveConfigPath("")
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/smart-hub/cli.yaml", path)
+
+	path, err = resolveConfigPath("./cli.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "./cli.yaml", path, "-config wins over the environment")
+
+	t.Setenv("SMART_HUB_CLI_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "/home/user/.config")
+	t.Setenv("HOME", "/home/user")
+	path, err = resolveConfigPath("")
+	require.NoError(t, err)
+	assert.Equal(t, "smart-hub", filepath.Base(filepath.Dir(path)))
+	assert.Equal(t, "cli.yaml", filepath.Base(path))
+}
+
+func TestRun_Profiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cli.yaml")
+	t.Setenv("SMART_HUB_CLI_CONFIG", path)
+	t.Setenv("SMART_HUB_PROFILE", "")
+
+	cli := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		require.NoError(t, run(args, &out))
+		return out.String()
+	}
+
+	// Flags may follow the profile name.
+	cli("profiles", "set", "local", "-address", "localhost:50051")
+	cli("profiles", "set", "prod", "-address", "hub.example.com:443", "-tls", "-timeout", "10s", "-output", "yaml")
+	assert.Equal(t, "local\nprod\n", cli("profiles", "list", "-q"))
+
+	config, err := loadProfileConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "local", config.Current, "the first profile becomes the current one")
+	assert.Equal(t, &profile{Address: "hub.example.com:443", TLS: true, Timeout: 10 * time.Second, Output: "yaml"}, config.Profiles["prod"])
+
+	cli("profiles", "use", "prod")
+	// prod prints YAML, unless -o overrides it.
+	assert.Contains(t, cli("profiles", "list"), "- name: local\n")
+	assert.Contains(t, cli("-o", "table", "profiles", "list"), "CURRENT")
+
+	// SMART_HUB_PROFILE selects the profile whose settings apply.
+	t.Setenv("SMART_HUB_PROFILE", "local")
+	assert.Contains(t, cli("profiles", "list"), "CURRENT")
+	t.Setenv("SMART_HUB_PROFILE", "staging")
+	assert.EqualError(t, run([]string{"profiles", "list"}, &bytes.Buffer{}), `unknown profile "staging"`)
+	t.Setenv("SMART_HUB_PROFILE", "")
+
+	cli("profiles", "delete", "prod")
+	config, err = loadProfileConfig(path)
+	require.NoError(t, err)
+	assert.Empty(t, config.Current)
+	assert.Equal(t, []string{"local"}, config.names())
+
+	var usageErr *usageError
+	assert.ErrorAs(t, run([]string{"profiles", "set", "local", "-output", "xml"}, &bytes.Buffer{}), &usageErr)
+	assert.EqualError(t, run([]string{"profiles", "use", "prod"}, &bytes.Buffer{}), `unknown profile "prod"`)
+}
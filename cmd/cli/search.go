@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pbFeature "smart-hub/gen/proto/smart_feature/v1"
+	pbModel "smart-hub/gen/proto/smart_model/v1"
+	"strings"
+)
+
+func searchCommand() *command {
+	return &command{
+		name: "search",
+		subcommands: []*command{
+			{name: "models", usage: "search models [-name TEXT] [-manufacturer M] [-model-number N] [-type T] [-category C] [-full]", run: runSearchModels},
+			{name: "features", usage: "search features [-name-prefix P] [-path GLOB] [-protocol P] [-model-type T] [-model-category C] [-page-size N] [-page-token T] [-all]", run: runSearchFeatures},
+		},
+	}
+}
+
+// runSearchModels looks a model up by manufacturer and model number when
+// both are given, and otherwise filters the list of all models. Text filters
+// compare case-insensitively; -name matches any part of the name.
+func runSearchModels(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("search models")
+	name := flags.String("name", "", "part of the model name")
+	manufacturer := flags.String("manufacturer", "", "manufacturer")
+	modelNumber := flags.String("model-number", "", "model number")
+	modelType := flags.String("type", "", "DEVICE or SERVICE")
+	category := flags.String("category", "", "WEARABLE, CAMERA, WEATHER or ENTERTAINMENT")
+	full := flags.Bool("full", false, "include the features of each model")
+	if _, err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	var typeFilter *pbModel.ModelType
+	if *modelType != "" {
+		v, err := parseEnum("model type", *modelType, pbModel.ModelType_value)
+		if err != nil {
+			return err
+		}
+		typeFilter = pbModel.ModelType(v).Enum()
+	}
+	var categoryFilter *pbModel.ModelCategory
+	if *category != "" {
+		v, err := parseEnum("model category", *category, pbModel.ModelCategory_value)
+		if err != nil {
+			return err
+		}
+		categoryFilter = pbModel.ModelCategory(v).Enum()
+	}
+
+	client := pbModel.NewSmartModelServiceClient(c.conn)
+	var candidates []*pbModel.SmartModel
+	if *manufacturer != "" && *modelNumber != "" {
+		resp, err := client.GetSmartModelByModelNumber(ctx, &pbModel.GetSmartModelByModelNumberRequest{
+			Manufacturer: *manufacturer,
+			ModelNumber:  *modelNumber,
+			View:         modelView(*full),
+		})
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			candidates = append(candidates, resp.Model)
+		}
+	} else {
+		resp, err := client.ListSmartModels(ctx, &pbModel.ListSmartModelsRequest{View: modelView(*full)})
+		if err != nil {
+			return err
+		}
+		candidates = resp.Models
+	}
+
+	result := &pbModel.ListSmartModelsResponse{}
+	for _, model := range candidates {
+		switch {
+		case *name != "" && !strings.Contains(strings.ToLower(model.Name), strings.ToLower(*name)):
+		case *manufacturer != "" && !strings.EqualFold(model.Manufacturer, *manufacturer):
+		case *modelNumber != "" && !strings.EqualFold(model.ModelNumber, *modelNumber):
+		case typeFilter != nil && model.Type != *typeFilter:
+		case categoryFilter != nil && model.Category != *categoryFilter:
+		default:
+			result.Models = append(result.Models, model)
+		}
+	}
+	return c.print(result, modelTable(*full, result.Models...))
+}
+
+// runSearchFeatures runs ListSmartFeatures. It prints one page unless -all
+// is set, in which case it follows the page tokens to the end.
+func runSearchFeatures(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("search features")
+	namePrefix := flags.String("name-prefix", "", "case-sensitive prefix of the feature name")
+	path := flags.String("path", "", "glob over the interface path, e.g. /sensors/*")
+	protocol := flags.String("protocol", "", "REST, GRPC, MQTT or WEBSOCKET")
+	modelType := flags.String("model-type", "", "type of the model, DEVICE or SERVICE")
+	category := flags.String("model-category", "", "category of the model")
+	pageSize := flags.Int("page-size", 0, "features per page; the server defaults to 50 and caps at 1000")
+	pageToken := flags.String("page-token", "", "next page token printed by the previous search")
+	all := flags.Bool("all", false, "fetch every page")
+	if _, err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	req := &pbFeature.ListSmartFeaturesRequest{
+		PageSize:             int32(*pageSize),
+		PageToken:            *pageToken,
+		NamePrefix:           *namePrefix,
+		InterfacePathPattern: *path,
+	}
+	if *protocol != "" {
+		v, err := parseEnum("protocol", *protocol, pbFeature.ProtocolType_value)
+		if err != nil {
+			return err
+		}
+		req.Protocol = pbFeature.ProtocolType(v).Enum()
+	}
+	if *modelType != "" {
+		v, err := parseEnum("model type", *modelType, pbFeature.ModelType_value)
+		if err != nil {
+			return err
+		}
+		req.ModelType = pbFeature.ModelType(v).Enum()
+	}
+	if *category != "" {
+		v, err := parseEnum("model category", *category, pbFeature.ModelCategory_value)
+		if err != nil {
+			return err
+		}
+		req.ModelCategory = pbFeature.ModelCategory(v).Enum()
+	}
+
+	client := pbFeature.NewSmartFeatureServiceClient(c.conn)
+	result := &pbFeature.ListSmartFeaturesResponse{}
+	for {
+		resp, err := client.ListSmartFeatures(ctx, req)
+		if err != nil {
+			return err
+		}
+		result.Features = append(result.Features, resp.Features...)
+		result.NextPageToken = resp.NextPageToken
+		if !*all || resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+
+	if err := c.print(result, featureTable(result.Features...)); err != nil {
+		return err
+	}
+	if c.output == tableOutput && result.NextPageToken != "" {
+		fmt.Fprintf(c.out, "\nMore results: -page-token %s\n", result.NextPageToken)
+	}
+	return nil
+}
@@ -1,11 +1,26 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
 
 type Config struct {
-	Service  ServiceConfig
-	Log      LogConfig
-	Database DatabaseConfig
+	Service    ServiceConfig
+	Log        LogConfig
+	Database   DatabaseConfig
+	Tracing    TracingConfig
+	Metrics    MetricsConfig
+	Events     EventsConfig
+	Watch      WatchConfig
+	Webhooks   WebhooksConfig
+	Cache      CacheConfig
+	Telemetry  TelemetryConfig
+	Automation AutomationConfig
+	Scheduler  SchedulerConfig
+	Scenes     ScenesConfig
 }
 
 type ServiceConfig struct {
@@ -15,15 +30,170 @@ type ServiceConfig struct {
 }
 
 type LogConfig struct {
-	Level string `split_words:"true" default:"DEBUG"`
+	Level      string   `split_words:"true" default:"DEBUG"`
+	RedactKeys []string `split_words:"true"`
 }
 
+// DatabaseConfig selects the storage backend. Driver is "postgres",
+// "sqlite" or "memory"; the connection settings are only required for
+// Postgres and Path only applies to SQLite. The memory driver keeps
+// everything in process and loses it on restart.
+//
+// AutoMigrate applies pending migrations at startup; turn it off to run
+// "smart-hub migrate" as a separate deployment step instead. ReplicaHosts
+// lists Postgres read replicas as "host" or "host:port"; they share the
+// primary's credentials and database name.
 type DatabaseConfig struct {
-	Host     string `split_words:"true" required:"true"`
-	Port     int    `split_words:"true" required:"true"`
-	User     string `split_words:"true" required:"true"`
-	Password string `split_words:"true" required:"true"`
-	Database string `split_words:"true" required:"true"`
+	Driver               string        `split_words:"true" default:"postgres"`
+	Path                 string        `split_words:"true" default:"smart-hub.db"`
+	Host                 string        `split_words:"true"`
+	Port                 int           `split_words:"true"`
+	User                 string        `split_words:"true"`
+	Password             string        `split_words:"true"`
+	Database             string        `split_words:"true"`
+	AutoMigrate          bool          `split_words:"true" default:"true"`
+	ReplicaHosts         []string      `split_words:"true"`
+	MaxReplicaLag        time.Duration `split_words:"true" default:"5s"`
+	ReplicaCheckInterval time.Duration `split_words:"true" default:"5s"`
+}
+
+// TracingConfig selects the span exporter. Exporter is one of "otlp",
+// "stdout" or "none". The OTLP exporter honours the standard
+// OTEL_EXPORTER_OTLP_* variables when Endpoint is left empty.
+type TracingConfig struct {
+	Exporter    string  `split_words:"true" default:"none"`
+	Endpoint    string  `split_words:"true"`
+	Insecure    bool    `split_words:"true" default:"true"`
+	SampleRatio float64 `split_words:"true" default:"1"`
+}
+
+// MetricsConfig selects the metric exporter, "otlp" or "none". Metrics are
+// pushed every Interval; Endpoint falls back to OTEL_EXPORTER_OTLP_* like the
+// span exporter's.
+type MetricsConfig struct {
+	Exporter string        `split_words:"true" default:"none"`
+	Endpoint string        `split_words:"true"`
+	Insecure bool          `split_words:"true" default:"true"`
+	Interval time.Duration `split_words:"true" default:"1m"`
+}
+
+// EventsConfig selects where the outbox relay publishes domain events. Sink
+// is one of "channel" (in-process subscribers), "nats", "kafka" or "webhook".
+// Nothing in the server subscribes to the channel sink, so with it events
+// only reach webhook subscriptions; the server warns about this at startup.
+// An event is given up after RelayMaxAttempts failed attempts, and a relay
+// holds the events it publishes for RelayLease.
+type EventsConfig struct {
+	Sink             string        `split_words:"true" default:"channel"`
+	RelayInterval    time.Duration `split_words:"true" default:"1s"`
+	RelayBatchSize   int           `split_words:"true" default:"100"`
+	RelayMaxAttempts int           `split_words:"true" default:"10"`
+	RelayLease       time.Duration `split_words:"true" default:"1m"`
+	NatsURL          string        `envconfig:"NATS_URL" default:"nats://localhost:4222"`
+	NatsSubject      string        `split_words:"true" default:"smart-hub"`
+	KafkaBrokers     []string      `split_words:"true" default:"localhost:9092"`
+	KafkaTopic       string        `split_words:"true" default:"smart-hub.events"`
+	WebhookURL       string        `envconfig:"WEBHOOK_URL"`
+	WebhookTimeout   time.Duration `split_words:"true" default:"5s"`
+}
+
+// WatchConfig tunes the Watch streaming RPCs, of the catalog and of shadow
+// deltas alike. BufferSize is how many changes a watcher may lag behind
+// before it is disconnected; changes older than Retention can no longer be
+// resumed from.
+type WatchConfig struct {
+	BufferSize   int           `split_words:"true" default:"256"`
+	PollInterval time.Duration `split_words:"true" default:"5s"`
+	Retention    time.Duration `split_words:"true" default:"24h"`
+}
+
+// WebhooksConfig controls delivery to webhook subscriptions. A failed
+// delivery is retried after InitialBackoff, doubling up to MaxBackoff, and
+// moves to the dead-letter list after MaxAttempts.
+type WebhooksConfig struct {
+	DispatchInterval time.Duration `split_words:"true" default:"1s"`
+	BatchSize        int           `split_words:"true" default:"50"`
+	Timeout          time.Duration `split_words:"true" default:"10s"`
+	MaxAttempts      int           `split_words:"true" default:"8"`
+	InitialBackoff   time.Duration `split_words:"true" default:"10s"`
+	MaxBackoff       time.Duration `split_words:"true" default:"1h"`
+}
+
+// CacheConfig puts a read-through cache in front of model and feature reads.
+// Backend is "none", "memory" (an in-process LRU of Size entries) or "redis",
+// which is shared by all replicas. Entries expire after TTL even if an
+// invalidation is missed.
+type CacheConfig struct {
+	Backend       string        `split_words:"true" default:"none"`
+	Size          int           `split_words:"true" default:"10000"`
+	TTL           time.Duration `split_words:"true" default:"5m"`
+	RedisAddr     string        `split_words:"true" default:"localhost:6379"`
+	RedisPassword string        `split_words:"true"`
+	RedisDB       int           `split_words:"true"`
+	KeyPrefix     string        `split_words:"true" default:"smart-hub"`
+}
+
+// TelemetryConfig controls telemetry storage. Readings of features without a
+// retention policy are kept for Retention; expired readings are removed every
+// RetentionInterval. MaxPoints caps the readings or buckets one query returns,
+// and ingested readings are written in batches of IngestBatchSize.
+type TelemetryConfig struct {
+	Retention         time.Duration `split_words:"true" default:"720h"`
+	RetentionInterval time.Duration `split_words:"true" default:"1h"`
+	MaxPoints         int           `split_words:"true" default:"10000"`
+	IngestBatchSize   int           `split_words:"true" default:"500"`
+}
+
+// AutomationConfig tunes the automation engine. Rules are reloaded every
+// RefreshInterval and right after a change made through this replica. Up to
+// QueueSize telemetry and state signals wait for evaluation before new ones
+// are dropped. Each action gets ActionTimeout, and executions are kept for
+// ExecutionRetention.
+type AutomationConfig struct {
+	RefreshInterval    time.Duration `split_words:"true" default:"30s"`
+	QueueSize          int           `split_words:"true" default:"1024"`
+	ActionTimeout      time.Duration `split_words:"true" default:"10s"`
+	ExecutionRetention time.Duration `split_words:"true" default:"720h"`
+}
+
+// SchedulerConfig tunes the firing of schedules. Due schedules are looked
+// up every TickInterval, each invocation gets RunTimeout, and runs are kept
+// for RunRetention.
+type SchedulerConfig struct {
+	TickInterval time.Duration `split_words:"true" default:"5s"`
+	RunTimeout   time.Duration `split_words:"true" default:"30s"`
+	RunRetention time.Duration `split_words:"true" default:"720h"`
+}
+
+// ScenesConfig bounds the invocation made by each step of a scene
+// activation.
+type ScenesConfig struct {
+	StepTimeout time.Duration `split_words:"true" default:"10s"`
+}
+
+const (
+	PostgresDriver = "postgres"
+	SQLiteDriver   = "sqlite"
+	MemoryDriver   = "memory"
+)
+
+func (d DatabaseConfig) Validate() error {
+	switch d.Driver {
+	case MemoryDriver:
+		return nil
+	case SQLiteDriver:
+		if d.Path == "" {
+			return fmt.Errorf("DATABASE_PATH is required for the %s driver", d.Driver)
+		}
+		return nil
+	case PostgresDriver:
+		if d.Host == "" || d.Port == 0 || d.User == "" || d.Password == "" || d.Database == "" {
+			return fmt.Errorf("DATABASE_HOST, DATABASE_PORT, DATABASE_USER, DATABASE_PASSWORD and DATABASE_DATABASE are required for the %s driver", d.Driver)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown database driver %q", d.Driver)
+	}
 }
 
 func (d DatabaseConfig) GetDSN() string {
@@ -31,7 +201,17 @@ func (d DatabaseConfig) GetDSN() string {
 		d.Host, d.Port, d.User, d.Password, d.Database)
 }
 
-func (d DatabaseConfig) GetURL() string {
-	return fmt.Sprintf("postgresql://%s:%s@%s:%d/%s?sslmode=disable",
-		d.User, d.Password, d.Host, d.Port, d.Database)
+// GetReplicaDSNs returns a DSN for every entry of ReplicaHosts. Hosts
+// without a port use the primary's port.
+func (d DatabaseConfig) GetReplicaDSNs() []string {
+	dsns := make([]string, 0, len(d.ReplicaHosts))
+	for _, host := range d.ReplicaHosts {
+		port := strconv.Itoa(d.Port)
+		if h, p, err := net.SplitHostPort(host); err == nil {
+			host, port = h, p
+		}
+		dsns = append(dsns, fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+			host, port, d.User, d.Password, d.Database))
+	}
+	return dsns
 }
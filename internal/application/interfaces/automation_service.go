@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"smart-hub/internal/domain/models"
+)
+
+type AutomationService interface {
+	CreateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error)
+	GetRule(ctx context.Context, id uuid.UUID) (*models.AutomationRule, error)
+	ListRules(ctx context.Context) ([]*models.AutomationRule, error)
+	UpdateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error)
+	DeleteRule(ctx context.Context, id uuid.UUID) error
+	// ListExecutions returns a page of executions, newest first, of one rule
+	// or of every rule when ruleID is nil, and the next page token.
+	ListExecutions(ctx context.Context, ruleID *uuid.UUID, pageSize int, pageToken string) ([]*models.AutomationExecution, string, error)
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"smart-hub/internal/domain/models"
+)
+
+type CapabilityService interface {
+	// ListCapabilities returns the standard capability library.
+	ListCapabilities(ctx context.Context) []models.Capability
+	GetCapability(ctx context.Context, name string) (models.Capability, error)
+	// SetFeatureCapability declares that a feature implements a standard
+	// capability. A mapping that does not fit the capability schema fails
+	// with models.ErrInvalidCapability.
+	SetFeatureCapability(ctx context.Context, capability *models.FeatureCapability) (*models.FeatureCapability, error)
+	GetFeatureCapability(ctx context.Context, featureID uuid.UUID) (*models.FeatureCapability, error)
+	ClearFeatureCapability(ctx context.Context, featureID uuid.UUID) error
+	// ModelsByCapability returns the models with a feature implementing a
+	// capability, together with those features.
+	ModelsByCapability(ctx context.Context, capability string) ([]*models.CapableModel, error)
+}
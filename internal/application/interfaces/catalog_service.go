@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+)
+
+type CatalogService interface {
+	Import(ctx context.Context, entries []*models.CatalogEntry, opts models.ImportOptions) (*models.ImportReport, error)
+	Export(ctx context.Context, filter models.ExportFilter, send func(*models.CatalogEntry) error) error
+}
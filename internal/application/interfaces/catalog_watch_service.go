@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+)
+
+// CatalogWatchService streams catalog changes to send until ctx is done or
+// the watch fails. An empty resumeToken starts with a snapshot of the
+// matching entities followed by a SYNCED event.
+type CatalogWatchService interface {
+	WatchModels(ctx context.Context, filter models.ModelWatchFilter, resumeToken string, send func(*models.WatchEvent) error) error
+	WatchFeatures(ctx context.Context, filter models.FeatureWatchFilter, resumeToken string, send func(*models.WatchEvent) error) error
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"smart-hub/internal/domain/models"
+)
+
+type RelationshipService interface {
+	CreateRelationship(ctx context.Context, relationship *models.ModelRelationship) (*models.ModelRelationship, error)
+	GetRelationship(ctx context.Context, id uuid.UUID) (*models.ModelRelationship, error)
+	DeleteRelationship(ctx context.Context, id uuid.UUID) error
+	ListRelationships(ctx context.Context, filter models.RelationshipFilter) ([]*models.ModelRelationship, error)
+	// RelatedModels returns the models reachable from query.ModelID, with
+	// their depth and the models themselves.
+	RelatedModels(ctx context.Context, query models.RelationshipQuery) ([]*models.RelatedModel, error)
+}
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"smart-hub/internal/domain/models"
+)
+
+type SceneService interface {
+	CreateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error)
+	GetScene(ctx context.Context, id uuid.UUID) (*models.Scene, error)
+	ListScenes(ctx context.Context) ([]*models.Scene, error)
+	UpdateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error)
+	DeleteScene(ctx context.Context, id uuid.UUID) error
+	// ActivateScene runs the steps of a scene and reports the outcome of
+	// each; failed steps are part of the report, not an error.
+	ActivateScene(ctx context.Context, id uuid.UUID) (*models.SceneActivation, error)
+}
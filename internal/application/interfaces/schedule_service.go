@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"smart-hub/internal/domain/models"
+)
+
+type ScheduleService interface {
+	CreateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error)
+	GetSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error)
+	ListSchedules(ctx context.Context) ([]*models.Schedule, error)
+	UpdateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error)
+	DeleteSchedule(ctx context.Context, id uuid.UUID) error
+	PauseSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error)
+	ResumeSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error)
+	// ListRuns returns a page of runs, newest first, of one schedule or of
+	// every schedule when scheduleID is nil, and the next page token.
+	ListRuns(ctx context.Context, scheduleID *uuid.UUID, pageSize int, pageToken string) ([]*models.ScheduleRun, string, error)
+}
@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+)
+
+type ShadowService interface {
+	GetShadow(ctx context.Context, deviceID string) (*models.DeviceShadow, error)
+	UpdateDesiredState(ctx context.Context, deviceID string, state map[string]interface{}, expectedVersion *int64) (*models.DeviceShadow, error)
+	ReportState(ctx context.Context, deviceID string, state map[string]interface{}, expectedVersion *int64) (*models.DeviceShadow, error)
+	DeleteShadow(ctx context.Context, deviceID string) error
+	// WatchDeltas streams shadow deltas to send until ctx is done or the
+	// watch fails.
+	WatchDeltas(ctx context.Context, deviceIDs []string, resumeToken string, send func(*models.ShadowDeltaEvent) error) error
+}
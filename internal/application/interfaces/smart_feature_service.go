@@ -10,6 +10,23 @@ type SmartFeatureService interface {
 	GetByID(ctx context.Context, id string) (*models.SmartFeature, error)
 	GetWithModelID(ctx context.Context, modelID string) ([]*models.SmartFeature, error)
 	GetAll(ctx context.Context) ([]*models.SmartFeature, error)
+	List(ctx context.Context, filter models.FeatureFilter, pageSize int, pageToken string) ([]*models.SmartFeature, string, error)
 	Update(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error)
 	Delete(ctx context.Context, id string) error
+	// BatchCreate, BatchUpdate and BatchDelete run in one transaction. They
+	// fail as a whole with a *models.BatchItemError unless partial is set,
+	// in which case the failed items are reported in the returned errors,
+	// one per item.
+	BatchCreate(ctx context.Context, features []*models.SmartFeature, partial bool) ([]*models.SmartFeature, []error, error)
+	BatchUpdate(ctx context.Context, features []*models.SmartFeature, partial bool) ([]*models.SmartFeature, []error, error)
+	BatchDelete(ctx context.Context, ids []string, partial bool) ([]error, error)
+	// SetDependencies replaces the features a feature requires and conflicts
+	// with; they must belong to the same model. Declarations that introduce
+	// a requirement cycle or make a feature impossible to enable fail with
+	// models.ErrInvalidFeatureDependency.
+	SetDependencies(ctx context.Context, dependencies *models.FeatureDependencies) (*models.FeatureDependencies, error)
+	GetDependencies(ctx context.Context, id string) (*models.FeatureDependencies, error)
+	// ResolveFeatureSet returns the requested features with everything they
+	// require, dependencies first.
+	ResolveFeatureSet(ctx context.Context, modelID string, featureIDs []string) ([]*models.ResolvedFeature, error)
 }
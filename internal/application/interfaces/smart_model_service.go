@@ -8,7 +8,13 @@ import (
 type SmartModelService interface {
 	Create(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error)
 	GetByID(ctx context.Context, id string) (*models.SmartModel, error)
+	// GetByModelNumber finds a model by manufacturer and model number,
+	// ignoring case.
+	GetByModelNumber(ctx context.Context, manufacturer, modelNumber string) (*models.SmartModel, error)
 	GetAll(ctx context.Context) ([]*models.SmartModel, error)
 	Update(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error)
 	Delete(ctx context.Context, id string) error
+	// ExpandFeatures fills in the Features of the given models.
+	ExpandFeatures(ctx context.Context, smartModels []*models.SmartModel) error
+	BatchGet(ctx context.Context, ids []string, partial bool) ([]*models.SmartModel, []error, error)
 }
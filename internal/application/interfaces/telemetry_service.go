@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TelemetryService interface {
+	Ingest(ctx context.Context, next func() ([]*models.TelemetryReading, error)) (*models.IngestReport, error)
+	Query(ctx context.Context, query models.ReadingQuery, bucket time.Duration) (*models.ReadingSeries, error)
+	SetRetentionPolicy(ctx context.Context, featureID uuid.UUID, retention time.Duration) (*models.RetentionPolicy, error)
+	ListRetentionPolicies(ctx context.Context) ([]*models.RetentionPolicy, time.Duration, error)
+	DeleteRetentionPolicy(ctx context.Context, featureID uuid.UUID) error
+}
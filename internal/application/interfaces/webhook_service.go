@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+}
@@ -0,0 +1,437 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"reflect"
+	"slices"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+const (
+	// scheduleTickInterval is how often schedule triggers are checked; they
+	// have a resolution of one minute.
+	scheduleTickInterval = 10 * time.Second
+	// maxScheduleCatchUp bounds the missed minutes that are still fired
+	// after the engine was blocked or the clock jumped.
+	maxScheduleCatchUp = 5 * time.Minute
+)
+
+// AutomationEngine evaluates the enabled automation rules in process. It
+// observes stored telemetry readings and reported shadow state, checks
+// schedule triggers every few seconds and runs the actions of the rules
+// that fire through the feature invoker.
+//
+// Signals are queued and evaluated one at a time by Run, which owns all of
+// the engine's state. Telemetry and state signals are only seen by the
+// replica that received them; schedule ticks are seen by every replica and
+// claimed through the execution's dedup key, so each runs once. Threshold
+// edges and cooldowns are tracked per replica.
+type AutomationEngine struct {
+	repo            interfaces.AutomationRepository
+	shadowRepo      interfaces.ShadowRepository
+	invoker         interfaces.FeatureInvoker
+	refreshInterval time.Duration
+	actionTimeout   time.Duration
+	retention       time.Duration
+	now             func() time.Time
+
+	signals    chan automationSignal
+	invalidate chan struct{}
+
+	rules      []*compiledRule
+	lastFired  map[uuid.UUID]time.Time
+	lastMinute time.Time
+}
+
+// compiledRule is an enabled rule ready for evaluation. armed holds, per
+// device, whether the last reading satisfied a telemetry threshold.
+type compiledRule struct {
+	rule      *models.AutomationRule
+	condition expression
+	location  *time.Location
+	armed     map[string]bool
+}
+
+// automationSignal is either a batch of readings or a change of the
+// reported state of a device.
+type automationSignal struct {
+	readings []*models.TelemetryReading
+	deviceID string
+	previous map[string]interface{}
+	current  map[string]interface{}
+}
+
+func NewAutomationEngine(
+	repo interfaces.AutomationRepository,
+	shadowRepo interfaces.ShadowRepository,
+	invoker interfaces.FeatureInvoker,
+	queueSize int,
+	refreshInterval time.Duration,
+	actionTimeout time.Duration,
+	retention time.Duration,
+) *AutomationEngine {
+	return &AutomationEngine{
+		repo:            repo,
+		shadowRepo:      shadowRepo,
+		invoker:         invoker,
+		refreshInterval: refreshInterval,
+		actionTimeout:   actionTimeout,
+		retention:       retention,
+		now:             time.Now,
+		signals:         make(chan automationSignal, queueSize),
+		invalidate:      make(chan struct{}, 1),
+		lastFired:       make(map[uuid.UUID]time.Time),
+	}
+}
+
+// ObserveReadings queues readings for the telemetry triggers.
+func (e *AutomationEngine) ObserveReadings(readings []*models.TelemetryReading) {
+	e.enqueue(automationSignal{readings: readings})
+}
+
+// ObserveReportedState queues a reported state change for the state change
+// triggers.
+func (e *AutomationEngine) ObserveReportedState(deviceID string, previous, current map[string]interface{}) {
+	e.enqueue(automationSignal{deviceID: deviceID, previous: previous, current: current})
+}
+
+// enqueue never blocks the ingestion or shadow update that produced the
+// signal; when the queue is full the signal is dropped.
+func (e *AutomationEngine) enqueue(signal automationSignal) {
+	select {
+	case e.signals <- signal:
+	default:
+		logger.Warn("Dropping automation signal, the queue is full", "device_id", signal.deviceID, "readings", len(signal.readings))
+	}
+}
+
+// Invalidate makes Run reload the rules.
+func (e *AutomationEngine) Invalidate() {
+	select {
+	case e.invalidate <- struct{}{}:
+	default:
+	}
+}
+
+// Run evaluates signals and schedules until ctx is cancelled.
+func (e *AutomationEngine) Run(ctx context.Context) {
+	e.reload(ctx)
+	// The current minute is checked too; if another replica already fired
+	// it, the dedup key stops a second run.
+	e.lastMinute = e.now().Truncate(time.Minute).Add(-time.Minute)
+
+	refreshTicker := time.NewTicker(e.refreshInterval)
+	defer refreshTicker.Stop()
+	scheduleTicker := time.NewTicker(scheduleTickInterval)
+	defer scheduleTicker.Stop()
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.invalidate:
+			e.reload(ctx)
+		case <-refreshTicker.C:
+			e.reload(ctx)
+		case <-scheduleTicker.C:
+			e.runSchedules(ctx)
+		case <-pruneTicker.C:
+			e.prune(ctx)
+		case signal := <-e.signals:
+			e.handle(ctx, signal)
+		}
+	}
+}
+
+// reload replaces the rules with the enabled rules in storage. A rule that
+// did not change keeps its threshold state. Rules that no longer compile,
+// e.g. because their timezone went away, are skipped with an error.
+func (e *AutomationEngine) reload(ctx context.Context) {
+	rules, err := e.repo.ListRules(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("Failed to load automation rules", err)
+		}
+		return
+	}
+
+	previous := make(map[uuid.UUID]*compiledRule, len(e.rules))
+	for _, compiled := range e.rules {
+		previous[compiled.rule.ID] = compiled
+	}
+
+	compiled := make([]*compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		if old, ok := previous[rule.ID]; ok && old.rule.UpdatedAt.Equal(rule.UpdatedAt) {
+			compiled = append(compiled, old)
+			continue
+		}
+		c, err := compileRule(rule)
+		if err != nil {
+			logger.Error("Skipping automation rule", err, "rule_id", rule.ID)
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+	e.rules = compiled
+}
+
+func compileRule(rule *models.AutomationRule) (*compiledRule, error) {
+	location, err := time.LoadLocation(rule.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiledRule{rule: rule, location: location, armed: make(map[string]bool)}
+	if rule.Condition != "" {
+		if c.condition, err = parseCondition(rule.Condition); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (e *AutomationEngine) handle(ctx context.Context, signal automationSignal) {
+	if signal.readings != nil {
+		e.handleReadings(ctx, signal.readings)
+		return
+	}
+	e.handleStateChange(ctx, signal.deviceID, signal.previous, signal.current)
+}
+
+// handleReadings fires telemetry triggers on the first reading of a device
+// that satisfies their threshold.
+func (e *AutomationEngine) handleReadings(ctx context.Context, readings []*models.TelemetryReading) {
+	for _, reading := range readings {
+		for _, rule := range e.rules {
+			trigger := rule.rule.Trigger
+			if trigger.Type != models.TriggerTelemetry || trigger.FeatureID != reading.FeatureID || !deviceMatches(trigger.DeviceID, reading.DeviceID) {
+				continue
+			}
+			holds := trigger.Operator.Holds(reading.Value, trigger.Threshold)
+			wasArmed := rule.armed[reading.DeviceID]
+			rule.armed[reading.DeviceID] = holds
+			if !holds || wasArmed {
+				continue
+			}
+			e.fire(ctx, rule, map[string]interface{}{
+				"type":       string(models.TriggerTelemetry),
+				"device_id":  reading.DeviceID,
+				"feature_id": reading.FeatureID.String(),
+				"value":      reading.Value,
+			}, "")
+		}
+	}
+}
+
+// handleStateChange fires state change triggers whose feature got a new
+// reported value. Removing a value does not fire.
+func (e *AutomationEngine) handleStateChange(ctx context.Context, deviceID string, previous, current map[string]interface{}) {
+	for _, rule := range e.rules {
+		trigger := rule.rule.Trigger
+		if trigger.Type != models.TriggerStateChange || !deviceMatches(trigger.DeviceID, deviceID) {
+			continue
+		}
+		key := trigger.FeatureID.String()
+		value, ok := current[key]
+		if !ok {
+			continue
+		}
+		if before, ok := previous[key]; ok && reflect.DeepEqual(before, value) {
+			continue
+		}
+		if trigger.Value != nil && !reflect.DeepEqual(trigger.Value, value) {
+			continue
+		}
+		e.fire(ctx, rule, map[string]interface{}{
+			"type":       string(models.TriggerStateChange),
+			"device_id":  deviceID,
+			"feature_id": key,
+			"value":      value,
+		}, "")
+	}
+}
+
+// runSchedules fires the schedule triggers of every minute since the last
+// check.
+func (e *AutomationEngine) runSchedules(ctx context.Context) {
+	now := e.now().Truncate(time.Minute)
+	from := e.lastMinute.Add(time.Minute)
+	if from.Before(now.Add(-maxScheduleCatchUp)) {
+		from = now.Add(-maxScheduleCatchUp)
+	}
+
+	for minute := from; !minute.After(now); minute = minute.Add(time.Minute) {
+		for _, rule := range e.rules {
+			if rule.rule.Trigger.Type != models.TriggerSchedule || !scheduleDue(rule, minute) {
+				continue
+			}
+			e.fire(ctx, rule, map[string]interface{}{
+				"type": string(models.TriggerSchedule),
+				"at":   minute.In(rule.location).Format(time.RFC3339),
+			}, fmt.Sprintf("%s/%d", rule.rule.ID, minute.Unix()))
+		}
+	}
+	if now.After(e.lastMinute) {
+		e.lastMinute = now
+	}
+}
+
+func scheduleDue(rule *compiledRule, minute time.Time) bool {
+	local := minute.In(rule.location)
+	if local.Format("15:04") != rule.rule.Trigger.At {
+		return false
+	}
+	return len(rule.rule.Trigger.Weekdays) == 0 || slices.Contains(rule.rule.Trigger.Weekdays, local.Weekday())
+}
+
+func deviceMatches(want, deviceID string) bool {
+	return want == "" || want == deviceID
+}
+
+// fire runs the actions of rule unless it is cooling down or its condition
+// does not hold, and records the execution. A condition that fails to
+// evaluate is recorded as a failed execution without running the actions.
+func (e *AutomationEngine) fire(ctx context.Context, rule *compiledRule, trigger map[string]interface{}, dedupKey string) {
+	ctx, span := tracing.StartSpan(ctx, "AutomationEngine.Fire",
+		attribute.String("automation.rule_id", rule.rule.ID.String()),
+		attribute.String("automation.trigger", string(rule.rule.Trigger.Type)),
+	)
+	defer span.End()
+
+	now := e.now()
+	if last, ok := e.lastFired[rule.rule.ID]; ok && now.Sub(last) < rule.rule.Cooldown {
+		logger.FromContext(ctx).Debug("Automation rule is cooling down", "rule_id", rule.rule.ID)
+		return
+	}
+
+	execution := &models.AutomationExecution{
+		ID:        uuid.New(),
+		RuleID:    rule.rule.ID,
+		Trigger:   trigger,
+		DedupKey:  dedupKey,
+		Status:    models.ExecutionRunning,
+		StartedAt: now,
+	}
+
+	if rule.condition != nil {
+		holds, err := evalCondition(rule.condition, e.conditionEnv(ctx, rule, trigger, now))
+		if err != nil {
+			tracing.RecordError(span, err)
+			execution.Status = models.ExecutionFailed
+			execution.Error = "condition: " + err.Error()
+			execution.FinishedAt = &now
+			e.record(ctx, execution)
+			return
+		}
+		if !holds {
+			logger.FromContext(ctx).Debug("Automation rule condition does not hold", "rule_id", rule.rule.ID)
+			return
+		}
+	}
+
+	if !e.record(ctx, execution) {
+		return
+	}
+	e.lastFired[rule.rule.ID] = now
+
+	var failed int
+	for _, action := range rule.rule.Actions {
+		result := models.ActionResult{DeviceID: action.DeviceID, FeatureID: action.FeatureID}
+		if err := e.invoke(ctx, action); err != nil {
+			result.Error = err.Error()
+			failed++
+		}
+		execution.Results = append(execution.Results, result)
+	}
+
+	execution.Status = models.ExecutionSucceeded
+	if failed > 0 {
+		execution.Status = models.ExecutionFailed
+		execution.Error = fmt.Sprintf("%d of %d actions failed", failed, len(rule.rule.Actions))
+		tracing.RecordError(span, errors.New(execution.Error))
+	}
+	finishedAt := e.now()
+	execution.FinishedAt = &finishedAt
+	if err := e.repo.FinishExecution(ctx, execution); err != nil {
+		logger.Error("Failed to record automation execution", err, "rule_id", rule.rule.ID, "execution_id", execution.ID)
+	}
+}
+
+// record stores a new execution and reports whether this replica owns it.
+func (e *AutomationEngine) record(ctx context.Context, execution *models.AutomationExecution) bool {
+	err := e.repo.CreateExecution(ctx, execution)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, models.ErrAlreadyExists):
+		logger.FromContext(ctx).Debug("Automation execution already claimed", "rule_id", execution.RuleID, "dedup_key", execution.DedupKey)
+	case errors.Is(err, models.ErrNotFound):
+		logger.FromContext(ctx).Debug("Automation rule was deleted before it ran", "rule_id", execution.RuleID)
+	default:
+		logger.Error("Failed to record automation execution", err, "rule_id", execution.RuleID)
+	}
+	return false
+}
+
+func (e *AutomationEngine) invoke(ctx context.Context, action models.RuleAction) error {
+	ctx, cancel := context.WithTimeout(ctx, e.actionTimeout)
+	defer cancel()
+	return e.invoker.InvokeFeature(ctx, action.DeviceID, action.FeatureID, action.Parameters)
+}
+
+// conditionEnv exposes the time in the rule's timezone, the trigger and the
+// reported device state, read at most once per device.
+func (e *AutomationEngine) conditionEnv(ctx context.Context, rule *compiledRule, trigger map[string]interface{}, now time.Time) *conditionEnv {
+	local := now.In(rule.location)
+	shadows := make(map[string]*models.DeviceShadow)
+	return &conditionEnv{
+		vars: map[string]interface{}{
+			"hour":    float64(local.Hour()),
+			"minute":  float64(local.Minute()),
+			"weekday": float64(local.Weekday()),
+			"time":    local.Format("15:04"),
+			"trigger": trigger,
+		},
+		state: func(deviceID, featureID string) (interface{}, error) {
+			shadow, ok := shadows[deviceID]
+			if !ok {
+				var err error
+				shadow, err = e.shadowRepo.GetShadow(ctx, deviceID)
+				if errors.Is(err, models.ErrNotFound) {
+					shadow = nil
+				} else if err != nil {
+					return nil, err
+				}
+				shadows[deviceID] = shadow
+			}
+			if shadow == nil {
+				return nil, nil
+			}
+			return shadow.Reported[featureID], nil
+		},
+	}
+}
+
+func (e *AutomationEngine) prune(ctx context.Context) {
+	pruned, err := e.repo.PruneExecutions(ctx, e.now().Add(-e.retention))
+	if err != nil {
+		logger.Error("Failed to prune automation executions", err)
+		return
+	}
+	if pruned > 0 {
+		logger.Debug("Pruned automation executions", "count", pruned)
+	}
+}
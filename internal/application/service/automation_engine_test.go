@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeAutomationRepo struct {
+	mu         sync.Mutex
+	rules      []*models.AutomationRule
+	executions []*models.AutomationExecution
+}
+
+func (r *fakeAutomationRepo) CreateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = append(r.rules, rule)
+	return rule, nil
+}
+
+func (r *fakeAutomationRepo) GetRule(ctx context.Context, id uuid.UUID) (*models.AutomationRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rule := range r.rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *fakeAutomationRepo) ListRules(ctx context.Context) ([]*models.AutomationRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*models.AutomationRule(nil), r.rules...), nil
+}
+
+func (r *fakeAutomationRepo) UpdateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.rules {
+		if existing.ID == rule.ID {
+			r.rules[i] = rule
+			return rule, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *fakeAutomationRepo) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, rule := range r.rules {
+		if rule.ID == id {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (r *fakeAutomationRepo) CreateExecution(ctx context.Context, execution *models.AutomationExecution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.executions {
+		if execution.DedupKey != "" && existing.DedupKey == execution.DedupKey {
+			return models.ErrAlreadyExists
+		}
+	}
+	clone := *execution
+	r.executions = append(r.executions, &clone)
+	return nil
+}
+
+func (r *fakeAutomationRepo) FinishExecution(ctx context.Context, execution *models.AutomationExecution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.executions {
+		if existing.ID == execution.ID {
+			clone := *execution
+			r.executions[i] = &clone
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (r *fakeAutomationRepo) ListExecutions(ctx context.Context, filter models.ExecutionFilter) ([]*models.AutomationExecution, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*models.AutomationExecution(nil), r.executions...), nil
+}
+
+func (r *fakeAutomationRepo) PruneExecutions(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeAutomationRepo) recorded() []*models.AutomationExecution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*models.AutomationExecution(nil), r.executions...)
+}
+
+type invocation struct {
+	deviceID   string
+	featureID  uuid.UUID
+	parameters map[string]interface{}
+}
+
+type fakeInvoker struct {
+	invocations []invocation
+	fail        map[string]error
+}
+
+func (i *fakeInvoker) InvokeFeature(ctx context.Context, deviceID string, featureID uuid.UUID, parameters map[string]interface{}) error {
+	i.invocations = append(i.invocations, invocation{deviceID, featureID, parameters})
+	return i.fail[deviceID]
+}
+
+var automationNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestAutomationEngine(repo *fakeAutomationRepo, shadowRepo *fakeShadowRepo, invoker *fakeInvoker, rules ...*models.AutomationRule) *AutomationEngine {
+	for _, rule := range rules {
+		repo.rules = append(repo.rules, rule)
+	}
+	engine := NewAutomationEngine(repo, shadowRepo, invoker, 16, time.Hour, time.Second, time.Hour)
+	engine.now = func() time.Time { return automationNow }
+	engine.reload(context.Background())
+	return engine
+}
+
+func newTestRule(trigger models.RuleTrigger) *models.AutomationRule {
+	return &models.AutomationRule{
+		ID:       uuid.New(),
+		Name:     "Porch light",
+		Enabled:  true,
+		Trigger:  trigger,
+		Timezone: "UTC",
+		Actions: []models.RuleAction{
+			{DeviceID: "light-1", FeatureID: uuid.New(), Parameters: map[string]interface{}{"on": true}},
+		},
+		UpdatedAt: automationNow,
+	}
+}
+
+func TestAutomationEngine_TelemetryThresholdIsEdgeTriggered(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	invoker := &fakeInvoker{}
+	featureID := uuid.New()
+	rule := newTestRule(models.RuleTrigger{Type: models.TriggerTelemetry, FeatureID: featureID, Operator: models.OperatorGreater, Threshold: 40})
+	engine := newTestAutomationEngine(repo, newFakeShadowRepo(), invoker, rule)
+	ctx := context.Background()
+
+	reading := func(deviceID string, value float64) *models.TelemetryReading {
+		return &models.TelemetryReading{DeviceID: deviceID, FeatureID: featureID, Value: value}
+	}
+	engine.handleReadings(ctx, []*models.TelemetryReading{
+		reading("sensor-1", 30),
+		reading("sensor-1", 45),
+		reading("sensor-1", 50),
+		reading("sensor-2", 41),
+		{DeviceID: "sensor-1", FeatureID: uuid.New(), Value: 99},
+	})
+	// Dropping below the threshold re-arms the trigger.
+	engine.handleReadings(ctx, []*models.TelemetryReading{reading("sensor-1", 35), reading("sensor-1", 42)})
+
+	executions := repo.recorded()
+	require.Len(t, executions, 3)
+	assert.Equal(t, "sensor-1", executions[0].Trigger["device_id"])
+	assert.Equal(t, float64(45), executions[0].Trigger["value"])
+	assert.Equal(t, "sensor-2", executions[1].Trigger["device_id"])
+	assert.Equal(t, float64(42), executions[2].Trigger["value"])
+	for _, execution := range executions {
+		assert.Equal(t, models.ExecutionSucceeded, execution.Status)
+		assert.Equal(t, rule.ID, execution.RuleID)
+		require.NotNil(t, execution.FinishedAt)
+	}
+	require.Len(t, invoker.invocations, 3)
+	assert.Equal(t, invocation{"light-1", rule.Actions[0].FeatureID, map[string]interface{}{"on": true}}, invoker.invocations[0])
+}
+
+func TestAutomationEngine_Cooldown(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	invoker := &fakeInvoker{}
+	featureID := uuid.New()
+	rule := newTestRule(models.RuleTrigger{Type: models.TriggerStateChange, FeatureID: featureID})
+	rule.Cooldown = time.Minute
+	engine := newTestAutomationEngine(repo, newFakeShadowRepo(), invoker, rule)
+	ctx := context.Background()
+	key := featureID.String()
+
+	engine.handleStateChange(ctx, "camera-1", nil, map[string]interface{}{key: true})
+	engine.now = func() time.Time { return automationNow.Add(30 * time.Second) }
+	engine.handleStateChange(ctx, "camera-1", map[string]interface{}{key: true}, map[string]interface{}{key: false})
+	engine.now = func() time.Time { return automationNow.Add(time.Minute) }
+	engine.handleStateChange(ctx, "camera-1", map[string]interface{}{key: false}, map[string]interface{}{key: true})
+
+	executions := repo.recorded()
+	require.Len(t, executions, 2)
+	assert.Equal(t, true, executions[0].Trigger["value"])
+	assert.Equal(t, true, executions[1].Trigger["value"])
+}
+
+func TestAutomationEngine_StateChange(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	featureID := uuid.New()
+	rule := newTestRule(models.RuleTrigger{Type: models.TriggerStateChange, FeatureID: featureID, DeviceID: "camera-1", Value: "motion"})
+	engine := newTestAutomationEngine(repo, newFakeShadowRepo(), &fakeInvoker{}, rule)
+	ctx := context.Background()
+	key := featureID.String()
+
+	engine.handleStateChange(ctx, "camera-2", nil, map[string]interface{}{key: "motion"})
+	engine.handleStateChange(ctx, "camera-1", nil, map[string]interface{}{key: "idle"})
+	engine.handleStateChange(ctx, "camera-1", map[string]interface{}{key: "idle"}, map[string]interface{}{})
+	engine.handleStateChange(ctx, "camera-1", map[string]interface{}{key: "idle"}, map[string]interface{}{key: "motion"})
+	// Another feature changing leaves the value as it was.
+	engine.handleStateChange(ctx, "camera-1", map[string]interface{}{key: "motion"}, map[string]interface{}{key: "motion", "other": 1.0})
+
+	executions := repo.recorded()
+	require.Len(t, executions, 1)
+	assert.Equal(t, map[string]interface{}{
+		"type":       "state_change",
+		"device_id":  "camera-1",
+		"feature_id": key,
+		"value":      "motion",
+	}, executions[0].Trigger)
+}
+
+func TestAutomationEngine_ScheduleRunsOncePerMinuteAcrossReplicas(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	berlin := newTestRule(models.RuleTrigger{Type: models.TriggerSchedule, At: "13:00", Weekdays: []time.Weekday{time.Friday}})
+	berlin.Timezone = "Europe/Berlin"
+	saturday := newTestRule(models.RuleTrigger{Type: models.TriggerSchedule, At: "13:00", Weekdays: []time.Weekday{time.Saturday}})
+	saturday.Timezone = "Europe/Berlin"
+	first := newTestAutomationEngine(repo, newFakeShadowRepo(), &fakeInvoker{}, berlin, saturday)
+	second := newTestAutomationEngine(repo, newFakeShadowRepo(), &fakeInvoker{})
+	ctx := context.Background()
+
+	// 12:00 UTC is 13:00 in Berlin on Friday, March 1st 2024.
+	for _, engine := range []*AutomationEngine{first, second} {
+		engine.lastMinute = automationNow.Add(-time.Minute)
+		engine.runSchedules(ctx)
+		engine.runSchedules(ctx)
+	}
+
+	executions := repo.recorded()
+	require.Len(t, executions, 1)
+	assert.Equal(t, berlin.ID, executions[0].RuleID)
+	assert.Equal(t, "2024-03-01T13:00:00+01:00", executions[0].Trigger["at"])
+}
+
+func TestAutomationEngine_ScheduleCatchUpIsBounded(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	missed := newTestRule(models.RuleTrigger{Type: models.TriggerSchedule, At: "11:50"})
+	recent := newTestRule(models.RuleTrigger{Type: models.TriggerSchedule, At: "11:57"})
+	engine := newTestAutomationEngine(repo, newFakeShadowRepo(), &fakeInvoker{}, missed, recent)
+	engine.lastMinute = automationNow.Add(-time.Hour)
+
+	engine.runSchedules(context.Background())
+
+	executions := repo.recorded()
+	require.Len(t, executions, 1)
+	assert.Equal(t, recent.ID, executions[0].RuleID)
+	assert.Equal(t, automationNow, engine.lastMinute)
+}
+
+func TestAutomationEngine_Condition(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	shadowRepo := newFakeShadowRepo()
+	featureID := uuid.New()
+	powerID := uuid.New()
+	shadowRepo.shadows["light-1"] = &models.DeviceShadow{
+		DeviceID: "light-1",
+		Reported: map[string]interface{}{powerID.String(): map[string]interface{}{"on": false}},
+	}
+	trigger := models.RuleTrigger{Type: models.TriggerStateChange, FeatureID: featureID}
+	off := newTestRule(trigger)
+	off.Condition = `state("light-1", "` + powerID.String() + `").on == false && hour == 12`
+	on := newTestRule(trigger)
+	on.Condition = `state("light-1", "` + powerID.String() + `").on == true`
+	broken := newTestRule(trigger)
+	broken.Condition = `trigger.value > 1`
+	invoker := &fakeInvoker{}
+	engine := newTestAutomationEngine(repo, shadowRepo, invoker, off, on, broken)
+
+	engine.handleStateChange(context.Background(), "camera-1", nil, map[string]interface{}{featureID.String(): "motion"})
+
+	executions := repo.recorded()
+	require.Len(t, executions, 2)
+	assert.Equal(t, off.ID, executions[0].RuleID)
+	assert.Equal(t, models.ExecutionSucceeded, executions[0].Status)
+	assert.Equal(t, broken.ID, executions[1].RuleID)
+	assert.Equal(t, models.ExecutionFailed, executions[1].Status)
+	assert.Equal(t, "condition: cannot compare string > number", executions[1].Error)
+	assert.Len(t, invoker.invocations, 1)
+}
+
+func TestAutomationEngine_FailedActions(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	featureID := uuid.New()
+	rule := newTestRule(models.RuleTrigger{Type: models.TriggerStateChange, FeatureID: featureID})
+	rule.Actions = append(rule.Actions, models.RuleAction{DeviceID: "light-2", FeatureID: rule.Actions[0].FeatureID})
+	invoker := &fakeInvoker{fail: map[string]error{"light-2": errors.New("device offline")}}
+	engine := newTestAutomationEngine(repo, newFakeShadowRepo(), invoker, rule)
+
+	engine.handleStateChange(context.Background(), "camera-1", nil, map[string]interface{}{featureID.String(): true})
+
+	executions := repo.recorded()
+	require.Len(t, executions, 1)
+	assert.Equal(t, models.ExecutionFailed, executions[0].Status)
+	assert.Equal(t, "1 of 2 actions failed", executions[0].Error)
+	assert.Equal(t, []models.ActionResult{
+		{DeviceID: "light-1", FeatureID: rule.Actions[0].FeatureID},
+		{DeviceID: "light-2", FeatureID: rule.Actions[0].FeatureID, Error: "device offline"},
+	}, executions[0].Results)
+	assert.Len(t, invoker.invocations, 2)
+}
+
+func TestAutomationEngine_ReloadKeepsUnchangedRules(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	featureID := uuid.New()
+	rule := newTestRule(models.RuleTrigger{Type: models.TriggerTelemetry, FeatureID: featureID, Operator: models.OperatorGreater, Threshold: 40})
+	disabled := newTestRule(rule.Trigger)
+	disabled.Enabled = false
+	engine := newTestAutomationEngine(repo, newFakeShadowRepo(), &fakeInvoker{}, rule, disabled)
+	ctx := context.Background()
+	reading := &models.TelemetryReading{DeviceID: "sensor-1", FeatureID: featureID, Value: 45}
+
+	engine.handleReadings(ctx, []*models.TelemetryReading{reading})
+	engine.reload(ctx)
+	engine.handleReadings(ctx, []*models.TelemetryReading{reading})
+	assert.Len(t, repo.recorded(), 1)
+
+	// An updated rule starts over.
+	updated := *rule
+	updated.UpdatedAt = automationNow.Add(time.Second)
+	_, err := repo.UpdateRule(ctx, &updated)
+	require.NoError(t, err)
+	engine.reload(ctx)
+	engine.handleReadings(ctx, []*models.TelemetryReading{reading})
+	assert.Len(t, repo.recorded(), 2)
+}
+
+func TestAutomationEngine_Run(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	featureID := uuid.New()
+	invoker := &fakeInvoker{}
+	engine := newTestAutomationEngine(repo, newFakeShadowRepo(), invoker)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		engine.Run(ctx)
+		close(done)
+	}()
+
+	rule := newTestRule(models.RuleTrigger{Type: models.TriggerStateChange, FeatureID: featureID})
+	_, err := repo.CreateRule(ctx, rule)
+	require.NoError(t, err)
+	engine.Invalidate()
+	require.Eventually(t, func() bool {
+		engine.ObserveReportedState("camera-1", nil, map[string]interface{}{featureID.String(): true})
+		return len(repo.recorded()) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
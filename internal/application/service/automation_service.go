@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"regexp"
+	"slices"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+const (
+	maxRuleNameLength        = 255
+	maxRuleDescriptionLength = 1000
+	maxRuleActions           = 20
+)
+
+var scheduleTimePattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// AutomationService manages automation rules and their execution history.
+// Rules are evaluated by the AutomationEngine, which is told to reload them
+// after every change.
+type AutomationService struct {
+	repo        interfaces.AutomationRepository
+	featureRepo interfaces.SmartFeatureRepository
+	engine      *AutomationEngine
+	now         func() time.Time
+}
+
+func NewAutomationService(
+	repo interfaces.AutomationRepository,
+	featureRepo interfaces.SmartFeatureRepository,
+	engine *AutomationEngine,
+) *AutomationService {
+	return &AutomationService{
+		repo:        repo,
+		featureRepo: featureRepo,
+		engine:      engine,
+		now:         time.Now,
+	}
+}
+
+// CreateRule validates and stores a new rule. The timezone defaults to UTC.
+func (s *AutomationService) CreateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	ctx, span := tracing.StartSpan(ctx, "AutomationService.CreateRule", attribute.String("automation.trigger", string(rule.Trigger.Type)))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Create automation rule", "name", rule.Name, "trigger", rule.Trigger.Type)
+
+	if err := s.validateRule(ctx, rule); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+	now := s.now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	created, err := s.repo.CreateRule(ctx, rule)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	s.engine.Invalidate()
+	return created, nil
+}
+
+func (s *AutomationService) GetRule(ctx context.Context, id uuid.UUID) (*models.AutomationRule, error) {
+	ctx, span := tracing.StartSpan(ctx, "AutomationService.GetRule", attribute.String("automation.rule_id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get automation rule", "id", id)
+	rule, err := s.repo.GetRule(ctx, id)
+	tracing.RecordError(span, err)
+	return rule, err
+}
+
+func (s *AutomationService) ListRules(ctx context.Context) ([]*models.AutomationRule, error) {
+	ctx, span := tracing.StartSpan(ctx, "AutomationService.ListRules")
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("List automation rules")
+	rules, err := s.repo.ListRules(ctx)
+	tracing.RecordError(span, err)
+	return rules, err
+}
+
+// UpdateRule replaces everything but the ID and creation time of a rule.
+func (s *AutomationService) UpdateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	ctx, span := tracing.StartSpan(ctx, "AutomationService.UpdateRule", attribute.String("automation.rule_id", rule.ID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Update automation rule", "id", rule.ID, "name", rule.Name)
+
+	if err := s.validateRule(ctx, rule); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	rule.UpdatedAt = s.now()
+
+	updated, err := s.repo.UpdateRule(ctx, rule)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	s.engine.Invalidate()
+	return updated, nil
+}
+
+// DeleteRule removes a rule and its execution history.
+func (s *AutomationService) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.StartSpan(ctx, "AutomationService.DeleteRule", attribute.String("automation.rule_id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Delete automation rule", "id", id)
+	if err := s.repo.DeleteRule(ctx, id); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	s.engine.Invalidate()
+	return nil
+}
+
+// ListExecutions returns a page of executions, newest first, of the rule
+// ruleID or of every rule when it is nil, and the token of the next page,
+// which is empty on the last one.
+func (s *AutomationService) ListExecutions(ctx context.Context, ruleID *uuid.UUID, pageSize int, pageToken string) ([]*models.AutomationExecution, string, error) {
+	ctx, span := tracing.StartSpan(ctx, "AutomationService.ListExecutions", attribute.Int("page.size", pageSize))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("List automation executions", "rule_id", ruleID, "pageSize", pageSize)
+
+	after, err := models.ParsePageToken(pageToken)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, "", err
+	}
+	if ruleID != nil {
+		if _, err := s.repo.GetRule(ctx, *ruleID); err != nil {
+			tracing.RecordError(span, err)
+			return nil, "", err
+		}
+	}
+
+	executions, err := s.repo.ListExecutions(ctx, models.ExecutionFilter{RuleID: ruleID, After: after, Limit: pageSize + 1})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, "", err
+	}
+	if len(executions) <= pageSize {
+		return executions, "", nil
+	}
+	executions = executions[:pageSize]
+	last := executions[pageSize-1]
+	return executions, models.PageCursor{CreatedAt: last.StartedAt, ID: last.ID}.Token(), nil
+}
+
+// validateRule checks a rule and fills in its default timezone. Every
+// feature the rule refers to must exist.
+func (s *AutomationService) validateRule(ctx context.Context, rule *models.AutomationRule) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", models.ErrInvalidAutomationRule, fmt.Sprintf(format, args...))
+	}
+
+	if rule.Timezone == "" {
+		rule.Timezone = "UTC"
+	}
+	switch {
+	case strings.TrimSpace(rule.Name) == "" || len(rule.Name) > maxRuleNameLength:
+		return invalid("name must be 1 to %d characters", maxRuleNameLength)
+	case len(rule.Description) > maxRuleDescriptionLength:
+		return invalid("description is longer than %d characters", maxRuleDescriptionLength)
+	case rule.Cooldown < 0 || rule.Cooldown%time.Second != 0:
+		return invalid("cooldown must be a non-negative whole number of seconds")
+	case len(rule.Actions) == 0 || len(rule.Actions) > maxRuleActions:
+		return invalid("a rule needs 1 to %d actions", maxRuleActions)
+	}
+	if _, err := time.LoadLocation(rule.Timezone); err != nil {
+		return invalid("unknown timezone %q", rule.Timezone)
+	}
+	if err := validateTrigger(rule.Trigger); err != nil {
+		return invalid("trigger: %s", err)
+	}
+	if rule.Condition != "" {
+		if _, err := parseCondition(rule.Condition); err != nil {
+			return invalid("condition: %s", err)
+		}
+	}
+
+	var featureIDs []string
+	if rule.Trigger.FeatureID != uuid.Nil {
+		featureIDs = append(featureIDs, rule.Trigger.FeatureID.String())
+	}
+	for i, action := range rule.Actions {
+		switch {
+		case action.DeviceID == "" || len(action.DeviceID) > maxDeviceIDLength:
+			return invalid("action %d: device_id must be 1 to %d characters", i, maxDeviceIDLength)
+		case action.FeatureID == uuid.Nil:
+			return invalid("action %d: feature_id is required", i)
+		}
+		featureIDs = append(featureIDs, action.FeatureID.String())
+	}
+
+	missing, err := missingFeatures(ctx, s.featureRepo, featureIDs)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return invalid("unknown smart features: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func validateTrigger(trigger models.RuleTrigger) error {
+	if len(trigger.DeviceID) > maxDeviceIDLength {
+		return fmt.Errorf("device_id is longer than %d characters", maxDeviceIDLength)
+	}
+
+	switch trigger.Type {
+	case models.TriggerTelemetry:
+		if trigger.FeatureID == uuid.Nil {
+			return fmt.Errorf("feature_id is required")
+		}
+		if !slices.Contains([]models.ThresholdOperator{
+			models.OperatorGreater, models.OperatorGreaterEqual, models.OperatorLess,
+			models.OperatorLessEqual, models.OperatorEqual, models.OperatorNotEqual,
+		}, trigger.Operator) {
+			return fmt.Errorf("unknown operator %q", trigger.Operator)
+		}
+	case models.TriggerStateChange:
+		if trigger.FeatureID == uuid.Nil {
+			return fmt.Errorf("feature_id is required")
+		}
+	case models.TriggerSchedule:
+		if !scheduleTimePattern.MatchString(trigger.At) {
+			return fmt.Errorf("at must be a time of day as HH:MM")
+		}
+		for _, day := range trigger.Weekdays {
+			if day < time.Sunday || day > time.Saturday {
+				return fmt.Errorf("weekdays must be 0 (Sunday) to 6")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown type %q", trigger.Type)
+	}
+	return nil
+}
+
+// missingFeatures returns the IDs in ids, sorted and without duplicates, of
+// which there is no smart feature.
+func missingFeatures(ctx context.Context, repo interfaces.SmartFeatureRepository, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	features, err := repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]struct{}, len(features))
+	for _, feature := range features {
+		found[feature.ID.String()] = struct{}{}
+	}
+	var missing []string
+	for _, id := range ids {
+		if _, ok := found[id]; !ok && !slices.Contains(missing, id) {
+			missing = append(missing, id)
+		}
+	}
+	slices.Sort(missing)
+	return missing, nil
+}
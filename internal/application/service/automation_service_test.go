@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestAutomationService(repo *fakeAutomationRepo, featureRepo *mockSmartFeatureRepo) *AutomationService {
+	engine := NewAutomationEngine(repo, newFakeShadowRepo(), &fakeInvoker{}, 16, time.Hour, time.Second, time.Hour)
+	svc := NewAutomationService(repo, featureRepo, engine)
+	svc.now = func() time.Time { return automationNow }
+	return svc
+}
+
+func TestAutomationService_CreateRule(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	featureRepo := new(mockSmartFeatureRepo)
+	svc := newTestAutomationService(repo, featureRepo)
+	rule := newTestRule(models.RuleTrigger{Type: models.TriggerStateChange, FeatureID: uuid.New()})
+	rule.ID = uuid.Nil
+	rule.Timezone = ""
+	expectFeatures(featureRepo, rule.Trigger.FeatureID, rule.Actions[0].FeatureID)
+
+	created, err := svc.CreateRule(context.Background(), rule)
+
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, created.ID)
+	assert.Equal(t, "UTC", created.Timezone)
+	assert.Equal(t, automationNow, created.CreatedAt)
+	assert.Equal(t, automationNow, created.UpdatedAt)
+	assert.Len(t, svc.engine.invalidate, 1, "the engine reloads the rules")
+	featureRepo.AssertExpectations(t)
+}
+
+func TestAutomationService_CreateRule_Invalid(t *testing.T) {
+	featureRepo := new(mockSmartFeatureRepo)
+	known := uuid.New()
+	unknown := uuid.New()
+	featureRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{{ID: known}}, nil)
+	svc := newTestAutomationService(&fakeAutomationRepo{}, featureRepo)
+
+	valid := func() *models.AutomationRule {
+		rule := newTestRule(models.RuleTrigger{Type: models.TriggerTelemetry, FeatureID: known, Operator: models.OperatorLess, Threshold: 10})
+		rule.Actions[0].FeatureID = known
+		return rule
+	}
+	tests := []struct {
+		name    string
+		modify  func(rule *models.AutomationRule)
+		message string
+	}{
+		{"missing name", func(r *models.AutomationRule) { r.Name = " " }, "name must be 1 to 255 characters"},
+		{"long description", func(r *models.AutomationRule) { r.Description = strings.Repeat("x", 1001) }, "description is longer than 1000 characters"},
+		{"negative cooldown", func(r *models.AutomationRule) { r.Cooldown = -time.Second }, "cooldown"},
+		{"fractional cooldown", func(r *models.AutomationRule) { r.Cooldown = 1500 * time.Millisecond }, "cooldown"},
+		{"unknown timezone", func(r *models.AutomationRule) { r.Timezone = "Mars/Olympus" }, `unknown timezone "Mars/Olympus"`},
+		{"no actions", func(r *models.AutomationRule) { r.Actions = nil }, "a rule needs 1 to 20 actions"},
+		{"unknown trigger", func(r *models.AutomationRule) { r.Trigger.Type = "webhook" }, `trigger: unknown type "webhook"`},
+		{"unknown operator", func(r *models.AutomationRule) { r.Trigger.Operator = "between" }, `trigger: unknown operator "between"`},
+		{"threshold without feature", func(r *models.AutomationRule) { r.Trigger.FeatureID = uuid.Nil }, "trigger: feature_id is required"},
+		{"schedule time", func(r *models.AutomationRule) {
+			r.Trigger = models.RuleTrigger{Type: models.TriggerSchedule, At: "24:00"}
+		}, "trigger: at must be a time of day as HH:MM"},
+		{"schedule weekday", func(r *models.AutomationRule) {
+			r.Trigger = models.RuleTrigger{Type: models.TriggerSchedule, At: "07:30", Weekdays: []time.Weekday{7}}
+		}, "trigger: weekdays must be 0 (Sunday) to 6"},
+		{"condition", func(r *models.AutomationRule) { r.Condition = "hour >" }, "condition: unexpected end of condition"},
+		{"action device", func(r *models.AutomationRule) { r.Actions[0].DeviceID = "" }, "action 0: device_id must be 1 to 255 characters"},
+		{"action feature", func(r *models.AutomationRule) { r.Actions[0].FeatureID = uuid.Nil }, "action 0: feature_id is required"},
+		{"unknown feature", func(r *models.AutomationRule) { r.Actions[0].FeatureID = unknown }, "unknown smart features: " + unknown.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := valid()
+			tt.modify(rule)
+
+			_, err := svc.CreateRule(context.Background(), rule)
+
+			assert.ErrorIs(t, err, models.ErrInvalidAutomationRule)
+			assert.ErrorContains(t, err, tt.message)
+		})
+	}
+	assert.Empty(t, svc.engine.invalidate)
+}
+
+func TestAutomationService_UpdateAndDeleteRule(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	featureRepo := new(mockSmartFeatureRepo)
+	svc := newTestAutomationService(repo, featureRepo)
+	rule := newTestRule(models.RuleTrigger{Type: models.TriggerSchedule, At: "07:30"})
+	expectFeatures(featureRepo, rule.Actions[0].FeatureID)
+	repo.rules = append(repo.rules, rule)
+	ctx := context.Background()
+
+	update := *rule
+	update.Name = "Morning blinds"
+	svc.now = func() time.Time { return automationNow.Add(time.Hour) }
+	updated, err := svc.UpdateRule(ctx, &update)
+	require.NoError(t, err)
+	assert.Equal(t, "Morning blinds", updated.Name)
+	assert.Equal(t, automationNow.Add(time.Hour), updated.UpdatedAt)
+	<-svc.engine.invalidate
+
+	missing := *rule
+	missing.ID = uuid.New()
+	_, err = svc.UpdateRule(ctx, &missing)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.Empty(t, svc.engine.invalidate)
+
+	require.NoError(t, svc.DeleteRule(ctx, rule.ID))
+	assert.Len(t, svc.engine.invalidate, 1)
+	assert.ErrorIs(t, svc.DeleteRule(ctx, rule.ID), models.ErrNotFound)
+}
+
+func TestAutomationService_ListExecutions(t *testing.T) {
+	repo := &fakeAutomationRepo{}
+	svc := newTestAutomationService(repo, new(mockSmartFeatureRepo))
+	rule := newTestRule(models.RuleTrigger{Type: models.TriggerSchedule, At: "07:30"})
+	repo.rules = append(repo.rules, rule)
+	for i := 0; i < 3; i++ {
+		repo.executions = append(repo.executions, &models.AutomationExecution{
+			ID:        uuid.New(),
+			RuleID:    rule.ID,
+			StartedAt: automationNow.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	ctx := context.Background()
+
+	page, token, err := svc.ListExecutions(ctx, &rule.ID, 2, "")
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	cursor, err := models.ParsePageToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, repo.executions[1].ID, cursor.ID)
+	assert.True(t, cursor.CreatedAt.Equal(repo.executions[1].StartedAt))
+
+	page, token, err = svc.ListExecutions(ctx, nil, 3, "")
+	require.NoError(t, err)
+	assert.Len(t, page, 3)
+	assert.Empty(t, token)
+
+	missing := uuid.New()
+	_, _, err = svc.ListExecutions(ctx, &missing, 2, "")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	_, _, err = svc.ListExecutions(ctx, nil, 2, "not a token")
+	assert.ErrorIs(t, err, models.ErrInvalidPageToken)
+}
@@ -0,0 +1,31 @@
+package service
+
+import "smart-hub/internal/domain/models"
+
+// splitBatch checks the n items of a batch and returns the indexes of those
+// that passed. An item that fails check ends an all-or-nothing batch with a
+// *models.BatchItemError; in a partial batch it is skipped and its error
+// reported at its index in itemErrs.
+func splitBatch(n int, partial bool, check func(i int) error) (pending []int, itemErrs []error, err error) {
+	itemErrs = make([]error, n)
+	pending = make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if err := check(i); err != nil {
+			if !partial {
+				return nil, nil, &models.BatchItemError{Index: i, Err: err}
+			}
+			itemErrs[i] = err
+			continue
+		}
+		pending = append(pending, i)
+	}
+	return pending, itemErrs, nil
+}
+
+func pick[T any](items []T, indexes []int) []T {
+	picked := make([]T, len(indexes))
+	for j, i := range indexes {
+		picked[j] = items[i]
+	}
+	return picked
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"maps"
+	"slices"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"strings"
+)
+
+// CapabilityService exposes the standard capability library and records
+// which features implement its capabilities.
+type CapabilityService struct {
+	repo        interfaces.FeatureCapabilityRepository
+	featureRepo interfaces.SmartFeatureRepository
+	modelRepo   interfaces.SmartModelRepository
+	uow         interfaces.UnitOfWork
+}
+
+func NewCapabilityService(
+	repo interfaces.FeatureCapabilityRepository,
+	featureRepo interfaces.SmartFeatureRepository,
+	modelRepo interfaces.SmartModelRepository,
+	uow interfaces.UnitOfWork,
+) *CapabilityService {
+	return &CapabilityService{
+		repo:        repo,
+		featureRepo: featureRepo,
+		modelRepo:   modelRepo,
+		uow:         uow,
+	}
+}
+
+func (s *CapabilityService) ListCapabilities(ctx context.Context) []models.Capability {
+	return models.StandardCapabilities()
+}
+
+func (s *CapabilityService) GetCapability(ctx context.Context, name string) (models.Capability, error) {
+	capability, ok := models.LookupCapability(name)
+	if !ok {
+		return models.Capability{}, fmt.Errorf("%w: capability %q", models.ErrNotFound, name)
+	}
+	return capability, nil
+}
+
+// SetFeatureCapability declares that a feature implements a capability,
+// replacing what it implemented before. The mapping must name every
+// required parameter of the capability, and only parameters it has; each
+// maps to a distinct feature parameter.
+func (s *CapabilityService) SetFeatureCapability(ctx context.Context, capability *models.FeatureCapability) (*models.FeatureCapability, error) {
+	ctx, span := tracing.StartSpan(ctx, "CapabilityService.SetFeatureCapability",
+		attribute.String("feature.id", capability.FeatureID.String()),
+		attribute.String("capability", capability.Capability))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Set feature capability",
+		"feature_id", capability.FeatureID, "capability", capability.Capability, "mapping", capability.ParameterMapping)
+
+	if err := validateFeatureCapability(capability); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		feature, err := s.featureRepo.GetByID(ctx, capability.FeatureID.String())
+		if err != nil {
+			return err
+		}
+		if err := checkMappingTargets(feature, capability); err != nil {
+			return err
+		}
+		capability.ModelID = feature.ModelID
+		return s.repo.SetFeatureCapability(ctx, capability)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return capability, nil
+}
+
+func (s *CapabilityService) GetFeatureCapability(ctx context.Context, featureID uuid.UUID) (*models.FeatureCapability, error) {
+	ctx, span := tracing.StartSpan(ctx, "CapabilityService.GetFeatureCapability", attribute.String("feature.id", featureID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get feature capability", "feature_id", featureID)
+	capability, err := s.repo.GetFeatureCapability(ctx, featureID)
+	tracing.RecordError(span, err)
+	return capability, err
+}
+
+func (s *CapabilityService) ClearFeatureCapability(ctx context.Context, featureID uuid.UUID) error {
+	ctx, span := tracing.StartSpan(ctx, "CapabilityService.ClearFeatureCapability", attribute.String("feature.id", featureID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Clear feature capability", "feature_id", featureID)
+	err := s.repo.DeleteFeatureCapability(ctx, featureID)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// ModelsByCapability returns every model with a feature implementing
+// capability, ordered by model ID, each with the features that implement
+// it.
+func (s *CapabilityService) ModelsByCapability(ctx context.Context, capability string) ([]*models.CapableModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "CapabilityService.ModelsByCapability", attribute.String("capability", capability))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Models by capability", "capability", capability)
+
+	if _, ok := models.LookupCapability(capability); !ok {
+		err := fmt.Errorf("%w: unknown capability %q", models.ErrInvalidCapability, capability)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	implementations, err := s.repo.ListByCapability(ctx, capability)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	var capable []*models.CapableModel
+	var ids []string
+	for _, implementation := range implementations {
+		if n := len(capable); n == 0 || capable[n-1].Model.ID != implementation.ModelID {
+			capable = append(capable, &models.CapableModel{Model: &models.SmartModel{ID: implementation.ModelID}})
+			ids = append(ids, implementation.ModelID.String())
+		}
+		last := capable[len(capable)-1]
+		last.Features = append(last.Features, implementation)
+	}
+	if len(ids) == 0 {
+		return capable, nil
+	}
+
+	found, err := s.modelRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.SmartModel, len(found))
+	for _, model := range found {
+		byID[model.ID] = model
+	}
+	// A model deleted since the features were listed is left out.
+	capable = slices.DeleteFunc(capable, func(c *models.CapableModel) bool {
+		model, ok := byID[c.Model.ID]
+		c.Model = model
+		return !ok
+	})
+	return capable, nil
+}
+
+// checkMappingTargets fails unless every parameter is mapped to one of the
+// feature's own parameters.
+func checkMappingTargets(feature *models.SmartFeature, capability *models.FeatureCapability) error {
+	for _, canonical := range slices.Sorted(maps.Keys(capability.ParameterMapping)) {
+		target := capability.ParameterMapping[canonical]
+		if _, ok := feature.Parameters[target]; !ok {
+			return fmt.Errorf("%w: parameter %q is mapped to %q, which feature %q does not have",
+				models.ErrInvalidCapability, canonical, target, feature.Name)
+		}
+	}
+	return nil
+}
+
+func validateFeatureCapability(capability *models.FeatureCapability) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", models.ErrInvalidCapability, fmt.Sprintf(format, args...))
+	}
+
+	if capability.FeatureID == uuid.Nil {
+		return invalid("feature_id is required")
+	}
+	standard, ok := models.LookupCapability(capability.Capability)
+	if !ok {
+		return invalid("unknown capability %q", capability.Capability)
+	}
+
+	targets := make(map[string]string, len(capability.ParameterMapping))
+	for _, canonical := range slices.Sorted(maps.Keys(capability.ParameterMapping)) {
+		target := capability.ParameterMapping[canonical]
+		if _, ok := standard.Parameter(canonical); !ok {
+			return invalid("%s has no parameter %q", standard.Name, canonical)
+		}
+		if strings.TrimSpace(target) == "" {
+			return invalid("parameter %q is mapped to an empty name", canonical)
+		}
+		if other, ok := targets[target]; ok {
+			return invalid("parameters %q and %q are both mapped to %q", other, canonical, target)
+		}
+		targets[target] = canonical
+	}
+	for _, parameter := range standard.Parameters {
+		if _, ok := capability.ParameterMapping[parameter.Name]; parameter.Required && !ok {
+			return invalid("required parameter %q of %s is not mapped", parameter.Name, standard.Name)
+		}
+	}
+	return nil
+}
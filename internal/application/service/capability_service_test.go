@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+)
+
+type mockFeatureCapabilityRepo struct {
+	mock.Mock
+}
+
+func (m *mockFeatureCapabilityRepo) SetFeatureCapability(ctx context.Context, capability *models.FeatureCapability) error {
+	args := m.Called(ctx, capability)
+	return args.Error(0)
+}
+
+func (m *mockFeatureCapabilityRepo) GetFeatureCapability(ctx context.Context, featureID uuid.UUID) (*models.FeatureCapability, error) {
+	args := m.Called(ctx, featureID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FeatureCapability), args.Error(1)
+}
+
+func (m *mockFeatureCapabilityRepo) DeleteFeatureCapability(ctx context.Context, featureID uuid.UUID) error {
+	args := m.Called(ctx, featureID)
+	return args.Error(0)
+}
+
+func (m *mockFeatureCapabilityRepo) ListByCapability(ctx context.Context, capability string) ([]*models.FeatureCapability, error) {
+	args := m.Called(ctx, capability)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.FeatureCapability), args.Error(1)
+}
+
+func TestCapabilityService_ListCapabilities(t *testing.T) {
+	svc := NewCapabilityService(new(mockFeatureCapabilityRepo), new(mockSmartFeatureRepo), new(mockSmartModelRepo), &fakeUnitOfWork{})
+
+	capabilities := svc.ListCapabilities(context.Background())
+
+	var names []string
+	for _, capability := range capabilities {
+		names = append(names, capability.Name)
+	}
+	assert.IsIncreasing(t, names)
+	assert.Contains(t, names, "switch")
+	assert.Contains(t, names, "brightness")
+	assert.Contains(t, names, "temperature-sensor")
+
+	_, err := svc.GetCapability(context.Background(), "teleport")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestCapabilityService_SetFeatureCapability(t *testing.T) {
+	repo := new(mockFeatureCapabilityRepo)
+	featureRepo := new(mockSmartFeatureRepo)
+	svc := NewCapabilityService(repo, featureRepo, new(mockSmartModelRepo), &fakeUnitOfWork{})
+
+	feature := &models.SmartFeature{ID: uuid.New(), ModelID: uuid.New(), Name: "Dimmer", Parameters: map[string]interface{}{
+		"dim_level": 0,
+		"fade_ms":   200,
+	}}
+	featureRepo.On("GetByID", mock.Anything, feature.ID.String()).Return(feature, nil)
+	repo.On("SetFeatureCapability", mock.Anything, mock.MatchedBy(func(c *models.FeatureCapability) bool {
+		return c.FeatureID == feature.ID && c.Capability == "brightness" && c.ParameterMapping["level"] == "dim_level"
+	})).Return(nil)
+
+	set, err := svc.SetFeatureCapability(context.Background(), &models.FeatureCapability{
+		FeatureID:        feature.ID,
+		Capability:       "brightness",
+		ParameterMapping: map[string]string{"level": "dim_level", "transition": "fade_ms"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, feature.ModelID, set.ModelID)
+	repo.AssertExpectations(t)
+}
+
+func TestCapabilityService_SetFeatureCapability_Invalid(t *testing.T) {
+	featureID := uuid.New()
+	tests := []struct {
+		name       string
+		capability *models.FeatureCapability
+		message    string
+	}{
+		{"missing feature", &models.FeatureCapability{Capability: "switch", ParameterMapping: map[string]string{"on": "power"}}, "feature_id is required"},
+		{"unknown capability", &models.FeatureCapability{FeatureID: featureID, Capability: "teleport"}, `unknown capability "teleport"`},
+		{"unknown parameter", &models.FeatureCapability{FeatureID: featureID, Capability: "switch", ParameterMapping: map[string]string{"on": "power", "speed": "rpm"}}, `switch has no parameter "speed"`},
+		{"required parameter missing", &models.FeatureCapability{FeatureID: featureID, Capability: "brightness", ParameterMapping: map[string]string{"transition": "fade"}}, `required parameter "level" of brightness is not mapped`},
+		{"empty target", &models.FeatureCapability{FeatureID: featureID, Capability: "switch", ParameterMapping: map[string]string{"on": " "}}, `mapped to an empty name`},
+		{"shared target", &models.FeatureCapability{FeatureID: featureID, Capability: "color", ParameterMapping: map[string]string{"hue": "value", "saturation": "value"}}, `both mapped to "value"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockFeatureCapabilityRepo)
+			svc := NewCapabilityService(repo, new(mockSmartFeatureRepo), new(mockSmartModelRepo), &fakeUnitOfWork{})
+
+			_, err := svc.SetFeatureCapability(context.Background(), tt.capability)
+
+			require.ErrorIs(t, err, models.ErrInvalidCapability)
+			assert.Contains(t, err.Error(), tt.message)
+			repo.AssertNotCalled(t, "SetFeatureCapability", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCapabilityService_SetFeatureCapability_UnknownTarget(t *testing.T) {
+	repo := new(mockFeatureCapabilityRepo)
+	featureRepo := new(mockSmartFeatureRepo)
+	svc := NewCapabilityService(repo, featureRepo, new(mockSmartModelRepo), &fakeUnitOfWork{})
+
+	feature := &models.SmartFeature{ID: uuid.New(), ModelID: uuid.New(), Name: "Dimmer", Parameters: map[string]interface{}{"dim_level": 0}}
+	featureRepo.On("GetByID", mock.Anything, feature.ID.String()).Return(feature, nil)
+
+	_, err := svc.SetFeatureCapability(context.Background(), &models.FeatureCapability{
+		FeatureID:        feature.ID,
+		Capability:       "brightness",
+		ParameterMapping: map[string]string{"level": "dim_level", "transition": "fade_ms"},
+	})
+
+	require.ErrorIs(t, err, models.ErrInvalidCapability)
+	assert.Contains(t, err.Error(), `parameter "transition" is mapped to "fade_ms", which feature "Dimmer" does not have`)
+	repo.AssertNotCalled(t, "SetFeatureCapability", mock.Anything, mock.Anything)
+}
+
+func TestCapabilityService_SetFeatureCapability_UnknownFeature(t *testing.T) {
+	repo := new(mockFeatureCapabilityRepo)
+	featureRepo := new(mockSmartFeatureRepo)
+	svc := NewCapabilityService(repo, featureRepo, new(mockSmartModelRepo), &fakeUnitOfWork{})
+
+	featureID := uuid.New()
+	featureRepo.On("GetByID", mock.Anything, featureID.String()).Return(nil, models.ErrNotFound)
+
+	_, err := svc.SetFeatureCapability(context.Background(), &models.FeatureCapability{
+		FeatureID:        featureID,
+		Capability:       "switch",
+		ParameterMapping: map[string]string{"on": "power"},
+	})
+
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	repo.AssertNotCalled(t, "SetFeatureCapability", mock.Anything, mock.Anything)
+}
+
+func TestCapabilityService_ModelsByCapability(t *testing.T) {
+	repo := new(mockFeatureCapabilityRepo)
+	modelRepo := new(mockSmartModelRepo)
+	svc := NewCapabilityService(repo, new(mockSmartFeatureRepo), modelRepo, &fakeUnitOfWork{})
+
+	strip, bulb, deleted := uuid.New(), uuid.New(), uuid.New()
+	implementations := []*models.FeatureCapability{
+		{FeatureID: uuid.New(), ModelID: strip, Capability: "switch", ParameterMapping: map[string]string{"on": "relay_1"}},
+		{FeatureID: uuid.New(), ModelID: strip, Capability: "switch", ParameterMapping: map[string]string{"on": "relay_2"}},
+		{FeatureID: uuid.New(), ModelID: bulb, Capability: "switch", ParameterMapping: map[string]string{"on": "power"}},
+		{FeatureID: uuid.New(), ModelID: deleted, Capability: "switch", ParameterMapping: map[string]string{"on": "power"}},
+	}
+	repo.On("ListByCapability", mock.Anything, "switch").Return(implementations, nil)
+	modelRepo.On("GetByIDs", mock.Anything, []string{strip.String(), bulb.String(), deleted.String()}).
+		Return([]*models.SmartModel{{ID: bulb, Name: "Bulb"}, {ID: strip, Name: "Power strip"}}, nil)
+
+	capable, err := svc.ModelsByCapability(context.Background(), "switch")
+
+	require.NoError(t, err)
+	require.Len(t, capable, 2)
+	assert.Equal(t, "Power strip", capable[0].Model.Name)
+	assert.Equal(t, implementations[:2], capable[0].Features)
+	assert.Equal(t, "Bulb", capable[1].Model.Name)
+	assert.Equal(t, implementations[2:3], capable[1].Features)
+}
+
+func TestCapabilityService_ModelsByCapability_Unknown(t *testing.T) {
+	repo := new(mockFeatureCapabilityRepo)
+	svc := NewCapabilityService(repo, new(mockSmartFeatureRepo), new(mockSmartModelRepo), &fakeUnitOfWork{})
+
+	_, err := svc.ModelsByCapability(context.Background(), "teleport")
+
+	assert.True(t, errors.Is(err, models.ErrInvalidCapability), "got %v", err)
+	repo.AssertNotCalled(t, "ListByCapability", mock.Anything, mock.Anything)
+}
+
+func TestCapabilityService_ModelsByCapability_None(t *testing.T) {
+	repo := new(mockFeatureCapabilityRepo)
+	modelRepo := new(mockSmartModelRepo)
+	svc := NewCapabilityService(repo, new(mockSmartFeatureRepo), modelRepo, &fakeUnitOfWork{})
+	repo.On("ListByCapability", mock.Anything, "lock").Return(nil, nil)
+
+	capable, err := svc.ModelsByCapability(context.Background(), "lock")
+
+	require.NoError(t, err)
+	assert.Empty(t, capable)
+	modelRepo.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything)
+}
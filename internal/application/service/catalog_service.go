@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/common/validation"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+// CatalogService imports and exports models together with their features.
+// Imports upsert by (manufacturer, model_number): an existing model is
+// updated in place and its features are matched by name and interface path,
+// ignoring case, the unique key of features within a model.
+// Features that are missing from the import are left alone.
+type CatalogService struct {
+	modelRepo   interfaces.SmartModelRepository
+	featureRepo interfaces.SmartFeatureRepository
+	uow         interfaces.UnitOfWork
+	outbox      interfaces.OutboxRepository
+}
+
+func NewCatalogService(
+	modelRepo interfaces.SmartModelRepository,
+	featureRepo interfaces.SmartFeatureRepository,
+	uow interfaces.UnitOfWork,
+	outbox interfaces.OutboxRepository,
+) *CatalogService {
+	return &CatalogService{
+		modelRepo:   modelRepo,
+		featureRepo: featureRepo,
+		uow:         uow,
+		outbox:      outbox,
+	}
+}
+
+func (s *CatalogService) recordEvent(ctx context.Context, aggregate models.AggregateType, eventType models.EventType, id uuid.UUID, payload interface{}) error {
+	event, err := models.NewDomainEvent(aggregate, id, eventType, payload)
+	if err != nil {
+		return err
+	}
+	return s.outbox.Add(ctx, event)
+}
+
+// Import validates every entry up front and then writes the valid ones in
+// batches of opts.BatchSize, each in its own transaction. A batch holding an
+// invalid or failing entry is rolled back as a whole. The returned error is
+// only set when the import could not run at all; row problems are reported
+// in the ImportReport.
+func (s *CatalogService) Import(ctx context.Context, entries []*models.CatalogEntry, opts models.ImportOptions) (*models.ImportReport, error) {
+	ctx, span := tracing.StartSpan(ctx, "CatalogService.Import",
+		attribute.Int("import.rows", len(entries)),
+		attribute.Bool("import.dry_run", opts.DryRun),
+		attribute.Int("import.batch_size", opts.BatchSize),
+	)
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Import catalog", "rows", len(entries), "dry_run", opts.DryRun, "batch_size", opts.BatchSize)
+
+	report := &models.ImportReport{
+		DryRun: opts.DryRun,
+		Rows:   make([]models.ImportRowResult, len(entries)),
+	}
+	prepareImport(entries, report.Rows)
+
+	var err error
+	if opts.DryRun {
+		err = s.planImport(ctx, entries, report.Rows)
+	} else {
+		err = s.runImport(ctx, entries, report.Rows, opts.BatchSize)
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	for _, row := range report.Rows {
+		switch row.Status {
+		case models.ImportCreated:
+			report.Created++
+		case models.ImportUpdated:
+			report.Updated++
+		case models.ImportRolledBack:
+			report.RolledBack++
+		default:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// prepareImport fills in IDs and timestamps and marks every entry that fails
+// validation as invalid. Manufacturers and model numbers, and feature names
+// and interface paths, are compared ignoring case, as the unique keys of
+// models and features do.
+func prepareImport(entries []*models.CatalogEntry, rows []models.ImportRowResult) {
+	now := time.Now()
+	firstRow := make(map[[2]string]int)
+
+	for i, entry := range entries {
+		rows[i].Row = i + 1
+		errs := prepareEntry(entry, now)
+
+		if entry != nil && entry.Model != nil && entry.Model.Manufacturer != "" && entry.Model.ModelNumber != "" {
+			key := [2]string{strings.ToLower(entry.Model.Manufacturer), strings.ToLower(entry.Model.ModelNumber)}
+			if row, ok := firstRow[key]; ok {
+				errs = append(errs, fmt.Sprintf("duplicate of row %d", row))
+			} else {
+				firstRow[key] = i + 1
+			}
+		}
+
+		if len(errs) > 0 {
+			rows[i].Status = models.ImportInvalid
+			rows[i].Errors = errs
+		}
+	}
+}
+
+func prepareEntry(entry *models.CatalogEntry, now time.Time) []string {
+	if entry == nil || entry.Model == nil {
+		return []string{"model is required"}
+	}
+
+	var errs []string
+	model := entry.Model
+	if model.ID == uuid.Nil {
+		model.ID = uuid.New()
+	}
+	model.CreatedAt = now
+	model.UpdatedAt = now
+
+	if model.Manufacturer == "" {
+		errs = append(errs, "manufacturer is required")
+	}
+	if model.ModelNumber == "" {
+		errs = append(errs, "model_number is required")
+	}
+	errs = append(errs, validationMessages("", validation.ValidateStruct(model))...)
+
+	keys := make(map[featureKey]bool, len(entry.Features))
+	for i, feature := range entry.Features {
+		if feature == nil {
+			errs = append(errs, fmt.Sprintf("feature %d: feature is required", i+1))
+			continue
+		}
+		if feature.ID == uuid.Nil {
+			feature.ID = uuid.New()
+		}
+		feature.ModelID = model.ID
+		feature.CreatedAt = now
+		feature.UpdatedAt = now
+
+		errs = append(errs, validationMessages(fmt.Sprintf("feature %d: ", i+1), validation.ValidateStruct(feature))...)
+		key := newFeatureKey(model.ID, feature.Name, feature.InterfacePath)
+		if keys[key] {
+			errs = append(errs, fmt.Sprintf("feature %d: duplicate feature %q at %q", i+1, feature.Name, feature.InterfacePath))
+		}
+		keys[key] = true
+	}
+	return errs
+}
+
+// validationMessages splits a validation error into one message per field.
+func validationMessages(prefix string, err error) []string {
+	if err == nil {
+		return nil
+	}
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return []string{prefix + err.Error()}
+	}
+	messages := make([]string, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		messages[i] = prefix + fieldError.Error()
+	}
+	return messages
+}
+
+// planImport reports what a real import would do with every valid entry.
+func (s *CatalogService) planImport(ctx context.Context, entries []*models.CatalogEntry, rows []models.ImportRowResult) error {
+	for i, entry := range entries {
+		if rows[i].Status == models.ImportInvalid {
+			continue
+		}
+		existing, err := s.modelRepo.GetByModelNumber(ctx, entry.Model.Manufacturer, entry.Model.ModelNumber)
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			rows[i].Status = models.ImportCreated
+			rows[i].ModelID = entry.Model.ID
+		case err != nil:
+			return err
+		default:
+			rows[i].Status = models.ImportUpdated
+			rows[i].ModelID = existing.ID
+		}
+	}
+	return nil
+}
+
+func (s *CatalogService) runImport(ctx context.Context, entries []*models.CatalogEntry, rows []models.ImportRowResult, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = len(entries)
+	}
+
+	for start := 0; start < len(entries); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+batchSize, len(entries))
+		s.importBatch(ctx, entries[start:end], rows[start:end])
+	}
+	return nil
+}
+
+// importBatch writes one batch in a single transaction and records the
+// outcome of every row in it.
+func (s *CatalogService) importBatch(ctx context.Context, entries []*models.CatalogEntry, rows []models.ImportRowResult) {
+	for _, row := range rows {
+		if row.Status == models.ImportInvalid {
+			rollBackRows(rows)
+			return
+		}
+	}
+
+	results := make([]models.ImportRowResult, len(rows))
+	copy(results, rows)
+	failed := -1
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		for i, entry := range entries {
+			status, id, err := s.upsert(ctx, entry)
+			if err != nil {
+				failed = i
+				return err
+			}
+			results[i].Status = status
+			results[i].ModelID = id
+		}
+		return nil
+	})
+	if err == nil {
+		copy(rows, results)
+		return
+	}
+
+	logger.FromContext(ctx).Error("Catalog import batch failed", "first_row", rows[0].Row, "error", err)
+	if failed < 0 {
+		// The rows were written but the commit failed.
+		for i := range rows {
+			rows[i].Status = models.ImportFailed
+			rows[i].Errors = []string{err.Error()}
+		}
+		return
+	}
+	rollBackRows(rows)
+	rows[failed].Status = models.ImportFailed
+	rows[failed].Errors = []string{err.Error()}
+}
+
+// rollBackRows marks the rows of a batch that would otherwise have been
+// written as rolled back.
+func rollBackRows(rows []models.ImportRowResult) {
+	for i := range rows {
+		if rows[i].Status != models.ImportInvalid {
+			rows[i].Status = models.ImportRolledBack
+		}
+	}
+}
+
+func (s *CatalogService) upsert(ctx context.Context, entry *models.CatalogEntry) (models.ImportRowStatus, uuid.UUID, error) {
+	model := entry.Model
+	existing, err := s.modelRepo.GetByModelNumber(ctx, model.Manufacturer, model.ModelNumber)
+	if errors.Is(err, models.ErrNotFound) {
+		return models.ImportCreated, model.ID, s.createEntry(ctx, entry)
+	}
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	return models.ImportUpdated, existing.ID, s.updateEntry(ctx, existing, entry)
+}
+
+func (s *CatalogService) createEntry(ctx context.Context, entry *models.CatalogEntry) error {
+	created, err := s.modelRepo.Create(ctx, entry.Model)
+	if err != nil {
+		return err
+	}
+	if err := s.recordEvent(ctx, models.SmartModelAggregate, models.ModelCreatedEvent, created.ID, created); err != nil {
+		return err
+	}
+
+	for _, feature := range entry.Features {
+		feature.ModelID = created.ID
+		if err := s.createFeature(ctx, feature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *CatalogService) updateEntry(ctx context.Context, existing *models.SmartModel, entry *models.CatalogEntry) error {
+	model := entry.Model
+	model.ID = existing.ID
+	model.CreatedAt = existing.CreatedAt
+
+	updated, err := s.modelRepo.Update(ctx, model)
+	if err != nil {
+		return err
+	}
+	if err := s.recordEvent(ctx, models.SmartModelAggregate, models.ModelUpdatedEvent, updated.ID, updated); err != nil {
+		return err
+	}
+
+	current, err := s.featureRepo.GetWithModelID(ctx, existing.ID.String())
+	if err != nil {
+		return err
+	}
+	byKey := make(map[featureKey]*models.SmartFeature, len(current))
+	for _, feature := range current {
+		byKey[newFeatureKey(feature.ModelID, feature.Name, feature.InterfacePath)] = feature
+	}
+
+	for _, feature := range entry.Features {
+		feature.ModelID = existing.ID
+		match, ok := byKey[newFeatureKey(existing.ID, feature.Name, feature.InterfacePath)]
+		if !ok {
+			if err := s.createFeature(ctx, feature); err != nil {
+				return err
+			}
+			continue
+		}
+
+		feature.ID = match.ID
+		feature.CreatedAt = match.CreatedAt
+		updatedFeature, err := s.featureRepo.Update(ctx, feature)
+		if err != nil {
+			return err
+		}
+		if err := s.recordEvent(ctx, models.SmartFeatureAggregate, models.FeatureUpdatedEvent, updatedFeature.ID, updatedFeature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *CatalogService) createFeature(ctx context.Context, feature *models.SmartFeature) error {
+	created, err := s.featureRepo.Create(ctx, feature)
+	if err != nil {
+		return err
+	}
+	return s.recordEvent(ctx, models.SmartFeatureAggregate, models.FeatureCreatedEvent, created.ID, created)
+}
+
+// Export calls send for every model matching filter, in creation order, with
+// its features attached. It stops at the first error returned by send.
+func (s *CatalogService) Export(ctx context.Context, filter models.ExportFilter, send func(*models.CatalogEntry) error) error {
+	ctx, span := tracing.StartSpan(ctx, "CatalogService.Export")
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Export catalog", "filter", filter)
+
+	err := s.export(ctx, filter, send)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (s *CatalogService) export(ctx context.Context, filter models.ExportFilter, send func(*models.CatalogEntry) error) error {
+	var smartModels []*models.SmartModel
+	var err error
+	if filter.Type != nil {
+		smartModels, err = s.modelRepo.GetWithType(ctx, *filter.Type)
+	} else {
+		smartModels, err = s.modelRepo.GetAll(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	features, err := s.featureRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	byModel := make(map[uuid.UUID][]*models.SmartFeature)
+	for _, feature := range features {
+		byModel[feature.ModelID] = append(byModel[feature.ModelID], feature)
+	}
+
+	for _, model := range smartModels {
+		if !filter.Matches(model) {
+			continue
+		}
+		if err := send(&models.CatalogEntry{Model: model, Features: byModel[model.ID]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func newCatalogEntry(modelNumber string, featureNames ...string) *models.CatalogEntry {
+	entry := &models.CatalogEntry{
+		Model: &models.SmartModel{
+			Name:         "Test Device " + modelNumber,
+			Description:  "Test Description",
+			Type:         models.DeviceType,
+			Category:     models.WearableCategory,
+			Manufacturer: "Acme",
+			ModelNumber:  modelNumber,
+		},
+	}
+	for _, name := range featureNames {
+		entry.Features = append(entry.Features, &models.SmartFeature{
+			Name:          name,
+			Description:   "Feature Description",
+			Protocol:      models.RestProtocol,
+			InterfacePath: "/" + name,
+		})
+	}
+	return entry
+}
+
+func TestCatalogService_Import_CreatesNewModel(t *testing.T) {
+	mockModels := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewCatalogService(mockModels, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	entry := newCatalogEntry("AC100", "power", "status")
+
+	mockModels.On("GetByModelNumber", mock.Anything, "Acme", "AC100").Return(nil, models.ErrNotFound)
+	mockModels.On("Create", mock.Anything, entry.Model).Return(entry.Model, nil)
+	mockFeatures.On("Create", mock.Anything, entry.Features[0]).Return(entry.Features[0], nil)
+	mockFeatures.On("Create", mock.Anything, entry.Features[1]).Return(entry.Features[1], nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.ModelCreatedEvent)).Return(nil).Once()
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.FeatureCreatedEvent)).Return(nil).Twice()
+
+	report, err := service.Import(context.Background(), []*models.CatalogEntry{entry}, models.ImportOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 0, report.Failed)
+	require.Len(t, report.Rows, 1)
+	assert.Equal(t, models.ImportCreated, report.Rows[0].Status)
+	assert.Equal(t, entry.Model.ID, report.Rows[0].ModelID)
+	assert.NotEqual(t, uuid.Nil, entry.Model.ID)
+	for _, feature := range entry.Features {
+		assert.Equal(t, entry.Model.ID, feature.ModelID)
+	}
+
+	mockModels.AssertExpectations(t)
+	mockFeatures.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestCatalogService_Import_UpdatesExistingModel(t *testing.T) {
+	mockModels := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewCatalogService(mockModels, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	existing := &models.SmartModel{ID: uuid.New(), Manufacturer: "Acme", ModelNumber: "AC100", CreatedAt: created}
+	existingFeature := &models.SmartFeature{ID: uuid.New(), ModelID: existing.ID, Name: "power", InterfacePath: "/power", CreatedAt: created}
+
+	entry := newCatalogEntry("AC100", "power", "status")
+
+	mockModels.On("GetByModelNumber", mock.Anything, "Acme", "AC100").Return(existing, nil)
+	mockModels.On("Update", mock.Anything, entry.Model).Return(entry.Model, nil)
+	mockFeatures.On("GetWithModelID", mock.Anything, existing.ID.String()).Return([]*models.SmartFeature{existingFeature}, nil)
+	mockFeatures.On("Update", mock.Anything, entry.Features[0]).Return(entry.Features[0], nil)
+	mockFeatures.On("Create", mock.Anything, entry.Features[1]).Return(entry.Features[1], nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.ModelUpdatedEvent)).Return(nil).Once()
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.FeatureUpdatedEvent)).Return(nil).Once()
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.FeatureCreatedEvent)).Return(nil).Once()
+
+	report, err := service.Import(context.Background(), []*models.CatalogEntry{entry}, models.ImportOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, models.ImportUpdated, report.Rows[0].Status)
+	assert.Equal(t, existing.ID, report.Rows[0].ModelID)
+	assert.Equal(t, existing.ID, entry.Model.ID)
+	assert.Equal(t, created, entry.Model.CreatedAt)
+	assert.Equal(t, existingFeature.ID, entry.Features[0].ID)
+	assert.Equal(t, created, entry.Features[0].CreatedAt)
+	assert.Equal(t, existing.ID, entry.Features[1].ModelID)
+
+	mockModels.AssertExpectations(t)
+	mockFeatures.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestCatalogService_Import_DryRun(t *testing.T) {
+	mockModels := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewCatalogService(mockModels, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	existing := &models.SmartModel{ID: uuid.New(), Manufacturer: "Acme", ModelNumber: "AC100"}
+	invalid := newCatalogEntry("AC300")
+	invalid.Model.Name = ""
+
+	mockModels.On("GetByModelNumber", mock.Anything, "Acme", "AC100").Return(existing, nil)
+	mockModels.On("GetByModelNumber", mock.Anything, "Acme", "AC200").Return(nil, models.ErrNotFound)
+
+	report, err := service.Import(context.Background(), []*models.CatalogEntry{
+		newCatalogEntry("AC100"),
+		newCatalogEntry("AC200", "power"),
+		invalid,
+	}, models.ImportOptions{DryRun: true})
+
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, models.ImportUpdated, report.Rows[0].Status)
+	assert.Equal(t, existing.ID, report.Rows[0].ModelID)
+	assert.Equal(t, models.ImportCreated, report.Rows[1].Status)
+	assert.Equal(t, models.ImportInvalid, report.Rows[2].Status)
+	assert.Equal(t, 3, report.Rows[2].Row)
+	assert.NotEmpty(t, report.Rows[2].Errors)
+
+	mockModels.AssertExpectations(t)
+	mockFeatures.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestCatalogService_Import_InvalidRowRollsBackItsBatch(t *testing.T) {
+	mockModels := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewCatalogService(mockModels, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	first := newCatalogEntry("AC100")
+	missingKey := newCatalogEntry("")
+	duplicate := newCatalogEntry("AC100")
+	last := newCatalogEntry("AC400")
+
+	mockModels.On("GetByModelNumber", mock.Anything, "Acme", "AC400").Return(nil, models.ErrNotFound)
+	mockModels.On("Create", mock.Anything, last.Model).Return(last.Model, nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.ModelCreatedEvent)).Return(nil).Once()
+
+	report, err := service.Import(context.Background(), []*models.CatalogEntry{
+		first, missingKey, duplicate, last,
+	}, models.ImportOptions{BatchSize: 3})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportRolledBack, report.Rows[0].Status)
+	assert.Equal(t, models.ImportInvalid, report.Rows[1].Status)
+	assert.Contains(t, report.Rows[1].Errors, "model_number is required")
+	assert.Equal(t, models.ImportInvalid, report.Rows[2].Status)
+	assert.Contains(t, report.Rows[2].Errors, "duplicate of row 1")
+	assert.Equal(t, models.ImportCreated, report.Rows[3].Status)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 1, report.RolledBack)
+
+	mockModels.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestCatalogService_Import_KeysIgnoreCase(t *testing.T) {
+	mockModels := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewCatalogService(mockModels, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	first := newCatalogEntry("AC100")
+	sameModel := newCatalogEntry("ac100")
+	sameModel.Model.Manufacturer = "ACME"
+	sameFeature := newCatalogEntry("AC200", "Power", "power")
+
+	mockModels.On("GetByModelNumber", mock.Anything, "Acme", "AC100").Return(nil, models.ErrNotFound)
+
+	report, err := service.Import(context.Background(), []*models.CatalogEntry{
+		first, sameModel, sameFeature,
+	}, models.ImportOptions{DryRun: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportCreated, report.Rows[0].Status)
+	assert.Equal(t, models.ImportInvalid, report.Rows[1].Status)
+	assert.Contains(t, report.Rows[1].Errors, "duplicate of row 1")
+	assert.Equal(t, models.ImportInvalid, report.Rows[2].Status)
+	assert.Contains(t, report.Rows[2].Errors, `feature 2: duplicate feature "power" at "/power"`)
+}
+
+func TestCatalogService_Import_MatchesFeaturesIgnoringCase(t *testing.T) {
+	mockModels := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewCatalogService(mockModels, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	existing := &models.SmartModel{ID: uuid.New(), Manufacturer: "Acme", ModelNumber: "AC100"}
+	existingFeature := &models.SmartFeature{ID: uuid.New(), ModelID: existing.ID, Name: "Power", InterfacePath: "/Power"}
+	entry := newCatalogEntry("AC100", "power")
+
+	mockModels.On("GetByModelNumber", mock.Anything, "Acme", "AC100").Return(existing, nil)
+	mockModels.On("Update", mock.Anything, entry.Model).Return(entry.Model, nil)
+	mockFeatures.On("GetWithModelID", mock.Anything, existing.ID.String()).Return([]*models.SmartFeature{existingFeature}, nil)
+	mockFeatures.On("Update", mock.Anything, entry.Features[0]).Return(entry.Features[0], nil)
+	mockOutbox.On("Add", mock.Anything, mock.Anything).Return(nil)
+
+	report, err := service.Import(context.Background(), []*models.CatalogEntry{entry}, models.ImportOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportUpdated, report.Rows[0].Status)
+	assert.Equal(t, existingFeature.ID, entry.Features[0].ID)
+	mockFeatures.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockFeatures.AssertExpectations(t)
+}
+
+func TestCatalogService_Import_MatchesFeaturesByNameAndPath(t *testing.T) {
+	mockModels := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewCatalogService(mockModels, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	existing := &models.SmartModel{ID: uuid.New(), Manufacturer: "Acme", ModelNumber: "AC100"}
+	v1 := &models.SmartFeature{ID: uuid.New(), ModelID: existing.ID, Name: "Power", InterfacePath: "/power"}
+	v2 := &models.SmartFeature{ID: uuid.New(), ModelID: existing.ID, Name: "Power", InterfacePath: "/power/v2"}
+	entry := newCatalogEntry("AC100", "power", "power")
+	entry.Features[1].InterfacePath = "/POWER/v2"
+
+	mockModels.On("GetByModelNumber", mock.Anything, "Acme", "AC100").Return(existing, nil)
+	mockModels.On("Update", mock.Anything, entry.Model).Return(entry.Model, nil)
+	mockFeatures.On("GetWithModelID", mock.Anything, existing.ID.String()).Return([]*models.SmartFeature{v1, v2}, nil)
+	mockFeatures.On("Update", mock.Anything, entry.Features[0]).Return(entry.Features[0], nil)
+	mockFeatures.On("Update", mock.Anything, entry.Features[1]).Return(entry.Features[1], nil)
+	mockOutbox.On("Add", mock.Anything, mock.Anything).Return(nil)
+
+	report, err := service.Import(context.Background(), []*models.CatalogEntry{entry}, models.ImportOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportUpdated, report.Rows[0].Status)
+	assert.Empty(t, report.Rows[0].Errors)
+	assert.Equal(t, v1.ID, entry.Features[0].ID)
+	assert.Equal(t, v2.ID, entry.Features[1].ID)
+	mockFeatures.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockFeatures.AssertExpectations(t)
+}
+
+func TestCatalogService_Import_WriteErrorRollsBackBatch(t *testing.T) {
+	mockModels := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewCatalogService(mockModels, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	first := newCatalogEntry("AC100")
+	second := newCatalogEntry("AC200")
+
+	mockModels.On("GetByModelNumber", mock.Anything, "Acme", "AC100").Return(nil, models.ErrNotFound)
+	mockModels.On("GetByModelNumber", mock.Anything, "Acme", "AC200").Return(nil, models.ErrNotFound)
+	mockModels.On("Create", mock.Anything, first.Model).Return(first.Model, nil)
+	mockModels.On("Create", mock.Anything, second.Model).Return(nil, assert.AnError)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.ModelCreatedEvent)).Return(nil).Once()
+
+	report, err := service.Import(context.Background(), []*models.CatalogEntry{first, second}, models.ImportOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportRolledBack, report.Rows[0].Status)
+	assert.Equal(t, models.ImportFailed, report.Rows[1].Status)
+	assert.Equal(t, []string{assert.AnError.Error()}, report.Rows[1].Errors)
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.RolledBack)
+
+	mockModels.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestCatalogService_Export(t *testing.T) {
+	mockModels := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewCatalogService(mockModels, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	acme := &models.SmartModel{ID: uuid.New(), Manufacturer: "Acme", Type: models.DeviceType}
+	other := &models.SmartModel{ID: uuid.New(), Manufacturer: "Other", Type: models.DeviceType}
+	feature := &models.SmartFeature{ID: uuid.New(), ModelID: acme.ID, Name: "power"}
+	otherFeature := &models.SmartFeature{ID: uuid.New(), ModelID: other.ID, Name: "power"}
+
+	mockModels.On("GetWithType", mock.Anything, models.DeviceType).Return([]*models.SmartModel{acme, other}, nil)
+	mockFeatures.On("GetAll", mock.Anything).Return([]*models.SmartFeature{feature, otherFeature}, nil)
+
+	manufacturer := "Acme"
+	modelType := models.DeviceType
+	var exported []*models.CatalogEntry
+	err := service.Export(context.Background(), models.ExportFilter{Manufacturer: &manufacturer, Type: &modelType}, func(entry *models.CatalogEntry) error {
+		exported = append(exported, entry)
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, exported, 1)
+	assert.Equal(t, acme, exported[0].Model)
+	assert.Equal(t, []*models.SmartFeature{feature}, exported[0].Features)
+
+	mockModels.AssertExpectations(t)
+	mockFeatures.AssertExpectations(t)
+}
+
+func TestCatalogService_Export_SendError(t *testing.T) {
+	mockModels := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewCatalogService(mockModels, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	mockModels.On("GetAll", mock.Anything).Return([]*models.SmartModel{{ID: uuid.New()}, {ID: uuid.New()}}, nil)
+	mockFeatures.On("GetAll", mock.Anything).Return([]*models.SmartFeature{}, nil)
+
+	calls := 0
+	err := service.Export(context.Background(), models.ExportFilter{}, func(*models.CatalogEntry) error {
+		calls++
+		return assert.AnError
+	})
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 1, calls)
+}
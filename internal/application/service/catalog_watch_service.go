@@ -0,0 +1,641 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	watchBatchSize = 500
+	// watchGapTimeout is how long a missing sequence holds back later
+	// changes, the same as for the cache invalidator. Sequences are taken
+	// before commit, so a gap is either a transaction still in flight or one
+	// that rolled back. A skipped gap is reread on every poll until it fills
+	// or falls out of retention, so a long transaction is delivered late
+	// rather than lost.
+	watchGapTimeout = 10 * time.Second
+	// maxSkippedGaps bounds how many skipped gaps are reread; the oldest is
+	// given up first.
+	maxSkippedGaps = 100
+	pruneInterval  = 10 * time.Minute
+)
+
+// CatalogWatchService fans the catalog change log out to watchers. A single
+// reader follows the log, woken by LISTEN/NOTIFY and by a poll interval as a
+// fallback, and hands changes to every subscriber in sequence order. The
+// resume token of a change is its sequence, so a watcher that reconnects
+// replays exactly what it missed from the log. The exception is a change
+// whose transaction outlasted watchGapTimeout: it arrives late, after
+// changes with higher sequences, carrying the current token.
+type CatalogWatchService struct {
+	changes      interfaces.CatalogChangeRepository
+	listener     interfaces.ChangeListener
+	modelRepo    interfaces.SmartModelRepository
+	featureRepo  interfaces.SmartFeatureRepository
+	bufferSize   int
+	pollInterval time.Duration
+	retention    time.Duration
+
+	wake     chan struct{}
+	ready    chan struct{}
+	mu       sync.Mutex
+	cursor   int64
+	gapSince time.Time
+	skipped  []*skippedGap
+	stopped  bool
+	subs     map[*watchSubscriber]struct{}
+}
+
+type watchSubscriber struct {
+	changes chan watchDelivery
+	done    chan struct{}
+	err     error
+}
+
+// watchDelivery is a change on its way to a subscriber. token is the resume
+// token to send with it: the change's own sequence, or the cursor for a
+// change that committed after its gap was skipped, so that tokens never move
+// backwards.
+type watchDelivery struct {
+	change *models.CatalogChange
+	token  int64
+}
+
+// skippedGap is a range of sequences the reader gave up waiting for. seen
+// holds the ones that have since turned up and been delivered.
+type skippedGap struct {
+	from, to int64
+	since    time.Time
+	seen     map[int64]bool
+}
+
+func NewCatalogWatchService(
+	changes interfaces.CatalogChangeRepository,
+	listener interfaces.ChangeListener,
+	modelRepo interfaces.SmartModelRepository,
+	featureRepo interfaces.SmartFeatureRepository,
+	bufferSize int,
+	pollInterval time.Duration,
+	retention time.Duration,
+) *CatalogWatchService {
+	return &CatalogWatchService{
+		changes:      changes,
+		listener:     listener,
+		modelRepo:    modelRepo,
+		featureRepo:  featureRepo,
+		bufferSize:   bufferSize,
+		pollInterval: pollInterval,
+		retention:    retention,
+		wake:         make(chan struct{}, 1),
+		ready:        make(chan struct{}),
+		subs:         make(map[*watchSubscriber]struct{}),
+	}
+}
+
+// Run follows the change log until ctx is cancelled, then ends all open
+// watches with ErrWatchStopped.
+func (s *CatalogWatchService) Run(ctx context.Context) {
+	defer s.stop()
+
+	for {
+		cursor, err := s.changes.LatestSequence(ctx)
+		if err == nil {
+			s.mu.Lock()
+			s.cursor = cursor
+			s.mu.Unlock()
+			break
+		}
+		logger.Error("Failed to read catalog change head", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.pollInterval):
+		}
+	}
+	close(s.ready)
+
+	if s.listener != nil {
+		go func() {
+			if err := s.listener.Listen(ctx, s.notify); err != nil && ctx.Err() == nil {
+				logger.Error("Catalog change listener stopped", err)
+			}
+		}()
+	}
+
+	pollTicker := time.NewTicker(s.pollInterval)
+	defer pollTicker.Stop()
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-pollTicker.C:
+		case <-pruneTicker.C:
+			s.prune(ctx)
+			continue
+		}
+
+		if err := s.poll(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Failed to read catalog changes", err)
+		}
+	}
+}
+
+// poll reads new changes from the log and dispatches them. It stops at a
+// missing sequence until the gap is filled or watchGapTimeout has passed,
+// and then remembers the gap so that recheckGaps can still deliver it.
+func (s *CatalogWatchService) poll(ctx context.Context) error {
+	if err := s.recheckGaps(ctx); err != nil {
+		return err
+	}
+
+	for {
+		s.mu.Lock()
+		cursor := s.cursor
+		s.mu.Unlock()
+
+		changes, err := s.changes.ListSince(ctx, cursor, watchBatchSize)
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		blocked := false
+		for _, change := range changes {
+			if change.Sequence != s.cursor+1 {
+				if s.gapSince.IsZero() {
+					s.gapSince = time.Now()
+				}
+				if time.Since(s.gapSince) < watchGapTimeout {
+					blocked = true
+					break
+				}
+				s.skipGap(s.cursor+1, change.Sequence-1)
+			}
+			s.gapSince = time.Time{}
+			s.cursor = change.Sequence
+			s.dispatch(change, change.Sequence)
+		}
+		s.mu.Unlock()
+
+		if blocked {
+			s.notifyAfter(watchGapTimeout)
+			return nil
+		}
+		if len(changes) < watchBatchSize {
+			return nil
+		}
+	}
+}
+
+// skipGap records the missing sequences from..to as given up. Must be called
+// with s.mu held.
+func (s *CatalogWatchService) skipGap(from, to int64) {
+	logger.Warn("Skipping catalog change gap", "from", from, "to", to)
+	if len(s.skipped) == maxSkippedGaps {
+		logger.Warn("Too many skipped catalog change gaps, giving up the oldest",
+			"from", s.skipped[0].from, "to", s.skipped[0].to)
+		s.skipped = s.skipped[1:]
+	}
+	s.skipped = append(s.skipped, &skippedGap{from: from, to: to, since: time.Now(), seen: make(map[int64]bool)})
+}
+
+// recheckGaps rereads the skipped gaps and dispatches the changes that have
+// committed since, out of sequence order. A gap is forgotten once every
+// sequence in it has turned up or it is older than the retention, after
+// which not even a resume could replay it. A watcher that is not connected
+// when a late change is dispatched does not get it on resume, since its
+// token is already past the change.
+func (s *CatalogWatchService) recheckGaps(ctx context.Context) error {
+	s.mu.Lock()
+	gaps := append([]*skippedGap(nil), s.skipped...)
+	s.mu.Unlock()
+	if len(gaps) == 0 {
+		return nil
+	}
+
+	late := make(map[*skippedGap][]*models.CatalogChange, len(gaps))
+	for _, gap := range gaps {
+		changes, err := s.gapChanges(ctx, gap)
+		if err != nil {
+			return err
+		}
+		late[gap] = changes
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.skipped[:0]
+	for _, gap := range s.skipped {
+		for _, change := range late[gap] {
+			if gap.seen[change.Sequence] {
+				continue
+			}
+			gap.seen[change.Sequence] = true
+			logger.Warn("Delivering catalog change after its gap was skipped", "sequence", change.Sequence)
+			s.dispatch(change, s.cursor)
+		}
+		if int64(len(gap.seen)) <= gap.to-gap.from && time.Since(gap.since) < s.retention {
+			kept = append(kept, gap)
+		}
+	}
+	clear(s.skipped[len(kept):])
+	s.skipped = kept
+	return nil
+}
+
+// gapChanges lists the changes logged within gap. Every row in the range is
+// one that was missing when the gap was skipped.
+func (s *CatalogWatchService) gapChanges(ctx context.Context, gap *skippedGap) ([]*models.CatalogChange, error) {
+	var result []*models.CatalogChange
+	after := gap.from - 1
+	for after < gap.to {
+		limit := int(min(gap.to-after, watchBatchSize))
+		changes, err := s.changes.ListSince(ctx, after, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range changes {
+			if change.Sequence > gap.to {
+				return result, nil
+			}
+			after = change.Sequence
+			result = append(result, change)
+		}
+		if len(changes) < limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (s *CatalogWatchService) WatchModels(ctx context.Context, filter models.ModelWatchFilter, resumeToken string, send func(*models.WatchEvent) error) error {
+	ctx, span := tracing.StartSpan(ctx, "CatalogWatchService.WatchModels",
+		attribute.Bool("watch.resume", resumeToken != ""),
+	)
+	defer span.End()
+
+	snapshot := func(ctx context.Context) ([]*models.WatchEvent, error) {
+		var list []*models.SmartModel
+		var err error
+		if filter.Type != nil {
+			list, err = s.modelRepo.GetWithType(ctx, *filter.Type)
+		} else {
+			list, err = s.modelRepo.GetAll(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var events []*models.WatchEvent
+		for _, model := range list {
+			if filter.Matches(model) {
+				events = append(events, &models.WatchEvent{Operation: models.ChangeExisting, Model: model})
+			}
+		}
+		return events, nil
+	}
+
+	translate := func(change *models.CatalogChange) *models.WatchEvent {
+		if change.Entity != models.SmartModelAggregate {
+			return nil
+		}
+		operation, current := filteredOperation(change.Operation, filter.Matches(change.OldModel), filter.Matches(change.NewModel))
+		if operation == "" {
+			return nil
+		}
+		model := change.NewModel
+		if !current {
+			model = change.OldModel
+		}
+		return &models.WatchEvent{Operation: operation, Model: model}
+	}
+
+	err := s.watch(ctx, resumeToken, nil, snapshot, translate, send)
+	if err != nil && ctx.Err() == nil {
+		tracing.RecordError(span, err)
+	}
+	return err
+}
+
+// WatchFeatures matches features against their model's type and category as
+// the model was when the feature changed. A model that later changes type or
+// category does not move its features into or out of the watch.
+func (s *CatalogWatchService) WatchFeatures(ctx context.Context, filter models.FeatureWatchFilter, resumeToken string, send func(*models.WatchEvent) error) error {
+	ctx, span := tracing.StartSpan(ctx, "CatalogWatchService.WatchFeatures",
+		attribute.Bool("watch.resume", resumeToken != ""),
+	)
+	defer span.End()
+
+	known := make(watchedModels)
+	var prepare func(context.Context) error
+	if filter.FiltersModel() {
+		prepare = func(ctx context.Context) error {
+			list, err := s.modelRepo.GetAll(ctx)
+			if err != nil {
+				return err
+			}
+			for _, model := range list {
+				known[model.ID] = model
+			}
+			return nil
+		}
+	}
+
+	snapshot := func(ctx context.Context) ([]*models.WatchEvent, error) {
+		var list []*models.SmartFeature
+		var err error
+		if filter.ModelID != nil {
+			list, err = s.featureRepo.GetWithModelID(ctx, filter.ModelID.String())
+		} else {
+			list, err = s.featureRepo.GetAll(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var events []*models.WatchEvent
+		for _, feature := range list {
+			if filter.Matches(feature, known[feature.ModelID]) {
+				events = append(events, &models.WatchEvent{Operation: models.ChangeExisting, Feature: feature})
+			}
+		}
+		return events, nil
+	}
+
+	translate := func(change *models.CatalogChange) *models.WatchEvent {
+		if change.Entity == models.SmartModelAggregate && prepare != nil {
+			known.apply(change)
+		}
+		if change.Entity != models.SmartFeatureAggregate {
+			return nil
+		}
+		oldMatch := change.OldFeature != nil && filter.Matches(change.OldFeature, known[change.OldFeature.ModelID])
+		newMatch := change.NewFeature != nil && filter.Matches(change.NewFeature, known[change.NewFeature.ModelID])
+		operation, current := filteredOperation(change.Operation, oldMatch, newMatch)
+		if operation == "" {
+			return nil
+		}
+		feature := change.NewFeature
+		if !current {
+			feature = change.OldFeature
+		}
+		return &models.WatchEvent{Operation: operation, Feature: feature}
+	}
+
+	err := s.watch(ctx, resumeToken, prepare, snapshot, translate, send)
+	if err != nil && ctx.Err() == nil {
+		tracing.RecordError(span, err)
+	}
+	return err
+}
+
+// watchedModels is the last known state of every model, for feature watches
+// that filter on the model's type or category. It is loaded when the watch
+// starts and kept current from the model changes on the stream. A deleted
+// model keeps its last state, so the deletions of its features still match.
+type watchedModels map[uuid.UUID]*models.SmartModel
+
+func (w watchedModels) apply(change *models.CatalogChange) {
+	if change.NewModel != nil {
+		w[change.NewModel.ID] = change.NewModel
+	} else if change.OldModel != nil {
+		w[change.OldModel.ID] = change.OldModel
+	}
+}
+
+// watch subscribes before reading the snapshot or the catch-up range, so no
+// change can fall between them and the live stream. prepare, if set, runs
+// right after subscribing, for state that translate keeps up to date from
+// the stream.
+func (s *CatalogWatchService) watch(
+	ctx context.Context,
+	resumeToken string,
+	prepare func(context.Context) error,
+	snapshot func(context.Context) ([]*models.WatchEvent, error),
+	translate func(*models.CatalogChange) *models.WatchEvent,
+	send func(*models.WatchEvent) error,
+) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.ready:
+	}
+
+	sub, head, err := s.subscribe()
+	if err != nil {
+		return err
+	}
+	defer s.unsubscribe(sub)
+
+	if prepare != nil {
+		if err := prepare(database.ForcePrimary(ctx)); err != nil {
+			return err
+		}
+	}
+
+	headToken := formatResumeToken(head)
+	if resumeToken == "" {
+		// A replica may not have caught up with head yet.
+		events, err := snapshot(database.ForcePrimary(ctx))
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			event.ResumeToken = headToken
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+		if err := send(&models.WatchEvent{Operation: models.ChangeSynced, ResumeToken: headToken}); err != nil {
+			return err
+		}
+	} else if err := s.catchUp(ctx, resumeToken, head, translate, send); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sub.done:
+			return sub.err
+		case delivery := <-sub.changes:
+			event := translate(delivery.change)
+			if event == nil {
+				continue
+			}
+			event.ResumeToken = formatResumeToken(delivery.token)
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// catchUp replays the logged changes between resumeToken and head.
+func (s *CatalogWatchService) catchUp(
+	ctx context.Context,
+	resumeToken string,
+	head int64,
+	translate func(*models.CatalogChange) *models.WatchEvent,
+	send func(*models.WatchEvent) error,
+) error {
+	after, err := parseResumeToken(resumeToken)
+	if err != nil || after > head {
+		return models.ErrInvalidResumeToken
+	}
+	if after == head {
+		return nil
+	}
+
+	oldest, err := s.changes.OldestSequence(ctx)
+	if err != nil {
+		return err
+	}
+	if oldest == 0 || after < oldest-1 {
+		return models.ErrResumeTokenExpired
+	}
+
+	for after < head {
+		changes, err := s.changes.ListSince(ctx, after, watchBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		for _, change := range changes {
+			if change.Sequence > head {
+				return nil
+			}
+			after = change.Sequence
+			event := translate(change)
+			if event == nil {
+				continue
+			}
+			event.ResumeToken = formatResumeToken(change.Sequence)
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *CatalogWatchService) subscribe() (*watchSubscriber, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return nil, 0, models.ErrWatchStopped
+	}
+	sub := &watchSubscriber{
+		changes: make(chan watchDelivery, s.bufferSize),
+		done:    make(chan struct{}),
+	}
+	s.subs[sub] = struct{}{}
+	return sub, s.cursor, nil
+}
+
+func (s *CatalogWatchService) unsubscribe(sub *watchSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, sub)
+}
+
+// dispatch never blocks the reader: a subscriber whose buffer is full is
+// dropped with ErrSlowConsumer. Must be called with s.mu held.
+func (s *CatalogWatchService) dispatch(change *models.CatalogChange, token int64) {
+	for sub := range s.subs {
+		select {
+		case sub.changes <- watchDelivery{change: change, token: token}:
+		default:
+			logger.Warn("Dropping slow catalog watcher", "sequence", change.Sequence)
+			s.closeSubscriber(sub, models.ErrSlowConsumer)
+		}
+	}
+}
+
+func (s *CatalogWatchService) closeSubscriber(sub *watchSubscriber, err error) {
+	sub.err = err
+	close(sub.done)
+	delete(s.subs, sub)
+}
+
+func (s *CatalogWatchService) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	for sub := range s.subs {
+		s.closeSubscriber(sub, models.ErrWatchStopped)
+	}
+}
+
+func (s *CatalogWatchService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *CatalogWatchService) notifyAfter(delay time.Duration) {
+	time.AfterFunc(delay, s.notify)
+}
+
+func (s *CatalogWatchService) prune(ctx context.Context) {
+	pruned, err := s.changes.Prune(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		logger.Error("Failed to prune catalog changes", err)
+		return
+	}
+	if pruned > 0 {
+		logger.Debug("Pruned catalog changes", "count", pruned)
+	}
+}
+
+// filteredOperation maps a change onto a filtered view: an update that moves
+// an entity into the filter is a creation for the watcher, one that moves it
+// out is a deletion. current reports whether the new state is the one to
+// send.
+func filteredOperation(operation models.ChangeOperation, oldMatch, newMatch bool) (models.ChangeOperation, bool) {
+	switch {
+	case operation == models.ChangeCreated && newMatch:
+		return models.ChangeCreated, true
+	case operation == models.ChangeDeleted && oldMatch:
+		return models.ChangeDeleted, false
+	case operation == models.ChangeUpdated && oldMatch && newMatch:
+		return models.ChangeUpdated, true
+	case operation == models.ChangeUpdated && newMatch:
+		return models.ChangeCreated, true
+	case operation == models.ChangeUpdated && oldMatch:
+		return models.ChangeDeleted, false
+	}
+	return "", false
+}
+
+func formatResumeToken(sequence int64) string {
+	return strconv.FormatInt(sequence, 10)
+}
+
+func parseResumeToken(token string) (int64, error) {
+	sequence, err := strconv.ParseInt(token, 10, 64)
+	if err != nil || sequence < 0 {
+		return 0, models.ErrInvalidResumeToken
+	}
+	return sequence, nil
+}
@@ -0,0 +1,448 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeCatalogChangeRepo struct {
+	mu      sync.Mutex
+	changes []*models.CatalogChange
+}
+
+func (r *fakeCatalogChangeRepo) add(changes ...*models.CatalogChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, changes...)
+	slices.SortFunc(r.changes, func(a, b *models.CatalogChange) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+}
+
+func (r *fakeCatalogChangeRepo) ListSince(ctx context.Context, after int64, limit int) ([]*models.CatalogChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []*models.CatalogChange
+	for _, change := range r.changes {
+		if change.Sequence > after && len(result) < limit {
+			result = append(result, change)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeCatalogChangeRepo) LatestSequence(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.changes) == 0 {
+		return 0, nil
+	}
+	return r.changes[len(r.changes)-1].Sequence, nil
+}
+
+func (r *fakeCatalogChangeRepo) OldestSequence(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.changes) == 0 {
+		return 0, nil
+	}
+	return r.changes[0].Sequence, nil
+}
+
+func (r *fakeCatalogChangeRepo) Prune(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func modelChange(sequence int64, operation models.ChangeOperation, oldModel, newModel *models.SmartModel) *models.CatalogChange {
+	change := &models.CatalogChange{
+		Sequence:  sequence,
+		Entity:    models.SmartModelAggregate,
+		Operation: operation,
+		OldModel:  oldModel,
+		NewModel:  newModel,
+	}
+	if newModel != nil {
+		change.EntityID = newModel.ID
+	} else {
+		change.EntityID = oldModel.ID
+	}
+	change.ModelID = change.EntityID
+	return change
+}
+
+func startWatchService(t *testing.T, changes *fakeCatalogChangeRepo, modelRepo *mockSmartModelRepo, bufferSize int) (*CatalogWatchService, context.CancelFunc) {
+	svc := NewCatalogWatchService(changes, nil, modelRepo, new(mockSmartFeatureRepo), bufferSize, time.Hour, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	go svc.Run(ctx)
+
+	select {
+	case <-svc.ready:
+	case <-time.After(time.Second):
+		t.Fatal("watch service did not start")
+	}
+	return svc, cancel
+}
+
+func waitForSubscribers(t *testing.T, svc *CatalogWatchService, count int) {
+	assert.Eventually(t, func() bool {
+		svc.mu.Lock()
+		defer svc.mu.Unlock()
+		return len(svc.subs) == count
+	}, time.Second, time.Millisecond)
+}
+
+func receiveEvent(t *testing.T, events <-chan *models.WatchEvent) *models.WatchEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no watch event received")
+		return nil
+	}
+}
+
+func TestCatalogWatchService_WatchModels_SnapshotThenLive(t *testing.T) {
+	changes := &fakeCatalogChangeRepo{}
+	modelRepo := new(mockSmartModelRepo)
+	camera := &models.SmartModel{ID: uuid.New(), Type: models.DeviceType, Category: models.CameraCategory}
+	weather := &models.SmartModel{ID: uuid.New(), Type: models.DeviceType, Category: models.WeatherCategory}
+	modelRepo.On("GetWithType", mock.Anything, models.DeviceType).Return([]*models.SmartModel{camera, weather}, nil)
+
+	svc, stop := startWatchService(t, changes, modelRepo, 16)
+	defer stop()
+
+	modelType := models.DeviceType
+	category := models.CameraCategory
+	filter := models.ModelWatchFilter{Type: &modelType, Category: &category}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan *models.WatchEvent, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.WatchModels(ctx, filter, "", func(event *models.WatchEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	existing := receiveEvent(t, events)
+	assert.Equal(t, models.ChangeExisting, existing.Operation)
+	assert.Equal(t, camera, existing.Model)
+	synced := receiveEvent(t, events)
+	assert.Equal(t, models.ChangeSynced, synced.Operation)
+	assert.Equal(t, "0", synced.ResumeToken)
+
+	waitForSubscribers(t, svc, 1)
+	created := &models.SmartModel{ID: uuid.New(), Type: models.DeviceType, Category: models.CameraCategory}
+	changes.add(
+		modelChange(1, models.ChangeCreated, nil, weather),
+		modelChange(2, models.ChangeCreated, nil, created),
+	)
+	require.NoError(t, svc.poll(context.Background()))
+
+	event := receiveEvent(t, events)
+	assert.Equal(t, models.ChangeCreated, event.Operation)
+	assert.Equal(t, created, event.Model)
+	assert.Equal(t, "2", event.ResumeToken)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	modelRepo.AssertExpectations(t)
+}
+
+func TestCatalogWatchService_WatchModels_Resume(t *testing.T) {
+	changes := &fakeCatalogChangeRepo{}
+	model := &models.SmartModel{ID: uuid.New(), Category: models.CameraCategory}
+	moved := &models.SmartModel{ID: model.ID, Category: models.WeatherCategory}
+	changes.add(
+		modelChange(1, models.ChangeCreated, nil, model),
+		modelChange(2, models.ChangeUpdated, model, model),
+		modelChange(3, models.ChangeUpdated, model, moved),
+	)
+
+	svc, stop := startWatchService(t, changes, new(mockSmartModelRepo), 16)
+	defer stop()
+
+	category := models.CameraCategory
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan *models.WatchEvent, 16)
+	go func() {
+		_ = svc.WatchModels(ctx, models.ModelWatchFilter{Category: &category}, "1", func(event *models.WatchEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+	defer cancel()
+
+	updated := receiveEvent(t, events)
+	assert.Equal(t, models.ChangeUpdated, updated.Operation)
+	assert.Equal(t, "2", updated.ResumeToken)
+
+	// Moving out of the filter is a deletion for this watcher.
+	deleted := receiveEvent(t, events)
+	assert.Equal(t, models.ChangeDeleted, deleted.Operation)
+	assert.Equal(t, model, deleted.Model)
+	assert.Equal(t, "3", deleted.ResumeToken)
+}
+
+func featureChange(sequence int64, operation models.ChangeOperation, oldFeature, newFeature *models.SmartFeature) *models.CatalogChange {
+	change := &models.CatalogChange{
+		Sequence:   sequence,
+		Entity:     models.SmartFeatureAggregate,
+		Operation:  operation,
+		OldFeature: oldFeature,
+		NewFeature: newFeature,
+	}
+	feature := newFeature
+	if feature == nil {
+		feature = oldFeature
+	}
+	change.EntityID = feature.ID
+	change.ModelID = feature.ModelID
+	return change
+}
+
+func TestCatalogWatchService_WatchFeatures_ModelCategory(t *testing.T) {
+	changes := &fakeCatalogChangeRepo{}
+	modelRepo := new(mockSmartModelRepo)
+	featureRepo := new(mockSmartFeatureRepo)
+	camera := &models.SmartModel{ID: uuid.New(), Type: models.DeviceType, Category: models.CameraCategory}
+	weather := &models.SmartModel{ID: uuid.New(), Type: models.DeviceType, Category: models.WeatherCategory}
+	lens := &models.SmartFeature{ID: uuid.New(), ModelID: camera.ID, Name: "lens"}
+	forecast := &models.SmartFeature{ID: uuid.New(), ModelID: weather.ID, Name: "forecast"}
+	modelRepo.On("GetAll", mock.Anything).Return([]*models.SmartModel{camera, weather}, nil)
+	featureRepo.On("GetAll", mock.Anything).Return([]*models.SmartFeature{lens, forecast}, nil)
+
+	svc := NewCatalogWatchService(changes, nil, modelRepo, featureRepo, 16, time.Hour, time.Hour)
+	runCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go svc.Run(runCtx)
+
+	category := models.CameraCategory
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan *models.WatchEvent, 16)
+	go func() {
+		_ = svc.WatchFeatures(ctx, models.FeatureWatchFilter{ModelCategory: &category}, "", func(event *models.WatchEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	existing := receiveEvent(t, events)
+	assert.Equal(t, models.ChangeExisting, existing.Operation)
+	assert.Equal(t, lens, existing.Feature)
+	assert.Equal(t, models.ChangeSynced, receiveEvent(t, events).Operation)
+
+	waitForSubscribers(t, svc, 1)
+	doorbell := &models.SmartModel{ID: uuid.New(), Type: models.DeviceType, Category: models.CameraCategory}
+	ring := &models.SmartFeature{ID: uuid.New(), ModelID: doorbell.ID, Name: "ring"}
+	rain := &models.SmartFeature{ID: uuid.New(), ModelID: weather.ID, Name: "rain"}
+	changes.add(
+		modelChange(1, models.ChangeCreated, nil, doorbell),
+		featureChange(2, models.ChangeCreated, nil, ring),
+		featureChange(3, models.ChangeCreated, nil, rain),
+		// The model goes first; its features' deletions still match.
+		modelChange(4, models.ChangeDeleted, camera, nil),
+		featureChange(5, models.ChangeDeleted, lens, nil),
+	)
+	require.NoError(t, svc.poll(context.Background()))
+
+	created := receiveEvent(t, events)
+	assert.Equal(t, models.ChangeCreated, created.Operation)
+	assert.Equal(t, ring, created.Feature)
+	assert.Equal(t, "2", created.ResumeToken)
+
+	deleted := receiveEvent(t, events)
+	assert.Equal(t, models.ChangeDeleted, deleted.Operation)
+	assert.Equal(t, lens, deleted.Feature)
+	assert.Equal(t, "5", deleted.ResumeToken)
+
+	modelRepo.AssertExpectations(t)
+	featureRepo.AssertExpectations(t)
+}
+
+func TestCatalogWatchService_WatchModels_ResumeTokenErrors(t *testing.T) {
+	changes := &fakeCatalogChangeRepo{}
+	model := &models.SmartModel{ID: uuid.New()}
+	changes.add(
+		modelChange(5, models.ChangeCreated, nil, model),
+		modelChange(6, models.ChangeDeleted, model, nil),
+	)
+
+	svc, stop := startWatchService(t, changes, new(mockSmartModelRepo), 16)
+	defer stop()
+
+	send := func(*models.WatchEvent) error { return nil }
+	tests := []struct {
+		token string
+		err   error
+	}{
+		{"abc", models.ErrInvalidResumeToken},
+		{"-1", models.ErrInvalidResumeToken},
+		{"7", models.ErrInvalidResumeToken},
+		{"2", models.ErrResumeTokenExpired},
+	}
+
+	for _, tt := range tests {
+		err := svc.WatchModels(context.Background(), models.ModelWatchFilter{}, tt.token, send)
+		assert.ErrorIs(t, err, tt.err, "token %q", tt.token)
+	}
+}
+
+func TestCatalogWatchService_DropsSlowConsumer(t *testing.T) {
+	changes := &fakeCatalogChangeRepo{}
+	svc, stop := startWatchService(t, changes, new(mockSmartModelRepo), 1)
+	defer stop()
+
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.WatchModels(context.Background(), models.ModelWatchFilter{}, "0", func(*models.WatchEvent) error {
+			<-release
+			return nil
+		})
+	}()
+
+	waitForSubscribers(t, svc, 1)
+	for i := int64(1); i <= 3; i++ {
+		changes.add(modelChange(i, models.ChangeCreated, nil, &models.SmartModel{ID: uuid.New()}))
+	}
+	require.NoError(t, svc.poll(context.Background()))
+	close(release)
+
+	assert.ErrorIs(t, <-done, models.ErrSlowConsumer)
+	waitForSubscribers(t, svc, 0)
+}
+
+func TestCatalogWatchService_HoldsBackAfterGap(t *testing.T) {
+	changes := &fakeCatalogChangeRepo{}
+	svc, stop := startWatchService(t, changes, new(mockSmartModelRepo), 16)
+	defer stop()
+
+	changes.add(
+		modelChange(1, models.ChangeCreated, nil, &models.SmartModel{ID: uuid.New()}),
+		modelChange(3, models.ChangeCreated, nil, &models.SmartModel{ID: uuid.New()}),
+	)
+	require.NoError(t, svc.poll(context.Background()))
+
+	svc.mu.Lock()
+	assert.Equal(t, int64(1), svc.cursor)
+	svc.gapSince = time.Now().Add(-watchGapTimeout)
+	svc.mu.Unlock()
+
+	require.NoError(t, svc.poll(context.Background()))
+
+	svc.mu.Lock()
+	assert.Equal(t, int64(3), svc.cursor)
+	require.Len(t, svc.skipped, 1)
+	assert.Equal(t, int64(2), svc.skipped[0].from)
+	assert.Equal(t, int64(2), svc.skipped[0].to)
+	svc.mu.Unlock()
+}
+
+func TestCatalogWatchService_DeliversChangesAfterSkippedGap(t *testing.T) {
+	changes := &fakeCatalogChangeRepo{}
+	svc, stop := startWatchService(t, changes, new(mockSmartModelRepo), 16)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan *models.WatchEvent, 16)
+	go func() {
+		_ = svc.WatchModels(ctx, models.ModelWatchFilter{}, "0", func(event *models.WatchEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+	waitForSubscribers(t, svc, 1)
+
+	changes.add(
+		modelChange(1, models.ChangeCreated, nil, &models.SmartModel{ID: uuid.New()}),
+		modelChange(4, models.ChangeCreated, nil, &models.SmartModel{ID: uuid.New()}),
+	)
+	require.NoError(t, svc.poll(context.Background()))
+	svc.mu.Lock()
+	svc.gapSince = time.Now().Add(-watchGapTimeout)
+	svc.mu.Unlock()
+	require.NoError(t, svc.poll(context.Background()))
+
+	assert.Equal(t, "1", receiveEvent(t, events).ResumeToken)
+	assert.Equal(t, "4", receiveEvent(t, events).ResumeToken)
+
+	// A long transaction commits sequence 3 after the reader moved on.
+	late := &models.SmartModel{ID: uuid.New()}
+	changes.add(modelChange(3, models.ChangeCreated, nil, late))
+	require.NoError(t, svc.poll(context.Background()))
+
+	event := receiveEvent(t, events)
+	assert.Equal(t, late, event.Model)
+	assert.Equal(t, "4", event.ResumeToken, "a late change must not move the token backwards")
+
+	// Rereading does not deliver it twice; the gap stays until 2 turns up.
+	require.NoError(t, svc.poll(context.Background()))
+	assert.Empty(t, events)
+	svc.mu.Lock()
+	require.Len(t, svc.skipped, 1)
+	svc.mu.Unlock()
+
+	changes.add(modelChange(2, models.ChangeCreated, nil, &models.SmartModel{ID: uuid.New()}))
+	require.NoError(t, svc.poll(context.Background()))
+	assert.Equal(t, "4", receiveEvent(t, events).ResumeToken)
+	svc.mu.Lock()
+	assert.Empty(t, svc.skipped)
+	svc.mu.Unlock()
+}
+
+func TestCatalogWatchService_StopEndsWatches(t *testing.T) {
+	svc, stop := startWatchService(t, &fakeCatalogChangeRepo{}, new(mockSmartModelRepo), 16)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.WatchModels(context.Background(), models.ModelWatchFilter{}, "0", func(*models.WatchEvent) error {
+			return nil
+		})
+	}()
+
+	waitForSubscribers(t, svc, 1)
+	stop()
+
+	assert.ErrorIs(t, <-done, models.ErrWatchStopped)
+	assert.ErrorIs(t, svc.WatchModels(context.Background(), models.ModelWatchFilter{}, "0", nil), models.ErrWatchStopped)
+}
+
+func TestFilteredOperation(t *testing.T) {
+	tests := []struct {
+		operation models.ChangeOperation
+		oldMatch  bool
+		newMatch  bool
+		expected  models.ChangeOperation
+		current   bool
+	}{
+		{models.ChangeCreated, false, true, models.ChangeCreated, true},
+		{models.ChangeCreated, false, false, "", false},
+		{models.ChangeDeleted, true, false, models.ChangeDeleted, false},
+		{models.ChangeUpdated, true, true, models.ChangeUpdated, true},
+		{models.ChangeUpdated, false, true, models.ChangeCreated, true},
+		{models.ChangeUpdated, true, false, models.ChangeDeleted, false},
+		{models.ChangeUpdated, false, false, "", false},
+	}
+
+	for _, tt := range tests {
+		operation, current := filteredOperation(tt.operation, tt.oldMatch, tt.newMatch)
+		assert.Equal(t, tt.expected, operation)
+		assert.Equal(t, tt.current, current)
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronSearch bounds how far ahead the next time of a cron expression is
+// searched, so expressions that never match, e.g. "0 0 30 2 *", end.
+const maxCronSearch = 5 // years
+
+// cronMacros are the shorthands accepted in place of the five fields.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes one of the five fields of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDay    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: []string{
+		"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec",
+	}}
+	// The day of week accepts 7 for Sunday too.
+	cronWeekday = cronField{name: "day of week", min: 0, max: 7, names: []string{
+		"sun", "mon", "tue", "wed", "thu", "fri", "sat",
+	}}
+)
+
+// cronSchedule is a parsed cron expression. Each field is a bit set of the
+// values it matches. As in Vixie cron, when both the day of month and the
+// day of week are restricted, a day matches if either does.
+type cronSchedule struct {
+	minute, hour, day, month, weekday uint64
+	dayStar, weekdayStar              bool
+}
+
+// parseCron parses a standard five-field cron expression or one of the
+// cronMacros. Fields are lists of values, ranges and steps, e.g.
+// "0,30 8-18/2 * jan-jun mon-fri".
+func parseCron(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	var c cronSchedule
+	var err error
+	if c.minute, err = cronMinute.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if c.hour, err = cronHour.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if c.day, err = cronDay.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if c.month, err = cronMonth.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if c.weekday, err = cronWeekday.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if c.weekday&(1<<7) != 0 {
+		c.weekday |= 1 << 0
+	}
+	c.dayStar = strings.HasPrefix(fields[2], "*")
+	c.weekdayStar = strings.HasPrefix(fields[4], "*")
+	return &c, nil
+}
+
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		low, high := f.min, f.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = f.value(lowPart); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(highPart); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("%s: range %q is backwards", f.name, rangePart)
+			}
+		default:
+			var err error
+			if low, err = f.value(rangePart); err != nil {
+				return 0, err
+			}
+			// "a/n" runs from a to the end of the field.
+			if !hasStep {
+				high = low
+			}
+		}
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("%s: invalid step %q", f.name, stepPart)
+			}
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return i + f.min, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid value %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: %d is out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// next returns the first time after after at which the expression matches
+// in loc, or false if there is none within maxCronSearch years. Times that
+// fall into a daylight saving gap are skipped, and the wall clock times
+// repeated when the clocks go back only match once.
+func (c *cronSchedule) next(after time.Time, loc *time.Location) (time.Time, bool) {
+	after = after.In(loc)
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Year() + maxCronSearch
+
+	for t.Year() <= limit {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			// Adding to the absolute time keeps stepping forward through
+			// daylight saving changes.
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case c.minute&(1<<uint(t.Minute())) == 0 || !wallClockAfter(t, after):
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	day := c.day&(1<<uint(t.Day())) != 0
+	weekday := c.weekday&(1<<uint(t.Weekday())) != 0
+	if c.dayStar || c.weekdayStar {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// wallClockAfter compares the local wall clock times of t and u, ignoring
+// their UTC offsets.
+func wallClockAfter(t, u time.Time) bool {
+	wall := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	return wall(t).After(wall(u))
+}
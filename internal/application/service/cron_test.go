@@ -0,0 +1,96 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	tests := []struct {
+		expr    string
+		message string
+	}{
+		{"* * * *", "expected 5 fields, got 4"},
+		{"60 * * * *", "minute: 60 is out of range 0-59"},
+		{"* 24 * * *", "hour: 24 is out of range 0-23"},
+		{"* * 0 * *", "day of month: 0 is out of range 1-31"},
+		{"* * * foo *", `month: invalid value "foo"`},
+		{"* * * * 8", "day of week: 8 is out of range 0-7"},
+		{"5-1 * * * *", `minute: range "5-1" is backwards`},
+		{"*/0 * * * *", `minute: invalid step "0"`},
+		{"@reboot", "expected 5 fields, got 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parseCron(tt.expr)
+			assert.EqualError(t, err, tt.message)
+		})
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	// Friday.
+	from := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		loc  *time.Location
+		want time.Time
+	}{
+		{"every minute", "* * * * *", time.UTC, time.Date(2024, 3, 1, 12, 1, 0, 0, time.UTC)},
+		{"step", "*/20 * * * *", time.UTC, time.Date(2024, 3, 1, 12, 20, 0, 0, time.UTC)},
+		{"list and range", "0 8-18/4,20 * * *", time.UTC, time.Date(2024, 3, 1, 16, 0, 0, 0, time.UTC)},
+		{"later today", "30 14 * * *", time.UTC, time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC)},
+		{"tomorrow", "30 7 * * *", time.UTC, time.Date(2024, 3, 2, 7, 30, 0, 0, time.UTC)},
+		{"weekdays", "30 7 * * mon-fri", time.UTC, time.Date(2024, 3, 4, 7, 30, 0, 0, time.UTC)},
+		{"sunday as 7", "0 9 * * 7", time.UTC, time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC)},
+		{"day of month or weekday", "0 0 15 * 1", time.UTC, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 feb *", time.UTC, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"macro", "@monthly", time.UTC, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"timezone", "0 13 * * *", berlin, time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := parseCron(tt.expr)
+			require.NoError(t, err)
+
+			next, ok := cron.next(from, tt.loc)
+
+			require.True(t, ok)
+			assert.True(t, tt.want.Equal(next), "want %v, got %v", tt.want, next)
+		})
+	}
+}
+
+func TestCronSchedule_NextNeverMatches(t *testing.T) {
+	cron, err := parseCron("0 0 30 2 *")
+	require.NoError(t, err)
+
+	_, ok := cron.next(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), time.UTC)
+	assert.False(t, ok)
+}
+
+func TestCronSchedule_NextAcrossDaylightSaving(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// 02:30 does not exist on 2024-03-31; that day is skipped.
+	cron, err := parseCron("30 2 * * *")
+	require.NoError(t, err)
+	next, ok := cron.next(time.Date(2024, 3, 30, 3, 0, 0, 0, berlin), berlin)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2024, 4, 1, 2, 30, 0, 0, berlin), next)
+
+	// 02:30 happens twice on 2024-10-27 but runs once.
+	next, ok = cron.next(time.Date(2024, 10, 27, 0, 0, 0, 0, berlin), berlin)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2024, 10, 27, 0, 30, 0, 0, time.UTC), next.UTC())
+	next, ok = cron.next(next, berlin)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2024, 10, 28, 2, 30, 0, 0, berlin), next)
+}
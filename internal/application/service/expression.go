@@ -0,0 +1,520 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Rule conditions are small boolean expressions:
+//
+//	hour >= 22 && state("porch-light", "<feature-id>") != true
+//
+// They support numbers, strings, true, false and null, the operators ||,
+// &&, !, ==, !=, <, <=, > and >=, parentheses, member access with a dot and
+// the function state(device, feature), which returns the value a device
+// last reported for a feature, or null. The variables are hour, minute, weekday
+// (0 is Sunday), time ("HH:MM") in the rule's timezone, and trigger, which
+// holds device_id, feature_id and value of the signal that fired the rule.
+// Strings and numbers compare with < and >, values of any type with == and
+// !=; && and || only take booleans.
+
+// conditionVariables are the names a condition may refer to.
+var conditionVariables = map[string]struct{}{
+	"hour":    {},
+	"minute":  {},
+	"weekday": {},
+	"time":    {},
+	"trigger": {},
+}
+
+// conditionEnv is what a condition is evaluated against.
+type conditionEnv struct {
+	vars  map[string]interface{}
+	state func(deviceID, featureID string) (interface{}, error)
+}
+
+type expression interface {
+	eval(env *conditionEnv) (interface{}, error)
+}
+
+// parseCondition compiles a condition. Syntax errors, unknown variables and
+// unknown functions wrap models.ErrInvalidAutomationRule at the caller.
+func parseCondition(source string) (expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &conditionParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at offset %d", tok, tok.pos)
+	}
+	return expr, nil
+}
+
+// evalCondition evaluates a compiled condition, which must yield a boolean.
+func evalCondition(expr expression, env *conditionEnv) (bool, error) {
+	value, err := expr.eval(env)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("condition is %s, not a boolean", typeName(value))
+	}
+	return result, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of condition"
+	}
+	return strconv.Quote(t.text)
+}
+
+var conditionOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", ",", ".", "-"}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			number, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at offset %d", text, start)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: number, pos: start})
+		case r == '"' || r == '\'':
+			start := i
+			var b strings.Builder
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated string at offset %d", start)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[start:i]), value: b.String(), pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		default:
+			rest := string(runes[i:])
+			matched := false
+			for _, op := range conditionOperators {
+				if strings.HasPrefix(rest, op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected %q at offset %d", r, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+type conditionParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *conditionParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *conditionParser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == tokenOperator && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) expect(op string) error {
+	if !p.accept(op) {
+		tok := p.peek()
+		return fmt.Errorf("expected %q, got %s at offset %d", op, tok, tok.pos)
+	}
+	return nil
+}
+
+func (p *conditionParser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (expression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseNot() (expression, error) {
+	if p.accept("!") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (expression, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parsePostfix()
+			if err != nil {
+				return nil, err
+			}
+			return &comparisonExpr{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parsePostfix() (expression, error) {
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(".") {
+		tok := p.next()
+		if tok.kind != tokenIdent {
+			return nil, fmt.Errorf("expected a field name, got %s at offset %d", tok, tok.pos)
+		}
+		expr = &memberExpr{object: expr, field: tok.text}
+	}
+	return expr, nil
+}
+
+func (p *conditionParser) parsePrimary() (expression, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber, tokenString:
+		return &literalExpr{value: tok.value}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalExpr{value: true}, nil
+		case "false":
+			return &literalExpr{value: false}, nil
+		case "null":
+			return &literalExpr{value: nil}, nil
+		}
+		if p.accept("(") {
+			return p.parseCall(tok)
+		}
+		if _, ok := conditionVariables[tok.text]; !ok {
+			return nil, fmt.Errorf("unknown variable %q at offset %d", tok.text, tok.pos)
+		}
+		return &variableExpr{name: tok.text}, nil
+	case tokenOperator:
+		if tok.text == "-" {
+			if number := p.peek(); number.kind == tokenNumber {
+				p.next()
+				return &literalExpr{value: -number.value.(float64)}, nil
+			}
+		}
+		if tok.text == "(" {
+			expr, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return expr, p.expect(")")
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s at offset %d", tok, tok.pos)
+}
+
+func (p *conditionParser) parseCall(name token) (expression, error) {
+	if name.text != "state" {
+		return nil, fmt.Errorf("unknown function %q at offset %d", name.text, name.pos)
+	}
+	var args []expression
+	if !p.accept(")") {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(args) != 2 {
+		return nil, fmt.Errorf("state takes a device and a feature, got %d arguments at offset %d", len(args), name.pos)
+	}
+	return &stateExpr{device: args[0], feature: args[1]}, nil
+}
+
+type literalExpr struct {
+	value interface{}
+}
+
+func (e *literalExpr) eval(*conditionEnv) (interface{}, error) {
+	return e.value, nil
+}
+
+type variableExpr struct {
+	name string
+}
+
+func (e *variableExpr) eval(env *conditionEnv) (interface{}, error) {
+	return env.vars[e.name], nil
+}
+
+// memberExpr reads a field of an object; a missing field, or a field of
+// null, is null.
+type memberExpr struct {
+	object expression
+	field  string
+}
+
+func (e *memberExpr) eval(env *conditionEnv) (interface{}, error) {
+	object, err := e.object.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch object := object.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return object[e.field], nil
+	}
+	return nil, fmt.Errorf("cannot read %q of %s", e.field, typeName(object))
+}
+
+type stateExpr struct {
+	device  expression
+	feature expression
+}
+
+func (e *stateExpr) eval(env *conditionEnv) (interface{}, error) {
+	device, err := evalString(e.device, env, "state device")
+	if err != nil {
+		return nil, err
+	}
+	feature, err := evalString(e.feature, env, "state feature")
+	if err != nil {
+		return nil, err
+	}
+	return env.state(device, feature)
+}
+
+type notExpr struct {
+	operand expression
+}
+
+func (e *notExpr) eval(env *conditionEnv) (interface{}, error) {
+	value, err := e.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("! needs a boolean, got %s", typeName(value))
+	}
+	return !b, nil
+}
+
+// logicalExpr short-circuits, so the right side is only evaluated, and
+// state only looked up, when it decides the result.
+type logicalExpr struct {
+	op          string
+	left, right expression
+}
+
+func (e *logicalExpr) eval(env *conditionEnv) (interface{}, error) {
+	left, err := evalBool(e.left, env, e.op)
+	if err != nil {
+		return nil, err
+	}
+	if (e.op == "&&" && !left) || (e.op == "||" && left) {
+		return left, nil
+	}
+	return evalBool(e.right, env, e.op)
+}
+
+type comparisonExpr struct {
+	op          string
+	left, right expression
+}
+
+func (e *comparisonExpr) eval(env *conditionEnv) (interface{}, error) {
+	left, err := e.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "==":
+		return conditionEqual(left, right), nil
+	case "!=":
+		return !conditionEqual(left, right), nil
+	}
+
+	var cmp int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare number %s %s", e.op, typeName(right))
+		}
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare string %s %s", e.op, typeName(right))
+		}
+		cmp = strings.Compare(l, r)
+	default:
+		return nil, fmt.Errorf("cannot compare %s %s %s", typeName(left), e.op, typeName(right))
+	}
+
+	switch e.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func evalBool(expr expression, env *conditionEnv, op string) (bool, error) {
+	value, err := expr.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s needs booleans, got %s", op, typeName(value))
+	}
+	return b, nil
+}
+
+func evalString(expr expression, env *conditionEnv, what string) (string, error) {
+	value, err := expr.eval(env)
+	if err != nil {
+		return "", err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string, got %s", what, typeName(value))
+	}
+	return s, nil
+}
+
+// conditionEqual compares decoded JSON values, in which every number is a
+// float64.
+func conditionEqual(left, right interface{}) bool {
+	return reflect.DeepEqual(left, right)
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
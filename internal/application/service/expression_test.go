@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testConditionEnv() *conditionEnv {
+	return &conditionEnv{
+		vars: map[string]interface{}{
+			"hour":    float64(22),
+			"minute":  float64(30),
+			"weekday": float64(5),
+			"time":    "22:30",
+			"trigger": map[string]interface{}{"device_id": "camera-1", "value": true},
+		},
+		state: func(deviceID, featureID string) (interface{}, error) {
+			if deviceID == "broken" {
+				return nil, errors.New("connection reset")
+			}
+			if deviceID == "light-1" && featureID == "power" {
+				return map[string]interface{}{"on": false, "level": float64(40)}, nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestEvalCondition(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{`hour >= 22`, true},
+		{`hour >= 22 && minute < 30`, false},
+		{`time >= "22:00" || time < "06:00"`, true},
+		{`!(weekday == 0 || weekday == 6)`, true},
+		{`trigger.value == true && trigger.device_id == 'camera-1'`, true},
+		{`trigger.missing == null`, true},
+		{`state("light-1", "power").on == false`, true},
+		{`state("light-1", "power").level > 35.5`, true},
+		{`state("light-9", "power") == null`, true},
+		{`state("light-9", "power").on == null`, true},
+		{`hour > -1`, true},
+		{`"a\"b" == 'a"b'`, true},
+		{`false && state("broken", "power") == 1`, false},
+		{`true || state("broken", "power") == 1`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			expr, err := parseCondition(tt.condition)
+			require.NoError(t, err)
+
+			got, err := evalCondition(expr, testConditionEnv())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseCondition_Errors(t *testing.T) {
+	tests := []struct {
+		condition string
+		message   string
+	}{
+		{`hour >=`, "unexpected end of condition"},
+		{`hour >= 22 &&`, "unexpected end of condition"},
+		{`(hour >= 22`, `expected ")"`},
+		{`hour >= 22 minute`, `unexpected "minute"`},
+		{`temperature > 20`, `unknown variable "temperature"`},
+		{`now() > 20`, `unknown function "now"`},
+		{`state("light-1") == 1`, "state takes a device and a feature, got 1 arguments"},
+		{`hour # 2`, `unexpected '#'`},
+		{`time == "22:00`, "unterminated string"},
+		{`hour > 1.2.3`, `invalid number "1.2.3"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			_, err := parseCondition(tt.condition)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestEvalCondition_Errors(t *testing.T) {
+	tests := []struct {
+		condition string
+		message   string
+	}{
+		{`hour`, "condition is number, not a boolean"},
+		{`hour && true`, "&& needs booleans, got number"},
+		{`!time`, "! needs a boolean, got string"},
+		{`hour > "22"`, "cannot compare number > string"},
+		{`trigger > 1`, "cannot compare object > number"},
+		{`hour.value == 1`, `cannot read "value" of number`},
+		{`state(1, "power") == 1`, "state device must be a string, got number"},
+		{`state("broken", "power") == 1`, "connection reset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			expr, err := parseCondition(tt.condition)
+			require.NoError(t, err)
+
+			_, err = evalCondition(expr, testConditionEnv())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
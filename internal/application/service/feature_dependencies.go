@@ -0,0 +1,441 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"maps"
+	"slices"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/models"
+	"strconv"
+	"strings"
+)
+
+// maxFeatureDependencies bounds what one feature can declare.
+const maxFeatureDependencies = 32
+
+// SetDependencies replaces what a feature declares it requires and
+// conflicts with. Every feature it names must belong to the same model. The
+// declaration is rejected when the requirements of the model would form a
+// cycle, or when some feature could no longer be enabled because it
+// requires, directly or not, two features that conflict. Changes to the
+// dependencies of one model are serialised, since the check covers them all.
+func (s *SmartFeatureService) SetDependencies(ctx context.Context, dependencies *models.FeatureDependencies) (*models.FeatureDependencies, error) {
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.SetDependencies",
+		attribute.String("feature.id", dependencies.FeatureID.String()),
+		attribute.Int("dependencies.requires", len(dependencies.Requires)),
+		attribute.Int("dependencies.conflicts", len(dependencies.Conflicts)))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Set smart feature dependencies", "id", dependencies.FeatureID,
+		"requires", dependencies.Requires, "conflicts", dependencies.Conflicts)
+
+	if err := validateDependencies(dependencies); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	var set []models.FeatureDependency
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		feature, err := s.repo.GetByID(ctx, dependencies.FeatureID.String())
+		if err != nil {
+			return err
+		}
+		if err := s.dependencyRepo.LockModelDependencies(ctx, feature.ModelID); err != nil {
+			return err
+		}
+		features, err := s.modelFeatures(ctx, feature.ModelID)
+		if err != nil {
+			return err
+		}
+		var foreign []string
+		for _, edge := range dependencies.Edges() {
+			if _, ok := features[edge.DependsOnID]; !ok {
+				foreign = append(foreign, edge.DependsOnID.String())
+			}
+		}
+		if len(foreign) > 0 {
+			return fmt.Errorf("%w: not features of model %s: %s",
+				models.ErrInvalidFeatureDependency, feature.ModelID, strings.Join(foreign, ", "))
+		}
+
+		existing, err := s.dependencyRepo.ListModelDependencies(ctx, feature.ModelID)
+		if err != nil {
+			return err
+		}
+		graph := newDependencyGraph(features, existing)
+		graph.replace(dependencies.FeatureID, dependencies.Edges())
+		if err := graph.check(); err != nil {
+			return err
+		}
+
+		if err := s.dependencyRepo.SetDependencies(ctx, dependencies.FeatureID, dependencies.Edges()); err != nil {
+			return err
+		}
+		set, err = s.dependencyRepo.ListDependencies(ctx, dependencies.FeatureID)
+		return err
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return groupDependencies(dependencies.FeatureID, set), nil
+}
+
+func (s *SmartFeatureService) GetDependencies(ctx context.Context, id string) (*models.FeatureDependencies, error) {
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.GetDependencies", attribute.String("feature.id", id))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get smart feature dependencies", "id", id)
+
+	feature, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	dependencies, err := s.dependencyRepo.ListDependencies(ctx, feature.ID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return groupDependencies(feature.ID, dependencies), nil
+}
+
+// ResolveFeatureSet returns the requested features of a model together with
+// everything they require, directly or not, each feature after the features
+// it requires. It fails when two features of the result conflict, since no
+// valid set then contains the requested features.
+func (s *SmartFeatureService) ResolveFeatureSet(ctx context.Context, modelID string, featureIDs []string) ([]*models.ResolvedFeature, error) {
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.ResolveFeatureSet",
+		attribute.String("model.id", modelID), attribute.Int("features.requested", len(featureIDs)))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Resolve smart feature set", "modelID", modelID, "features", featureIDs)
+
+	resolved, err := s.resolveFeatureSet(ctx, modelID, featureIDs)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return resolved, nil
+}
+
+func (s *SmartFeatureService) resolveFeatureSet(ctx context.Context, modelID string, featureIDs []string) ([]*models.ResolvedFeature, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", models.ErrInvalidFeatureDependency, fmt.Sprintf(format, args...))
+	}
+
+	if len(featureIDs) == 0 {
+		return nil, invalid("at least one feature is required")
+	}
+	model, err := s.modelRepo.GetByID(ctx, modelID)
+	if err != nil {
+		return nil, err
+	}
+	features, err := s.modelFeatures(ctx, model.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make([]uuid.UUID, 0, len(featureIDs))
+	var foreign []string
+	for _, id := range featureIDs {
+		featureID, err := uuid.Parse(id)
+		if _, ok := features[featureID]; err != nil || !ok {
+			foreign = append(foreign, id)
+			continue
+		}
+		requested = append(requested, featureID)
+	}
+	if len(foreign) > 0 {
+		return nil, invalid("not features of model %s: %s", model.ID, strings.Join(foreign, ", "))
+	}
+
+	dependencies, err := s.dependencyRepo.ListModelDependencies(ctx, model.ID)
+	if err != nil {
+		return nil, err
+	}
+	graph := newDependencyGraph(features, dependencies)
+	closure, err := graph.closure(requested...)
+	if err != nil {
+		return nil, err
+	}
+	if err := graph.checkConflicts(closure); err != nil {
+		return nil, invalid("the requested features cannot be enabled together: %s", err)
+	}
+
+	wanted := make(map[uuid.UUID]bool, len(requested))
+	for _, id := range requested {
+		wanted[id] = true
+	}
+	resolved := make([]*models.ResolvedFeature, len(closure))
+	for i, id := range closure {
+		resolved[i] = &models.ResolvedFeature{Feature: features[id], Requested: wanted[id]}
+	}
+	return resolved, nil
+}
+
+func (s *SmartFeatureService) modelFeatures(ctx context.Context, modelID uuid.UUID) (map[uuid.UUID]*models.SmartFeature, error) {
+	features, err := s.repo.GetWithModelID(ctx, modelID.String())
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.SmartFeature, len(features))
+	for _, feature := range features {
+		byID[feature.ID] = feature
+	}
+	return byID, nil
+}
+
+// requiredFeatures returns, for each feature of deleted that a feature not
+// being deleted still requires, an error wrapping ErrFeatureRequired. A
+// feature that stays because it is required keeps what it requires in turn.
+// The dependencies of every model involved are locked, so that none can be
+// added before the features are gone.
+func (s *SmartFeatureService) requiredFeatures(ctx context.Context, deleted []*models.SmartFeature) (map[uuid.UUID]error, error) {
+	byModel := make(map[uuid.UUID][]*models.SmartFeature)
+	for _, feature := range deleted {
+		byModel[feature.ModelID] = append(byModel[feature.ModelID], feature)
+	}
+	modelIDs := slices.SortedFunc(maps.Keys(byModel), func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+
+	required := make(map[uuid.UUID]error)
+	for _, modelID := range modelIDs {
+		if err := s.dependencyRepo.LockModelDependencies(ctx, modelID); err != nil {
+			return nil, err
+		}
+		edges, err := s.dependencyRepo.ListModelDependencies(ctx, modelID)
+		if err != nil {
+			return nil, err
+		}
+
+		gone := make(map[uuid.UUID]bool)
+		for _, feature := range byModel[modelID] {
+			gone[feature.ID] = true
+		}
+		kept := make(map[uuid.UUID]bool)
+		for changed := true; changed; {
+			changed = false
+			for _, edge := range edges {
+				if edge.Kind == models.DependencyRequires && gone[edge.DependsOnID] && !gone[edge.FeatureID] {
+					delete(gone, edge.DependsOnID)
+					kept[edge.DependsOnID] = true
+					changed = true
+				}
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+
+		features, err := s.modelFeatures(ctx, modelID)
+		if err != nil {
+			return nil, err
+		}
+		dependents := make(map[uuid.UUID][]string)
+		for _, edge := range edges {
+			if edge.Kind == models.DependencyRequires && kept[edge.DependsOnID] && !gone[edge.FeatureID] {
+				dependents[edge.DependsOnID] = append(dependents[edge.DependsOnID], strconv.Quote(features[edge.FeatureID].Name))
+			}
+		}
+		for id, names := range dependents {
+			slices.Sort(names)
+			required[id] = fmt.Errorf("%w: %q is required by %s",
+				models.ErrFeatureRequired, features[id].Name, strings.Join(names, ", "))
+		}
+	}
+	return required, nil
+}
+
+func validateDependencies(dependencies *models.FeatureDependencies) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", models.ErrInvalidFeatureDependency, fmt.Sprintf(format, args...))
+	}
+
+	if dependencies.FeatureID == uuid.Nil {
+		return invalid("feature_id is required")
+	}
+	edges := dependencies.Edges()
+	if len(edges) > maxFeatureDependencies {
+		return invalid("a feature can declare at most %d dependencies", maxFeatureDependencies)
+	}
+	seen := make(map[uuid.UUID]bool, len(edges))
+	for _, edge := range edges {
+		switch {
+		case edge.DependsOnID == uuid.Nil:
+			return invalid("dependencies need a feature ID")
+		case edge.DependsOnID == dependencies.FeatureID:
+			return invalid("a feature cannot depend on itself")
+		case seen[edge.DependsOnID]:
+			return invalid("feature %s is listed more than once", edge.DependsOnID)
+		}
+		seen[edge.DependsOnID] = true
+	}
+	return nil
+}
+
+// groupDependencies turns the edges declared by featureID back into one
+// declaration.
+func groupDependencies(featureID uuid.UUID, edges []models.FeatureDependency) *models.FeatureDependencies {
+	dependencies := &models.FeatureDependencies{
+		FeatureID: featureID,
+		Requires:  []uuid.UUID{},
+		Conflicts: []uuid.UUID{},
+	}
+	for _, edge := range edges {
+		switch edge.Kind {
+		case models.DependencyRequires:
+			dependencies.Requires = append(dependencies.Requires, edge.DependsOnID)
+		case models.DependencyConflicts:
+			dependencies.Conflicts = append(dependencies.Conflicts, edge.DependsOnID)
+		}
+	}
+	return dependencies
+}
+
+// dependencyGraph holds the declarations of the features of one model.
+// Conflicts hold both ways: declaring one on either feature keeps the two
+// apart.
+type dependencyGraph struct {
+	features  map[uuid.UUID]*models.SmartFeature
+	edges     []models.FeatureDependency
+	requires  map[uuid.UUID][]uuid.UUID
+	conflicts map[uuid.UUID]map[uuid.UUID]bool
+}
+
+func newDependencyGraph(features map[uuid.UUID]*models.SmartFeature, edges []models.FeatureDependency) *dependencyGraph {
+	g := &dependencyGraph{features: features}
+	g.build(edges)
+	return g
+}
+
+func (g *dependencyGraph) build(edges []models.FeatureDependency) {
+	g.edges = edges
+	g.requires = make(map[uuid.UUID][]uuid.UUID)
+	g.conflicts = make(map[uuid.UUID]map[uuid.UUID]bool)
+	for _, edge := range edges {
+		switch edge.Kind {
+		case models.DependencyRequires:
+			g.requires[edge.FeatureID] = append(g.requires[edge.FeatureID], edge.DependsOnID)
+		case models.DependencyConflicts:
+			g.addConflict(edge.FeatureID, edge.DependsOnID)
+			g.addConflict(edge.DependsOnID, edge.FeatureID)
+		}
+	}
+}
+
+func (g *dependencyGraph) addConflict(a, b uuid.UUID) {
+	if g.conflicts[a] == nil {
+		g.conflicts[a] = make(map[uuid.UUID]bool)
+	}
+	g.conflicts[a][b] = true
+}
+
+// replace swaps the declarations of featureID for edges.
+func (g *dependencyGraph) replace(featureID uuid.UUID, edges []models.FeatureDependency) {
+	var kept []models.FeatureDependency
+	for _, edge := range g.edges {
+		if edge.FeatureID != featureID {
+			kept = append(kept, edge)
+		}
+	}
+	g.build(append(kept, edges...))
+}
+
+// check makes sure requirements form no cycle and that every feature of
+// the model can still be enabled. Only features that require others need
+// a look: a feature on its own is always valid.
+func (g *dependencyGraph) check() error {
+	ids := make([]uuid.UUID, 0, len(g.requires))
+	for id := range g.requires {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+
+	for _, id := range ids {
+		closure, err := g.closure(id)
+		if err != nil {
+			return err
+		}
+		if err := g.checkConflicts(closure); err != nil {
+			return fmt.Errorf("%w: %s could no longer be enabled: %s",
+				models.ErrInvalidFeatureDependency, g.name(id), err)
+		}
+	}
+	return nil
+}
+
+// closure returns ids and every feature they require, directly or not, each
+// after the features it requires.
+func (g *dependencyGraph) closure(ids ...uuid.UUID) ([]uuid.UUID, error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[uuid.UUID]int)
+	var order, path []uuid.UUID
+
+	var visit func(id uuid.UUID) error
+	visit = func(id uuid.UUID) error {
+		switch state[id] {
+		case done:
+			return nil
+		case visiting:
+			cycle := []string{g.name(id)}
+			for i := len(path) - 1; i >= 0 && path[i] != id; i-- {
+				cycle = append(cycle, g.name(path[i]))
+			}
+			cycle = append(cycle, g.name(id))
+			for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+				cycle[i], cycle[j] = cycle[j], cycle[i]
+			}
+			return fmt.Errorf("%w: requirements form a cycle: %s",
+				models.ErrInvalidFeatureDependency, strings.Join(cycle, " requires "))
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, required := range g.requires[id] {
+			if err := visit(required); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		order = append(order, id)
+		return nil
+	}
+
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// checkConflicts returns an error naming the first two features of set
+// that conflict.
+func (g *dependencyGraph) checkConflicts(set []uuid.UUID) error {
+	for i, id := range set {
+		for _, other := range set[i+1:] {
+			if g.conflicts[id][other] {
+				return fmt.Errorf("%s conflicts with %s", g.name(id), g.name(other))
+			}
+		}
+	}
+	return nil
+}
+
+func (g *dependencyGraph) name(id uuid.UUID) string {
+	if feature, ok := g.features[id]; ok {
+		return fmt.Sprintf("%q", feature.Name)
+	}
+	return id.String()
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+)
+
+type mockFeatureDependencyRepo struct {
+	mock.Mock
+}
+
+func (m *mockFeatureDependencyRepo) SetDependencies(ctx context.Context, featureID uuid.UUID, dependencies []models.FeatureDependency) error {
+	args := m.Called(ctx, featureID, dependencies)
+	return args.Error(0)
+}
+
+func (m *mockFeatureDependencyRepo) ListDependencies(ctx context.Context, featureID uuid.UUID) ([]models.FeatureDependency, error) {
+	args := m.Called(ctx, featureID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.FeatureDependency), args.Error(1)
+}
+
+func (m *mockFeatureDependencyRepo) ListModelDependencies(ctx context.Context, modelID uuid.UUID) ([]models.FeatureDependency, error) {
+	args := m.Called(ctx, modelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.FeatureDependency), args.Error(1)
+}
+
+func (m *mockFeatureDependencyRepo) LockModelDependencies(ctx context.Context, modelID uuid.UUID) error {
+	args := m.Called(ctx, modelID)
+	return args.Error(0)
+}
+
+// dependencyFixture is a camera model with its features, by name.
+type dependencyFixture struct {
+	model        *models.SmartModel
+	features     map[string]*models.SmartFeature
+	featureRepo  *mockSmartFeatureRepo
+	modelRepo    *mockSmartModelRepo
+	dependencies *mockFeatureDependencyRepo
+	service      *SmartFeatureService
+}
+
+func newDependencyFixture(names ...string) *dependencyFixture {
+	f := &dependencyFixture{
+		model:        &models.SmartModel{ID: uuid.New(), Name: "Camera"},
+		features:     make(map[string]*models.SmartFeature),
+		featureRepo:  new(mockSmartFeatureRepo),
+		modelRepo:    new(mockSmartModelRepo),
+		dependencies: new(mockFeatureDependencyRepo),
+	}
+	var all []*models.SmartFeature
+	for _, name := range names {
+		feature := &models.SmartFeature{ID: uuid.New(), ModelID: f.model.ID, Name: name}
+		f.features[name] = feature
+		all = append(all, feature)
+		f.featureRepo.On("GetByID", mock.Anything, feature.ID.String()).Return(feature, nil).Maybe()
+	}
+	f.featureRepo.On("GetWithModelID", mock.Anything, f.model.ID.String()).Return(all, nil).Maybe()
+	f.dependencies.On("LockModelDependencies", mock.Anything, f.model.ID).Return(nil).Maybe()
+	f.modelRepo.On("GetByID", mock.Anything, f.model.ID.String()).Return(f.model, nil).Maybe()
+	f.service = NewSmartFeatureService(f.featureRepo, f.modelRepo, &fakeUnitOfWork{}, new(mockOutboxRepo), f.dependencies)
+	return f
+}
+
+func (f *dependencyFixture) id(name string) uuid.UUID {
+	return f.features[name].ID
+}
+
+func (f *dependencyFixture) edge(from string, kind models.DependencyKind, to string) models.FeatureDependency {
+	return models.FeatureDependency{FeatureID: f.id(from), DependsOnID: f.id(to), Kind: kind}
+}
+
+func (f *dependencyFixture) existing(edges ...models.FeatureDependency) {
+	f.dependencies.On("ListModelDependencies", mock.Anything, f.model.ID).Return(edges, nil)
+}
+
+func TestSmartFeatureService_SetDependencies(t *testing.T) {
+	f := newDependencyFixture("night vision", "infrared", "power", "privacy mode")
+	f.existing(f.edge("infrared", models.DependencyRequires, "power"))
+
+	edges := []models.FeatureDependency{
+		f.edge("night vision", models.DependencyRequires, "infrared"),
+		f.edge("night vision", models.DependencyConflicts, "privacy mode"),
+	}
+	f.dependencies.On("SetDependencies", mock.Anything, f.id("night vision"), edges).Return(nil)
+	f.dependencies.On("ListDependencies", mock.Anything, f.id("night vision")).Return(edges, nil)
+
+	result, err := f.service.SetDependencies(context.Background(), &models.FeatureDependencies{
+		FeatureID: f.id("night vision"),
+		Requires:  []uuid.UUID{f.id("infrared")},
+		Conflicts: []uuid.UUID{f.id("privacy mode")},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{f.id("infrared")}, result.Requires)
+	assert.Equal(t, []uuid.UUID{f.id("privacy mode")}, result.Conflicts)
+	f.dependencies.AssertExpectations(t)
+	f.dependencies.AssertCalled(t, "LockModelDependencies", mock.Anything, f.model.ID)
+}
+
+func TestSmartFeatureService_SetDependencies_ReplacesOwnDeclaration(t *testing.T) {
+	f := newDependencyFixture("night vision", "infrared")
+	// The old declaration of night vision conflicts with infrared; the new
+	// one requires it instead, which must not count as a conflict.
+	f.existing(f.edge("night vision", models.DependencyConflicts, "infrared"))
+
+	edges := []models.FeatureDependency{f.edge("night vision", models.DependencyRequires, "infrared")}
+	f.dependencies.On("SetDependencies", mock.Anything, f.id("night vision"), edges).Return(nil)
+	f.dependencies.On("ListDependencies", mock.Anything, f.id("night vision")).Return(edges, nil)
+
+	_, err := f.service.SetDependencies(context.Background(), &models.FeatureDependencies{
+		FeatureID: f.id("night vision"),
+		Requires:  []uuid.UUID{f.id("infrared")},
+	})
+
+	require.NoError(t, err)
+	f.dependencies.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_SetDependencies_Rejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing func(f *dependencyFixture) []models.FeatureDependency
+		requires []string
+		conflict []string
+		message  string
+		names    []string
+	}{
+		{
+			name: "direct cycle",
+			existing: func(f *dependencyFixture) []models.FeatureDependency {
+				return []models.FeatureDependency{f.edge("infrared", models.DependencyRequires, "night vision")}
+			},
+			requires: []string{"infrared"},
+			message:  `requirements form a cycle`,
+		},
+		{
+			name: "transitive cycle",
+			existing: func(f *dependencyFixture) []models.FeatureDependency {
+				return []models.FeatureDependency{
+					f.edge("infrared", models.DependencyRequires, "power"),
+					f.edge("power", models.DependencyRequires, "night vision"),
+				}
+			},
+			requires: []string{"infrared"},
+			message:  `requirements form a cycle`,
+			names:    []string{`"night vision"`, `"infrared"`, `"power"`},
+		},
+		{
+			name: "conflicts with a transitive requirement",
+			existing: func(f *dependencyFixture) []models.FeatureDependency {
+				return []models.FeatureDependency{f.edge("infrared", models.DependencyRequires, "power")}
+			},
+			requires: []string{"infrared"},
+			conflict: []string{"power"},
+			message:  `"night vision" could no longer be enabled`,
+		},
+		{
+			name: "requires features another declared in conflict",
+			existing: func(f *dependencyFixture) []models.FeatureDependency {
+				return []models.FeatureDependency{f.edge("privacy mode", models.DependencyConflicts, "infrared")}
+			},
+			requires: []string{"infrared", "privacy mode"},
+			message:  `conflicts with`,
+		},
+		{
+			name: "breaks another feature",
+			existing: func(f *dependencyFixture) []models.FeatureDependency {
+				return []models.FeatureDependency{
+					f.edge("privacy mode", models.DependencyRequires, "infrared"),
+					f.edge("privacy mode", models.DependencyRequires, "night vision"),
+				}
+			},
+			conflict: []string{"infrared"},
+			message:  `"privacy mode" could no longer be enabled`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newDependencyFixture("night vision", "infrared", "power", "privacy mode")
+			f.existing(tt.existing(f)...)
+
+			declaration := &models.FeatureDependencies{FeatureID: f.id("night vision")}
+			for _, name := range tt.requires {
+				declaration.Requires = append(declaration.Requires, f.id(name))
+			}
+			for _, name := range tt.conflict {
+				declaration.Conflicts = append(declaration.Conflicts, f.id(name))
+			}
+
+			_, err := f.service.SetDependencies(context.Background(), declaration)
+
+			require.ErrorIs(t, err, models.ErrInvalidFeatureDependency)
+			assert.Contains(t, err.Error(), tt.message)
+			for _, name := range tt.names {
+				assert.Contains(t, err.Error(), name)
+			}
+			f.dependencies.AssertNotCalled(t, "SetDependencies", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSmartFeatureService_SetDependencies_Invalid(t *testing.T) {
+	f := newDependencyFixture("night vision", "infrared")
+	f.existing()
+	nightVision, infrared := f.id("night vision"), f.id("infrared")
+
+	tests := []struct {
+		name         string
+		dependencies *models.FeatureDependencies
+	}{
+		{"missing feature", &models.FeatureDependencies{Requires: []uuid.UUID{infrared}}},
+		{"self", &models.FeatureDependencies{FeatureID: nightVision, Requires: []uuid.UUID{nightVision}}},
+		{"both required and conflicting", &models.FeatureDependencies{FeatureID: nightVision, Requires: []uuid.UUID{infrared}, Conflicts: []uuid.UUID{infrared}}},
+		{"nil dependency", &models.FeatureDependencies{FeatureID: nightVision, Conflicts: []uuid.UUID{uuid.Nil}}},
+		{"feature of another model", &models.FeatureDependencies{FeatureID: nightVision, Requires: []uuid.UUID{uuid.New()}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.SetDependencies(context.Background(), tt.dependencies)
+			assert.ErrorIs(t, err, models.ErrInvalidFeatureDependency)
+		})
+	}
+	f.dependencies.AssertNotCalled(t, "SetDependencies", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSmartFeatureService_ResolveFeatureSet(t *testing.T) {
+	f := newDependencyFixture("night vision", "infrared", "power", "motion alerts")
+	f.existing(
+		f.edge("night vision", models.DependencyRequires, "infrared"),
+		f.edge("infrared", models.DependencyRequires, "power"),
+		f.edge("motion alerts", models.DependencyRequires, "power"),
+	)
+
+	resolved, err := f.service.ResolveFeatureSet(context.Background(), f.model.ID.String(),
+		[]string{f.id("night vision").String(), f.id("power").String()})
+
+	require.NoError(t, err)
+	var names []string
+	var requested []bool
+	for _, r := range resolved {
+		names = append(names, r.Feature.Name)
+		requested = append(requested, r.Requested)
+	}
+	assert.Equal(t, []string{"power", "infrared", "night vision"}, names)
+	assert.Equal(t, []bool{true, false, true}, requested)
+}
+
+func TestSmartFeatureService_ResolveFeatureSet_Errors(t *testing.T) {
+	f := newDependencyFixture("night vision", "infrared", "privacy mode")
+	f.existing(
+		f.edge("night vision", models.DependencyRequires, "infrared"),
+		f.edge("privacy mode", models.DependencyConflicts, "infrared"),
+	)
+	f.modelRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, models.ErrNotFound)
+
+	tests := []struct {
+		name     string
+		modelID  string
+		features []string
+		err      error
+	}{
+		{"conflict", f.model.ID.String(), []string{f.id("night vision").String(), f.id("privacy mode").String()}, models.ErrInvalidFeatureDependency},
+		{"feature of another model", f.model.ID.String(), []string{uuid.New().String()}, models.ErrInvalidFeatureDependency},
+		{"malformed feature", f.model.ID.String(), []string{"night-vision"}, models.ErrInvalidFeatureDependency},
+		{"no features", f.model.ID.String(), nil, models.ErrInvalidFeatureDependency},
+		{"unknown model", uuid.New().String(), []string{f.id("infrared").String()}, models.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.ResolveFeatureSet(context.Background(), tt.modelID, tt.features)
+			assert.True(t, errors.Is(err, tt.err), "got %v", err)
+		})
+	}
+}
+
+func TestSmartFeatureService_Delete_RequiredFeature(t *testing.T) {
+	f := newDependencyFixture("night vision", "infrared", "privacy mode")
+	f.existing(
+		f.edge("night vision", models.DependencyRequires, "infrared"),
+		f.edge("privacy mode", models.DependencyConflicts, "infrared"),
+	)
+
+	err := f.service.Delete(context.Background(), f.id("infrared").String())
+
+	require.ErrorIs(t, err, models.ErrFeatureRequired)
+	assert.Contains(t, err.Error(), `"infrared" is required by "night vision"`)
+	f.featureRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestSmartFeatureService_BatchDelete_RequiredFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		deleted  []string
+		partial  bool
+		required []string
+	}{
+		{"with its dependents", []string{"night vision", "infrared"}, false, nil},
+		{"required by a feature that stays", []string{"motion alerts", "power"}, true, []string{"power"}},
+		{"required by a feature that is itself required", []string{"infrared", "power"}, true, []string{"infrared", "power"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newDependencyFixture("night vision", "infrared", "power", "motion alerts")
+			f.existing(
+				f.edge("night vision", models.DependencyRequires, "infrared"),
+				f.edge("infrared", models.DependencyRequires, "power"),
+				f.edge("motion alerts", models.DependencyRequires, "power"),
+			)
+			ids := make([]string, len(tt.deleted))
+			var features []*models.SmartFeature
+			for i, name := range tt.deleted {
+				ids[i] = f.id(name).String()
+				features = append(features, f.features[name])
+			}
+			f.featureRepo.On("GetByIDs", mock.Anything, ids).Return(features, nil)
+			f.featureRepo.On("DeleteBatch", mock.Anything, mock.Anything).Return(nil).Maybe()
+			outbox := f.service.outbox.(*mockOutboxRepo)
+			outbox.On("Add", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			itemErrs, err := f.service.BatchDelete(context.Background(), ids, tt.partial)
+
+			require.NoError(t, err)
+			var required []string
+			for i, itemErr := range itemErrs {
+				if itemErr != nil {
+					assert.ErrorIs(t, itemErr, models.ErrFeatureRequired)
+					required = append(required, tt.deleted[i])
+				}
+			}
+			assert.Equal(t, tt.required, required)
+		})
+	}
+}
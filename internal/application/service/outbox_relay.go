@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+// OutboxRelay moves events from the outbox to an EventPublisher. An event is
+// only marked published after the publisher accepted it, so delivery is
+// at-least-once. When publishing fails, later events of the same aggregate
+// are held back until the failed one goes through, keeping per-aggregate
+// order. An event that failed maxAttempts times is marked dead and no longer
+// holds its aggregate back.
+//
+// Events are claimed for lease in one short unit of work, published outside
+// of it, and their outcome recorded in a second one, so no transaction or
+// lock is held during network I/O. The lease must outlast publishing a batch:
+// another relay takes over the events of a relay that stopped once it runs
+// out.
+type OutboxRelay struct {
+	uow         interfaces.UnitOfWork
+	outbox      interfaces.OutboxRepository
+	publisher   interfaces.EventPublisher
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	lease       time.Duration
+}
+
+func NewOutboxRelay(
+	uow interfaces.UnitOfWork,
+	outbox interfaces.OutboxRepository,
+	publisher interfaces.EventPublisher,
+	interval time.Duration,
+	batchSize int,
+	maxAttempts int,
+	lease time.Duration,
+) *OutboxRelay {
+	return &OutboxRelay{
+		uow:         uow,
+		outbox:      outbox,
+		publisher:   publisher,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		lease:       lease,
+	}
+}
+
+// Run relays events every interval until ctx is cancelled. A full batch is
+// followed immediately by the next one so a backlog drains quickly.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Outbox relay failed", err)
+		}
+
+		if err == nil && published == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes one batch of pending events and returns how many were
+// published.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	ctx, span := tracing.StartSpan(ctx, "OutboxRelay.RelayOnce")
+	defer span.End()
+
+	events, err := r.claim(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	var published, heldBack []int64
+	failed := make(map[*models.OutboxEvent]error)
+	blocked := make(map[string]bool)
+	for _, event := range events {
+		key := aggregateKey(&event.DomainEvent)
+		if blocked[key] {
+			heldBack = append(heldBack, event.Sequence)
+			continue
+		}
+
+		if err := r.publisher.Publish(ctx, &event.DomainEvent); err != nil {
+			logger.FromContext(ctx).Warn("Failed to publish outbox event",
+				"event_id", event.ID, "event_type", event.Type, "attempts", event.Attempts+1, err)
+			blocked[key] = true
+			failed[event] = err
+			continue
+		}
+		published = append(published, event.Sequence)
+	}
+
+	err = r.uow.Do(ctx, func(ctx context.Context) error {
+		for event, publishErr := range failed {
+			dead := event.Attempts+1 >= r.maxAttempts
+			if dead {
+				logger.FromContext(ctx).Error("Giving up on outbox event",
+					"event_id", event.ID, "event_type", event.Type, "attempts", event.Attempts+1, publishErr)
+			}
+			if err := r.outbox.MarkFailed(ctx, event.Sequence, publishErr.Error(), dead); err != nil {
+				return err
+			}
+		}
+		if err := r.outbox.Release(ctx, heldBack); err != nil {
+			return err
+		}
+		return r.outbox.MarkPublished(ctx, published)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return 0, err
+	}
+
+	return len(published), nil
+}
+
+// claim leases the next batch of pending events to this relay. Events of an
+// aggregate are skipped from the first one that another relay still holds,
+// so that they are published in order.
+func (r *OutboxRelay) claim(ctx context.Context) ([]*models.OutboxEvent, error) {
+	var claimed []*models.OutboxEvent
+	err := r.uow.Do(ctx, func(ctx context.Context) error {
+		claimed = nil
+
+		events, err := r.outbox.FetchPending(ctx, r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		held := make(map[string]bool)
+		var sequences []int64
+		for _, event := range events {
+			key := aggregateKey(&event.DomainEvent)
+			if event.ClaimedUntil != nil && event.ClaimedUntil.After(now) {
+				held[key] = true
+			}
+			if held[key] {
+				continue
+			}
+			claimed = append(claimed, event)
+			sequences = append(sequences, event.Sequence)
+		}
+
+		return r.outbox.Claim(ctx, sequences, now.Add(r.lease))
+	})
+	return claimed, err
+}
+
+func aggregateKey(event *models.DomainEvent) string {
+	return string(event.AggregateType) + "/" + event.AggregateID.String()
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+type fakeUnitOfWork struct{}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type mockOutboxRepo struct {
+	mock.Mock
+}
+
+func (m *mockOutboxRepo) Add(ctx context.Context, events ...*models.DomainEvent) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *mockOutboxRepo) FetchPending(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.OutboxEvent), args.Error(1)
+}
+
+func (m *mockOutboxRepo) Claim(ctx context.Context, sequences []int64, until time.Time) error {
+	args := m.Called(ctx, sequences, until)
+	return args.Error(0)
+}
+
+func (m *mockOutboxRepo) Release(ctx context.Context, sequences []int64) error {
+	args := m.Called(ctx, sequences)
+	return args.Error(0)
+}
+
+func (m *mockOutboxRepo) MarkPublished(ctx context.Context, sequences []int64) error {
+	args := m.Called(ctx, sequences)
+	return args.Error(0)
+}
+
+func (m *mockOutboxRepo) MarkFailed(ctx context.Context, sequence int64, reason string, dead bool) error {
+	args := m.Called(ctx, sequence, reason, dead)
+	return args.Error(0)
+}
+
+// eventsOfType matches an Add call carrying exactly one event of type t.
+func eventsOfType(t models.EventType) interface{} {
+	return mock.MatchedBy(func(events []*models.DomainEvent) bool {
+		return len(events) == 1 && events[0].Type == t
+	})
+}
+
+// trackingUnitOfWork records whether a unit of work is in progress.
+type trackingUnitOfWork struct {
+	active bool
+}
+
+func (u *trackingUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	u.active = true
+	defer func() { u.active = false }()
+	return fn(ctx)
+}
+
+type mockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *mockEventPublisher) Publish(ctx context.Context, event *models.DomainEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *mockEventPublisher) Close() error {
+	return nil
+}
+
+func newOutboxEvent(sequence int64, aggregateID uuid.UUID) *models.OutboxEvent {
+	return &models.OutboxEvent{
+		DomainEvent: models.DomainEvent{
+			ID:            uuid.New(),
+			AggregateType: models.SmartModelAggregate,
+			AggregateID:   aggregateID,
+			Type:          models.ModelUpdatedEvent,
+			Payload:       []byte(`{}`),
+			OccurredAt:    time.Now(),
+		},
+		Sequence: sequence,
+	}
+}
+
+func TestOutboxRelay_RelayOnce(t *testing.T) {
+	mockOutbox := new(mockOutboxRepo)
+	mockPublisher := new(mockEventPublisher)
+	uow := &trackingUnitOfWork{}
+	relay := NewOutboxRelay(uow, mockOutbox, mockPublisher, time.Second, 10, 3, time.Minute)
+
+	events := []*models.OutboxEvent{
+		newOutboxEvent(1, uuid.New()),
+		newOutboxEvent(2, uuid.New()),
+	}
+
+	outsideUnitOfWork := func(mock.Arguments) {
+		assert.False(t, uow.active, "published inside a unit of work")
+	}
+	mockOutbox.On("FetchPending", mock.Anything, 10).Return(events, nil)
+	mockOutbox.On("Claim", mock.Anything, []int64{1, 2}, mock.Anything).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, &events[0].DomainEvent).Run(outsideUnitOfWork).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, &events[1].DomainEvent).Run(outsideUnitOfWork).Return(nil)
+	mockOutbox.On("Release", mock.Anything, []int64(nil)).Return(nil)
+	mockOutbox.On("MarkPublished", mock.Anything, []int64{1, 2}).Return(nil)
+
+	published, err := relay.RelayOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+
+	mockOutbox.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestOutboxRelay_RelayOnce_HoldsBackAggregateAfterFailure(t *testing.T) {
+	mockOutbox := new(mockOutboxRepo)
+	mockPublisher := new(mockEventPublisher)
+	relay := NewOutboxRelay(&fakeUnitOfWork{}, mockOutbox, mockPublisher, time.Second, 10, 3, time.Minute)
+
+	failing := uuid.New()
+	events := []*models.OutboxEvent{
+		newOutboxEvent(1, failing),
+		newOutboxEvent(2, uuid.New()),
+		newOutboxEvent(3, failing),
+	}
+
+	mockOutbox.On("FetchPending", mock.Anything, 10).Return(events, nil)
+	mockOutbox.On("Claim", mock.Anything, []int64{1, 2, 3}, mock.Anything).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, &events[0].DomainEvent).Return(assert.AnError)
+	mockPublisher.On("Publish", mock.Anything, &events[1].DomainEvent).Return(nil)
+	mockOutbox.On("MarkFailed", mock.Anything, int64(1), assert.AnError.Error(), false).Return(nil)
+	mockOutbox.On("Release", mock.Anything, []int64{3}).Return(nil)
+	mockOutbox.On("MarkPublished", mock.Anything, []int64{2}).Return(nil)
+
+	published, err := relay.RelayOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	mockOutbox.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, &events[2].DomainEvent)
+}
+
+func TestOutboxRelay_RelayOnce_FetchError(t *testing.T) {
+	mockOutbox := new(mockOutboxRepo)
+	mockPublisher := new(mockEventPublisher)
+	relay := NewOutboxRelay(&fakeUnitOfWork{}, mockOutbox, mockPublisher, time.Second, 10, 3, time.Minute)
+
+	mockOutbox.On("FetchPending", mock.Anything, 10).Return(nil, assert.AnError)
+
+	published, err := relay.RelayOnce(context.Background())
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, published)
+
+	mockOutbox.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestOutboxRelay_RelayOnce_GivesUpAfterMaxAttempts(t *testing.T) {
+	mockOutbox := new(mockOutboxRepo)
+	mockPublisher := new(mockEventPublisher)
+	relay := NewOutboxRelay(&fakeUnitOfWork{}, mockOutbox, mockPublisher, time.Second, 10, 3, time.Minute)
+
+	poison := newOutboxEvent(1, uuid.New())
+	poison.Attempts = 2
+
+	mockOutbox.On("FetchPending", mock.Anything, 10).Return([]*models.OutboxEvent{poison}, nil)
+	mockOutbox.On("Claim", mock.Anything, []int64{1}, mock.Anything).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, &poison.DomainEvent).Return(assert.AnError)
+	mockOutbox.On("MarkFailed", mock.Anything, int64(1), assert.AnError.Error(), true).Return(nil)
+	mockOutbox.On("Release", mock.Anything, []int64(nil)).Return(nil)
+	mockOutbox.On("MarkPublished", mock.Anything, []int64(nil)).Return(nil)
+
+	published, err := relay.RelayOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, published)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestOutboxRelay_RelayOnce_SkipsAggregatesClaimedElsewhere(t *testing.T) {
+	mockOutbox := new(mockOutboxRepo)
+	mockPublisher := new(mockEventPublisher)
+	relay := NewOutboxRelay(&fakeUnitOfWork{}, mockOutbox, mockPublisher, time.Second, 10, 3, time.Minute)
+
+	claimed := uuid.New()
+	expired, held := time.Now().Add(-time.Second), time.Now().Add(time.Minute)
+	events := []*models.OutboxEvent{
+		newOutboxEvent(1, claimed),
+		newOutboxEvent(2, uuid.New()),
+		newOutboxEvent(3, claimed),
+	}
+	events[0].ClaimedUntil = &held
+	events[1].ClaimedUntil = &expired
+
+	mockOutbox.On("FetchPending", mock.Anything, 10).Return(events, nil)
+	mockOutbox.On("Claim", mock.Anything, []int64{2}, mock.Anything).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, &events[1].DomainEvent).Return(nil)
+	mockOutbox.On("Release", mock.Anything, []int64(nil)).Return(nil)
+	mockOutbox.On("MarkPublished", mock.Anything, []int64{2}).Return(nil)
+
+	published, err := relay.RelayOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+	mockOutbox.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
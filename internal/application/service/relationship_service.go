@@ -0,0 +1,295 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+const (
+	// maxRelationshipDepth bounds graph queries; a query without a depth
+	// follows relationships this far.
+	maxRelationshipDepth = 16
+	// relationshipCycleDepth bounds the search for a cycle when a
+	// relationship of an acyclic type is created. It is far beyond any
+	// chain a catalog has, so in practice the search is exhaustive.
+	relationshipCycleDepth = 1000
+)
+
+// RelationshipService manages typed relationships between smart models and
+// answers graph queries over them.
+type RelationshipService struct {
+	repo      interfaces.RelationshipRepository
+	modelRepo interfaces.SmartModelRepository
+	uow       interfaces.UnitOfWork
+	now       func() time.Time
+}
+
+func NewRelationshipService(
+	repo interfaces.RelationshipRepository,
+	modelRepo interfaces.SmartModelRepository,
+	uow interfaces.UnitOfWork,
+) *RelationshipService {
+	return &RelationshipService{
+		repo:      repo,
+		modelRepo: modelRepo,
+		uow:       uow,
+		now:       time.Now,
+	}
+}
+
+// CreateRelationship relates two existing models. A symmetric relationship
+// is stored with the smaller model ID as its source, so it exists once
+// whichever way round it is created. A relationship of an acyclic type is
+// rejected when it would close a cycle; creations of one acyclic type are
+// serialised so that concurrent ones cannot close a cycle together.
+func (s *RelationshipService) CreateRelationship(ctx context.Context, relationship *models.ModelRelationship) (*models.ModelRelationship, error) {
+	ctx, span := tracing.StartSpan(ctx, "RelationshipService.CreateRelationship",
+		attribute.String("relationship.type", string(relationship.Type)),
+		attribute.String("relationship.source", relationship.SourceModelID.String()),
+		attribute.String("relationship.target", relationship.TargetModelID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Create relationship",
+		"type", relationship.Type, "source", relationship.SourceModelID, "target", relationship.TargetModelID)
+
+	if err := validateRelationship(relationship); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if relationship.Type.Symmetric() && bytes.Compare(relationship.SourceModelID[:], relationship.TargetModelID[:]) > 0 {
+		relationship.SourceModelID, relationship.TargetModelID = relationship.TargetModelID, relationship.SourceModelID
+	}
+	if relationship.ID == uuid.Nil {
+		relationship.ID = uuid.New()
+	}
+	relationship.CreatedAt = s.now()
+
+	var created *models.ModelRelationship
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.checkModels(ctx, relationship.SourceModelID, relationship.TargetModelID); err != nil {
+			return err
+		}
+		if relationship.Type.Acyclic() {
+			if err := s.repo.LockRelationshipType(ctx, relationship.Type); err != nil {
+				return err
+			}
+			if err := s.checkCycle(ctx, relationship); err != nil {
+				return err
+			}
+		}
+		var err error
+		created, err = s.repo.CreateRelationship(ctx, relationship)
+		return err
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *RelationshipService) GetRelationship(ctx context.Context, id uuid.UUID) (*models.ModelRelationship, error) {
+	ctx, span := tracing.StartSpan(ctx, "RelationshipService.GetRelationship", attribute.String("relationship.id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get relationship", "id", id)
+	relationship, err := s.repo.GetRelationship(ctx, id)
+	tracing.RecordError(span, err)
+	return relationship, err
+}
+
+func (s *RelationshipService) DeleteRelationship(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.StartSpan(ctx, "RelationshipService.DeleteRelationship", attribute.String("relationship.id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Delete relationship", "id", id)
+	err := s.repo.DeleteRelationship(ctx, id)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// ListRelationships returns the direct relationships of a model, whether it
+// is their source or target.
+func (s *RelationshipService) ListRelationships(ctx context.Context, filter models.RelationshipFilter) ([]*models.ModelRelationship, error) {
+	ctx, span := tracing.StartSpan(ctx, "RelationshipService.ListRelationships",
+		attribute.String("model.id", filter.ModelID.String()), attribute.String("relationship.type", string(filter.Type)))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("List relationships", "model_id", filter.ModelID, "type", filter.Type)
+
+	var err error
+	switch {
+	case filter.ModelID == uuid.Nil:
+		err = fmt.Errorf("%w: model_id is required", models.ErrInvalidRelationship)
+	case filter.Type != "" && !knownRelationshipType(filter.Type):
+		err = fmt.Errorf("%w: unknown type %q", models.ErrInvalidRelationship, filter.Type)
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	relationships, err := s.repo.ListRelationships(ctx, filter)
+	tracing.RecordError(span, err)
+	return relationships, err
+}
+
+// RelatedModels returns the models reachable from a model over
+// relationships of one type, e.g. every model compatible with it directly
+// or through others. Symmetric relationships are always followed both ways.
+// A query without a direction follows relationships from source to target
+// and one without a depth goes as deep as allowed.
+func (s *RelationshipService) RelatedModels(ctx context.Context, query models.RelationshipQuery) ([]*models.RelatedModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "RelationshipService.RelatedModels",
+		attribute.String("model.id", query.ModelID.String()),
+		attribute.String("relationship.type", string(query.Type)),
+		attribute.String("relationship.direction", string(query.Direction)),
+		attribute.Int("relationship.max_depth", query.MaxDepth))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Related models",
+		"model_id", query.ModelID, "type", query.Type, "direction", query.Direction, "max_depth", query.MaxDepth)
+
+	if err := normalizeRelationshipQuery(&query); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if _, err := s.modelRepo.GetByID(ctx, query.ModelID.String()); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	related, err := s.repo.RelatedModels(ctx, query)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if len(related) == 0 {
+		return related, nil
+	}
+
+	ids := make([]string, len(related))
+	for i, r := range related {
+		ids[i] = r.ModelID.String()
+	}
+	found, err := s.modelRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.SmartModel, len(found))
+	for _, model := range found {
+		byID[model.ID] = model
+	}
+	for _, r := range related {
+		r.Model = byID[r.ModelID]
+	}
+	return related, nil
+}
+
+// checkModels makes sure both ends of a relationship exist.
+func (s *RelationshipService) checkModels(ctx context.Context, source, target uuid.UUID) error {
+	found, err := s.modelRepo.GetByIDs(ctx, []string{source.String(), target.String()})
+	if err != nil {
+		return err
+	}
+	exists := make(map[uuid.UUID]bool, len(found))
+	for _, model := range found {
+		exists[model.ID] = true
+	}
+	var missing []string
+	for _, id := range []uuid.UUID{source, target} {
+		if !exists[id] {
+			missing = append(missing, id.String())
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: unknown smart models: %s", models.ErrInvalidRelationship, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// checkCycle rejects relationship when its source is already reachable
+// from its target over relationships of the same type.
+func (s *RelationshipService) checkCycle(ctx context.Context, relationship *models.ModelRelationship) error {
+	reachable, err := s.repo.RelatedModels(ctx, models.RelationshipQuery{
+		ModelID:   relationship.TargetModelID,
+		Type:      relationship.Type,
+		Direction: models.DirectionOutgoing,
+		MaxDepth:  relationshipCycleDepth,
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range reachable {
+		if r.ModelID == relationship.SourceModelID {
+			return fmt.Errorf("%w: %s %s %s would create a cycle",
+				models.ErrInvalidRelationship, relationship.SourceModelID, relationship.Type, relationship.TargetModelID)
+		}
+	}
+	return nil
+}
+
+func validateRelationship(relationship *models.ModelRelationship) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", models.ErrInvalidRelationship, fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case !knownRelationshipType(relationship.Type):
+		return invalid("unknown type %q", relationship.Type)
+	case relationship.SourceModelID == uuid.Nil || relationship.TargetModelID == uuid.Nil:
+		return invalid("source_model_id and target_model_id are required")
+	case relationship.SourceModelID == relationship.TargetModelID:
+		return invalid("a model cannot be related to itself")
+	}
+	return nil
+}
+
+// normalizeRelationshipQuery checks query and fills in its defaults.
+func normalizeRelationshipQuery(query *models.RelationshipQuery) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", models.ErrInvalidRelationship, fmt.Sprintf(format, args...))
+	}
+
+	switch query.Direction {
+	case "":
+		query.Direction = models.DirectionOutgoing
+	case models.DirectionOutgoing, models.DirectionIncoming, models.DirectionBoth:
+	default:
+		return invalid("unknown direction %q", query.Direction)
+	}
+	if query.MaxDepth == 0 {
+		query.MaxDepth = maxRelationshipDepth
+	}
+
+	switch {
+	case query.ModelID == uuid.Nil:
+		return invalid("model_id is required")
+	case !knownRelationshipType(query.Type):
+		return invalid("unknown type %q", query.Type)
+	case query.MaxDepth < 0 || query.MaxDepth > maxRelationshipDepth:
+		return invalid("max_depth must be between 1 and %d", maxRelationshipDepth)
+	}
+	if query.Type.Symmetric() {
+		query.Direction = models.DirectionBoth
+	}
+	return nil
+}
+
+func knownRelationshipType(t models.RelationshipType) bool {
+	switch t {
+	case models.RelationshipCompatibleWith, models.RelationshipRequires, models.RelationshipReplaces, models.RelationshipAccessoryOf:
+		return true
+	}
+	return false
+}
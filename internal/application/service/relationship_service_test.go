@@ -0,0 +1,253 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+type mockRelationshipRepo struct {
+	mock.Mock
+}
+
+func (m *mockRelationshipRepo) CreateRelationship(ctx context.Context, relationship *models.ModelRelationship) (*models.ModelRelationship, error) {
+	args := m.Called(ctx, relationship)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ModelRelationship), args.Error(1)
+}
+
+func (m *mockRelationshipRepo) GetRelationship(ctx context.Context, id uuid.UUID) (*models.ModelRelationship, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ModelRelationship), args.Error(1)
+}
+
+func (m *mockRelationshipRepo) DeleteRelationship(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockRelationshipRepo) ListRelationships(ctx context.Context, filter models.RelationshipFilter) ([]*models.ModelRelationship, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ModelRelationship), args.Error(1)
+}
+
+func (m *mockRelationshipRepo) RelatedModels(ctx context.Context, query models.RelationshipQuery) ([]*models.RelatedModel, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RelatedModel), args.Error(1)
+}
+
+func (m *mockRelationshipRepo) LockRelationshipType(ctx context.Context, relationshipType models.RelationshipType) error {
+	args := m.Called(ctx, relationshipType)
+	return args.Error(0)
+}
+
+func newRelationshipService(repo *mockRelationshipRepo, modelRepo *mockSmartModelRepo) *RelationshipService {
+	svc := NewRelationshipService(repo, modelRepo, &fakeUnitOfWork{})
+	svc.now = func() time.Time { return automationNow }
+	return svc
+}
+
+// orderedModelIDs returns two model IDs, the smaller first.
+func orderedModelIDs() (uuid.UUID, uuid.UUID) {
+	a, b := uuid.New(), uuid.New()
+	if bytes.Compare(a[:], b[:]) > 0 {
+		return b, a
+	}
+	return a, b
+}
+
+func TestRelationshipService_CreateRelationship(t *testing.T) {
+	repo, modelRepo := new(mockRelationshipRepo), new(mockSmartModelRepo)
+	svc := newRelationshipService(repo, modelRepo)
+
+	camera, hub := uuid.New(), uuid.New()
+	modelRepo.On("GetByIDs", mock.Anything, []string{camera.String(), hub.String()}).
+		Return([]*models.SmartModel{{ID: camera}, {ID: hub}}, nil)
+	repo.On("CreateRelationship", mock.Anything, mock.MatchedBy(func(r *models.ModelRelationship) bool {
+		return r.ID != uuid.Nil && r.SourceModelID == camera && r.TargetModelID == hub && r.CreatedAt.Equal(automationNow)
+	})).Return(&models.ModelRelationship{ID: uuid.New(), SourceModelID: camera, TargetModelID: hub, Type: models.RelationshipRequires}, nil)
+
+	created, err := svc.CreateRelationship(context.Background(), &models.ModelRelationship{
+		SourceModelID: camera,
+		TargetModelID: hub,
+		Type:          models.RelationshipRequires,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, camera, created.SourceModelID)
+	repo.AssertNotCalled(t, "RelatedModels", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestRelationshipService_CreateRelationship_SymmetricIsCanonical(t *testing.T) {
+	repo, modelRepo := new(mockRelationshipRepo), new(mockSmartModelRepo)
+	svc := newRelationshipService(repo, modelRepo)
+
+	smaller, larger := orderedModelIDs()
+	modelRepo.On("GetByIDs", mock.Anything, []string{smaller.String(), larger.String()}).
+		Return([]*models.SmartModel{{ID: smaller}, {ID: larger}}, nil)
+	repo.On("CreateRelationship", mock.Anything, mock.MatchedBy(func(r *models.ModelRelationship) bool {
+		return r.SourceModelID == smaller && r.TargetModelID == larger
+	})).Return(&models.ModelRelationship{ID: uuid.New(), SourceModelID: smaller, TargetModelID: larger, Type: models.RelationshipCompatibleWith}, nil)
+
+	created, err := svc.CreateRelationship(context.Background(), &models.ModelRelationship{
+		SourceModelID: larger,
+		TargetModelID: smaller,
+		Type:          models.RelationshipCompatibleWith,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, smaller, created.SourceModelID)
+	repo.AssertExpectations(t)
+}
+
+func TestRelationshipService_CreateRelationship_Cycle(t *testing.T) {
+	repo, modelRepo := new(mockRelationshipRepo), new(mockSmartModelRepo)
+	svc := newRelationshipService(repo, modelRepo)
+
+	v1, v2 := uuid.New(), uuid.New()
+	modelRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartModel{{ID: v1}, {ID: v2}}, nil)
+	repo.On("LockRelationshipType", mock.Anything, models.RelationshipReplaces).Return(nil)
+	// v2 already replaces v1 through some other model, so v1 replacing v2
+	// would close the loop.
+	repo.On("RelatedModels", mock.Anything, models.RelationshipQuery{
+		ModelID:   v2,
+		Type:      models.RelationshipReplaces,
+		Direction: models.DirectionOutgoing,
+		MaxDepth:  relationshipCycleDepth,
+	}).Return([]*models.RelatedModel{{ModelID: uuid.New(), Depth: 1}, {ModelID: v1, Depth: 2}}, nil)
+
+	_, err := svc.CreateRelationship(context.Background(), &models.ModelRelationship{
+		SourceModelID: v1,
+		TargetModelID: v2,
+		Type:          models.RelationshipReplaces,
+	})
+
+	assert.ErrorIs(t, err, models.ErrInvalidRelationship)
+	assert.Contains(t, err.Error(), "cycle")
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "CreateRelationship", mock.Anything, mock.Anything)
+}
+
+func TestRelationshipService_CreateRelationship_Invalid(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name         string
+		relationship *models.ModelRelationship
+	}{
+		{"unknown type", &models.ModelRelationship{SourceModelID: uuid.New(), TargetModelID: uuid.New(), Type: "works_with"}},
+		{"missing source", &models.ModelRelationship{TargetModelID: uuid.New(), Type: models.RelationshipRequires}},
+		{"self", &models.ModelRelationship{SourceModelID: id, TargetModelID: id, Type: models.RelationshipAccessoryOf}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, modelRepo := new(mockRelationshipRepo), new(mockSmartModelRepo)
+			svc := newRelationshipService(repo, modelRepo)
+
+			_, err := svc.CreateRelationship(context.Background(), tt.relationship)
+
+			assert.ErrorIs(t, err, models.ErrInvalidRelationship)
+			repo.AssertNotCalled(t, "CreateRelationship", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRelationshipService_CreateRelationship_UnknownModel(t *testing.T) {
+	repo, modelRepo := new(mockRelationshipRepo), new(mockSmartModelRepo)
+	svc := newRelationshipService(repo, modelRepo)
+
+	camera, missing := uuid.New(), uuid.New()
+	modelRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartModel{{ID: camera}}, nil)
+
+	_, err := svc.CreateRelationship(context.Background(), &models.ModelRelationship{
+		SourceModelID: camera,
+		TargetModelID: missing,
+		Type:          models.RelationshipRequires,
+	})
+
+	assert.ErrorIs(t, err, models.ErrInvalidRelationship)
+	assert.Contains(t, err.Error(), missing.String())
+	repo.AssertNotCalled(t, "CreateRelationship", mock.Anything, mock.Anything)
+}
+
+func TestRelationshipService_RelatedModels(t *testing.T) {
+	repo, modelRepo := new(mockRelationshipRepo), new(mockSmartModelRepo)
+	svc := newRelationshipService(repo, modelRepo)
+
+	start, hub, bridge := uuid.New(), uuid.New(), uuid.New()
+	modelRepo.On("GetByID", mock.Anything, start.String()).Return(&models.SmartModel{ID: start}, nil)
+	repo.On("RelatedModels", mock.Anything, models.RelationshipQuery{
+		ModelID:   start,
+		Type:      models.RelationshipCompatibleWith,
+		Direction: models.DirectionBoth,
+		MaxDepth:  maxRelationshipDepth,
+	}).Return([]*models.RelatedModel{{ModelID: hub, Depth: 1}, {ModelID: bridge, Depth: 2}}, nil)
+	modelRepo.On("GetByIDs", mock.Anything, []string{hub.String(), bridge.String()}).
+		Return([]*models.SmartModel{{ID: bridge, Name: "Bridge"}, {ID: hub, Name: "Hub"}}, nil)
+
+	related, err := svc.RelatedModels(context.Background(), models.RelationshipQuery{
+		ModelID: start,
+		Type:    models.RelationshipCompatibleWith,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, related, 2)
+	assert.Equal(t, "Hub", related[0].Model.Name)
+	assert.Equal(t, 1, related[0].Depth)
+	assert.Equal(t, "Bridge", related[1].Model.Name)
+	repo.AssertExpectations(t)
+	modelRepo.AssertExpectations(t)
+}
+
+func TestRelationshipService_RelatedModels_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query models.RelationshipQuery
+		err   error
+	}{
+		{"unknown type", models.RelationshipQuery{ModelID: uuid.New(), Type: "works_with"}, models.ErrInvalidRelationship},
+		{"unknown direction", models.RelationshipQuery{ModelID: uuid.New(), Type: models.RelationshipRequires, Direction: "sideways"}, models.ErrInvalidRelationship},
+		{"too deep", models.RelationshipQuery{ModelID: uuid.New(), Type: models.RelationshipRequires, MaxDepth: maxRelationshipDepth + 1}, models.ErrInvalidRelationship},
+		{"negative depth", models.RelationshipQuery{ModelID: uuid.New(), Type: models.RelationshipRequires, MaxDepth: -1}, models.ErrInvalidRelationship},
+		{"unknown model", models.RelationshipQuery{ModelID: uuid.New(), Type: models.RelationshipRequires}, models.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, modelRepo := new(mockRelationshipRepo), new(mockSmartModelRepo)
+			svc := newRelationshipService(repo, modelRepo)
+			modelRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, models.ErrNotFound)
+
+			_, err := svc.RelatedModels(context.Background(), tt.query)
+
+			assert.True(t, errors.Is(err, tt.err), "got %v", err)
+			repo.AssertNotCalled(t, "RelatedModels", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRelationshipService_ListRelationships_RequiresModel(t *testing.T) {
+	svc := newRelationshipService(new(mockRelationshipRepo), new(mockSmartModelRepo))
+
+	_, err := svc.ListRelationships(context.Background(), models.RelationshipFilter{Type: models.RelationshipRequires})
+	assert.ErrorIs(t, err, models.ErrInvalidRelationship)
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxSceneNameLength        = 255
+	maxSceneDescriptionLength = 1000
+	maxSceneGroupLength       = 64
+	maxSceneSteps             = 50
+	maxSceneStepDelay         = 5 * time.Minute
+)
+
+// SceneService manages scenes and activates them. Steps invoke their
+// feature through the same path as UpdateDesiredState.
+type SceneService struct {
+	repo        interfaces.SceneRepository
+	featureRepo interfaces.SmartFeatureRepository
+	invoker     interfaces.FeatureInvoker
+	stepTimeout time.Duration
+	now         func() time.Time
+}
+
+func NewSceneService(
+	repo interfaces.SceneRepository,
+	featureRepo interfaces.SmartFeatureRepository,
+	invoker interfaces.FeatureInvoker,
+	stepTimeout time.Duration,
+) *SceneService {
+	return &SceneService{
+		repo:        repo,
+		featureRepo: featureRepo,
+		invoker:     invoker,
+		stepTimeout: stepTimeout,
+		now:         time.Now,
+	}
+}
+
+func (s *SceneService) CreateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	ctx, span := tracing.StartSpan(ctx, "SceneService.CreateScene", attribute.String("scene.name", scene.Name))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Create scene", "name", scene.Name, "steps", len(scene.Steps))
+
+	if err := s.validateScene(ctx, scene); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if scene.ID == uuid.Nil {
+		scene.ID = uuid.New()
+	}
+	now := s.now()
+	scene.CreatedAt = now
+	scene.UpdatedAt = now
+
+	created, err := s.repo.CreateScene(ctx, scene)
+	tracing.RecordError(span, err)
+	return created, err
+}
+
+func (s *SceneService) GetScene(ctx context.Context, id uuid.UUID) (*models.Scene, error) {
+	ctx, span := tracing.StartSpan(ctx, "SceneService.GetScene", attribute.String("scene.id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get scene", "id", id)
+	scene, err := s.repo.GetScene(ctx, id)
+	tracing.RecordError(span, err)
+	return scene, err
+}
+
+func (s *SceneService) ListScenes(ctx context.Context) ([]*models.Scene, error) {
+	ctx, span := tracing.StartSpan(ctx, "SceneService.ListScenes")
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("List scenes")
+	scenes, err := s.repo.ListScenes(ctx)
+	tracing.RecordError(span, err)
+	return scenes, err
+}
+
+// UpdateScene replaces everything but the ID and creation time of a scene.
+func (s *SceneService) UpdateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	ctx, span := tracing.StartSpan(ctx, "SceneService.UpdateScene", attribute.String("scene.id", scene.ID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Update scene", "id", scene.ID, "name", scene.Name)
+
+	if err := s.validateScene(ctx, scene); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	scene.UpdatedAt = s.now()
+
+	updated, err := s.repo.UpdateScene(ctx, scene)
+	tracing.RecordError(span, err)
+	return updated, err
+}
+
+func (s *SceneService) DeleteScene(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.StartSpan(ctx, "SceneService.DeleteScene", attribute.String("scene.id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Delete scene", "id", id)
+	err := s.repo.DeleteScene(ctx, id)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// ActivateScene runs the steps of a scene stage by stage and reports the
+// outcome of each. A failing step does not fail the activation: the other
+// steps still run, unless the scene stops on errors, and the report says
+// which ones failed. Only a scene that cannot be read is an error.
+func (s *SceneService) ActivateScene(ctx context.Context, id uuid.UUID) (*models.SceneActivation, error) {
+	ctx, span := tracing.StartSpan(ctx, "SceneService.ActivateScene", attribute.String("scene.id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Activate scene", "id", id)
+
+	scene, err := s.repo.GetScene(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	activation := &models.SceneActivation{
+		SceneID:   scene.ID,
+		Results:   make([]models.StepResult, len(scene.Steps)),
+		StartedAt: s.now(),
+	}
+	for i, step := range scene.Steps {
+		activation.Results[i] = models.StepResult{
+			Index:     i,
+			DeviceID:  step.DeviceID,
+			FeatureID: step.FeatureID,
+			Status:    models.StepSkipped,
+		}
+	}
+
+	var failed bool
+	for _, stage := range sceneStages(scene.Steps) {
+		if failed && scene.StopOnError {
+			break
+		}
+		var wg sync.WaitGroup
+		for _, i := range stage {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				activation.Results[i] = s.runStep(ctx, scene.Steps[i], activation.Results[i])
+			}(i)
+		}
+		wg.Wait()
+		for _, i := range stage {
+			failed = failed || activation.Results[i].Status == models.StepFailed
+		}
+	}
+
+	var succeeded int
+	for _, result := range activation.Results {
+		if result.Status == models.StepSucceeded {
+			succeeded++
+		}
+	}
+	switch {
+	case succeeded == len(activation.Results):
+		activation.Status = models.ActivationSucceeded
+	case succeeded == 0:
+		activation.Status = models.ActivationFailed
+	default:
+		activation.Status = models.ActivationPartiallyFailed
+	}
+	if activation.Status != models.ActivationSucceeded {
+		tracing.RecordError(span, fmt.Errorf("%d of %d steps succeeded", succeeded, len(activation.Results)))
+		logger.Warn("Scene activation did not fully succeed", "scene_id", scene.ID, "status", activation.Status)
+	}
+	activation.FinishedAt = s.now()
+	return activation, nil
+}
+
+// runStep waits for the delay of step and invokes its feature. A step whose
+// delay is cut short by the end of ctx fails without invoking anything.
+func (s *SceneService) runStep(ctx context.Context, step models.SceneStep, result models.StepResult) models.StepResult {
+	if step.Delay > 0 {
+		select {
+		case <-ctx.Done():
+			result.Status = models.StepFailed
+			result.Error = ctx.Err().Error()
+			return result
+		case <-time.After(step.Delay):
+		}
+	}
+
+	startedAt := s.now()
+	result.StartedAt = &startedAt
+	invokeCtx, cancel := context.WithTimeout(ctx, s.stepTimeout)
+	err := s.invoker.InvokeFeature(invokeCtx, step.DeviceID, step.FeatureID, step.Parameters)
+	cancel()
+	finishedAt := s.now()
+	result.FinishedAt = &finishedAt
+
+	result.Status = models.StepSucceeded
+	if err != nil {
+		result.Status = models.StepFailed
+		result.Error = err.Error()
+		logger.FromContext(ctx).Debug("Scene step failed", "device_id", step.DeviceID, "feature_id", step.FeatureID, "error", err)
+	}
+	return result
+}
+
+// sceneStages splits the indices of steps into the stages they run in:
+// consecutive steps with the same non-empty group run together.
+func sceneStages(steps []models.SceneStep) [][]int {
+	var stages [][]int
+	for i, step := range steps {
+		last := len(stages) - 1
+		if step.Group != "" && last >= 0 && steps[i-1].Group == step.Group {
+			stages[last] = append(stages[last], i)
+			continue
+		}
+		stages = append(stages, []int{i})
+	}
+	return stages
+}
+
+// validateScene checks a scene. Every feature it refers to must exist.
+func (s *SceneService) validateScene(ctx context.Context, scene *models.Scene) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", models.ErrInvalidScene, fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case strings.TrimSpace(scene.Name) == "" || len(scene.Name) > maxSceneNameLength:
+		return invalid("name must be 1 to %d characters", maxSceneNameLength)
+	case len(scene.Description) > maxSceneDescriptionLength:
+		return invalid("description is longer than %d characters", maxSceneDescriptionLength)
+	case len(scene.Steps) == 0 || len(scene.Steps) > maxSceneSteps:
+		return invalid("a scene needs 1 to %d steps", maxSceneSteps)
+	}
+
+	var featureIDs []string
+	for i, step := range scene.Steps {
+		switch {
+		case step.DeviceID == "" || len(step.DeviceID) > maxDeviceIDLength:
+			return invalid("step %d: device_id must be 1 to %d characters", i, maxDeviceIDLength)
+		case step.FeatureID == uuid.Nil:
+			return invalid("step %d: feature_id is required", i)
+		case step.Delay < 0 || step.Delay > maxSceneStepDelay:
+			return invalid("step %d: delay must be between 0 and %s", i, maxSceneStepDelay)
+		case len(step.Group) > maxSceneGroupLength:
+			return invalid("step %d: group is longer than %d characters", i, maxSceneGroupLength)
+		}
+		featureIDs = append(featureIDs, step.FeatureID.String())
+	}
+
+	missing, err := missingFeatures(ctx, s.featureRepo, featureIDs)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return invalid("unknown smart features: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
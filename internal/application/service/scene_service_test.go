@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSceneRepo struct {
+	scenes map[uuid.UUID]*models.Scene
+}
+
+func newFakeSceneRepo(scenes ...*models.Scene) *fakeSceneRepo {
+	repo := &fakeSceneRepo{scenes: make(map[uuid.UUID]*models.Scene)}
+	for _, scene := range scenes {
+		repo.scenes[scene.ID] = scene
+	}
+	return repo
+}
+
+func (r *fakeSceneRepo) CreateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	r.scenes[scene.ID] = scene
+	return scene, nil
+}
+
+func (r *fakeSceneRepo) GetScene(ctx context.Context, id uuid.UUID) (*models.Scene, error) {
+	scene, ok := r.scenes[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return scene, nil
+}
+
+func (r *fakeSceneRepo) ListScenes(ctx context.Context) ([]*models.Scene, error) {
+	var scenes []*models.Scene
+	for _, scene := range r.scenes {
+		scenes = append(scenes, scene)
+	}
+	return scenes, nil
+}
+
+func (r *fakeSceneRepo) UpdateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	if _, ok := r.scenes[scene.ID]; !ok {
+		return nil, models.ErrNotFound
+	}
+	r.scenes[scene.ID] = scene
+	return scene, nil
+}
+
+func (r *fakeSceneRepo) DeleteScene(ctx context.Context, id uuid.UUID) error {
+	if _, ok := r.scenes[id]; !ok {
+		return models.ErrNotFound
+	}
+	delete(r.scenes, id)
+	return nil
+}
+
+// stageInvoker records invocations from concurrent steps and how many were
+// in flight at once.
+type stageInvoker struct {
+	mu         sync.Mutex
+	devices    []string
+	running    int
+	maxRunning int
+	hold       time.Duration
+	fail       map[string]error
+	parameters map[string]map[string]interface{}
+	invokedAt  map[string]time.Time
+}
+
+func newStageInvoker(hold time.Duration) *stageInvoker {
+	return &stageInvoker{
+		hold:       hold,
+		fail:       make(map[string]error),
+		parameters: make(map[string]map[string]interface{}),
+		invokedAt:  make(map[string]time.Time),
+	}
+}
+
+func (i *stageInvoker) InvokeFeature(ctx context.Context, deviceID string, featureID uuid.UUID, parameters map[string]interface{}) error {
+	i.mu.Lock()
+	i.devices = append(i.devices, deviceID)
+	i.parameters[deviceID] = parameters
+	i.invokedAt[deviceID] = time.Now()
+	i.running++
+	i.maxRunning = max(i.maxRunning, i.running)
+	i.mu.Unlock()
+
+	time.Sleep(i.hold)
+
+	i.mu.Lock()
+	i.running--
+	i.mu.Unlock()
+	return i.fail[deviceID]
+}
+
+func newTestScene(steps ...models.SceneStep) *models.Scene {
+	return &models.Scene{ID: uuid.New(), Name: "Movie night", Steps: steps}
+}
+
+func newTestSceneService(repo *fakeSceneRepo, featureRepo *mockSmartFeatureRepo, invoker *stageInvoker) *SceneService {
+	service := NewSceneService(repo, featureRepo, invoker, time.Second)
+	service.now = func() time.Time { return automationNow }
+	return service
+}
+
+func TestSceneService_CreateScene(t *testing.T) {
+	featureRepo := new(mockSmartFeatureRepo)
+	dim, play := uuid.New(), uuid.New()
+	expectFeatures(featureRepo, dim, play)
+	service := newTestSceneService(newFakeSceneRepo(), featureRepo, newStageInvoker(0))
+
+	created, err := service.CreateScene(context.Background(), &models.Scene{
+		Name: "Movie night",
+		Steps: []models.SceneStep{
+			{DeviceID: "light-1", FeatureID: dim, Parameters: map[string]interface{}{"brightness": 20}},
+			{DeviceID: "tv-1", FeatureID: play, Delay: 2 * time.Second},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, created.ID)
+	assert.Equal(t, automationNow, created.CreatedAt)
+	assert.Equal(t, automationNow, created.UpdatedAt)
+	featureRepo.AssertExpectations(t)
+}
+
+func TestSceneService_CreateScene_Invalid(t *testing.T) {
+	featureID := uuid.New()
+	step := models.SceneStep{DeviceID: "light-1", FeatureID: featureID}
+	tests := []struct {
+		name  string
+		scene *models.Scene
+		err   string
+	}{
+		{"missing name", &models.Scene{Steps: []models.SceneStep{step}}, "name must be 1 to 255 characters"},
+		{"no steps", &models.Scene{Name: "Empty"}, "a scene needs 1 to 50 steps"},
+		{"missing device", newTestScene(models.SceneStep{FeatureID: featureID}), "step 0: device_id must be 1 to 255 characters"},
+		{"missing feature", newTestScene(step, models.SceneStep{DeviceID: "tv-1"}), "step 1: feature_id is required"},
+		{"negative delay", newTestScene(models.SceneStep{DeviceID: "light-1", FeatureID: featureID, Delay: -time.Second}), "step 0: delay must be between 0 and 5m0s"},
+		{"long delay", newTestScene(models.SceneStep{DeviceID: "light-1", FeatureID: featureID, Delay: time.Hour}), "step 0: delay must be between 0 and 5m0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestSceneService(newFakeSceneRepo(), new(mockSmartFeatureRepo), newStageInvoker(0))
+
+			_, err := service.CreateScene(context.Background(), tt.scene)
+
+			assert.ErrorIs(t, err, models.ErrInvalidScene)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestSceneService_CreateScene_UnknownFeature(t *testing.T) {
+	featureRepo := new(mockSmartFeatureRepo)
+	known, unknown := uuid.New(), uuid.New()
+	featureRepo.On("GetByIDs", mock.Anything, []string{known.String(), unknown.String()}).Return([]*models.SmartFeature{{ID: known}}, nil)
+	service := newTestSceneService(newFakeSceneRepo(), featureRepo, newStageInvoker(0))
+
+	_, err := service.CreateScene(context.Background(), newTestScene(
+		models.SceneStep{DeviceID: "light-1", FeatureID: known},
+		models.SceneStep{DeviceID: "tv-1", FeatureID: unknown},
+	))
+
+	assert.ErrorIs(t, err, models.ErrInvalidScene)
+	assert.ErrorContains(t, err, "unknown smart features: "+unknown.String())
+}
+
+func TestSceneService_ActivateScene_RunsStagesInOrder(t *testing.T) {
+	dim, lower, play := uuid.New(), uuid.New(), uuid.New()
+	scene := newTestScene(
+		models.SceneStep{DeviceID: "light-1", FeatureID: dim, Parameters: map[string]interface{}{"brightness": 20}, Group: "setup"},
+		models.SceneStep{DeviceID: "light-2", FeatureID: dim, Parameters: map[string]interface{}{"brightness": 10}, Group: "setup"},
+		models.SceneStep{DeviceID: "blinds-1", FeatureID: lower, Group: "setup"},
+		models.SceneStep{DeviceID: "tv-1", FeatureID: play, Delay: 30 * time.Millisecond},
+	)
+	invoker := newStageInvoker(20 * time.Millisecond)
+	service := newTestSceneService(newFakeSceneRepo(scene), new(mockSmartFeatureRepo), invoker)
+
+	activation, err := service.ActivateScene(context.Background(), scene.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ActivationSucceeded, activation.Status)
+	assert.Equal(t, scene.ID, activation.SceneID)
+	require.Len(t, activation.Results, 4)
+	for i, result := range activation.Results {
+		assert.Equal(t, i, result.Index)
+		assert.Equal(t, models.StepSucceeded, result.Status)
+		assert.Equal(t, scene.Steps[i].DeviceID, result.DeviceID)
+		assert.NotNil(t, result.StartedAt)
+		assert.NotNil(t, result.FinishedAt)
+	}
+	assert.Equal(t, 3, invoker.maxRunning, "the setup group runs in parallel")
+	assert.Equal(t, "tv-1", invoker.devices[3], "the delayed step runs after the group")
+	assert.GreaterOrEqual(t, invoker.invokedAt["tv-1"].Sub(invoker.invokedAt["light-1"]), 50*time.Millisecond)
+	assert.Equal(t, map[string]interface{}{"brightness": 10}, invoker.parameters["light-2"])
+}
+
+func TestSceneService_ActivateScene_PartialFailure(t *testing.T) {
+	scene := newTestScene(
+		models.SceneStep{DeviceID: "light-1", FeatureID: uuid.New()},
+		models.SceneStep{DeviceID: "blinds-1", FeatureID: uuid.New()},
+		models.SceneStep{DeviceID: "tv-1", FeatureID: uuid.New()},
+	)
+	invoker := newStageInvoker(0)
+	invoker.fail["blinds-1"] = errors.New("device offline")
+	service := newTestSceneService(newFakeSceneRepo(scene), new(mockSmartFeatureRepo), invoker)
+
+	activation, err := service.ActivateScene(context.Background(), scene.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ActivationPartiallyFailed, activation.Status)
+	assert.Equal(t, []string{"light-1", "blinds-1", "tv-1"}, invoker.devices)
+	assert.Equal(t, models.StepSucceeded, activation.Results[0].Status)
+	assert.Equal(t, models.StepFailed, activation.Results[1].Status)
+	assert.Equal(t, "device offline", activation.Results[1].Error)
+	assert.Equal(t, models.StepSucceeded, activation.Results[2].Status)
+}
+
+func TestSceneService_ActivateScene_StopOnError(t *testing.T) {
+	scene := newTestScene(
+		models.SceneStep{DeviceID: "light-1", FeatureID: uuid.New(), Group: "lights"},
+		models.SceneStep{DeviceID: "light-2", FeatureID: uuid.New(), Group: "lights"},
+		models.SceneStep{DeviceID: "tv-1", FeatureID: uuid.New()},
+	)
+	scene.StopOnError = true
+	invoker := newStageInvoker(0)
+	invoker.fail["light-2"] = errors.New("device offline")
+	service := newTestSceneService(newFakeSceneRepo(scene), new(mockSmartFeatureRepo), invoker)
+
+	activation, err := service.ActivateScene(context.Background(), scene.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ActivationPartiallyFailed, activation.Status)
+	assert.ElementsMatch(t, []string{"light-1", "light-2"}, invoker.devices)
+	assert.Equal(t, models.StepSucceeded, activation.Results[0].Status)
+	assert.Equal(t, models.StepFailed, activation.Results[1].Status)
+	assert.Equal(t, models.StepSkipped, activation.Results[2].Status)
+	assert.Nil(t, activation.Results[2].StartedAt)
+}
+
+func TestSceneService_ActivateScene_AllFailed(t *testing.T) {
+	scene := newTestScene(models.SceneStep{DeviceID: "tv-1", FeatureID: uuid.New()})
+	invoker := newStageInvoker(0)
+	invoker.fail["tv-1"] = errors.New("device offline")
+	service := newTestSceneService(newFakeSceneRepo(scene), new(mockSmartFeatureRepo), invoker)
+
+	activation, err := service.ActivateScene(context.Background(), scene.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ActivationFailed, activation.Status)
+}
+
+func TestSceneService_ActivateScene_CancelledDuringDelay(t *testing.T) {
+	scene := newTestScene(models.SceneStep{DeviceID: "tv-1", FeatureID: uuid.New(), Delay: time.Minute})
+	invoker := newStageInvoker(0)
+	service := newTestSceneService(newFakeSceneRepo(scene), new(mockSmartFeatureRepo), invoker)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	activation, err := service.ActivateScene(ctx, scene.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ActivationFailed, activation.Status)
+	assert.Equal(t, models.StepFailed, activation.Results[0].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), activation.Results[0].Error)
+	assert.Empty(t, invoker.devices)
+}
+
+func TestSceneService_ActivateScene_NotFound(t *testing.T) {
+	service := newTestSceneService(newFakeSceneRepo(), new(mockSmartFeatureRepo), newStageInvoker(0))
+
+	_, err := service.ActivateScene(context.Background(), uuid.New())
+
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestSceneStages(t *testing.T) {
+	steps := []models.SceneStep{
+		{Group: "a"}, {Group: "a"}, {}, {}, {Group: "b"}, {Group: "a"}, {Group: "a"},
+	}
+
+	assert.Equal(t, [][]int{{0, 1}, {2}, {3}, {4}, {5, 6}}, sceneStages(steps))
+}
@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+const (
+	maxScheduleNameLength        = 255
+	maxScheduleDescriptionLength = 1000
+	minScheduleInterval          = time.Minute
+	// dueScheduleBatchSize bounds the schedules fired per tick; the rest
+	// are picked up by the next one.
+	dueScheduleBatchSize = 100
+)
+
+// ScheduleService manages schedules and fires the ones that are due. Only
+// the replica elected by the leader elector fires schedules; without an
+// elector every replica does, which is only safe with a single one.
+// Advancing a schedule and recording its run happen in one transaction
+// that fails when the schedule was advanced, changed or paused meanwhile,
+// so a due time is never run twice.
+type ScheduleService struct {
+	repo         interfaces.ScheduleRepository
+	featureRepo  interfaces.SmartFeatureRepository
+	uow          interfaces.UnitOfWork
+	invoker      interfaces.FeatureInvoker
+	elector      interfaces.LeaderElector
+	tickInterval time.Duration
+	runTimeout   time.Duration
+	retention    time.Duration
+	now          func() time.Time
+}
+
+func NewScheduleService(
+	repo interfaces.ScheduleRepository,
+	featureRepo interfaces.SmartFeatureRepository,
+	uow interfaces.UnitOfWork,
+	invoker interfaces.FeatureInvoker,
+	elector interfaces.LeaderElector,
+	tickInterval time.Duration,
+	runTimeout time.Duration,
+	retention time.Duration,
+) *ScheduleService {
+	return &ScheduleService{
+		repo:         repo,
+		featureRepo:  featureRepo,
+		uow:          uow,
+		invoker:      invoker,
+		elector:      elector,
+		tickInterval: tickInterval,
+		runTimeout:   runTimeout,
+		retention:    retention,
+		now:          time.Now,
+	}
+}
+
+// CreateSchedule validates and stores a new schedule, due at its first time
+// after now. The timezone defaults to UTC.
+func (s *ScheduleService) CreateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	ctx, span := tracing.StartSpan(ctx, "ScheduleService.CreateSchedule", attribute.String("schedule.name", schedule.Name))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Create schedule", "name", schedule.Name, "cron", schedule.Cron, "interval", schedule.Interval)
+
+	now := s.now().Truncate(time.Second)
+	next, err := s.validateSchedule(ctx, schedule, now)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if schedule.ID == uuid.Nil {
+		schedule.ID = uuid.New()
+	}
+	schedule.Paused = false
+	schedule.NextRunAt = next
+	schedule.LastRunAt = nil
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	created, err := s.repo.CreateSchedule(ctx, schedule)
+	tracing.RecordError(span, err)
+	return created, err
+}
+
+func (s *ScheduleService) GetSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	ctx, span := tracing.StartSpan(ctx, "ScheduleService.GetSchedule", attribute.String("schedule.id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get schedule", "id", id)
+	schedule, err := s.repo.GetSchedule(ctx, id)
+	tracing.RecordError(span, err)
+	return schedule, err
+}
+
+func (s *ScheduleService) ListSchedules(ctx context.Context) ([]*models.Schedule, error) {
+	ctx, span := tracing.StartSpan(ctx, "ScheduleService.ListSchedules")
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("List schedules")
+	schedules, err := s.repo.ListSchedules(ctx)
+	tracing.RecordError(span, err)
+	return schedules, err
+}
+
+// UpdateSchedule replaces the definition of a schedule and plans its next
+// run from now. Whether it is paused and when it last ran are kept.
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	ctx, span := tracing.StartSpan(ctx, "ScheduleService.UpdateSchedule", attribute.String("schedule.id", schedule.ID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Update schedule", "id", schedule.ID, "name", schedule.Name)
+
+	now := s.now().Truncate(time.Second)
+	next, err := s.validateSchedule(ctx, schedule, now)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	var updated *models.Schedule
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetSchedule(ctx, schedule.ID)
+		if err != nil {
+			return err
+		}
+		schedule.Paused = existing.Paused
+		schedule.LastRunAt = existing.LastRunAt
+		schedule.CreatedAt = existing.CreatedAt
+		schedule.NextRunAt = next
+		schedule.UpdatedAt = now
+		updated, err = s.repo.UpdateSchedule(ctx, schedule)
+		return err
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteSchedule removes a schedule and its run history.
+func (s *ScheduleService) DeleteSchedule(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.StartSpan(ctx, "ScheduleService.DeleteSchedule", attribute.String("schedule.id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Delete schedule", "id", id)
+	err := s.repo.DeleteSchedule(ctx, id)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// PauseSchedule stops a schedule from running until it is resumed. Pausing
+// a paused schedule does nothing.
+func (s *ScheduleService) PauseSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	ctx, span := tracing.StartSpan(ctx, "ScheduleService.PauseSchedule", attribute.String("schedule.id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Pause schedule", "id", id)
+	schedule, err := s.setPaused(ctx, id, true)
+	tracing.RecordError(span, err)
+	return schedule, err
+}
+
+// ResumeSchedule lets a paused schedule run again from its next time after
+// now; the runs missed while it was paused are skipped. Resuming a schedule
+// that is not paused does nothing.
+func (s *ScheduleService) ResumeSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	ctx, span := tracing.StartSpan(ctx, "ScheduleService.ResumeSchedule", attribute.String("schedule.id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Resume schedule", "id", id)
+	schedule, err := s.setPaused(ctx, id, false)
+	tracing.RecordError(span, err)
+	return schedule, err
+}
+
+func (s *ScheduleService) setPaused(ctx context.Context, id uuid.UUID, paused bool) (*models.Schedule, error) {
+	var schedule *models.Schedule
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if schedule, err = s.repo.GetSchedule(ctx, id); err != nil {
+			return err
+		}
+		if schedule.Paused == paused {
+			return nil
+		}
+
+		now := s.now().Truncate(time.Second)
+		if !paused {
+			next, err := nextRun(schedule, now)
+			if err != nil {
+				return err
+			}
+			schedule.NextRunAt = next
+		}
+		schedule.Paused = paused
+		schedule.UpdatedAt = now
+		schedule, err = s.repo.UpdateSchedule(ctx, schedule)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// ListRuns returns a page of runs, newest first, of the schedule scheduleID
+// or of every schedule when it is nil, and the token of the next page,
+// which is empty on the last one.
+func (s *ScheduleService) ListRuns(ctx context.Context, scheduleID *uuid.UUID, pageSize int, pageToken string) ([]*models.ScheduleRun, string, error) {
+	ctx, span := tracing.StartSpan(ctx, "ScheduleService.ListRuns", attribute.Int("page.size", pageSize))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("List schedule runs", "schedule_id", scheduleID, "pageSize", pageSize)
+
+	after, err := models.ParsePageToken(pageToken)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, "", err
+	}
+	if scheduleID != nil {
+		if _, err := s.repo.GetSchedule(ctx, *scheduleID); err != nil {
+			tracing.RecordError(span, err)
+			return nil, "", err
+		}
+	}
+
+	runs, err := s.repo.ListRuns(ctx, models.ScheduleRunFilter{ScheduleID: scheduleID, After: after, Limit: pageSize + 1})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, "", err
+	}
+	if len(runs) <= pageSize {
+		return runs, "", nil
+	}
+	runs = runs[:pageSize]
+	last := runs[pageSize-1]
+	return runs, models.PageCursor{CreatedAt: last.StartedAt, ID: last.ID}.Token(), nil
+}
+
+// Run fires due schedules and prunes old runs until ctx is cancelled, as
+// long as this replica is the leader.
+func (s *ScheduleService) Run(ctx context.Context) {
+	if s.elector == nil {
+		s.lead(ctx)
+		return
+	}
+	if err := s.elector.Lead(ctx, s.lead); err != nil && ctx.Err() == nil {
+		logger.Error("Schedule leader election stopped", err)
+	}
+}
+
+func (s *ScheduleService) lead(ctx context.Context) {
+	logger.Info("Firing schedules on this replica")
+	s.runDue(ctx)
+
+	ticker := time.NewTicker(s.tickInterval)
+	defer ticker.Stop()
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runDue(ctx)
+		case <-pruneTicker.C:
+			s.prune(ctx)
+		}
+	}
+}
+
+// runDue fires the schedules that are due, oldest first.
+func (s *ScheduleService) runDue(ctx context.Context) {
+	schedules, err := s.repo.ListDueSchedules(ctx, s.now(), dueScheduleBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("Failed to list due schedules", err)
+		}
+		return
+	}
+	for _, schedule := range schedules {
+		if ctx.Err() != nil {
+			return
+		}
+		s.fire(ctx, schedule)
+	}
+}
+
+// fire advances schedule to its next time after now, records the run and
+// invokes the feature. A schedule that was advanced or changed since it was
+// read is left alone.
+func (s *ScheduleService) fire(ctx context.Context, schedule *models.Schedule) {
+	ctx, span := tracing.StartSpan(ctx, "ScheduleService.Fire", attribute.String("schedule.id", schedule.ID.String()))
+	defer span.End()
+
+	now := s.now().Truncate(time.Second)
+	due := schedule.NextRunAt
+	next, err := nextRun(schedule, now)
+	if err != nil {
+		// The timezone or expression went away since the schedule was
+		// stored; it is not advanced and fails again on the next tick.
+		tracing.RecordError(span, err)
+		logger.Error("Failed to plan the next schedule run", err, "schedule_id", schedule.ID)
+		return
+	}
+
+	run := &models.ScheduleRun{
+		ID:          uuid.New(),
+		ScheduleID:  schedule.ID,
+		ScheduledAt: due,
+		Status:      models.ExecutionRunning,
+		StartedAt:   s.now(),
+	}
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.repo.AdvanceSchedule(ctx, schedule.ID, due, next); err != nil {
+			return err
+		}
+		return s.repo.CreateRun(ctx, run)
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrVersionConflict), errors.Is(err, models.ErrNotFound):
+		logger.FromContext(ctx).Debug("Schedule changed before it ran", "schedule_id", schedule.ID, "error", err)
+		return
+	default:
+		tracing.RecordError(span, err)
+		logger.Error("Failed to start schedule run", err, "schedule_id", schedule.ID)
+		return
+	}
+
+	invokeCtx, cancel := context.WithTimeout(ctx, s.runTimeout)
+	err = s.invoker.InvokeFeature(invokeCtx, schedule.DeviceID, schedule.FeatureID, schedule.Parameters)
+	cancel()
+
+	run.Status = models.ExecutionSucceeded
+	if err != nil {
+		tracing.RecordError(span, err)
+		run.Status = models.ExecutionFailed
+		run.Error = err.Error()
+	}
+	finishedAt := s.now()
+	run.FinishedAt = &finishedAt
+	if err := s.repo.FinishRun(ctx, run); err != nil {
+		logger.Error("Failed to record schedule run", err, "schedule_id", schedule.ID, "run_id", run.ID)
+	}
+}
+
+func (s *ScheduleService) prune(ctx context.Context) {
+	pruned, err := s.repo.PruneRuns(ctx, s.now().Add(-s.retention))
+	if err != nil {
+		logger.Error("Failed to prune schedule runs", err)
+		return
+	}
+	if pruned > 0 {
+		logger.Debug("Pruned schedule runs", "count", pruned)
+	}
+}
+
+// nextRun returns the first time after now at which schedule is due. An
+// interval schedule keeps its phase: it is due a whole number of intervals
+// after its previous due time, or an interval after now when it has none
+// in the future, e.g. when it was just created or resumed.
+func nextRun(schedule *models.Schedule, now time.Time) (time.Time, error) {
+	if schedule.Cron == "" {
+		due := schedule.NextRunAt
+		if due.IsZero() || schedule.Paused {
+			return now.Add(schedule.Interval), nil
+		}
+		if due.After(now) {
+			return due, nil
+		}
+		return due.Add((now.Sub(due)/schedule.Interval + 1) * schedule.Interval), nil
+	}
+
+	cron, err := parseCron(schedule.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next, ok := cron.next(now, location)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%q does not match within %d years", schedule.Cron, maxCronSearch)
+	}
+	return next, nil
+}
+
+// validateSchedule checks a schedule, fills in its default timezone and
+// returns its first time after now. The feature it invokes must exist.
+func (s *ScheduleService) validateSchedule(ctx context.Context, schedule *models.Schedule, now time.Time) (time.Time, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", models.ErrInvalidSchedule, fmt.Sprintf(format, args...))
+	}
+
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+	switch {
+	case strings.TrimSpace(schedule.Name) == "" || len(schedule.Name) > maxScheduleNameLength:
+		return time.Time{}, invalid("name must be 1 to %d characters", maxScheduleNameLength)
+	case len(schedule.Description) > maxScheduleDescriptionLength:
+		return time.Time{}, invalid("description is longer than %d characters", maxScheduleDescriptionLength)
+	case (schedule.Cron == "") == (schedule.Interval == 0):
+		return time.Time{}, invalid("exactly one of cron and interval is required")
+	case schedule.Interval != 0 && (schedule.Interval < minScheduleInterval || schedule.Interval%time.Second != 0):
+		return time.Time{}, invalid("interval must be a whole number of seconds of at least %s", minScheduleInterval)
+	case schedule.DeviceID == "" || len(schedule.DeviceID) > maxDeviceIDLength:
+		return time.Time{}, invalid("device_id must be 1 to %d characters", maxDeviceIDLength)
+	case schedule.FeatureID == uuid.Nil:
+		return time.Time{}, invalid("feature_id is required")
+	}
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return time.Time{}, invalid("unknown timezone %q", schedule.Timezone)
+	}
+	if schedule.Cron != "" {
+		if _, err := parseCron(schedule.Cron); err != nil {
+			return time.Time{}, invalid("cron: %s", err)
+		}
+	}
+	// A new or changed schedule starts from now, not from a stored due time.
+	next, err := nextRun(&models.Schedule{Cron: schedule.Cron, Interval: schedule.Interval, Timezone: schedule.Timezone}, now)
+	if err != nil {
+		return time.Time{}, invalid("cron: %s", err)
+	}
+
+	missing, err := missingFeatures(ctx, s.featureRepo, []string{schedule.FeatureID.String()})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(missing) > 0 {
+		return time.Time{}, invalid("unknown smart feature: %s", missing[0])
+	}
+	return next, nil
+}
@@ -0,0 +1,428 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeScheduleRepo struct {
+	schedules []*models.Schedule
+	runs      []*models.ScheduleRun
+}
+
+func (r *fakeScheduleRepo) find(id uuid.UUID) (int, error) {
+	for i, schedule := range r.schedules {
+		if schedule.ID == id {
+			return i, nil
+		}
+	}
+	return -1, models.ErrNotFound
+}
+
+func (r *fakeScheduleRepo) CreateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	stored := *schedule
+	r.schedules = append(r.schedules, &stored)
+	created := stored
+	return &created, nil
+}
+
+func (r *fakeScheduleRepo) GetSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	i, err := r.find(id)
+	if err != nil {
+		return nil, err
+	}
+	schedule := *r.schedules[i]
+	return &schedule, nil
+}
+
+func (r *fakeScheduleRepo) ListSchedules(ctx context.Context) ([]*models.Schedule, error) {
+	return r.schedules, nil
+}
+
+func (r *fakeScheduleRepo) UpdateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	i, err := r.find(schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	stored := *schedule
+	r.schedules[i] = &stored
+	updated := stored
+	return &updated, nil
+}
+
+func (r *fakeScheduleRepo) DeleteSchedule(ctx context.Context, id uuid.UUID) error {
+	i, err := r.find(id)
+	if err != nil {
+		return err
+	}
+	r.schedules = slices.Delete(r.schedules, i, i+1)
+	return nil
+}
+
+func (r *fakeScheduleRepo) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]*models.Schedule, error) {
+	var due []*models.Schedule
+	for _, schedule := range r.schedules {
+		if !schedule.Paused && !schedule.NextRunAt.After(now) {
+			copied := *schedule
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeScheduleRepo) AdvanceSchedule(ctx context.Context, id uuid.UUID, due, next time.Time) error {
+	i, err := r.find(id)
+	if err != nil {
+		return err
+	}
+	schedule := r.schedules[i]
+	if schedule.Paused || !schedule.NextRunAt.Equal(due) {
+		return fmt.Errorf("%w: schedule %s", models.ErrVersionConflict, id)
+	}
+	schedule.NextRunAt = next
+	schedule.LastRunAt = &due
+	return nil
+}
+
+func (r *fakeScheduleRepo) CreateRun(ctx context.Context, run *models.ScheduleRun) error {
+	if _, err := r.find(run.ScheduleID); err != nil {
+		return err
+	}
+	stored := *run
+	r.runs = append(r.runs, &stored)
+	return nil
+}
+
+func (r *fakeScheduleRepo) FinishRun(ctx context.Context, run *models.ScheduleRun) error {
+	for i, stored := range r.runs {
+		if stored.ID == run.ID {
+			finished := *run
+			r.runs[i] = &finished
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (r *fakeScheduleRepo) ListRuns(ctx context.Context, filter models.ScheduleRunFilter) ([]*models.ScheduleRun, error) {
+	var runs []*models.ScheduleRun
+	for _, run := range r.runs {
+		if filter.ScheduleID != nil && run.ScheduleID != *filter.ScheduleID {
+			continue
+		}
+		if filter.After != nil && !run.StartedAt.Before(filter.After.CreatedAt) {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	slices.SortFunc(runs, func(a, b *models.ScheduleRun) int { return b.StartedAt.Compare(a.StartedAt) })
+	if len(runs) > filter.Limit {
+		runs = runs[:filter.Limit]
+	}
+	return runs, nil
+}
+
+func (r *fakeScheduleRepo) PruneRuns(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type fakeLeaderElector struct {
+	led int
+}
+
+func (e *fakeLeaderElector) Lead(ctx context.Context, fn func(ctx context.Context)) error {
+	e.led++
+	fn(ctx)
+	return ctx.Err()
+}
+
+func newTestScheduleService(repo *fakeScheduleRepo, featureRepo *mockSmartFeatureRepo, invoker *fakeInvoker) *ScheduleService {
+	svc := NewScheduleService(repo, featureRepo, &fakeUnitOfWork{}, invoker, nil, time.Hour, time.Second, time.Hour)
+	svc.now = func() time.Time { return automationNow }
+	return svc
+}
+
+func newTestSchedule(featureID uuid.UUID) *models.Schedule {
+	return &models.Schedule{
+		Name:       "Morning blinds",
+		Cron:       "30 7 * * mon-fri",
+		Timezone:   "Europe/Berlin",
+		DeviceID:   "blinds-1",
+		FeatureID:  featureID,
+		Parameters: map[string]interface{}{"position": 100},
+	}
+}
+
+func TestScheduleService_CreateSchedule(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	featureRepo := new(mockSmartFeatureRepo)
+	svc := newTestScheduleService(repo, featureRepo, &fakeInvoker{})
+	featureID := uuid.New()
+	expectFeatures(featureRepo, featureID)
+	ctx := context.Background()
+
+	created, err := svc.CreateSchedule(ctx, newTestSchedule(featureID))
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, created.ID)
+	// automationNow is a Friday noon, so the next weekday 07:30 in Berlin is
+	// Monday 06:30 UTC.
+	assert.Equal(t, time.Date(2024, 3, 4, 6, 30, 0, 0, time.UTC), created.NextRunAt.UTC())
+	assert.Equal(t, automationNow, created.CreatedAt)
+
+	interval := newTestSchedule(featureID)
+	interval.Cron = ""
+	interval.Interval = 15 * time.Minute
+	interval.Timezone = ""
+	created, err = svc.CreateSchedule(ctx, interval)
+	require.NoError(t, err)
+	assert.Equal(t, automationNow.Add(15*time.Minute), created.NextRunAt)
+	assert.Equal(t, "UTC", created.Timezone)
+	featureRepo.AssertExpectations(t)
+}
+
+func TestScheduleService_CreateSchedule_Invalid(t *testing.T) {
+	featureRepo := new(mockSmartFeatureRepo)
+	known := uuid.New()
+	unknown := uuid.New()
+	featureRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{{ID: known}}, nil)
+	svc := newTestScheduleService(&fakeScheduleRepo{}, featureRepo, &fakeInvoker{})
+
+	tests := []struct {
+		name    string
+		modify  func(schedule *models.Schedule)
+		message string
+	}{
+		{"missing name", func(s *models.Schedule) { s.Name = "" }, "name must be 1 to 255 characters"},
+		{"long description", func(s *models.Schedule) { s.Description = strings.Repeat("x", 1001) }, "description is longer than 1000 characters"},
+		{"cron and interval", func(s *models.Schedule) { s.Interval = time.Hour }, "exactly one of cron and interval is required"},
+		{"neither", func(s *models.Schedule) { s.Cron = "" }, "exactly one of cron and interval is required"},
+		{"short interval", func(s *models.Schedule) { s.Cron, s.Interval = "", 30*time.Second }, "interval must be a whole number of seconds of at least 1m0s"},
+		{"fractional interval", func(s *models.Schedule) { s.Cron, s.Interval = "", 90500*time.Millisecond }, "interval must be a whole number of seconds"},
+		{"malformed cron", func(s *models.Schedule) { s.Cron = "30 7 * *" }, "cron: expected 5 fields, got 4"},
+		{"cron never matches", func(s *models.Schedule) { s.Cron = "0 0 31 feb *" }, `cron: "0 0 31 feb *" does not match within 5 years`},
+		{"unknown timezone", func(s *models.Schedule) { s.Timezone = "Mars/Olympus" }, `unknown timezone "Mars/Olympus"`},
+		{"missing device", func(s *models.Schedule) { s.DeviceID = "" }, "device_id must be 1 to 255 characters"},
+		{"missing feature", func(s *models.Schedule) { s.FeatureID = uuid.Nil }, "feature_id is required"},
+		{"unknown feature", func(s *models.Schedule) { s.FeatureID = unknown }, "unknown smart feature: " + unknown.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := newTestSchedule(known)
+			tt.modify(schedule)
+
+			_, err := svc.CreateSchedule(context.Background(), schedule)
+
+			assert.ErrorIs(t, err, models.ErrInvalidSchedule)
+			assert.ErrorContains(t, err, tt.message)
+		})
+	}
+}
+
+func TestScheduleService_UpdateKeepsRunState(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	featureRepo := new(mockSmartFeatureRepo)
+	svc := newTestScheduleService(repo, featureRepo, &fakeInvoker{})
+	featureID := uuid.New()
+	expectFeatures(featureRepo, featureID)
+	ctx := context.Background()
+	created, err := svc.CreateSchedule(ctx, newTestSchedule(featureID))
+	require.NoError(t, err)
+	_, err = svc.PauseSchedule(ctx, created.ID)
+	require.NoError(t, err)
+
+	update := newTestSchedule(featureID)
+	update.ID = created.ID
+	update.Cron = "0 * * * *"
+	update.Paused = false
+	updated, err := svc.UpdateSchedule(ctx, update)
+	require.NoError(t, err)
+	assert.True(t, updated.Paused, "updating does not resume")
+	assert.Equal(t, time.Date(2024, 3, 1, 13, 0, 0, 0, time.UTC), updated.NextRunAt.UTC())
+	assert.Equal(t, automationNow, updated.CreatedAt)
+
+	update.ID = uuid.New()
+	_, err = svc.UpdateSchedule(ctx, update)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestScheduleService_PauseAndResume(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	featureRepo := new(mockSmartFeatureRepo)
+	invoker := &fakeInvoker{}
+	svc := newTestScheduleService(repo, featureRepo, invoker)
+	featureID := uuid.New()
+	expectFeatures(featureRepo, featureID)
+	ctx := context.Background()
+
+	schedule := newTestSchedule(featureID)
+	schedule.Cron = ""
+	schedule.Interval = time.Hour
+	created, err := svc.CreateSchedule(ctx, schedule)
+	require.NoError(t, err)
+
+	paused, err := svc.PauseSchedule(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, paused.Paused)
+
+	svc.now = func() time.Time { return automationNow.Add(5 * time.Hour) }
+	svc.runDue(ctx)
+	assert.Empty(t, invoker.invocations, "a paused schedule does not run")
+
+	resumed, err := svc.ResumeSchedule(ctx, created.ID)
+	require.NoError(t, err)
+	assert.False(t, resumed.Paused)
+	assert.Equal(t, automationNow.Add(6*time.Hour), resumed.NextRunAt, "missed runs are skipped")
+
+	again, err := svc.ResumeSchedule(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, resumed.UpdatedAt, again.UpdatedAt)
+
+	_, err = svc.PauseSchedule(ctx, uuid.New())
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestScheduleService_RunDue(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	invoker := &fakeInvoker{fail: map[string]error{"meter-1": errors.New("device offline")}}
+	svc := newTestScheduleService(repo, new(mockSmartFeatureRepo), invoker)
+	lastWeek := automationNow.Add(-7 * 24 * time.Hour)
+
+	blinds := &models.Schedule{
+		ID:         uuid.New(),
+		Cron:       "30 7 * * *",
+		Timezone:   "UTC",
+		DeviceID:   "blinds-1",
+		FeatureID:  uuid.New(),
+		Parameters: map[string]interface{}{"position": float64(100)},
+		NextRunAt:  time.Date(2024, 2, 23, 7, 30, 0, 0, time.UTC),
+	}
+	meter := &models.Schedule{
+		ID:        uuid.New(),
+		Interval:  25 * time.Minute,
+		Timezone:  "UTC",
+		DeviceID:  "meter-1",
+		FeatureID: uuid.New(),
+		NextRunAt: automationNow.Add(-time.Hour),
+	}
+	later := &models.Schedule{
+		ID:        uuid.New(),
+		Interval:  time.Hour,
+		Timezone:  "UTC",
+		DeviceID:  "light-1",
+		FeatureID: uuid.New(),
+		NextRunAt: automationNow.Add(time.Minute),
+		LastRunAt: &lastWeek,
+	}
+	repo.schedules = []*models.Schedule{blinds, meter, later}
+	ctx := context.Background()
+
+	svc.runDue(ctx)
+
+	require.Len(t, invoker.invocations, 2, "missed runs are made once")
+	assert.Equal(t, invocation{"blinds-1", blinds.FeatureID, blinds.Parameters}, invoker.invocations[0])
+	assert.Equal(t, "meter-1", invoker.invocations[1].deviceID)
+
+	assert.Equal(t, time.Date(2024, 3, 2, 7, 30, 0, 0, time.UTC), repo.schedules[0].NextRunAt)
+	assert.Equal(t, time.Date(2024, 2, 23, 7, 30, 0, 0, time.UTC), *repo.schedules[0].LastRunAt)
+	// The interval keeps its phase: 11:00 + 3 * 25 minutes.
+	assert.Equal(t, automationNow.Add(15*time.Minute), repo.schedules[1].NextRunAt)
+	assert.Equal(t, automationNow.Add(time.Minute), repo.schedules[2].NextRunAt)
+
+	require.Len(t, repo.runs, 2)
+	assert.Equal(t, blinds.ID, repo.runs[0].ScheduleID)
+	assert.Equal(t, models.ExecutionSucceeded, repo.runs[0].Status)
+	assert.Equal(t, time.Date(2024, 2, 23, 7, 30, 0, 0, time.UTC), repo.runs[0].ScheduledAt)
+	require.NotNil(t, repo.runs[0].FinishedAt)
+	assert.Equal(t, models.ExecutionFailed, repo.runs[1].Status)
+	assert.Equal(t, "device offline", repo.runs[1].Error)
+
+	svc.runDue(ctx)
+	assert.Len(t, invoker.invocations, 2, "nothing is due any more")
+}
+
+func TestScheduleService_FireSkipsAdvancedSchedule(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	invoker := &fakeInvoker{}
+	svc := newTestScheduleService(repo, new(mockSmartFeatureRepo), invoker)
+	schedule := &models.Schedule{
+		ID:        uuid.New(),
+		Interval:  time.Hour,
+		Timezone:  "UTC",
+		DeviceID:  "meter-1",
+		FeatureID: uuid.New(),
+		NextRunAt: automationNow.Add(time.Hour),
+	}
+	repo.schedules = []*models.Schedule{schedule}
+
+	// Another replica advanced the schedule after this one listed it.
+	stale := *schedule
+	stale.NextRunAt = automationNow
+	svc.fire(context.Background(), &stale)
+
+	assert.Empty(t, invoker.invocations)
+	assert.Empty(t, repo.runs)
+}
+
+func TestScheduleService_RunLeadsBeforeFiring(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	invoker := &fakeInvoker{}
+	elector := &fakeLeaderElector{}
+	svc := NewScheduleService(repo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, invoker, elector, time.Hour, time.Second, time.Hour)
+	svc.now = func() time.Time { return automationNow }
+	repo.schedules = []*models.Schedule{{
+		ID:        uuid.New(),
+		Interval:  time.Hour,
+		Timezone:  "UTC",
+		DeviceID:  "meter-1",
+		FeatureID: uuid.New(),
+		NextRunAt: automationNow,
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	svc.Run(ctx)
+
+	assert.Equal(t, 1, elector.led)
+	assert.Len(t, invoker.invocations, 1, "due schedules fire as soon as leadership is acquired")
+}
+
+func TestScheduleService_ListRuns(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	svc := newTestScheduleService(repo, new(mockSmartFeatureRepo), &fakeInvoker{})
+	schedule := &models.Schedule{ID: uuid.New()}
+	repo.schedules = append(repo.schedules, schedule)
+	for i := 0; i < 3; i++ {
+		repo.runs = append(repo.runs, &models.ScheduleRun{
+			ID:         uuid.New(),
+			ScheduleID: schedule.ID,
+			StartedAt:  automationNow.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	ctx := context.Background()
+
+	page, token, err := svc.ListRuns(ctx, &schedule.ID, 2, "")
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	cursor, err := models.ParsePageToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, repo.runs[1].ID, cursor.ID)
+
+	page, token, err = svc.ListRuns(ctx, &schedule.ID, 2, token)
+	require.NoError(t, err)
+	assert.Len(t, page, 1)
+	assert.Empty(t, token)
+
+	missing := uuid.New()
+	_, _, err = svc.ListRuns(ctx, &missing, 2, "")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
@@ -0,0 +1,605 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"reflect"
+	"slices"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shadowUpdateAttempts bounds the retries of an update without an expected
+// version that lost a race with another writer.
+const shadowUpdateAttempts = 3
+
+// ShadowService keeps the device shadows and fans their delta log out to
+// watchers. The fan-out works like CatalogWatchService: a single reader
+// follows the log, woken by the listener, by updates made through this
+// service and by a poll interval, and hands deltas to every subscriber in
+// sequence order. The resume token of a delta is its sequence.
+type ShadowService struct {
+	repo         interfaces.ShadowRepository
+	featureRepo  interfaces.SmartFeatureRepository
+	uow          interfaces.UnitOfWork
+	listener     interfaces.ChangeListener
+	bufferSize   int
+	pollInterval time.Duration
+	retention    time.Duration
+	now          func() time.Time
+	observers    []interfaces.StateObserver
+
+	wake     chan struct{}
+	ready    chan struct{}
+	mu       sync.Mutex
+	cursor   int64
+	gapSince time.Time
+	stopped  bool
+	subs     map[*shadowSubscriber]struct{}
+}
+
+type shadowSubscriber struct {
+	deltas chan *models.ShadowDelta
+	done   chan struct{}
+	err    error
+}
+
+func NewShadowService(
+	repo interfaces.ShadowRepository,
+	featureRepo interfaces.SmartFeatureRepository,
+	uow interfaces.UnitOfWork,
+	listener interfaces.ChangeListener,
+	bufferSize int,
+	pollInterval time.Duration,
+	retention time.Duration,
+) *ShadowService {
+	return &ShadowService{
+		repo:         repo,
+		featureRepo:  featureRepo,
+		uow:          uow,
+		listener:     listener,
+		bufferSize:   bufferSize,
+		pollInterval: pollInterval,
+		retention:    retention,
+		now:          time.Now,
+		wake:         make(chan struct{}, 1),
+		ready:        make(chan struct{}),
+		subs:         make(map[*shadowSubscriber]struct{}),
+	}
+}
+
+// AddObserver registers an observer of reported state changes. It must be
+// called before the service is used.
+func (s *ShadowService) AddObserver(observer interfaces.StateObserver) {
+	s.observers = append(s.observers, observer)
+}
+
+func (s *ShadowService) GetShadow(ctx context.Context, deviceID string) (*models.DeviceShadow, error) {
+	ctx, span := tracing.StartSpan(ctx, "ShadowService.GetShadow", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get device shadow", "device_id", deviceID)
+	shadow, err := s.repo.GetShadow(ctx, deviceID)
+	tracing.RecordError(span, err)
+	return shadow, err
+}
+
+// UpdateDesiredState merges state into the desired section of the shadow of
+// deviceID, creating the shadow if needed. A nil value removes its key. When
+// expectedVersion is set the update fails with ErrVersionConflict unless the
+// shadow is at that version, 0 meaning that it does not exist yet.
+func (s *ShadowService) UpdateDesiredState(ctx context.Context, deviceID string, state map[string]interface{}, expectedVersion *int64) (*models.DeviceShadow, error) {
+	ctx, span := tracing.StartSpan(ctx, "ShadowService.UpdateDesiredState", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Update desired device state", "device_id", deviceID, "state", state)
+	shadow, _, err := s.update(ctx, deviceID, state, expectedVersion, func(shadow *models.DeviceShadow) *map[string]interface{} {
+		return &shadow.Desired
+	})
+	tracing.RecordError(span, err)
+	return shadow, err
+}
+
+// InvokeFeature asks deviceID to apply parameters to a smart feature by
+// setting them as the desired state of the feature.
+func (s *ShadowService) InvokeFeature(ctx context.Context, deviceID string, featureID uuid.UUID, parameters map[string]interface{}) error {
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+	_, err := s.UpdateDesiredState(ctx, deviceID, map[string]interface{}{featureID.String(): parameters}, nil)
+	return err
+}
+
+// ReportState merges state into the reported section, like
+// UpdateDesiredState does for the desired one. Observers are told about
+// every change of the reported state.
+func (s *ShadowService) ReportState(ctx context.Context, deviceID string, state map[string]interface{}, expectedVersion *int64) (*models.DeviceShadow, error) {
+	ctx, span := tracing.StartSpan(ctx, "ShadowService.ReportState", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Report device state", "device_id", deviceID, "state", state)
+	shadow, previous, err := s.update(ctx, deviceID, state, expectedVersion, func(shadow *models.DeviceShadow) *map[string]interface{} {
+		return &shadow.Reported
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if !reflect.DeepEqual(previous, shadow.Reported) {
+		for _, observer := range s.observers {
+			observer.ObserveReportedState(deviceID, previous, shadow.Reported)
+		}
+	}
+	return shadow, nil
+}
+
+// update applies state to the section of the shadow and logs the delta when
+// it changed. It also returns the section as it was before. Without an
+// expected version, an update that raced with another writer is retried on
+// the new version.
+func (s *ShadowService) update(
+	ctx context.Context,
+	deviceID string,
+	state map[string]interface{},
+	expectedVersion *int64,
+	section func(*models.DeviceShadow) *map[string]interface{},
+) (*models.DeviceShadow, map[string]interface{}, error) {
+	if err := s.validateState(ctx, deviceID, state); err != nil {
+		return nil, nil, err
+	}
+
+	var shadow *models.DeviceShadow
+	var previous map[string]interface{}
+	var logged bool
+	for attempt := 1; ; attempt++ {
+		err := s.uow.Do(ctx, func(ctx context.Context) error {
+			var err error
+			shadow, previous, logged, err = s.applyState(ctx, deviceID, state, expectedVersion, section)
+			return err
+		})
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, models.ErrVersionConflict) || expectedVersion != nil || attempt == shadowUpdateAttempts {
+			return nil, nil, err
+		}
+		logger.FromContext(ctx).Debug("Retrying conflicting shadow update", "device_id", deviceID, "attempt", attempt)
+	}
+
+	if logged {
+		s.notify()
+	}
+	return shadow, previous, nil
+}
+
+func (s *ShadowService) applyState(
+	ctx context.Context,
+	deviceID string,
+	state map[string]interface{},
+	expectedVersion *int64,
+	section func(*models.DeviceShadow) *map[string]interface{},
+) (*models.DeviceShadow, map[string]interface{}, bool, error) {
+	now := s.now()
+	shadow, err := s.repo.GetShadow(ctx, deviceID)
+	if errors.Is(err, models.ErrNotFound) {
+		shadow = &models.DeviceShadow{
+			DeviceID:  deviceID,
+			Desired:   map[string]interface{}{},
+			Reported:  map[string]interface{}{},
+			CreatedAt: now,
+		}
+	} else if err != nil {
+		return nil, nil, false, err
+	}
+
+	if expectedVersion != nil && *expectedVersion != shadow.Version {
+		return nil, nil, false, fmt.Errorf("%w: shadow of %s is at version %d", models.ErrVersionConflict, deviceID, shadow.Version)
+	}
+
+	before := shadow.Delta()
+	target := section(shadow)
+	prior := *target
+	*target = mergeShadowState(prior, state)
+	previous := shadow.Version
+	shadow.Version++
+	shadow.UpdatedAt = now
+	if err := s.repo.SaveShadow(ctx, shadow, previous); err != nil {
+		return nil, nil, false, err
+	}
+
+	after := shadow.Delta()
+	if reflect.DeepEqual(before, after) {
+		return shadow, prior, false, nil
+	}
+	err = s.repo.AddDelta(ctx, &models.ShadowDelta{
+		DeviceID:  deviceID,
+		Version:   shadow.Version,
+		Delta:     after,
+		CreatedAt: now,
+	})
+	return shadow, prior, err == nil, err
+}
+
+// validateState checks the device ID and that every key that is set names
+// an existing smart feature. Keys that are being removed are not checked,
+// so that the values of deleted features can still be cleared.
+func (s *ShadowService) validateState(ctx context.Context, deviceID string, state map[string]interface{}) error {
+	if deviceID == "" || len(deviceID) > maxDeviceIDLength {
+		return fmt.Errorf("%w: device_id must be 1 to %d characters", models.ErrInvalidShadowState, maxDeviceIDLength)
+	}
+
+	var featureIDs []string
+	for key, value := range state {
+		if value == nil {
+			continue
+		}
+		// Keys must be canonical so one feature cannot appear twice.
+		if id, err := uuid.Parse(key); err != nil || id.String() != key {
+			return fmt.Errorf("%w: key %q is not a lowercase smart feature ID", models.ErrInvalidShadowState, key)
+		}
+		featureIDs = append(featureIDs, key)
+	}
+
+	missing, err := missingFeatures(ctx, s.featureRepo, featureIDs)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: unknown smart features: %s", models.ErrInvalidShadowState, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// DeleteShadow removes the shadow of deviceID. When the device still had a
+// delta, watchers get an empty one so they stop acting on it.
+func (s *ShadowService) DeleteShadow(ctx context.Context, deviceID string) error {
+	ctx, span := tracing.StartSpan(ctx, "ShadowService.DeleteShadow", attribute.String("device.id", deviceID))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Delete device shadow", "device_id", deviceID)
+
+	var logged bool
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		shadow, err := s.repo.GetShadow(ctx, deviceID)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.DeleteShadow(ctx, deviceID); err != nil {
+			return err
+		}
+		if len(shadow.Delta()) == 0 {
+			return nil
+		}
+		logged = true
+		return s.repo.AddDelta(ctx, &models.ShadowDelta{
+			DeviceID:  deviceID,
+			Delta:     map[string]interface{}{},
+			CreatedAt: s.now(),
+		})
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	if logged {
+		s.notify()
+	}
+	return nil
+}
+
+// Run follows the delta log until ctx is cancelled, then ends all open
+// watches with ErrWatchStopped.
+func (s *ShadowService) Run(ctx context.Context) {
+	defer s.stop()
+
+	for {
+		cursor, err := s.repo.LatestDeltaSequence(ctx)
+		if err == nil {
+			s.mu.Lock()
+			s.cursor = cursor
+			s.mu.Unlock()
+			break
+		}
+		logger.Error("Failed to read shadow delta head", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.pollInterval):
+		}
+	}
+	close(s.ready)
+
+	if s.listener != nil {
+		go func() {
+			if err := s.listener.Listen(ctx, s.notify); err != nil && ctx.Err() == nil {
+				logger.Error("Shadow delta listener stopped", err)
+			}
+		}()
+	}
+
+	pollTicker := time.NewTicker(s.pollInterval)
+	defer pollTicker.Stop()
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-pollTicker.C:
+		case <-pruneTicker.C:
+			s.prune(ctx)
+			continue
+		}
+
+		if err := s.poll(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Failed to read shadow deltas", err)
+		}
+	}
+}
+
+// poll reads new deltas from the log and dispatches them. It stops at a
+// missing sequence until the gap is filled or watchGapTimeout has passed.
+func (s *ShadowService) poll(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		cursor := s.cursor
+		s.mu.Unlock()
+
+		deltas, err := s.repo.ListDeltasSince(ctx, cursor, watchBatchSize)
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		blocked := false
+		for _, delta := range deltas {
+			if delta.Sequence != s.cursor+1 {
+				if s.gapSince.IsZero() {
+					s.gapSince = time.Now()
+				}
+				if time.Since(s.gapSince) < watchGapTimeout {
+					blocked = true
+					break
+				}
+			}
+			s.gapSince = time.Time{}
+			s.cursor = delta.Sequence
+			s.dispatch(delta)
+		}
+		s.mu.Unlock()
+
+		if blocked {
+			time.AfterFunc(watchGapTimeout, s.notify)
+			return nil
+		}
+		if len(deltas) < watchBatchSize {
+			return nil
+		}
+	}
+}
+
+// WatchDeltas sends the deltas of deviceIDs, or of every device when
+// deviceIDs is empty, until ctx is done or the watch fails. Without a resume
+// token it starts with the current non-empty deltas, all carrying the token
+// of the log head.
+func (s *ShadowService) WatchDeltas(ctx context.Context, deviceIDs []string, resumeToken string, send func(*models.ShadowDeltaEvent) error) error {
+	ctx, span := tracing.StartSpan(ctx, "ShadowService.WatchDeltas",
+		attribute.Int("watch.devices", len(deviceIDs)),
+		attribute.Bool("watch.resume", resumeToken != ""),
+	)
+	defer span.End()
+
+	err := s.watch(ctx, deviceIDs, resumeToken, send)
+	if err != nil && ctx.Err() == nil {
+		tracing.RecordError(span, err)
+	}
+	return err
+}
+
+func (s *ShadowService) watch(ctx context.Context, deviceIDs []string, resumeToken string, send func(*models.ShadowDeltaEvent) error) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.ready:
+	}
+
+	sub, head, err := s.subscribe()
+	if err != nil {
+		return err
+	}
+	defer s.unsubscribe(sub)
+
+	watched := func(delta *models.ShadowDelta) bool {
+		return len(deviceIDs) == 0 || slices.Contains(deviceIDs, delta.DeviceID)
+	}
+
+	if resumeToken == "" {
+		// A replica may not have caught up with head yet.
+		shadows, err := s.repo.ListShadows(database.ForcePrimary(ctx), deviceIDs)
+		if err != nil {
+			return err
+		}
+		headToken := formatResumeToken(head)
+		for _, shadow := range shadows {
+			delta := shadow.Delta()
+			if len(delta) == 0 {
+				continue
+			}
+			err := send(&models.ShadowDeltaEvent{
+				Delta: &models.ShadowDelta{
+					DeviceID:  shadow.DeviceID,
+					Version:   shadow.Version,
+					Delta:     delta,
+					CreatedAt: shadow.UpdatedAt,
+				},
+				ResumeToken: headToken,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	} else if err := s.catchUp(ctx, resumeToken, head, watched, send); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sub.done:
+			return sub.err
+		case delta := <-sub.deltas:
+			if !watched(delta) {
+				continue
+			}
+			if err := send(&models.ShadowDeltaEvent{Delta: delta, ResumeToken: formatResumeToken(delta.Sequence)}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// catchUp replays the logged deltas between resumeToken and head.
+func (s *ShadowService) catchUp(
+	ctx context.Context,
+	resumeToken string,
+	head int64,
+	watched func(*models.ShadowDelta) bool,
+	send func(*models.ShadowDeltaEvent) error,
+) error {
+	after, err := parseResumeToken(resumeToken)
+	if err != nil || after > head {
+		return models.ErrInvalidResumeToken
+	}
+	if after == head {
+		return nil
+	}
+
+	oldest, err := s.repo.OldestDeltaSequence(ctx)
+	if err != nil {
+		return err
+	}
+	if oldest == 0 || after < oldest-1 {
+		return models.ErrResumeTokenExpired
+	}
+
+	for after < head {
+		deltas, err := s.repo.ListDeltasSince(ctx, after, watchBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(deltas) == 0 {
+			return nil
+		}
+		for _, delta := range deltas {
+			if delta.Sequence > head {
+				return nil
+			}
+			after = delta.Sequence
+			if !watched(delta) {
+				continue
+			}
+			if err := send(&models.ShadowDeltaEvent{Delta: delta, ResumeToken: formatResumeToken(delta.Sequence)}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ShadowService) subscribe() (*shadowSubscriber, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return nil, 0, models.ErrWatchStopped
+	}
+	sub := &shadowSubscriber{
+		deltas: make(chan *models.ShadowDelta, s.bufferSize),
+		done:   make(chan struct{}),
+	}
+	s.subs[sub] = struct{}{}
+	return sub, s.cursor, nil
+}
+
+func (s *ShadowService) unsubscribe(sub *shadowSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, sub)
+}
+
+// dispatch never blocks the reader: a subscriber whose buffer is full is
+// dropped with ErrSlowConsumer. Must be called with s.mu held.
+func (s *ShadowService) dispatch(delta *models.ShadowDelta) {
+	for sub := range s.subs {
+		select {
+		case sub.deltas <- delta:
+		default:
+			logger.Warn("Dropping slow shadow delta watcher", "sequence", delta.Sequence)
+			s.closeSubscriber(sub, models.ErrSlowConsumer)
+		}
+	}
+}
+
+func (s *ShadowService) closeSubscriber(sub *shadowSubscriber, err error) {
+	sub.err = err
+	close(sub.done)
+	delete(s.subs, sub)
+}
+
+func (s *ShadowService) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	for sub := range s.subs {
+		s.closeSubscriber(sub, models.ErrWatchStopped)
+	}
+}
+
+func (s *ShadowService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *ShadowService) prune(ctx context.Context) {
+	pruned, err := s.repo.PruneDeltas(ctx, s.now().Add(-s.retention))
+	if err != nil {
+		logger.Error("Failed to prune shadow deltas", err)
+		return
+	}
+	if pruned > 0 {
+		logger.Debug("Pruned shadow deltas", "count", pruned)
+	}
+}
+
+// mergeShadowState returns a copy of section with state applied; a nil
+// value removes its key.
+func mergeShadowState(section, state map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(section)+len(state))
+	for key, value := range section {
+		merged[key] = value
+	}
+	for key, value := range state {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
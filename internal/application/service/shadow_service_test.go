@@ -0,0 +1,482 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeShadowRepo struct {
+	mu      sync.Mutex
+	shadows map[string]*models.DeviceShadow
+	deltas  []*models.ShadowDelta
+	seq     int64
+	// beforeSave runs before every SaveShadow, to simulate a concurrent
+	// writer.
+	beforeSave func(r *fakeShadowRepo)
+}
+
+func newFakeShadowRepo() *fakeShadowRepo {
+	return &fakeShadowRepo{shadows: make(map[string]*models.DeviceShadow)}
+}
+
+func (r *fakeShadowRepo) GetShadow(ctx context.Context, deviceID string) (*models.DeviceShadow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	shadow, ok := r.shadows[deviceID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	clone := *shadow
+	return &clone, nil
+}
+
+func (r *fakeShadowRepo) ListShadows(ctx context.Context, deviceIDs []string) ([]*models.DeviceShadow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var shadows []*models.DeviceShadow
+	for _, deviceID := range deviceIDs {
+		if shadow, ok := r.shadows[deviceID]; ok {
+			shadows = append(shadows, shadow)
+		}
+	}
+	return shadows, nil
+}
+
+func (r *fakeShadowRepo) SaveShadow(ctx context.Context, shadow *models.DeviceShadow, expectedVersion int64) error {
+	if r.beforeSave != nil {
+		r.beforeSave(r)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.shadows[shadow.DeviceID]
+	if (expectedVersion == 0 && ok) || (expectedVersion != 0 && (!ok || existing.Version != expectedVersion)) {
+		return models.ErrVersionConflict
+	}
+	clone := *shadow
+	r.shadows[shadow.DeviceID] = &clone
+	return nil
+}
+
+func (r *fakeShadowRepo) DeleteShadow(ctx context.Context, deviceID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.shadows[deviceID]; !ok {
+		return models.ErrNotFound
+	}
+	delete(r.shadows, deviceID)
+	return nil
+}
+
+func (r *fakeShadowRepo) AddDelta(ctx context.Context, delta *models.ShadowDelta) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	delta.Sequence = r.seq
+	r.deltas = append(r.deltas, delta)
+	return nil
+}
+
+func (r *fakeShadowRepo) ListDeltasSince(ctx context.Context, after int64, limit int) ([]*models.ShadowDelta, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []*models.ShadowDelta
+	for _, delta := range r.deltas {
+		if delta.Sequence > after && len(result) < limit {
+			result = append(result, delta)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeShadowRepo) LatestDeltaSequence(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seq, nil
+}
+
+func (r *fakeShadowRepo) OldestDeltaSequence(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.deltas) == 0 {
+		return 0, nil
+	}
+	return r.deltas[0].Sequence, nil
+}
+
+func (r *fakeShadowRepo) PruneDeltas(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeShadowRepo) loggedDeltas() []*models.ShadowDelta {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*models.ShadowDelta(nil), r.deltas...)
+}
+
+func newShadowService(repo *fakeShadowRepo, featureRepo *mockSmartFeatureRepo) *ShadowService {
+	svc := NewShadowService(repo, featureRepo, &fakeUnitOfWork{}, nil, 16, time.Hour, time.Hour)
+	svc.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func expectFeatures(featureRepo *mockSmartFeatureRepo, ids ...uuid.UUID) {
+	features := make([]*models.SmartFeature, len(ids))
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		features[i] = &models.SmartFeature{ID: id}
+		keys[i] = id.String()
+	}
+	slices.Sort(keys)
+	featureRepo.On("GetByIDs", mock.Anything, mock.MatchedBy(func(requested []string) bool {
+		sorted := slices.Clone(requested)
+		slices.Sort(sorted)
+		return slices.Equal(keys, sorted)
+	})).Return(features, nil)
+}
+
+func TestShadowService_UpdateAndReport(t *testing.T) {
+	repo := newFakeShadowRepo()
+	featureRepo := new(mockSmartFeatureRepo)
+	power := uuid.New()
+	level := uuid.New()
+	expectFeatures(featureRepo, power, level)
+	expectFeatures(featureRepo, power)
+	svc := newShadowService(repo, featureRepo)
+	ctx := context.Background()
+
+	shadow, err := svc.UpdateDesiredState(ctx, "dev-1", map[string]interface{}{power.String(): "on", level.String(): 3.0}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), shadow.Version)
+	assert.Equal(t, map[string]interface{}{power.String(): "on", level.String(): 3.0}, shadow.Delta())
+
+	shadow, err = svc.ReportState(ctx, "dev-1", map[string]interface{}{power.String(): "on"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), shadow.Version)
+	assert.Equal(t, map[string]interface{}{level.String(): 3.0}, shadow.Delta())
+
+	// Removing the pending key leaves nothing to apply.
+	shadow, err = svc.UpdateDesiredState(ctx, "dev-1", map[string]interface{}{level.String(): nil}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), shadow.Version)
+	assert.Equal(t, map[string]interface{}{power.String(): "on"}, shadow.Desired)
+	assert.Empty(t, shadow.Delta())
+
+	deltas := repo.loggedDeltas()
+	require.Len(t, deltas, 3)
+	assert.Equal(t, int64(1), deltas[0].Version)
+	assert.Len(t, deltas[0].Delta, 2)
+	assert.Equal(t, map[string]interface{}{level.String(): 3.0}, deltas[1].Delta)
+	assert.Empty(t, deltas[2].Delta)
+	assert.Equal(t, int64(3), deltas[2].Version)
+}
+
+func TestShadowService_UnchangedDeltaIsNotLogged(t *testing.T) {
+	repo := newFakeShadowRepo()
+	featureRepo := new(mockSmartFeatureRepo)
+	power := uuid.New()
+	expectFeatures(featureRepo, power)
+	svc := newShadowService(repo, featureRepo)
+
+	shadow, err := svc.ReportState(context.Background(), "dev-1", map[string]interface{}{power.String(): "off"}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), shadow.Version)
+	assert.Empty(t, repo.loggedDeltas())
+}
+
+func TestShadowService_InvalidState(t *testing.T) {
+	featureRepo := new(mockSmartFeatureRepo)
+	known := uuid.New()
+	unknown := uuid.New()
+	featureRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{{ID: known}}, nil)
+	svc := newShadowService(newFakeShadowRepo(), featureRepo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		deviceID string
+		state    map[string]interface{}
+	}{
+		{"missing device ID", "", map[string]interface{}{known.String(): 1.0}},
+		{"key is not a UUID", "dev-1", map[string]interface{}{"power": "on"}},
+		{"key is not canonical", "dev-1", map[string]interface{}{"{" + known.String() + "}": "on"}},
+		{"unknown feature", "dev-1", map[string]interface{}{known.String(): 1.0, unknown.String(): 2.0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.UpdateDesiredState(ctx, tt.deviceID, tt.state, nil)
+			assert.ErrorIs(t, err, models.ErrInvalidShadowState)
+		})
+	}
+
+	// Removing a key does not need the feature to exist.
+	_, err := svc.UpdateDesiredState(ctx, "dev-1", map[string]interface{}{"stale": nil}, nil)
+	assert.NoError(t, err)
+}
+
+type recordingStateObserver struct {
+	changes [][2]map[string]interface{}
+}
+
+func (o *recordingStateObserver) ObserveReportedState(deviceID string, previous, current map[string]interface{}) {
+	o.changes = append(o.changes, [2]map[string]interface{}{previous, current})
+}
+
+func TestShadowService_ReportStateNotifiesObservers(t *testing.T) {
+	featureRepo := new(mockSmartFeatureRepo)
+	power := uuid.New()
+	expectFeatures(featureRepo, power)
+	svc := newShadowService(newFakeShadowRepo(), featureRepo)
+	observer := &recordingStateObserver{}
+	svc.AddObserver(observer)
+	ctx := context.Background()
+
+	_, err := svc.ReportState(ctx, "dev-1", map[string]interface{}{power.String(): "on"}, nil)
+	require.NoError(t, err)
+	_, err = svc.ReportState(ctx, "dev-1", map[string]interface{}{power.String(): "on"}, nil)
+	require.NoError(t, err)
+	_, err = svc.UpdateDesiredState(ctx, "dev-1", map[string]interface{}{power.String(): "off"}, nil)
+	require.NoError(t, err)
+
+	require.Len(t, observer.changes, 1)
+	assert.Empty(t, observer.changes[0][0])
+	assert.Equal(t, map[string]interface{}{power.String(): "on"}, observer.changes[0][1])
+}
+
+func TestShadowService_InvokeFeature(t *testing.T) {
+	featureRepo := new(mockSmartFeatureRepo)
+	power := uuid.New()
+	expectFeatures(featureRepo, power)
+	repo := newFakeShadowRepo()
+	svc := newShadowService(repo, featureRepo)
+	ctx := context.Background()
+
+	require.NoError(t, svc.InvokeFeature(ctx, "dev-1", power, map[string]interface{}{"on": true}))
+	require.NoError(t, svc.InvokeFeature(ctx, "dev-2", power, nil))
+
+	shadow, err := repo.GetShadow(ctx, "dev-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{power.String(): map[string]interface{}{"on": true}}, shadow.Desired)
+	shadow, err = repo.GetShadow(ctx, "dev-2")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{power.String(): map[string]interface{}{}}, shadow.Desired)
+}
+
+func TestShadowService_ExpectedVersion(t *testing.T) {
+	repo := newFakeShadowRepo()
+	featureRepo := new(mockSmartFeatureRepo)
+	power := uuid.New()
+	expectFeatures(featureRepo, power)
+	svc := newShadowService(repo, featureRepo)
+	ctx := context.Background()
+	state := map[string]interface{}{power.String(): "on"}
+
+	zero := int64(0)
+	_, err := svc.UpdateDesiredState(ctx, "dev-1", state, &zero)
+	require.NoError(t, err)
+
+	_, err = svc.UpdateDesiredState(ctx, "dev-1", state, &zero)
+	assert.ErrorIs(t, err, models.ErrVersionConflict)
+
+	one := int64(1)
+	shadow, err := svc.ReportState(ctx, "dev-1", state, &one)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), shadow.Version)
+
+	_, err = svc.ReportState(ctx, "dev-1", state, &one)
+	assert.ErrorIs(t, err, models.ErrVersionConflict)
+}
+
+func TestShadowService_RetriesConflictWithoutExpectedVersion(t *testing.T) {
+	repo := newFakeShadowRepo()
+	featureRepo := new(mockSmartFeatureRepo)
+	power := uuid.New()
+	expectFeatures(featureRepo, power)
+	svc := newShadowService(repo, featureRepo)
+
+	concurrentWrites := 1
+	repo.beforeSave = func(r *fakeShadowRepo) {
+		if concurrentWrites == 0 {
+			return
+		}
+		concurrentWrites--
+		r.mu.Lock()
+		r.shadows["dev-1"] = &models.DeviceShadow{DeviceID: "dev-1", Version: 1, Reported: map[string]interface{}{power.String(): "off"}}
+		r.mu.Unlock()
+	}
+
+	shadow, err := svc.UpdateDesiredState(context.Background(), "dev-1", map[string]interface{}{power.String(): "on"}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), shadow.Version)
+	assert.Equal(t, map[string]interface{}{power.String(): "off"}, shadow.Reported, "the retry must apply on top of the concurrent write")
+}
+
+func TestShadowService_RetriesAreBounded(t *testing.T) {
+	repo := newFakeShadowRepo()
+	featureRepo := new(mockSmartFeatureRepo)
+	power := uuid.New()
+	expectFeatures(featureRepo, power)
+	svc := newShadowService(repo, featureRepo)
+
+	saves := 0
+	repo.beforeSave = func(r *fakeShadowRepo) {
+		saves++
+		r.mu.Lock()
+		r.shadows["dev-1"] = &models.DeviceShadow{DeviceID: "dev-1", Version: int64(saves)}
+		r.mu.Unlock()
+	}
+
+	_, err := svc.UpdateDesiredState(context.Background(), "dev-1", map[string]interface{}{power.String(): "on"}, nil)
+
+	assert.ErrorIs(t, err, models.ErrVersionConflict)
+	assert.Equal(t, shadowUpdateAttempts, saves)
+}
+
+func TestShadowService_DeleteShadow(t *testing.T) {
+	repo := newFakeShadowRepo()
+	svc := newShadowService(repo, new(mockSmartFeatureRepo))
+	repo.shadows["dev-1"] = &models.DeviceShadow{DeviceID: "dev-1", Version: 4, Desired: map[string]interface{}{"a": "on"}}
+	repo.shadows["dev-2"] = &models.DeviceShadow{DeviceID: "dev-2", Version: 2}
+
+	require.NoError(t, svc.DeleteShadow(context.Background(), "dev-1"))
+	require.NoError(t, svc.DeleteShadow(context.Background(), "dev-2"))
+	assert.ErrorIs(t, svc.DeleteShadow(context.Background(), "dev-2"), models.ErrNotFound)
+
+	deltas := repo.loggedDeltas()
+	require.Len(t, deltas, 1, "only a shadow with a pending delta logs its deletion")
+	assert.Equal(t, "dev-1", deltas[0].DeviceID)
+	assert.Zero(t, deltas[0].Version)
+	assert.Empty(t, deltas[0].Delta)
+}
+
+func startShadowService(t *testing.T, svc *ShadowService) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	go svc.Run(ctx)
+
+	select {
+	case <-svc.ready:
+	case <-time.After(time.Second):
+		t.Fatal("shadow service did not start")
+	}
+	return cancel
+}
+
+func receiveDelta(t *testing.T, events <-chan *models.ShadowDeltaEvent) *models.ShadowDeltaEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no shadow delta received")
+		return nil
+	}
+}
+
+func TestShadowService_WatchDeltas_SnapshotThenLive(t *testing.T) {
+	repo := newFakeShadowRepo()
+	featureRepo := new(mockSmartFeatureRepo)
+	power := uuid.New()
+	expectFeatures(featureRepo, power)
+	repo.shadows["dev-1"] = &models.DeviceShadow{DeviceID: "dev-1", Version: 3, Desired: map[string]interface{}{power.String(): "on"}}
+	repo.shadows["dev-2"] = &models.DeviceShadow{DeviceID: "dev-2", Version: 1}
+	repo.AddDelta(context.Background(), &models.ShadowDelta{DeviceID: "dev-1", Version: 3})
+
+	svc := newShadowService(repo, featureRepo)
+	stop := startShadowService(t, svc)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan *models.ShadowDeltaEvent, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.WatchDeltas(ctx, []string{"dev-1", "dev-2"}, "", func(event *models.ShadowDeltaEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	existing := receiveDelta(t, events)
+	assert.Equal(t, "dev-1", existing.Delta.DeviceID)
+	assert.Equal(t, int64(3), existing.Delta.Version)
+	assert.Equal(t, map[string]interface{}{power.String(): "on"}, existing.Delta.Delta)
+	assert.Equal(t, "1", existing.ResumeToken)
+
+	assert.Eventually(t, func() bool {
+		svc.mu.Lock()
+		defer svc.mu.Unlock()
+		return len(svc.subs) == 1
+	}, time.Second, time.Millisecond)
+
+	// Deltas of other devices are filtered out; updates through the service
+	// wake the reader without waiting for the poll interval.
+	_, err := svc.UpdateDesiredState(context.Background(), "dev-3", map[string]interface{}{power.String(): "on"}, nil)
+	require.NoError(t, err)
+	_, err = svc.ReportState(context.Background(), "dev-1", map[string]interface{}{power.String(): "on"}, nil)
+	require.NoError(t, err)
+
+	live := receiveDelta(t, events)
+	assert.Equal(t, "dev-1", live.Delta.DeviceID)
+	assert.Equal(t, int64(4), live.Delta.Version)
+	assert.Empty(t, live.Delta.Delta)
+	assert.Equal(t, "3", live.ResumeToken)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestShadowService_WatchDeltas_Resume(t *testing.T) {
+	repo := newFakeShadowRepo()
+	ctx := context.Background()
+	for i, deviceID := range []string{"dev-1", "dev-2", "dev-1"} {
+		repo.AddDelta(ctx, &models.ShadowDelta{DeviceID: deviceID, Version: int64(i + 1)})
+	}
+
+	svc := newShadowService(repo, new(mockSmartFeatureRepo))
+	stop := startShadowService(t, svc)
+	defer stop()
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := make(chan *models.ShadowDeltaEvent, 16)
+	go func() {
+		_ = svc.WatchDeltas(watchCtx, []string{"dev-1"}, "1", func(event *models.ShadowDeltaEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	event := receiveDelta(t, events)
+	assert.Equal(t, "3", event.ResumeToken)
+	assert.Equal(t, int64(3), event.Delta.Version)
+}
+
+func TestShadowService_WatchDeltas_ResumeTokenErrors(t *testing.T) {
+	repo := newFakeShadowRepo()
+	repo.seq = 4
+	repo.AddDelta(context.Background(), &models.ShadowDelta{DeviceID: "dev-1"})
+
+	svc := newShadowService(repo, new(mockSmartFeatureRepo))
+	stop := startShadowService(t, svc)
+	defer stop()
+
+	send := func(*models.ShadowDeltaEvent) error { return nil }
+	assert.ErrorIs(t, svc.WatchDeltas(context.Background(), nil, "bogus", send), models.ErrInvalidResumeToken)
+	assert.ErrorIs(t, svc.WatchDeltas(context.Background(), nil, "9", send), models.ErrInvalidResumeToken)
+	assert.ErrorIs(t, svc.WatchDeltas(context.Background(), nil, "2", send), models.ErrResumeTokenExpired)
+}
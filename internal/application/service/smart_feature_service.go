@@ -2,47 +2,461 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
 	"smart-hub/internal/domain/interfaces"
 	"smart-hub/internal/domain/models"
+	"strings"
 )
 
 type SmartFeatureService struct {
-	repo interfaces.SmartFeatureRepository
+	repo           interfaces.SmartFeatureRepository
+	modelRepo      interfaces.SmartModelRepository
+	uow            interfaces.UnitOfWork
+	outbox         interfaces.OutboxRepository
+	dependencyRepo interfaces.FeatureDependencyRepository
 }
 
-func NewSmartFeatureService(repo interfaces.SmartFeatureRepository) *SmartFeatureService {
+func NewSmartFeatureService(
+	repo interfaces.SmartFeatureRepository,
+	modelRepo interfaces.SmartModelRepository,
+	uow interfaces.UnitOfWork,
+	outbox interfaces.OutboxRepository,
+	dependencyRepo interfaces.FeatureDependencyRepository,
+) *SmartFeatureService {
 	return &SmartFeatureService{
-		repo: repo,
+		repo:           repo,
+		modelRepo:      modelRepo,
+		uow:            uow,
+		outbox:         outbox,
+		dependencyRepo: dependencyRepo,
 	}
 }
 
+// recordFeatureEvent writes an event for feature to the outbox. It must be
+// called inside the unit of work that changed the feature.
+func (s *SmartFeatureService) recordFeatureEvent(ctx context.Context, eventType models.EventType, id uuid.UUID, payload interface{}) error {
+	event, err := models.NewDomainEvent(models.SmartFeatureAggregate, id, eventType, payload)
+	if err != nil {
+		return err
+	}
+	return s.outbox.Add(ctx, event)
+}
+
 func (s *SmartFeatureService) Create(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error) {
-	logger.Debug("Create smart feature", "feature", feature)
-	return s.repo.Create(ctx, feature)
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.Create", attribute.String("model.id", feature.ModelID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Create smart feature", "feature", feature)
+
+	var createdFeature *models.SmartFeature
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		createdFeature, err = s.repo.Create(ctx, feature)
+		if err != nil {
+			return err
+		}
+		return s.recordFeatureEvent(ctx, models.FeatureCreatedEvent, createdFeature.ID, createdFeature)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return createdFeature, nil
 }
 
 func (s *SmartFeatureService) GetByID(ctx context.Context, id string) (*models.SmartFeature, error) {
-	logger.Debug("Get smart feature by ID", "id", id)
-	return s.repo.GetByID(ctx, id)
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.GetByID", attribute.String("feature.id", id))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get smart feature by ID", "id", id)
+	feature, err := s.repo.GetByID(ctx, id)
+	tracing.RecordError(span, err)
+	return feature, err
 }
 
 func (s *SmartFeatureService) GetWithModelID(ctx context.Context, modelID string) ([]*models.SmartFeature, error) {
-	logger.Debug("Get smart feature by model ID", "modelID", modelID)
-	return s.repo.GetWithModelID(ctx, modelID)
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.GetWithModelID", attribute.String("model.id", modelID))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get smart feature by model ID", "modelID", modelID)
+	features, err := s.repo.GetWithModelID(ctx, modelID)
+	tracing.RecordError(span, err)
+	return features, err
 }
 
 func (s *SmartFeatureService) GetAll(ctx context.Context) ([]*models.SmartFeature, error) {
-	logger.Debug("Get all smart features")
-	return s.repo.GetAll(ctx)
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.GetAll")
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get all smart features")
+	features, err := s.repo.GetAll(ctx)
+	tracing.RecordError(span, err)
+	return features, err
+}
+
+// List returns one page of the features that match filter and the token of
+// the next page, which is empty after the last page.
+func (s *SmartFeatureService) List(ctx context.Context, filter models.FeatureFilter, pageSize int, pageToken string) ([]*models.SmartFeature, string, error) {
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.List", attribute.Int("page.size", pageSize))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("List smart features", "filter", filter, "pageSize", pageSize)
+
+	after, err := models.ParsePageToken(pageToken)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, "", err
+	}
+
+	// One extra feature tells whether there is another page.
+	features, err := s.repo.List(ctx, filter, after, pageSize+1)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, "", err
+	}
+	if len(features) <= pageSize {
+		return features, "", nil
+	}
+
+	features = features[:pageSize]
+	last := features[pageSize-1]
+	return features, models.PageCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Token(), nil
 }
 
 func (s *SmartFeatureService) Update(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error) {
-	logger.Debug("Update smart feature", "feature", feature)
-	return s.repo.Update(ctx, feature)
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.Update", attribute.String("feature.id", feature.ID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Update smart feature", "feature", feature)
+
+	var updatedFeature *models.SmartFeature
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		updatedFeature, err = s.repo.Update(ctx, feature)
+		if err != nil {
+			return err
+		}
+		return s.recordFeatureEvent(ctx, models.FeatureUpdatedEvent, updatedFeature.ID, updatedFeature)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return updatedFeature, nil
 }
 
+// Delete deletes a feature together with the dependencies it declares. It
+// fails with ErrFeatureRequired while other features require it.
 func (s *SmartFeatureService) Delete(ctx context.Context, id string) error {
-	logger.Debug("Delete smart feature", "id", id)
-	return s.repo.Delete(ctx, id)
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.Delete", attribute.String("feature.id", id))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Delete smart feature", "id", id)
+
+	featureID, err := uuid.Parse(id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		feature, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		required, err := s.requiredFeatures(ctx, []*models.SmartFeature{feature})
+		if err != nil {
+			return err
+		}
+		if err := required[feature.ID]; err != nil {
+			return err
+		}
+
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.recordFeatureEvent(ctx, models.FeatureDeletedEvent, featureID, map[string]string{"id": id})
+	})
+	tracing.RecordError(span, err)
+	return err
+}
+
+// BatchCreate creates features in one transaction. The batch is all or
+// nothing unless partial is set: then features whose model does not exist
+// or whose name and interface path are taken within the model are skipped
+// and reported in the per-item errors, and the rest are still created.
+// Results and per-item errors line up with features.
+func (s *SmartFeatureService) BatchCreate(ctx context.Context, features []*models.SmartFeature, partial bool) ([]*models.SmartFeature, []error, error) {
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.BatchCreate",
+		attribute.Int("batch.size", len(features)), attribute.Bool("batch.partial", partial))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Batch create smart features", "count", len(features), "partial", partial)
+
+	results := make([]*models.SmartFeature, len(features))
+	var itemErrs []error
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		modelIDs := make([]string, len(features))
+		for i, feature := range features {
+			modelIDs[i] = feature.ModelID.String()
+		}
+		existing, err := s.modelRepo.GetByIDs(ctx, modelIDs)
+		if err != nil {
+			return err
+		}
+		found := make(map[uuid.UUID]bool, len(existing))
+		foundIDs := make([]string, 0, len(existing))
+		for _, model := range existing {
+			found[model.ID] = true
+			foundIDs = append(foundIDs, model.ID.String())
+		}
+		keys, err := s.featureKeys(ctx, foundIDs)
+		if err != nil {
+			return err
+		}
+
+		var pending []int
+		pending, itemErrs, err = splitBatch(len(features), partial, func(i int) error {
+			if !found[features[i].ModelID] {
+				return fmt.Errorf("%w: smart model %s", models.ErrNotFound, features[i].ModelID)
+			}
+			return keys.claim(features[i].ModelID, features[i], true)
+		})
+		if err != nil || len(pending) == 0 {
+			return err
+		}
+
+		created, err := s.repo.CreateBatch(ctx, pick(features, pending))
+		if err != nil {
+			return requestIndex(err, pending)
+		}
+		events := make([]*models.DomainEvent, len(created))
+		for j, i := range pending {
+			results[i] = created[j]
+			events[j], err = models.NewDomainEvent(models.SmartFeatureAggregate, created[j].ID, models.FeatureCreatedEvent, created[j])
+			if err != nil {
+				return err
+			}
+		}
+		return s.outbox.Add(ctx, events...)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, nil, err
+	}
+
+	return results, itemErrs, nil
+}
+
+// BatchUpdate updates features in one transaction, with the same all or
+// nothing and partial semantics as BatchCreate. Features that do not exist
+// fail with models.ErrNotFound, and those renamed onto the name and
+// interface path of another feature of their model with
+// models.ErrAlreadyExists.
+func (s *SmartFeatureService) BatchUpdate(ctx context.Context, features []*models.SmartFeature, partial bool) ([]*models.SmartFeature, []error, error) {
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.BatchUpdate",
+		attribute.Int("batch.size", len(features)), attribute.Bool("batch.partial", partial))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Batch update smart features", "count", len(features), "partial", partial)
+
+	results := make([]*models.SmartFeature, len(features))
+	var itemErrs []error
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		ids := make([]string, len(features))
+		for i, feature := range features {
+			ids[i] = feature.ID.String()
+		}
+		existing, err := s.repo.GetByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		stored := make(map[uuid.UUID]*models.SmartFeature, len(existing))
+		modelIDs := make([]string, 0, len(existing))
+		for _, feature := range existing {
+			stored[feature.ID] = feature
+			modelIDs = append(modelIDs, feature.ModelID.String())
+		}
+		keys, err := s.featureKeys(ctx, modelIDs)
+		if err != nil {
+			return err
+		}
+
+		var pending []int
+		pending, itemErrs, err = splitBatch(len(features), partial, func(i int) error {
+			current := stored[features[i].ID]
+			if current == nil {
+				return models.ErrNotFound
+			}
+			return keys.claim(current.ModelID, features[i], false)
+		})
+		if err != nil || len(pending) == 0 {
+			return err
+		}
+
+		updated, err := s.repo.UpdateBatch(ctx, pick(features, pending))
+		if err != nil {
+			return requestIndex(err, pending)
+		}
+		events := make([]*models.DomainEvent, len(updated))
+		for j, i := range pending {
+			results[i] = updated[j]
+			events[j], err = models.NewDomainEvent(models.SmartFeatureAggregate, updated[j].ID, models.FeatureUpdatedEvent, updated[j])
+			if err != nil {
+				return err
+			}
+		}
+		return s.outbox.Add(ctx, events...)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, nil, err
+	}
+
+	return results, itemErrs, nil
+}
+
+// BatchDelete deletes features in one transaction, with the same all or
+// nothing and partial semantics as BatchCreate. Repeated IDs are deleted
+// once. Features that a feature outside the batch requires fail with
+// ErrFeatureRequired.
+func (s *SmartFeatureService) BatchDelete(ctx context.Context, ids []string, partial bool) ([]error, error) {
+	ctx, span := tracing.StartSpan(ctx, "SmartFeatureService.BatchDelete",
+		attribute.Int("batch.size", len(ids)), attribute.Bool("batch.partial", partial))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Batch delete smart features", "count", len(ids), "partial", partial)
+
+	featureIDs := make([]uuid.UUID, len(ids))
+	for i, id := range ids {
+		featureID, err := uuid.Parse(id)
+		if err != nil {
+			err = &models.BatchItemError{Index: i, Err: err}
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		featureIDs[i] = featureID
+	}
+
+	var itemErrs []error
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		found := make(map[uuid.UUID]bool, len(existing))
+		for _, feature := range existing {
+			found[feature.ID] = true
+		}
+		required, err := s.requiredFeatures(ctx, existing)
+		if err != nil {
+			return err
+		}
+
+		var pending []int
+		pending, itemErrs, err = splitBatch(len(ids), partial, func(i int) error {
+			if !found[featureIDs[i]] {
+				return models.ErrNotFound
+			}
+			return required[featureIDs[i]]
+		})
+		if err != nil || len(pending) == 0 {
+			return err
+		}
+
+		if err := s.repo.DeleteBatch(ctx, pick(ids, pending)); err != nil {
+			return err
+		}
+		var events []*models.DomainEvent
+		deleted := make(map[uuid.UUID]bool, len(pending))
+		for _, i := range pending {
+			if deleted[featureIDs[i]] {
+				continue
+			}
+			deleted[featureIDs[i]] = true
+			event, err := models.NewDomainEvent(models.SmartFeatureAggregate, featureIDs[i], models.FeatureDeletedEvent, map[string]string{"id": ids[i]})
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return s.outbox.Add(ctx, events...)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return itemErrs, nil
+}
+
+// featureKey is the unique key of a feature within its model, the same as
+// the smart_features index: the name and interface path ignoring case.
+type featureKey struct {
+	modelID       uuid.UUID
+	name          string
+	interfacePath string
+}
+
+func newFeatureKey(modelID uuid.UUID, name, interfacePath string) featureKey {
+	return featureKey{modelID: modelID, name: strings.ToLower(name), interfacePath: strings.ToLower(interfacePath)}
+}
+
+// featureKeyHolders tracks which feature holds each key while the items of a
+// batch are checked one by one, so that a conflict fails only the item that
+// causes it.
+type featureKeyHolders struct {
+	holders map[featureKey]uuid.UUID
+	keys    map[uuid.UUID]featureKey
+}
+
+// featureKeys loads the keys of the stored features of modelIDs.
+func (s *SmartFeatureService) featureKeys(ctx context.Context, modelIDs []string) (*featureKeyHolders, error) {
+	stored, err := s.repo.GetWithModelIDs(ctx, modelIDs)
+	if err != nil {
+		return nil, err
+	}
+	k := &featureKeyHolders{
+		holders: make(map[featureKey]uuid.UUID, len(stored)),
+		keys:    make(map[uuid.UUID]featureKey, len(stored)),
+	}
+	for _, feature := range stored {
+		key := newFeatureKey(feature.ModelID, feature.Name, feature.InterfacePath)
+		k.holders[key] = feature.ID
+		k.keys[feature.ID] = key
+	}
+	return k, nil
+}
+
+// claim gives the key of feature, stored under modelID, to feature, and
+// fails with a *models.AlreadyExistsError when another feature holds it. A
+// new feature never holds a key yet; an updated one releases its old key.
+func (k *featureKeyHolders) claim(modelID uuid.UUID, feature *models.SmartFeature, isNew bool) error {
+	key := newFeatureKey(modelID, feature.Name, feature.InterfacePath)
+	if holder, ok := k.holders[key]; ok && (isNew || holder != feature.ID) {
+		return &models.AlreadyExistsError{Entity: models.SmartFeatureAggregate, ID: holder}
+	}
+	if old, ok := k.keys[feature.ID]; ok && !isNew {
+		delete(k.holders, old)
+	}
+	k.holders[key] = feature.ID
+	k.keys[feature.ID] = key
+	return nil
+}
+
+// requestIndex translates the item index of a *models.BatchItemError from a
+// repository, which counts the pending items only, to the request index.
+func requestIndex(err error, pending []int) error {
+	var itemErr *models.BatchItemError
+	if errors.As(err, &itemErr) && itemErr.Index < len(pending) {
+		return &models.BatchItemError{Index: pending[itemErr.Index], Err: itemErr.Err}
+	}
+	return err
 }
@@ -38,6 +38,22 @@ func (m *mockSmartFeatureRepo) GetWithModelID(ctx context.Context, modelID strin
 	return args.Get(0).([]*models.SmartFeature), args.Error(1)
 }
 
+func (m *mockSmartFeatureRepo) GetWithModelIDs(ctx context.Context, modelIDs []string) ([]*models.SmartFeature, error) {
+	args := m.Called(ctx, modelIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SmartFeature), args.Error(1)
+}
+
+func (m *mockSmartFeatureRepo) List(ctx context.Context, filter models.FeatureFilter, after *models.PageCursor, limit int) ([]*models.SmartFeature, error) {
+	args := m.Called(ctx, filter, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SmartFeature), args.Error(1)
+}
+
 func (m *mockSmartFeatureRepo) GetAll(ctx context.Context) ([]*models.SmartFeature, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -59,9 +75,39 @@ func (m *mockSmartFeatureRepo) Delete(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *mockSmartFeatureRepo) GetByIDs(ctx context.Context, ids []string) ([]*models.SmartFeature, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SmartFeature), args.Error(1)
+}
+
+func (m *mockSmartFeatureRepo) CreateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error) {
+	args := m.Called(ctx, features)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SmartFeature), args.Error(1)
+}
+
+func (m *mockSmartFeatureRepo) UpdateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error) {
+	args := m.Called(ctx, features)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SmartFeature), args.Error(1)
+}
+
+func (m *mockSmartFeatureRepo) DeleteBatch(ctx context.Context, ids []string) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
 func TestSmartFeatureService_Create(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
 
 	now := time.Now()
 
@@ -78,6 +124,7 @@ func TestSmartFeatureService_Create(t *testing.T) {
 	}
 
 	mockRepo.On("Create", mock.Anything, feature).Return(feature, nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.FeatureCreatedEvent)).Return(nil)
 
 	createdFeature, err := service.Create(context.Background(), feature)
 
@@ -87,11 +134,13 @@ func TestSmartFeatureService_Create(t *testing.T) {
 	assert.Equal(t, feature.Description, createdFeature.Description)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartFeatureService_Create_Error(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
 
 	now := time.Now()
 
@@ -115,11 +164,13 @@ func TestSmartFeatureService_Create_Error(t *testing.T) {
 	assert.Nil(t, createdFeature)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartFeatureService_GetByID(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
 
 	now := time.Now()
 
@@ -145,11 +196,13 @@ func TestSmartFeatureService_GetByID(t *testing.T) {
 	assert.Equal(t, feature.Description, result.Description)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartFeatureService_GetByID_Error(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
 
 	now := time.Now()
 
@@ -173,11 +226,13 @@ func TestSmartFeatureService_GetByID_Error(t *testing.T) {
 	assert.Nil(t, result)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartFeatureService_GetWithModelID(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
 
 	now := time.Now()
 
@@ -203,11 +258,13 @@ func TestSmartFeatureService_GetWithModelID(t *testing.T) {
 	assert.Equal(t, feature.Description, result[0].Description)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartFeatureService_GetWithModelID_Error(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
 
 	now := time.Now()
 
@@ -231,11 +288,13 @@ func TestSmartFeatureService_GetWithModelID_Error(t *testing.T) {
 	assert.Nil(t, result)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartFeatureService_GetAll(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
 
 	now := time.Now()
 
@@ -261,11 +320,13 @@ func TestSmartFeatureService_GetAll(t *testing.T) {
 	assert.Equal(t, feature.Description, result[0].Description)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartFeatureService_GetAll_Error(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
 
 	mockRepo.On("GetAll", mock.Anything).Return(nil, assert.AnError)
 
@@ -275,11 +336,13 @@ func TestSmartFeatureService_GetAll_Error(t *testing.T) {
 	assert.Nil(t, result)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartFeatureService_Update(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
 
 	now := time.Now()
 
@@ -296,6 +359,7 @@ func TestSmartFeatureService_Update(t *testing.T) {
 	}
 
 	mockRepo.On("Update", mock.Anything, feature).Return(feature, nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.FeatureUpdatedEvent)).Return(nil)
 
 	updatedFeature, err := service.Update(context.Background(), feature)
 
@@ -305,11 +369,13 @@ func TestSmartFeatureService_Update(t *testing.T) {
 	assert.Equal(t, feature.Description, updatedFeature.Description)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartFeatureService_Update_Error(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
 
 	now := time.Now()
 
@@ -333,30 +399,387 @@ func TestSmartFeatureService_Update_Error(t *testing.T) {
 	assert.Nil(t, updatedFeature)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+// noDependencies makes every model of service declare no dependencies.
+func noDependencies(service *SmartFeatureService) *mockFeatureDependencyRepo {
+	dependencies := service.dependencyRepo.(*mockFeatureDependencyRepo)
+	dependencies.On("LockModelDependencies", mock.Anything, mock.Anything).Return(nil)
+	dependencies.On("ListModelDependencies", mock.Anything, mock.Anything).Return([]models.FeatureDependency(nil), nil)
+	return dependencies
 }
 
 func TestSmartFeatureService_Delete(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
+
+	testID := uuid.New()
+	dependencies := noDependencies(service)
 
-	mockRepo.On("Delete", mock.Anything, "test-id").Return(nil)
+	mockRepo.On("GetByID", mock.Anything, testID.String()).Return(&models.SmartFeature{ID: testID, ModelID: uuid.New()}, nil)
+	mockRepo.On("Delete", mock.Anything, testID.String()).Return(nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.FeatureDeletedEvent)).Return(nil)
 
-	err := service.Delete(context.Background(), "test-id")
+	err := service.Delete(context.Background(), testID.String())
 
 	assert.NoError(t, err)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+	dependencies.AssertExpectations(t)
 }
 
 func TestSmartFeatureService_Delete_Error(t *testing.T) {
 	mockRepo := new(mockSmartFeatureRepo)
-	service := NewSmartFeatureService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
 
-	mockRepo.On("Delete", mock.Anything, "test-id").Return(assert.AnError)
+	testID := uuid.New()
+	noDependencies(service)
 
-	err := service.Delete(context.Background(), "test-id")
+	mockRepo.On("GetByID", mock.Anything, testID.String()).Return(&models.SmartFeature{ID: testID, ModelID: uuid.New()}, nil)
+	mockRepo.On("Delete", mock.Anything, testID.String()).Return(assert.AnError)
+
+	err := service.Delete(context.Background(), testID.String())
 
 	assert.Error(t, err)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_BatchCreate(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	mockModels := new(mockSmartModelRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, mockModels, &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
+
+	model := &models.SmartModel{ID: uuid.New()}
+	features := []*models.SmartFeature{
+		{ID: uuid.New(), ModelID: model.ID, Name: "Power"},
+		{ID: uuid.New(), ModelID: model.ID, Name: "Status"},
+	}
+
+	mockModels.On("GetByIDs", mock.Anything, []string{model.ID.String(), model.ID.String()}).Return([]*models.SmartModel{model}, nil)
+	mockRepo.On("GetWithModelIDs", mock.Anything, []string{model.ID.String()}).Return([]*models.SmartFeature{}, nil)
+	mockRepo.On("CreateBatch", mock.Anything, features).Return(features, nil)
+	mockOutbox.On("Add", mock.Anything, mock.MatchedBy(func(events []*models.DomainEvent) bool {
+		return len(events) == 2 && events[0].Type == models.FeatureCreatedEvent && events[1].AggregateID == features[1].ID
+	})).Return(nil)
+
+	created, itemErrs, err := service.BatchCreate(context.Background(), features, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, features, created)
+	assert.Equal(t, []error{nil, nil}, itemErrs)
+
+	mockRepo.AssertExpectations(t)
+	mockModels.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_BatchCreate_MissingModel(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	mockModels := new(mockSmartModelRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, mockModels, &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
+
+	model := &models.SmartModel{ID: uuid.New()}
+	features := []*models.SmartFeature{
+		{ID: uuid.New(), ModelID: model.ID, Name: "Power"},
+		{ID: uuid.New(), ModelID: uuid.New(), Name: "Orphan"},
+	}
+	mockModels.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartModel{model}, nil)
+	mockRepo.On("GetWithModelIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{}, nil)
+
+	created, _, err := service.BatchCreate(context.Background(), features, false)
+
+	var itemErr *models.BatchItemError
+	assert.ErrorAs(t, err, &itemErr)
+	assert.Equal(t, 1, itemErr.Index)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.Nil(t, created)
+
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_BatchCreate_Partial(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	mockModels := new(mockSmartModelRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, mockModels, &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
+
+	model := &models.SmartModel{ID: uuid.New()}
+	features := []*models.SmartFeature{
+		{ID: uuid.New(), ModelID: uuid.New(), Name: "Orphan"},
+		{ID: uuid.New(), ModelID: model.ID, Name: "Power"},
+	}
+	mockModels.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartModel{model}, nil)
+	mockRepo.On("GetWithModelIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{}, nil)
+	mockRepo.On("CreateBatch", mock.Anything, features[1:]).Return(features[1:], nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.FeatureCreatedEvent)).Return(nil)
+
+	created, itemErrs, err := service.BatchCreate(context.Background(), features, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.SmartFeature{nil, features[1]}, created)
+	assert.ErrorIs(t, itemErrs[0], models.ErrNotFound)
+	assert.NoError(t, itemErrs[1])
+
+	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_BatchUpdate_Partial(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
+
+	existing := &models.SmartFeature{ID: uuid.New(), Name: "Power"}
+	missing := &models.SmartFeature{ID: uuid.New(), Name: "Missing"}
+	features := []*models.SmartFeature{existing, missing}
+
+	mockRepo.On("GetByIDs", mock.Anything, []string{existing.ID.String(), missing.ID.String()}).Return([]*models.SmartFeature{existing}, nil)
+	mockRepo.On("GetWithModelIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{existing}, nil)
+	mockRepo.On("UpdateBatch", mock.Anything, []*models.SmartFeature{existing}).Return([]*models.SmartFeature{existing}, nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.FeatureUpdatedEvent)).Return(nil)
+
+	updated, itemErrs, err := service.BatchUpdate(context.Background(), features, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.SmartFeature{existing, nil}, updated)
+	assert.NoError(t, itemErrs[0])
+	assert.ErrorIs(t, itemErrs[1], models.ErrNotFound)
+
+	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_BatchUpdate_Error(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
+
+	feature := &models.SmartFeature{ID: uuid.New(), Name: "Power"}
+	mockRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{feature}, nil)
+	mockRepo.On("GetWithModelIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{feature}, nil)
+	mockRepo.On("UpdateBatch", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	updated, _, err := service.BatchUpdate(context.Background(), []*models.SmartFeature{feature}, false)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Nil(t, updated)
+
+	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_BatchCreate_PartialNamePathConflict(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	mockModels := new(mockSmartModelRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, mockModels, &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
+
+	model := &models.SmartModel{ID: uuid.New()}
+	stored := &models.SmartFeature{ID: uuid.New(), ModelID: model.ID, Name: "Power", InterfacePath: "/power"}
+	features := []*models.SmartFeature{
+		{ID: uuid.New(), ModelID: model.ID, Name: "power", InterfacePath: "/POWER"},
+		{ID: uuid.New(), ModelID: model.ID, Name: "Power", InterfacePath: "/power/v2"},
+		{ID: uuid.New(), ModelID: model.ID, Name: "Status", InterfacePath: "/status"},
+		{ID: uuid.New(), ModelID: model.ID, Name: "STATUS", InterfacePath: "/status"},
+	}
+	mockModels.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartModel{model}, nil)
+	mockRepo.On("GetWithModelIDs", mock.Anything, []string{model.ID.String()}).Return([]*models.SmartFeature{stored}, nil)
+	mockRepo.On("CreateBatch", mock.Anything, []*models.SmartFeature{features[1], features[2]}).
+		Return([]*models.SmartFeature{features[1], features[2]}, nil)
+	mockOutbox.On("Add", mock.Anything, mock.AnythingOfType("[]*models.DomainEvent")).Return(nil)
+
+	created, itemErrs, err := service.BatchCreate(context.Background(), features, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.SmartFeature{nil, features[1], features[2], nil}, created)
+	assert.Equal(t, &models.AlreadyExistsError{Entity: models.SmartFeatureAggregate, ID: stored.ID}, itemErrs[0])
+	assert.NoError(t, itemErrs[1], "the same name under another interface path is a different feature")
+	assert.NoError(t, itemErrs[2])
+	assert.Equal(t, &models.AlreadyExistsError{Entity: models.SmartFeatureAggregate, ID: features[2].ID}, itemErrs[3],
+		"an item conflicting with an earlier item of the batch fails alone")
+
+	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_BatchCreate_NamePathConflict(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	mockModels := new(mockSmartModelRepo)
+	service := NewSmartFeatureService(mockRepo, mockModels, &fakeUnitOfWork{}, new(mockOutboxRepo), new(mockFeatureDependencyRepo))
+
+	model := &models.SmartModel{ID: uuid.New()}
+	stored := &models.SmartFeature{ID: uuid.New(), ModelID: model.ID, Name: "Power", InterfacePath: "/power"}
+	features := []*models.SmartFeature{
+		{ID: uuid.New(), ModelID: model.ID, Name: "Status", InterfacePath: "/status"},
+		{ID: uuid.New(), ModelID: model.ID, Name: "Power", InterfacePath: "/power"},
+	}
+	mockModels.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartModel{model}, nil)
+	mockRepo.On("GetWithModelIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{stored}, nil)
+
+	_, _, err := service.BatchCreate(context.Background(), features, false)
+
+	var itemErr *models.BatchItemError
+	assert.ErrorAs(t, err, &itemErr)
+	assert.Equal(t, 1, itemErr.Index)
+	assert.ErrorIs(t, err, models.ErrAlreadyExists)
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestSmartFeatureService_BatchCreate_RepositoryItemErrorIndex(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	mockModels := new(mockSmartModelRepo)
+	service := NewSmartFeatureService(mockRepo, mockModels, &fakeUnitOfWork{}, new(mockOutboxRepo), new(mockFeatureDependencyRepo))
+
+	model := &models.SmartModel{ID: uuid.New()}
+	features := []*models.SmartFeature{
+		{ID: uuid.New(), ModelID: uuid.New(), Name: "Orphan"},
+		{ID: uuid.New(), ModelID: model.ID, Name: "Power", InterfacePath: "/power"},
+	}
+	// A feature committed concurrently is only caught by the repository,
+	// which counts the items it was given.
+	conflict := &models.AlreadyExistsError{Entity: models.SmartFeatureAggregate, ID: uuid.New()}
+	mockModels.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartModel{model}, nil)
+	mockRepo.On("GetWithModelIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{}, nil)
+	mockRepo.On("CreateBatch", mock.Anything, features[1:]).Return(nil, &models.BatchItemError{Index: 0, Err: conflict})
+
+	_, _, err := service.BatchCreate(context.Background(), features, true)
+
+	var itemErr *models.BatchItemError
+	assert.ErrorAs(t, err, &itemErr)
+	assert.Equal(t, 1, itemErr.Index, "the index is that of the request")
+	assert.ErrorIs(t, err, models.ErrAlreadyExists)
+}
+
+func TestSmartFeatureService_BatchUpdate_PartialNamePathConflict(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
+
+	modelID := uuid.New()
+	power := &models.SmartFeature{ID: uuid.New(), ModelID: modelID, Name: "Power", InterfacePath: "/power"}
+	status := &models.SmartFeature{ID: uuid.New(), ModelID: modelID, Name: "Status", InterfacePath: "/status"}
+	reboot := &models.SmartFeature{ID: uuid.New(), ModelID: modelID, Name: "Reboot", InterfacePath: "/reboot"}
+	features := []*models.SmartFeature{
+		// Renamed onto the key of status, which keeps it.
+		{ID: power.ID, Name: "Status", InterfacePath: "/status"},
+		// Renamed away from its key, which reboot then takes.
+		{ID: status.ID, Name: "State", InterfacePath: "/state"},
+		{ID: reboot.ID, Name: "status", InterfacePath: "/status"},
+	}
+
+	mockRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{power, status, reboot}, nil)
+	mockRepo.On("GetWithModelIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{power, status, reboot}, nil)
+	mockRepo.On("UpdateBatch", mock.Anything, features[1:]).Return(features[1:], nil)
+	mockOutbox.On("Add", mock.Anything, mock.AnythingOfType("[]*models.DomainEvent")).Return(nil)
+
+	updated, itemErrs, err := service.BatchUpdate(context.Background(), features, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.SmartFeature{nil, features[1], features[2]}, updated)
+	assert.Equal(t, &models.AlreadyExistsError{Entity: models.SmartFeatureAggregate, ID: status.ID}, itemErrs[0])
+	assert.NoError(t, itemErrs[1])
+	assert.NoError(t, itemErrs[2])
+
+	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_BatchDelete(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
+
+	first, second := uuid.New(), uuid.New()
+	ids := []string{first.String(), second.String(), first.String()}
+
+	noDependencies(service)
+	mockRepo.On("GetByIDs", mock.Anything, ids).Return([]*models.SmartFeature{{ID: first}, {ID: second}}, nil)
+	mockRepo.On("DeleteBatch", mock.Anything, ids).Return(nil)
+	mockOutbox.On("Add", mock.Anything, mock.MatchedBy(func(events []*models.DomainEvent) bool {
+		return len(events) == 2 && events[0].Type == models.FeatureDeletedEvent
+	})).Return(nil)
+
+	itemErrs, err := service.BatchDelete(context.Background(), ids, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []error{nil, nil, nil}, itemErrs)
+
+	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_BatchDelete_MissingFeature(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, mockOutbox, new(mockFeatureDependencyRepo))
+
+	existing := uuid.New()
+	ids := []string{existing.String(), uuid.NewString()}
+	noDependencies(service)
+	mockRepo.On("GetByIDs", mock.Anything, ids).Return([]*models.SmartFeature{{ID: existing}}, nil)
+
+	_, err := service.BatchDelete(context.Background(), ids, false)
+
+	var itemErr *models.BatchItemError
+	assert.ErrorAs(t, err, &itemErr)
+	assert.Equal(t, 1, itemErr.Index)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	mockRepo.AssertNotCalled(t, "DeleteBatch", mock.Anything, mock.Anything)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_List(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, new(mockOutboxRepo), new(mockFeatureDependencyRepo))
+
+	now := time.Now()
+	features := []*models.SmartFeature{
+		{ID: uuid.New(), Name: "Alpha", CreatedAt: now},
+		{ID: uuid.New(), Name: "Beta", CreatedAt: now.Add(time.Second)},
+		{ID: uuid.New(), Name: "Gamma", CreatedAt: now.Add(2 * time.Second)},
+	}
+	protocol := models.MqttProtocol
+	filter := models.FeatureFilter{Protocol: &protocol}
+
+	mockRepo.On("List", mock.Anything, filter, (*models.PageCursor)(nil), 3).Return(features, nil)
+
+	page, next, err := service.List(context.Background(), filter, 2, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, features[:2], page)
+	assert.NotEmpty(t, next)
+
+	cursor, err := models.ParsePageToken(next)
+	assert.NoError(t, err)
+	assert.Equal(t, features[1].ID, cursor.ID)
+	assert.True(t, features[1].CreatedAt.Equal(cursor.CreatedAt))
+
+	mockRepo.On("List", mock.Anything, filter, cursor, 3).Return(features[2:], nil)
+
+	page, next, err = service.List(context.Background(), filter, 2, next)
+
+	assert.NoError(t, err)
+	assert.Equal(t, features[2:], page)
+	assert.Empty(t, next)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSmartFeatureService_List_InvalidToken(t *testing.T) {
+	mockRepo := new(mockSmartFeatureRepo)
+	service := NewSmartFeatureService(mockRepo, new(mockSmartModelRepo), &fakeUnitOfWork{}, new(mockOutboxRepo), new(mockFeatureDependencyRepo))
+
+	_, _, err := service.List(context.Background(), models.FeatureFilter{}, 10, "not a token")
+
+	assert.ErrorIs(t, err, models.ErrInvalidPageToken)
+	mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
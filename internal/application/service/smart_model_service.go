@@ -2,47 +2,265 @@ package service
 
 import (
 	"context"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
 	"smart-hub/internal/domain/interfaces"
 	"smart-hub/internal/domain/models"
 )
 
 type SmartModelService struct {
-	repo interfaces.SmartModelRepository
+	repo        interfaces.SmartModelRepository
+	featureRepo interfaces.SmartFeatureRepository
+	uow         interfaces.UnitOfWork
+	outbox      interfaces.OutboxRepository
 }
 
-func NewSmartModelService(repo interfaces.SmartModelRepository) *SmartModelService {
+func NewSmartModelService(
+	repo interfaces.SmartModelRepository,
+	featureRepo interfaces.SmartFeatureRepository,
+	uow interfaces.UnitOfWork,
+	outbox interfaces.OutboxRepository,
+) *SmartModelService {
 	return &SmartModelService{
-		repo: repo,
+		repo:        repo,
+		featureRepo: featureRepo,
+		uow:         uow,
+		outbox:      outbox,
 	}
 }
 
+// recordModelEvent writes an event for model to the outbox. It must be called
+// inside the unit of work that changed the model.
+func (s *SmartModelService) recordModelEvent(ctx context.Context, eventType models.EventType, id uuid.UUID, payload interface{}) error {
+	event, err := models.NewDomainEvent(models.SmartModelAggregate, id, eventType, payload)
+	if err != nil {
+		return err
+	}
+	return s.outbox.Add(ctx, event)
+}
+
 func (s *SmartModelService) Create(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error) {
-	logger.Debug("Create smart model", "model", model)
-	return s.repo.Create(ctx, model)
+	ctx, span := tracing.StartSpan(ctx, "SmartModelService.Create")
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Create smart model", "model", model)
+
+	var createdModel *models.SmartModel
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		createdModel, err = s.repo.Create(ctx, model)
+		if err != nil {
+			return err
+		}
+		if err := s.recordModelEvent(ctx, models.ModelCreatedEvent, createdModel.ID, createdModel); err != nil {
+			return err
+		}
+		if len(model.Features) == 0 {
+			return nil
+		}
+
+		createdModel.Features, err = s.createFeatures(ctx, createdModel.ID, model.Features)
+		return err
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return createdModel, nil
+}
+
+// createFeatures creates the features of a new model. It must be called
+// inside the unit of work that created the model.
+func (s *SmartModelService) createFeatures(ctx context.Context, modelID uuid.UUID, features []*models.SmartFeature) ([]*models.SmartFeature, error) {
+	for _, feature := range features {
+		feature.ModelID = modelID
+	}
+
+	created, err := s.featureRepo.CreateBatch(ctx, features)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*models.DomainEvent, len(created))
+	for i, feature := range created {
+		events[i], err = models.NewDomainEvent(models.SmartFeatureAggregate, feature.ID, models.FeatureCreatedEvent, feature)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.outbox.Add(ctx, events...); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
 func (s *SmartModelService) GetByID(ctx context.Context, id string) (*models.SmartModel, error) {
-	logger.Debug("Get smart model by ID", "id", id)
-	return s.repo.GetByID(ctx, id)
+	ctx, span := tracing.StartSpan(ctx, "SmartModelService.GetByID", attribute.String("model.id", id))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get smart model by ID", "id", id)
+	model, err := s.repo.GetByID(ctx, id)
+	tracing.RecordError(span, err)
+	return model, err
+}
+
+func (s *SmartModelService) GetByModelNumber(ctx context.Context, manufacturer, modelNumber string) (*models.SmartModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "SmartModelService.GetByModelNumber",
+		attribute.String("model.manufacturer", manufacturer), attribute.String("model.model_number", modelNumber))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get smart model by model number", "manufacturer", manufacturer, "modelNumber", modelNumber)
+	model, err := s.repo.GetByModelNumber(ctx, manufacturer, modelNumber)
+	tracing.RecordError(span, err)
+	return model, err
+}
+
+// ExpandFeatures fills in the Features of smartModels. All features are
+// loaded with a single query, however many models there are.
+func (s *SmartModelService) ExpandFeatures(ctx context.Context, smartModels []*models.SmartModel) error {
+	ctx, span := tracing.StartSpan(ctx, "SmartModelService.ExpandFeatures", attribute.Int("model.count", len(smartModels)))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Expand smart model features", "count", len(smartModels))
+	if len(smartModels) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(smartModels))
+	byID := make(map[uuid.UUID]*models.SmartModel, len(smartModels))
+	for i, model := range smartModels {
+		ids[i] = model.ID.String()
+		model.Features = []*models.SmartFeature{}
+		byID[model.ID] = model
+	}
+
+	features, err := s.featureRepo.GetWithModelIDs(ctx, ids)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	for _, feature := range features {
+		if model, ok := byID[feature.ModelID]; ok {
+			model.Features = append(model.Features, feature)
+		}
+	}
+	return nil
 }
 
 func (s *SmartModelService) GetWithType(ctx context.Context, modelType models.ModelType) ([]*models.SmartModel, error) {
-	logger.Debug("Get smart models by type", "type", modelType)
-	return s.repo.GetWithType(ctx, modelType)
+	ctx, span := tracing.StartSpan(ctx, "SmartModelService.GetWithType", attribute.String("model.type", string(modelType)))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get smart models by type", "type", modelType)
+	smartModels, err := s.repo.GetWithType(ctx, modelType)
+	tracing.RecordError(span, err)
+	return smartModels, err
 }
 
 func (s *SmartModelService) GetAll(ctx context.Context) ([]*models.SmartModel, error) {
-	logger.Debug("Get all smart models")
-	return s.repo.GetAll(ctx)
+	ctx, span := tracing.StartSpan(ctx, "SmartModelService.GetAll")
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get all smart models")
+	smartModels, err := s.repo.GetAll(ctx)
+	tracing.RecordError(span, err)
+	return smartModels, err
 }
 
 func (s *SmartModelService) Update(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error) {
-	logger.Debug("Update smart model", "model", model)
-	return s.repo.Update(ctx, model)
+	ctx, span := tracing.StartSpan(ctx, "SmartModelService.Update", attribute.String("model.id", model.ID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Update smart model", "model", model)
+
+	var updatedModel *models.SmartModel
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		updatedModel, err = s.repo.Update(ctx, model)
+		if err != nil {
+			return err
+		}
+		return s.recordModelEvent(ctx, models.ModelUpdatedEvent, updatedModel.ID, updatedModel)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return updatedModel, nil
 }
 
 func (s *SmartModelService) Delete(ctx context.Context, id string) error {
-	logger.Debug("Delete smart model", "id", id)
-	return s.repo.Delete(ctx, id)
+	ctx, span := tracing.StartSpan(ctx, "SmartModelService.Delete", attribute.String("model.id", id))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Delete smart model", "id", id)
+
+	modelID, err := uuid.Parse(id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.recordModelEvent(ctx, models.ModelDeletedEvent, modelID, map[string]string{"id": id})
+	})
+	tracing.RecordError(span, err)
+	return err
+}
+
+// BatchGet returns the models with ids, in the order of ids. A missing model
+// fails the whole call with a *models.BatchItemError unless partial is set;
+// then it is reported with models.ErrNotFound in the per-item errors, which
+// line up with ids.
+func (s *SmartModelService) BatchGet(ctx context.Context, ids []string, partial bool) ([]*models.SmartModel, []error, error) {
+	ctx, span := tracing.StartSpan(ctx, "SmartModelService.BatchGet",
+		attribute.Int("batch.size", len(ids)), attribute.Bool("batch.partial", partial))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Batch get smart models", "count", len(ids), "partial", partial)
+
+	modelIDs := make([]uuid.UUID, len(ids))
+	for i, id := range ids {
+		modelID, err := uuid.Parse(id)
+		if err != nil {
+			err = &models.BatchItemError{Index: i, Err: err}
+			tracing.RecordError(span, err)
+			return nil, nil, err
+		}
+		modelIDs[i] = modelID
+	}
+
+	found, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, nil, err
+	}
+	byID := make(map[uuid.UUID]*models.SmartModel, len(found))
+	for _, model := range found {
+		byID[model.ID] = model
+	}
+
+	results := make([]*models.SmartModel, len(ids))
+	pending, itemErrs, err := splitBatch(len(ids), partial, func(i int) error {
+		if byID[modelIDs[i]] == nil {
+			return models.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, nil, err
+	}
+	for _, i := range pending {
+		results[i] = byID[modelIDs[i]]
+	}
+
+	return results, itemErrs, nil
 }
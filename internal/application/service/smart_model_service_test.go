@@ -30,6 +30,22 @@ func (m *mockSmartModelRepo) GetByID(ctx context.Context, id string) (*models.Sm
 	return args.Get(0).(*models.SmartModel), args.Error(1)
 }
 
+func (m *mockSmartModelRepo) GetByIDs(ctx context.Context, ids []string) ([]*models.SmartModel, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SmartModel), args.Error(1)
+}
+
+func (m *mockSmartModelRepo) GetByModelNumber(ctx context.Context, manufacturer, modelNumber string) (*models.SmartModel, error) {
+	args := m.Called(ctx, manufacturer, modelNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SmartModel), args.Error(1)
+}
+
 func (m *mockSmartModelRepo) GetWithType(ctx context.Context, modelType models.ModelType) ([]*models.SmartModel, error) {
 	args := m.Called(ctx, modelType)
 	if args.Get(0) == nil {
@@ -61,7 +77,8 @@ func (m *mockSmartModelRepo) Delete(ctx context.Context, id string) error {
 
 func TestSmartModelService_Create(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	now := time.Now()
 	testModel := &models.SmartModel{
@@ -75,6 +92,7 @@ func TestSmartModelService_Create(t *testing.T) {
 	}
 
 	mockRepo.On("Create", mock.Anything, testModel).Return(testModel, nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.ModelCreatedEvent)).Return(nil)
 
 	result, err := service.Create(context.Background(), testModel)
 
@@ -84,11 +102,67 @@ func TestSmartModelService_Create(t *testing.T) {
 	assert.Equal(t, testModel.Description, result.Description)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartModelService_Create_WithFeatures(t *testing.T) {
+	mockRepo := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	features := []*models.SmartFeature{
+		{ID: uuid.New(), Name: "Power"},
+		{ID: uuid.New(), Name: "Status"},
+	}
+	testModel := &models.SmartModel{ID: uuid.New(), Name: "Test Device", Features: features}
+	stored := &models.SmartModel{ID: testModel.ID, Name: testModel.Name}
+
+	mockRepo.On("Create", mock.Anything, testModel).Return(stored, nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.ModelCreatedEvent)).Return(nil)
+	mockFeatures.On("CreateBatch", mock.Anything, mock.MatchedBy(func(batch []*models.SmartFeature) bool {
+		return len(batch) == 2 && batch[0].ModelID == testModel.ID && batch[1].ModelID == testModel.ID
+	})).Return(features, nil)
+	mockOutbox.On("Add", mock.Anything, mock.MatchedBy(func(events []*models.DomainEvent) bool {
+		return len(events) == 2 && events[0].Type == models.FeatureCreatedEvent && events[1].AggregateID == features[1].ID
+	})).Return(nil)
+
+	result, err := service.Create(context.Background(), testModel)
+
+	assert.NoError(t, err)
+	assert.Equal(t, features, result.Features)
+
+	mockRepo.AssertExpectations(t)
+	mockFeatures.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartModelService_Create_FeatureError(t *testing.T) {
+	mockRepo := new(mockSmartModelRepo)
+	mockFeatures := new(mockSmartFeatureRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, mockFeatures, &fakeUnitOfWork{}, mockOutbox)
+
+	testModel := &models.SmartModel{ID: uuid.New(), Name: "Test Device", Features: []*models.SmartFeature{{ID: uuid.New(), Name: "Power"}}}
+
+	mockRepo.On("Create", mock.Anything, testModel).Return(&models.SmartModel{ID: testModel.ID}, nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.ModelCreatedEvent)).Return(nil)
+	mockFeatures.On("CreateBatch", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	result, err := service.Create(context.Background(), testModel)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Nil(t, result)
+
+	mockRepo.AssertExpectations(t)
+	mockFeatures.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartModelService_Create_Error(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	now := time.Now()
 	testModel := &models.SmartModel{
@@ -109,11 +183,13 @@ func TestSmartModelService_Create_Error(t *testing.T) {
 	assert.Nil(t, result)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartModelService_GetByID(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	now := time.Now()
 	testModel := &models.SmartModel{
@@ -136,11 +212,13 @@ func TestSmartModelService_GetByID(t *testing.T) {
 	assert.Equal(t, testModel.Description, result.Description)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartModelService_GetByID_Error(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	now := time.Now()
 	testModel := &models.SmartModel{
@@ -161,11 +239,13 @@ func TestSmartModelService_GetByID_Error(t *testing.T) {
 	assert.Nil(t, result)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartModelService_GetWithType(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	now := time.Now()
 	testModel := &models.SmartModel{
@@ -188,11 +268,13 @@ func TestSmartModelService_GetWithType(t *testing.T) {
 	assert.Equal(t, testModel.Description, result[0].Description)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartModelService_GetWithType_Error(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	mockRepo.On("GetWithType", mock.Anything, models.DeviceType).Return(nil, assert.AnError)
 
@@ -202,11 +284,13 @@ func TestSmartModelService_GetWithType_Error(t *testing.T) {
 	assert.Nil(t, result)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartModelService_GetAll(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	now := time.Now()
 	testModel := &models.SmartModel{
@@ -229,11 +313,13 @@ func TestSmartModelService_GetAll(t *testing.T) {
 	assert.Equal(t, testModel.Description, result[0].Description)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartModelService_GetAll_Error(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	mockRepo.On("GetAll", mock.Anything).Return(nil, assert.AnError)
 
@@ -243,11 +329,13 @@ func TestSmartModelService_GetAll_Error(t *testing.T) {
 	assert.Nil(t, result)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartModelService_Update(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	now := time.Now()
 	testModel := &models.SmartModel{
@@ -261,6 +349,7 @@ func TestSmartModelService_Update(t *testing.T) {
 	}
 
 	mockRepo.On("Update", mock.Anything, testModel).Return(testModel, nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.ModelUpdatedEvent)).Return(nil)
 
 	result, err := service.Update(context.Background(), testModel)
 
@@ -270,11 +359,13 @@ func TestSmartModelService_Update(t *testing.T) {
 	assert.Equal(t, testModel.Description, result.Description)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartModelService_Update_Error(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	now := time.Now()
 	testModel := &models.SmartModel{
@@ -295,26 +386,31 @@ func TestSmartModelService_Update_Error(t *testing.T) {
 	assert.Nil(t, result)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartModelService_Delete(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	testID := uuid.New()
 
 	mockRepo.On("Delete", mock.Anything, testID.String()).Return(nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.ModelDeletedEvent)).Return(nil)
 
 	err := service.Delete(context.Background(), testID.String())
 
 	assert.NoError(t, err)
 
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestSmartModelService_Delete_Error(t *testing.T) {
 	mockRepo := new(mockSmartModelRepo)
-	service := NewSmartModelService(mockRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
 
 	testID := uuid.New()
 
@@ -324,5 +420,117 @@ func TestSmartModelService_Delete_Error(t *testing.T) {
 
 	assert.Error(t, err)
 
+	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartModelService_Create_OutboxError(t *testing.T) {
+	mockRepo := new(mockSmartModelRepo)
+	mockOutbox := new(mockOutboxRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, mockOutbox)
+
+	now := time.Now()
+	testModel := &models.SmartModel{
+		ID:          uuid.New(),
+		Name:        "Test Device",
+		Description: "Test Description",
+		Type:        models.DeviceType,
+		Category:    models.WearableCategory,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	mockRepo.On("Create", mock.Anything, testModel).Return(testModel, nil)
+	mockOutbox.On("Add", mock.Anything, eventsOfType(models.ModelCreatedEvent)).Return(assert.AnError)
+
+	result, err := service.Create(context.Background(), testModel)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+
+	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestSmartModelService_BatchGet(t *testing.T) {
+	mockRepo := new(mockSmartModelRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, new(mockOutboxRepo))
+
+	first := &models.SmartModel{ID: uuid.New(), Name: "First"}
+	second := &models.SmartModel{ID: uuid.New(), Name: "Second"}
+	ids := []string{second.ID.String(), first.ID.String()}
+
+	mockRepo.On("GetByIDs", mock.Anything, ids).Return([]*models.SmartModel{first, second}, nil)
+
+	result, itemErrs, err := service.BatchGet(context.Background(), ids, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.SmartModel{second, first}, result)
+	assert.Equal(t, []error{nil, nil}, itemErrs)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSmartModelService_BatchGet_Missing(t *testing.T) {
+	mockRepo := new(mockSmartModelRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, new(mockOutboxRepo))
+
+	model := &models.SmartModel{ID: uuid.New(), Name: "First"}
+	ids := []string{model.ID.String(), uuid.NewString()}
+	mockRepo.On("GetByIDs", mock.Anything, ids).Return([]*models.SmartModel{model}, nil)
+
+	result, _, err := service.BatchGet(context.Background(), ids, false)
+
+	var itemErr *models.BatchItemError
+	assert.ErrorAs(t, err, &itemErr)
+	assert.Equal(t, 1, itemErr.Index)
+	assert.Nil(t, result)
+
+	result, itemErrs, err := service.BatchGet(context.Background(), ids, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.SmartModel{model, nil}, result)
+	assert.NoError(t, itemErrs[0])
+	assert.ErrorIs(t, itemErrs[1], models.ErrNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSmartModelService_ExpandFeatures(t *testing.T) {
+	mockFeatures := new(mockSmartFeatureRepo)
+	service := NewSmartModelService(new(mockSmartModelRepo), mockFeatures, &fakeUnitOfWork{}, new(mockOutboxRepo))
+
+	camera := &models.SmartModel{ID: uuid.New(), Name: "Camera"}
+	watch := &models.SmartModel{ID: uuid.New(), Name: "Watch"}
+	stream := &models.SmartFeature{ID: uuid.New(), ModelID: camera.ID, Name: "Stream"}
+	zoom := &models.SmartFeature{ID: uuid.New(), ModelID: camera.ID, Name: "Zoom"}
+
+	mockFeatures.On("GetWithModelIDs", mock.Anything, []string{camera.ID.String(), watch.ID.String()}).
+		Return([]*models.SmartFeature{stream, zoom}, nil).Once()
+
+	err := service.ExpandFeatures(context.Background(), []*models.SmartModel{camera, watch})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.SmartFeature{stream, zoom}, camera.Features)
+	assert.NotNil(t, watch.Features)
+	assert.Empty(t, watch.Features)
+	mockFeatures.AssertExpectations(t)
+}
+
+func TestSmartModelService_GetByModelNumber(t *testing.T) {
+	mockRepo := new(mockSmartModelRepo)
+	service := NewSmartModelService(mockRepo, new(mockSmartFeatureRepo), &fakeUnitOfWork{}, new(mockOutboxRepo))
+
+	model := &models.SmartModel{ID: uuid.New(), Name: "Thermostat", Manufacturer: "Acme", ModelNumber: "TH200"}
+	mockRepo.On("GetByModelNumber", mock.Anything, "acme", "th200").Return(model, nil)
+	mockRepo.On("GetByModelNumber", mock.Anything, "Acme", "MISSING").Return(nil, models.ErrNotFound)
+
+	result, err := service.GetByModelNumber(context.Background(), "acme", "th200")
+	assert.NoError(t, err)
+	assert.Equal(t, model, result)
+
+	_, err = service.GetByModelNumber(context.Background(), "Acme", "MISSING")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
 	mockRepo.AssertExpectations(t)
 }
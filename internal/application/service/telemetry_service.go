@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"io"
+	"math"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+const (
+	maxDeviceIDLength  = 255
+	maxReadingSkew     = 24 * time.Hour
+	minRetention       = time.Hour
+	minTelemetryBucket = time.Second
+)
+
+// TelemetryService ingests and queries feature readings and enforces their
+// retention. A feature's readings are kept for its retention policy, or the
+// default retention when it has none.
+type TelemetryService struct {
+	repo             interfaces.TelemetryRepository
+	featureRepo      interfaces.SmartFeatureRepository
+	defaultRetention time.Duration
+	maxPoints        int
+	batchSize        int
+	now              func() time.Time
+	observers        []interfaces.ReadingObserver
+}
+
+func NewTelemetryService(
+	repo interfaces.TelemetryRepository,
+	featureRepo interfaces.SmartFeatureRepository,
+	defaultRetention time.Duration,
+	maxPoints int,
+	batchSize int,
+) *TelemetryService {
+	return &TelemetryService{
+		repo:             repo,
+		featureRepo:      featureRepo,
+		defaultRetention: defaultRetention,
+		maxPoints:        maxPoints,
+		batchSize:        batchSize,
+		now:              time.Now,
+	}
+}
+
+// AddObserver registers an observer of every stored batch of readings. It
+// must be called before the service is used.
+func (s *TelemetryService) AddObserver(observer interfaces.ReadingObserver) {
+	s.observers = append(s.observers, observer)
+}
+
+// Ingest calls next until it returns io.EOF and stores the valid readings in
+// batches. Invalid readings are counted in the report and skipped. Any other
+// error from next ends the ingestion; readings of earlier batches stay
+// stored.
+func (s *TelemetryService) Ingest(ctx context.Context, next func() ([]*models.TelemetryReading, error)) (*models.IngestReport, error) {
+	ctx, span := tracing.StartSpan(ctx, "TelemetryService.Ingest")
+	defer span.End()
+
+	report := &models.IngestReport{}
+	retention, err := s.retentions(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	ingest := &ingestion{service: s, report: report, retention: retention, known: make(map[uuid.UUID]bool)}
+
+	for {
+		readings, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		if err := ingest.add(ctx, readings); err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+	}
+	if err := ingest.flush(ctx); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int64("telemetry.received", report.Received),
+		attribute.Int64("telemetry.stored", report.Stored),
+		attribute.Int64("telemetry.rejected", report.Rejected),
+	)
+	logger.FromContext(ctx).Debug("Ingested readings",
+		"received", report.Received, "stored", report.Stored,
+		"duplicates", report.Duplicates, "rejected", report.Rejected)
+	return report, nil
+}
+
+// ingestion is the state of one Ingest call. known caches whether a feature
+// exists, so each feature is looked up once per stream.
+type ingestion struct {
+	service   *TelemetryService
+	report    *models.IngestReport
+	retention func(featureID uuid.UUID) time.Duration
+	known     map[uuid.UUID]bool
+	pending   []*models.TelemetryReading
+}
+
+func (in *ingestion) add(ctx context.Context, readings []*models.TelemetryReading) error {
+	if err := in.lookUpFeatures(ctx, readings); err != nil {
+		return err
+	}
+
+	now := in.service.now()
+	for _, reading := range readings {
+		index := in.report.Received
+		in.report.Received++
+		if reason := in.validate(reading, now); reason != "" {
+			in.report.Reject(index, reason)
+			continue
+		}
+
+		stored := *reading
+		stored.Timestamp = reading.Timestamp.UTC().Truncate(time.Microsecond)
+		in.pending = append(in.pending, &stored)
+		if len(in.pending) >= in.service.batchSize {
+			if err := in.flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lookUpFeatures fetches the features of readings that are not known yet.
+func (in *ingestion) lookUpFeatures(ctx context.Context, readings []*models.TelemetryReading) error {
+	var ids []string
+	for _, reading := range readings {
+		if _, ok := in.known[reading.FeatureID]; ok || reading.FeatureID == uuid.Nil {
+			continue
+		}
+		in.known[reading.FeatureID] = false
+		ids = append(ids, reading.FeatureID.String())
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	features, err := in.service.featureRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, feature := range features {
+		in.known[feature.ID] = true
+	}
+	return nil
+}
+
+func (in *ingestion) validate(reading *models.TelemetryReading, now time.Time) string {
+	switch {
+	case reading.DeviceID == "":
+		return "device_id is required"
+	case len(reading.DeviceID) > maxDeviceIDLength:
+		return fmt.Sprintf("device_id is longer than %d characters", maxDeviceIDLength)
+	case reading.FeatureID == uuid.Nil:
+		return "feature_id must be a UUID of a smart feature"
+	case !in.known[reading.FeatureID]:
+		return fmt.Sprintf("smart feature %s not found", reading.FeatureID)
+	case reading.Timestamp.IsZero():
+		return "timestamp is required"
+	case reading.Timestamp.Before(now.Add(-in.retention(reading.FeatureID))):
+		return "timestamp is older than the feature's retention"
+	case reading.Timestamp.After(now.Add(maxReadingSkew)):
+		return "timestamp is too far in the future"
+	case math.IsNaN(reading.Value) || math.IsInf(reading.Value, 0):
+		return "value must be a finite number"
+	}
+	return ""
+}
+
+func (in *ingestion) flush(ctx context.Context) error {
+	if len(in.pending) == 0 {
+		return nil
+	}
+	stored, err := in.service.repo.AddReadings(ctx, in.pending)
+	if err != nil {
+		return err
+	}
+	in.report.Stored += int64(stored)
+	in.report.Duplicates += int64(len(in.pending) - stored)
+	for _, observer := range in.service.observers {
+		observer.ObserveReadings(in.pending)
+	}
+	in.pending = nil
+	return nil
+}
+
+// Query returns the readings of query, raw when bucket is zero and
+// downsampled otherwise. The limit defaults to, and is capped at, the
+// maximum number of points.
+func (s *TelemetryService) Query(ctx context.Context, query models.ReadingQuery, bucket time.Duration) (*models.ReadingSeries, error) {
+	ctx, span := tracing.StartSpan(ctx, "TelemetryService.Query",
+		attribute.String("feature.id", query.FeatureID.String()),
+		attribute.String("telemetry.bucket", bucket.String()),
+	)
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Query readings", "feature_id", query.FeatureID, "device_id", query.DeviceID, "from", query.From, "to", query.To, "bucket", bucket)
+
+	if query.To.IsZero() {
+		query.To = s.now()
+	}
+	if err := validateReadingQuery(query, bucket); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if query.Limit <= 0 || query.Limit > s.maxPoints {
+		query.Limit = s.maxPoints
+	}
+	if _, err := s.featureRepo.GetByID(ctx, query.FeatureID.String()); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	// One point more than the limit tells whether the series was cut short.
+	limit := query.Limit
+	query.Limit++
+	series := &models.ReadingSeries{}
+	if bucket == 0 {
+		readings, err := s.repo.ListReadings(ctx, query)
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		series.Truncated = len(readings) > limit
+		series.Readings = readings[:min(len(readings), limit)]
+	} else {
+		buckets, err := s.repo.Downsample(ctx, query, bucket)
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		series.Truncated = len(buckets) > limit
+		series.Buckets = buckets[:min(len(buckets), limit)]
+	}
+	return series, nil
+}
+
+func validateReadingQuery(query models.ReadingQuery, bucket time.Duration) error {
+	switch {
+	case query.FeatureID == uuid.Nil:
+		return fmt.Errorf("%w: feature_id is required", models.ErrInvalidTelemetryQuery)
+	case query.From.IsZero():
+		return fmt.Errorf("%w: start is required", models.ErrInvalidTelemetryQuery)
+	case !query.From.Before(query.To):
+		return fmt.Errorf("%w: start must be before end", models.ErrInvalidTelemetryQuery)
+	case bucket < 0 || (bucket > 0 && bucket < minTelemetryBucket):
+		return fmt.Errorf("%w: bucket must be at least %s", models.ErrInvalidTelemetryQuery, minTelemetryBucket)
+	case bucket%time.Second != 0:
+		return fmt.Errorf("%w: bucket must be a whole number of seconds", models.ErrInvalidTelemetryQuery)
+	}
+	return nil
+}
+
+// SetRetentionPolicy keeps the readings of a feature for retention instead
+// of the default retention.
+func (s *TelemetryService) SetRetentionPolicy(ctx context.Context, featureID uuid.UUID, retention time.Duration) (*models.RetentionPolicy, error) {
+	ctx, span := tracing.StartSpan(ctx, "TelemetryService.SetRetentionPolicy", attribute.String("feature.id", featureID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Set retention policy", "feature_id", featureID, "retention", retention)
+
+	if retention < minRetention {
+		err := fmt.Errorf("%w: retention must be at least %s", models.ErrInvalidTelemetryQuery, minRetention)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	policy, err := s.repo.SetRetentionPolicy(ctx, &models.RetentionPolicy{
+		FeatureID: featureID,
+		Retention: retention,
+		UpdatedAt: s.now(),
+	})
+	tracing.RecordError(span, err)
+	return policy, err
+}
+
+// ListRetentionPolicies returns the policies and the default retention that
+// applies to every other feature.
+func (s *TelemetryService) ListRetentionPolicies(ctx context.Context) ([]*models.RetentionPolicy, time.Duration, error) {
+	ctx, span := tracing.StartSpan(ctx, "TelemetryService.ListRetentionPolicies")
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("List retention policies")
+	policies, err := s.repo.ListRetentionPolicies(ctx)
+	tracing.RecordError(span, err)
+	return policies, s.defaultRetention, err
+}
+
+func (s *TelemetryService) DeleteRetentionPolicy(ctx context.Context, featureID uuid.UUID) error {
+	ctx, span := tracing.StartSpan(ctx, "TelemetryService.DeleteRetentionPolicy", attribute.String("feature.id", featureID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Delete retention policy", "feature_id", featureID)
+	err := s.repo.DeleteRetentionPolicy(ctx, featureID)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// retentions returns the retention of each feature as of now.
+func (s *TelemetryService) retentions(ctx context.Context) (func(featureID uuid.UUID) time.Duration, error) {
+	policies, err := s.repo.ListRetentionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byFeature := make(map[uuid.UUID]time.Duration, len(policies))
+	for _, policy := range policies {
+		byFeature[policy.FeatureID] = policy.Retention
+	}
+	return func(featureID uuid.UUID) time.Duration {
+		if retention, ok := byFeature[featureID]; ok {
+			return retention
+		}
+		return s.defaultRetention
+	}, nil
+}
+
+// ApplyRetention deletes the readings that outlived their retention. Readings
+// past the longest retention go first, which lets the Postgres backend drop
+// whole partitions; features with a shorter retention are trimmed after.
+func (s *TelemetryService) ApplyRetention(ctx context.Context) error {
+	ctx, span := tracing.StartSpan(ctx, "TelemetryService.ApplyRetention")
+	defer span.End()
+
+	policies, err := s.repo.ListRetentionPolicies(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	now := s.now()
+	longest := s.defaultRetention
+	for _, policy := range policies {
+		longest = max(longest, policy.Retention)
+	}
+	if err := s.repo.DeleteReadings(ctx, models.ReadingDeleteFilter{Before: now.Add(-longest)}); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	var withPolicy []uuid.UUID
+	for _, policy := range policies {
+		withPolicy = append(withPolicy, policy.FeatureID)
+		if policy.Retention >= longest {
+			continue
+		}
+		featureID := policy.FeatureID
+		if err := s.repo.DeleteReadings(ctx, models.ReadingDeleteFilter{Before: now.Add(-policy.Retention), FeatureID: &featureID}); err != nil {
+			tracing.RecordError(span, err)
+			return err
+		}
+	}
+
+	if s.defaultRetention < longest {
+		filter := models.ReadingDeleteFilter{Before: now.Add(-s.defaultRetention), ExceptFeatureIDs: withPolicy}
+		if err := s.repo.DeleteReadings(ctx, filter); err != nil {
+			tracing.RecordError(span, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// RunRetention applies the retention every interval until ctx is cancelled.
+func (s *TelemetryService) RunRetention(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ApplyRetention(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Telemetry retention failed", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
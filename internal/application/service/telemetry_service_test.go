@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+type mockTelemetryRepo struct {
+	mock.Mock
+}
+
+func (m *mockTelemetryRepo) AddReadings(ctx context.Context, readings []*models.TelemetryReading) (int, error) {
+	args := m.Called(ctx, readings)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockTelemetryRepo) ListReadings(ctx context.Context, query models.ReadingQuery) ([]*models.TelemetryReading, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TelemetryReading), args.Error(1)
+}
+
+func (m *mockTelemetryRepo) Downsample(ctx context.Context, query models.ReadingQuery, bucket time.Duration) ([]*models.TelemetryBucket, error) {
+	args := m.Called(ctx, query, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TelemetryBucket), args.Error(1)
+}
+
+func (m *mockTelemetryRepo) DeleteReadings(ctx context.Context, filter models.ReadingDeleteFilter) error {
+	args := m.Called(ctx, filter)
+	return args.Error(0)
+}
+
+func (m *mockTelemetryRepo) SetRetentionPolicy(ctx context.Context, policy *models.RetentionPolicy) (*models.RetentionPolicy, error) {
+	args := m.Called(ctx, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RetentionPolicy), args.Error(1)
+}
+
+func (m *mockTelemetryRepo) ListRetentionPolicies(ctx context.Context) ([]*models.RetentionPolicy, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RetentionPolicy), args.Error(1)
+}
+
+func (m *mockTelemetryRepo) DeleteRetentionPolicy(ctx context.Context, featureID uuid.UUID) error {
+	args := m.Called(ctx, featureID)
+	return args.Error(0)
+}
+
+var telemetryNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestTelemetryService(repo *mockTelemetryRepo, featureRepo *mockSmartFeatureRepo, batchSize int) *TelemetryService {
+	s := NewTelemetryService(repo, featureRepo, 30*24*time.Hour, 100, batchSize)
+	s.now = func() time.Time { return telemetryNow }
+	return s
+}
+
+// readingMessages returns a next function for Ingest that yields messages
+// and then io.EOF.
+func readingMessages(messages ...[]*models.TelemetryReading) func() ([]*models.TelemetryReading, error) {
+	return func() ([]*models.TelemetryReading, error) {
+		if len(messages) == 0 {
+			return nil, io.EOF
+		}
+		message := messages[0]
+		messages = messages[1:]
+		return message, nil
+	}
+}
+
+func TestTelemetryService_Ingest(t *testing.T) {
+	mockRepo := new(mockTelemetryRepo)
+	mockFeatureRepo := new(mockSmartFeatureRepo)
+	s := newTestTelemetryService(mockRepo, mockFeatureRepo, 2)
+
+	featureID, unknownID := uuid.New(), uuid.New()
+	at := telemetryNow.Add(-time.Minute)
+
+	mockRepo.On("ListRetentionPolicies", mock.Anything).Return([]*models.RetentionPolicy{}, nil)
+	mockFeatureRepo.On("GetByIDs", mock.Anything, mock.MatchedBy(func(ids []string) bool {
+		return len(ids) == 2
+	})).Return([]*models.SmartFeature{{ID: featureID}}, nil).Once()
+	mockRepo.On("AddReadings", mock.Anything, mock.MatchedBy(func(readings []*models.TelemetryReading) bool {
+		return len(readings) == 2 && readings[0].DeviceID == "dev-1" && readings[1].DeviceID == "dev-2"
+	})).Return(2, nil).Once()
+	mockRepo.On("AddReadings", mock.Anything, mock.MatchedBy(func(readings []*models.TelemetryReading) bool {
+		return len(readings) == 1 && readings[0].DeviceID == "dev-1"
+	})).Return(0, nil).Once()
+
+	report, err := s.Ingest(context.Background(), readingMessages(
+		[]*models.TelemetryReading{
+			{DeviceID: "dev-1", FeatureID: featureID, Timestamp: at, Value: 1},
+			{DeviceID: "dev-1", FeatureID: unknownID, Timestamp: at, Value: 1},
+			{DeviceID: "", FeatureID: featureID, Timestamp: at, Value: 1},
+		},
+		[]*models.TelemetryReading{
+			{DeviceID: "dev-2", FeatureID: featureID, Timestamp: at, Value: 2},
+			{DeviceID: "dev-3", FeatureID: featureID, Timestamp: at, Value: math.NaN()},
+			{DeviceID: "dev-1", FeatureID: featureID, Timestamp: at, Value: 1},
+			{DeviceID: "dev-4", FeatureID: uuid.Nil, Timestamp: at, Value: 1},
+		},
+	))
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), report.Received)
+	assert.Equal(t, int64(2), report.Stored)
+	assert.Equal(t, int64(1), report.Duplicates)
+	assert.Equal(t, int64(4), report.Rejected)
+	assert.Equal(t, []int64{1, 2, 4, 6}, rejectionIndexes(report))
+	mockRepo.AssertExpectations(t)
+	mockFeatureRepo.AssertExpectations(t)
+}
+
+type recordingReadingObserver struct {
+	batches [][]*models.TelemetryReading
+}
+
+func (o *recordingReadingObserver) ObserveReadings(readings []*models.TelemetryReading) {
+	o.batches = append(o.batches, readings)
+}
+
+func TestTelemetryService_Ingest_NotifiesObservers(t *testing.T) {
+	mockRepo := new(mockTelemetryRepo)
+	mockFeatureRepo := new(mockSmartFeatureRepo)
+	s := newTestTelemetryService(mockRepo, mockFeatureRepo, 2)
+	observer := &recordingReadingObserver{}
+	s.AddObserver(observer)
+
+	featureID := uuid.New()
+	at := telemetryNow.Add(-time.Minute)
+	mockRepo.On("ListRetentionPolicies", mock.Anything).Return([]*models.RetentionPolicy{}, nil)
+	mockFeatureRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*models.SmartFeature{{ID: featureID}}, nil)
+	mockRepo.On("AddReadings", mock.Anything, mock.Anything).Return(2, nil).Once()
+	mockRepo.On("AddReadings", mock.Anything, mock.Anything).Return(0, errors.New("disk full")).Once()
+
+	_, err := s.Ingest(context.Background(), readingMessages(
+		[]*models.TelemetryReading{
+			{DeviceID: "dev-1", FeatureID: featureID, Timestamp: at, Value: 1},
+			{DeviceID: "dev-2", FeatureID: featureID, Timestamp: at, Value: 2},
+			{DeviceID: "dev-3", FeatureID: featureID, Timestamp: at, Value: 3},
+		},
+	))
+
+	require.Error(t, err)
+	require.Len(t, observer.batches, 1, "a batch that failed to store is not observed")
+	assert.Len(t, observer.batches[0], 2)
+}
+
+func TestTelemetryService_Ingest_RejectsReadingsPastRetention(t *testing.T) {
+	mockRepo := new(mockTelemetryRepo)
+	mockFeatureRepo := new(mockSmartFeatureRepo)
+	s := newTestTelemetryService(mockRepo, mockFeatureRepo, 10)
+
+	featureID := uuid.New()
+	mockRepo.On("ListRetentionPolicies", mock.Anything).
+		Return([]*models.RetentionPolicy{{FeatureID: featureID, Retention: 2 * time.Hour}}, nil)
+	mockFeatureRepo.On("GetByIDs", mock.Anything, []string{featureID.String()}).
+		Return([]*models.SmartFeature{{ID: featureID}}, nil)
+	mockRepo.On("AddReadings", mock.Anything, mock.MatchedBy(func(readings []*models.TelemetryReading) bool {
+		return len(readings) == 1 && readings[0].Timestamp.Equal(telemetryNow.Add(-time.Hour).Truncate(time.Microsecond))
+	})).Return(1, nil)
+
+	report, err := s.Ingest(context.Background(), readingMessages([]*models.TelemetryReading{
+		{DeviceID: "dev-1", FeatureID: featureID, Timestamp: telemetryNow.Add(-3 * time.Hour), Value: 1},
+		{DeviceID: "dev-1", FeatureID: featureID, Timestamp: telemetryNow.Add(-time.Hour + time.Nanosecond), Value: 2},
+		{DeviceID: "dev-1", FeatureID: featureID, Timestamp: telemetryNow.Add(48 * time.Hour), Value: 3},
+	}))
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), report.Stored)
+	require.Len(t, report.Rejections, 2)
+	assert.Equal(t, "timestamp is older than the feature's retention", report.Rejections[0].Reason)
+	assert.Equal(t, "timestamp is too far in the future", report.Rejections[1].Reason)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTelemetryService_Ingest_StreamError(t *testing.T) {
+	mockRepo := new(mockTelemetryRepo)
+	s := newTestTelemetryService(mockRepo, new(mockSmartFeatureRepo), 10)
+
+	streamErr := errors.New("stream reset")
+	mockRepo.On("ListRetentionPolicies", mock.Anything).Return([]*models.RetentionPolicy{}, nil)
+
+	report, err := s.Ingest(context.Background(), func() ([]*models.TelemetryReading, error) {
+		return nil, streamErr
+	})
+
+	assert.Nil(t, report)
+	assert.ErrorIs(t, err, streamErr)
+	mockRepo.AssertNotCalled(t, "AddReadings", mock.Anything, mock.Anything)
+}
+
+func TestTelemetryService_Query_Truncated(t *testing.T) {
+	mockRepo := new(mockTelemetryRepo)
+	mockFeatureRepo := new(mockSmartFeatureRepo)
+	s := newTestTelemetryService(mockRepo, mockFeatureRepo, 10)
+
+	featureID := uuid.New()
+	from := telemetryNow.Add(-time.Hour)
+	mockFeatureRepo.On("GetByID", mock.Anything, featureID.String()).Return(&models.SmartFeature{ID: featureID}, nil)
+	mockRepo.On("ListReadings", mock.Anything, models.ReadingQuery{FeatureID: featureID, From: from, To: telemetryNow, Limit: 3}).
+		Return([]*models.TelemetryReading{{Value: 1}, {Value: 2}, {Value: 3}}, nil)
+
+	series, err := s.Query(context.Background(), models.ReadingQuery{FeatureID: featureID, From: from, Limit: 2}, 0)
+
+	require.NoError(t, err)
+	assert.True(t, series.Truncated)
+	assert.Len(t, series.Readings, 2)
+	assert.Nil(t, series.Buckets)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTelemetryService_Query_Downsampled(t *testing.T) {
+	mockRepo := new(mockTelemetryRepo)
+	mockFeatureRepo := new(mockSmartFeatureRepo)
+	s := newTestTelemetryService(mockRepo, mockFeatureRepo, 10)
+
+	featureID := uuid.New()
+	from, to := telemetryNow.Add(-time.Hour), telemetryNow.Add(-time.Minute)
+	mockFeatureRepo.On("GetByID", mock.Anything, featureID.String()).Return(&models.SmartFeature{ID: featureID}, nil)
+	mockRepo.On("Downsample", mock.Anything, models.ReadingQuery{FeatureID: featureID, From: from, To: to, Limit: 101}, 5*time.Minute).
+		Return([]*models.TelemetryBucket{{Start: from, Count: 2, Avg: 1.5, Min: 1, Max: 2}}, nil)
+
+	series, err := s.Query(context.Background(), models.ReadingQuery{FeatureID: featureID, From: from, To: to, Limit: 1000}, 5*time.Minute)
+
+	require.NoError(t, err)
+	assert.False(t, series.Truncated)
+	assert.Len(t, series.Buckets, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTelemetryService_Query_Invalid(t *testing.T) {
+	featureID := uuid.New()
+	from := telemetryNow.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		query  models.ReadingQuery
+		bucket time.Duration
+	}{
+		{"missing feature", models.ReadingQuery{From: from}, 0},
+		{"missing start", models.ReadingQuery{FeatureID: featureID}, 0},
+		{"start after end", models.ReadingQuery{FeatureID: featureID, From: telemetryNow, To: from}, 0},
+		{"bucket too small", models.ReadingQuery{FeatureID: featureID, From: from}, 500 * time.Millisecond},
+		{"fractional bucket", models.ReadingQuery{FeatureID: featureID, From: from}, 1500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockTelemetryRepo)
+			mockFeatureRepo := new(mockSmartFeatureRepo)
+			s := newTestTelemetryService(mockRepo, mockFeatureRepo, 10)
+
+			series, err := s.Query(context.Background(), tt.query, tt.bucket)
+
+			assert.Nil(t, series)
+			assert.ErrorIs(t, err, models.ErrInvalidTelemetryQuery)
+			mockFeatureRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTelemetryService_Query_FeatureNotFound(t *testing.T) {
+	mockRepo := new(mockTelemetryRepo)
+	mockFeatureRepo := new(mockSmartFeatureRepo)
+	s := newTestTelemetryService(mockRepo, mockFeatureRepo, 10)
+
+	featureID := uuid.New()
+	mockFeatureRepo.On("GetByID", mock.Anything, featureID.String()).Return(nil, models.ErrNotFound)
+
+	series, err := s.Query(context.Background(), models.ReadingQuery{FeatureID: featureID, From: telemetryNow.Add(-time.Hour)}, 0)
+
+	assert.Nil(t, series)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	mockRepo.AssertNotCalled(t, "ListReadings", mock.Anything, mock.Anything)
+}
+
+func TestTelemetryService_SetRetentionPolicy(t *testing.T) {
+	mockRepo := new(mockTelemetryRepo)
+	s := newTestTelemetryService(mockRepo, new(mockSmartFeatureRepo), 10)
+
+	featureID := uuid.New()
+	expected := &models.RetentionPolicy{FeatureID: featureID, Retention: 48 * time.Hour, UpdatedAt: telemetryNow}
+	mockRepo.On("SetRetentionPolicy", mock.Anything, expected).Return(expected, nil)
+
+	policy, err := s.SetRetentionPolicy(context.Background(), featureID, 48*time.Hour)
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, policy)
+
+	_, err = s.SetRetentionPolicy(context.Background(), featureID, time.Minute)
+	assert.ErrorIs(t, err, models.ErrInvalidTelemetryQuery)
+	mockRepo.AssertNumberOfCalls(t, "SetRetentionPolicy", 1)
+}
+
+func TestTelemetryService_ApplyRetention(t *testing.T) {
+	mockRepo := new(mockTelemetryRepo)
+	s := newTestTelemetryService(mockRepo, new(mockSmartFeatureRepo), 10)
+
+	short, long := uuid.New(), uuid.New()
+	mockRepo.On("ListRetentionPolicies", mock.Anything).Return([]*models.RetentionPolicy{
+		{FeatureID: short, Retention: 24 * time.Hour},
+		{FeatureID: long, Retention: 90 * 24 * time.Hour},
+	}, nil)
+	mockRepo.On("DeleteReadings", mock.Anything, models.ReadingDeleteFilter{
+		Before: telemetryNow.Add(-90 * 24 * time.Hour),
+	}).Return(nil).Once()
+	mockRepo.On("DeleteReadings", mock.Anything, models.ReadingDeleteFilter{
+		Before:    telemetryNow.Add(-24 * time.Hour),
+		FeatureID: &short,
+	}).Return(nil).Once()
+	mockRepo.On("DeleteReadings", mock.Anything, models.ReadingDeleteFilter{
+		Before:           telemetryNow.Add(-30 * 24 * time.Hour),
+		ExceptFeatureIDs: []uuid.UUID{short, long},
+	}).Return(nil).Once()
+
+	err := s.ApplyRetention(context.Background())
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTelemetryService_ApplyRetention_DefaultOnly(t *testing.T) {
+	mockRepo := new(mockTelemetryRepo)
+	s := newTestTelemetryService(mockRepo, new(mockSmartFeatureRepo), 10)
+
+	mockRepo.On("ListRetentionPolicies", mock.Anything).Return([]*models.RetentionPolicy{}, nil)
+	mockRepo.On("DeleteReadings", mock.Anything, models.ReadingDeleteFilter{
+		Before: telemetryNow.Add(-30 * 24 * time.Hour),
+	}).Return(nil).Once()
+
+	err := s.ApplyRetention(context.Background())
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func rejectionIndexes(report *models.IngestReport) []int64 {
+	var indexes []int64
+	for _, rejection := range report.Rejections {
+		indexes = append(indexes, rejection.Index)
+	}
+	return indexes
+}
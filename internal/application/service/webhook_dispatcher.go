@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"sync"
+	"time"
+)
+
+// WebhookDispatcher turns domain events into webhook deliveries and sends
+// them. As an EventPublisher it only enqueues. Delivery is at-least-once: the
+// relay may publish an event again after a failure, and EnqueueDeliveries
+// absorbs the duplicates with ON CONFLICT (subscription_id, event_id) DO
+// NOTHING. Run then POSTs due deliveries, retrying failures with exponential
+// backoff until they succeed or run out of attempts.
+type WebhookDispatcher struct {
+	repo           interfaces.WebhookRepository
+	sender         interfaces.WebhookSender
+	interval       time.Duration
+	batchSize      int
+	timeout        time.Duration
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	now            func() time.Time
+}
+
+func NewWebhookDispatcher(
+	repo interfaces.WebhookRepository,
+	sender interfaces.WebhookSender,
+	interval time.Duration,
+	batchSize int,
+	timeout time.Duration,
+	maxAttempts int,
+	initialBackoff time.Duration,
+	maxBackoff time.Duration,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:           repo,
+		sender:         sender,
+		interval:       interval,
+		batchSize:      batchSize,
+		timeout:        timeout,
+		maxAttempts:    maxAttempts,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		now:            time.Now,
+	}
+}
+
+// Publish enqueues event for every active subscription interested in it.
+func (d *WebhookDispatcher) Publish(ctx context.Context, event *models.DomainEvent) error {
+	subscriptions, err := d.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	var deliveries []*models.WebhookDelivery
+	now := d.now()
+	for _, subscription := range subscriptions {
+		if !subscription.Active || !subscription.Matches(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, &models.WebhookDelivery{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return d.repo.EnqueueDeliveries(ctx, deliveries...)
+}
+
+func (d *WebhookDispatcher) Close() error {
+	return nil
+}
+
+// Run sends due deliveries every interval until ctx is cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		claimed, err := d.DispatchOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Webhook dispatch failed", err)
+		}
+
+		if err == nil && claimed == d.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce sends one batch of due deliveries concurrently and returns
+// how many were attempted.
+func (d *WebhookDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookDispatcher.DispatchOnce")
+	defer span.End()
+
+	// The lease covers the request timeout plus time to record the outcome;
+	// a dispatcher that dies mid-batch leaves its deliveries due again after
+	// it expires.
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, d.batchSize, d.now().Add(2*d.timeout))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return 0, err
+	}
+
+	subscriptions := make(map[uuid.UUID]*models.WebhookSubscription)
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			subscription, err = d.repo.GetSubscription(ctx, delivery.SubscriptionID)
+			if err != nil {
+				logger.FromContext(ctx).Warn("Failed to load webhook subscription", "subscription_id", delivery.SubscriptionID, err)
+				continue
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		wg.Add(1)
+		go func(delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			d.deliver(ctx, subscription, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) {
+	sendCtx, cancel := context.WithTimeout(ctx, d.timeout)
+	statusCode, err := d.sender.Send(sendCtx, subscription, delivery)
+	cancel()
+
+	now := d.now()
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= d.maxAttempts:
+		delivery.Status = models.DeliveryDead
+		delivery.LastError = err.Error()
+	default:
+		delivery.Status = models.DeliveryFailed
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+	}
+
+	if err != nil {
+		logger.FromContext(ctx).Warn("Webhook delivery failed",
+			"delivery_id", delivery.ID, "subscription_id", subscription.ID, "attempts", delivery.Attempts, "status", delivery.Status, err)
+	}
+
+	if err := d.repo.UpdateDelivery(ctx, delivery); err != nil {
+		logger.FromContext(ctx).Error("Failed to record webhook delivery", "delivery_id", delivery.ID, err)
+	}
+}
+
+// backoff returns the wait before the next attempt after attempts failures.
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	backoff := d.initialBackoff
+	for i := 1; i < attempts && backoff < d.maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, d.maxBackoff)
+}
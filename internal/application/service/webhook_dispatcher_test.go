@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+type mockWebhookSender struct {
+	mock.Mock
+}
+
+func (m *mockWebhookSender) Send(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	args := m.Called(ctx, subscription, delivery)
+	return args.Int(0), args.Error(1)
+}
+
+func newTestDispatcher(repo *mockWebhookRepo, sender *mockWebhookSender, now time.Time) *WebhookDispatcher {
+	dispatcher := NewWebhookDispatcher(repo, sender, time.Second, 10, time.Second, 3, 10*time.Second, time.Minute)
+	dispatcher.now = func() time.Time { return now }
+	return dispatcher
+}
+
+func TestWebhookDispatcher_Publish_EnqueuesMatchingSubscriptions(t *testing.T) {
+	mockRepo := new(mockWebhookRepo)
+	now := time.Now()
+	dispatcher := newTestDispatcher(mockRepo, new(mockWebhookSender), now)
+
+	all := &models.WebhookSubscription{ID: uuid.New(), Active: true}
+	modelsOnly := &models.WebhookSubscription{ID: uuid.New(), Active: true, EventTypes: []models.EventType{models.ModelCreatedEvent}}
+	featuresOnly := &models.WebhookSubscription{ID: uuid.New(), Active: true, EventTypes: []models.EventType{models.FeatureCreatedEvent}}
+	inactive := &models.WebhookSubscription{ID: uuid.New(), Active: false}
+	mockRepo.On("ListSubscriptions", mock.Anything).Return([]*models.WebhookSubscription{all, modelsOnly, featuresOnly, inactive}, nil)
+
+	event, err := models.NewDomainEvent(models.SmartModelAggregate, uuid.New(), models.ModelCreatedEvent, map[string]string{"name": "Test"})
+	require.NoError(t, err)
+
+	mockRepo.On("EnqueueDeliveries", mock.Anything, mock.MatchedBy(func(deliveries []*models.WebhookDelivery) bool {
+		if len(deliveries) != 2 || deliveries[0].SubscriptionID != all.ID || deliveries[1].SubscriptionID != modelsOnly.ID {
+			return false
+		}
+		var payload models.DomainEvent
+		if err := json.Unmarshal(deliveries[0].Payload, &payload); err != nil {
+			return false
+		}
+		return payload.ID == event.ID && deliveries[0].EventID == event.ID &&
+			deliveries[0].Status == models.DeliveryPending && deliveries[0].NextAttemptAt.Equal(now)
+	})).Return(nil)
+
+	err = dispatcher.Publish(context.Background(), event)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookDispatcher_Publish_NoSubscribers(t *testing.T) {
+	mockRepo := new(mockWebhookRepo)
+	dispatcher := newTestDispatcher(mockRepo, new(mockWebhookSender), time.Now())
+
+	mockRepo.On("ListSubscriptions", mock.Anything).Return([]*models.WebhookSubscription{}, nil)
+
+	event, err := models.NewDomainEvent(models.SmartModelAggregate, uuid.New(), models.ModelDeletedEvent, nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, dispatcher.Publish(context.Background(), event))
+	mockRepo.AssertNotCalled(t, "EnqueueDeliveries", mock.Anything, mock.Anything)
+}
+
+func TestWebhookDispatcher_DispatchOnce(t *testing.T) {
+	now := time.Now()
+	subscription := &models.WebhookSubscription{ID: uuid.New(), URL: "https://example.com", Active: true}
+
+	tests := []struct {
+		name           string
+		attempts       int
+		statusCode     int
+		sendErr        error
+		expectedStatus models.DeliveryStatus
+		expectedNext   time.Time
+	}{
+		{"success", 0, 200, nil, models.DeliverySucceeded, now},
+		{"first failure", 0, 500, errors.New("status 500"), models.DeliveryFailed, now.Add(10 * time.Second)},
+		{"second failure backs off", 1, 0, errors.New("timeout"), models.DeliveryFailed, now.Add(20 * time.Second)},
+		{"last attempt goes to dead letters", 2, 503, errors.New("status 503"), models.DeliveryDead, now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockWebhookRepo)
+			mockSender := new(mockWebhookSender)
+			dispatcher := newTestDispatcher(mockRepo, mockSender, now)
+
+			delivery := &models.WebhookDelivery{
+				ID:             uuid.New(),
+				SubscriptionID: subscription.ID,
+				Attempts:       tt.attempts,
+				Status:         models.DeliveryPending,
+				NextAttemptAt:  now,
+			}
+			mockRepo.On("ClaimDueDeliveries", mock.Anything, 10, now.Add(2*time.Second)).Return([]*models.WebhookDelivery{delivery}, nil)
+			mockRepo.On("GetSubscription", mock.Anything, subscription.ID).Return(subscription, nil)
+			mockSender.On("Send", mock.Anything, subscription, delivery).Return(tt.statusCode, tt.sendErr)
+			mockRepo.On("UpdateDelivery", mock.Anything, delivery).Return(nil)
+
+			claimed, err := dispatcher.DispatchOnce(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, claimed)
+			assert.Equal(t, tt.expectedStatus, delivery.Status)
+			assert.Equal(t, tt.attempts+1, delivery.Attempts)
+			assert.Equal(t, tt.statusCode, delivery.LastStatusCode)
+			assert.True(t, tt.expectedNext.Equal(delivery.NextAttemptAt), "next attempt %v", delivery.NextAttemptAt)
+			if tt.sendErr == nil {
+				assert.NotNil(t, delivery.DeliveredAt)
+				assert.Empty(t, delivery.LastError)
+			} else {
+				assert.Nil(t, delivery.DeliveredAt)
+				assert.Equal(t, tt.sendErr.Error(), delivery.LastError)
+			}
+			mockRepo.AssertExpectations(t)
+			mockSender.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookDispatcher_Backoff(t *testing.T) {
+	dispatcher := newTestDispatcher(new(mockWebhookRepo), new(mockWebhookSender), time.Now())
+
+	assert.Equal(t, 10*time.Second, dispatcher.backoff(1))
+	assert.Equal(t, 20*time.Second, dispatcher.backoff(2))
+	assert.Equal(t, 40*time.Second, dispatcher.backoff(3))
+	assert.Equal(t, time.Minute, dispatcher.backoff(4))
+	assert.Equal(t, time.Minute, dispatcher.backoff(30))
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+const defaultDeliveryListLimit = 100
+
+type WebhookService struct {
+	repo interfaces.WebhookRepository
+}
+
+func NewWebhookService(repo interfaces.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		repo: repo,
+	}
+}
+
+// CreateSubscription stores a new, active subscription. A secret is generated
+// when the caller did not provide one.
+func (s *WebhookService) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.CreateSubscription", attribute.String("webhook.url", subscription.URL))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Create webhook subscription", "url", subscription.URL, "event_types", subscription.EventTypes)
+
+	if subscription.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		subscription.Secret = secret
+	}
+	if subscription.ID == uuid.Nil {
+		subscription.ID = uuid.New()
+	}
+	now := time.Now()
+	subscription.Active = true
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+
+	created, err := s.repo.CreateSubscription(ctx, subscription)
+	tracing.RecordError(span, err)
+	return created, err
+}
+
+func (s *WebhookService) GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.GetSubscription", attribute.String("webhook.id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Get webhook subscription", "id", id)
+	subscription, err := s.repo.GetSubscription(ctx, id)
+	tracing.RecordError(span, err)
+	return subscription, err
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.ListSubscriptions")
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("List webhook subscriptions")
+	subscriptions, err := s.repo.ListSubscriptions(ctx)
+	tracing.RecordError(span, err)
+	return subscriptions, err
+}
+
+// UpdateSubscription changes the URL, filters, description and active flag.
+// The secret cannot be changed.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.UpdateSubscription", attribute.String("webhook.id", subscription.ID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Update webhook subscription", "id", subscription.ID)
+
+	subscription.UpdatedAt = time.Now()
+	updated, err := s.repo.UpdateSubscription(ctx, subscription)
+	tracing.RecordError(span, err)
+	return updated, err
+}
+
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.DeleteSubscription", attribute.String("webhook.id", id.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("Delete webhook subscription", "id", id)
+	err := s.repo.DeleteSubscription(ctx, id)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.ListDeliveries")
+	defer span.End()
+
+	logger.FromContext(ctx).Debug("List webhook deliveries", "subscription_id", filter.SubscriptionID, "status", filter.Status)
+
+	if filter.Limit <= 0 || filter.Limit > defaultDeliveryListLimit {
+		filter.Limit = defaultDeliveryListLimit
+	}
+	deliveries, err := s.repo.ListDeliveries(ctx, filter)
+	tracing.RecordError(span, err)
+	return deliveries, err
+}
+
+// Redeliver queues a delivery for an immediate attempt with a fresh retry
+// budget, including ones that already succeeded or went to the dead-letter
+// list.
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	ctx, span := tracing.StartSpan(ctx, "WebhookService.Redeliver", attribute.String("delivery.id", deliveryID.String()))
+	defer span.End()
+
+	logger.FromContext(ctx).Info("Redeliver webhook delivery", "id", deliveryID)
+
+	delivery, err := s.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+type mockWebhookRepo struct {
+	mock.Mock
+}
+
+func (m *mockWebhookRepo) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, subscription)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookRepo) GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookRepo) ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookRepo) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, subscription)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookRepo) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepo) EnqueueDeliveries(ctx context.Context, deliveries ...*models.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepo) ClaimDueDeliveries(ctx context.Context, limit int, leaseUntil time.Time) ([]*models.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, leaseUntil)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookRepo) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepo) GetDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookRepo) ListDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+func TestWebhookService_CreateSubscription_GeneratesSecret(t *testing.T) {
+	mockRepo := new(mockWebhookRepo)
+	service := NewWebhookService(mockRepo)
+
+	subscription := &models.WebhookSubscription{URL: "https://example.com/hook"}
+	mockRepo.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(s *models.WebhookSubscription) bool {
+		return len(s.Secret) == 64 && s.Active && s.ID != uuid.Nil && !s.CreatedAt.IsZero()
+	})).Return(subscription, nil)
+
+	result, err := service.CreateSubscription(context.Background(), subscription)
+
+	assert.NoError(t, err)
+	assert.Equal(t, subscription, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_CreateSubscription_KeepsSecret(t *testing.T) {
+	mockRepo := new(mockWebhookRepo)
+	service := NewWebhookService(mockRepo)
+
+	subscription := &models.WebhookSubscription{URL: "https://example.com/hook", Secret: "my-own-secret-value"}
+	mockRepo.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(s *models.WebhookSubscription) bool {
+		return s.Secret == "my-own-secret-value"
+	})).Return(subscription, nil)
+
+	_, err := service.CreateSubscription(context.Background(), subscription)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_ListDeliveries_CapsLimit(t *testing.T) {
+	mockRepo := new(mockWebhookRepo)
+	service := NewWebhookService(mockRepo)
+
+	id := uuid.New()
+	expected := []*models.WebhookDelivery{{ID: uuid.New()}}
+	mockRepo.On("ListDeliveries", mock.Anything, models.WebhookDeliveryFilter{SubscriptionID: &id, Limit: defaultDeliveryListLimit}).Return(expected, nil)
+
+	result, err := service.ListDeliveries(context.Background(), models.WebhookDeliveryFilter{SubscriptionID: &id, Limit: 5000})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	mockRepo := new(mockWebhookRepo)
+	service := NewWebhookService(mockRepo)
+
+	delivery := &models.WebhookDelivery{ID: uuid.New(), Status: models.DeliveryDead, Attempts: 8, LastError: "timeout"}
+	mockRepo.On("GetDelivery", mock.Anything, delivery.ID).Return(delivery, nil)
+	mockRepo.On("UpdateDelivery", mock.Anything, delivery).Return(nil)
+
+	result, err := service.Redeliver(context.Background(), delivery.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, result.Status)
+	assert.Equal(t, 0, result.Attempts)
+	assert.WithinDuration(t, time.Now(), result.NextAttemptAt, time.Second)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_Redeliver_NotFound(t *testing.T) {
+	mockRepo := new(mockWebhookRepo)
+	service := NewWebhookService(mockRepo)
+
+	id := uuid.New()
+	mockRepo.On("GetDelivery", mock.Anything, id).Return(nil, models.ErrNotFound)
+
+	result, err := service.Redeliver(context.Background(), id)
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+	mockRepo.AssertExpectations(t)
+}
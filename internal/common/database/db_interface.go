@@ -10,6 +10,7 @@ type PgxPool interface {
 	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
 	Begin(ctx context.Context) (pgx.Tx, error)
 	Close()
 	Ping(ctx context.Context) error
@@ -18,5 +19,9 @@ type PgxPool interface {
 type Database interface {
 	Ping(ctx context.Context) error
 	Close()
+	// GetPool returns the primary pool.
 	GetPool() PgxPool
+	// GetReadPool returns a pool for reads that tolerate replication lag.
+	// Reads made with a context from ForcePrimary still go to the primary.
+	GetReadPool() PgxPool
 }
@@ -1,29 +1,225 @@
 package migrations
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
 	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"smart-hub/internal/common/logger"
+	schema "smart-hub/migrations"
 )
 
-func RunMigrations(databaseURL string) error {
-	return RunMigrationsWithSourceUrl(databaseURL, "file://migrations")
+// lockID is the Postgres advisory lock held while migrating, so replicas
+// that start together run the migrations one after another.
+const lockID int64 = 0x736d6172742d6875 // "smart-hu"
+
+// Status describes where a database stands against the embedded migrations.
+// Version is 0 before the first migration.
+type Status struct {
+	Version uint
+	Dirty   bool
+	Latest  uint
+	Pending []uint
 }
 
-func RunMigrationsWithSourceUrl(databaseURL string, sourceUrl string) error {
-	logger.Info("Running migrations...")
-	m, err := migrate.New(
-		sourceUrl,
-		databaseURL)
+// Migrator applies the embedded migrations to one database. It must be
+// closed to release the connection and, on Postgres, the advisory lock.
+type Migrator struct {
+	migrate *migrate.Migrate
+	source  source.Driver
+	close   func() error
+}
+
+// NewPostgresMigrator connects to dsn and waits for the migration lock.
+func NewPostgresMigrator(ctx context.Context, dsn string) (*Migrator, error) {
+	db, err := sql.Open("pgx", dsn)
 	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := lock(ctx, conn); err != nil {
+		conn.Close()
+		db.Close()
+		return nil, err
+	}
+	unlock := func() error {
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
 		return err
 	}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		unlock()
+		conn.Close()
+		db.Close()
+		return nil, err
+	}
+
+	m, err := newMigrator(schema.Files, ".", func(src source.Driver) (*migrate.Migrate, error) {
+		return migrate.NewWithInstance("iofs", src, "postgres", driver)
+	})
+	if err != nil {
+		unlock()
+		driver.Close()
+		db.Close()
+		return nil, err
+	}
+	m.close = func() error {
+		unlockErr := unlock()
+		sourceErr, databaseErr := m.migrate.Close()
+		return errors.Join(unlockErr, sourceErr, databaseErr, db.Close())
+	}
+	return m, nil
+}
+
+// lock takes the migration lock on conn, logging when another replica holds
+// it.
+func lock(ctx context.Context, conn *sql.Conn) error {
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, lockID).Scan(&locked); err != nil {
 		return err
 	}
+	if locked {
+		return nil
+	}
+	logger.Info("Waiting for another instance to finish migrating...")
+	_, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID)
+	return err
+}
+
+// NewSQLiteMigrator opens the database file at path.
+func NewSQLiteMigrator(path string) (*Migrator, error) {
+	m, err := newMigrator(schema.Files, "sqlite", func(src source.Driver) (*migrate.Migrate, error) {
+		return migrate.NewWithSourceInstance("iofs", src, "sqlite://"+path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.close = func() error {
+		sourceErr, databaseErr := m.migrate.Close()
+		return errors.Join(sourceErr, databaseErr)
+	}
+	return m, nil
+}
+
+func newMigrator(fsys fs.FS, dir string, open func(source.Driver) (*migrate.Migrate, error)) (*Migrator, error) {
+	src, err := iofs.New(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	m, err := open(src)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+	// A separate source for Status, since migrate reads its own concurrently.
+	statusSource, err := iofs.New(fsys, dir)
+	if err != nil {
+		m.Close()
+		return nil, err
+	}
+	return &Migrator{migrate: m, source: statusSource}, nil
+}
+
+func (m *Migrator) Close() error {
+	return errors.Join(m.close(), m.source.Close())
+}
 
+// Up applies all pending migrations.
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.migrate.Up())
+}
+
+// Down rolls back the last n migrations.
+func (m *Migrator) Down(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("down: step count must be positive, got %d", n)
+	}
+	return ignoreNoChange(m.migrate.Steps(-n))
+}
+
+// Goto migrates up or down to version.
+func (m *Migrator) Goto(version uint) error {
+	return ignoreNoChange(m.migrate.Migrate(version))
+}
+
+// Force records version as applied and clears the dirty flag without running
+// anything, to recover from a migration that failed halfway. -1 means no
+// version.
+func (m *Migrator) Force(version int) error {
+	return m.migrate.Force(version)
+}
+
+func (m *Migrator) Status() (*Status, error) {
+	status := &Status{}
+	version, dirty, err := m.migrate.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, err
+	}
+	status.Version, status.Dirty = version, dirty
+
+	next, err := m.source.First()
+	for err == nil {
+		status.Latest = next
+		if next > status.Version {
+			status.Pending = append(status.Pending, next)
+		}
+		next, err = m.source.Next(next)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return status, nil
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// RunMigrations applies the pending Postgres migrations at dsn.
+func RunMigrations(ctx context.Context, dsn string) error {
+	m, err := NewPostgresMigrator(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	return run(m)
+}
+
+// RunSQLiteMigrations applies the pending SQLite migrations to the database
+// file at path.
+func RunSQLiteMigrations(path string) error {
+	m, err := NewSQLiteMigrator(path)
+	if err != nil {
+		return err
+	}
+	return run(m)
+}
+
+func run(m *Migrator) error {
+	logger.Info("Running migrations...")
+	err := m.Up()
+	if closeErr := m.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
 	logger.Info("Migrations completed successfully")
 	return nil
 }
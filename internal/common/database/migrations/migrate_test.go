@@ -0,0 +1,94 @@
+package migrations
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/fs"
+	"path/filepath"
+	schema "smart-hub/migrations"
+	"strings"
+	"testing"
+)
+
+// sqliteVersions lists the versions of the embedded SQLite migrations.
+func sqliteVersions(t *testing.T) []uint {
+	files, err := fs.Glob(schema.Files, "sqlite/*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	versions := make([]uint, len(files))
+	for i := range files {
+		versions[i] = uint(i + 1)
+	}
+	return versions
+}
+
+func newTestMigrator(t *testing.T) *Migrator {
+	m, err := NewSQLiteMigrator(filepath.Join(t.TempDir(), "smart-hub.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, m.Close()) })
+	return m
+}
+
+func requireStatus(t *testing.T, m *Migrator, version uint, pending []uint) {
+	t.Helper()
+	status, err := m.Status()
+	require.NoError(t, err)
+	assert.Equal(t, version, status.Version)
+	assert.False(t, status.Dirty)
+	assert.Equal(t, pending, status.Pending)
+}
+
+func TestMigrator_Status_Pending(t *testing.T) {
+	versions := sqliteVersions(t)
+	latest := versions[len(versions)-1]
+	m := newTestMigrator(t)
+
+	status, err := m.Status()
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), status.Version)
+	assert.Equal(t, latest, status.Latest)
+	assert.Equal(t, versions, status.Pending)
+
+	require.NoError(t, m.Up())
+	requireStatus(t, m, latest, nil)
+
+	// Up with nothing pending is not an error.
+	require.NoError(t, m.Up())
+}
+
+func TestMigrator_DownGotoForce(t *testing.T) {
+	versions := sqliteVersions(t)
+	latest := versions[len(versions)-1]
+	m := newTestMigrator(t)
+	require.NoError(t, m.Up())
+
+	require.NoError(t, m.Down(2))
+	requireStatus(t, m, latest-2, versions[latest-2:])
+
+	require.NoError(t, m.Goto(3))
+	requireStatus(t, m, 3, versions[3:])
+
+	require.NoError(t, m.Goto(latest))
+	requireStatus(t, m, latest, nil)
+
+	require.NoError(t, m.Force(5))
+	requireStatus(t, m, 5, versions[5:])
+
+	require.NoError(t, m.Force(-1))
+	requireStatus(t, m, 0, versions)
+
+	assert.Error(t, m.Down(0))
+}
+
+func TestSQLiteMigrations_ComeInPairs(t *testing.T) {
+	up, err := fs.Glob(schema.Files, "sqlite/*.up.sql")
+	require.NoError(t, err)
+	down, err := fs.Glob(schema.Files, "sqlite/*.down.sql")
+	require.NoError(t, err)
+
+	require.Len(t, down, len(up))
+	for i := range up {
+		assert.Equal(t, strings.TrimSuffix(up[i], ".up.sql"), strings.TrimSuffix(down[i], ".down.sql"))
+	}
+}
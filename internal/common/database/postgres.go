@@ -4,26 +4,28 @@ import (
 	"context"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"smart-hub/internal/common/logger"
+	"time"
 )
 
 type PostgresDB struct {
-	pool PgxPool
+	pool   PgxPool
+	router *replicaRouter
 }
 
+// PostgreConfig connects to the primary at DSN and, optionally, to read
+// replicas at ReplicaDSNs. Replicas are checked every ReplicaCheckInterval
+// and skipped while they lag more than MaxReplicaLag behind.
 type PostgreConfig struct {
-	DSN string
+	DSN                  string
+	ReplicaDSNs          []string
+	MaxReplicaLag        time.Duration
+	ReplicaCheckInterval time.Duration
 }
 
 func NewPostgresDatabase(ctx context.Context, config *PostgreConfig) (Database, error) {
 	logger.Info("PostgreSQL Starting...")
 
-	poolConfig, err := pgxpool.ParseConfig(config.DSN)
-	if err != nil {
-		logger.Error("Error parsing PostgreSQL config: ", err)
-		return nil, err
-	}
-
-	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	pool, err := newPool(ctx, config.DSN)
 	if err != nil {
 		logger.Error("PostgreSQL Connection Error: ", err)
 		return nil, err
@@ -39,19 +41,57 @@ func NewPostgresDatabase(ctx context.Context, config *PostgreConfig) (Database,
 	err = db.Ping(ctx)
 	if err != nil {
 		logger.Error("PostgreSQL Ping Error: ", err)
+		pool.Close()
 		return nil, err
 	}
 
 	logger.Info("PostgreSQL Ping successful")
+
+	if len(config.ReplicaDSNs) > 0 {
+		replicas := make([]*replica, len(config.ReplicaDSNs))
+		for i, dsn := range config.ReplicaDSNs {
+			replicaPool, err := newPool(ctx, dsn)
+			if err != nil {
+				logger.Error("PostgreSQL replica config error: ", err)
+				for _, opened := range replicas[:i] {
+					opened.pool.Close()
+				}
+				pool.Close()
+				return nil, err
+			}
+			replicas[i] = &replica{name: replicaPool.Config().ConnConfig.Host, pool: replicaPool}
+		}
+
+		db.router = newReplicaRouter(pool, replicas, config.MaxReplicaLag)
+		db.router.checkAll(config.ReplicaCheckInterval)
+		db.router.run(config.ReplicaCheckInterval)
+		logger.Info("PostgreSQL read replicas configured", "count", len(replicas))
+	}
+
 	return db, nil
 }
 
+// newPool creates a traced pool. It connects lazily, so an unreachable
+// replica only shows up in its health checks.
+func newPool(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	poolConfig.ConnConfig.Tracer = newQueryTracer()
+
+	return pgxpool.NewWithConfig(ctx, poolConfig)
+}
+
 func (db *PostgresDB) Ping(ctx context.Context) error {
 	return db.pool.Ping(ctx)
 }
 
 func (db *PostgresDB) Close() {
 	logger.Info("Closing PostgreSQL connection...")
+	if db.router != nil {
+		db.router.Close()
+	}
 	db.pool.Close()
 	logger.Info("PostgreSQL connection closed successfully.")
 }
@@ -59,3 +99,12 @@ func (db *PostgresDB) Close() {
 func (db *PostgresDB) GetPool() PgxPool {
 	return db.pool
 }
+
+// GetReadPool returns the pool for reads that may be served by a replica. It
+// is the primary pool when no replicas are configured.
+func (db *PostgresDB) GetReadPool() PgxPool {
+	if db.router == nil {
+		return db.pool
+	}
+	return db.router
+}
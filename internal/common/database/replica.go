@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"smart-hub/internal/common/logger"
+)
+
+type primaryKey struct{}
+
+// ForcePrimary marks ctx so that reads made with it go to the primary, e.g.
+// right after a write that has to be visible to the caller.
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryForced reports whether ctx was marked by ForcePrimary.
+func PrimaryForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryKey{}).(bool)
+	return forced
+}
+
+// replicaLagSQL returns how far a replica is behind in seconds. A replica
+// that has replayed everything it received counts as current, however long
+// ago the last write was.
+const replicaLagSQL = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END::float8`
+
+type replica struct {
+	name    string
+	pool    PgxPool
+	healthy atomic.Bool
+}
+
+// replicaRouter is the read pool of a database with replicas. Queries go to
+// the next healthy replica in turn, and to the primary when none is healthy
+// or the context asks for it. Everything that may write (Exec, SendBatch and
+// Begin) goes to the primary.
+type replicaRouter struct {
+	primary  PgxPool
+	replicas []*replica
+	maxLag   time.Duration
+	next     atomic.Uint64
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+func newReplicaRouter(primary PgxPool, replicas []*replica, maxLag time.Duration) *replicaRouter {
+	return &replicaRouter{
+		primary:  primary,
+		replicas: replicas,
+		maxLag:   maxLag,
+		stop:     make(chan struct{}),
+	}
+}
+
+// pick returns the pool a query made with ctx should use.
+func (r *replicaRouter) pick(ctx context.Context) PgxPool {
+	if PrimaryForced(ctx) {
+		return r.primary
+	}
+	for range r.replicas {
+		candidate := r.replicas[r.next.Add(1)%uint64(len(r.replicas))]
+		if candidate.healthy.Load() {
+			return candidate.pool
+		}
+	}
+	return r.primary
+}
+
+// run checks the replicas every interval until Close is called.
+func (r *replicaRouter) run(interval time.Duration) {
+	r.done.Add(1)
+	go func() {
+		defer r.done.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.checkAll(interval)
+			}
+		}
+	}()
+}
+
+// checkAll updates the health of every replica. A replica is healthy when it
+// answers within timeout and lags at most maxLag behind the primary.
+func (r *replicaRouter) checkAll(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, rep := range r.replicas {
+		healthy := r.check(ctx, rep)
+		if rep.healthy.Swap(healthy) != healthy {
+			logger.Info("Read replica health changed", "replica", rep.name, "healthy", healthy)
+		}
+	}
+}
+
+func (r *replicaRouter) check(ctx context.Context, rep *replica) bool {
+	var lagSeconds float64
+	if err := rep.pool.QueryRow(ctx, replicaLagSQL).Scan(&lagSeconds); err != nil {
+		logger.Warn("Read replica health check failed", "replica", rep.name, err)
+		return false
+	}
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	if lag > r.maxLag {
+		logger.Warn("Read replica lags behind", "replica", rep.name, "lag", lag.String())
+		return false
+	}
+	return true
+}
+
+func (r *replicaRouter) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return r.primary.Exec(ctx, sql, arguments...)
+}
+
+func (r *replicaRouter) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return r.pick(ctx).Query(ctx, sql, args...)
+}
+
+func (r *replicaRouter) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return r.pick(ctx).QueryRow(ctx, sql, args...)
+}
+
+func (r *replicaRouter) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return r.primary.SendBatch(ctx, b)
+}
+
+func (r *replicaRouter) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.primary.Begin(ctx)
+}
+
+func (r *replicaRouter) Ping(ctx context.Context) error {
+	return r.primary.Ping(ctx)
+}
+
+// Close stops the health checks and closes the replica pools. The primary
+// belongs to the database and is closed there.
+func (r *replicaRouter) Close() {
+	close(r.stop)
+	r.done.Wait()
+	for _, rep := range r.replicas {
+		rep.pool.Close()
+	}
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// batchPool counts SendBatch calls, which pgxmock does not record.
+type batchPool struct {
+	PgxPool
+	batches int
+}
+
+func (p *batchPool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	p.batches++
+	return nil
+}
+
+func newTestRouter(t *testing.T, replicas int) (*replicaRouter, pgxmock.PgxPoolIface, []pgxmock.PgxPoolIface) {
+	primary, err := pgxmock.NewPool(pgxmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	t.Cleanup(primary.Close)
+
+	mocks := make([]pgxmock.PgxPoolIface, replicas)
+	reps := make([]*replica, replicas)
+	for i := range replicas {
+		mocks[i], err = pgxmock.NewPool(pgxmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		t.Cleanup(mocks[i].Close)
+		reps[i] = &replica{name: string(rune('a' + i)), pool: mocks[i]}
+		reps[i].healthy.Store(true)
+	}
+	return newReplicaRouter(primary, reps, time.Second), primary, mocks
+}
+
+func TestReplicaRouter_RoundRobinSkipsUnhealthy(t *testing.T) {
+	router, _, replicas := newTestRouter(t, 3)
+	router.replicas[1].healthy.Store(false)
+
+	ctx := context.Background()
+	var picked []PgxPool
+	for range 4 {
+		picked = append(picked, router.pick(ctx))
+	}
+
+	assert.Same(t, replicas[2], picked[0])
+	assert.Same(t, replicas[0], picked[1])
+	assert.Same(t, replicas[2], picked[2], "the unhealthy replica is skipped")
+	assert.Same(t, replicas[0], picked[3])
+}
+
+func TestReplicaRouter_FallsBackToPrimary(t *testing.T) {
+	router, primary, _ := newTestRouter(t, 2)
+	for _, rep := range router.replicas {
+		rep.healthy.Store(false)
+	}
+	assert.Same(t, primary, router.pick(context.Background()))
+
+	noReplicas, primary, _ := newTestRouter(t, 0)
+	assert.Same(t, primary, noReplicas.pick(context.Background()))
+}
+
+func TestReplicaRouter_ForcePrimary(t *testing.T) {
+	router, primary, replicas := newTestRouter(t, 1)
+
+	assert.Same(t, replicas[0], router.pick(context.Background()))
+	assert.Same(t, primary, router.pick(ForcePrimary(context.Background())))
+	assert.False(t, PrimaryForced(context.Background()))
+	assert.True(t, PrimaryForced(ForcePrimary(context.Background())))
+}
+
+func TestReplicaRouter_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		lag     float64
+		err     error
+		healthy bool
+	}{
+		{name: "current", lag: 0, healthy: true},
+		{name: "lag within maxLag", lag: 0.5, healthy: true},
+		{name: "lag at maxLag", lag: 1, healthy: true},
+		{name: "lag beyond maxLag", lag: 1.5, healthy: false},
+		{name: "unreachable", err: errors.New("connection refused"), healthy: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _, replicas := newTestRouter(t, 1)
+			expect := replicas[0].ExpectQuery("pg_last_xact_replay_timestamp")
+			if tt.err != nil {
+				expect.WillReturnError(tt.err)
+			} else {
+				expect.WillReturnRows(pgxmock.NewRows([]string{"lag"}).AddRow(tt.lag))
+			}
+
+			router.checkAll(time.Second)
+
+			assert.Equal(t, tt.healthy, router.replicas[0].healthy.Load())
+			assert.NoError(t, replicas[0].ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReplicaRouter_WritesGoToPrimary(t *testing.T) {
+	router, primary, replicas := newTestRouter(t, 1)
+	ctx := context.Background()
+
+	primary.ExpectExec("UPDATE smart_models").WithArgs("Lamp").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	primary.ExpectBegin()
+	primary.ExpectPing()
+	replicas[0].ExpectQuery("SELECT id FROM smart_models").WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+	replicas[0].ExpectQuery("SELECT name FROM smart_models").WillReturnRows(pgxmock.NewRows([]string{"name"}).AddRow("Lamp"))
+
+	_, err := router.Exec(ctx, "UPDATE smart_models SET name = $1", "Lamp")
+	require.NoError(t, err)
+
+	_, err = router.Begin(ctx)
+	require.NoError(t, err)
+	require.NoError(t, router.Ping(ctx))
+
+	rows, err := router.Query(ctx, "SELECT id FROM smart_models")
+	require.NoError(t, err)
+	rows.Close()
+
+	var name string
+	require.NoError(t, router.QueryRow(ctx, "SELECT name FROM smart_models").Scan(&name))
+	assert.Equal(t, "Lamp", name)
+
+	assert.NoError(t, primary.ExpectationsWereMet())
+	assert.NoError(t, replicas[0].ExpectationsWereMet())
+
+	batchPrimary, batchReplica := &batchPool{PgxPool: primary}, &batchPool{PgxPool: replicas[0]}
+	router.primary, router.replicas[0].pool = batchPrimary, batchReplica
+	router.SendBatch(ctx, &pgx.Batch{})
+	assert.Equal(t, 1, batchPrimary.batches)
+	assert.Zero(t, batchReplica.batches)
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SQLQuerier is the subset of *sql.DB and *sql.Tx that database/sql based
+// repositories need, so the same code works inside and outside a
+// transaction.
+type SQLQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type sqlTxKey struct{}
+
+// ContextWithSQLTx binds tx to ctx so repositories called with the returned
+// context take part in the transaction.
+func ContextWithSQLTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, sqlTxKey{}, tx)
+}
+
+func SQLTxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(sqlTxKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// SQLConn returns the transaction bound to ctx, falling back to db.
+func SQLConn(ctx context.Context, db *sql.DB) SQLQuerier {
+	if tx, ok := SQLTxFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"smart-hub/internal/common/logger"
+
+	_ "modernc.org/sqlite"
+)
+
+type SQLiteDB struct {
+	db *sql.DB
+}
+
+type SQLiteConfig struct {
+	Path string
+}
+
+// NewSQLiteDatabase opens the database file at config.Path, creating it if
+// needed. SQLite allows a single writer, so the pool is limited to one
+// connection; foreign keys are switched on for it since SQLite leaves them
+// off by default.
+func NewSQLiteDatabase(ctx context.Context, config *SQLiteConfig) (*SQLiteDB, error) {
+	logger.Info("SQLite Starting...", "path", config.Path)
+
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "foreign_keys(1)")
+	pragmas.Add("_pragma", "busy_timeout(5000)")
+	pragmas.Add("_pragma", "journal_mode(WAL)")
+
+	db, err := sql.Open("sqlite", "file:"+config.Path+"?"+pragmas.Encode())
+	if err != nil {
+		logger.Error("SQLite Open Error: ", err)
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	sqliteDB := &SQLiteDB{
+		db: db,
+	}
+
+	if err := sqliteDB.Ping(ctx); err != nil {
+		logger.Error("SQLite Ping Error: ", err)
+		db.Close()
+		return nil, err
+	}
+
+	logger.Info("SQLite Connection success...")
+	return sqliteDB, nil
+}
+
+func (db *SQLiteDB) Ping(ctx context.Context) error {
+	return db.db.PingContext(ctx)
+}
+
+func (db *SQLiteDB) Close() {
+	logger.Info("Closing SQLite database...")
+	if err := db.db.Close(); err != nil {
+		logger.Error("SQLite close error", err)
+		return
+	}
+	logger.Info("SQLite database closed successfully.")
+}
+
+func (db *SQLiteDB) GetDB() *sql.DB {
+	return db.db
+}
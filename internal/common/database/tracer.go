@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"smart-hub/internal/common/tracing"
+	"strings"
+)
+
+// queryTracer emits a client span for every query, batch and COPY issued
+// through a pgx connection.
+type queryTracer struct{}
+
+var (
+	_ pgx.QueryTracer    = (*queryTracer)(nil)
+	_ pgx.BatchTracer    = (*queryTracer)(nil)
+	_ pgx.CopyFromTracer = (*queryTracer)(nil)
+)
+
+func newQueryTracer() *queryTracer {
+	return &queryTracer{}
+}
+
+func (t *queryTracer) start(ctx context.Context, conn *pgx.Conn, name string, attrs ...attribute.KeyValue) context.Context {
+	attrs = append(attrs, semconv.DBSystemPostgreSQL)
+	if conn != nil {
+		cfg := conn.Config()
+		attrs = append(attrs,
+			semconv.DBNamespace(cfg.Database),
+			semconv.ServerAddress(cfg.Host),
+			semconv.ServerPort(int(cfg.Port)),
+		)
+	}
+
+	ctx, _ = tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+func (t *queryTracer) end(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	tracing.RecordError(span, err)
+	span.End()
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return t.start(ctx, conn, spanName(data.SQL), semconv.DBQueryText(data.SQL))
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	t.end(ctx, data.Err)
+}
+
+func (t *queryTracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	size := 0
+	if data.Batch != nil {
+		size = data.Batch.Len()
+	}
+	return t.start(ctx, conn, "batch", attribute.Int("db.batch.size", size))
+}
+
+func (t *queryTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("query", trace.WithAttributes(semconv.DBQueryText(data.SQL)))
+	tracing.RecordError(span, data.Err)
+}
+
+func (t *queryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	t.end(ctx, data.Err)
+}
+
+func (t *queryTracer) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	return t.start(ctx, conn, "COPY "+data.TableName.Sanitize(),
+		semconv.DBCollectionName(strings.Join(data.TableName, ".")),
+	)
+}
+
+func (t *queryTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	t.end(ctx, data.Err)
+}
+
+// spanName uses the leading SQL keyword (SELECT, INSERT, ...) so span names
+// stay low-cardinality.
+func spanName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "query"
+	}
+	return strings.ToUpper(fields[0])
+}
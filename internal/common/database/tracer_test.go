@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestQueryTracer_Query(t *testing.T) {
+	recorder := recordSpans(t)
+	tracer := newQueryTracer()
+
+	sql := "update smart_models set name = $1 where id = $2"
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: sql})
+	assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+	assert.Empty(t, recorder.Ended())
+
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 2")})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "UPDATE", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "postgresql", spanAttribute(spans[0], "db.system").AsString())
+	assert.Equal(t, sql, spanAttribute(spans[0], "db.query.text").AsString())
+	assert.Equal(t, int64(2), spanAttribute(spans[0], "db.rows_affected").AsInt64())
+}
+
+func TestQueryTracer_QueryError(t *testing.T) {
+	recorder := recordSpans(t)
+	tracer := newQueryTracer()
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection reset")})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "connection reset", spans[0].Status().Description)
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+}
+
+func TestQueryTracer_Batch(t *testing.T) {
+	recorder := recordSpans(t)
+	tracer := newQueryTracer()
+
+	batch := &pgx.Batch{}
+	batch.Queue("INSERT INTO outbox_events (id) VALUES ($1)", 1)
+	batch.Queue("DELETE FROM outbox_events WHERE id = $1", 2)
+
+	ctx := tracer.TraceBatchStart(context.Background(), nil, pgx.TraceBatchStartData{Batch: batch})
+	tracer.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "INSERT INTO outbox_events (id) VALUES ($1)"})
+	tracer.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "DELETE FROM outbox_events WHERE id = $1", Err: errors.New("deadlock")})
+	tracer.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "batch", spans[0].Name())
+	assert.Equal(t, int64(2), spanAttribute(spans[0], "db.batch.size").AsInt64())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+
+	var names []string
+	for _, event := range spans[0].Events() {
+		names = append(names, event.Name)
+	}
+	assert.Equal(t, []string{"query", "query", "exception"}, names)
+}
+
+func TestQueryTracer_CopyFrom(t *testing.T) {
+	recorder := recordSpans(t)
+	tracer := newQueryTracer()
+
+	ctx := tracer.TraceCopyFromStart(context.Background(), nil, pgx.TraceCopyFromStartData{
+		TableName: pgx.Identifier{"public", "telemetry"},
+	})
+	tracer.TraceCopyFromEnd(ctx, nil, pgx.TraceCopyFromEndData{})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, `COPY "public"."telemetry"`, spans[0].Name())
+	assert.Equal(t, "public.telemetry", spanAttribute(spans[0], "db.collection.name").AsString())
+}
+
+func TestSpanName(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{sql: "select * from smart_models", want: "SELECT"},
+		{sql: "\n\t  INSERT INTO smart_models VALUES ($1)", want: "INSERT"},
+		{sql: "", want: "query"},
+		{sql: "   ", want: "query"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, spanName(tt.sql), tt.sql)
+	}
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is the subset of PgxPool and pgx.Tx that repositories need to run
+// statements, so the same code works inside and outside a transaction.
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+type txKey struct{}
+
+// ContextWithTx binds tx to ctx so repositories called with the returned
+// context take part in the transaction.
+func ContextWithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+// Conn returns the transaction bound to ctx, falling back to pool.
+func Conn(ctx context.Context, pool PgxPool) Querier {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return pool
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type fieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx carrying the given key/value pairs
+// in addition to those already attached. FromContext adds them to every log
+// line written with that context.
+func ContextWithFields(ctx context.Context, fields ...interface{}) context.Context {
+	existing, _ := ctx.Value(fieldsKey{}).([]interface{})
+	merged := make([]interface{}, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FromContext returns the global logger enriched with the request-scoped
+// fields attached to ctx and the trace and span IDs of the span it carries,
+// so log lines can be joined with traces.
+func FromContext(ctx context.Context) Logger {
+	l := GetLogger()
+
+	fields, _ := ctx.Value(fieldsKey{}).([]interface{})
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		fields = append(fields[:len(fields):len(fields)],
+			"trace_id", spanCtx.TraceID().String(),
+			"span_id", spanCtx.SpanID().String(),
+		)
+	}
+
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+)
+
+// observe replaces the global logger with one that records every entry.
+func observe(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	previous := globalLogger
+	globalLogger = &zapLogger{log: zap.New(core)}
+	t.Cleanup(func() { globalLogger = previous })
+	return logs
+}
+
+func TestFromContext_AddsTraceAndSpanIDs(t *testing.T) {
+	logs := observe(t)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	ctx = ContextWithFields(ctx, "request_id", "req-1")
+
+	FromContext(ctx).Info("handled")
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, map[string]interface{}{
+		"request_id": "req-1",
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":    "00f067aa0ba902b7",
+	}, logs.All()[0].ContextMap())
+}
+
+func TestFromContext_WithoutSpan(t *testing.T) {
+	logs := observe(t)
+
+	FromContext(ContextWithFields(context.Background(), "request_id", "req-1")).Info("handled")
+	FromContext(context.Background()).Info("plain")
+
+	require.Equal(t, 2, logs.Len())
+	assert.Equal(t, map[string]interface{}{"request_id": "req-1"}, logs.All()[0].ContextMap())
+	assert.Empty(t, logs.All()[1].ContextMap())
+}
+
+func TestContextWithFields_DoesNotShareFields(t *testing.T) {
+	logs := observe(t)
+
+	parent := ContextWithFields(context.Background(), "request_id", "req-1")
+	FromContext(ContextWithFields(parent, "step", "a")).Info("first")
+	FromContext(ContextWithFields(parent, "step", "b")).Info("second")
+	FromContext(parent).Info("parent")
+
+	require.Equal(t, 3, logs.Len())
+	assert.Equal(t, "a", logs.All()[0].ContextMap()["step"])
+	assert.Equal(t, "b", logs.All()[1].ContextMap()["step"])
+	assert.NotContains(t, logs.All()[2].ContextMap(), "step")
+}
@@ -4,9 +4,15 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"smart-hub/config"
+	"strings"
 	"sync"
 )
 
+const (
+	redactedValue = "[REDACTED]"
+	badKey        = "!BADKEY"
+)
+
 type zapLogger struct {
 	log *zap.Logger
 }
@@ -14,6 +20,12 @@ type zapLogger struct {
 var (
 	globalLogger Logger
 	once         sync.Once
+
+	defaultRedactKeys = []string{
+		"password", "secret", "token", "authorization", "api_key", "apikey", "dsn",
+		"access_token", "refresh_token", "client_secret",
+	}
+	redactKeys = defaultRedactKeys
 )
 
 func InitLogger(cfg *config.LogConfig) error {
@@ -28,6 +40,8 @@ func InitLogger(cfg *config.LogConfig) error {
 		zapConfig.Level.SetLevel(level)
 		zapConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
+		redactKeys = withDefaultRedactKeys(cfg.RedactKeys)
+
 		logger, err := zapConfig.Build()
 		if err != nil {
 			panic(err)
@@ -50,21 +64,64 @@ func GetLogger() Logger {
 	return globalLogger
 }
 
-func toZapField(field interface{}) zap.Field {
-	switch f := field.(type) {
-	case error:
-		return zap.Error(f)
-	case zap.Field:
-		return f
-	default:
-		return zap.Any("field", f)
+func withDefaultRedactKeys(extra []string) []string {
+	if len(extra) == 0 {
+		return defaultRedactKeys
+	}
+	return append(append([]string{}, defaultRedactKeys...), extra...)
+}
+
+// isSensitive reports whether values logged under key must be redacted. Keys
+// match whole and ignoring case, so "token" hides "token" and "grpc.token"
+// (a dotted key is matched by its last part) but not "resume_token", which is
+// a cursor rather than a credential.
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		key = key[i+1:]
+	}
+	for _, k := range redactKeys {
+		if key == strings.ToLower(k) {
+			return true
+		}
+	}
+	return false
+}
+
+func keyValueField(key string, value interface{}) zap.Field {
+	if isSensitive(key) {
+		return zap.String(key, redactedValue)
+	}
+	if err, ok := value.(error); ok {
+		return zap.NamedError(key, err)
 	}
+	return zap.Any(key, value)
 }
 
+// convertFields turns alternating key/value pairs into zap fields. Errors and
+// zap.Fields are accepted without a key; a value whose key is not a string is
+// logged under "!BADKEY" instead of being dropped.
 func convertFields(fields ...interface{}) []zap.Field {
-	zapFields := make([]zap.Field, len(fields))
-	for i, field := range fields {
-		zapFields[i] = toZapField(field)
+	zapFields := make([]zap.Field, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		switch f := fields[i].(type) {
+		case zap.Field:
+			if isSensitive(f.Key) {
+				f = zap.String(f.Key, redactedValue)
+			}
+			zapFields = append(zapFields, f)
+		case error:
+			zapFields = append(zapFields, zap.Error(f))
+		case string:
+			if i+1 >= len(fields) {
+				zapFields = append(zapFields, zap.String(badKey, f))
+				continue
+			}
+			zapFields = append(zapFields, keyValueField(f, fields[i+1]))
+			i++
+		default:
+			zapFields = append(zapFields, zap.Any(badKey, f))
+		}
 	}
 	return zapFields
 }
@@ -85,6 +142,12 @@ func (l *zapLogger) Error(message string, fields ...interface{}) {
 	l.log.Error(message, convertFields(fields...)...)
 }
 
+func (l *zapLogger) With(fields ...interface{}) Logger {
+	return &zapLogger{
+		log: l.log.With(convertFields(fields...)...),
+	}
+}
+
 func Debug(message string, fields ...interface{}) {
 	GetLogger().Debug(message, fields...)
 }
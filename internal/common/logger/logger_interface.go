@@ -1,8 +1,12 @@
 package logger
 
+// Logger accepts alternating key/value pairs after the message, e.g.
+// Info("model created", "id", id). An error or zap.Field may also be passed
+// on its own without a key.
 type Logger interface {
 	Debug(message string, fields ...interface{})
 	Info(message string, fields ...interface{})
 	Warn(message string, fields ...interface{})
 	Error(message string, fields ...interface{})
+	With(fields ...interface{}) Logger
 }
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestConvertFields(t *testing.T) {
+	err := errors.New("boom")
+
+	tests := []struct {
+		name   string
+		fields []interface{}
+		want   []zap.Field
+	}{
+		{name: "none", fields: nil, want: []zap.Field{}},
+		{
+			name:   "key value pairs",
+			fields: []interface{}{"id", 7, "name", "lamp"},
+			want:   []zap.Field{zap.Any("id", 7), zap.Any("name", "lamp")},
+		},
+		{
+			name:   "odd count",
+			fields: []interface{}{"id", 7, "dangling"},
+			want:   []zap.Field{zap.Any("id", 7), zap.String(badKey, "dangling")},
+		},
+		{
+			name:   "key that is not a string",
+			fields: []interface{}{42, "id", 7},
+			want:   []zap.Field{zap.Any(badKey, 42), zap.Any("id", 7)},
+		},
+		{
+			name:   "bare error",
+			fields: []interface{}{"id", 7, err},
+			want:   []zap.Field{zap.Any("id", 7), zap.Error(err)},
+		},
+		{
+			name:   "error under a key",
+			fields: []interface{}{"cause", err},
+			want:   []zap.Field{zap.NamedError("cause", err)},
+		},
+		{
+			name:   "zap field",
+			fields: []interface{}{zap.Int("count", 3), "id", 7},
+			want:   []zap.Field{zap.Int("count", 3), zap.Any("id", 7)},
+		},
+		{
+			name:   "sensitive value",
+			fields: []interface{}{"password", "hunter2", "resume_token", "42"},
+			want:   []zap.Field{zap.String("password", redactedValue), zap.Any("resume_token", "42")},
+		},
+		{
+			name:   "sensitive zap field",
+			fields: []interface{}{zap.String("Authorization", "Bearer abc")},
+			want:   []zap.Field{zap.String("Authorization", redactedValue)},
+		},
+		{
+			name:   "sensitive error",
+			fields: []interface{}{"dsn", errors.New("postgres://user:pw@host")},
+			want:   []zap.Field{zap.String("dsn", redactedValue)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, convertFields(tt.fields...))
+		})
+	}
+}
+
+func TestIsSensitive(t *testing.T) {
+	tests := []struct {
+		key       string
+		sensitive bool
+	}{
+		{key: "password", sensitive: true},
+		{key: "Password", sensitive: true},
+		{key: "token", sensitive: true},
+		{key: "api_key", sensitive: true},
+		{key: "access_token", sensitive: true},
+		{key: "db.password", sensitive: true},
+		{key: "grpc.authorization", sensitive: true},
+		{key: "resume_token", sensitive: false},
+		{key: "page_token", sensitive: false},
+		{key: "passwords_checked", sensitive: false},
+		{key: "secretary", sensitive: false},
+		{key: "id", sensitive: false},
+		{key: "", sensitive: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.sensitive, isSensitive(tt.key), tt.key)
+	}
+}
+
+func TestRedactKeys(t *testing.T) {
+	previous := redactKeys
+	t.Cleanup(func() { redactKeys = previous })
+
+	assert.Equal(t, defaultRedactKeys, withDefaultRedactKeys(nil))
+
+	redactKeys = withDefaultRedactKeys([]string{"Serial_Number", "pin"})
+	assert.True(t, isSensitive("serial_number"), "configured keys match ignoring case")
+	assert.True(t, isSensitive("device.pin"))
+	assert.True(t, isSensitive("password"), "the defaults still apply")
+	assert.False(t, isSensitive("pinned"))
+	assert.Equal(t, []string{"password", "secret"}, defaultRedactKeys[:2], "the defaults are not modified")
+}
+
+func TestZapLogger_RedactsWithFields(t *testing.T) {
+	logs := observe(t)
+
+	GetLogger().With("token", "abc", "model_id", "m-1").Warn("refreshed", "secret", "xyz")
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, map[string]interface{}{
+		"token":    redactedValue,
+		"model_id": "m-1",
+		"secret":   redactedValue,
+	}, logs.All()[0].ContextMap())
+}
@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"smart-hub/config"
+	"testing"
+)
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+		want     interface{}
+		wantErr  string
+	}{
+		{name: "empty disables tracing", exporter: ""},
+		{name: "none disables tracing", exporter: ExporterNone},
+		{name: "stdout", exporter: ExporterStdout, want: &stdouttrace.Exporter{}},
+		{name: "case insensitive", exporter: "STDOUT", want: &stdouttrace.Exporter{}},
+		{name: "otlp", exporter: ExporterOTLP, want: &otlptrace.Exporter{}},
+		{name: "unknown", exporter: "zipkin", wantErr: `unknown tracing exporter "zipkin"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter, err := newExporter(context.Background(), &config.TracingConfig{
+				Exporter: tt.exporter,
+				Endpoint: "localhost:4317",
+				Insecure: true,
+			})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.Nil(t, exporter)
+				return
+			}
+			assert.IsType(t, tt.want, exporter)
+			assert.NoError(t, exporter.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestInitTracer_None(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	provider := otel.GetTracerProvider()
+
+	shutdown, err := InitTracer(context.Background(), &config.TracingConfig{Exporter: ExporterNone}, "smart-hub")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	// Incoming trace context is still propagated, but no provider is installed.
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, otel.GetTextMapPropagator().Fields())
+	assert.Equal(t, provider, otel.GetTracerProvider())
+}
+
+func TestInitTracer_Stdout(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(provider)
+
+	shutdown, err := InitTracer(context.Background(), &config.TracingConfig{Exporter: ExporterStdout, SampleRatio: 1}, "smart-hub")
+	require.NoError(t, err)
+	assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestInitTracer_UnknownExporter(t *testing.T) {
+	shutdown, err := InitTracer(context.Background(), &config.TracingConfig{Exporter: "zipkin"}, "smart-hub")
+	assert.Error(t, err)
+	assert.Nil(t, shutdown)
+}
+
+func TestNewMetricExporter(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+		wantNil  bool
+		wantErr  string
+	}{
+		{name: "empty disables metrics", exporter: "", wantNil: true},
+		{name: "none disables metrics", exporter: ExporterNone, wantNil: true},
+		{name: "otlp", exporter: ExporterOTLP},
+		{name: "case insensitive", exporter: "OTLP"},
+		{name: "stdout is not supported", exporter: ExporterStdout, wantErr: `unknown metrics exporter "stdout"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter, err := newMetricExporter(context.Background(), &config.MetricsConfig{
+				Exporter: tt.exporter,
+				Endpoint: "localhost:4317",
+				Insecure: true,
+			})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, exporter)
+				return
+			}
+			assert.IsType(t, &otlpmetricgrpc.Exporter{}, exporter)
+			assert.NoError(t, exporter.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestInitMeter_None(t *testing.T) {
+	provider := otel.GetMeterProvider()
+
+	shutdown, err := InitMeter(context.Background(), &config.MetricsConfig{Exporter: ExporterNone}, "smart-hub")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+	assert.Equal(t, provider, otel.GetMeterProvider())
+}
+
+func TestInitMeter_UnknownExporter(t *testing.T) {
+	shutdown, err := InitMeter(context.Background(), &config.MetricsConfig{Exporter: "prometheus"}, "smart-hub")
+	assert.Error(t, err)
+	assert.Nil(t, shutdown)
+}
+
+func TestInitTracer_DoesNotInstallMeterProvider(t *testing.T) {
+	tracerProvider := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(tracerProvider)
+	meterProvider := otel.GetMeterProvider()
+
+	shutdown, err := InitTracer(context.Background(), &config.TracingConfig{Exporter: ExporterOTLP, Endpoint: "localhost:4317", Insecure: true}, "smart-hub")
+	require.NoError(t, err)
+	assert.Equal(t, meterProvider, otel.GetMeterProvider())
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestRecordError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	_, ok := tracer.Start(context.Background(), "ok")
+	RecordError(ok, nil)
+	ok.End()
+
+	_, failed := tracer.Start(context.Background(), "failed")
+	RecordError(failed, errors.New("boom"))
+	failed.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Empty(t, spans[0].Events())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "boom", spans[1].Status().Description)
+	require.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, "exception", spans[1].Events()[0].Name)
+}
@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AutomationRepository interface {
+	CreateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error)
+	GetRule(ctx context.Context, id uuid.UUID) (*models.AutomationRule, error)
+	// ListRules returns every rule, ordered by creation time and ID.
+	ListRules(ctx context.Context) ([]*models.AutomationRule, error)
+	UpdateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error)
+	// DeleteRule removes a rule together with its executions.
+	DeleteRule(ctx context.Context, id uuid.UUID) error
+
+	// CreateExecution stores a new execution. It fails with
+	// ErrAlreadyExists when another execution has the same non-empty dedup
+	// key, and with ErrNotFound when the rule does not exist.
+	CreateExecution(ctx context.Context, execution *models.AutomationExecution) error
+	// FinishExecution stores the status, error, results and finish time of
+	// an execution.
+	FinishExecution(ctx context.Context, execution *models.AutomationExecution) error
+	// ListExecutions returns the executions of filter ordered by start time
+	// and ID, newest first.
+	ListExecutions(ctx context.Context, filter models.ExecutionFilter) ([]*models.AutomationExecution, error)
+	PruneExecutions(ctx context.Context, before time.Time) (int64, error)
+}
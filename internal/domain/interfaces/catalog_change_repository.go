@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type CatalogChangeRepository interface {
+	// ListSince returns up to limit changes with a sequence greater than
+	// after, ordered by sequence.
+	ListSince(ctx context.Context, after int64, limit int) ([]*models.CatalogChange, error)
+	LatestSequence(ctx context.Context) (int64, error)
+	OldestSequence(ctx context.Context) (int64, error)
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ChangeListener calls onChange whenever the catalog change log may have
+// grown. Listen blocks until ctx is done, reconnecting as needed.
+type ChangeListener interface {
+	Listen(ctx context.Context, onChange func()) error
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+)
+
+// EventPublisher delivers domain events to a sink. Publish must only return
+// nil once the sink has accepted the event.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *models.DomainEvent) error
+	Close() error
+}
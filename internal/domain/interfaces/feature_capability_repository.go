@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type FeatureCapabilityRepository interface {
+	// SetFeatureCapability stores the capability a feature implements,
+	// replacing the one it implemented before. It fails with ErrNotFound
+	// when the feature does not exist.
+	SetFeatureCapability(ctx context.Context, capability *models.FeatureCapability) error
+	// GetFeatureCapability fails with ErrNotFound when the feature
+	// implements no capability.
+	GetFeatureCapability(ctx context.Context, featureID uuid.UUID) (*models.FeatureCapability, error)
+	// DeleteFeatureCapability fails with ErrNotFound when the feature
+	// implements no capability.
+	DeleteFeatureCapability(ctx context.Context, featureID uuid.UUID) error
+	// ListByCapability returns the features implementing capability,
+	// ordered by model and feature ID.
+	ListByCapability(ctx context.Context, capability string) ([]*models.FeatureCapability, error)
+}
@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type FeatureDependencyRepository interface {
+	// SetDependencies replaces every dependency declared by featureID with
+	// dependencies, which must all have featureID as their FeatureID. It
+	// fails with ErrNotFound when a feature does not exist.
+	SetDependencies(ctx context.Context, featureID uuid.UUID, dependencies []models.FeatureDependency) error
+	// ListDependencies returns the dependencies declared by featureID,
+	// ordered by kind and the ID they depend on.
+	ListDependencies(ctx context.Context, featureID uuid.UUID) ([]models.FeatureDependency, error)
+	// ListModelDependencies returns the dependencies declared by every
+	// feature of a model, ordered by feature, kind and the ID they depend
+	// on.
+	ListModelDependencies(ctx context.Context, modelID uuid.UUID) ([]models.FeatureDependency, error)
+	// LockModelDependencies holds the surrounding unit of work back until no
+	// other one holds the lock of modelID, and keeps the lock until it ends.
+	// Changing dependencies takes it before checking the dependencies of the
+	// whole model, so two concurrent changes cannot together form a cycle or
+	// a conflict.
+	LockModelDependencies(ctx context.Context, modelID uuid.UUID) error
+}
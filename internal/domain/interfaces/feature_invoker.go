@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// FeatureInvoker asks a device to run one of its smart features with
+// parameters.
+type FeatureInvoker interface {
+	InvokeFeature(ctx context.Context, deviceID string, featureID uuid.UUID, parameters map[string]interface{}) error
+}
+
+// ReadingObserver is told about telemetry readings once they are stored.
+// ObserveReadings must not block.
+type ReadingObserver interface {
+	ObserveReadings(readings []*models.TelemetryReading)
+}
+
+// StateObserver is told about each change of the reported state of a
+// device shadow once it is committed. ObserveReportedState must not block.
+type StateObserver interface {
+	ObserveReportedState(deviceID string, previous, current map[string]interface{})
+}
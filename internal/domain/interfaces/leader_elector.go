@@ -0,0 +1,11 @@
+package interfaces
+
+import "context"
+
+// LeaderElector lets one replica at a time do work that must not run
+// concurrently. Lead blocks until ctx is done: whenever this replica becomes
+// the leader it calls fn with a context that is cancelled when leadership is
+// lost, and waits for fn to return before it gives leadership up.
+type LeaderElector interface {
+	Lead(ctx context.Context, fn func(ctx context.Context)) error
+}
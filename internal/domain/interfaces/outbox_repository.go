@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type OutboxRepository interface {
+	Add(ctx context.Context, events ...*models.DomainEvent) error
+	// FetchPending locks the outbox for the surrounding unit of work and
+	// returns up to limit events that are neither published nor dead, in
+	// sequence order. Events claimed by a relay are included with their
+	// ClaimedUntil. It returns nothing while another relay holds the outbox.
+	FetchPending(ctx context.Context, limit int) ([]*models.OutboxEvent, error)
+	// Claim reserves events for the calling relay until the given time, so
+	// that other relays skip them while they are published.
+	Claim(ctx context.Context, sequences []int64, until time.Time) error
+	// Release drops the claim on events that were not published.
+	Release(ctx context.Context, sequences []int64) error
+	MarkPublished(ctx context.Context, sequences []int64) error
+	// MarkFailed records a failed attempt and drops the claim on the event.
+	// A dead event is given up and no longer fetched.
+	MarkFailed(ctx context.Context, sequence int64, reason string, dead bool) error
+}
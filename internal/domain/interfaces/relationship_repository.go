@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type RelationshipRepository interface {
+	// CreateRelationship stores a new relationship. It fails with
+	// ErrAlreadyExists when the models already have a relationship of that
+	// type, and with ErrNotFound when either model does not exist.
+	CreateRelationship(ctx context.Context, relationship *models.ModelRelationship) (*models.ModelRelationship, error)
+	GetRelationship(ctx context.Context, id uuid.UUID) (*models.ModelRelationship, error)
+	DeleteRelationship(ctx context.Context, id uuid.UUID) error
+	// ListRelationships returns the relationships of filter, ordered by
+	// creation time and ID.
+	ListRelationships(ctx context.Context, filter models.RelationshipFilter) ([]*models.ModelRelationship, error)
+	// RelatedModels returns the models reachable by query, each once with
+	// its smallest depth, ordered by depth and ID. The start model is never
+	// part of the result. query.MaxDepth must be positive.
+	RelatedModels(ctx context.Context, query models.RelationshipQuery) ([]*models.RelatedModel, error)
+	// LockRelationshipType holds the surrounding unit of work back until no
+	// other one holds the lock of relationshipType, and keeps the lock until
+	// it ends. Creating an acyclic relationship takes it before looking for
+	// a cycle, so two concurrent creations cannot close one between them.
+	LockRelationshipType(ctx context.Context, relationshipType models.RelationshipType) error
+}
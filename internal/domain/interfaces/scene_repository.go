@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type SceneRepository interface {
+	CreateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error)
+	GetScene(ctx context.Context, id uuid.UUID) (*models.Scene, error)
+	// ListScenes returns every scene, ordered by creation time and ID.
+	ListScenes(ctx context.Context) ([]*models.Scene, error)
+	UpdateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error)
+	DeleteScene(ctx context.Context, id uuid.UUID) error
+}
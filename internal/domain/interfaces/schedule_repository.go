@@ -0,0 +1,38 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScheduleRepository interface {
+	CreateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error)
+	GetSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error)
+	// ListSchedules returns every schedule, ordered by creation time and ID.
+	ListSchedules(ctx context.Context) ([]*models.Schedule, error)
+	// UpdateSchedule stores every field of a schedule but its creation time.
+	UpdateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error)
+	// DeleteSchedule removes a schedule together with its runs.
+	DeleteSchedule(ctx context.Context, id uuid.UUID) error
+	// ListDueSchedules returns up to limit schedules that are not paused and
+	// due at now, the longest overdue first.
+	ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]*models.Schedule, error)
+	// AdvanceSchedule moves a schedule that is due at due on to next and
+	// records due as its last run. It fails with ErrVersionConflict when the
+	// schedule is paused or no longer due at due, and with ErrNotFound when
+	// it does not exist.
+	AdvanceSchedule(ctx context.Context, id uuid.UUID, due, next time.Time) error
+
+	// CreateRun stores a new run. It fails with ErrNotFound when the schedule
+	// does not exist.
+	CreateRun(ctx context.Context, run *models.ScheduleRun) error
+	// FinishRun stores the status, error and finish time of a run.
+	FinishRun(ctx context.Context, run *models.ScheduleRun) error
+	// ListRuns returns the runs of filter ordered by start time and ID,
+	// newest first.
+	ListRuns(ctx context.Context, filter models.ScheduleRunFilter) ([]*models.ScheduleRun, error)
+	PruneRuns(ctx context.Context, before time.Time) (int64, error)
+}
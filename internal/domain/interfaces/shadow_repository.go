@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type ShadowRepository interface {
+	GetShadow(ctx context.Context, deviceID string) (*models.DeviceShadow, error)
+	// ListShadows returns the shadows of deviceIDs, or every shadow when
+	// deviceIDs is empty, ordered by device ID.
+	ListShadows(ctx context.Context, deviceIDs []string) ([]*models.DeviceShadow, error)
+	// SaveShadow stores shadow if the stored one is at expectedVersion, 0
+	// meaning that there is none yet, and fails with ErrVersionConflict
+	// otherwise.
+	SaveShadow(ctx context.Context, shadow *models.DeviceShadow, expectedVersion int64) error
+	DeleteShadow(ctx context.Context, deviceID string) error
+
+	// AddDelta appends to the delta log and sets delta.Sequence.
+	AddDelta(ctx context.Context, delta *models.ShadowDelta) error
+	// ListDeltasSince returns up to limit deltas with a sequence greater
+	// than after, ordered by sequence.
+	ListDeltasSince(ctx context.Context, after int64, limit int) ([]*models.ShadowDelta, error)
+	LatestDeltaSequence(ctx context.Context) (int64, error)
+	OldestDeltaSequence(ctx context.Context) (int64, error)
+	PruneDeltas(ctx context.Context, before time.Time) (int64, error)
+}
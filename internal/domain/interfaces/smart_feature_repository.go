@@ -9,7 +9,24 @@ type SmartFeatureRepository interface {
 	Create(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error)
 	GetByID(ctx context.Context, id string) (*models.SmartFeature, error)
 	GetWithModelID(ctx context.Context, modelID string) ([]*models.SmartFeature, error)
+	// GetWithModelIDs returns the features of all the given models in one
+	// query, ordered like GetWithModelID.
+	GetWithModelIDs(ctx context.Context, modelIDs []string) ([]*models.SmartFeature, error)
 	GetAll(ctx context.Context) ([]*models.SmartFeature, error)
+	// List returns up to limit features that match filter, ordered by
+	// creation time and ID and starting after the cursor, if any.
+	List(ctx context.Context, filter models.FeatureFilter, after *models.PageCursor, limit int) ([]*models.SmartFeature, error)
 	Update(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error)
 	Delete(ctx context.Context, id string) error
+
+	// GetByIDs returns the features among ids that exist, in no particular
+	// order.
+	GetByIDs(ctx context.Context, ids []string) ([]*models.SmartFeature, error)
+	// CreateBatch, UpdateBatch and DeleteBatch write all features or none.
+	// They fail with ErrNotFound when a model or feature does not exist.
+	// CreateBatch and UpdateBatch report the failed feature with a
+	// *models.BatchItemError indexed into features.
+	CreateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error)
+	UpdateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error)
+	DeleteBatch(ctx context.Context, ids []string) error
 }
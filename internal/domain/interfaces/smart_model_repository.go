@@ -8,6 +8,11 @@ import (
 type SmartModelRepository interface {
 	Create(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error)
 	GetByID(ctx context.Context, id string) (*models.SmartModel, error)
+	// GetByIDs returns the models among ids that exist, in no particular
+	// order.
+	GetByIDs(ctx context.Context, ids []string) ([]*models.SmartModel, error)
+	// GetByModelNumber finds a model by manufacturer and model number.
+	GetByModelNumber(ctx context.Context, manufacturer, modelNumber string) (*models.SmartModel, error)
 	GetWithType(ctx context.Context, modelType models.ModelType) ([]*models.SmartModel, error)
 	GetAll(ctx context.Context) ([]*models.SmartModel, error)
 	Update(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error)
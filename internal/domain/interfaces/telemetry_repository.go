@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TelemetryRepository interface {
+	// AddReadings stores readings and returns how many of them were new. A
+	// reading with the device, feature and timestamp of one already stored
+	// is skipped.
+	AddReadings(ctx context.Context, readings []*models.TelemetryReading) (int, error)
+	// ListReadings returns up to query.Limit readings, ordered by timestamp
+	// and device.
+	ListReadings(ctx context.Context, query models.ReadingQuery) ([]*models.TelemetryReading, error)
+	// Downsample aggregates the readings into buckets of width bucket,
+	// aligned to the Unix epoch, and returns up to query.Limit non-empty
+	// buckets ordered by start.
+	Downsample(ctx context.Context, query models.ReadingQuery, bucket time.Duration) ([]*models.TelemetryBucket, error)
+	DeleteReadings(ctx context.Context, filter models.ReadingDeleteFilter) error
+
+	// SetRetentionPolicy creates or replaces the policy of a feature. It
+	// fails with ErrNotFound when the feature does not exist; policies go
+	// away with their feature.
+	SetRetentionPolicy(ctx context.Context, policy *models.RetentionPolicy) (*models.RetentionPolicy, error)
+	ListRetentionPolicies(ctx context.Context) ([]*models.RetentionPolicy, error)
+	DeleteRetentionPolicy(ctx context.Context, featureID uuid.UUID) error
+}
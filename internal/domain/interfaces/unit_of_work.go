@@ -0,0 +1,10 @@
+package interfaces
+
+import "context"
+
+// UnitOfWork runs fn in a single transaction. Repositories called with the
+// context passed to fn join that transaction; it is committed when fn returns
+// nil and rolled back otherwise. Nested calls join the outer transaction.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,34 @@
+package interfaces
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+
+	// EnqueueDeliveries stores new deliveries, ignoring ones that already
+	// exist for the same subscription and event.
+	EnqueueDeliveries(ctx context.Context, deliveries ...*models.WebhookDelivery) error
+	// ClaimDueDeliveries returns up to limit deliveries of active
+	// subscriptions that are due, and pushes their next attempt to leaseUntil
+	// so that other dispatchers skip them while they are being sent.
+	ClaimDueDeliveries(ctx context.Context, limit int, leaseUntil time.Time) ([]*models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, error)
+}
+
+// WebhookSender POSTs a delivery to the subscription URL and returns the
+// HTTP status code of the response, if any.
+type WebhookSender interface {
+	Send(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error)
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidAutomationRule is wrapped by the errors for malformed automation
+// rules.
+var ErrInvalidAutomationRule = errors.New("invalid automation rule")
+
+type TriggerType string
+
+const (
+	// TriggerTelemetry fires when a reading crosses a threshold.
+	TriggerTelemetry TriggerType = "telemetry"
+	// TriggerStateChange fires when a device reports a new value for a
+	// feature in its shadow.
+	TriggerStateChange TriggerType = "state_change"
+	// TriggerSchedule fires at a time of day in the rule's timezone.
+	TriggerSchedule TriggerType = "schedule"
+)
+
+type ThresholdOperator string
+
+const (
+	OperatorGreater      ThresholdOperator = "gt"
+	OperatorGreaterEqual ThresholdOperator = "gte"
+	OperatorLess         ThresholdOperator = "lt"
+	OperatorLessEqual    ThresholdOperator = "lte"
+	OperatorEqual        ThresholdOperator = "eq"
+	OperatorNotEqual     ThresholdOperator = "ne"
+)
+
+// Holds reports whether value compares to threshold with the operator. An
+// unknown operator never holds.
+func (o ThresholdOperator) Holds(value, threshold float64) bool {
+	switch o {
+	case OperatorGreater:
+		return value > threshold
+	case OperatorGreaterEqual:
+		return value >= threshold
+	case OperatorLess:
+		return value < threshold
+	case OperatorLessEqual:
+		return value <= threshold
+	case OperatorEqual:
+		return value == threshold
+	case OperatorNotEqual:
+		return value != threshold
+	}
+	return false
+}
+
+// RuleTrigger says when a rule is evaluated. FeatureID, DeviceID, Operator,
+// Threshold and Value belong to telemetry and state change triggers, At and
+// Weekdays to schedule triggers. An empty DeviceID matches every device.
+//
+// A telemetry trigger is edge-triggered: it fires on the first reading of a
+// device that satisfies the threshold and not again until a reading of that
+// device has not. A state change trigger fires when the reported value of
+// the feature changes, to Value only when Value is set. A schedule trigger
+// fires at At, "HH:MM", on Weekdays, or every day when Weekdays is empty.
+type RuleTrigger struct {
+	Type      TriggerType       `json:"type"`
+	FeatureID uuid.UUID         `json:"feature_id,omitempty"`
+	DeviceID  string            `json:"device_id,omitempty"`
+	Operator  ThresholdOperator `json:"operator,omitempty"`
+	Threshold float64           `json:"threshold,omitempty"`
+	Value     interface{}       `json:"value,omitempty"`
+	At        string            `json:"at,omitempty"`
+	Weekdays  []time.Weekday    `json:"weekdays,omitempty"`
+}
+
+// RuleAction invokes a smart feature of a device with parameters.
+type RuleAction struct {
+	DeviceID   string                 `json:"device_id"`
+	FeatureID  uuid.UUID              `json:"feature_id"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// AutomationRule runs its actions when its trigger fires and its condition,
+// if any, holds. Condition is an expression over device state, the time in
+// Timezone and the trigger. A rule does not fire again within Cooldown of
+// its last execution.
+type AutomationRule struct {
+	ID          uuid.UUID     `json:"id" db:"id"`
+	Name        string        `json:"name" db:"name"`
+	Description string        `json:"description,omitempty" db:"description"`
+	Enabled     bool          `json:"enabled" db:"enabled"`
+	Trigger     RuleTrigger   `json:"trigger" db:"trigger"`
+	Condition   string        `json:"condition,omitempty" db:"condition"`
+	Actions     []RuleAction  `json:"actions" db:"actions"`
+	Timezone    string        `json:"timezone" db:"timezone"`
+	Cooldown    time.Duration `json:"cooldown" db:"cooldown_seconds"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+type ExecutionStatus string
+
+const (
+	ExecutionRunning   ExecutionStatus = "running"
+	ExecutionSucceeded ExecutionStatus = "succeeded"
+	ExecutionFailed    ExecutionStatus = "failed"
+)
+
+// ActionResult is the outcome of one action of an execution; Error is empty
+// when it succeeded.
+type ActionResult struct {
+	DeviceID  string    `json:"device_id"`
+	FeatureID uuid.UUID `json:"feature_id"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// AutomationExecution records one firing of a rule. Trigger describes the
+// signal that fired it. DedupKey, when set, is unique across executions, so
+// replicas that see the same schedule tick run the rule once.
+type AutomationExecution struct {
+	ID         uuid.UUID              `json:"id" db:"id"`
+	RuleID     uuid.UUID              `json:"rule_id" db:"rule_id"`
+	Trigger    map[string]interface{} `json:"trigger" db:"trigger"`
+	DedupKey   string                 `json:"-" db:"dedup_key"`
+	Status     ExecutionStatus        `json:"status" db:"status"`
+	Error      string                 `json:"error,omitempty" db:"error"`
+	Results    []ActionResult         `json:"results,omitempty" db:"action_results"`
+	StartedAt  time.Time              `json:"started_at" db:"started_at"`
+	FinishedAt *time.Time             `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// ExecutionFilter selects up to Limit executions, of RuleID only when it is
+// set, that started before the After cursor, newest first.
+type ExecutionFilter struct {
+	RuleID *uuid.UUID
+	After  *PageCursor
+	Limit  int
+}
@@ -0,0 +1,18 @@
+package models
+
+import "fmt"
+
+// BatchItemError reports the item that failed an all-or-nothing batch.
+// Index is the 0-based position of the item in the request.
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
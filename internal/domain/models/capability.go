@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCapability is wrapped by the errors for unknown capabilities and
+// for parameter mappings that do not fit the capability schema.
+var ErrInvalidCapability = errors.New("invalid capability")
+
+type ParameterType string
+
+const (
+	ParameterBoolean ParameterType = "boolean"
+	ParameterInteger ParameterType = "integer"
+	ParameterNumber  ParameterType = "number"
+	ParameterString  ParameterType = "string"
+)
+
+// CapabilityParameter is a parameter of the canonical schema of a
+// capability. Minimum and Maximum bound numeric parameters when set, and
+// Values lists the allowed values of a string parameter when it is not
+// empty.
+type CapabilityParameter struct {
+	Name        string        `json:"name"`
+	Type        ParameterType `json:"type"`
+	Required    bool          `json:"required"`
+	Minimum     *float64      `json:"minimum,omitempty"`
+	Maximum     *float64      `json:"maximum,omitempty"`
+	Unit        string        `json:"unit,omitempty"`
+	Values      []string      `json:"values,omitempty"`
+	Description string        `json:"description"`
+}
+
+// Capability is a standard behaviour, such as switching on and off, that
+// features of different manufacturers implement under their own names.
+type Capability struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Parameters  []CapabilityParameter `json:"parameters"`
+}
+
+// Parameter returns the canonical parameter called name.
+func (c *Capability) Parameter(name string) (*CapabilityParameter, bool) {
+	for i := range c.Parameters {
+		if c.Parameters[i].Name == name {
+			return &c.Parameters[i], true
+		}
+	}
+	return nil, false
+}
+
+// FeatureCapability says that a feature implements a standard capability.
+// ParameterMapping maps canonical parameter names of the capability to the
+// names the feature uses for them. ModelID is the model of the feature;
+// repositories fill it in on reads and ignore it on writes.
+type FeatureCapability struct {
+	FeatureID        uuid.UUID         `json:"feature_id" db:"feature_id"`
+	ModelID          uuid.UUID         `json:"model_id" db:"model_id"`
+	Capability       string            `json:"capability" db:"capability"`
+	ParameterMapping map[string]string `json:"parameter_mapping" db:"parameter_mapping"`
+}
+
+// CapableModel is a model with the features that implement a capability.
+type CapableModel struct {
+	Model    *SmartModel
+	Features []*FeatureCapability
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+)
+
+// standardCapabilities is the capability library. Names are stable: they are
+// stored with the features that implement them.
+var standardCapabilities = []Capability{
+	{
+		Name:        "switch",
+		Description: "Turns the device on or off",
+		Parameters: []CapabilityParameter{
+			{Name: "on", Type: ParameterBoolean, Required: true, Description: "Whether the device is on"},
+		},
+	},
+	{
+		Name:        "brightness",
+		Description: "Dims a light or display",
+		Parameters: []CapabilityParameter{
+			{Name: "level", Type: ParameterInteger, Required: true, Minimum: bound(0), Maximum: bound(100), Unit: "%", Description: "Brightness"},
+			{Name: "transition", Type: ParameterInteger, Minimum: bound(0), Unit: "ms", Description: "How long the change takes"},
+		},
+	},
+	{
+		Name:        "color-temperature",
+		Description: "Sets the white point of a light",
+		Parameters: []CapabilityParameter{
+			{Name: "kelvin", Type: ParameterInteger, Required: true, Minimum: bound(1000), Maximum: bound(10000), Unit: "K", Description: "Color temperature"},
+		},
+	},
+	{
+		Name:        "color",
+		Description: "Sets the color of a light",
+		Parameters: []CapabilityParameter{
+			{Name: "hue", Type: ParameterNumber, Required: true, Minimum: bound(0), Maximum: bound(360), Unit: "°", Description: "Hue"},
+			{Name: "saturation", Type: ParameterNumber, Required: true, Minimum: bound(0), Maximum: bound(100), Unit: "%", Description: "Saturation"},
+		},
+	},
+	{
+		Name:        "temperature-sensor",
+		Description: "Reports the ambient temperature",
+		Parameters: []CapabilityParameter{
+			{Name: "temperature", Type: ParameterNumber, Required: true, Unit: "°C", Description: "Measured temperature"},
+		},
+	},
+	{
+		Name:        "humidity-sensor",
+		Description: "Reports the relative humidity",
+		Parameters: []CapabilityParameter{
+			{Name: "humidity", Type: ParameterNumber, Required: true, Minimum: bound(0), Maximum: bound(100), Unit: "%", Description: "Measured relative humidity"},
+		},
+	},
+	{
+		Name:        "motion-sensor",
+		Description: "Reports motion",
+		Parameters: []CapabilityParameter{
+			{Name: "motion", Type: ParameterBoolean, Required: true, Description: "Whether motion is detected"},
+		},
+	},
+	{
+		Name:        "contact-sensor",
+		Description: "Reports whether a door or window is open",
+		Parameters: []CapabilityParameter{
+			{Name: "open", Type: ParameterBoolean, Required: true, Description: "Whether the contact is open"},
+		},
+	},
+	{
+		Name:        "lock",
+		Description: "Locks and unlocks a door",
+		Parameters: []CapabilityParameter{
+			{Name: "locked", Type: ParameterBoolean, Required: true, Description: "Whether the lock is locked"},
+		},
+	},
+	{
+		Name:        "thermostat",
+		Description: "Controls heating and cooling",
+		Parameters: []CapabilityParameter{
+			{Name: "target_temperature", Type: ParameterNumber, Required: true, Minimum: bound(5), Maximum: bound(35), Unit: "°C", Description: "Temperature to reach"},
+			{Name: "mode", Type: ParameterString, Values: []string{"off", "heat", "cool", "auto"}, Description: "Operating mode"},
+		},
+	},
+	{
+		Name:        "volume",
+		Description: "Controls audio volume",
+		Parameters: []CapabilityParameter{
+			{Name: "level", Type: ParameterInteger, Required: true, Minimum: bound(0), Maximum: bound(100), Unit: "%", Description: "Volume"},
+			{Name: "muted", Type: ParameterBoolean, Description: "Whether audio is muted"},
+		},
+	},
+	{
+		Name:        "battery",
+		Description: "Reports the charge of a battery powered device",
+		Parameters: []CapabilityParameter{
+			{Name: "level", Type: ParameterInteger, Required: true, Minimum: bound(0), Maximum: bound(100), Unit: "%", Description: "Remaining charge"},
+		},
+	},
+}
+
+// StandardCapabilities returns the capability library, ordered by name.
+func StandardCapabilities() []Capability {
+	capabilities := slices.Clone(standardCapabilities)
+	slices.SortFunc(capabilities, func(a, b Capability) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return capabilities
+}
+
+// LookupCapability returns the standard capability called name.
+func LookupCapability(name string) (Capability, bool) {
+	for _, capability := range standardCapabilities {
+		if capability.Name == name {
+			return capability, true
+		}
+	}
+	return Capability{}, false
+}
+
+func bound(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,65 @@
+package models
+
+import "github.com/google/uuid"
+
+// CatalogEntry is a model with its features, the unit of catalog import and
+// export.
+type CatalogEntry struct {
+	Model    *SmartModel
+	Features []*SmartFeature
+}
+
+// ImportOptions controls a catalog import. With BatchSize 0 the whole import
+// runs in one transaction; otherwise every BatchSize rows commit on their
+// own. A dry run validates and reports without writing anything.
+type ImportOptions struct {
+	DryRun    bool
+	BatchSize int
+}
+
+type ImportRowStatus string
+
+const (
+	ImportCreated ImportRowStatus = "created"
+	ImportUpdated ImportRowStatus = "updated"
+	// ImportInvalid rows failed validation.
+	ImportInvalid ImportRowStatus = "invalid"
+	// ImportFailed rows were valid but could not be written.
+	ImportFailed ImportRowStatus = "failed"
+	// ImportRolledBack rows were fine on their own but share a transaction
+	// with a row that was invalid or failed.
+	ImportRolledBack ImportRowStatus = "rolled_back"
+)
+
+// ImportRowResult reports what happened to one imported entry. Row is the
+// 1-based position of the entry in the import.
+type ImportRowResult struct {
+	Row     int
+	Status  ImportRowStatus
+	ModelID uuid.UUID
+	Errors  []string
+}
+
+// ImportReport summarizes an import. In a dry run Created and Updated count
+// the rows that would have been written.
+type ImportReport struct {
+	DryRun     bool
+	Created    int
+	Updated    int
+	Failed     int
+	RolledBack int
+	Rows       []ImportRowResult
+}
+
+// ExportFilter narrows a catalog export; nil fields match everything.
+type ExportFilter struct {
+	Manufacturer *string
+	Type         *ModelType
+}
+
+func (f ExportFilter) Matches(model *SmartModel) bool {
+	if f.Manufacturer != nil && model.Manufacturer != *f.Manufacturer {
+		return false
+	}
+	return f.Type == nil || model.Type == *f.Type
+}
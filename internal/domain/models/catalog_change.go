@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ChangeOperation string
+
+const (
+	ChangeExisting ChangeOperation = "existing"
+	ChangeCreated  ChangeOperation = "created"
+	ChangeUpdated  ChangeOperation = "updated"
+	ChangeDeleted  ChangeOperation = "deleted"
+	ChangeSynced   ChangeOperation = "synced"
+)
+
+// CatalogChange is one row of the catalog change log written by database
+// triggers. Old and new snapshots are kept so watchers can tell when an
+// update moves an entity into or out of their filter.
+type CatalogChange struct {
+	Sequence   int64           `json:"sequence" db:"sequence"`
+	Entity     AggregateType   `json:"entity" db:"entity"`
+	Operation  ChangeOperation `json:"operation" db:"operation"`
+	EntityID   uuid.UUID       `json:"entity_id" db:"entity_id"`
+	ModelID    uuid.UUID       `json:"model_id" db:"model_id"`
+	OldModel   *SmartModel     `json:"old_model,omitempty"`
+	NewModel   *SmartModel     `json:"new_model,omitempty"`
+	OldFeature *SmartFeature   `json:"old_feature,omitempty"`
+	NewFeature *SmartFeature   `json:"new_feature,omitempty"`
+	ChangedAt  time.Time       `json:"changed_at" db:"changed_at"`
+}
+
+// WatchEvent is what a watcher receives: the change as seen through its
+// filter, and the token to resume from after it.
+type WatchEvent struct {
+	Operation   ChangeOperation
+	ResumeToken string
+	Model       *SmartModel
+	Feature     *SmartFeature
+}
+
+type ModelWatchFilter struct {
+	Type     *ModelType
+	Category *ModelCategory
+}
+
+func (f ModelWatchFilter) Matches(model *SmartModel) bool {
+	if model == nil {
+		return false
+	}
+	if f.Type != nil && model.Type != *f.Type {
+		return false
+	}
+	if f.Category != nil && model.Category != *f.Category {
+		return false
+	}
+	return true
+}
+
+// FeatureWatchFilter narrows a feature watch by the feature's model: by its
+// ID, or by its type and category.
+type FeatureWatchFilter struct {
+	ModelID       *uuid.UUID
+	ModelType     *ModelType
+	ModelCategory *ModelCategory
+}
+
+// FiltersModel reports whether matching needs the feature's model.
+func (f FeatureWatchFilter) FiltersModel() bool {
+	return f.ModelType != nil || f.ModelCategory != nil
+}
+
+// Matches reports whether feature, which belongs to model, passes the
+// filter. model may be nil when the filter does not need it.
+func (f FeatureWatchFilter) Matches(feature *SmartFeature, model *SmartModel) bool {
+	if feature == nil {
+		return false
+	}
+	if f.ModelID != nil && feature.ModelID != *f.ModelID {
+		return false
+	}
+	if !f.FiltersModel() {
+		return true
+	}
+	return model != nil && ModelWatchFilter{Type: f.ModelType, Category: f.ModelCategory}.Matches(model)
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AggregateType string
+type EventType string
+
+const (
+	SmartModelAggregate   AggregateType = "smart_model"
+	SmartFeatureAggregate AggregateType = "smart_feature"
+
+	ModelCreatedEvent   EventType = "model.created"
+	ModelUpdatedEvent   EventType = "model.updated"
+	ModelDeletedEvent   EventType = "model.deleted"
+	FeatureCreatedEvent EventType = "feature.created"
+	FeatureUpdatedEvent EventType = "feature.updated"
+	FeatureDeletedEvent EventType = "feature.deleted"
+)
+
+// DomainEvent records a change to a single aggregate. Payload holds the JSON
+// snapshot of the aggregate after the change (only the ID for deletions).
+type DomainEvent struct {
+	ID            uuid.UUID       `json:"id" db:"id"`
+	AggregateType AggregateType   `json:"aggregate_type" db:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id" db:"aggregate_id"`
+	Type          EventType       `json:"type" db:"event_type"`
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at" db:"occurred_at"`
+}
+
+// OutboxEvent is a DomainEvent waiting in the outbox. Sequence gives the
+// global write order used to keep events of one aggregate in order.
+// ClaimedUntil is set while a relay publishes the event, and DeadAt once the
+// relay gave up on it.
+type OutboxEvent struct {
+	DomainEvent
+	Sequence     int64      `json:"sequence" db:"sequence"`
+	Attempts     int        `json:"attempts" db:"attempts"`
+	LastError    string     `json:"last_error,omitempty" db:"last_error"`
+	ClaimedUntil *time.Time `json:"claimed_until,omitempty" db:"claimed_until"`
+	PublishedAt  *time.Time `json:"published_at,omitempty" db:"published_at"`
+	DeadAt       *time.Time `json:"dead_at,omitempty" db:"dead_at"`
+}
+
+func NewDomainEvent(aggregateType AggregateType, aggregateID uuid.UUID, eventType EventType, payload interface{}) (*DomainEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DomainEvent{
+		ID:            uuid.New(),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Type:          eventType,
+		Payload:       data,
+		OccurredAt:    time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is matched by every *AlreadyExistsError.
+	ErrAlreadyExists = errors.New("already exists")
+
+	// ErrSlowConsumer is returned to a watcher that fell too far behind the
+	// change stream. It can reconnect with its last resume token.
+	ErrSlowConsumer = errors.New("watcher fell behind the change stream")
+	// ErrResumeTokenExpired means the changes after a resume token have
+	// already been pruned; the watcher has to start from a fresh snapshot.
+	ErrResumeTokenExpired = errors.New("resume token expired")
+	ErrInvalidResumeToken = errors.New("invalid resume token")
+	// ErrWatchStopped ends open watches when the server shuts down.
+	ErrWatchStopped = errors.New("watch service stopped")
+
+	ErrInvalidPageToken = errors.New("invalid page token")
+
+	// ErrVersionConflict rejects a write made against a version of a record
+	// that is no longer current.
+	ErrVersionConflict = errors.New("version conflict")
+)
+
+// AlreadyExistsError reports a write that collides with another smart model
+// or feature on a unique key: a model's manufacturer and model number, or a
+// feature's name and interface path within its model. ID is the entity it
+// collides with; it is uuid.Nil when the storage backend could not tell.
+type AlreadyExistsError struct {
+	Entity AggregateType
+	ID     uuid.UUID
+}
+
+func (e *AlreadyExistsError) Error() string {
+	if e.ID == uuid.Nil {
+		return fmt.Sprintf("%s already exists", e.Entity)
+	}
+	return fmt.Sprintf("%s already exists: %s", e.Entity, e.ID)
+}
+
+func (e *AlreadyExistsError) Is(target error) bool {
+	return target == ErrAlreadyExists
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidFeatureDependency is wrapped by the errors for dependency
+// declarations that are malformed or inconsistent, and for feature sets
+// that cannot be resolved.
+var ErrInvalidFeatureDependency = errors.New("invalid feature dependency")
+
+// ErrFeatureRequired is wrapped by the error for deleting a feature that
+// other features of its model still require.
+var ErrFeatureRequired = errors.New("feature is required by other features")
+
+type DependencyKind string
+
+const (
+	// DependencyRequires says a feature only works when the other feature is
+	// enabled too, e.g. night vision needs infrared.
+	DependencyRequires DependencyKind = "requires"
+	// DependencyConflicts says a feature cannot be enabled together with the
+	// other feature.
+	DependencyConflicts DependencyKind = "conflicts"
+)
+
+// FeatureDependency is one edge of a dependency declaration: FeatureID
+// requires or conflicts with DependsOnID, a feature of the same model.
+type FeatureDependency struct {
+	FeatureID   uuid.UUID      `json:"feature_id" db:"feature_id"`
+	DependsOnID uuid.UUID      `json:"depends_on_id" db:"depends_on_id"`
+	Kind        DependencyKind `json:"kind" db:"kind"`
+}
+
+// FeatureDependencies is everything a feature declares about the other
+// features of its model.
+type FeatureDependencies struct {
+	FeatureID uuid.UUID   `json:"feature_id"`
+	Requires  []uuid.UUID `json:"requires"`
+	Conflicts []uuid.UUID `json:"conflicts"`
+}
+
+// Edges flattens d into the edges repositories store.
+func (d *FeatureDependencies) Edges() []FeatureDependency {
+	edges := make([]FeatureDependency, 0, len(d.Requires)+len(d.Conflicts))
+	for _, id := range d.Requires {
+		edges = append(edges, FeatureDependency{FeatureID: d.FeatureID, DependsOnID: id, Kind: DependencyRequires})
+	}
+	for _, id := range d.Conflicts {
+		edges = append(edges, FeatureDependency{FeatureID: d.FeatureID, DependsOnID: id, Kind: DependencyConflicts})
+	}
+	return edges
+}
+
+// ResolvedFeature is a member of a resolved feature set. Requested is false
+// for features that were only added because another member requires them.
+type ResolvedFeature struct {
+	Feature   *SmartFeature
+	Requested bool
+}
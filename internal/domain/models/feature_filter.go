@@ -0,0 +1,51 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FeatureFilter narrows a feature listing; zero fields match everything.
+type FeatureFilter struct {
+	Protocol      *ProtocolType
+	ModelType     *ModelType
+	ModelCategory *ModelCategory
+	// NamePrefix matches feature names that start with it, case-sensitively.
+	NamePrefix string
+	// InterfacePathPattern is a glob over the interface path: * matches any
+	// run of characters, / included, and ? exactly one character.
+	InterfacePathPattern string
+}
+
+// Matches reports whether feature, which belongs to model, passes the
+// filter.
+func (f FeatureFilter) Matches(feature *SmartFeature, model *SmartModel) bool {
+	switch {
+	case f.Protocol != nil && feature.Protocol != *f.Protocol:
+		return false
+	case f.ModelType != nil && model.Type != *f.ModelType:
+		return false
+	case f.ModelCategory != nil && model.Category != *f.ModelCategory:
+		return false
+	case !strings.HasPrefix(feature.Name, f.NamePrefix):
+		return false
+	}
+	return f.InterfacePathPattern == "" || globRegexp(f.InterfacePathPattern).MatchString(feature.InterfacePath)
+}
+
+func globRegexp(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
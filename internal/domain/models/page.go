@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PageCursor is the position after the last item of a page in a listing
+// ordered by creation time and then ID.
+type PageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Token renders the cursor as an opaque page token.
+func (c PageCursor) Token() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParsePageToken reverses PageCursor.Token. An empty token is the start of
+// the listing and returns nil.
+func ParsePageToken(token string) (*PageCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidPageToken
+	}
+
+	var cursor PageCursor
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	if cursor.ID, err = uuid.Parse(id); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	return &cursor, nil
+}
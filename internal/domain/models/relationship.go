@@ -0,0 +1,88 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRelationship is wrapped by the errors for malformed model
+// relationships and relationship queries.
+var ErrInvalidRelationship = errors.New("invalid relationship")
+
+type RelationshipType string
+
+const (
+	// RelationshipCompatibleWith says two models work together. It is
+	// symmetric and stored with the smaller model ID as the source.
+	RelationshipCompatibleWith RelationshipType = "compatible_with"
+	// RelationshipRequires says the source model needs the target model to
+	// work, e.g. a camera that needs a hub.
+	RelationshipRequires RelationshipType = "requires"
+	// RelationshipReplaces says the source model supersedes the target.
+	RelationshipReplaces RelationshipType = "replaces"
+	// RelationshipAccessoryOf says the source model is an accessory of the
+	// target.
+	RelationshipAccessoryOf RelationshipType = "accessory_of"
+)
+
+// Symmetric reports whether a relationship of type t holds in both
+// directions.
+func (t RelationshipType) Symmetric() bool {
+	return t == RelationshipCompatibleWith
+}
+
+// Acyclic reports whether relationships of type t must not form a cycle: a
+// model cannot end up replacing itself or being its own accessory.
+func (t RelationshipType) Acyclic() bool {
+	return t == RelationshipReplaces || t == RelationshipAccessoryOf
+}
+
+// ModelRelationship is a typed edge from one smart model to another. There
+// is at most one relationship of a type between two models.
+type ModelRelationship struct {
+	ID            uuid.UUID        `json:"id" db:"id"`
+	SourceModelID uuid.UUID        `json:"source_model_id" db:"source_model_id"`
+	TargetModelID uuid.UUID        `json:"target_model_id" db:"target_model_id"`
+	Type          RelationshipType `json:"type" db:"type"`
+	CreatedAt     time.Time        `json:"created_at" db:"created_at"`
+}
+
+// RelationshipFilter selects the relationships a model takes part in, as
+// source or target, of Type only when it is set.
+type RelationshipFilter struct {
+	ModelID uuid.UUID
+	Type    RelationshipType
+}
+
+type RelationshipDirection string
+
+const (
+	// DirectionOutgoing follows relationships from source to target, e.g.
+	// the models X requires.
+	DirectionOutgoing RelationshipDirection = "outgoing"
+	// DirectionIncoming follows relationships from target to source, e.g.
+	// the models that require X.
+	DirectionIncoming RelationshipDirection = "incoming"
+	// DirectionBoth follows relationships either way.
+	DirectionBoth RelationshipDirection = "both"
+)
+
+// RelationshipQuery selects the models reachable from ModelID over
+// relationships of Type followed in Direction, at most MaxDepth hops away.
+type RelationshipQuery struct {
+	ModelID   uuid.UUID
+	Type      RelationshipType
+	Direction RelationshipDirection
+	MaxDepth  int
+}
+
+// RelatedModel is a model reached by a RelationshipQuery, Depth hops from
+// the start over the shortest path. Model is only filled in by
+// RelationshipService; repositories return the ID alone.
+type RelatedModel struct {
+	ModelID uuid.UUID
+	Depth   int
+	Model   *SmartModel
+}
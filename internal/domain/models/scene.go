@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidScene is wrapped by the errors for malformed scenes.
+var ErrInvalidScene = errors.New("invalid scene")
+
+// SceneStep invokes a smart feature of a device with parameters, Delay after
+// the step's stage starts. Consecutive steps with the same non-empty Group
+// form one stage and run in parallel; every other step is a stage of its
+// own.
+type SceneStep struct {
+	DeviceID   string                 `json:"device_id"`
+	FeatureID  uuid.UUID              `json:"feature_id"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Delay      time.Duration          `json:"delay,omitempty"`
+	Group      string                 `json:"group,omitempty"`
+}
+
+// Scene is a named set of steps that are activated together. Its stages run
+// one after the other. When StopOnError is set, a failed step makes the
+// steps of the later stages be skipped; otherwise every step runs.
+type Scene struct {
+	ID          uuid.UUID   `json:"id" db:"id"`
+	Name        string      `json:"name" db:"name"`
+	Description string      `json:"description,omitempty" db:"description"`
+	Steps       []SceneStep `json:"steps" db:"steps"`
+	StopOnError bool        `json:"stop_on_error" db:"stop_on_error"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+type StepStatus string
+
+const (
+	StepSucceeded StepStatus = "succeeded"
+	StepFailed    StepStatus = "failed"
+	StepSkipped   StepStatus = "skipped"
+)
+
+// StepResult is the outcome of the step at Index of a scene. A skipped step
+// has no start and finish time.
+type StepResult struct {
+	Index      int
+	DeviceID   string
+	FeatureID  uuid.UUID
+	Status     StepStatus
+	Error      string
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+type ActivationStatus string
+
+const (
+	// ActivationSucceeded means every step succeeded.
+	ActivationSucceeded ActivationStatus = "succeeded"
+	// ActivationPartiallyFailed means some steps succeeded and others
+	// failed or were skipped.
+	ActivationPartiallyFailed ActivationStatus = "partially_failed"
+	// ActivationFailed means no step succeeded.
+	ActivationFailed ActivationStatus = "failed"
+)
+
+// SceneActivation reports one activation of a scene, with a result per step
+// in the order of the steps.
+type SceneActivation struct {
+	SceneID    uuid.UUID
+	Status     ActivationStatus
+	Results    []StepResult
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSchedule is wrapped by the errors for malformed schedules.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule invokes a smart feature of a device with fixed parameters, either
+// at the times of a cron expression in Timezone or every Interval. Exactly
+// one of Cron and Interval is set.
+//
+// NextRunAt is the next time the schedule is due. A run that was missed,
+// e.g. while no replica was up, is made once as soon as possible; the runs
+// after it are planned from then on. A paused schedule does not run and
+// gets a new NextRunAt when it is resumed.
+type Schedule struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	// Cron has five fields: minute, hour, day of month, month and day of
+	// week, e.g. "0 7 * * 1-5".
+	Cron       string
+	Interval   time.Duration
+	Timezone   string
+	DeviceID   string
+	FeatureID  uuid.UUID
+	Parameters map[string]interface{}
+	Paused     bool
+	NextRunAt  time.Time
+	LastRunAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ScheduleRun is one invocation made by a schedule. ScheduledAt is the time
+// the schedule was due, StartedAt when the run actually started.
+type ScheduleRun struct {
+	ID          uuid.UUID
+	ScheduleID  uuid.UUID
+	ScheduledAt time.Time
+	Status      ExecutionStatus
+	Error       string
+	StartedAt   time.Time
+	FinishedAt  *time.Time
+}
+
+// ScheduleRunFilter selects runs newest first. A nil ScheduleID selects the
+// runs of every schedule.
+type ScheduleRunFilter struct {
+	ScheduleID *uuid.UUID
+	After      *PageCursor
+	Limit      int
+}
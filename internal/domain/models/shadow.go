@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+// ErrInvalidShadowState is wrapped by the errors for malformed shadow
+// updates.
+var ErrInvalidShadowState = errors.New("invalid shadow state")
+
+// DeviceShadow is the last known and the intended state of a device. Both
+// sections map smart feature IDs to the value of the feature. Version starts
+// at 1 and grows with every change.
+type DeviceShadow struct {
+	DeviceID  string                 `json:"device_id" db:"device_id"`
+	Desired   map[string]interface{} `json:"desired" db:"desired"`
+	Reported  map[string]interface{} `json:"reported" db:"reported"`
+	Version   int64                  `json:"version" db:"version"`
+	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// Delta returns the desired values that the device has not reported: the
+// keys missing from Reported or reported with a different value. Values are
+// compared as decoded JSON.
+func (s *DeviceShadow) Delta() map[string]interface{} {
+	delta := make(map[string]interface{})
+	for key, desired := range s.Desired {
+		reported, ok := s.Reported[key]
+		if !ok || !reflect.DeepEqual(desired, reported) {
+			delta[key] = desired
+		}
+	}
+	return delta
+}
+
+// ShadowDelta is an entry of the delta log: the delta of a shadow after the
+// change that produced Version. An empty Delta means the device is in sync;
+// a deleted shadow is logged with an empty Delta and Version 0. Sequence
+// orders the log.
+type ShadowDelta struct {
+	Sequence  int64                  `json:"sequence" db:"sequence"`
+	DeviceID  string                 `json:"device_id" db:"device_id"`
+	Version   int64                  `json:"version" db:"version"`
+	Delta     map[string]interface{} `json:"delta" db:"delta"`
+	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+}
+
+// ShadowDeltaEvent is what a delta watcher receives: a delta and the token
+// to resume from after it.
+type ShadowDeltaEvent struct {
+	Delta       *ShadowDelta
+	ResumeToken string
+}
@@ -30,4 +30,7 @@ type SmartModel struct {
 	Metadata     map[string]interface{} `json:"metadata,omitempty" db:"metadata" validate:"omitempty,dive,keys,required,endkeys"`
 	CreatedAt    time.Time              `json:"created_at" db:"created_at" validate:"omitempty,ltefield=UpdatedAt"`
 	UpdatedAt    time.Time              `json:"updated_at" db:"updated_at" validate:"omitempty"`
+	// Features are only filled in on request, by SmartModelService.Create and
+	// ExpandFeatures. Repositories neither store nor load them.
+	Features []*SmartFeature `json:"features,omitempty" db:"-" validate:"omitempty,dive"`
 }
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidTelemetryQuery is wrapped by the errors for malformed telemetry
+// queries and retention policies.
+var ErrInvalidTelemetryQuery = errors.New("invalid telemetry query")
+
+// TelemetryReading is one value a device reported for a feature. DeviceID
+// is chosen by the caller, e.g. a serial number; a device, feature and
+// timestamp identify a reading.
+type TelemetryReading struct {
+	DeviceID  string    `json:"device_id" db:"device_id"`
+	FeatureID uuid.UUID `json:"feature_id" db:"feature_id"`
+	Timestamp time.Time `json:"timestamp" db:"recorded_at"`
+	Value     float64   `json:"value" db:"value"`
+}
+
+// ReadingQuery selects the readings of a feature in [From, To). An empty
+// DeviceID matches every device.
+type ReadingQuery struct {
+	FeatureID uuid.UUID
+	DeviceID  string
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+// TelemetryBucket aggregates the readings of one downsampling bucket.
+type TelemetryBucket struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
+	Avg   float64   `json:"avg"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+}
+
+// ReadingSeries is the result of a telemetry query: Readings for a raw
+// query, Buckets for a downsampled one. Truncated is set when the limit cut
+// the series short.
+type ReadingSeries struct {
+	Readings  []*TelemetryReading
+	Buckets   []*TelemetryBucket
+	Truncated bool
+}
+
+// ReadingDeleteFilter selects readings older than Before, of FeatureID only
+// when it is set, and never of the features in ExceptFeatureIDs.
+type ReadingDeleteFilter struct {
+	Before           time.Time
+	FeatureID        *uuid.UUID
+	ExceptFeatureIDs []uuid.UUID
+}
+
+// RetentionPolicy overrides the default retention of the readings of one
+// feature.
+type RetentionPolicy struct {
+	FeatureID uuid.UUID     `json:"feature_id" db:"feature_id"`
+	Retention time.Duration `json:"retention" db:"retention_seconds"`
+	UpdatedAt time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// maxReadingRejections caps the rejections listed in an IngestReport.
+const maxReadingRejections = 100
+
+// ReadingRejection is a reading that was not stored. Index is its 0-based
+// position in the ingested stream.
+type ReadingRejection struct {
+	Index  int64
+	Reason string
+}
+
+// IngestReport sums up an ingestion. Rejections lists the first rejected
+// readings; Rejected counts all of them.
+type IngestReport struct {
+	Received   int64
+	Stored     int64
+	Duplicates int64
+	Rejected   int64
+	Rejections []ReadingRejection
+}
+
+func (r *IngestReport) Reject(index int64, reason string) {
+	r.Rejected++
+	if len(r.Rejections) < maxReadingRejections {
+		r.Rejections = append(r.Rejections, ReadingRejection{Index: index, Reason: reason})
+	}
+}
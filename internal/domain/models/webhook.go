@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+	DeliveryDead      DeliveryStatus = "dead"
+)
+
+// WebhookSubscription is an HTTP endpoint that receives domain events. An
+// empty EventTypes list subscribes to every event.
+type WebhookSubscription struct {
+	ID          uuid.UUID   `json:"id" db:"id" validate:"omitempty,uuid"`
+	URL         string      `json:"url" db:"url" validate:"required,url,max=2048"`
+	EventTypes  []EventType `json:"event_types" db:"event_types" validate:"omitempty,dive,oneof=model.created model.updated model.deleted feature.created feature.updated feature.deleted"`
+	Secret      string      `json:"-" db:"secret" validate:"omitempty,min=16,max=255"`
+	Description string      `json:"description,omitempty" db:"description" validate:"omitempty,max=1000"`
+	Active      bool        `json:"active" db:"active"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+func (s *WebhookSubscription) Matches(eventType EventType) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for one subscription. Payload is the
+// JSON encoded DomainEvent exactly as it is POSTed.
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id" db:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id" db:"event_id"`
+	EventType      EventType       `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         DeliveryStatus  `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	LastStatusCode int             `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+}
+
+type WebhookDeliveryFilter struct {
+	SubscriptionID *uuid.UUID
+	Status         *DeliveryStatus
+	Limit          int
+}
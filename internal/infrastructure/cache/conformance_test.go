@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"smart-hub/internal/infrastructure/database/memory"
+	"smart-hub/internal/infrastructure/database/repotest"
+	"testing"
+	"time"
+)
+
+func TestConformance_LRU(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		return cachedMemoryRepositories(NewLRUStore(100, time.Minute))
+	})
+}
+
+func TestConformance_Redis(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store, _ := newTestRedisStore(t, time.Minute)
+		return cachedMemoryRepositories(store)
+	})
+}
+
+func cachedMemoryRepositories(store Store) repotest.Repositories {
+	db := memory.NewStore()
+	return repotest.Repositories{
+		Models:   NewCachedSmartModelRepository(memory.NewMemSmartModelRepository(db), store),
+		Features: NewCachedSmartFeatureRepository(memory.NewMemSmartFeatureRepository(db), store),
+	}
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+const (
+	invalidatorBatchSize = 500
+	// invalidatorGapTimeout is how long the invalidator keeps rereading from
+	// a missing sequence, which may belong to a transaction still in flight.
+	invalidatorGapTimeout = 10 * time.Second
+)
+
+// Invalidator follows the catalog change log and drops the cache entries of
+// every model and feature that changed, whichever replica changed them. It is
+// woken by the change listener (LISTEN/NOTIFY on Postgres) and polls as a
+// fallback.
+type Invalidator struct {
+	store        Store
+	changes      interfaces.CatalogChangeRepository
+	listener     interfaces.ChangeListener
+	pollInterval time.Duration
+
+	wake     chan struct{}
+	cursor   int64
+	gapSince time.Time
+}
+
+func NewInvalidator(
+	store Store,
+	changes interfaces.CatalogChangeRepository,
+	listener interfaces.ChangeListener,
+	pollInterval time.Duration,
+) *Invalidator {
+	return &Invalidator{
+		store:        store,
+		changes:      changes,
+		listener:     listener,
+		pollInterval: pollInterval,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Run follows the change log until ctx is cancelled.
+func (i *Invalidator) Run(ctx context.Context) {
+	for {
+		cursor, err := i.changes.LatestSequence(ctx)
+		if err == nil {
+			i.cursor = cursor
+			break
+		}
+		logger.Error("Failed to read catalog change head", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(i.pollInterval):
+		}
+	}
+
+	if i.listener != nil {
+		go func() {
+			if err := i.listener.Listen(ctx, i.notify); err != nil && ctx.Err() == nil {
+				logger.Error("Cache invalidation listener stopped", err)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(i.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-i.wake:
+		case <-ticker.C:
+		}
+
+		if err := i.poll(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Failed to read catalog changes for cache invalidation", err)
+		}
+	}
+}
+
+func (i *Invalidator) notify() {
+	select {
+	case i.wake <- struct{}{}:
+	default:
+	}
+}
+
+// poll invalidates the entries of all changes after the cursor. The cursor
+// stops at a missing sequence, so the changes after it are invalidated again
+// on the next poll until the gap is filled or invalidatorGapTimeout passes;
+// dropping an entry twice is harmless.
+func (i *Invalidator) poll(ctx context.Context) error {
+	for {
+		changes, err := i.changes.ListSince(ctx, i.cursor, invalidatorBatchSize)
+		if err != nil {
+			return err
+		}
+
+		keys := make([]string, 0, 2*len(changes))
+		blocked := false
+		for _, change := range changes {
+			keys = append(keys, changeKeys(change)...)
+			if blocked {
+				continue
+			}
+			if change.Sequence != i.cursor+1 {
+				if i.gapSince.IsZero() {
+					i.gapSince = time.Now()
+				}
+				if time.Since(i.gapSince) < invalidatorGapTimeout {
+					blocked = true
+					continue
+				}
+			}
+			i.gapSince = time.Time{}
+			i.cursor = change.Sequence
+		}
+
+		if len(keys) > 0 {
+			if err := i.store.Delete(ctx, keys...); err != nil {
+				return err
+			}
+		}
+		if blocked || len(changes) < invalidatorBatchSize {
+			return nil
+		}
+	}
+}
+
+// changeKeys returns the cache keys a change makes stale.
+func changeKeys(change *models.CatalogChange) []string {
+	switch change.Entity {
+	case models.SmartModelAggregate:
+		id := change.EntityID.String()
+		return []string{modelKey(id), featuresKey(id)}
+	case models.SmartFeatureAggregate:
+		return []string{featuresKey(change.ModelID.String())}
+	default:
+		return nil
+	}
+}
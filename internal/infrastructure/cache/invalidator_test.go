@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"smart-hub/internal/infrastructure/database/memory"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidator_DropsEntriesChangedElsewhere(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	catalog := newTestCatalog(NewLRUStore(10, time.Minute))
+	model, err := catalog.models.Create(ctx, newTestModel("Thermostat"))
+	require.NoError(t, err)
+	modelID := model.ID.String()
+	_, err = catalog.models.GetByID(ctx, modelID)
+	require.NoError(t, err)
+	_, err = catalog.features.GetWithModelID(ctx, modelID)
+	require.NoError(t, err)
+
+	invalidator := NewInvalidator(
+		catalog.store,
+		memory.NewMemCatalogChangeRepository(catalog.db),
+		memory.NewMemChangeListener(catalog.db),
+		time.Hour,
+	)
+	go invalidator.Run(ctx)
+	// Let the invalidator find the head of the log before anything changes.
+	time.Sleep(50 * time.Millisecond)
+
+	// Another replica renames the model and adds a feature.
+	renamed := *model
+	renamed.Name = "Renamed"
+	_, err = catalog.rawModel.Update(ctx, &renamed)
+	require.NoError(t, err)
+	_, err = catalog.rawFeat.Create(ctx, newTestFeature(model.ID, "temperature"))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		_, cachedModel, _ := catalog.store.Get(ctx, modelKey(modelID))
+		_, cachedFeatures, _ := catalog.store.Get(ctx, featuresKey(modelID))
+		return !cachedModel && !cachedFeatures
+	}, time.Second, 10*time.Millisecond)
+
+	found, err := catalog.models.GetByID(ctx, modelID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", found.Name)
+	features, err := catalog.features.GetWithModelID(ctx, modelID)
+	require.NoError(t, err)
+	assert.Len(t, features, 1)
+}
+
+func TestInvalidator_PollsWithoutListener(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	catalog := newTestCatalog(NewLRUStore(10, time.Minute))
+	model, err := catalog.models.Create(ctx, newTestModel("Thermostat"))
+	require.NoError(t, err)
+	_, err = catalog.features.Create(ctx, newTestFeature(model.ID, "temperature"))
+	require.NoError(t, err)
+	modelID := model.ID.String()
+	_, err = catalog.features.GetWithModelID(ctx, modelID)
+	require.NoError(t, err)
+
+	go NewInvalidator(catalog.store, memory.NewMemCatalogChangeRepository(catalog.db), nil, 10*time.Millisecond).Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, catalog.rawModel.Delete(ctx, modelID))
+
+	assert.Eventually(t, func() bool {
+		_, cached, _ := catalog.store.Get(ctx, featuresKey(modelID))
+		return !cached
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// LRUStore keeps entries in process, evicting the least recently used one
+// once it holds size entries.
+type LRUStore struct {
+	lru *expirable.LRU[string, []byte]
+}
+
+func NewLRUStore(size int, ttl time.Duration) *LRUStore {
+	return &LRUStore{lru: expirable.NewLRU[string, []byte](size, nil, ttl)}
+}
+
+func (s *LRUStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, ok := s.lru.Get(key)
+	return value, ok, nil
+}
+
+func (s *LRUStore) Set(_ context.Context, key string, value []byte) error {
+	s.lru.Add(key, value)
+	return nil
+}
+
+func (s *LRUStore) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		s.lru.Remove(key)
+	}
+	return nil
+}
+
+func (s *LRUStore) Close() error {
+	return nil
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUStore_SetGetDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewLRUStore(10, time.Minute)
+
+	_, ok, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1")))
+	require.NoError(t, store.Set(ctx, "b", []byte("2")))
+	value, ok, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	require.NoError(t, store.Delete(ctx, "a", "b", "missing"))
+	_, ok, _ = store.Get(ctx, "a")
+	assert.False(t, ok)
+	_, ok, _ = store.Get(ctx, "b")
+	assert.False(t, ok)
+}
+
+func TestLRUStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	store := NewLRUStore(2, time.Minute)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1")))
+	require.NoError(t, store.Set(ctx, "b", []byte("2")))
+	_, _, _ = store.Get(ctx, "a")
+	require.NoError(t, store.Set(ctx, "c", []byte("3")))
+
+	_, ok, _ := store.Get(ctx, "a")
+	assert.True(t, ok)
+	_, ok, _ = store.Get(ctx, "b")
+	assert.False(t, ok, "b was used least recently")
+	_, ok, _ = store.Get(ctx, "c")
+	assert.True(t, ok)
+}
+
+func TestLRUStore_Expires(t *testing.T) {
+	ctx := context.Background()
+	store := NewLRUStore(10, 20*time.Millisecond)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1")))
+	assert.Eventually(t, func() bool {
+		_, ok, _ := store.Get(ctx, "a")
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/tracing"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Stats counts the lookups a cached repository answered from the cache and
+// the ones it had to pass on to the database.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// counters records hits and misses both locally, for Stats, and as the
+// cache.hits and cache.misses OpenTelemetry counters.
+type counters struct {
+	hits, misses   atomic.Uint64
+	otelHits       metric.Int64Counter
+	otelMisses     metric.Int64Counter
+	nameAttributes metric.MeasurementOption
+}
+
+func newCounters(name string) *counters {
+	c := &counters{
+		nameAttributes: metric.WithAttributes(attribute.String("cache.name", name)),
+	}
+
+	meter := tracing.Meter()
+	var err error
+	if c.otelHits, err = meter.Int64Counter("cache.hits", metric.WithDescription("Reads answered from the cache")); err != nil {
+		logger.Error("Failed to create cache hit counter", err)
+	}
+	if c.otelMisses, err = meter.Int64Counter("cache.misses", metric.WithDescription("Reads passed on to the database")); err != nil {
+		logger.Error("Failed to create cache miss counter", err)
+	}
+	return c
+}
+
+func (c *counters) hit(ctx context.Context) {
+	c.hits.Add(1)
+	if c.otelHits != nil {
+		c.otelHits.Add(ctx, 1, c.nameAttributes)
+	}
+}
+
+func (c *counters) miss(ctx context.Context) {
+	c.misses.Add(1)
+	if c.otelMisses != nil {
+		c.otelMisses.Add(ctx, 1, c.nameAttributes)
+	}
+}
+
+func (c *counters) stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
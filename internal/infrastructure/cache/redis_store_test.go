@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisStore(t *testing.T, ttl time.Duration) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	store := NewRedisStore(server.Addr(), "", 0, "smart-hub", ttl)
+	t.Cleanup(func() { _ = store.Close() })
+	return store, server
+}
+
+func TestRedisStore_SetGetDelete(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestRedisStore(t, time.Minute)
+
+	_, ok, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1")))
+	require.NoError(t, store.Set(ctx, "b", []byte("2")))
+	assert.True(t, server.Exists("smart-hub:a"), "keys are prefixed")
+
+	value, ok, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	require.NoError(t, store.Delete(ctx, "a", "b"))
+	require.NoError(t, store.Delete(ctx))
+	_, ok, _ = store.Get(ctx, "a")
+	assert.False(t, ok)
+	_, ok, _ = store.Get(ctx, "b")
+	assert.False(t, ok)
+}
+
+func TestRedisStore_Expires(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestRedisStore(t, time.Minute)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1")))
+	assert.Equal(t, time.Minute, server.TTL("smart-hub:a"))
+
+	server.FastForward(time.Minute)
+	_, ok, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRedisStore_Unavailable(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestRedisStore(t, time.Minute)
+	server.Close()
+
+	_, _, err := store.Get(ctx, "a")
+	assert.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// CachedSmartFeatureRepository answers GetWithModelID from the cache, falling
+// back to the wrapped repository (and the primary) on a miss. Writes go
+// straight through and drop the feature lists of the models they touch.
+type CachedSmartFeatureRepository struct {
+	repo     interfaces.SmartFeatureRepository
+	store    Store
+	counters *counters
+}
+
+func NewCachedSmartFeatureRepository(repo interfaces.SmartFeatureRepository, store Store) *CachedSmartFeatureRepository {
+	return &CachedSmartFeatureRepository{
+		repo:     repo,
+		store:    store,
+		counters: newCounters("smart_feature"),
+	}
+}
+
+func (r *CachedSmartFeatureRepository) Stats() Stats {
+	return r.counters.stats()
+}
+
+func (r *CachedSmartFeatureRepository) Create(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error) {
+	created, err := r.repo.Create(ctx, feature)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidateModels(ctx, created)
+	return created, nil
+}
+
+func (r *CachedSmartFeatureRepository) GetByID(ctx context.Context, id string) (*models.SmartFeature, error) {
+	return r.repo.GetByID(ctx, id)
+}
+
+func (r *CachedSmartFeatureRepository) GetWithModelID(ctx context.Context, modelID string) ([]*models.SmartFeature, error) {
+	if _, ok := txKeysFromContext(ctx); ok {
+		return r.repo.GetWithModelID(ctx, modelID)
+	}
+
+	key := featuresKey(modelID)
+	var features []*models.SmartFeature
+	if lookup(ctx, r.store, r.counters, key, &features) {
+		return features, nil
+	}
+
+	found, err := r.repo.GetWithModelID(database.ForcePrimary(ctx), modelID)
+	if err != nil {
+		return nil, err
+	}
+	fill(ctx, r.store, key, found)
+	return found, nil
+}
+
+func (r *CachedSmartFeatureRepository) GetWithModelIDs(ctx context.Context, modelIDs []string) ([]*models.SmartFeature, error) {
+	return r.repo.GetWithModelIDs(ctx, modelIDs)
+}
+
+func (r *CachedSmartFeatureRepository) GetAll(ctx context.Context) ([]*models.SmartFeature, error) {
+	return r.repo.GetAll(ctx)
+}
+
+func (r *CachedSmartFeatureRepository) List(ctx context.Context, filter models.FeatureFilter, after *models.PageCursor, limit int) ([]*models.SmartFeature, error) {
+	return r.repo.List(ctx, filter, after, limit)
+}
+
+func (r *CachedSmartFeatureRepository) Update(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error) {
+	updated, err := r.repo.Update(ctx, feature)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidateModels(ctx, updated)
+	return updated, nil
+}
+
+func (r *CachedSmartFeatureRepository) Delete(ctx context.Context, id string) error {
+	// The model is only known before the feature is gone.
+	feature, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		return r.repo.Delete(ctx, id)
+	}
+	if err := r.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidateModels(ctx, feature)
+	return nil
+}
+
+func (r *CachedSmartFeatureRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.SmartFeature, error) {
+	return r.repo.GetByIDs(ctx, ids)
+}
+
+func (r *CachedSmartFeatureRepository) CreateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error) {
+	created, err := r.repo.CreateBatch(ctx, features)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidateModels(ctx, created...)
+	return created, nil
+}
+
+func (r *CachedSmartFeatureRepository) UpdateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error) {
+	updated, err := r.repo.UpdateBatch(ctx, features)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidateModels(ctx, updated...)
+	return updated, nil
+}
+
+func (r *CachedSmartFeatureRepository) DeleteBatch(ctx context.Context, ids []string) error {
+	features, err := r.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return r.repo.DeleteBatch(ctx, ids)
+	}
+	if err := r.repo.DeleteBatch(ctx, ids); err != nil {
+		return err
+	}
+	r.invalidateModels(ctx, features...)
+	return nil
+}
+
+// invalidateModels drops the cached feature lists of the models features
+// belong to.
+func (r *CachedSmartFeatureRepository) invalidateModels(ctx context.Context, features ...*models.SmartFeature) {
+	seen := make(map[uuid.UUID]bool, len(features))
+	var keys []string
+	for _, feature := range features {
+		if !seen[feature.ModelID] {
+			seen[feature.ModelID] = true
+			keys = append(keys, featuresKey(feature.ModelID.String()))
+		}
+	}
+	if len(keys) > 0 {
+		invalidate(ctx, r.store, keys...)
+	}
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedSmartFeatureRepository_GetWithModelID(t *testing.T) {
+	ctx := context.Background()
+	catalog := newTestCatalog(NewLRUStore(10, time.Minute))
+	model, err := catalog.models.Create(ctx, newTestModel("Thermostat"))
+	require.NoError(t, err)
+	_, err = catalog.features.Create(ctx, newTestFeature(model.ID, "temperature"))
+	require.NoError(t, err)
+
+	first, err := catalog.features.GetWithModelID(ctx, model.ID.String())
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+
+	_, err = catalog.rawFeat.Create(ctx, newTestFeature(model.ID, "humidity"))
+	require.NoError(t, err)
+
+	second, err := catalog.features.GetWithModelID(ctx, model.ID.String())
+	require.NoError(t, err)
+	assert.Len(t, second, 1, "served from the cache")
+	assert.Equal(t, first[0].ID, second[0].ID)
+	assert.Equal(t, Stats{Hits: 1, Misses: 1}, catalog.features.Stats())
+}
+
+func TestCachedSmartFeatureRepository_WritesInvalidate(t *testing.T) {
+	ctx := context.Background()
+	catalog := newTestCatalog(NewLRUStore(10, time.Minute))
+	model, err := catalog.models.Create(ctx, newTestModel("Thermostat"))
+	require.NoError(t, err)
+	modelID := model.ID.String()
+
+	names := func() []string {
+		t.Helper()
+		features, err := catalog.features.GetWithModelID(ctx, modelID)
+		require.NoError(t, err)
+		var names []string
+		for _, feature := range features {
+			names = append(names, feature.Name)
+		}
+		return names
+	}
+	assert.Empty(t, names())
+
+	feature, err := catalog.features.Create(ctx, newTestFeature(model.ID, "temperature"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"temperature"}, names())
+
+	feature.Name = "heat"
+	_, err = catalog.features.Update(ctx, feature)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"heat"}, names())
+
+	require.NoError(t, catalog.features.Delete(ctx, feature.ID.String()))
+	assert.Empty(t, names())
+
+	created, err := catalog.features.CreateBatch(ctx, []*models.SmartFeature{
+		newTestFeature(model.ID, "humidity"),
+		newTestFeature(model.ID, "pressure"),
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"humidity", "pressure"}, names())
+
+	created[0].Name = "moisture"
+	_, err = catalog.features.UpdateBatch(ctx, created[:1])
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"moisture", "pressure"}, names())
+
+	require.NoError(t, catalog.features.DeleteBatch(ctx, []string{created[0].ID.String(), created[1].ID.String()}))
+	assert.Empty(t, names())
+	assert.Zero(t, catalog.features.Stats().Hits)
+}
+
+func TestCachedSmartFeatureRepository_DeleteMissing(t *testing.T) {
+	ctx := context.Background()
+	catalog := newTestCatalog(NewLRUStore(10, time.Minute))
+
+	err := catalog.features.Delete(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+)
+
+// CachedSmartModelRepository answers GetByID from the cache, falling back to
+// the wrapped repository on a miss. Misses are read from the primary, so a
+// lagging replica cannot put back a row that was just invalidated. Writes go
+// straight through and drop the model and its feature list from the cache.
+type CachedSmartModelRepository struct {
+	repo     interfaces.SmartModelRepository
+	store    Store
+	counters *counters
+}
+
+func NewCachedSmartModelRepository(repo interfaces.SmartModelRepository, store Store) *CachedSmartModelRepository {
+	return &CachedSmartModelRepository{
+		repo:     repo,
+		store:    store,
+		counters: newCounters("smart_model"),
+	}
+}
+
+func (r *CachedSmartModelRepository) Stats() Stats {
+	return r.counters.stats()
+}
+
+func (r *CachedSmartModelRepository) Create(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error) {
+	return r.repo.Create(ctx, model)
+}
+
+func (r *CachedSmartModelRepository) GetByID(ctx context.Context, id string) (*models.SmartModel, error) {
+	if _, ok := txKeysFromContext(ctx); ok {
+		return r.repo.GetByID(ctx, id)
+	}
+
+	key := modelKey(id)
+	var model models.SmartModel
+	if lookup(ctx, r.store, r.counters, key, &model) {
+		return &model, nil
+	}
+
+	found, err := r.repo.GetByID(database.ForcePrimary(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	fill(ctx, r.store, key, found)
+	return found, nil
+}
+
+func (r *CachedSmartModelRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.SmartModel, error) {
+	return r.repo.GetByIDs(ctx, ids)
+}
+
+func (r *CachedSmartModelRepository) GetByModelNumber(ctx context.Context, manufacturer, modelNumber string) (*models.SmartModel, error) {
+	return r.repo.GetByModelNumber(ctx, manufacturer, modelNumber)
+}
+
+func (r *CachedSmartModelRepository) GetWithType(ctx context.Context, modelType models.ModelType) ([]*models.SmartModel, error) {
+	return r.repo.GetWithType(ctx, modelType)
+}
+
+func (r *CachedSmartModelRepository) GetAll(ctx context.Context) ([]*models.SmartModel, error) {
+	return r.repo.GetAll(ctx)
+}
+
+func (r *CachedSmartModelRepository) Update(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error) {
+	updated, err := r.repo.Update(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	invalidate(ctx, r.store, modelKey(updated.ID.String()))
+	return updated, nil
+}
+
+func (r *CachedSmartModelRepository) Delete(ctx context.Context, id string) error {
+	if err := r.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	// Deleting a model deletes its features too.
+	invalidate(ctx, r.store, modelKey(id), featuresKey(id))
+	return nil
+}
+
+// lookup decodes the entry under key into value and reports whether there
+// was one. A broken store counts as a miss, so reads keep working without
+// the cache.
+func lookup(ctx context.Context, store Store, counters *counters, key string, value interface{}) bool {
+	data, ok, err := store.Get(ctx, key)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Cache read failed", "key", key, "error", err)
+	}
+	if ok && err == nil {
+		if err := json.Unmarshal(data, value); err == nil {
+			counters.hit(ctx)
+			return true
+		}
+		logger.FromContext(ctx).Warn("Dropping undecodable cache entry", "key", key)
+	}
+	counters.miss(ctx)
+	return false
+}
+
+// fill stores value under key, logging rather than failing the read when the
+// store is unavailable.
+func fill(ctx context.Context, store Store, key string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err == nil {
+		err = store.Set(ctx, key, data)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warn("Cache write failed", "key", key, "error", err)
+	}
+}
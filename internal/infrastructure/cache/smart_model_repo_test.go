@@ -0,0 +1,220 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/infrastructure/database/memory"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCatalog is a memory database behind the cache, with the uncached
+// repositories kept around to change rows as another replica would.
+type testCatalog struct {
+	db       *memory.Store
+	store    Store
+	uow      *CachedUnitOfWork
+	models   *CachedSmartModelRepository
+	features *CachedSmartFeatureRepository
+	rawModel *memory.MemSmartModelRepository
+	rawFeat  *memory.MemSmartFeatureRepository
+}
+
+func newTestCatalog(store Store) *testCatalog {
+	db := memory.NewStore()
+	rawModel := memory.NewMemSmartModelRepository(db)
+	rawFeat := memory.NewMemSmartFeatureRepository(db)
+	return &testCatalog{
+		db:       db,
+		store:    store,
+		uow:      NewCachedUnitOfWork(memory.NewMemUnitOfWork(db), store),
+		models:   NewCachedSmartModelRepository(rawModel, store),
+		features: NewCachedSmartFeatureRepository(rawFeat, store),
+		rawModel: rawModel,
+		rawFeat:  rawFeat,
+	}
+}
+
+func newTestModel(name string) *models.SmartModel {
+	now := time.Now().UTC()
+	return &models.SmartModel{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: name + " description",
+		Type:        models.DeviceType,
+		Category:    models.WearableCategory,
+		Metadata:    map[string]interface{}{"channels": float64(2)},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func newTestFeature(modelID uuid.UUID, name string) *models.SmartFeature {
+	now := time.Now().UTC()
+	return &models.SmartFeature{
+		ID:            uuid.New(),
+		ModelID:       modelID,
+		Name:          name,
+		Description:   name + " description",
+		Protocol:      models.RestProtocol,
+		InterfacePath: "/api/" + name,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func TestCachedSmartModelRepository_GetByID(t *testing.T) {
+	ctx := context.Background()
+	catalog := newTestCatalog(NewLRUStore(10, time.Minute))
+	model, err := catalog.models.Create(ctx, newTestModel("Thermostat"))
+	require.NoError(t, err)
+
+	first, err := catalog.models.GetByID(ctx, model.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Misses: 1}, catalog.models.Stats())
+
+	// A change that bypasses the cache is not seen until the entry is
+	// invalidated.
+	stale := *model
+	stale.Name = "Renamed"
+	_, err = catalog.rawModel.Update(ctx, &stale)
+	require.NoError(t, err)
+
+	second, err := catalog.models.GetByID(ctx, model.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Hits: 1, Misses: 1}, catalog.models.Stats())
+	assert.Equal(t, first.Name, second.Name)
+	assert.Equal(t, first.Metadata, second.Metadata)
+	assert.True(t, first.CreatedAt.Equal(second.CreatedAt))
+}
+
+func TestCachedSmartModelRepository_NotFoundIsNotCached(t *testing.T) {
+	ctx := context.Background()
+	catalog := newTestCatalog(NewLRUStore(10, time.Minute))
+	model := newTestModel("Thermostat")
+
+	_, err := catalog.models.GetByID(ctx, model.ID.String())
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+
+	_, err = catalog.rawModel.Create(ctx, model)
+	require.NoError(t, err)
+	found, err := catalog.models.GetByID(ctx, model.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, model.ID, found.ID)
+	assert.Equal(t, Stats{Misses: 2}, catalog.models.Stats())
+}
+
+func TestCachedSmartModelRepository_UpdateInvalidates(t *testing.T) {
+	ctx := context.Background()
+	catalog := newTestCatalog(NewLRUStore(10, time.Minute))
+	model, err := catalog.models.Create(ctx, newTestModel("Thermostat"))
+	require.NoError(t, err)
+	_, err = catalog.models.GetByID(ctx, model.ID.String())
+	require.NoError(t, err)
+
+	model.Name = "Renamed"
+	_, err = catalog.models.Update(ctx, model)
+	require.NoError(t, err)
+
+	found, err := catalog.models.GetByID(ctx, model.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", found.Name)
+	assert.Equal(t, Stats{Misses: 2}, catalog.models.Stats())
+}
+
+func TestCachedSmartModelRepository_DeleteInvalidatesFeatures(t *testing.T) {
+	ctx := context.Background()
+	catalog := newTestCatalog(NewLRUStore(10, time.Minute))
+	model, err := catalog.models.Create(ctx, newTestModel("Thermostat"))
+	require.NoError(t, err)
+	_, err = catalog.features.Create(ctx, newTestFeature(model.ID, "temperature"))
+	require.NoError(t, err)
+
+	_, err = catalog.models.GetByID(ctx, model.ID.String())
+	require.NoError(t, err)
+	features, err := catalog.features.GetWithModelID(ctx, model.ID.String())
+	require.NoError(t, err)
+	require.Len(t, features, 1)
+
+	require.NoError(t, catalog.models.Delete(ctx, model.ID.String()))
+
+	_, err = catalog.models.GetByID(ctx, model.ID.String())
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+	features, err = catalog.features.GetWithModelID(ctx, model.ID.String())
+	require.NoError(t, err)
+	assert.Empty(t, features)
+}
+
+func TestCachedSmartModelRepository_StoreUnavailable(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestRedisStore(t, time.Minute)
+	catalog := newTestCatalog(store)
+	model, err := catalog.models.Create(ctx, newTestModel("Thermostat"))
+	require.NoError(t, err)
+	server.Close()
+
+	found, err := catalog.models.GetByID(ctx, model.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, model.ID, found.ID)
+
+	model.Name = "Renamed"
+	_, err = catalog.models.Update(ctx, model)
+	assert.NoError(t, err)
+	assert.Equal(t, Stats{Misses: 1}, catalog.models.Stats())
+}
+
+func TestCachedUnitOfWork_ReadsPastCacheInTransaction(t *testing.T) {
+	ctx := context.Background()
+	catalog := newTestCatalog(NewLRUStore(10, time.Minute))
+	model, err := catalog.models.Create(ctx, newTestModel("Thermostat"))
+	require.NoError(t, err)
+
+	rollback := errors.New("rollback")
+	err = catalog.uow.Do(ctx, func(ctx context.Context) error {
+		model.Name = "Uncommitted"
+		if _, err := catalog.models.Update(ctx, model); err != nil {
+			return err
+		}
+		found, err := catalog.models.GetByID(ctx, model.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, "Uncommitted", found.Name)
+		return rollback
+	})
+	require.ErrorIs(t, err, rollback)
+	assert.Equal(t, Stats{}, catalog.models.Stats(), "reads inside the transaction skip the cache")
+
+	found, err := catalog.models.GetByID(ctx, model.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "Thermostat", found.Name)
+}
+
+func TestCachedUnitOfWork_InvalidatesAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	catalog := newTestCatalog(NewLRUStore(10, time.Minute))
+	model, err := catalog.models.Create(ctx, newTestModel("Thermostat"))
+	require.NoError(t, err)
+
+	err = catalog.uow.Do(ctx, func(ctx context.Context) error {
+		model.Name = "Renamed"
+		if _, err := catalog.models.Update(ctx, model); err != nil {
+			return err
+		}
+		// A reader outside the transaction caches the committed row.
+		stale := *model
+		stale.Name = "Thermostat"
+		data, err := json.Marshal(&stale)
+		require.NoError(t, err)
+		return catalog.store.Set(ctx, modelKey(model.ID.String()), data)
+	})
+	require.NoError(t, err)
+
+	found, err := catalog.models.GetByID(ctx, model.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", found.Name)
+}
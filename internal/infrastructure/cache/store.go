@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"smart-hub/config"
+	"strings"
+)
+
+const (
+	BackendNone   = "none"
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Store holds encoded cache entries. Entries expire after the TTL the store
+// was built with; Get reports a missing or expired key with ok set to false.
+type Store interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte) error
+	Delete(ctx context.Context, keys ...string) error
+	Close() error
+}
+
+// NewStore builds the Store selected by cfg.Backend. It returns nil for the
+// "none" backend.
+func NewStore(cfg *config.CacheConfig) (Store, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case BackendNone, "":
+		return nil, nil
+	case BackendMemory:
+		if cfg.Size <= 0 {
+			return nil, fmt.Errorf("CACHE_SIZE must be positive for the memory cache")
+		}
+		return NewLRUStore(cfg.Size, cfg.TTL), nil
+	case BackendRedis:
+		return NewRedisStore(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB, cfg.KeyPrefix, cfg.TTL), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.Backend)
+	}
+}
+
+func modelKey(id string) string {
+	return "model:" + id
+}
+
+func featuresKey(modelID string) string {
+	return "features:" + modelID
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/interfaces"
+	"sync"
+)
+
+type txKeysKey struct{}
+
+// txKeys collects the keys written inside a unit of work, so they can be
+// dropped again once it has committed or rolled back.
+type txKeys struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (t *txKeys) add(keys ...string) {
+	t.mu.Lock()
+	t.keys = append(t.keys, keys...)
+	t.mu.Unlock()
+}
+
+func txKeysFromContext(ctx context.Context) (*txKeys, bool) {
+	keys, ok := ctx.Value(txKeysKey{}).(*txKeys)
+	return keys, ok
+}
+
+// CachedUnitOfWork wraps a UnitOfWork so the cached repositories can tell
+// when they run inside a transaction. They read past the cache there, since
+// the transaction may see rows nobody else can yet, and the keys they
+// invalidate are invalidated once more after the transaction ends: a reader
+// outside it may have cached the old row again in the meantime.
+type CachedUnitOfWork struct {
+	uow   interfaces.UnitOfWork
+	store Store
+}
+
+func NewCachedUnitOfWork(uow interfaces.UnitOfWork, store Store) *CachedUnitOfWork {
+	return &CachedUnitOfWork{uow: uow, store: store}
+}
+
+func (u *CachedUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := txKeysFromContext(ctx); ok {
+		return u.uow.Do(ctx, fn)
+	}
+
+	keys := &txKeys{}
+	err := u.uow.Do(context.WithValue(ctx, txKeysKey{}, keys), fn)
+	if len(keys.keys) > 0 {
+		if err := u.store.Delete(context.WithoutCancel(ctx), keys.keys...); err != nil {
+			logger.Error("Failed to invalidate cache after transaction", err)
+		}
+	}
+	return err
+}
+
+// invalidate drops keys now and, inside a unit of work, again when it ends.
+func invalidate(ctx context.Context, store Store, keys ...string) {
+	if tx, ok := txKeysFromContext(ctx); ok {
+		tx.add(keys...)
+	}
+	if err := store.Delete(context.WithoutCancel(ctx), keys...); err != nil {
+		logger.FromContext(ctx).Error("Failed to invalidate cache", err, "keys", keys)
+	}
+}
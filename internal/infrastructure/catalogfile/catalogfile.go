@@ -0,0 +1,189 @@
+// Package catalogfile reads and writes catalog entries as JSON, YAML or CSV
+// files for bulk import and export.
+//
+// JSON and YAML files hold a list of models with their features nested
+// under "features". CSV files hold one row per feature; the model columns
+// are repeated on every row of a model and rows are grouped back into models
+// by (manufacturer, model_number). A model without features is a single row
+// with empty feature columns.
+package catalogfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path/filepath"
+	"smart-hub/internal/domain/models"
+	"strings"
+)
+
+type Format string
+
+const (
+	JSON Format = "json"
+	YAML Format = "yaml"
+	CSV  Format = "csv"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "json":
+		return JSON, nil
+	case "yaml", "yml":
+		return YAML, nil
+	case "csv":
+		return CSV, nil
+	default:
+		return "", fmt.Errorf("unknown catalog format %q, expected json, yaml or csv", s)
+	}
+}
+
+// FormatFromPath infers the format from the file extension.
+func FormatFromPath(path string) (Format, error) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if ext == "" {
+		return "", fmt.Errorf("cannot infer catalog format of %q, set it explicitly", path)
+	}
+	return ParseFormat(ext)
+}
+
+type modelRecord struct {
+	ID           string                 `json:"id,omitempty" yaml:"id,omitempty"`
+	Name         string                 `json:"name" yaml:"name"`
+	Description  string                 `json:"description" yaml:"description"`
+	Type         string                 `json:"type" yaml:"type"`
+	Category     string                 `json:"category" yaml:"category"`
+	Manufacturer string                 `json:"manufacturer" yaml:"manufacturer"`
+	ModelNumber  string                 `json:"model_number" yaml:"model_number"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Features     []featureRecord        `json:"features,omitempty" yaml:"features,omitempty"`
+}
+
+type featureRecord struct {
+	Name          string                 `json:"name" yaml:"name"`
+	Description   string                 `json:"description" yaml:"description"`
+	Protocol      string                 `json:"protocol" yaml:"protocol"`
+	InterfacePath string                 `json:"interface_path" yaml:"interface_path"`
+	Parameters    map[string]interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// Decode reads every entry from r. IDs in the file are ignored; imports
+// match existing models by (manufacturer, model_number).
+func Decode(r io.Reader, format Format) ([]*models.CatalogEntry, error) {
+	var records []modelRecord
+	var err error
+	switch format {
+	case JSON:
+		err = json.NewDecoder(r).Decode(&records)
+	case YAML:
+		records, err = readYAML(r)
+	case CSV:
+		records, err = readCSV(r)
+	default:
+		err = fmt.Errorf("unknown catalog format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode %s catalog: %w", format, err)
+	}
+
+	entries := make([]*models.CatalogEntry, len(records))
+	for i, record := range records {
+		entries[i] = record.toEntry()
+	}
+	return entries, nil
+}
+
+// readYAML decodes through JSON so that numbers come out as float64, the
+// same as for the other formats.
+func readYAML(r io.Reader) ([]modelRecord, error) {
+	var document interface{}
+	if err := yaml.NewDecoder(r).Decode(&document); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var records []modelRecord
+	err = json.Unmarshal(data, &records)
+	return records, err
+}
+
+// Encode writes entries to w.
+func Encode(w io.Writer, format Format, entries []*models.CatalogEntry) error {
+	records := make([]modelRecord, len(entries))
+	for i, entry := range entries {
+		records[i] = newModelRecord(entry)
+	}
+
+	switch format {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case YAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(records); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case CSV:
+		return writeCSV(w, records)
+	default:
+		return fmt.Errorf("unknown catalog format %q", format)
+	}
+}
+
+func newModelRecord(entry *models.CatalogEntry) modelRecord {
+	model := entry.Model
+	record := modelRecord{
+		ID:           model.ID.String(),
+		Name:         model.Name,
+		Description:  model.Description,
+		Type:         string(model.Type),
+		Category:     string(model.Category),
+		Manufacturer: model.Manufacturer,
+		ModelNumber:  model.ModelNumber,
+		Metadata:     model.Metadata,
+	}
+	for _, feature := range entry.Features {
+		record.Features = append(record.Features, featureRecord{
+			Name:          feature.Name,
+			Description:   feature.Description,
+			Protocol:      string(feature.Protocol),
+			InterfacePath: feature.InterfacePath,
+			Parameters:    feature.Parameters,
+		})
+	}
+	return record
+}
+
+func (r modelRecord) toEntry() *models.CatalogEntry {
+	entry := &models.CatalogEntry{
+		Model: &models.SmartModel{
+			Name:         r.Name,
+			Description:  r.Description,
+			Type:         models.ModelType(r.Type),
+			Category:     models.ModelCategory(r.Category),
+			Manufacturer: r.Manufacturer,
+			ModelNumber:  r.ModelNumber,
+			Metadata:     r.Metadata,
+		},
+	}
+	for _, feature := range r.Features {
+		entry.Features = append(entry.Features, &models.SmartFeature{
+			Name:          feature.Name,
+			Description:   feature.Description,
+			Protocol:      models.ProtocolType(feature.Protocol),
+			InterfacePath: feature.InterfacePath,
+			Parameters:    feature.Parameters,
+		})
+	}
+	return entry
+}
@@ -0,0 +1,153 @@
+package catalogfile
+
+import (
+	"bytes"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"testing"
+)
+
+func testEntries() []*models.CatalogEntry {
+	return []*models.CatalogEntry{
+		{
+			Model: &models.SmartModel{
+				ID:           uuid.New(),
+				Name:         "Smart Watch",
+				Description:  "A watch, with a comma",
+				Type:         models.DeviceType,
+				Category:     models.WearableCategory,
+				Manufacturer: "Acme",
+				ModelNumber:  "AC100",
+				Metadata:     map[string]interface{}{"color": "black", "weight": 42.5},
+			},
+			Features: []*models.SmartFeature{
+				{Name: "heart_rate", Description: "Heart rate", Protocol: models.MqttProtocol, InterfacePath: "/hr", Parameters: map[string]interface{}{"interval": 5.0}},
+				{Name: "steps", Description: "Step counter", Protocol: models.RestProtocol, InterfacePath: "/steps"},
+			},
+		},
+		{
+			Model: &models.SmartModel{
+				ID:           uuid.New(),
+				Name:         "Forecast",
+				Description:  "Weather service",
+				Type:         models.ServiceType,
+				Category:     models.WeatherCategory,
+				Manufacturer: "Acme",
+				ModelNumber:  "WS1",
+			},
+		},
+	}
+}
+
+func assertEntriesEqual(t *testing.T, expected, actual []*models.CatalogEntry) {
+	t.Helper()
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		want, got := expected[i].Model, actual[i].Model
+		assert.Equal(t, want.Name, got.Name)
+		assert.Equal(t, want.Description, got.Description)
+		assert.Equal(t, want.Type, got.Type)
+		assert.Equal(t, want.Category, got.Category)
+		assert.Equal(t, want.Manufacturer, got.Manufacturer)
+		assert.Equal(t, want.ModelNumber, got.ModelNumber)
+		assert.Equal(t, want.Metadata, got.Metadata)
+		assert.Equal(t, uuid.Nil, got.ID, "IDs are not imported")
+
+		require.Len(t, actual[i].Features, len(expected[i].Features))
+		for j, feature := range expected[i].Features {
+			assert.Equal(t, feature.Name, actual[i].Features[j].Name)
+			assert.Equal(t, feature.Description, actual[i].Features[j].Description)
+			assert.Equal(t, feature.Protocol, actual[i].Features[j].Protocol)
+			assert.Equal(t, feature.InterfacePath, actual[i].Features[j].InterfacePath)
+			assert.Equal(t, feature.Parameters, actual[i].Features[j].Parameters)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{JSON, YAML, CSV} {
+		t.Run(string(format), func(t *testing.T) {
+			entries := testEntries()
+
+			var buf bytes.Buffer
+			require.NoError(t, Encode(&buf, format, entries))
+
+			decoded, err := Decode(&buf, format)
+			require.NoError(t, err)
+			assertEntriesEqual(t, entries, decoded)
+		})
+	}
+}
+
+func TestDecodeCSV_GroupsRowsByModelNumber(t *testing.T) {
+	input := `model_number,manufacturer,name,type,category,description,feature_name,feature_protocol,feature_interface_path
+AC100,Acme,Watch,device,wearable,Watch,power,rest,/power
+WS1,Acme,Forecast,service,weather,Weather,,,
+AC100,Acme,Watch,device,wearable,Watch,status,mqtt,/status
+`
+	entries, err := Decode(strings.NewReader(input), CSV)
+
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "AC100", entries[0].Model.ModelNumber)
+	require.Len(t, entries[0].Features, 2)
+	assert.Equal(t, "power", entries[0].Features[0].Name)
+	assert.Equal(t, "status", entries[0].Features[1].Name)
+	assert.Equal(t, models.MqttProtocol, entries[0].Features[1].Protocol)
+	assert.Equal(t, "WS1", entries[1].Model.ModelNumber)
+	assert.Empty(t, entries[1].Features)
+}
+
+func TestDecodeCSV_Errors(t *testing.T) {
+	_, err := Decode(strings.NewReader("name,colour\nWatch,red\n"), CSV)
+	assert.ErrorContains(t, err, `unknown column "colour"`)
+
+	_, err = Decode(strings.NewReader("name,metadata\nWatch,{broken\n"), CSV)
+	assert.ErrorContains(t, err, "line 2: metadata")
+}
+
+func TestDecodeYAML(t *testing.T) {
+	input := `
+- name: Watch
+  type: device
+  manufacturer: Acme
+  model_number: AC100
+  metadata:
+    bands: [small, large]
+  features:
+    - name: power
+      protocol: grpc
+      interface_path: /power
+      parameters:
+        levels: {min: 0, max: 10}
+`
+	entries, err := Decode(strings.NewReader(input), YAML)
+
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []interface{}{"small", "large"}, entries[0].Model.Metadata["bands"])
+	require.Len(t, entries[0].Features, 1)
+	assert.Equal(t, models.GrpcProtocol, entries[0].Features[0].Protocol)
+	assert.Equal(t, map[string]interface{}{"min": 0.0, "max": 10.0}, entries[0].Features[0].Parameters["levels"])
+}
+
+func TestFormatFromPath(t *testing.T) {
+	for path, expected := range map[string]Format{
+		"catalog.json": JSON,
+		"catalog.yml":  YAML,
+		"catalog.YAML": YAML,
+		"dir/cat.csv":  CSV,
+	} {
+		format, err := FormatFromPath(path)
+		assert.NoError(t, err, path)
+		assert.Equal(t, expected, format, path)
+	}
+
+	_, err := FormatFromPath("catalog")
+	assert.Error(t, err)
+	_, err = FormatFromPath("catalog.xml")
+	assert.Error(t, err)
+}
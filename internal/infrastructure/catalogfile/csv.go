@@ -0,0 +1,176 @@
+package catalogfile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+var csvHeader = []string{
+	"id",
+	"name",
+	"description",
+	"type",
+	"category",
+	"manufacturer",
+	"model_number",
+	"metadata",
+	"feature_name",
+	"feature_description",
+	"feature_protocol",
+	"feature_interface_path",
+	"feature_parameters",
+}
+
+// readCSV reads the rows of a CSV catalog. Columns are matched by the names
+// in the header row, so their order is free and missing columns are empty.
+// metadata and feature_parameters hold JSON objects.
+func readCSV(r io.Reader) ([]modelRecord, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if !slices.Contains(csvHeader, name) {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[name] = i
+	}
+
+	var records []modelRecord
+	index := make(map[[2]string]int)
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		get := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return row[i]
+			}
+			return ""
+		}
+
+		model := modelRecord{
+			Name:         get("name"),
+			Description:  get("description"),
+			Type:         get("type"),
+			Category:     get("category"),
+			Manufacturer: get("manufacturer"),
+			ModelNumber:  get("model_number"),
+		}
+		if model.Metadata, err = parseJSONColumn(get("metadata")); err != nil {
+			return nil, fmt.Errorf("line %d: metadata: %w", line, err)
+		}
+
+		// Rows of a model already seen only contribute their feature.
+		key := [2]string{model.Manufacturer, model.ModelNumber}
+		pos, seen := index[key]
+		if !seen || model.Manufacturer == "" || model.ModelNumber == "" {
+			pos = len(records)
+			index[key] = pos
+			records = append(records, model)
+		}
+
+		if get("feature_name") == "" {
+			continue
+		}
+		parameters, err := parseJSONColumn(get("feature_parameters"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: feature_parameters: %w", line, err)
+		}
+		records[pos].Features = append(records[pos].Features, featureRecord{
+			Name:          get("feature_name"),
+			Description:   get("feature_description"),
+			Protocol:      get("feature_protocol"),
+			InterfacePath: get("feature_interface_path"),
+			Parameters:    parameters,
+		})
+	}
+}
+
+func writeCSV(w io.Writer, records []modelRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		metadata, err := formatJSONColumn(record.Metadata)
+		if err != nil {
+			return err
+		}
+		model := []string{
+			record.ID,
+			record.Name,
+			record.Description,
+			record.Type,
+			record.Category,
+			record.Manufacturer,
+			record.ModelNumber,
+			metadata,
+		}
+
+		if len(record.Features) == 0 {
+			if err := writer.Write(append(model, "", "", "", "", "")); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, feature := range record.Features {
+			parameters, err := formatJSONColumn(feature.Parameters)
+			if err != nil {
+				return err
+			}
+			row := append(model[:len(model):len(model)],
+				feature.Name,
+				feature.Description,
+				feature.Protocol,
+				feature.InterfacePath,
+				parameters,
+			)
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func parseJSONColumn(value string) (map[string]interface{}, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func formatJSONColumn(value map[string]interface{}) (string, error) {
+	if len(value) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
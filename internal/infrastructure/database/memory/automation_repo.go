@@ -0,0 +1,267 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type MemAutomationRepository struct {
+	store *Store
+}
+
+func NewMemAutomationRepository(store *Store) *MemAutomationRepository {
+	return &MemAutomationRepository{
+		store: store,
+	}
+}
+
+func (r *MemAutomationRepository) CreateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	stored, err := normalizeRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.store.write(ctx, func() error {
+		if _, exists := r.store.rules[stored.ID]; exists {
+			return ErrDuplicateKey
+		}
+		r.store.rules[stored.ID] = stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneRule(stored), nil
+}
+
+func (r *MemAutomationRepository) GetRule(ctx context.Context, id uuid.UUID) (*models.AutomationRule, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	rule, ok := r.store.rules[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return cloneRule(rule), nil
+}
+
+func (r *MemAutomationRepository) ListRules(ctx context.Context) ([]*models.AutomationRule, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var rules []*models.AutomationRule
+	for _, rule := range r.store.rules {
+		rules = append(rules, cloneRule(rule))
+	}
+	slices.SortFunc(rules, func(a, b *models.AutomationRule) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	return rules, nil
+}
+
+func (r *MemAutomationRepository) UpdateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	stored, err := normalizeRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.store.write(ctx, func() error {
+		existing, ok := r.store.rules[stored.ID]
+		if !ok {
+			return models.ErrNotFound
+		}
+		stored.CreatedAt = existing.CreatedAt
+		r.store.rules[stored.ID] = stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneRule(stored), nil
+}
+
+func (r *MemAutomationRepository) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func() error {
+		if _, ok := r.store.rules[id]; !ok {
+			return models.ErrNotFound
+		}
+		delete(r.store.rules, id)
+		for executionID, execution := range r.store.executions {
+			if execution.RuleID == id {
+				delete(r.store.executions, executionID)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *MemAutomationRepository) CreateExecution(ctx context.Context, execution *models.AutomationExecution) error {
+	trigger, err := normalizeJSON(execution.Trigger)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(ctx, func() error {
+		if _, exists := r.store.executions[execution.ID]; exists {
+			return ErrDuplicateKey
+		}
+		if _, ok := r.store.rules[execution.RuleID]; !ok {
+			return models.ErrNotFound
+		}
+		if execution.DedupKey != "" {
+			for _, other := range r.store.executions {
+				if other.DedupKey == execution.DedupKey {
+					return models.ErrAlreadyExists
+				}
+			}
+		}
+
+		stored := cloneExecution(execution)
+		stored.Trigger = emptyIfNil(trigger)
+		r.store.executions[stored.ID] = stored
+		return nil
+	})
+}
+
+func (r *MemAutomationRepository) FinishExecution(ctx context.Context, execution *models.AutomationExecution) error {
+	return r.store.write(ctx, func() error {
+		existing, ok := r.store.executions[execution.ID]
+		if !ok {
+			return models.ErrNotFound
+		}
+
+		stored := cloneExecution(existing)
+		stored.Status = execution.Status
+		stored.Error = execution.Error
+		stored.Results = slices.Clone(execution.Results)
+		if execution.FinishedAt != nil {
+			finishedAt := *execution.FinishedAt
+			stored.FinishedAt = &finishedAt
+		} else {
+			stored.FinishedAt = nil
+		}
+		r.store.executions[stored.ID] = stored
+		return nil
+	})
+}
+
+func (r *MemAutomationRepository) ListExecutions(ctx context.Context, filter models.ExecutionFilter) ([]*models.AutomationExecution, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var executions []*models.AutomationExecution
+	for _, execution := range r.store.executions {
+		if filter.RuleID != nil && execution.RuleID != *filter.RuleID {
+			continue
+		}
+		if filter.After != nil && !executionBefore(execution, filter.After) {
+			continue
+		}
+		executions = append(executions, cloneExecution(execution))
+	}
+	slices.SortFunc(executions, func(a, b *models.AutomationExecution) int {
+		if c := b.StartedAt.Compare(a.StartedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(b.ID[:], a.ID[:])
+	})
+	if len(executions) > filter.Limit {
+		executions = executions[:filter.Limit]
+	}
+
+	return executions, nil
+}
+
+func (r *MemAutomationRepository) PruneExecutions(ctx context.Context, before time.Time) (int64, error) {
+	var pruned int64
+	err := r.store.write(ctx, func() error {
+		for id, execution := range r.store.executions {
+			if execution.StartedAt.Before(before) {
+				delete(r.store.executions, id)
+				pruned++
+			}
+		}
+		return nil
+	})
+	return pruned, err
+}
+
+// executionBefore reports whether execution comes after cursor in the
+// newest-first listing.
+func executionBefore(execution *models.AutomationExecution, cursor *models.PageCursor) bool {
+	if c := execution.StartedAt.Compare(cursor.CreatedAt); c != 0 {
+		return c < 0
+	}
+	return bytes.Compare(execution.ID[:], cursor.ID[:]) < 0
+}
+
+// normalizeRule copies a rule with its JSON values as a Postgres round trip
+// would return them.
+func normalizeRule(rule *models.AutomationRule) (*models.AutomationRule, error) {
+	normalized := *rule
+	value, err := normalizeJSONValue(rule.Trigger.Value)
+	if err != nil {
+		return nil, err
+	}
+	normalized.Trigger.Value = value
+	normalized.Trigger.Weekdays = slices.Clone(rule.Trigger.Weekdays)
+
+	normalized.Actions = make([]models.RuleAction, len(rule.Actions))
+	for i, action := range rule.Actions {
+		parameters, err := normalizeJSON(action.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		action.Parameters = parameters
+		normalized.Actions[i] = action
+	}
+	return &normalized, nil
+}
+
+func normalizeJSONValue(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
+
+func cloneRule(rule *models.AutomationRule) *models.AutomationRule {
+	clone := *rule
+	clone.Trigger.Value = copyJSON(rule.Trigger.Value)
+	clone.Trigger.Weekdays = slices.Clone(rule.Trigger.Weekdays)
+	clone.Actions = make([]models.RuleAction, len(rule.Actions))
+	for i, action := range rule.Actions {
+		action.Parameters = copyJSONObject(action.Parameters)
+		clone.Actions[i] = action
+	}
+	return &clone
+}
+
+func cloneExecution(execution *models.AutomationExecution) *models.AutomationExecution {
+	clone := *execution
+	clone.Trigger = copyJSONObject(execution.Trigger)
+	clone.Results = slices.Clone(execution.Results)
+	if len(clone.Results) == 0 {
+		clone.Results = nil
+	}
+	if execution.FinishedAt != nil {
+		finishedAt := *execution.FinishedAt
+		clone.FinishedAt = &finishedAt
+	}
+	return &clone
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type MemCatalogChangeRepository struct {
+	store *Store
+}
+
+func NewMemCatalogChangeRepository(store *Store) *MemCatalogChangeRepository {
+	return &MemCatalogChangeRepository{
+		store: store,
+	}
+}
+
+func (r *MemCatalogChangeRepository) ListSince(ctx context.Context, after int64, limit int) ([]*models.CatalogChange, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var changes []*models.CatalogChange
+	for _, change := range r.store.changes {
+		if len(changes) == limit {
+			break
+		}
+		if change.Sequence > after {
+			changes = append(changes, cloneChange(change))
+		}
+	}
+
+	return changes, nil
+}
+
+func (r *MemCatalogChangeRepository) LatestSequence(ctx context.Context) (int64, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if len(r.store.changes) == 0 {
+		return 0, nil
+	}
+	return r.store.changes[len(r.store.changes)-1].Sequence, nil
+}
+
+func (r *MemCatalogChangeRepository) OldestSequence(ctx context.Context) (int64, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if len(r.store.changes) == 0 {
+		return 0, nil
+	}
+	return r.store.changes[0].Sequence, nil
+}
+
+func (r *MemCatalogChangeRepository) Prune(ctx context.Context, before time.Time) (int64, error) {
+	var pruned int64
+	err := r.store.write(ctx, func() error {
+		var kept []*models.CatalogChange
+		for _, change := range r.store.changes {
+			if change.ChangedAt.Before(before) {
+				pruned++
+				continue
+			}
+			kept = append(kept, change)
+		}
+		r.store.changes = kept
+		return nil
+	})
+	return pruned, err
+}
+
+func cloneChange(change *models.CatalogChange) *models.CatalogChange {
+	clone := *change
+	if change.OldModel != nil {
+		clone.OldModel = cloneModel(change.OldModel)
+	}
+	if change.NewModel != nil {
+		clone.NewModel = cloneModel(change.NewModel)
+	}
+	if change.OldFeature != nil {
+		clone.OldFeature = cloneFeature(change.OldFeature)
+	}
+	if change.NewFeature != nil {
+		clone.NewFeature = cloneFeature(change.NewFeature)
+	}
+	return &clone
+}
+
+// MemChangeListener calls onChange whenever catalog changes are committed to
+// the store.
+type MemChangeListener struct {
+	store *Store
+}
+
+func NewMemChangeListener(store *Store) *MemChangeListener {
+	return &MemChangeListener{
+		store: store,
+	}
+}
+
+func (l *MemChangeListener) Listen(ctx context.Context, onChange func()) error {
+	changed := l.store.subscribe()
+	defer l.store.unsubscribe(changed)
+
+	onChange()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+			onChange()
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"smart-hub/internal/infrastructure/database/repotest"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := NewStore()
+		return repotest.Repositories{
+			Models:        NewMemSmartModelRepository(store),
+			Features:      NewMemSmartFeatureRepository(store),
+			Telemetry:     NewMemTelemetryRepository(store),
+			Shadows:       NewMemShadowRepository(store),
+			Automations:   NewMemAutomationRepository(store),
+			Schedules:     NewMemScheduleRepository(store),
+			Scenes:        NewMemSceneRepository(store),
+			Relationships: NewMemRelationshipRepository(store),
+			Dependencies:  NewMemFeatureDependencyRepository(store),
+			Capabilities:  NewMemFeatureCapabilityRepository(store),
+			UnitOfWork:    NewMemUnitOfWork(store),
+		}
+	})
+}
@@ -0,0 +1,7 @@
+package memory
+
+import "errors"
+
+// ErrDuplicateKey is returned when a row with the same primary key already
+// exists.
+var ErrDuplicateKey = errors.New("duplicate key")
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"maps"
+	"slices"
+	"smart-hub/internal/domain/models"
+)
+
+type MemFeatureCapabilityRepository struct {
+	store *Store
+}
+
+func NewMemFeatureCapabilityRepository(store *Store) *MemFeatureCapabilityRepository {
+	return &MemFeatureCapabilityRepository{
+		store: store,
+	}
+}
+
+func (r *MemFeatureCapabilityRepository) SetFeatureCapability(ctx context.Context, capability *models.FeatureCapability) error {
+	return r.store.write(ctx, func() error {
+		if _, ok := r.store.features[capability.FeatureID]; !ok {
+			return fmt.Errorf("%w: smart feature %s", models.ErrNotFound, capability.FeatureID)
+		}
+		mapping := maps.Clone(capability.ParameterMapping)
+		if mapping == nil {
+			mapping = map[string]string{}
+		}
+		r.store.capabilities[capability.FeatureID] = &models.FeatureCapability{
+			FeatureID:        capability.FeatureID,
+			Capability:       capability.Capability,
+			ParameterMapping: mapping,
+		}
+		return nil
+	})
+}
+
+func (r *MemFeatureCapabilityRepository) GetFeatureCapability(ctx context.Context, featureID uuid.UUID) (*models.FeatureCapability, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	capability, ok := r.store.capabilities[featureID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return r.store.copyCapability(capability), nil
+}
+
+func (r *MemFeatureCapabilityRepository) DeleteFeatureCapability(ctx context.Context, featureID uuid.UUID) error {
+	return r.store.write(ctx, func() error {
+		if _, ok := r.store.capabilities[featureID]; !ok {
+			return models.ErrNotFound
+		}
+		delete(r.store.capabilities, featureID)
+		return nil
+	})
+}
+
+func (r *MemFeatureCapabilityRepository) ListByCapability(ctx context.Context, capability string) ([]*models.FeatureCapability, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var capabilities []*models.FeatureCapability
+	for _, c := range r.store.capabilities {
+		if c.Capability == capability {
+			capabilities = append(capabilities, r.store.copyCapability(c))
+		}
+	}
+	slices.SortFunc(capabilities, func(a, b *models.FeatureCapability) int {
+		if c := bytes.Compare(a.ModelID[:], b.ModelID[:]); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.FeatureID[:], b.FeatureID[:])
+	})
+	return capabilities, nil
+}
+
+// copyCapability returns a copy of capability with the model of its
+// feature, as the SQL repositories join it in. The caller holds the store
+// lock.
+func (s *Store) copyCapability(capability *models.FeatureCapability) *models.FeatureCapability {
+	c := *capability
+	c.ParameterMapping = maps.Clone(capability.ParameterMapping)
+	if feature, ok := s.features[capability.FeatureID]; ok {
+		c.ModelID = feature.ModelID
+	}
+	return &c
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"smart-hub/internal/domain/models"
+)
+
+type MemFeatureDependencyRepository struct {
+	store *Store
+}
+
+func NewMemFeatureDependencyRepository(store *Store) *MemFeatureDependencyRepository {
+	return &MemFeatureDependencyRepository{
+		store: store,
+	}
+}
+
+func (r *MemFeatureDependencyRepository) SetDependencies(ctx context.Context, featureID uuid.UUID, dependencies []models.FeatureDependency) error {
+	return r.store.write(ctx, func() error {
+		if _, ok := r.store.features[featureID]; !ok {
+			return models.ErrNotFound
+		}
+		seen := make(map[uuid.UUID]bool, len(dependencies))
+		for _, dependency := range dependencies {
+			if _, ok := r.store.features[dependency.DependsOnID]; !ok {
+				return fmt.Errorf("%w: smart feature %s", models.ErrNotFound, dependency.DependsOnID)
+			}
+			if dependency.DependsOnID == featureID {
+				return fmt.Errorf("smart feature %s cannot depend on itself", featureID)
+			}
+			if seen[dependency.DependsOnID] {
+				return ErrDuplicateKey
+			}
+			seen[dependency.DependsOnID] = true
+		}
+
+		if len(dependencies) == 0 {
+			delete(r.store.dependencies, featureID)
+			return nil
+		}
+		stored := make([]models.FeatureDependency, len(dependencies))
+		for i, dependency := range dependencies {
+			stored[i] = models.FeatureDependency{FeatureID: featureID, DependsOnID: dependency.DependsOnID, Kind: dependency.Kind}
+		}
+		sortDependencies(stored)
+		r.store.dependencies[featureID] = stored
+		return nil
+	})
+}
+
+func (r *MemFeatureDependencyRepository) ListDependencies(ctx context.Context, featureID uuid.UUID) ([]models.FeatureDependency, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	return slices.Clone(r.store.dependencies[featureID]), nil
+}
+
+func (r *MemFeatureDependencyRepository) ListModelDependencies(ctx context.Context, modelID uuid.UUID) ([]models.FeatureDependency, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var dependencies []models.FeatureDependency
+	for featureID, declared := range r.store.dependencies {
+		if feature, ok := r.store.features[featureID]; ok && feature.ModelID == modelID {
+			dependencies = append(dependencies, declared...)
+		}
+	}
+	sortDependencies(dependencies)
+	return dependencies, nil
+}
+
+// deleteDependencies drops the declarations of a deleted feature and those
+// that depend on it, like the foreign keys of the SQL schemas. The caller
+// holds the store lock.
+func (s *Store) deleteDependencies(featureID uuid.UUID) {
+	delete(s.dependencies, featureID)
+	for id, declared := range s.dependencies {
+		kept := slices.DeleteFunc(slices.Clone(declared), func(dependency models.FeatureDependency) bool {
+			return dependency.DependsOnID == featureID
+		})
+		switch {
+		case len(kept) == 0:
+			delete(s.dependencies, id)
+		case len(kept) != len(declared):
+			s.dependencies[id] = kept
+		}
+	}
+}
+
+// sortDependencies orders dependencies by feature, kind and the ID they
+// depend on, as the SQL repositories do.
+func sortDependencies(dependencies []models.FeatureDependency) {
+	slices.SortFunc(dependencies, func(a, b models.FeatureDependency) int {
+		if c := bytes.Compare(a.FeatureID[:], b.FeatureID[:]); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Kind, b.Kind); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.DependsOnID[:], b.DependsOnID[:])
+	})
+}
+
+// LockModelDependencies needs no lock: a unit of work holds the whole store.
+func (r *MemFeatureDependencyRepository) LockModelDependencies(ctx context.Context, modelID uuid.UUID) error {
+	return nil
+}
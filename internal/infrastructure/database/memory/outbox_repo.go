@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type MemOutboxRepository struct {
+	store *Store
+}
+
+func NewMemOutboxRepository(store *Store) *MemOutboxRepository {
+	return &MemOutboxRepository{
+		store: store,
+	}
+}
+
+func (r *MemOutboxRepository) Add(ctx context.Context, events ...*models.DomainEvent) error {
+	return r.store.write(ctx, func() error {
+		for _, event := range events {
+			r.store.outboxSeq++
+			r.store.outbox = append(r.store.outbox, &models.OutboxEvent{
+				DomainEvent: *event,
+				Sequence:    r.store.outboxSeq,
+			})
+		}
+		return nil
+	})
+}
+
+// FetchPending needs no relay lock: the surrounding unit of work already
+// holds the whole store.
+func (r *MemOutboxRepository) FetchPending(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var events []*models.OutboxEvent
+	for _, event := range r.store.outbox {
+		if len(events) == limit {
+			break
+		}
+		if event.PublishedAt == nil && event.DeadAt == nil {
+			pending := *event
+			events = append(events, &pending)
+		}
+	}
+
+	return events, nil
+}
+
+func (r *MemOutboxRepository) Claim(ctx context.Context, sequences []int64, until time.Time) error {
+	return r.updateEach(ctx, sequences, func(event *models.OutboxEvent) {
+		event.ClaimedUntil = &until
+	})
+}
+
+func (r *MemOutboxRepository) Release(ctx context.Context, sequences []int64) error {
+	return r.updateEach(ctx, sequences, func(event *models.OutboxEvent) {
+		event.ClaimedUntil = nil
+	})
+}
+
+func (r *MemOutboxRepository) MarkPublished(ctx context.Context, sequences []int64) error {
+	now := time.Now()
+	return r.updateEach(ctx, sequences, func(event *models.OutboxEvent) {
+		event.PublishedAt = &now
+		event.ClaimedUntil = nil
+		event.LastError = ""
+	})
+}
+
+func (r *MemOutboxRepository) MarkFailed(ctx context.Context, sequence int64, reason string, dead bool) error {
+	return r.updateEach(ctx, []int64{sequence}, func(event *models.OutboxEvent) {
+		event.Attempts++
+		event.LastError = reason
+		event.ClaimedUntil = nil
+		if dead {
+			now := time.Now()
+			event.DeadAt = &now
+		}
+	})
+}
+
+// updateEach applies fn to the events with the given sequences.
+func (r *MemOutboxRepository) updateEach(ctx context.Context, sequences []int64, fn func(event *models.OutboxEvent)) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	return r.store.write(ctx, func() error {
+		r.update(func(event *models.OutboxEvent) bool {
+			if !slices.Contains(sequences, event.Sequence) {
+				return false
+			}
+			fn(event)
+			return true
+		})
+		return nil
+	})
+}
+
+// update replaces every event fn modifies with a modified copy, leaving the
+// stored value untouched for snapshots that still reference it.
+func (r *MemOutboxRepository) update(fn func(event *models.OutboxEvent) bool) {
+	outbox := make([]*models.OutboxEvent, len(r.store.outbox))
+	for i, event := range r.store.outbox {
+		updated := *event
+		if fn(&updated) {
+			event = &updated
+		}
+		outbox[i] = event
+	}
+	r.store.outbox = outbox
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"smart-hub/internal/domain/models"
+)
+
+type MemRelationshipRepository struct {
+	store *Store
+}
+
+func NewMemRelationshipRepository(store *Store) *MemRelationshipRepository {
+	return &MemRelationshipRepository{
+		store: store,
+	}
+}
+
+func (r *MemRelationshipRepository) CreateRelationship(ctx context.Context, relationship *models.ModelRelationship) (*models.ModelRelationship, error) {
+	stored := *relationship
+	err := r.store.write(ctx, func() error {
+		if _, exists := r.store.relationships[stored.ID]; exists {
+			return ErrDuplicateKey
+		}
+		for _, id := range []uuid.UUID{stored.SourceModelID, stored.TargetModelID} {
+			if _, ok := r.store.models[id]; !ok {
+				return fmt.Errorf("%w: smart model %s", models.ErrNotFound, id)
+			}
+		}
+		for _, other := range r.store.relationships {
+			if other.Type == stored.Type && other.SourceModelID == stored.SourceModelID && other.TargetModelID == stored.TargetModelID {
+				return fmt.Errorf("%w: %s relationship from %s to %s", models.ErrAlreadyExists, stored.Type, stored.SourceModelID, stored.TargetModelID)
+			}
+		}
+		r.store.relationships[stored.ID] = &stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	created := stored
+	return &created, nil
+}
+
+func (r *MemRelationshipRepository) GetRelationship(ctx context.Context, id uuid.UUID) (*models.ModelRelationship, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	relationship, ok := r.store.relationships[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	clone := *relationship
+	return &clone, nil
+}
+
+func (r *MemRelationshipRepository) DeleteRelationship(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func() error {
+		if _, ok := r.store.relationships[id]; !ok {
+			return models.ErrNotFound
+		}
+		delete(r.store.relationships, id)
+		return nil
+	})
+}
+
+func (r *MemRelationshipRepository) ListRelationships(ctx context.Context, filter models.RelationshipFilter) ([]*models.ModelRelationship, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var relationships []*models.ModelRelationship
+	for _, relationship := range r.store.relationships {
+		if relationship.SourceModelID != filter.ModelID && relationship.TargetModelID != filter.ModelID {
+			continue
+		}
+		if filter.Type != "" && relationship.Type != filter.Type {
+			continue
+		}
+		clone := *relationship
+		relationships = append(relationships, &clone)
+	}
+	slices.SortFunc(relationships, func(a, b *models.ModelRelationship) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	return relationships, nil
+}
+
+// RelatedModels walks the graph breadth first, which visits every model at
+// its smallest depth.
+func (r *MemRelationshipRepository) RelatedModels(ctx context.Context, query models.RelationshipQuery) ([]*models.RelatedModel, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	neighbours := make(map[uuid.UUID][]uuid.UUID)
+	for _, relationship := range r.store.relationships {
+		if relationship.Type != query.Type {
+			continue
+		}
+		if query.Direction != models.DirectionIncoming {
+			neighbours[relationship.SourceModelID] = append(neighbours[relationship.SourceModelID], relationship.TargetModelID)
+		}
+		if query.Direction != models.DirectionOutgoing {
+			neighbours[relationship.TargetModelID] = append(neighbours[relationship.TargetModelID], relationship.SourceModelID)
+		}
+	}
+
+	var related []*models.RelatedModel
+	visited := map[uuid.UUID]bool{query.ModelID: true}
+	frontier := []uuid.UUID{query.ModelID}
+	for depth := 1; depth <= query.MaxDepth && len(frontier) > 0; depth++ {
+		var next []uuid.UUID
+		for _, id := range frontier {
+			for _, neighbour := range neighbours[id] {
+				if !visited[neighbour] {
+					visited[neighbour] = true
+					next = append(next, neighbour)
+				}
+			}
+		}
+		slices.SortFunc(next, func(a, b uuid.UUID) int {
+			return bytes.Compare(a[:], b[:])
+		})
+		for _, id := range next {
+			related = append(related, &models.RelatedModel{ModelID: id, Depth: depth})
+		}
+		frontier = next
+	}
+
+	return related, nil
+}
+
+// LockRelationshipType needs no lock: a unit of work holds the whole store.
+func (r *MemRelationshipRepository) LockRelationshipType(ctx context.Context, relationshipType models.RelationshipType) error {
+	return nil
+}
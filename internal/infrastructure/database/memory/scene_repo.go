@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"github.com/google/uuid"
+	"slices"
+	"smart-hub/internal/domain/models"
+)
+
+type MemSceneRepository struct {
+	store *Store
+}
+
+func NewMemSceneRepository(store *Store) *MemSceneRepository {
+	return &MemSceneRepository{
+		store: store,
+	}
+}
+
+func (r *MemSceneRepository) CreateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	stored, err := normalizeScene(scene)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.store.write(ctx, func() error {
+		if _, exists := r.store.scenes[stored.ID]; exists {
+			return ErrDuplicateKey
+		}
+		r.store.scenes[stored.ID] = stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneScene(stored), nil
+}
+
+func (r *MemSceneRepository) GetScene(ctx context.Context, id uuid.UUID) (*models.Scene, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	scene, ok := r.store.scenes[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return cloneScene(scene), nil
+}
+
+func (r *MemSceneRepository) ListScenes(ctx context.Context) ([]*models.Scene, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var scenes []*models.Scene
+	for _, scene := range r.store.scenes {
+		scenes = append(scenes, cloneScene(scene))
+	}
+	slices.SortFunc(scenes, func(a, b *models.Scene) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	return scenes, nil
+}
+
+func (r *MemSceneRepository) UpdateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	stored, err := normalizeScene(scene)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.store.write(ctx, func() error {
+		existing, ok := r.store.scenes[stored.ID]
+		if !ok {
+			return models.ErrNotFound
+		}
+		stored.CreatedAt = existing.CreatedAt
+		r.store.scenes[stored.ID] = stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneScene(stored), nil
+}
+
+func (r *MemSceneRepository) DeleteScene(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func() error {
+		if _, ok := r.store.scenes[id]; !ok {
+			return models.ErrNotFound
+		}
+		delete(r.store.scenes, id)
+		return nil
+	})
+}
+
+func normalizeScene(scene *models.Scene) (*models.Scene, error) {
+	normalized := *scene
+	normalized.Steps = make([]models.SceneStep, len(scene.Steps))
+	for i, step := range scene.Steps {
+		parameters, err := normalizeJSON(step.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		step.Parameters = parameters
+		normalized.Steps[i] = step
+	}
+	return &normalized, nil
+}
+
+func cloneScene(scene *models.Scene) *models.Scene {
+	clone := *scene
+	clone.Steps = make([]models.SceneStep, len(scene.Steps))
+	for i, step := range scene.Steps {
+		step.Parameters = copyJSONObject(step.Parameters)
+		clone.Steps[i] = step
+	}
+	return &clone
+}
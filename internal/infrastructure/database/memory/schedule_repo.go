@@ -0,0 +1,259 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type MemScheduleRepository struct {
+	store *Store
+}
+
+func NewMemScheduleRepository(store *Store) *MemScheduleRepository {
+	return &MemScheduleRepository{
+		store: store,
+	}
+}
+
+func (r *MemScheduleRepository) CreateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	stored, err := normalizeSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.store.write(ctx, func() error {
+		if _, exists := r.store.schedules[stored.ID]; exists {
+			return ErrDuplicateKey
+		}
+		r.store.schedules[stored.ID] = stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneSchedule(stored), nil
+}
+
+func (r *MemScheduleRepository) GetSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	schedule, ok := r.store.schedules[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return cloneSchedule(schedule), nil
+}
+
+func (r *MemScheduleRepository) ListSchedules(ctx context.Context) ([]*models.Schedule, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var schedules []*models.Schedule
+	for _, schedule := range r.store.schedules {
+		schedules = append(schedules, cloneSchedule(schedule))
+	}
+	slices.SortFunc(schedules, func(a, b *models.Schedule) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	return schedules, nil
+}
+
+func (r *MemScheduleRepository) UpdateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	stored, err := normalizeSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.store.write(ctx, func() error {
+		existing, ok := r.store.schedules[stored.ID]
+		if !ok {
+			return models.ErrNotFound
+		}
+		stored.CreatedAt = existing.CreatedAt
+		r.store.schedules[stored.ID] = stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneSchedule(stored), nil
+}
+
+func (r *MemScheduleRepository) DeleteSchedule(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func() error {
+		if _, ok := r.store.schedules[id]; !ok {
+			return models.ErrNotFound
+		}
+		delete(r.store.schedules, id)
+		for runID, run := range r.store.scheduleRuns {
+			if run.ScheduleID == id {
+				delete(r.store.scheduleRuns, runID)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *MemScheduleRepository) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]*models.Schedule, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var schedules []*models.Schedule
+	for _, schedule := range r.store.schedules {
+		if !schedule.Paused && !schedule.NextRunAt.After(now) {
+			schedules = append(schedules, cloneSchedule(schedule))
+		}
+	}
+	slices.SortFunc(schedules, func(a, b *models.Schedule) int {
+		if c := a.NextRunAt.Compare(b.NextRunAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+	if len(schedules) > limit {
+		schedules = schedules[:limit]
+	}
+
+	return schedules, nil
+}
+
+func (r *MemScheduleRepository) AdvanceSchedule(ctx context.Context, id uuid.UUID, due, next time.Time) error {
+	return r.store.write(ctx, func() error {
+		existing, ok := r.store.schedules[id]
+		if !ok {
+			return models.ErrNotFound
+		}
+		if existing.Paused || !existing.NextRunAt.Equal(due) {
+			return fmt.Errorf("%w: schedule %s is no longer due at %s", models.ErrVersionConflict, id, due.Format(time.RFC3339))
+		}
+
+		stored := cloneSchedule(existing)
+		stored.NextRunAt = next
+		stored.LastRunAt = &due
+		r.store.schedules[id] = stored
+		return nil
+	})
+}
+
+func (r *MemScheduleRepository) CreateRun(ctx context.Context, run *models.ScheduleRun) error {
+	return r.store.write(ctx, func() error {
+		if _, exists := r.store.scheduleRuns[run.ID]; exists {
+			return ErrDuplicateKey
+		}
+		if _, ok := r.store.schedules[run.ScheduleID]; !ok {
+			return models.ErrNotFound
+		}
+		r.store.scheduleRuns[run.ID] = cloneScheduleRun(run)
+		return nil
+	})
+}
+
+func (r *MemScheduleRepository) FinishRun(ctx context.Context, run *models.ScheduleRun) error {
+	return r.store.write(ctx, func() error {
+		existing, ok := r.store.scheduleRuns[run.ID]
+		if !ok {
+			return models.ErrNotFound
+		}
+
+		stored := cloneScheduleRun(existing)
+		stored.Status = run.Status
+		stored.Error = run.Error
+		if run.FinishedAt != nil {
+			finishedAt := *run.FinishedAt
+			stored.FinishedAt = &finishedAt
+		} else {
+			stored.FinishedAt = nil
+		}
+		r.store.scheduleRuns[stored.ID] = stored
+		return nil
+	})
+}
+
+func (r *MemScheduleRepository) ListRuns(ctx context.Context, filter models.ScheduleRunFilter) ([]*models.ScheduleRun, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var runs []*models.ScheduleRun
+	for _, run := range r.store.scheduleRuns {
+		if filter.ScheduleID != nil && run.ScheduleID != *filter.ScheduleID {
+			continue
+		}
+		if filter.After != nil && !runBefore(run, filter.After) {
+			continue
+		}
+		runs = append(runs, cloneScheduleRun(run))
+	}
+	slices.SortFunc(runs, func(a, b *models.ScheduleRun) int {
+		if c := b.StartedAt.Compare(a.StartedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(b.ID[:], a.ID[:])
+	})
+	if len(runs) > filter.Limit {
+		runs = runs[:filter.Limit]
+	}
+
+	return runs, nil
+}
+
+func (r *MemScheduleRepository) PruneRuns(ctx context.Context, before time.Time) (int64, error) {
+	var pruned int64
+	err := r.store.write(ctx, func() error {
+		for id, run := range r.store.scheduleRuns {
+			if run.StartedAt.Before(before) {
+				delete(r.store.scheduleRuns, id)
+				pruned++
+			}
+		}
+		return nil
+	})
+	return pruned, err
+}
+
+// runBefore reports whether run comes after cursor in the newest-first
+// listing.
+func runBefore(run *models.ScheduleRun, cursor *models.PageCursor) bool {
+	if c := run.StartedAt.Compare(cursor.CreatedAt); c != 0 {
+		return c < 0
+	}
+	return bytes.Compare(run.ID[:], cursor.ID[:]) < 0
+}
+
+func normalizeSchedule(schedule *models.Schedule) (*models.Schedule, error) {
+	parameters, err := normalizeJSON(schedule.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	normalized := cloneSchedule(schedule)
+	normalized.Parameters = emptyIfNil(parameters)
+	return normalized, nil
+}
+
+func cloneSchedule(schedule *models.Schedule) *models.Schedule {
+	clone := *schedule
+	clone.Parameters = copyJSONObject(schedule.Parameters)
+	if schedule.LastRunAt != nil {
+		lastRunAt := *schedule.LastRunAt
+		clone.LastRunAt = &lastRunAt
+	}
+	return &clone
+}
+
+func cloneScheduleRun(run *models.ScheduleRun) *models.ScheduleRun {
+	clone := *run
+	if run.FinishedAt != nil {
+		finishedAt := *run.FinishedAt
+		clone.FinishedAt = &finishedAt
+	}
+	return &clone
+}
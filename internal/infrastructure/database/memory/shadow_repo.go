@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+type MemShadowRepository struct {
+	store *Store
+}
+
+func NewMemShadowRepository(store *Store) *MemShadowRepository {
+	return &MemShadowRepository{
+		store: store,
+	}
+}
+
+func (r *MemShadowRepository) GetShadow(ctx context.Context, deviceID string) (*models.DeviceShadow, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	shadow, ok := r.store.shadows[deviceID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return cloneShadow(shadow), nil
+}
+
+func (r *MemShadowRepository) ListShadows(ctx context.Context, deviceIDs []string) ([]*models.DeviceShadow, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var shadows []*models.DeviceShadow
+	for deviceID, shadow := range r.store.shadows {
+		if len(deviceIDs) == 0 || slices.Contains(deviceIDs, deviceID) {
+			shadows = append(shadows, cloneShadow(shadow))
+		}
+	}
+	slices.SortFunc(shadows, func(a, b *models.DeviceShadow) int {
+		return strings.Compare(a.DeviceID, b.DeviceID)
+	})
+
+	return shadows, nil
+}
+
+func (r *MemShadowRepository) SaveShadow(ctx context.Context, shadow *models.DeviceShadow, expectedVersion int64) error {
+	desired, err := normalizeJSON(shadow.Desired)
+	if err != nil {
+		return err
+	}
+	reported, err := normalizeJSON(shadow.Reported)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(ctx, func() error {
+		existing, ok := r.store.shadows[shadow.DeviceID]
+		switch {
+		case expectedVersion == 0 && ok:
+			return models.ErrVersionConflict
+		case expectedVersion != 0 && (!ok || existing.Version != expectedVersion):
+			return models.ErrVersionConflict
+		}
+
+		stored := *shadow
+		stored.Desired = emptyIfNil(desired)
+		stored.Reported = emptyIfNil(reported)
+		if ok {
+			stored.CreatedAt = existing.CreatedAt
+		}
+		r.store.shadows[stored.DeviceID] = &stored
+		return nil
+	})
+}
+
+func (r *MemShadowRepository) DeleteShadow(ctx context.Context, deviceID string) error {
+	return r.store.write(ctx, func() error {
+		if _, ok := r.store.shadows[deviceID]; !ok {
+			return models.ErrNotFound
+		}
+		delete(r.store.shadows, deviceID)
+		return nil
+	})
+}
+
+func (r *MemShadowRepository) AddDelta(ctx context.Context, delta *models.ShadowDelta) error {
+	normalized, err := normalizeJSON(delta.Delta)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(ctx, func() error {
+		r.store.shadowSeq++
+		delta.Sequence = r.store.shadowSeq
+
+		stored := *delta
+		stored.Delta = emptyIfNil(normalized)
+		r.store.shadowDeltas = append(r.store.shadowDeltas, &stored)
+		return nil
+	})
+}
+
+func (r *MemShadowRepository) ListDeltasSince(ctx context.Context, after int64, limit int) ([]*models.ShadowDelta, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var deltas []*models.ShadowDelta
+	for _, delta := range r.store.shadowDeltas {
+		if len(deltas) == limit {
+			break
+		}
+		if delta.Sequence > after {
+			clone := *delta
+			clone.Delta = copyJSONObject(delta.Delta)
+			deltas = append(deltas, &clone)
+		}
+	}
+
+	return deltas, nil
+}
+
+func (r *MemShadowRepository) LatestDeltaSequence(ctx context.Context) (int64, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if len(r.store.shadowDeltas) == 0 {
+		return 0, nil
+	}
+	return r.store.shadowDeltas[len(r.store.shadowDeltas)-1].Sequence, nil
+}
+
+func (r *MemShadowRepository) OldestDeltaSequence(ctx context.Context) (int64, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if len(r.store.shadowDeltas) == 0 {
+		return 0, nil
+	}
+	return r.store.shadowDeltas[0].Sequence, nil
+}
+
+func (r *MemShadowRepository) PruneDeltas(ctx context.Context, before time.Time) (int64, error) {
+	var pruned int64
+	err := r.store.write(ctx, func() error {
+		var kept []*models.ShadowDelta
+		for _, delta := range r.store.shadowDeltas {
+			if delta.CreatedAt.Before(before) {
+				pruned++
+				continue
+			}
+			kept = append(kept, delta)
+		}
+		r.store.shadowDeltas = kept
+		return nil
+	})
+	return pruned, err
+}
+
+func cloneShadow(shadow *models.DeviceShadow) *models.DeviceShadow {
+	clone := *shadow
+	clone.Desired = copyJSONObject(shadow.Desired)
+	clone.Reported = copyJSONObject(shadow.Reported)
+	return &clone
+}
+
+// emptyIfNil stores a nil map as an empty object, like the NOT NULL
+// defaults of the SQL schemas.
+func emptyIfNil(value map[string]interface{}) map[string]interface{} {
+	if value == nil {
+		return map[string]interface{}{}
+	}
+	return value
+}
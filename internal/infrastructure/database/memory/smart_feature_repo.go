@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"strings"
+)
+
+type MemSmartFeatureRepository struct {
+	store *Store
+}
+
+func NewMemSmartFeatureRepository(store *Store) *MemSmartFeatureRepository {
+	return &MemSmartFeatureRepository{
+		store: store,
+	}
+}
+
+func (r *MemSmartFeatureRepository) Create(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error) {
+	parameters, err := normalizeJSON(feature.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var created *models.SmartFeature
+	err = r.store.write(ctx, func() error {
+		if _, exists := r.store.features[feature.ID]; exists {
+			return ErrDuplicateKey
+		}
+		if _, exists := r.store.models[feature.ModelID]; !exists {
+			return fmt.Errorf("%w: smart model %s", models.ErrNotFound, feature.ModelID)
+		}
+		if err := r.checkNamePath(feature.ModelID, feature); err != nil {
+			return err
+		}
+
+		stored := *feature
+		stored.Parameters = parameters
+		r.store.features[stored.ID] = &stored
+		r.store.recordChange(&models.CatalogChange{
+			Entity:     models.SmartFeatureAggregate,
+			Operation:  models.ChangeCreated,
+			EntityID:   stored.ID,
+			ModelID:    stored.ModelID,
+			NewFeature: cloneFeature(&stored),
+		})
+
+		created = cloneFeature(&stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r *MemSmartFeatureRepository) GetByID(ctx context.Context, id string) (*models.SmartFeature, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	featureID, ok := parseID(id)
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	feature, ok := r.store.features[featureID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+
+	return cloneFeature(feature), nil
+}
+
+func (r *MemSmartFeatureRepository) GetWithModelID(ctx context.Context, modelID string) ([]*models.SmartFeature, error) {
+	id, ok := parseID(modelID)
+	if !ok {
+		return nil, nil
+	}
+
+	return r.list(ctx, func(feature *models.SmartFeature) bool {
+		return feature.ModelID == id
+	}), nil
+}
+
+func (r *MemSmartFeatureRepository) GetWithModelIDs(ctx context.Context, modelIDs []string) ([]*models.SmartFeature, error) {
+	wanted := idSet(modelIDs)
+	return r.list(ctx, func(feature *models.SmartFeature) bool {
+		_, ok := wanted[feature.ModelID]
+		return ok
+	}), nil
+}
+
+func (r *MemSmartFeatureRepository) List(ctx context.Context, filter models.FeatureFilter, after *models.PageCursor, limit int) ([]*models.SmartFeature, error) {
+	features := r.list(ctx, func(feature *models.SmartFeature) bool {
+		if after != nil && compareFeaturePosition(feature, after) <= 0 {
+			return false
+		}
+		model, ok := r.store.models[feature.ModelID]
+		return ok && filter.Matches(feature, model)
+	})
+	if len(features) > limit {
+		features = features[:limit]
+	}
+	return features, nil
+}
+
+func (r *MemSmartFeatureRepository) GetAll(ctx context.Context) ([]*models.SmartFeature, error) {
+	return r.list(ctx, func(*models.SmartFeature) bool {
+		return true
+	}), nil
+}
+
+// Update changes everything but the owning model, which a feature cannot
+// move between.
+func (r *MemSmartFeatureRepository) Update(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error) {
+	parameters, err := normalizeJSON(feature.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *models.SmartFeature
+	err = r.store.write(ctx, func() error {
+		existing, ok := r.store.features[feature.ID]
+		if !ok {
+			return models.ErrNotFound
+		}
+		if err := r.checkNamePath(existing.ModelID, feature); err != nil {
+			return err
+		}
+
+		stored := *feature
+		stored.Parameters = parameters
+		stored.ModelID = existing.ModelID
+		stored.CreatedAt = existing.CreatedAt
+		r.store.features[stored.ID] = &stored
+		r.store.recordChange(&models.CatalogChange{
+			Entity:     models.SmartFeatureAggregate,
+			Operation:  models.ChangeUpdated,
+			EntityID:   stored.ID,
+			ModelID:    stored.ModelID,
+			OldFeature: cloneFeature(existing),
+			NewFeature: cloneFeature(&stored),
+		})
+
+		updated = cloneFeature(&stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (r *MemSmartFeatureRepository) Delete(ctx context.Context, id string) error {
+	return r.store.write(ctx, func() error {
+		featureID, ok := parseID(id)
+		if !ok {
+			return models.ErrNotFound
+		}
+		existing, ok := r.store.features[featureID]
+		if !ok {
+			return models.ErrNotFound
+		}
+
+		delete(r.store.features, featureID)
+		r.store.deleteDependencies(featureID)
+		delete(r.store.capabilities, featureID)
+		r.store.recordChange(&models.CatalogChange{
+			Entity:     models.SmartFeatureAggregate,
+			Operation:  models.ChangeDeleted,
+			EntityID:   featureID,
+			ModelID:    existing.ModelID,
+			OldFeature: cloneFeature(existing),
+		})
+
+		return nil
+	})
+}
+
+func (r *MemSmartFeatureRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.SmartFeature, error) {
+	wanted := idSet(ids)
+	return r.list(ctx, func(feature *models.SmartFeature) bool {
+		_, ok := wanted[feature.ID]
+		return ok
+	}), nil
+}
+
+func (r *MemSmartFeatureRepository) CreateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error) {
+	return r.writeBatch(ctx, features, r.Create)
+}
+
+func (r *MemSmartFeatureRepository) UpdateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error) {
+	return r.writeBatch(ctx, features, r.Update)
+}
+
+func (r *MemSmartFeatureRepository) DeleteBatch(ctx context.Context, ids []string) error {
+	return NewMemUnitOfWork(r.store).Do(ctx, func(ctx context.Context) error {
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if err := r.Delete(ctx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeBatch applies write to every feature in one unit of work, so a failure
+// undoes the features written before it.
+func (r *MemSmartFeatureRepository) writeBatch(
+	ctx context.Context,
+	features []*models.SmartFeature,
+	write func(context.Context, *models.SmartFeature) (*models.SmartFeature, error),
+) ([]*models.SmartFeature, error) {
+	written := make([]*models.SmartFeature, 0, len(features))
+	err := NewMemUnitOfWork(r.store).Do(ctx, func(ctx context.Context) error {
+		for i, feature := range features {
+			result, err := write(ctx, feature)
+			if err != nil {
+				return &models.BatchItemError{Index: i, Err: err}
+			}
+			written = append(written, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return written, nil
+}
+
+// checkNamePath enforces the unique index on name and interface path within
+// a model of the Postgres schema for feature, stored under modelID. The
+// caller holds the store lock.
+func (r *MemSmartFeatureRepository) checkNamePath(modelID uuid.UUID, feature *models.SmartFeature) error {
+	for _, other := range r.store.features {
+		if other.ID != feature.ID && other.ModelID == modelID &&
+			strings.ToLower(other.Name) == strings.ToLower(feature.Name) &&
+			strings.ToLower(other.InterfacePath) == strings.ToLower(feature.InterfacePath) {
+			return &models.AlreadyExistsError{Entity: models.SmartFeatureAggregate, ID: other.ID}
+		}
+	}
+	return nil
+}
+
+func (r *MemSmartFeatureRepository) list(ctx context.Context, match func(*models.SmartFeature) bool) []*models.SmartFeature {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var result []*models.SmartFeature
+	for _, feature := range sortedFeatures(r.store.features) {
+		if match(feature) {
+			result = append(result, cloneFeature(feature))
+		}
+	}
+
+	return result
+}
+
+// sortedFeatures returns the stored features by creation time and then ID,
+// the ORDER BY of the Postgres repository.
+func sortedFeatures(features map[uuid.UUID]*models.SmartFeature) []*models.SmartFeature {
+	sorted := make([]*models.SmartFeature, 0, len(features))
+	for _, feature := range features {
+		sorted = append(sorted, feature)
+	}
+	slices.SortFunc(sorted, func(a, b *models.SmartFeature) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+	return sorted
+}
+
+// compareFeaturePosition orders feature against a page cursor the way
+// sortedFeatures orders features.
+func compareFeaturePosition(feature *models.SmartFeature, cursor *models.PageCursor) int {
+	if c := feature.CreatedAt.Compare(cursor.CreatedAt); c != 0 {
+		return c
+	}
+	return bytes.Compare(feature.ID[:], cursor.ID[:])
+}
@@ -0,0 +1,231 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"strings"
+)
+
+type MemSmartModelRepository struct {
+	store *Store
+}
+
+func NewMemSmartModelRepository(store *Store) *MemSmartModelRepository {
+	return &MemSmartModelRepository{
+		store: store,
+	}
+}
+
+func (r *MemSmartModelRepository) Create(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error) {
+	metadata, err := normalizeJSON(model.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var created *models.SmartModel
+	err = r.store.write(ctx, func() error {
+		if _, exists := r.store.models[model.ID]; exists {
+			return ErrDuplicateKey
+		}
+		if err := r.checkModelNumber(model); err != nil {
+			return err
+		}
+
+		stored := *model
+		stored.Metadata = metadata
+		stored.Features = nil
+		r.store.models[stored.ID] = &stored
+		r.store.recordChange(&models.CatalogChange{
+			Entity:    models.SmartModelAggregate,
+			Operation: models.ChangeCreated,
+			EntityID:  stored.ID,
+			ModelID:   stored.ID,
+			NewModel:  cloneModel(&stored),
+		})
+
+		created = cloneModel(&stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r *MemSmartModelRepository) GetByID(ctx context.Context, id string) (*models.SmartModel, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	modelID, ok := parseID(id)
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	model, ok := r.store.models[modelID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+
+	return cloneModel(model), nil
+}
+
+func (r *MemSmartModelRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.SmartModel, error) {
+	wanted := idSet(ids)
+	return r.list(ctx, func(model *models.SmartModel) bool {
+		_, ok := wanted[model.ID]
+		return ok
+	}), nil
+}
+
+func (r *MemSmartModelRepository) GetByModelNumber(ctx context.Context, manufacturer, modelNumber string) (*models.SmartModel, error) {
+	matches := r.list(ctx, func(model *models.SmartModel) bool {
+		return strings.ToLower(model.Manufacturer) == strings.ToLower(manufacturer) &&
+			strings.ToLower(model.ModelNumber) == strings.ToLower(modelNumber)
+	})
+	if len(matches) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return matches[0], nil
+}
+
+func (r *MemSmartModelRepository) GetWithType(ctx context.Context, modelType models.ModelType) ([]*models.SmartModel, error) {
+	return r.list(ctx, func(model *models.SmartModel) bool {
+		return model.Type == modelType
+	}), nil
+}
+
+func (r *MemSmartModelRepository) GetAll(ctx context.Context) ([]*models.SmartModel, error) {
+	return r.list(ctx, func(*models.SmartModel) bool {
+		return true
+	}), nil
+}
+
+func (r *MemSmartModelRepository) Update(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error) {
+	metadata, err := normalizeJSON(model.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *models.SmartModel
+	err = r.store.write(ctx, func() error {
+		existing, ok := r.store.models[model.ID]
+		if !ok {
+			return models.ErrNotFound
+		}
+		if err := r.checkModelNumber(model); err != nil {
+			return err
+		}
+
+		stored := *model
+		stored.Metadata = metadata
+		stored.Features = nil
+		stored.CreatedAt = existing.CreatedAt
+		r.store.models[stored.ID] = &stored
+		r.store.recordChange(&models.CatalogChange{
+			Entity:    models.SmartModelAggregate,
+			Operation: models.ChangeUpdated,
+			EntityID:  stored.ID,
+			ModelID:   stored.ID,
+			OldModel:  cloneModel(existing),
+			NewModel:  cloneModel(&stored),
+		})
+
+		updated = cloneModel(&stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// Delete removes the model together with its features, like the ON DELETE
+// CASCADE foreign key of the Postgres schema.
+func (r *MemSmartModelRepository) Delete(ctx context.Context, id string) error {
+	return r.store.write(ctx, func() error {
+		modelID, ok := parseID(id)
+		if !ok {
+			return models.ErrNotFound
+		}
+		existing, ok := r.store.models[modelID]
+		if !ok {
+			return models.ErrNotFound
+		}
+
+		delete(r.store.models, modelID)
+		r.store.recordChange(&models.CatalogChange{
+			Entity:    models.SmartModelAggregate,
+			Operation: models.ChangeDeleted,
+			EntityID:  modelID,
+			ModelID:   modelID,
+			OldModel:  cloneModel(existing),
+		})
+
+		for _, feature := range sortedFeatures(r.store.features) {
+			if feature.ModelID != modelID {
+				continue
+			}
+			delete(r.store.features, feature.ID)
+			r.store.deleteDependencies(feature.ID)
+			delete(r.store.capabilities, feature.ID)
+			r.store.recordChange(&models.CatalogChange{
+				Entity:     models.SmartFeatureAggregate,
+				Operation:  models.ChangeDeleted,
+				EntityID:   feature.ID,
+				ModelID:    modelID,
+				OldFeature: cloneFeature(feature),
+			})
+		}
+		for id, relationship := range r.store.relationships {
+			if relationship.SourceModelID == modelID || relationship.TargetModelID == modelID {
+				delete(r.store.relationships, id)
+			}
+		}
+
+		return nil
+	})
+}
+
+// checkModelNumber enforces the unique index on manufacturer and model
+// number of the Postgres schema. The caller holds the store lock.
+func (r *MemSmartModelRepository) checkModelNumber(model *models.SmartModel) error {
+	if model.ModelNumber == "" {
+		return nil
+	}
+	for _, other := range r.store.models {
+		if other.ID != model.ID &&
+			strings.ToLower(other.Manufacturer) == strings.ToLower(model.Manufacturer) &&
+			strings.ToLower(other.ModelNumber) == strings.ToLower(model.ModelNumber) {
+			return &models.AlreadyExistsError{Entity: models.SmartModelAggregate, ID: other.ID}
+		}
+	}
+	return nil
+}
+
+func (r *MemSmartModelRepository) list(ctx context.Context, match func(*models.SmartModel) bool) []*models.SmartModel {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var result []*models.SmartModel
+	for _, model := range r.store.models {
+		if match(model) {
+			result = append(result, cloneModel(model))
+		}
+	}
+	slices.SortFunc(result, compareModels)
+
+	return result
+}
+
+// compareModels orders models by creation time and then ID, the ORDER BY of
+// the Postgres repository.
+func compareModels(a, b *models.SmartModel) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
@@ -0,0 +1,294 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"smart-hub/internal/domain/models"
+	"sync"
+	"time"
+)
+
+// Store holds every table of the in-memory backend. All access is
+// serialized: a unit of work holds the store exclusively until it commits or
+// rolls back, so other callers never see uncommitted state.
+type Store struct {
+	mu sync.Mutex
+
+	models        map[uuid.UUID]*models.SmartModel
+	features      map[uuid.UUID]*models.SmartFeature
+	outbox        []*models.OutboxEvent
+	outboxSeq     int64
+	changes       []*models.CatalogChange
+	changeSeq     int64
+	subscriptions map[uuid.UUID]*models.WebhookSubscription
+	deliveries    map[uuid.UUID]*models.WebhookDelivery
+	shadows       map[string]*models.DeviceShadow
+	shadowDeltas  []*models.ShadowDelta
+	shadowSeq     int64
+	rules         map[uuid.UUID]*models.AutomationRule
+	executions    map[uuid.UUID]*models.AutomationExecution
+	schedules     map[uuid.UUID]*models.Schedule
+	scheduleRuns  map[uuid.UUID]*models.ScheduleRun
+	scenes        map[uuid.UUID]*models.Scene
+	relationships map[uuid.UUID]*models.ModelRelationship
+	// dependencies holds the declarations of each feature that has any.
+	dependencies map[uuid.UUID][]models.FeatureDependency
+	// capabilities holds the capability of each feature that implements one.
+	capabilities map[uuid.UUID]*models.FeatureCapability
+
+	listenersMu sync.Mutex
+	listeners   map[chan struct{}]struct{}
+
+	// Telemetry is not transactional, so it is kept out of snapshots and
+	// guarded by its own mutex.
+	telemetryMu       sync.Mutex
+	readings          map[readingKey]float64
+	retentionPolicies map[uuid.UUID]*models.RetentionPolicy
+}
+
+func NewStore() *Store {
+	return &Store{
+		models:        make(map[uuid.UUID]*models.SmartModel),
+		features:      make(map[uuid.UUID]*models.SmartFeature),
+		subscriptions: make(map[uuid.UUID]*models.WebhookSubscription),
+		deliveries:    make(map[uuid.UUID]*models.WebhookDelivery),
+		shadows:       make(map[string]*models.DeviceShadow),
+		rules:         make(map[uuid.UUID]*models.AutomationRule),
+		executions:    make(map[uuid.UUID]*models.AutomationExecution),
+		schedules:     make(map[uuid.UUID]*models.Schedule),
+		scheduleRuns:  make(map[uuid.UUID]*models.ScheduleRun),
+		scenes:        make(map[uuid.UUID]*models.Scene),
+		relationships: make(map[uuid.UUID]*models.ModelRelationship),
+		dependencies:  make(map[uuid.UUID][]models.FeatureDependency),
+		capabilities:  make(map[uuid.UUID]*models.FeatureCapability),
+		listeners:     make(map[chan struct{}]struct{}),
+
+		readings:          make(map[readingKey]float64),
+		retentionPolicies: make(map[uuid.UUID]*models.RetentionPolicy),
+	}
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (s *Store) Close() {}
+
+type txKey struct{}
+
+// lock acquires the store unless ctx belongs to a unit of work that already
+// holds it.
+func (s *Store) lock(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *Store) inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) == s
+}
+
+// write runs fn with the store locked and wakes up change listeners when fn
+// recorded catalog changes. Inside a unit of work that happens on commit.
+func (s *Store) write(ctx context.Context, fn func() error) error {
+	unlock := s.lock(ctx)
+	before := s.changeSeq
+	err := fn()
+	changed := s.changeSeq != before
+	unlock()
+
+	if changed && !s.inTx(ctx) {
+		s.notify()
+	}
+	return err
+}
+
+// snapshot is a copy of the table maps and slices. Stored values are never
+// mutated in place, so sharing them between snapshot and store is safe.
+type snapshot struct {
+	models        map[uuid.UUID]*models.SmartModel
+	features      map[uuid.UUID]*models.SmartFeature
+	outbox        []*models.OutboxEvent
+	outboxSeq     int64
+	changes       []*models.CatalogChange
+	changeSeq     int64
+	subscriptions map[uuid.UUID]*models.WebhookSubscription
+	deliveries    map[uuid.UUID]*models.WebhookDelivery
+	shadows       map[string]*models.DeviceShadow
+	shadowDeltas  []*models.ShadowDelta
+	shadowSeq     int64
+	rules         map[uuid.UUID]*models.AutomationRule
+	executions    map[uuid.UUID]*models.AutomationExecution
+	schedules     map[uuid.UUID]*models.Schedule
+	scheduleRuns  map[uuid.UUID]*models.ScheduleRun
+	scenes        map[uuid.UUID]*models.Scene
+	relationships map[uuid.UUID]*models.ModelRelationship
+	dependencies  map[uuid.UUID][]models.FeatureDependency
+	capabilities  map[uuid.UUID]*models.FeatureCapability
+}
+
+func (s *Store) snapshot() snapshot {
+	return snapshot{
+		models:        cloneMap(s.models),
+		features:      cloneMap(s.features),
+		outbox:        append([]*models.OutboxEvent(nil), s.outbox...),
+		outboxSeq:     s.outboxSeq,
+		changes:       append([]*models.CatalogChange(nil), s.changes...),
+		changeSeq:     s.changeSeq,
+		subscriptions: cloneMap(s.subscriptions),
+		deliveries:    cloneMap(s.deliveries),
+		shadows:       cloneMap(s.shadows),
+		shadowDeltas:  append([]*models.ShadowDelta(nil), s.shadowDeltas...),
+		shadowSeq:     s.shadowSeq,
+		rules:         cloneMap(s.rules),
+		executions:    cloneMap(s.executions),
+		schedules:     cloneMap(s.schedules),
+		scheduleRuns:  cloneMap(s.scheduleRuns),
+		scenes:        cloneMap(s.scenes),
+		relationships: cloneMap(s.relationships),
+		dependencies:  cloneMap(s.dependencies),
+		capabilities:  cloneMap(s.capabilities),
+	}
+}
+
+func (s *Store) restore(snap snapshot) {
+	s.models = snap.models
+	s.features = snap.features
+	s.outbox = snap.outbox
+	s.outboxSeq = snap.outboxSeq
+	s.changes = snap.changes
+	s.changeSeq = snap.changeSeq
+	s.subscriptions = snap.subscriptions
+	s.deliveries = snap.deliveries
+	s.shadows = snap.shadows
+	s.shadowDeltas = snap.shadowDeltas
+	s.shadowSeq = snap.shadowSeq
+	s.rules = snap.rules
+	s.executions = snap.executions
+	s.schedules = snap.schedules
+	s.scheduleRuns = snap.scheduleRuns
+	s.scenes = snap.scenes
+	s.relationships = snap.relationships
+	s.dependencies = snap.dependencies
+	s.capabilities = snap.capabilities
+}
+
+// recordChange appends to the catalog change log, mirroring the
+// record_catalog_change trigger of the Postgres schema.
+func (s *Store) recordChange(change *models.CatalogChange) {
+	s.changeSeq++
+	change.Sequence = s.changeSeq
+	change.ChangedAt = time.Now()
+	s.changes = append(s.changes, change)
+}
+
+func (s *Store) subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+	s.listenersMu.Lock()
+	s.listeners[ch] = struct{}{}
+	s.listenersMu.Unlock()
+	return ch
+}
+
+func (s *Store) unsubscribe(ch chan struct{}) {
+	s.listenersMu.Lock()
+	delete(s.listeners, ch)
+	s.listenersMu.Unlock()
+}
+
+// notify wakes up change listeners without blocking; a listener that has
+// not consumed the previous wake-up still has one pending.
+func (s *Store) notify() {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	for ch := range s.listeners {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	clone := make(map[K]V, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// normalizeJSON copies a JSON column value the way a round trip through
+// Postgres would, so numbers come back as float64.
+func normalizeJSON(value map[string]interface{}) (map[string]interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// copyJSON deep-copies a normalized JSON value so callers cannot modify
+// stored rows through the maps they get back.
+func copyJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return copyJSONObject(v)
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = copyJSON(item)
+		}
+		return clone
+	default:
+		return v
+	}
+}
+
+func copyJSONObject(value map[string]interface{}) map[string]interface{} {
+	if value == nil {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(value))
+	for k, v := range value {
+		clone[k] = copyJSON(v)
+	}
+	return clone
+}
+
+func cloneModel(model *models.SmartModel) *models.SmartModel {
+	clone := *model
+	clone.Metadata = copyJSONObject(model.Metadata)
+	return &clone
+}
+
+func cloneFeature(feature *models.SmartFeature) *models.SmartFeature {
+	clone := *feature
+	clone.Parameters = copyJSONObject(feature.Parameters)
+	return &clone
+}
+
+func parseID(id string) (uuid.UUID, bool) {
+	parsed, err := uuid.Parse(id)
+	return parsed, err == nil
+}
+
+// idSet parses ids, skipping the malformed ones, which cannot match a row.
+func idSet(ids []string) map[uuid.UUID]struct{} {
+	set := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		if parsed, ok := parseID(id); ok {
+			set[parsed] = struct{}{}
+		}
+	}
+	return set
+}
@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"github.com/google/uuid"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+// readingKey identifies a reading; the timestamp is in Unix nanoseconds.
+type readingKey struct {
+	deviceID  string
+	featureID uuid.UUID
+	at        int64
+}
+
+type MemTelemetryRepository struct {
+	store *Store
+}
+
+func NewMemTelemetryRepository(store *Store) *MemTelemetryRepository {
+	return &MemTelemetryRepository{
+		store: store,
+	}
+}
+
+func (r *MemTelemetryRepository) AddReadings(ctx context.Context, readings []*models.TelemetryReading) (int, error) {
+	r.store.telemetryMu.Lock()
+	defer r.store.telemetryMu.Unlock()
+
+	stored := 0
+	for _, reading := range readings {
+		key := readingKey{deviceID: reading.DeviceID, featureID: reading.FeatureID, at: reading.Timestamp.UnixNano()}
+		if _, exists := r.store.readings[key]; exists {
+			continue
+		}
+		r.store.readings[key] = reading.Value
+		stored++
+	}
+	return stored, nil
+}
+
+// matching returns the keys query selects, ordered by timestamp and device.
+func (r *MemTelemetryRepository) matching(query models.ReadingQuery) []readingKey {
+	from, to := query.From.UnixNano(), query.To.UnixNano()
+	var keys []readingKey
+	for key := range r.store.readings {
+		if key.featureID != query.FeatureID || key.at < from || key.at >= to {
+			continue
+		}
+		if query.DeviceID != "" && key.deviceID != query.DeviceID {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b readingKey) int {
+		return cmp.Or(cmp.Compare(a.at, b.at), cmp.Compare(a.deviceID, b.deviceID))
+	})
+	return keys
+}
+
+func (r *MemTelemetryRepository) ListReadings(ctx context.Context, query models.ReadingQuery) ([]*models.TelemetryReading, error) {
+	r.store.telemetryMu.Lock()
+	defer r.store.telemetryMu.Unlock()
+
+	var readings []*models.TelemetryReading
+	for _, key := range r.matching(query) {
+		if len(readings) == query.Limit {
+			break
+		}
+		readings = append(readings, &models.TelemetryReading{
+			DeviceID:  key.deviceID,
+			FeatureID: key.featureID,
+			Timestamp: time.Unix(0, key.at).UTC(),
+			Value:     r.store.readings[key],
+		})
+	}
+	return readings, nil
+}
+
+func (r *MemTelemetryRepository) Downsample(ctx context.Context, query models.ReadingQuery, bucket time.Duration) ([]*models.TelemetryBucket, error) {
+	r.store.telemetryMu.Lock()
+	defer r.store.telemetryMu.Unlock()
+
+	width := bucket.Nanoseconds()
+	var buckets []*models.TelemetryBucket
+	var current *models.TelemetryBucket
+	var sum float64
+	for _, key := range r.matching(query) {
+		start := key.at - key.at%width
+		if current == nil || current.Start.UnixNano() != start {
+			if len(buckets) == query.Limit {
+				break
+			}
+			if current != nil {
+				current.Avg = sum / float64(current.Count)
+			}
+			current = &models.TelemetryBucket{Start: time.Unix(0, start).UTC()}
+			sum = 0
+			buckets = append(buckets, current)
+		}
+		value := r.store.readings[key]
+		if current.Count == 0 || value < current.Min {
+			current.Min = value
+		}
+		if current.Count == 0 || value > current.Max {
+			current.Max = value
+		}
+		current.Count++
+		sum += value
+	}
+	if current != nil {
+		current.Avg = sum / float64(current.Count)
+	}
+	return buckets, nil
+}
+
+func (r *MemTelemetryRepository) DeleteReadings(ctx context.Context, filter models.ReadingDeleteFilter) error {
+	r.store.telemetryMu.Lock()
+	defer r.store.telemetryMu.Unlock()
+
+	before := filter.Before.UnixNano()
+	for key := range r.store.readings {
+		if key.at >= before {
+			continue
+		}
+		if filter.FeatureID != nil && key.featureID != *filter.FeatureID {
+			continue
+		}
+		if slices.Contains(filter.ExceptFeatureIDs, key.featureID) {
+			continue
+		}
+		delete(r.store.readings, key)
+	}
+	return nil
+}
+
+// SetRetentionPolicy, like the other policy methods, locks the store before
+// telemetryMu to see the features the policies belong to.
+func (r *MemTelemetryRepository) SetRetentionPolicy(ctx context.Context, policy *models.RetentionPolicy) (*models.RetentionPolicy, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+	r.store.telemetryMu.Lock()
+	defer r.store.telemetryMu.Unlock()
+
+	if _, ok := r.store.features[policy.FeatureID]; !ok {
+		return nil, models.ErrNotFound
+	}
+	stored := *policy
+	r.store.retentionPolicies[stored.FeatureID] = &stored
+	result := stored
+	return &result, nil
+}
+
+// ListRetentionPolicies drops the policies of deleted features first, which
+// the other backends remove together with the feature.
+func (r *MemTelemetryRepository) ListRetentionPolicies(ctx context.Context) ([]*models.RetentionPolicy, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+	r.store.telemetryMu.Lock()
+	defer r.store.telemetryMu.Unlock()
+
+	r.dropOrphanedPolicies()
+	var policies []*models.RetentionPolicy
+	for _, policy := range r.store.retentionPolicies {
+		clone := *policy
+		policies = append(policies, &clone)
+	}
+	slices.SortFunc(policies, func(a, b *models.RetentionPolicy) int {
+		return cmp.Compare(a.FeatureID.String(), b.FeatureID.String())
+	})
+	return policies, nil
+}
+
+func (r *MemTelemetryRepository) DeleteRetentionPolicy(ctx context.Context, featureID uuid.UUID) error {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+	r.store.telemetryMu.Lock()
+	defer r.store.telemetryMu.Unlock()
+
+	r.dropOrphanedPolicies()
+	if _, ok := r.store.retentionPolicies[featureID]; !ok {
+		return models.ErrNotFound
+	}
+	delete(r.store.retentionPolicies, featureID)
+	return nil
+}
+
+func (r *MemTelemetryRepository) dropOrphanedPolicies() {
+	for id := range r.store.retentionPolicies {
+		if _, ok := r.store.features[id]; !ok {
+			delete(r.store.retentionPolicies, id)
+		}
+	}
+}
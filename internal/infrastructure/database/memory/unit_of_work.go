@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+)
+
+type MemUnitOfWork struct {
+	store *Store
+}
+
+func NewMemUnitOfWork(store *Store) *MemUnitOfWork {
+	return &MemUnitOfWork{
+		store: store,
+	}
+}
+
+// Do holds the store for the duration of fn and restores the state from
+// before fn when it fails.
+func (u *MemUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if u.store.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	u.store.mu.Lock()
+	snap := u.store.snapshot()
+	err := fn(context.WithValue(ctx, txKey{}, u.store))
+	if err != nil {
+		u.store.restore(snap)
+	}
+	changed := u.store.changeSeq != snap.changeSeq
+	u.store.mu.Unlock()
+
+	if changed {
+		u.store.notify()
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func newTestModel() *models.SmartModel {
+	now := time.Now()
+	return &models.SmartModel{
+		ID:          uuid.New(),
+		Name:        "Thermostat",
+		Description: "Smart thermostat",
+		Type:        models.DeviceType,
+		Category:    models.WearableCategory,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func TestMemUnitOfWork_Commit(t *testing.T) {
+	store := NewStore()
+	uow := NewMemUnitOfWork(store)
+	modelRepo := NewMemSmartModelRepository(store)
+	outbox := NewMemOutboxRepository(store)
+	model := newTestModel()
+
+	err := uow.Do(context.Background(), func(ctx context.Context) error {
+		if _, err := modelRepo.Create(ctx, model); err != nil {
+			return err
+		}
+		event, err := models.NewDomainEvent(models.SmartModelAggregate, model.ID, models.ModelCreatedEvent, model)
+		if err != nil {
+			return err
+		}
+		return outbox.Add(ctx, event)
+	})
+	require.NoError(t, err)
+
+	_, err = modelRepo.GetByID(context.Background(), model.ID.String())
+	assert.NoError(t, err)
+
+	pending, err := outbox.FetchPending(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, int64(1), pending[0].Sequence)
+}
+
+func TestMemUnitOfWork_RollbackRestoresState(t *testing.T) {
+	store := NewStore()
+	uow := NewMemUnitOfWork(store)
+	modelRepo := NewMemSmartModelRepository(store)
+	changes := NewMemCatalogChangeRepository(store)
+	existing, err := modelRepo.Create(context.Background(), newTestModel())
+	require.NoError(t, err)
+	failure := errors.New("boom")
+
+	err = uow.Do(context.Background(), func(ctx context.Context) error {
+		if _, err := modelRepo.Create(ctx, newTestModel()); err != nil {
+			return err
+		}
+		if err := modelRepo.Delete(ctx, existing.ID.String()); err != nil {
+			return err
+		}
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	all, err := modelRepo.GetAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, existing.ID, all[0].ID)
+
+	latest, err := changes.LatestSequence(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), latest)
+}
+
+func TestMemUnitOfWork_NestedJoinsOuter(t *testing.T) {
+	store := NewStore()
+	uow := NewMemUnitOfWork(store)
+	modelRepo := NewMemSmartModelRepository(store)
+
+	err := uow.Do(context.Background(), func(ctx context.Context) error {
+		return uow.Do(ctx, func(ctx context.Context) error {
+			_, err := modelRepo.Create(ctx, newTestModel())
+			return err
+		})
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestMemChangeListener_NotifiedOnCommit(t *testing.T) {
+	store := NewStore()
+	uow := NewMemUnitOfWork(store)
+	modelRepo := NewMemSmartModelRepository(store)
+	listener := NewMemChangeListener(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notified := make(chan struct{}, 10)
+	go func() {
+		_ = listener.Listen(ctx, func() { notified <- struct{}{} })
+	}()
+
+	// The initial call after subscribing.
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("listener did not start")
+	}
+
+	err := uow.Do(context.Background(), func(ctx context.Context) error {
+		_, err := modelRepo.Create(ctx, newTestModel())
+		return err
+	})
+	require.NoError(t, err)
+
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("listener was not notified")
+	}
+}
@@ -0,0 +1,237 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type MemWebhookRepository struct {
+	store *Store
+}
+
+func NewMemWebhookRepository(store *Store) *MemWebhookRepository {
+	return &MemWebhookRepository{
+		store: store,
+	}
+}
+
+func (r *MemWebhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	var created *models.WebhookSubscription
+	err := r.store.write(ctx, func() error {
+		if _, exists := r.store.subscriptions[subscription.ID]; exists {
+			return ErrDuplicateKey
+		}
+		stored := cloneSubscription(subscription)
+		r.store.subscriptions[stored.ID] = stored
+		created = cloneSubscription(stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *MemWebhookRepository) GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	subscription, ok := r.store.subscriptions[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return cloneSubscription(subscription), nil
+}
+
+func (r *MemWebhookRepository) ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var subscriptions []*models.WebhookSubscription
+	for _, subscription := range r.store.subscriptions {
+		subscriptions = append(subscriptions, cloneSubscription(subscription))
+	}
+	slices.SortFunc(subscriptions, func(a, b *models.WebhookSubscription) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return subscriptions, nil
+}
+
+// UpdateSubscription keeps the stored secret and creation time.
+func (r *MemWebhookRepository) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	var updated *models.WebhookSubscription
+	err := r.store.write(ctx, func() error {
+		existing, ok := r.store.subscriptions[subscription.ID]
+		if !ok {
+			return models.ErrNotFound
+		}
+		stored := cloneSubscription(subscription)
+		stored.Secret = existing.Secret
+		stored.CreatedAt = existing.CreatedAt
+		r.store.subscriptions[stored.ID] = stored
+		updated = cloneSubscription(stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (r *MemWebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func() error {
+		if _, ok := r.store.subscriptions[id]; !ok {
+			return models.ErrNotFound
+		}
+		delete(r.store.subscriptions, id)
+		for deliveryID, delivery := range r.store.deliveries {
+			if delivery.SubscriptionID == id {
+				delete(r.store.deliveries, deliveryID)
+			}
+		}
+		return nil
+	})
+}
+
+// EnqueueDeliveries skips deliveries of an event a subscription already has.
+func (r *MemWebhookRepository) EnqueueDeliveries(ctx context.Context, deliveries ...*models.WebhookDelivery) error {
+	return r.store.write(ctx, func() error {
+		for _, delivery := range deliveries {
+			if _, ok := r.store.subscriptions[delivery.SubscriptionID]; !ok {
+				return models.ErrNotFound
+			}
+			if r.hasDelivery(delivery.SubscriptionID, delivery.EventID) {
+				continue
+			}
+			stored := cloneDelivery(delivery)
+			r.store.deliveries[stored.ID] = stored
+		}
+		return nil
+	})
+}
+
+func (r *MemWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, leaseUntil time.Time) ([]*models.WebhookDelivery, error) {
+	var claimed []*models.WebhookDelivery
+	err := r.store.write(ctx, func() error {
+		now := time.Now()
+		var due []*models.WebhookDelivery
+		for _, delivery := range r.store.deliveries {
+			subscription, ok := r.store.subscriptions[delivery.SubscriptionID]
+			if !ok || !subscription.Active {
+				continue
+			}
+			if delivery.Status != models.DeliveryPending && delivery.Status != models.DeliveryFailed {
+				continue
+			}
+			if delivery.NextAttemptAt.After(now) {
+				continue
+			}
+			due = append(due, delivery)
+		}
+		slices.SortFunc(due, func(a, b *models.WebhookDelivery) int {
+			return a.NextAttemptAt.Compare(b.NextAttemptAt)
+		})
+		if len(due) > limit {
+			due = due[:limit]
+		}
+
+		for _, delivery := range due {
+			leased := cloneDelivery(delivery)
+			leased.NextAttemptAt = leaseUntil
+			r.store.deliveries[leased.ID] = leased
+			claimed = append(claimed, cloneDelivery(leased))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func (r *MemWebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.store.write(ctx, func() error {
+		existing, ok := r.store.deliveries[delivery.ID]
+		if !ok {
+			return models.ErrNotFound
+		}
+		stored := cloneDelivery(existing)
+		stored.Status = delivery.Status
+		stored.Attempts = delivery.Attempts
+		stored.LastStatusCode = delivery.LastStatusCode
+		stored.LastError = delivery.LastError
+		stored.NextAttemptAt = delivery.NextAttemptAt
+		if delivery.DeliveredAt != nil {
+			deliveredAt := *delivery.DeliveredAt
+			stored.DeliveredAt = &deliveredAt
+		} else {
+			stored.DeliveredAt = nil
+		}
+		r.store.deliveries[stored.ID] = stored
+		return nil
+	})
+}
+
+func (r *MemWebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	delivery, ok := r.store.deliveries[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return cloneDelivery(delivery), nil
+}
+
+func (r *MemWebhookRepository) ListDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, error) {
+	unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var deliveries []*models.WebhookDelivery
+	for _, delivery := range r.store.deliveries {
+		if filter.SubscriptionID != nil && delivery.SubscriptionID != *filter.SubscriptionID {
+			continue
+		}
+		if filter.Status != nil && delivery.Status != *filter.Status {
+			continue
+		}
+		deliveries = append(deliveries, cloneDelivery(delivery))
+	}
+	slices.SortFunc(deliveries, func(a, b *models.WebhookDelivery) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if len(deliveries) > filter.Limit {
+		deliveries = deliveries[:filter.Limit]
+	}
+
+	return deliveries, nil
+}
+
+func (r *MemWebhookRepository) hasDelivery(subscriptionID, eventID uuid.UUID) bool {
+	for _, delivery := range r.store.deliveries {
+		if delivery.SubscriptionID == subscriptionID && delivery.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneSubscription(subscription *models.WebhookSubscription) *models.WebhookSubscription {
+	clone := *subscription
+	clone.EventTypes = slices.Clone(subscription.EventTypes)
+	return &clone
+}
+
+func cloneDelivery(delivery *models.WebhookDelivery) *models.WebhookDelivery {
+	clone := *delivery
+	clone.Payload = slices.Clone(delivery.Payload)
+	if delivery.DeliveredAt != nil {
+		deliveredAt := *delivery.DeliveredAt
+		clone.DeliveredAt = &deliveredAt
+	}
+	return &clone
+}
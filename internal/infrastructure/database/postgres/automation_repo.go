@@ -0,0 +1,273 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+const automationRuleColumns = `id, name, COALESCE(description, ''), enabled, trigger, condition, actions, timezone, cooldown_seconds, created_at, updated_at`
+
+const automationExecutionColumns = `id, rule_id, trigger, COALESCE(dedup_key, ''), status, COALESCE(error, ''), action_results, started_at, finished_at`
+
+type PGAutomationRepository struct {
+	db     database.PgxPool
+	reader database.PgxPool
+}
+
+func NewPGAutomationRepository(db database.Database) *PGAutomationRepository {
+	return &PGAutomationRepository{
+		db:     db.GetPool(),
+		reader: db.GetReadPool(),
+	}
+}
+
+func (r *PGAutomationRepository) CreateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	query := `
+		INSERT INTO automation_rules (id, name, description, enabled, trigger, condition, actions, timezone, cooldown_seconds, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ` + automationRuleColumns
+
+	row := database.Conn(ctx, r.db).QueryRow(ctx, query,
+		rule.ID,
+		rule.Name,
+		rule.Description,
+		rule.Enabled,
+		rule.Trigger,
+		rule.Condition,
+		ruleActionsOrEmpty(rule.Actions),
+		rule.Timezone,
+		int64(rule.Cooldown/time.Second),
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+	created, err := scanAutomationRule(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return created, nil
+}
+
+func (r *PGAutomationRepository) GetRule(ctx context.Context, id uuid.UUID) (*models.AutomationRule, error) {
+	query := `SELECT ` + automationRuleColumns + ` FROM automation_rules WHERE id = $1`
+
+	rule, err := scanAutomationRule(database.Conn(ctx, r.reader).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return rule, nil
+}
+
+// ListRules reads from the primary: the engine reloads the rules right after
+// a change and must not get a lagging replica's copy.
+func (r *PGAutomationRepository) ListRules(ctx context.Context) ([]*models.AutomationRule, error) {
+	query := `SELECT ` + automationRuleColumns + ` FROM automation_rules ORDER BY created_at, id`
+
+	rows, err := database.Conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*models.AutomationRule
+	for rows.Next() {
+		rule, err := scanAutomationRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *PGAutomationRepository) UpdateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	query := `
+		UPDATE automation_rules
+		SET name = $2, description = $3, enabled = $4, trigger = $5, condition = $6, actions = $7,
+			timezone = $8, cooldown_seconds = $9, updated_at = $10
+		WHERE id = $1
+		RETURNING ` + automationRuleColumns
+
+	row := database.Conn(ctx, r.db).QueryRow(ctx, query,
+		rule.ID,
+		rule.Name,
+		rule.Description,
+		rule.Enabled,
+		rule.Trigger,
+		rule.Condition,
+		ruleActionsOrEmpty(rule.Actions),
+		rule.Timezone,
+		int64(rule.Cooldown/time.Second),
+		rule.UpdatedAt,
+	)
+	updated, err := scanAutomationRule(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return updated, nil
+}
+
+func (r *PGAutomationRepository) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM automation_rules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PGAutomationRepository) CreateExecution(ctx context.Context, execution *models.AutomationExecution) error {
+	query := `
+		INSERT INTO automation_executions (id, rule_id, trigger, dedup_key, status, error, action_results, started_at, finished_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), $7, $8, $9)
+	`
+
+	_, err := database.Conn(ctx, r.db).Exec(ctx, query,
+		execution.ID,
+		execution.RuleID,
+		jsonObjectOrEmpty(execution.Trigger),
+		execution.DedupKey,
+		execution.Status,
+		execution.Error,
+		actionResultsOrEmpty(execution.Results),
+		execution.StartedAt,
+		execution.FinishedAt,
+	)
+	return mapError(err)
+}
+
+func (r *PGAutomationRepository) FinishExecution(ctx context.Context, execution *models.AutomationExecution) error {
+	query := `
+		UPDATE automation_executions
+		SET status = $2, error = NULLIF($3, ''), action_results = $4, finished_at = $5
+		WHERE id = $1
+	`
+
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, query,
+		execution.ID,
+		execution.Status,
+		execution.Error,
+		actionResultsOrEmpty(execution.Results),
+		execution.FinishedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PGAutomationRepository) ListExecutions(ctx context.Context, filter models.ExecutionFilter) ([]*models.AutomationExecution, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.RuleID != nil {
+		conditions = append(conditions, "rule_id = "+arg(*filter.RuleID))
+	}
+	if filter.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(started_at, id) < (%s, %s)", arg(filter.After.CreatedAt), arg(filter.After.ID)))
+	}
+
+	query := `
+		SELECT ` + automationExecutionColumns + `
+		FROM automation_executions`
+	if len(conditions) > 0 {
+		query += `
+		WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += `
+		ORDER BY started_at DESC, id DESC
+		LIMIT ` + arg(filter.Limit)
+
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []*models.AutomationExecution
+	for rows.Next() {
+		var execution models.AutomationExecution
+		err = rows.Scan(
+			&execution.ID,
+			&execution.RuleID,
+			&execution.Trigger,
+			&execution.DedupKey,
+			&execution.Status,
+			&execution.Error,
+			&execution.Results,
+			&execution.StartedAt,
+			&execution.FinishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(execution.Results) == 0 {
+			execution.Results = nil
+		}
+		executions = append(executions, &execution)
+	}
+
+	return executions, rows.Err()
+}
+
+func (r *PGAutomationRepository) PruneExecutions(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM automation_executions WHERE started_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanAutomationRule(row pgx.Row) (*models.AutomationRule, error) {
+	var rule models.AutomationRule
+	var seconds int64
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.Description,
+		&rule.Enabled,
+		&rule.Trigger,
+		&rule.Condition,
+		&rule.Actions,
+		&rule.Timezone,
+		&seconds,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rule.Cooldown = time.Duration(seconds) * time.Second
+	return &rule, nil
+}
+
+// ruleActionsOrEmpty and actionResultsOrEmpty keep nil slices, which pgx
+// encodes as NULL, out of the NOT NULL JSONB columns.
+func ruleActionsOrEmpty(actions []models.RuleAction) []models.RuleAction {
+	if actions == nil {
+		return []models.RuleAction{}
+	}
+	return actions
+}
+
+func actionResultsOrEmpty(results []models.ActionResult) []models.ActionResult {
+	if results == nil {
+		return []models.ActionResult{}
+	}
+	return results
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestPGAutomationRepository_CreateExecution_DuplicateDedupKey(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGAutomationRepository(&mockModelDB{mock})
+	execution := &models.AutomationExecution{
+		ID:        uuid.New(),
+		RuleID:    uuid.New(),
+		DedupKey:  "rule/1709294400",
+		Status:    models.ExecutionRunning,
+		StartedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	mock.ExpectExec(`INSERT INTO automation_executions`).
+		WithArgs(execution.ID, execution.RuleID, map[string]interface{}{}, "rule/1709294400", models.ExecutionRunning, "",
+			[]models.ActionResult{}, execution.StartedAt, (*time.Time)(nil)).
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation, ConstraintName: "uq_automation_executions_dedup_key"})
+
+	err = repo.CreateExecution(context.Background(), execution)
+	assert.ErrorIs(t, err, models.ErrAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGAutomationRepository_ListExecutions_Cursor(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGAutomationRepository(&mockModelDB{mock})
+	ruleID := uuid.New()
+	cursor := &models.PageCursor{CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), ID: uuid.New()}
+	startedAt := cursor.CreatedAt.Add(-time.Minute)
+	executionID := uuid.New()
+
+	mock.ExpectQuery(`FROM automation_executions\s+WHERE rule_id = \$1 AND \(started_at, id\) < \(\$2, \$3\)\s+ORDER BY started_at DESC, id DESC\s+LIMIT \$4`).
+		WithArgs(ruleID, cursor.CreatedAt, cursor.ID, 10).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "rule_id", "trigger", "dedup_key", "status", "error", "action_results", "started_at", "finished_at"}).
+			AddRow(executionID, ruleID, map[string]interface{}{"type": "schedule"}, "", models.ExecutionFailed, "1 of 1 actions failed",
+				[]models.ActionResult{{DeviceID: "dev-1", Error: "offline"}}, startedAt, &startedAt))
+
+	executions, err := repo.ListExecutions(context.Background(), models.ExecutionFilter{RuleID: &ruleID, After: cursor, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, executionID, executions[0].ID)
+	assert.Equal(t, models.ExecutionFailed, executions[0].Status)
+	assert.Equal(t, "offline", executions[0].Results[0].Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type PGCatalogChangeRepository struct {
+	db database.PgxPool
+}
+
+func NewPGCatalogChangeRepository(db database.Database) *PGCatalogChangeRepository {
+	return &PGCatalogChangeRepository{
+		db: db.GetPool(),
+	}
+}
+
+func (r *PGCatalogChangeRepository) ListSince(ctx context.Context, after int64, limit int) ([]*models.CatalogChange, error) {
+	query := `
+		SELECT sequence, entity, operation, entity_id, model_id, old_row, new_row, changed_at
+		FROM catalog_changes
+		WHERE sequence > $1
+		ORDER BY sequence
+		LIMIT $2
+	`
+
+	rows, err := database.Conn(ctx, r.db).Query(ctx, query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*models.CatalogChange
+	for rows.Next() {
+		var change models.CatalogChange
+		var oldRow, newRow []byte
+		err = rows.Scan(
+			&change.Sequence,
+			&change.Entity,
+			&change.Operation,
+			&change.EntityID,
+			&change.ModelID,
+			&oldRow,
+			&newRow,
+			&change.ChangedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := decodeChangeRows(&change, oldRow, newRow); err != nil {
+			return nil, err
+		}
+		changes = append(changes, &change)
+	}
+
+	return changes, rows.Err()
+}
+
+func (r *PGCatalogChangeRepository) LatestSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := database.Conn(ctx, r.db).QueryRow(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM catalog_changes`).Scan(&sequence)
+	return sequence, err
+}
+
+func (r *PGCatalogChangeRepository) OldestSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := database.Conn(ctx, r.db).QueryRow(ctx, `SELECT COALESCE(MIN(sequence), 0) FROM catalog_changes`).Scan(&sequence)
+	return sequence, err
+}
+
+func (r *PGCatalogChangeRepository) Prune(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM catalog_changes WHERE changed_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// decodeChangeRows unmarshals the to_jsonb snapshots written by the
+// record_catalog_change trigger into the entity type of the change.
+func decodeChangeRows(change *models.CatalogChange, oldRow, newRow []byte) error {
+	switch change.Entity {
+	case models.SmartModelAggregate:
+		return decodeRows(oldRow, newRow, &change.OldModel, &change.NewModel)
+	case models.SmartFeatureAggregate:
+		return decodeRows(oldRow, newRow, &change.OldFeature, &change.NewFeature)
+	}
+	return nil
+}
+
+func decodeRows[T any](oldRow, newRow []byte, oldValue, newValue **T) error {
+	if len(oldRow) > 0 {
+		*oldValue = new(T)
+		if err := json.Unmarshal(oldRow, *oldValue); err != nil {
+			return err
+		}
+	}
+	if len(newRow) > 0 {
+		*newValue = new(T)
+		if err := json.Unmarshal(newRow, *newValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestPGCatalogChangeRepository_ListSince(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGCatalogChangeRepository(db)
+
+	modelID := uuid.New()
+	featureID := uuid.New()
+	now := time.Now()
+
+	const expectedSQL = `SELECT sequence, entity, operation, entity_id, model_id, old_row, new_row, changed_at FROM catalog_changes WHERE sequence > $1 ORDER BY sequence LIMIT $2`
+
+	oldModel := []byte(`{"id":"` + modelID.String() + `","name":"Old","type":"device","category":"camera","created_at":"2024-01-01T10:00:00.123456+00:00","updated_at":"2024-01-01T10:00:00.123456+00:00"}`)
+	newModel := []byte(`{"id":"` + modelID.String() + `","name":"New","type":"device","category":"camera","created_at":"2024-01-01T10:00:00.123456+00:00","updated_at":"2024-01-02T10:00:00+00:00"}`)
+	oldFeature := []byte(`{"id":"` + featureID.String() + `","model_id":"` + modelID.String() + `","name":"Feature","protocol":"rest","interface_path":"/test"}`)
+
+	rows := pgxmock.NewRows([]string{
+		"sequence", "entity", "operation", "entity_id", "model_id", "old_row", "new_row", "changed_at",
+	}).
+		AddRow(int64(4), models.SmartModelAggregate, models.ChangeUpdated, modelID, modelID, oldModel, newModel, now).
+		AddRow(int64(5), models.SmartFeatureAggregate, models.ChangeDeleted, featureID, modelID, oldFeature, []byte(nil), now)
+
+	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
+		WithArgs(int64(3), 100).
+		WillReturnRows(rows)
+
+	changes, err := repo.ListSince(context.Background(), 3, 100)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+
+	assert.Equal(t, int64(4), changes[0].Sequence)
+	assert.Equal(t, "Old", changes[0].OldModel.Name)
+	assert.Equal(t, "New", changes[0].NewModel.Name)
+	assert.Equal(t, models.CameraCategory, changes[0].NewModel.Category)
+
+	assert.Equal(t, models.ChangeDeleted, changes[1].Operation)
+	assert.Equal(t, featureID, changes[1].OldFeature.ID)
+	assert.Equal(t, modelID, changes[1].OldFeature.ModelID)
+	assert.Nil(t, changes[1].NewFeature)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestPGCatalogChangeRepository_Sequences(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGCatalogChangeRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(sequence), 0) FROM catalog_changes`)).
+		WillReturnRows(pgxmock.NewRows([]string{"max"}).AddRow(int64(42)))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MIN(sequence), 0) FROM catalog_changes`)).
+		WillReturnRows(pgxmock.NewRows([]string{"min"}).AddRow(int64(7)))
+
+	latest, err := repo.LatestSequence(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), latest)
+
+	oldest, err := repo.OldestSequence(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), oldest)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestPGCatalogChangeRepository_Prune(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGCatalogChangeRepository(db)
+
+	before := time.Now().Add(-time.Hour)
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM catalog_changes WHERE changed_at < $1`)).
+		WithArgs(before).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+	pruned, err := repo.Prune(context.Background(), before)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), pruned)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"smart-hub/internal/common/logger"
+	"time"
+)
+
+const (
+	catalogChangesChannel = "catalog_changes"
+	shadowDeltasChannel   = "device_shadow_deltas"
+)
+
+// PGChangeListener waits for notifications on a channel, catalog_changes by
+// default, on a dedicated connection, since LISTEN does not work through a
+// pool. A lost connection
+// is re-established with a growing delay, and onChange is called after every
+// (re)connect so the caller can pick up changes made while it was away.
+type PGChangeListener struct {
+	dsn        string
+	channel    string
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+func NewPGChangeListener(dsn string) *PGChangeListener {
+	return newPGNotificationListener(dsn, catalogChangesChannel)
+}
+
+// NewPGShadowDeltaListener listens for the device_shadow_deltas
+// notifications sent for every new entry of the shadow delta log.
+func NewPGShadowDeltaListener(dsn string) *PGChangeListener {
+	return newPGNotificationListener(dsn, shadowDeltasChannel)
+}
+
+func newPGNotificationListener(dsn, channel string) *PGChangeListener {
+	return &PGChangeListener{
+		dsn:        dsn,
+		channel:    channel,
+		minBackoff: 500 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+	}
+}
+
+func (l *PGChangeListener) Listen(ctx context.Context, onChange func()) error {
+	backoff := l.minBackoff
+	for {
+		connected, err := l.listen(ctx, onChange)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if connected {
+			backoff = l.minBackoff
+		}
+		logger.Warn("Change listener disconnected", "channel", l.channel, "retry_in", backoff.String(), err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, l.maxBackoff)
+	}
+}
+
+func (l *PGChangeListener) listen(ctx context.Context, onChange func()) (bool, error) {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return false, err
+	}
+	logger.Info("Listening for notifications", "channel", l.channel)
+	onChange()
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return true, err
+		}
+		onChange()
+	}
+}
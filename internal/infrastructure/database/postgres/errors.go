@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"smart-hub/internal/domain/models"
+	"strings"
+)
+
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+// mapError translates driver errors into the domain errors the
+// repositories promise: a missing row, or a reference to one, is
+// models.ErrNotFound, and a duplicate unique key is models.ErrAlreadyExists.
+// Primary key violations are left alone; they are not a conflict between
+// two catalog entries but the same insert done twice.
+func mapError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return fmt.Errorf("%w: %s", models.ErrNotFound, pgErr.Detail)
+	}
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && strings.HasPrefix(pgErr.ConstraintName, "uq_") {
+		return fmt.Errorf("%w: %s", models.ErrAlreadyExists, pgErr.Detail)
+	}
+
+	return err
+}
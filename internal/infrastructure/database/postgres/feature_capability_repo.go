@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+)
+
+const featureCapabilityColumns = `c.feature_id, f.model_id, c.capability, c.parameter_mapping`
+
+type PGFeatureCapabilityRepository struct {
+	db     database.PgxPool
+	reader database.PgxPool
+}
+
+func NewPGFeatureCapabilityRepository(db database.Database) *PGFeatureCapabilityRepository {
+	return &PGFeatureCapabilityRepository{
+		db:     db.GetPool(),
+		reader: db.GetReadPool(),
+	}
+}
+
+func (r *PGFeatureCapabilityRepository) SetFeatureCapability(ctx context.Context, capability *models.FeatureCapability) error {
+	query := `
+		INSERT INTO feature_capabilities (feature_id, capability, parameter_mapping)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (feature_id) DO UPDATE
+		SET capability = EXCLUDED.capability, parameter_mapping = EXCLUDED.parameter_mapping
+	`
+
+	mapping := capability.ParameterMapping
+	if mapping == nil {
+		mapping = map[string]string{}
+	}
+	_, err := database.Conn(ctx, r.db).Exec(ctx, query, capability.FeatureID, capability.Capability, mapping)
+	return mapError(err)
+}
+
+func (r *PGFeatureCapabilityRepository) GetFeatureCapability(ctx context.Context, featureID uuid.UUID) (*models.FeatureCapability, error) {
+	query := `
+		SELECT ` + featureCapabilityColumns + `
+		FROM feature_capabilities c
+		JOIN smart_features f ON f.id = c.feature_id
+		WHERE c.feature_id = $1
+	`
+
+	capability, err := scanFeatureCapability(database.Conn(ctx, r.reader).QueryRow(ctx, query, featureID))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return capability, nil
+}
+
+func (r *PGFeatureCapabilityRepository) DeleteFeatureCapability(ctx context.Context, featureID uuid.UUID) error {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM feature_capabilities WHERE feature_id = $1`, featureID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PGFeatureCapabilityRepository) ListByCapability(ctx context.Context, capability string) ([]*models.FeatureCapability, error) {
+	query := `
+		SELECT ` + featureCapabilityColumns + `
+		FROM feature_capabilities c
+		JOIN smart_features f ON f.id = c.feature_id
+		WHERE c.capability = $1
+		ORDER BY f.model_id, c.feature_id
+	`
+
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, capability)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var capabilities []*models.FeatureCapability
+	for rows.Next() {
+		c, err := scanFeatureCapability(rows)
+		if err != nil {
+			return nil, err
+		}
+		capabilities = append(capabilities, c)
+	}
+	return capabilities, rows.Err()
+}
+
+func scanFeatureCapability(row pgx.Row) (*models.FeatureCapability, error) {
+	var capability models.FeatureCapability
+	err := row.Scan(&capability.FeatureID, &capability.ModelID, &capability.Capability, &capability.ParameterMapping)
+	if err != nil {
+		return nil, err
+	}
+	return &capability, nil
+}
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+)
+
+func TestPGFeatureCapabilityRepository_SetFeatureCapability(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGFeatureCapabilityRepository(&mockModelDB{mock})
+	featureID := uuid.New()
+
+	mock.ExpectExec(`INSERT INTO feature_capabilities .* ON CONFLICT \(feature_id\) DO UPDATE`).
+		WithArgs(featureID, "switch", map[string]string{}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = repo.SetFeatureCapability(context.Background(), &models.FeatureCapability{FeatureID: featureID, Capability: "switch"})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGFeatureCapabilityRepository_SetFeatureCapability_UnknownFeature(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGFeatureCapabilityRepository(&mockModelDB{mock})
+	featureID := uuid.New()
+
+	mock.ExpectExec(`INSERT INTO feature_capabilities`).
+		WithArgs(featureID, "brightness", map[string]string{"level": "dim"}).
+		WillReturnError(&pgconn.PgError{Code: foreignKeyViolation})
+
+	err = repo.SetFeatureCapability(context.Background(), &models.FeatureCapability{
+		FeatureID:        featureID,
+		Capability:       "brightness",
+		ParameterMapping: map[string]string{"level": "dim"},
+	})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGFeatureCapabilityRepository_GetFeatureCapability(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGFeatureCapabilityRepository(&mockModelDB{mock})
+	featureID, modelID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`SELECT c.feature_id, f.model_id, c.capability, c.parameter_mapping .* WHERE c.feature_id = \$1`).
+		WithArgs(featureID).
+		WillReturnRows(pgxmock.NewRows([]string{"feature_id", "model_id", "capability", "parameter_mapping"}).
+			AddRow(featureID, modelID, "switch", map[string]string{"on": "power"}))
+
+	got, err := repo.GetFeatureCapability(context.Background(), featureID)
+	require.NoError(t, err)
+	assert.Equal(t, &models.FeatureCapability{
+		FeatureID:        featureID,
+		ModelID:          modelID,
+		Capability:       "switch",
+		ParameterMapping: map[string]string{"on": "power"},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGFeatureCapabilityRepository_DeleteFeatureCapability_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGFeatureCapabilityRepository(&mockModelDB{mock})
+	featureID := uuid.New()
+
+	mock.ExpectExec(`DELETE FROM feature_capabilities WHERE feature_id = \$1`).WithArgs(featureID).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	err = repo.DeleteFeatureCapability(context.Background(), featureID)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGFeatureCapabilityRepository_ListByCapability(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGFeatureCapabilityRepository(&mockModelDB{mock})
+	modelID, first, second := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`WHERE c.capability = \$1\s+ORDER BY f.model_id, c.feature_id`).
+		WithArgs("switch").
+		WillReturnRows(pgxmock.NewRows([]string{"feature_id", "model_id", "capability", "parameter_mapping"}).
+			AddRow(first, modelID, "switch", map[string]string{"on": "relay_1"}).
+			AddRow(second, modelID, "switch", map[string]string{"on": "relay_2"}))
+
+	listed, err := repo.ListByCapability(context.Background(), "switch")
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, first, listed[0].FeatureID)
+	assert.Equal(t, "relay_2", listed[1].ParameterMapping["on"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
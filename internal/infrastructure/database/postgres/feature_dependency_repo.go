@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+)
+
+type PGFeatureDependencyRepository struct {
+	db     database.PgxPool
+	reader database.PgxPool
+}
+
+func NewPGFeatureDependencyRepository(db database.Database) *PGFeatureDependencyRepository {
+	return &PGFeatureDependencyRepository{
+		db:     db.GetPool(),
+		reader: db.GetReadPool(),
+	}
+}
+
+// SetDependencies deletes the old declarations and inserts the new ones in
+// one statement, in a transaction of its own unless ctx already has one.
+func (r *PGFeatureDependencyRepository) SetDependencies(ctx context.Context, featureID uuid.UUID, dependencies []models.FeatureDependency) error {
+	dependsOn := make([]uuid.UUID, len(dependencies))
+	kinds := make([]string, len(dependencies))
+	for i, dependency := range dependencies {
+		dependsOn[i] = dependency.DependsOnID
+		kinds[i] = string(dependency.Kind)
+	}
+
+	uow := &PGUnitOfWork{db: r.db}
+	return uow.Do(ctx, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+
+		var exists bool
+		err := conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM smart_features WHERE id = $1)`, featureID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrNotFound
+		}
+
+		if _, err := conn.Exec(ctx, `DELETE FROM feature_dependencies WHERE feature_id = $1`, featureID); err != nil {
+			return err
+		}
+		if len(dependencies) == 0 {
+			return nil
+		}
+		_, err = conn.Exec(ctx, `
+			INSERT INTO feature_dependencies (feature_id, depends_on_id, kind)
+			SELECT $1, d.depends_on_id, d.kind::feature_dependency_kind
+			FROM unnest($2::uuid[], $3::text[]) AS d (depends_on_id, kind)
+		`, featureID, dependsOn, kinds)
+		return mapError(err)
+	})
+}
+
+func (r *PGFeatureDependencyRepository) ListDependencies(ctx context.Context, featureID uuid.UUID) ([]models.FeatureDependency, error) {
+	query := `
+		SELECT feature_id, depends_on_id, kind
+		FROM feature_dependencies
+		WHERE feature_id = $1
+		ORDER BY kind::text, depends_on_id
+	`
+
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, featureID)
+	if err != nil {
+		return nil, err
+	}
+	return scanFeatureDependencies(rows)
+}
+
+// ListModelDependencies reads from the primary: the service validates new
+// declarations against it.
+func (r *PGFeatureDependencyRepository) ListModelDependencies(ctx context.Context, modelID uuid.UUID) ([]models.FeatureDependency, error) {
+	query := `
+		SELECT d.feature_id, d.depends_on_id, d.kind
+		FROM feature_dependencies d
+		JOIN smart_features f ON f.id = d.feature_id
+		WHERE f.model_id = $1
+		ORDER BY d.feature_id, d.kind::text, d.depends_on_id
+	`
+
+	rows, err := database.Conn(ctx, r.db).Query(ctx, query, modelID)
+	if err != nil {
+		return nil, err
+	}
+	return scanFeatureDependencies(rows)
+}
+
+func scanFeatureDependencies(rows pgx.Rows) ([]models.FeatureDependency, error) {
+	defer rows.Close()
+
+	var dependencies []models.FeatureDependency
+	for rows.Next() {
+		var dependency models.FeatureDependency
+		if err := rows.Scan(&dependency.FeatureID, &dependency.DependsOnID, &dependency.Kind); err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies, rows.Err()
+}
+
+// LockModelDependencies locks the row of the model for the rest of the
+// transaction.
+func (r *PGFeatureDependencyRepository) LockModelDependencies(ctx context.Context, modelID uuid.UUID) error {
+	_, err := database.Conn(ctx, r.db).Exec(ctx, `SELECT id FROM smart_models WHERE id = $1 FOR UPDATE`, modelID)
+	return err
+}
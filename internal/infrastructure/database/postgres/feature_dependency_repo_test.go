@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+)
+
+func TestPGFeatureDependencyRepository_SetDependencies(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGFeatureDependencyRepository(&mockModelDB{mock})
+	nightVision, infrared, spotlight := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS`).WithArgs(nightVision).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`DELETE FROM feature_dependencies WHERE feature_id = \$1`).WithArgs(nightVision).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec(`INSERT INTO feature_dependencies .* unnest\(\$2::uuid\[\], \$3::text\[\]\)`).
+		WithArgs(nightVision, []uuid.UUID{infrared, spotlight}, []string{"requires", "conflicts"}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mock.ExpectCommit()
+
+	err = repo.SetDependencies(context.Background(), nightVision, []models.FeatureDependency{
+		{FeatureID: nightVision, DependsOnID: infrared, Kind: models.DependencyRequires},
+		{FeatureID: nightVision, DependsOnID: spotlight, Kind: models.DependencyConflicts},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGFeatureDependencyRepository_SetDependencies_UnknownDependency(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGFeatureDependencyRepository(&mockModelDB{mock})
+	nightVision, missing := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS`).WithArgs(nightVision).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`DELETE FROM feature_dependencies`).WithArgs(nightVision).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec(`INSERT INTO feature_dependencies`).
+		WithArgs(nightVision, []uuid.UUID{missing}, []string{"requires"}).
+		WillReturnError(&pgconn.PgError{Code: foreignKeyViolation})
+	mock.ExpectRollback()
+
+	err = repo.SetDependencies(context.Background(), nightVision, []models.FeatureDependency{
+		{FeatureID: nightVision, DependsOnID: missing, Kind: models.DependencyRequires},
+	})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGFeatureDependencyRepository_SetDependencies_UnknownFeature(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGFeatureDependencyRepository(&mockModelDB{mock})
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS`).WithArgs(id).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+
+	err = repo.SetDependencies(context.Background(), id, nil)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGFeatureDependencyRepository_LockModelDependencies(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGFeatureDependencyRepository(&mockModelDB{mock})
+	modelID := uuid.New()
+
+	mock.ExpectExec(`SELECT id FROM smart_models WHERE id = \$1 FOR UPDATE`).WithArgs(modelID).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+
+	require.NoError(t, repo.LockModelDependencies(context.Background(), modelID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
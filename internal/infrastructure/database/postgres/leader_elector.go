@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"smart-hub/internal/common/logger"
+	"time"
+)
+
+// schedulerLockKey is the session-level advisory lock held by the replica
+// that fires schedules.
+const schedulerLockKey = 7245019312
+
+// PGLeaderElector elects a leader with a session-level advisory lock held on
+// a dedicated connection, so leadership ends when the connection does, e.g.
+// when the leader crashes. Followers retry the lock every retryInterval, and
+// the leader pings its connection every checkInterval to notice a lost one.
+type PGLeaderElector struct {
+	dsn           string
+	key           int64
+	retryInterval time.Duration
+	checkInterval time.Duration
+}
+
+// NewPGSchedulerElector elects the replica that fires schedules.
+func NewPGSchedulerElector(dsn string) *PGLeaderElector {
+	return newPGLeaderElector(dsn, schedulerLockKey)
+}
+
+func newPGLeaderElector(dsn string, key int64) *PGLeaderElector {
+	return &PGLeaderElector{
+		dsn:           dsn,
+		key:           key,
+		retryInterval: 5 * time.Second,
+		checkInterval: 5 * time.Second,
+	}
+}
+
+func (e *PGLeaderElector) Lead(ctx context.Context, fn func(ctx context.Context)) error {
+	for {
+		err := e.lead(ctx, fn)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			logger.Warn("Leader election failed", "key", e.key, "retry_in", e.retryInterval.String(), err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+// lead waits for the lock on a new connection and runs fn while it holds
+// it. Closing the connection releases the lock.
+func (e *PGLeaderElector) lead(ctx context.Context, fn func(ctx context.Context)) error {
+	conn, err := pgx.Connect(ctx, e.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	for {
+		var locked bool
+		if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, e.key).Scan(&locked); err != nil {
+			return err
+		}
+		if locked {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(e.retryInterval):
+		}
+	}
+	logger.Info("Acquired leadership", "key", e.key)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(leaderCtx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+		logger.Info("Gave up leadership", "key", e.key)
+	}()
+
+	ticker := time.NewTicker(e.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			return nil
+		case <-ticker.C:
+			if err := conn.Ping(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
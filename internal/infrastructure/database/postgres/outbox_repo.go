@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+// outboxRelayLockKey is the transaction-level advisory lock that lets only one
+// relay claim events at a time, so that two replicas never claim events of
+// the same aggregate out of order.
+const outboxRelayLockKey = 7245019311
+
+type PGOutboxRepository struct {
+	db database.PgxPool
+}
+
+func NewPGOutboxRepository(db database.Database) *PGOutboxRepository {
+	return &PGOutboxRepository{
+		db: db.GetPool(),
+	}
+}
+
+func (r *PGOutboxRepository) Add(ctx context.Context, events ...*models.DomainEvent) error {
+	query := `
+		INSERT INTO outbox_events (id, aggregate_type, aggregate_id, event_type, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	conn := database.Conn(ctx, r.db)
+	for _, event := range events {
+		_, err := conn.Exec(ctx, query,
+			event.ID,
+			event.AggregateType,
+			event.AggregateID,
+			event.Type,
+			event.Payload,
+			event.OccurredAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *PGOutboxRepository) FetchPending(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	conn := database.Conn(ctx, r.db)
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxRelayLockKey).Scan(&locked); err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	query := `
+		SELECT sequence, id, aggregate_type, aggregate_id, event_type, payload, occurred_at, attempts, COALESCE(last_error, ''), claimed_until
+		FROM outbox_events
+		WHERE published_at IS NULL AND dead_at IS NULL
+		ORDER BY sequence
+		LIMIT $1
+	`
+
+	rows, err := conn.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.OutboxEvent
+	for rows.Next() {
+		var event models.OutboxEvent
+		err = rows.Scan(
+			&event.Sequence,
+			&event.ID,
+			&event.AggregateType,
+			&event.AggregateID,
+			&event.Type,
+			&event.Payload,
+			&event.OccurredAt,
+			&event.Attempts,
+			&event.LastError,
+			&event.ClaimedUntil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *PGOutboxRepository) Claim(ctx context.Context, sequences []int64, until time.Time) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE outbox_events
+		SET claimed_until = $2
+		WHERE sequence = ANY($1)
+	`
+
+	_, err := database.Conn(ctx, r.db).Exec(ctx, query, sequences, until)
+	return err
+}
+
+func (r *PGOutboxRepository) Release(ctx context.Context, sequences []int64) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE outbox_events
+		SET claimed_until = NULL
+		WHERE sequence = ANY($1)
+	`
+
+	_, err := database.Conn(ctx, r.db).Exec(ctx, query, sequences)
+	return err
+}
+
+func (r *PGOutboxRepository) MarkPublished(ctx context.Context, sequences []int64) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE outbox_events
+		SET published_at = CURRENT_TIMESTAMP, claimed_until = NULL, last_error = NULL
+		WHERE sequence = ANY($1)
+	`
+
+	_, err := database.Conn(ctx, r.db).Exec(ctx, query, sequences)
+	return err
+}
+
+func (r *PGOutboxRepository) MarkFailed(ctx context.Context, sequence int64, reason string, dead bool) error {
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, last_error = $2, claimed_until = NULL,
+			dead_at = CASE WHEN $3 THEN CURRENT_TIMESTAMP END
+		WHERE sequence = $1
+	`
+
+	_, err := database.Conn(ctx, r.db).Exec(ctx, query, sequence, reason, dead)
+	return err
+}
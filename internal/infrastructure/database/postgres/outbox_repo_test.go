@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestPGOutboxRepository_Add(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGOutboxRepository(db)
+
+	event, err := models.NewDomainEvent(models.SmartModelAggregate, uuid.New(), models.ModelCreatedEvent, map[string]string{"name": "Test"})
+	require.NoError(t, err)
+
+	const expectedSQL = `INSERT INTO outbox_events (id, aggregate_type, aggregate_id, event_type, payload, occurred_at) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	mock.ExpectExec(regexp.QuoteMeta(expectedSQL)).
+		WithArgs(event.ID, event.AggregateType, event.AggregateID, event.Type, event.Payload, event.OccurredAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = repo.Add(context.Background(), event)
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestPGOutboxRepository_FetchPending(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGOutboxRepository(db)
+
+	eventID := uuid.New()
+	aggregateID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT pg_try_advisory_xact_lock($1)`)).
+		WithArgs(outboxRelayLockKey).
+		WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+
+	const expectedSQL = `SELECT sequence, id, aggregate_type, aggregate_id, event_type, payload, occurred_at, attempts, COALESCE(last_error, ''), claimed_until FROM outbox_events WHERE published_at IS NULL AND dead_at IS NULL ORDER BY sequence LIMIT $1`
+
+	rows := pgxmock.NewRows([]string{
+		"sequence", "id", "aggregate_type", "aggregate_id", "event_type", "payload", "occurred_at", "attempts", "last_error", "claimed_until",
+	}).AddRow(int64(1), eventID, models.SmartModelAggregate, aggregateID, models.ModelCreatedEvent, []byte(`{}`), now, 0, "", &now)
+
+	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
+		WithArgs(10).
+		WillReturnRows(rows)
+
+	events, err := repo.FetchPending(context.Background(), 10)
+	assert.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, int64(1), events[0].Sequence)
+	assert.Equal(t, eventID, events[0].ID)
+	assert.Equal(t, aggregateID, events[0].AggregateID)
+	assert.Equal(t, &now, events[0].ClaimedUntil)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestPGOutboxRepository_FetchPending_LockHeld(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGOutboxRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT pg_try_advisory_xact_lock($1)`)).
+		WithArgs(outboxRelayLockKey).
+		WillReturnRows(pgxmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+
+	events, err := repo.FetchPending(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestPGOutboxRepository_MarkPublished(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGOutboxRepository(db)
+
+	const expectedSQL = `UPDATE outbox_events SET published_at = CURRENT_TIMESTAMP, claimed_until = NULL, last_error = NULL WHERE sequence = ANY($1)`
+
+	mock.ExpectExec(regexp.QuoteMeta(expectedSQL)).
+		WithArgs([]int64{1, 2}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+	err = repo.MarkPublished(context.Background(), []int64{1, 2})
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestPGOutboxRepository_Claim(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGOutboxRepository(db)
+	until := time.Now().Add(time.Minute)
+
+	const expectedSQL = `UPDATE outbox_events SET claimed_until = $2 WHERE sequence = ANY($1)`
+
+	mock.ExpectExec(regexp.QuoteMeta(expectedSQL)).
+		WithArgs([]int64{1, 2}, until).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+	err = repo.Claim(context.Background(), []int64{1, 2}, until)
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestPGOutboxRepository_MarkFailed(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGOutboxRepository(db)
+
+	const expectedSQL = `UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, claimed_until = NULL, dead_at = CASE WHEN $3 THEN CURRENT_TIMESTAMP END WHERE sequence = $1`
+
+	mock.ExpectExec(regexp.QuoteMeta(expectedSQL)).
+		WithArgs(int64(1), "sink down", true).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = repo.MarkFailed(context.Background(), 1, "sink down", true)
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
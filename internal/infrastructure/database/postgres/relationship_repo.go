@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+)
+
+const relationshipColumns = `id, source_model_id, target_model_id, type, created_at`
+
+// relatedModelsQuery walks the relationship graph breadth first. edges
+// holds the relationships of the type in the directions being followed;
+// reach collects (model, depth) pairs, which UNION keeps distinct, so the
+// walk ends at the depth limit even when the graph has cycles.
+const relatedModelsQuery = `
+	WITH RECURSIVE edges (from_id, to_id) AS (
+		SELECT source_model_id, target_model_id FROM model_relationships WHERE type = $2 AND $3::boolean
+		UNION ALL
+		SELECT target_model_id, source_model_id FROM model_relationships WHERE type = $2 AND $4::boolean
+	),
+	reach (model_id, depth) AS (
+		SELECT $1::uuid, 0
+		UNION
+		SELECT edges.to_id, reach.depth + 1
+		FROM reach
+		JOIN edges ON edges.from_id = reach.model_id
+		WHERE reach.depth < $5
+	)
+	SELECT model_id, MIN(depth) AS depth
+	FROM reach
+	WHERE model_id <> $1
+	GROUP BY model_id
+	ORDER BY depth, model_id
+`
+
+type PGRelationshipRepository struct {
+	db     database.PgxPool
+	reader database.PgxPool
+}
+
+func NewPGRelationshipRepository(db database.Database) *PGRelationshipRepository {
+	return &PGRelationshipRepository{
+		db:     db.GetPool(),
+		reader: db.GetReadPool(),
+	}
+}
+
+func (r *PGRelationshipRepository) CreateRelationship(ctx context.Context, relationship *models.ModelRelationship) (*models.ModelRelationship, error) {
+	query := `
+		INSERT INTO model_relationships (id, source_model_id, target_model_id, type, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + relationshipColumns
+
+	row := database.Conn(ctx, r.db).QueryRow(ctx, query,
+		relationship.ID,
+		relationship.SourceModelID,
+		relationship.TargetModelID,
+		relationship.Type,
+		relationship.CreatedAt,
+	)
+	created, err := scanRelationship(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return created, nil
+}
+
+func (r *PGRelationshipRepository) GetRelationship(ctx context.Context, id uuid.UUID) (*models.ModelRelationship, error) {
+	query := `SELECT ` + relationshipColumns + ` FROM model_relationships WHERE id = $1`
+
+	relationship, err := scanRelationship(database.Conn(ctx, r.reader).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return relationship, nil
+}
+
+func (r *PGRelationshipRepository) DeleteRelationship(ctx context.Context, id uuid.UUID) error {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM model_relationships WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PGRelationshipRepository) ListRelationships(ctx context.Context, filter models.RelationshipFilter) ([]*models.ModelRelationship, error) {
+	query := `
+		SELECT ` + relationshipColumns + `
+		FROM model_relationships
+		WHERE (source_model_id = $1 OR target_model_id = $1) AND ($2 = '' OR type::text = $2)
+		ORDER BY created_at, id
+	`
+
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, filter.ModelID, string(filter.Type))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var relationships []*models.ModelRelationship
+	for rows.Next() {
+		relationship, err := scanRelationship(rows)
+		if err != nil {
+			return nil, err
+		}
+		relationships = append(relationships, relationship)
+	}
+
+	return relationships, rows.Err()
+}
+
+// RelatedModels reads from the primary: it also checks for cycles before a
+// relationship is written, which must see the latest graph.
+func (r *PGRelationshipRepository) RelatedModels(ctx context.Context, query models.RelationshipQuery) ([]*models.RelatedModel, error) {
+	outgoing := query.Direction != models.DirectionIncoming
+	incoming := query.Direction != models.DirectionOutgoing
+
+	rows, err := database.Conn(ctx, r.db).Query(ctx, relatedModelsQuery, query.ModelID, query.Type, outgoing, incoming, query.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var related []*models.RelatedModel
+	for rows.Next() {
+		var model models.RelatedModel
+		if err := rows.Scan(&model.ModelID, &model.Depth); err != nil {
+			return nil, err
+		}
+		related = append(related, &model)
+	}
+
+	return related, rows.Err()
+}
+
+// LockRelationshipType takes a transaction-level advisory lock keyed by the
+// hash of the type name.
+func (r *PGRelationshipRepository) LockRelationshipType(ctx context.Context, relationshipType models.RelationshipType) error {
+	_, err := database.Conn(ctx, r.db).Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, string(relationshipType))
+	return err
+}
+
+func scanRelationship(row pgx.Row) (*models.ModelRelationship, error) {
+	var relationship models.ModelRelationship
+	err := row.Scan(
+		&relationship.ID,
+		&relationship.SourceModelID,
+		&relationship.TargetModelID,
+		&relationship.Type,
+		&relationship.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &relationship, nil
+}
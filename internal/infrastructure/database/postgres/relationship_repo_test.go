@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestPGRelationshipRepository_CreateRelationship_Duplicate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGRelationshipRepository(&mockModelDB{mock})
+	relationship := &models.ModelRelationship{
+		ID:            uuid.New(),
+		SourceModelID: uuid.New(),
+		TargetModelID: uuid.New(),
+		Type:          models.RelationshipRequires,
+		CreatedAt:     time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	mock.ExpectQuery(`INSERT INTO model_relationships`).
+		WithArgs(relationship.ID, relationship.SourceModelID, relationship.TargetModelID, models.RelationshipRequires, relationship.CreatedAt).
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation, ConstraintName: "uq_model_relationships_edge"})
+
+	_, err = repo.CreateRelationship(context.Background(), relationship)
+	assert.ErrorIs(t, err, models.ErrAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGRelationshipRepository_RelatedModels(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction models.RelationshipDirection
+		outgoing  bool
+		incoming  bool
+	}{
+		{"outgoing", models.DirectionOutgoing, true, false},
+		{"incoming", models.DirectionIncoming, false, true},
+		{"both", models.DirectionBoth, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			defer mock.Close()
+
+			repo := NewPGRelationshipRepository(&mockModelDB{mock})
+			start, hub, bridge := uuid.New(), uuid.New(), uuid.New()
+
+			mock.ExpectQuery(`WITH RECURSIVE edges .* reach \(model_id, depth\) AS .* WHERE reach\.depth < \$5`).
+				WithArgs(start, models.RelationshipCompatibleWith, tt.outgoing, tt.incoming, 3).
+				WillReturnRows(pgxmock.NewRows([]string{"model_id", "depth"}).
+					AddRow(hub, 1).
+					AddRow(bridge, 2))
+
+			related, err := repo.RelatedModels(context.Background(), models.RelationshipQuery{
+				ModelID:   start,
+				Type:      models.RelationshipCompatibleWith,
+				Direction: tt.direction,
+				MaxDepth:  3,
+			})
+			require.NoError(t, err)
+			require.Len(t, related, 2)
+			assert.Equal(t, hub, related[0].ModelID)
+			assert.Equal(t, 1, related[0].Depth)
+			assert.Equal(t, bridge, related[1].ModelID)
+			assert.Equal(t, 2, related[1].Depth)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPGRelationshipRepository_LockRelationshipType(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGRelationshipRepository(&mockModelDB{mock})
+
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(hashtext\(\$1\)\)`).
+		WithArgs("replaces").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+
+	assert.NoError(t, repo.LockRelationshipType(context.Background(), models.RelationshipReplaces))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+)
+
+const sceneColumns = `id, name, COALESCE(description, ''), steps, stop_on_error, created_at, updated_at`
+
+type PGSceneRepository struct {
+	db     database.PgxPool
+	reader database.PgxPool
+}
+
+func NewPGSceneRepository(db database.Database) *PGSceneRepository {
+	return &PGSceneRepository{
+		db:     db.GetPool(),
+		reader: db.GetReadPool(),
+	}
+}
+
+func (r *PGSceneRepository) CreateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	query := `
+		INSERT INTO scenes (id, name, description, steps, stop_on_error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + sceneColumns
+
+	row := database.Conn(ctx, r.db).QueryRow(ctx, query,
+		scene.ID,
+		scene.Name,
+		scene.Description,
+		sceneStepsOrEmpty(scene.Steps),
+		scene.StopOnError,
+		scene.CreatedAt,
+		scene.UpdatedAt,
+	)
+	created, err := scanScene(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return created, nil
+}
+
+func (r *PGSceneRepository) GetScene(ctx context.Context, id uuid.UUID) (*models.Scene, error) {
+	query := `SELECT ` + sceneColumns + ` FROM scenes WHERE id = $1`
+
+	scene, err := scanScene(database.Conn(ctx, r.reader).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return scene, nil
+}
+
+func (r *PGSceneRepository) ListScenes(ctx context.Context) ([]*models.Scene, error) {
+	query := `SELECT ` + sceneColumns + ` FROM scenes ORDER BY created_at, id`
+
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scenes []*models.Scene
+	for rows.Next() {
+		scene, err := scanScene(rows)
+		if err != nil {
+			return nil, err
+		}
+		scenes = append(scenes, scene)
+	}
+
+	return scenes, rows.Err()
+}
+
+func (r *PGSceneRepository) UpdateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	query := `
+		UPDATE scenes
+		SET name = $2, description = $3, steps = $4, stop_on_error = $5, updated_at = $6
+		WHERE id = $1
+		RETURNING ` + sceneColumns
+
+	row := database.Conn(ctx, r.db).QueryRow(ctx, query,
+		scene.ID,
+		scene.Name,
+		scene.Description,
+		sceneStepsOrEmpty(scene.Steps),
+		scene.StopOnError,
+		scene.UpdatedAt,
+	)
+	updated, err := scanScene(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return updated, nil
+}
+
+func (r *PGSceneRepository) DeleteScene(ctx context.Context, id uuid.UUID) error {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM scenes WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func scanScene(row pgx.Row) (*models.Scene, error) {
+	var scene models.Scene
+	err := row.Scan(
+		&scene.ID,
+		&scene.Name,
+		&scene.Description,
+		&scene.Steps,
+		&scene.StopOnError,
+		&scene.CreatedAt,
+		&scene.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &scene, nil
+}
+
+// sceneStepsOrEmpty keeps a nil slice, which pgx encodes as NULL, out of the
+// NOT NULL JSONB column.
+func sceneStepsOrEmpty(steps []models.SceneStep) []models.SceneStep {
+	if steps == nil {
+		return []models.SceneStep{}
+	}
+	return steps
+}
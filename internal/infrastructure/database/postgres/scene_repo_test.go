@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestPGSceneRepository_CreateScene_WithoutSteps(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSceneRepository(&mockModelDB{mock})
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	scene := &models.Scene{ID: uuid.New(), Name: "Empty", CreatedAt: createdAt, UpdatedAt: createdAt}
+
+	mock.ExpectQuery(`INSERT INTO scenes`).
+		WithArgs(scene.ID, "Empty", "", []models.SceneStep{}, false, createdAt, createdAt).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "steps", "stop_on_error", "created_at", "updated_at"}).
+			AddRow(scene.ID, "Empty", "", []models.SceneStep{}, false, createdAt, createdAt))
+
+	created, err := repo.CreateScene(context.Background(), scene)
+	require.NoError(t, err)
+	assert.Equal(t, scene.ID, created.ID)
+	assert.Empty(t, created.Steps)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSceneRepository_UpdateScene_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSceneRepository(&mockModelDB{mock})
+	updatedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	scene := &models.Scene{
+		ID:        uuid.New(),
+		Name:      "Movie night",
+		Steps:     []models.SceneStep{{DeviceID: "tv-1", FeatureID: uuid.New()}},
+		UpdatedAt: updatedAt,
+	}
+
+	mock.ExpectQuery(`UPDATE scenes`).
+		WithArgs(scene.ID, "Movie night", "", scene.Steps, false, updatedAt).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "steps", "stop_on_error", "created_at", "updated_at"}))
+
+	_, err = repo.UpdateScene(context.Background(), scene)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,297 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+const scheduleColumns = `id, name, COALESCE(description, ''), COALESCE(cron, ''), COALESCE(interval_seconds, 0), timezone, device_id, feature_id, parameters, paused, next_run_at, last_run_at, created_at, updated_at`
+
+const scheduleRunColumns = `id, schedule_id, scheduled_at, status, COALESCE(error, ''), started_at, finished_at`
+
+type PGScheduleRepository struct {
+	db     database.PgxPool
+	reader database.PgxPool
+}
+
+func NewPGScheduleRepository(db database.Database) *PGScheduleRepository {
+	return &PGScheduleRepository{
+		db:     db.GetPool(),
+		reader: db.GetReadPool(),
+	}
+}
+
+func (r *PGScheduleRepository) CreateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	query := `
+		INSERT INTO schedules (id, name, description, cron, interval_seconds, timezone, device_id, feature_id, parameters,
+			paused, next_run_at, last_run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, 0), $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING ` + scheduleColumns
+
+	row := database.Conn(ctx, r.db).QueryRow(ctx, query,
+		schedule.ID,
+		schedule.Name,
+		schedule.Description,
+		schedule.Cron,
+		int64(schedule.Interval/time.Second),
+		schedule.Timezone,
+		schedule.DeviceID,
+		schedule.FeatureID,
+		jsonObjectOrEmpty(schedule.Parameters),
+		schedule.Paused,
+		schedule.NextRunAt,
+		schedule.LastRunAt,
+		schedule.CreatedAt,
+		schedule.UpdatedAt,
+	)
+	created, err := scanSchedule(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return created, nil
+}
+
+func (r *PGScheduleRepository) GetSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE id = $1`
+
+	schedule, err := scanSchedule(database.Conn(ctx, r.reader).QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return schedule, nil
+}
+
+func (r *PGScheduleRepository) ListSchedules(ctx context.Context) ([]*models.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules ORDER BY created_at, id`
+
+	return r.querySchedules(ctx, r.reader, query)
+}
+
+func (r *PGScheduleRepository) UpdateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	query := `
+		UPDATE schedules
+		SET name = $2, description = $3, cron = NULLIF($4, ''), interval_seconds = NULLIF($5, 0), timezone = $6,
+			device_id = $7, feature_id = $8, parameters = $9, paused = $10, next_run_at = $11, last_run_at = $12,
+			updated_at = $13
+		WHERE id = $1
+		RETURNING ` + scheduleColumns
+
+	row := database.Conn(ctx, r.db).QueryRow(ctx, query,
+		schedule.ID,
+		schedule.Name,
+		schedule.Description,
+		schedule.Cron,
+		int64(schedule.Interval/time.Second),
+		schedule.Timezone,
+		schedule.DeviceID,
+		schedule.FeatureID,
+		jsonObjectOrEmpty(schedule.Parameters),
+		schedule.Paused,
+		schedule.NextRunAt,
+		schedule.LastRunAt,
+		schedule.UpdatedAt,
+	)
+	updated, err := scanSchedule(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return updated, nil
+}
+
+func (r *PGScheduleRepository) DeleteSchedule(ctx context.Context, id uuid.UUID) error {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM schedules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// ListDueSchedules reads from the primary, which AdvanceSchedule checks
+// against anyway.
+func (r *PGScheduleRepository) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]*models.Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		WHERE NOT paused AND next_run_at <= $1
+		ORDER BY next_run_at, id
+		LIMIT $2
+	`
+
+	return r.querySchedules(ctx, r.db, query, now, limit)
+}
+
+func (r *PGScheduleRepository) AdvanceSchedule(ctx context.Context, id uuid.UUID, due, next time.Time) error {
+	query := `
+		UPDATE schedules
+		SET next_run_at = $3, last_run_at = $2
+		WHERE id = $1 AND next_run_at = $2 AND NOT paused
+	`
+
+	conn := database.Conn(ctx, r.db)
+	tag, err := conn.Exec(ctx, query, id, due, next)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM schedules WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return models.ErrNotFound
+	}
+	return fmt.Errorf("%w: schedule %s is no longer due at %s", models.ErrVersionConflict, id, due.Format(time.RFC3339))
+}
+
+func (r *PGScheduleRepository) CreateRun(ctx context.Context, run *models.ScheduleRun) error {
+	query := `
+		INSERT INTO schedule_runs (id, schedule_id, scheduled_at, status, error, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+	`
+
+	_, err := database.Conn(ctx, r.db).Exec(ctx, query,
+		run.ID,
+		run.ScheduleID,
+		run.ScheduledAt,
+		run.Status,
+		run.Error,
+		run.StartedAt,
+		run.FinishedAt,
+	)
+	return mapError(err)
+}
+
+func (r *PGScheduleRepository) FinishRun(ctx context.Context, run *models.ScheduleRun) error {
+	query := `
+		UPDATE schedule_runs
+		SET status = $2, error = NULLIF($3, ''), finished_at = $4
+		WHERE id = $1
+	`
+
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, query, run.ID, run.Status, run.Error, run.FinishedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PGScheduleRepository) ListRuns(ctx context.Context, filter models.ScheduleRunFilter) ([]*models.ScheduleRun, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.ScheduleID != nil {
+		conditions = append(conditions, "schedule_id = "+arg(*filter.ScheduleID))
+	}
+	if filter.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(started_at, id) < (%s, %s)", arg(filter.After.CreatedAt), arg(filter.After.ID)))
+	}
+
+	query := `
+		SELECT ` + scheduleRunColumns + `
+		FROM schedule_runs`
+	if len(conditions) > 0 {
+		query += `
+		WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += `
+		ORDER BY started_at DESC, id DESC
+		LIMIT ` + arg(filter.Limit)
+
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*models.ScheduleRun
+	for rows.Next() {
+		var run models.ScheduleRun
+		err = rows.Scan(
+			&run.ID,
+			&run.ScheduleID,
+			&run.ScheduledAt,
+			&run.Status,
+			&run.Error,
+			&run.StartedAt,
+			&run.FinishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, rows.Err()
+}
+
+func (r *PGScheduleRepository) PruneRuns(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM schedule_runs WHERE started_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *PGScheduleRepository) querySchedules(ctx context.Context, pool database.PgxPool, query string, args ...interface{}) ([]*models.Schedule, error) {
+	rows, err := database.Conn(ctx, pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []*models.Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func scanSchedule(row pgx.Row) (*models.Schedule, error) {
+	var schedule models.Schedule
+	var seconds int64
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.Name,
+		&schedule.Description,
+		&schedule.Cron,
+		&seconds,
+		&schedule.Timezone,
+		&schedule.DeviceID,
+		&schedule.FeatureID,
+		&schedule.Parameters,
+		&schedule.Paused,
+		&schedule.NextRunAt,
+		&schedule.LastRunAt,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	schedule.Interval = time.Duration(seconds) * time.Second
+	return &schedule, nil
+}
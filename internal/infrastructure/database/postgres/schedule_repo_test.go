@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestPGScheduleRepository_AdvanceSchedule(t *testing.T) {
+	due := time.Date(2024, 3, 1, 7, 30, 0, 0, time.UTC)
+	next := due.Add(24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		updated int64
+		exists  bool
+		err     error
+	}{
+		{"advanced", 1, true, nil},
+		{"already advanced", 0, true, models.ErrVersionConflict},
+		{"deleted", 0, false, models.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			defer mock.Close()
+
+			repo := NewPGScheduleRepository(&mockModelDB{mock})
+			id := uuid.New()
+
+			mock.ExpectExec(`UPDATE schedules\s+SET next_run_at = \$3, last_run_at = \$2\s+WHERE id = \$1 AND next_run_at = \$2 AND NOT paused`).
+				WithArgs(id, due, next).
+				WillReturnResult(pgxmock.NewResult("UPDATE", tt.updated))
+			if tt.updated == 0 {
+				mock.ExpectQuery(`SELECT EXISTS`).
+					WithArgs(id).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(tt.exists))
+			}
+
+			err = repo.AdvanceSchedule(context.Background(), id, due, next)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPGScheduleRepository_ListDueSchedules(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGScheduleRepository(&mockModelDB{mock})
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	id := uuid.New()
+	featureID := uuid.New()
+
+	mock.ExpectQuery(`FROM schedules\s+WHERE NOT paused AND next_run_at <= \$1\s+ORDER BY next_run_at, id\s+LIMIT \$2`).
+		WithArgs(now, 100).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "cron", "interval_seconds", "timezone", "device_id",
+			"feature_id", "parameters", "paused", "next_run_at", "last_run_at", "created_at", "updated_at"}).
+			AddRow(id, "Poll meter", "", "", int64(900), "UTC", "meter-1", featureID, map[string]interface{}{},
+				false, now.Add(-time.Minute), (*time.Time)(nil), now.Add(-time.Hour), now.Add(-time.Hour)))
+
+	schedules, err := repo.ListDueSchedules(context.Background(), now, 100)
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.Equal(t, id, schedules[0].ID)
+	assert.Equal(t, 15*time.Minute, schedules[0].Interval)
+	assert.Empty(t, schedules[0].Cron)
+	assert.Nil(t, schedules[0].LastRunAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,218 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+const shadowColumns = `device_id, desired, reported, version, created_at, updated_at`
+
+type PGShadowRepository struct {
+	db     database.PgxPool
+	reader database.PgxPool
+}
+
+func NewPGShadowRepository(db database.Database) *PGShadowRepository {
+	return &PGShadowRepository{
+		db:     db.GetPool(),
+		reader: db.GetReadPool(),
+	}
+}
+
+func (r *PGShadowRepository) GetShadow(ctx context.Context, deviceID string) (*models.DeviceShadow, error) {
+	query := `SELECT ` + shadowColumns + ` FROM device_shadows WHERE device_id = $1`
+
+	shadow, err := scanShadow(database.Conn(ctx, r.reader).QueryRow(ctx, query, deviceID))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return shadow, nil
+}
+
+func (r *PGShadowRepository) ListShadows(ctx context.Context, deviceIDs []string) ([]*models.DeviceShadow, error) {
+	query := `
+		SELECT ` + shadowColumns + `
+		FROM device_shadows
+		WHERE cardinality($1::VARCHAR[]) = 0 OR device_id = ANY($1)
+		ORDER BY device_id
+	`
+
+	if deviceIDs == nil {
+		deviceIDs = []string{}
+	}
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, deviceIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shadows []*models.DeviceShadow
+	for rows.Next() {
+		shadow, err := scanShadow(rows)
+		if err != nil {
+			return nil, err
+		}
+		shadows = append(shadows, shadow)
+	}
+
+	return shadows, rows.Err()
+}
+
+// SaveShadow inserts the shadow when expectedVersion is 0 and otherwise
+// updates it only while it is still at expectedVersion, so two writers
+// racing on the same version cannot both succeed.
+func (r *PGShadowRepository) SaveShadow(ctx context.Context, shadow *models.DeviceShadow, expectedVersion int64) error {
+	conn := database.Conn(ctx, r.db)
+
+	if expectedVersion == 0 {
+		query := `
+			INSERT INTO device_shadows (device_id, desired, reported, version, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (device_id) DO NOTHING
+		`
+		tag, err := conn.Exec(ctx, query,
+			shadow.DeviceID,
+			jsonObjectOrEmpty(shadow.Desired),
+			jsonObjectOrEmpty(shadow.Reported),
+			shadow.Version,
+			shadow.CreatedAt,
+			shadow.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return models.ErrVersionConflict
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE device_shadows
+		SET desired = $2, reported = $3, version = $4, updated_at = $5
+		WHERE device_id = $1 AND version = $6
+	`
+	tag, err := conn.Exec(ctx, query,
+		shadow.DeviceID,
+		jsonObjectOrEmpty(shadow.Desired),
+		jsonObjectOrEmpty(shadow.Reported),
+		shadow.Version,
+		shadow.UpdatedAt,
+		expectedVersion,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *PGShadowRepository) DeleteShadow(ctx context.Context, deviceID string) error {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM device_shadows WHERE device_id = $1`, deviceID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PGShadowRepository) AddDelta(ctx context.Context, delta *models.ShadowDelta) error {
+	query := `
+		INSERT INTO device_shadow_deltas (device_id, version, delta, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING sequence
+	`
+
+	return database.Conn(ctx, r.db).QueryRow(ctx, query,
+		delta.DeviceID,
+		delta.Version,
+		jsonObjectOrEmpty(delta.Delta),
+		delta.CreatedAt,
+	).Scan(&delta.Sequence)
+}
+
+func (r *PGShadowRepository) ListDeltasSince(ctx context.Context, after int64, limit int) ([]*models.ShadowDelta, error) {
+	query := `
+		SELECT sequence, device_id, version, delta, created_at
+		FROM device_shadow_deltas
+		WHERE sequence > $1
+		ORDER BY sequence
+		LIMIT $2
+	`
+
+	rows, err := database.Conn(ctx, r.db).Query(ctx, query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deltas []*models.ShadowDelta
+	for rows.Next() {
+		var delta models.ShadowDelta
+		err = rows.Scan(
+			&delta.Sequence,
+			&delta.DeviceID,
+			&delta.Version,
+			&delta.Delta,
+			&delta.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, &delta)
+	}
+
+	return deltas, rows.Err()
+}
+
+func (r *PGShadowRepository) LatestDeltaSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := database.Conn(ctx, r.db).QueryRow(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM device_shadow_deltas`).Scan(&sequence)
+	return sequence, err
+}
+
+func (r *PGShadowRepository) OldestDeltaSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := database.Conn(ctx, r.db).QueryRow(ctx, `SELECT COALESCE(MIN(sequence), 0) FROM device_shadow_deltas`).Scan(&sequence)
+	return sequence, err
+}
+
+func (r *PGShadowRepository) PruneDeltas(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM device_shadow_deltas WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanShadow(row pgx.Row) (*models.DeviceShadow, error) {
+	var shadow models.DeviceShadow
+	err := row.Scan(
+		&shadow.DeviceID,
+		&shadow.Desired,
+		&shadow.Reported,
+		&shadow.Version,
+		&shadow.CreatedAt,
+		&shadow.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &shadow, nil
+}
+
+// jsonObjectOrEmpty keeps the NOT NULL JSONB columns from receiving a nil
+// map, which pgx encodes as NULL.
+func jsonObjectOrEmpty(value map[string]interface{}) map[string]interface{} {
+	if value == nil {
+		return map[string]interface{}{}
+	}
+	return value
+}
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func newTestShadow(version int64) *models.DeviceShadow {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &models.DeviceShadow{
+		DeviceID:  "dev-1",
+		Desired:   map[string]interface{}{"a": "on"},
+		Version:   version,
+		CreatedAt: at,
+		UpdatedAt: at,
+	}
+}
+
+func TestPGShadowRepository_SaveShadow_InsertConflict(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGShadowRepository(&mockModelDB{mock})
+	shadow := newTestShadow(1)
+
+	mock.ExpectExec(`INSERT INTO device_shadows .* ON CONFLICT \(device_id\) DO NOTHING`).
+		WithArgs("dev-1", shadow.Desired, map[string]interface{}{}, int64(1), shadow.CreatedAt, shadow.UpdatedAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+	err = repo.SaveShadow(context.Background(), shadow, 0)
+	assert.ErrorIs(t, err, models.ErrVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGShadowRepository_SaveShadow_UpdateChecksVersion(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGShadowRepository(&mockModelDB{mock})
+	shadow := newTestShadow(5)
+
+	mock.ExpectExec(`UPDATE device_shadows .* WHERE device_id = \$1 AND version = \$6`).
+		WithArgs("dev-1", shadow.Desired, map[string]interface{}{}, int64(5), shadow.UpdatedAt, int64(4)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`UPDATE device_shadows .* WHERE device_id = \$1 AND version = \$6`).
+		WithArgs("dev-1", shadow.Desired, map[string]interface{}{}, int64(5), shadow.UpdatedAt, int64(4)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	require.NoError(t, repo.SaveShadow(context.Background(), shadow, 4))
+	err = repo.SaveShadow(context.Background(), shadow, 4)
+	assert.ErrorIs(t, err, models.ErrVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGShadowRepository_GetShadow_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGShadowRepository(&mockModelDB{mock})
+
+	mock.ExpectQuery(`SELECT .* FROM device_shadows WHERE device_id = \$1`).
+		WithArgs("dev-1").
+		WillReturnError(pgx.ErrNoRows)
+
+	shadow, err := repo.GetShadow(context.Background(), "dev-1")
+	assert.Nil(t, shadow)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,28 +2,46 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
 	"smart-hub/internal/common/database"
 	"smart-hub/internal/domain/models"
+	"strings"
+)
+
+const (
+	insertSmartFeatureSQL = `
+		INSERT INTO smart_features (id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at
+	`
+	updateSmartFeatureSQL = `
+		UPDATE smart_features
+		SET name = $2, description = $3, protocol = $4, interface_path = $5, parameters = $6, updated_at = $7
+		WHERE id = $1
+		RETURNING id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at
+	`
 )
 
 type PGSmartFeatureRepository struct {
-	db database.PgxPool
+	db     database.PgxPool
+	reader database.PgxPool
 }
 
 func NewPGSmartFeatureRepository(db database.Database) *PGSmartFeatureRepository {
 	return &PGSmartFeatureRepository{
-		db: db.GetPool(),
+		db:     db.GetPool(),
+		reader: db.GetReadPool(),
 	}
 }
 
 func (r *PGSmartFeatureRepository) Create(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error) {
-	query := `
-		INSERT INTO smart_features (id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)	
-		RETURNING id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at
-	`
+	if _, err := r.checkNamePaths(ctx, []*models.SmartFeature{feature}); err != nil {
+		return nil, err
+	}
 
-	row := r.db.QueryRow(ctx, query, feature.ID, feature.ModelID, feature.Name, feature.Description, feature.Protocol, feature.InterfacePath, feature.Parameters, feature.CreatedAt, feature.UpdatedAt)
+	row := database.Conn(ctx, r.db).QueryRow(ctx, insertSmartFeatureSQL, feature.ID, feature.ModelID, feature.Name, feature.Description, feature.Protocol, feature.InterfacePath, feature.Parameters, feature.CreatedAt, feature.UpdatedAt)
 
 	var createdFeature models.SmartFeature
 	err := row.Scan(
@@ -39,7 +57,7 @@ func (r *PGSmartFeatureRepository) Create(ctx context.Context, feature *models.S
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 
 	return &createdFeature, nil
@@ -52,7 +70,7 @@ func (r *PGSmartFeatureRepository) GetByID(ctx context.Context, id string) (*mod
 		WHERE id = $1
 	`
 
-	row := r.db.QueryRow(ctx, query, id)
+	row := database.Conn(ctx, r.reader).QueryRow(ctx, query, id)
 
 	var feature models.SmartFeature
 	err := row.Scan(
@@ -68,7 +86,7 @@ func (r *PGSmartFeatureRepository) GetByID(ctx context.Context, id string) (*mod
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 
 	return &feature, nil
@@ -79,12 +97,14 @@ func (r *PGSmartFeatureRepository) GetWithModelID(ctx context.Context, modelID s
 		SELECT id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at
 		FROM smart_features
 		WHERE model_id = $1
+		ORDER BY created_at, id
 	`
 
-	rows, err := r.db.Query(ctx, query, modelID)
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, modelID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var features []*models.SmartFeature
 	for rows.Next() {
@@ -107,22 +127,96 @@ func (r *PGSmartFeatureRepository) GetWithModelID(ctx context.Context, modelID s
 		features = append(features, &feature)
 	}
 
-	return features, nil
+	return features, rows.Err()
 }
 
-func (r *PGSmartFeatureRepository) GetAll(ctx context.Context) ([]*models.SmartFeature, error) {
+func (r *PGSmartFeatureRepository) GetWithModelIDs(ctx context.Context, modelIDs []string) ([]*models.SmartFeature, error) {
 	query := `
 		SELECT id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at
 		FROM smart_features
+		WHERE model_id = ANY($1::uuid[])
+		ORDER BY created_at, id
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, modelIDs)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var features []*models.SmartFeature
+	for rows.Next() {
+		var feature models.SmartFeature
+		err = rows.Scan(
+			&feature.ID,
+			&feature.ModelID,
+			&feature.Name,
+			&feature.Description,
+			&feature.Protocol,
+			&feature.InterfacePath,
+			&feature.Parameters,
+			&feature.CreatedAt,
+			&feature.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		features = append(features, &feature)
+	}
+
+	return features, rows.Err()
+}
+
+func (r *PGSmartFeatureRepository) List(ctx context.Context, filter models.FeatureFilter, after *models.PageCursor, limit int) ([]*models.SmartFeature, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Protocol != nil {
+		conditions = append(conditions, "protocol = "+arg(*filter.Protocol))
+	}
+	var modelConditions []string
+	if filter.ModelType != nil {
+		modelConditions = append(modelConditions, "type = "+arg(*filter.ModelType))
+	}
+	if filter.ModelCategory != nil {
+		modelConditions = append(modelConditions, "category = "+arg(*filter.ModelCategory))
+	}
+	if len(modelConditions) > 0 {
+		conditions = append(conditions, "model_id IN (SELECT id FROM smart_models WHERE "+strings.Join(modelConditions, " AND ")+")")
+	}
+	if filter.NamePrefix != "" {
+		conditions = append(conditions, "starts_with(name, "+arg(filter.NamePrefix)+")")
+	}
+	if filter.InterfacePathPattern != "" {
+		conditions = append(conditions, "interface_path LIKE "+arg(globToLike(filter.InterfacePathPattern))+` ESCAPE '\'`)
+	}
+	if after != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) > (%s, %s)", arg(after.CreatedAt), arg(after.ID)))
+	}
+
+	query := `
+		SELECT id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at
+		FROM smart_features`
+	if len(conditions) > 0 {
+		query += `
+		WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += `
+		ORDER BY created_at, id
+		LIMIT ` + arg(limit)
+
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	var features []*models.SmartFeature
 	for rows.Next() {
 		var feature models.SmartFeature
 		err = rows.Scan(
@@ -143,20 +237,55 @@ func (r *PGSmartFeatureRepository) GetAll(ctx context.Context) ([]*models.SmartF
 		features = append(features, &feature)
 	}
 
-	return features, nil
+	return features, rows.Err()
 }
 
-func (r *PGSmartFeatureRepository) Update(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error) {
+func (r *PGSmartFeatureRepository) GetAll(ctx context.Context) ([]*models.SmartFeature, error) {
 	query := `
-		UPDATE smart_features
-		SET name = $2, description = $3, protocol = $4, interface_path = $5, parameters = $6, updated_at = $7
-		WHERE id = $1
-		RETURNING id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at
+		SELECT id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at
+		FROM smart_features
+		ORDER BY created_at, id
 	`
 
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var features []*models.SmartFeature
+
+	for rows.Next() {
+		var feature models.SmartFeature
+		err = rows.Scan(
+			&feature.ID,
+			&feature.ModelID,
+			&feature.Name,
+			&feature.Description,
+			&feature.Protocol,
+			&feature.InterfacePath,
+			&feature.Parameters,
+			&feature.CreatedAt,
+			&feature.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		features = append(features, &feature)
+	}
+
+	return features, rows.Err()
+}
+
+func (r *PGSmartFeatureRepository) Update(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error) {
+	if _, err := r.checkNamePaths(ctx, []*models.SmartFeature{feature}); err != nil {
+		return nil, err
+	}
+
 	updatedFeature := models.SmartFeature{}
 
-	err := r.db.QueryRow(ctx, query,
+	err := database.Conn(ctx, r.db).QueryRow(ctx, updateSmartFeatureSQL,
 		feature.ID,
 		feature.Name,
 		feature.Description,
@@ -177,7 +306,7 @@ func (r *PGSmartFeatureRepository) Update(ctx context.Context, feature *models.S
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 
 	return &updatedFeature, nil
@@ -190,10 +319,228 @@ func (r *PGSmartFeatureRepository) Delete(ctx context.Context, id string) error
 		WHERE id = $1
 	`
 
-	_, err := r.db.Exec(ctx, query, id)
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
 
 	return nil
 }
+
+func (r *PGSmartFeatureRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.SmartFeature, error) {
+	query := `
+		SELECT id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at
+		FROM smart_features
+		WHERE id = ANY($1::uuid[])
+		ORDER BY created_at, id
+	`
+
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var features []*models.SmartFeature
+	for rows.Next() {
+		var feature models.SmartFeature
+		err = rows.Scan(
+			&feature.ID,
+			&feature.ModelID,
+			&feature.Name,
+			&feature.Description,
+			&feature.Protocol,
+			&feature.InterfacePath,
+			&feature.Parameters,
+			&feature.CreatedAt,
+			&feature.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		features = append(features, &feature)
+	}
+
+	return features, rows.Err()
+}
+
+// CreateBatch sends all inserts in one round trip.
+func (r *PGSmartFeatureRepository) CreateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error) {
+	if err := r.checkBatchNamePaths(ctx, features); err != nil {
+		return nil, err
+	}
+
+	batch := &pgx.Batch{}
+	for _, feature := range features {
+		batch.Queue(insertSmartFeatureSQL, feature.ID, feature.ModelID, feature.Name, feature.Description, feature.Protocol, feature.InterfacePath, feature.Parameters, feature.CreatedAt, feature.UpdatedAt)
+	}
+	return r.sendBatch(ctx, batch)
+}
+
+// UpdateBatch sends all updates in one round trip, like CreateBatch.
+func (r *PGSmartFeatureRepository) UpdateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error) {
+	if err := r.checkBatchNamePaths(ctx, features); err != nil {
+		return nil, err
+	}
+
+	batch := &pgx.Batch{}
+	for _, feature := range features {
+		batch.Queue(updateSmartFeatureSQL, feature.ID, feature.Name, feature.Description, feature.Protocol, feature.InterfacePath, feature.Parameters, feature.UpdatedAt)
+	}
+	return r.sendBatch(ctx, batch)
+}
+
+// sendBatch runs batch in a transaction, so an UPDATE that matches no row
+// rolls back the statements before it too.
+func (r *PGSmartFeatureRepository) sendBatch(ctx context.Context, batch *pgx.Batch) ([]*models.SmartFeature, error) {
+	if batch.Len() == 0 {
+		return nil, nil
+	}
+
+	var features []*models.SmartFeature
+	uow := &PGUnitOfWork{db: r.db}
+	err := uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		features, err = scanBatch(database.Conn(ctx, r.db).SendBatch(ctx, batch), batch.Len())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return features, nil
+}
+
+// checkNamePaths looks for stored features that already have the name and
+// interface path of one of features within its model, to report which one
+// it is, like PGSmartModelRepository.checkModelNumber. An update does not
+// carry the model ID, so it is read from the stored feature. Two features of
+// the same batch that collide are only caught by the unique index. The
+// returned index is the first feature with a conflict.
+func (r *PGSmartFeatureRepository) checkNamePaths(ctx context.Context, features []*models.SmartFeature) (int, error) {
+	if len(features) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		SELECT c.ord, f.id
+		FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::text[]) WITH ORDINALITY AS c(id, model_id, name, interface_path, ord)
+		JOIN smart_features f
+		  ON f.model_id = COALESCE((SELECT s.model_id FROM smart_features s WHERE s.id = c.id), c.model_id)
+		 AND lower(f.name) = lower(c.name)
+		 AND lower(f.interface_path) = lower(c.interface_path)
+		 AND f.id <> c.id
+		ORDER BY c.ord
+		LIMIT 1
+	`
+
+	ids := make([]string, len(features))
+	modelIDs := make([]string, len(features))
+	names := make([]string, len(features))
+	paths := make([]string, len(features))
+	for i, feature := range features {
+		ids[i] = feature.ID.String()
+		modelIDs[i] = feature.ModelID.String()
+		names[i] = feature.Name
+		paths[i] = feature.InterfacePath
+	}
+
+	var ord int
+	conflict := &models.AlreadyExistsError{Entity: models.SmartFeatureAggregate}
+	err := database.Conn(ctx, r.db).QueryRow(ctx, query, ids, modelIDs, names, paths).Scan(&ord, &conflict.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return ord - 1, conflict
+}
+
+// checkBatchNamePaths is checkNamePaths for a batch write, naming the item
+// with the conflict like scanBatch.
+func (r *PGSmartFeatureRepository) checkBatchNamePaths(ctx context.Context, features []*models.SmartFeature) error {
+	i, err := r.checkNamePaths(ctx, features)
+	if errors.Is(err, models.ErrAlreadyExists) {
+		return &models.BatchItemError{Index: i, Err: err}
+	}
+	return err
+}
+
+func scanBatch(results pgx.BatchResults, n int) ([]*models.SmartFeature, error) {
+	defer results.Close()
+
+	features := make([]*models.SmartFeature, 0, n)
+	for i := 0; i < n; i++ {
+		var feature models.SmartFeature
+		err := results.QueryRow().Scan(
+			&feature.ID,
+			&feature.ModelID,
+			&feature.Name,
+			&feature.Description,
+			&feature.Protocol,
+			&feature.InterfacePath,
+			&feature.Parameters,
+			&feature.CreatedAt,
+			&feature.UpdatedAt,
+		)
+		if err != nil {
+			return nil, &models.BatchItemError{Index: i, Err: mapError(err)}
+		}
+		features = append(features, &feature)
+	}
+
+	return features, results.Close()
+}
+
+// DeleteBatch deletes in a single statement and rolls back when an ID does
+// not exist.
+func (r *PGSmartFeatureRepository) DeleteBatch(ctx context.Context, ids []string) error {
+	query := `
+		DELETE FROM smart_features
+		WHERE id = ANY($1::uuid[])
+	`
+
+	uow := &PGUnitOfWork{db: r.db}
+	return uow.Do(ctx, func(ctx context.Context) error {
+		tag, err := database.Conn(ctx, r.db).Exec(ctx, query, ids)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() != int64(countDistinct(ids)) {
+			return models.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func countDistinct(ids []string) int {
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+	return len(seen)
+}
+
+// globToLike turns a FeatureFilter glob into a LIKE pattern with \ as the
+// escape character.
+func globToLike(pattern string) string {
+	var like strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			like.WriteByte('%')
+		case '?':
+			like.WriteByte('_')
+		case '%', '_', '\\':
+			like.WriteByte('\\')
+			like.WriteRune(r)
+		default:
+			like.WriteRune(r)
+		}
+	}
+	return like.String()
+}
@@ -28,6 +28,10 @@ func (m *mockFeatureDB) GetPool() database.PgxPool {
 	return m
 }
 
+func (m *mockFeatureDB) GetReadPool() database.PgxPool {
+	return m
+}
+
 func TestPGSmartFeatureRepository_Create(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -61,6 +65,8 @@ func TestPGSmartFeatureRepository_Create(t *testing.T) {
 
 	const expectedSQL = `INSERT INTO smart_features (id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at`
 
+	expectNoNamePathConflict(mock)
+
 	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
 		WithArgs(
 			feature.ID, feature.ModelID, feature.Name, feature.Description,
@@ -287,6 +293,8 @@ func TestPGSmartFeatureRepository_Update(t *testing.T) {
 
 	const expectedSQL = `UPDATE smart_features SET name = $2, description = $3, protocol = $4, interface_path = $5, parameters = $6, updated_at = $7 WHERE id = $1 RETURNING id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at`
 
+	expectNoNamePathConflict(mock)
+
 	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
 		WithArgs(
 			feature.ID, feature.Name, feature.Description,
@@ -353,6 +361,8 @@ func TestPGSmartFeatureRepository_Create_Failed(t *testing.T) {
 
 	const expectedSQL = `INSERT INTO smart_features (id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at`
 
+	expectNoNamePathConflict(mock)
+
 	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
 		WithArgs(
 			feature.ID, feature.ModelID, feature.Name, feature.Description,
@@ -466,6 +476,8 @@ func TestPGSmartFeatureRepository_Update_Failed(t *testing.T) {
 
 	const expectedSQL = `UPDATE smart_features SET name = $2, description = $3, protocol = $4, interface_path = $5, parameters = $6, updated_at = $7 WHERE id = $1 RETURNING id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at`
 
+	expectNoNamePathConflict(mock)
+
 	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
 		WithArgs(
 			feature.ID, feature.Name, feature.Description,
@@ -505,3 +517,197 @@ func TestPGSmartFeatureRepository_Delete_Failed(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestPGSmartFeatureRepository_DeleteBatch(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSmartFeatureRepository(&mockFeatureDB{mock})
+
+	first, second := uuid.NewString(), uuid.NewString()
+	ids := []string{first, second, first}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM smart_features`)).
+		WithArgs(ids).
+		WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	mock.ExpectCommit()
+
+	err = repo.DeleteBatch(context.Background(), ids)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSmartFeatureRepository_DeleteBatch_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSmartFeatureRepository(&mockFeatureDB{mock})
+
+	ids := []string{uuid.NewString(), uuid.NewString()}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM smart_features`)).
+		WithArgs(ids).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectRollback()
+
+	err = repo.DeleteBatch(context.Background(), ids)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSmartFeatureRepository_GetByIDs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSmartFeatureRepository(&mockFeatureDB{mock})
+
+	now := time.Now()
+	feature := &models.SmartFeature{
+		ID:            uuid.New(),
+		ModelID:       uuid.New(),
+		Name:          "Power",
+		Description:   "Power switch",
+		Protocol:      models.RestProtocol,
+		InterfacePath: "/power",
+		Parameters:    map[string]interface{}{},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	ids := []string{feature.ID.String(), uuid.NewString()}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE id = ANY($1::uuid[])`)).
+		WithArgs(ids).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "model_id", "name", "description", "protocol", "interface_path", "parameters", "created_at", "updated_at"}).
+			AddRow(feature.ID, feature.ModelID, feature.Name, feature.Description, feature.Protocol, feature.InterfacePath, feature.Parameters, feature.CreatedAt, feature.UpdatedAt))
+
+	features, err := repo.GetByIDs(context.Background(), ids)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.SmartFeature{feature}, features)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSmartFeatureRepository_GetWithModelIDs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSmartFeatureRepository(&mockFeatureDB{mock})
+
+	now := time.Now()
+	feature := &models.SmartFeature{
+		ID:            uuid.New(),
+		ModelID:       uuid.New(),
+		Name:          "Stream",
+		Description:   "Video stream",
+		Protocol:      models.WebsocketProtocol,
+		InterfacePath: "/stream",
+		Parameters:    map[string]interface{}{},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	modelIDs := []string{feature.ModelID.String(), uuid.NewString()}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE model_id = ANY($1::uuid[])`)).
+		WithArgs(modelIDs).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "model_id", "name", "description", "protocol", "interface_path", "parameters", "created_at", "updated_at"}).
+			AddRow(feature.ID, feature.ModelID, feature.Name, feature.Description, feature.Protocol, feature.InterfacePath, feature.Parameters, feature.CreatedAt, feature.UpdatedAt))
+
+	features, err := repo.GetWithModelIDs(context.Background(), modelIDs)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.SmartFeature{feature}, features)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSmartFeatureRepository_List(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSmartFeatureRepository(&mockFeatureDB{mock})
+
+	protocol := models.MqttProtocol
+	category := models.CameraCategory
+	after := &models.PageCursor{CreatedAt: time.Now(), ID: uuid.New()}
+	filter := models.FeatureFilter{
+		Protocol:             &protocol,
+		ModelCategory:        &category,
+		NamePrefix:           "Str",
+		InterfacePathPattern: "/video_*",
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE protocol = $1 AND model_id IN (SELECT id FROM smart_models WHERE category = $2) AND starts_with(name, $3) AND interface_path LIKE $4 ESCAPE '\' AND (created_at, id) > ($5, $6)`)).
+		WithArgs(protocol, category, "Str", `/video\_%`, after.CreatedAt, after.ID, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "model_id", "name", "description", "protocol", "interface_path", "parameters", "created_at", "updated_at"}))
+
+	features, err := repo.List(context.Background(), filter, after, 3)
+	require.NoError(t, err)
+	assert.Empty(t, features)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSmartFeatureRepository_Update_AlreadyExists(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSmartFeatureRepository(&mockFeatureDB{mock})
+
+	feature := &models.SmartFeature{ID: uuid.New(), Name: "Reboot", InterfacePath: "/reboot"}
+	conflictID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::text[])`)).
+		WithArgs([]string{feature.ID.String()}, []string{uuid.Nil.String()}, []string{"Reboot"}, []string{"/reboot"}).
+		WillReturnRows(pgxmock.NewRows([]string{"ord", "id"}).AddRow(1, conflictID))
+
+	result, err := repo.Update(context.Background(), feature)
+	assert.Nil(t, result)
+
+	var conflict *models.AlreadyExistsError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, conflictID, conflict.ID)
+	assert.Equal(t, models.SmartFeatureAggregate, conflict.Entity)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSmartFeatureRepository_CreateBatch_AlreadyExists(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSmartFeatureRepository(&mockFeatureDB{mock})
+
+	modelID := uuid.New()
+	features := []*models.SmartFeature{
+		{ID: uuid.New(), ModelID: modelID, Name: "Power", InterfacePath: "/power"},
+		{ID: uuid.New(), ModelID: modelID, Name: "Reboot", InterfacePath: "/reboot"},
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::text[])`)).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"ord", "id"}).AddRow(2, uuid.New()))
+
+	_, err = repo.CreateBatch(context.Background(), features)
+	assert.ErrorIs(t, err, models.ErrAlreadyExists)
+	var itemErr *models.BatchItemError
+	require.ErrorAs(t, err, &itemErr)
+	assert.Equal(t, 1, itemErr.Index)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func expectNoNamePathConflict(mock pgxmock.PgxPoolIface) {
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::text[])`)).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"ord", "id"}))
+}
@@ -2,28 +2,37 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"smart-hub/internal/common/database"
 	"smart-hub/internal/domain/models"
 )
 
 type PGSmartModelRepository struct {
-	db database.PgxPool
+	db     database.PgxPool
+	reader database.PgxPool
 }
 
 func NewPGSmartModelRepository(db database.Database) *PGSmartModelRepository {
 	return &PGSmartModelRepository{
-		db: db.GetPool(),
+		db:     db.GetPool(),
+		reader: db.GetReadPool(),
 	}
 }
 
 func (r *PGSmartModelRepository) Create(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error) {
+	if err := r.checkModelNumber(ctx, model); err != nil {
+		return nil, err
+	}
+
 	query := `
 		INSERT INTO smart_models (id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at
 	`
 
-	row := r.db.QueryRow(ctx, query, model.ID, model.Name, model.Description, model.Type, model.Category, model.Manufacturer, model.ModelNumber, model.Metadata, model.CreatedAt, model.UpdatedAt)
+	row := database.Conn(ctx, r.db).QueryRow(ctx, query, model.ID, model.Name, model.Description, model.Type, model.Category, model.Manufacturer, model.ModelNumber, model.Metadata, model.CreatedAt, model.UpdatedAt)
 
 	var createdModel models.SmartModel
 	err := row.Scan(
@@ -39,7 +48,7 @@ func (r *PGSmartModelRepository) Create(ctx context.Context, model *models.Smart
 		&createdModel.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 
 	return &createdModel, nil
@@ -52,7 +61,7 @@ func (r *PGSmartModelRepository) GetByID(ctx context.Context, id string) (*model
   WHERE id = $1
  `
 
-	row := r.db.QueryRow(ctx, query, id)
+	row := database.Conn(ctx, r.reader).QueryRow(ctx, query, id)
 
 	var model models.SmartModel
 	err := row.Scan(
@@ -67,9 +76,78 @@ func (r *PGSmartModelRepository) GetByID(ctx context.Context, id string) (*model
 		&model.CreatedAt,
 		&model.UpdatedAt,
 	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return &model, nil
+}
+
+func (r *PGSmartModelRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.SmartModel, error) {
+	query := `
+	  SELECT id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at
+	  FROM smart_models
+	  WHERE id = ANY($1::uuid[])
+	  ORDER BY created_at, id
+	`
+
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, ids)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	var smartModels []*models.SmartModel
+	for rows.Next() {
+		var model models.SmartModel
+		err = rows.Scan(
+			&model.ID,
+			&model.Name,
+			&model.Description,
+			&model.Type,
+			&model.Category,
+			&model.Manufacturer,
+			&model.ModelNumber,
+			&model.Metadata,
+			&model.CreatedAt,
+			&model.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		smartModels = append(smartModels, &model)
+	}
+
+	return smartModels, rows.Err()
+}
+
+func (r *PGSmartModelRepository) GetByModelNumber(ctx context.Context, manufacturer, modelNumber string) (*models.SmartModel, error) {
+	query := `
+		SELECT id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at
+		FROM smart_models
+		WHERE lower(manufacturer) = lower($1) AND lower(model_number) = lower($2)
+		ORDER BY created_at, id
+		LIMIT 1
+	`
+
+	row := database.Conn(ctx, r.reader).QueryRow(ctx, query, manufacturer, modelNumber)
+
+	var model models.SmartModel
+	err := row.Scan(
+		&model.ID,
+		&model.Name,
+		&model.Description,
+		&model.Type,
+		&model.Category,
+		&model.Manufacturer,
+		&model.ModelNumber,
+		&model.Metadata,
+		&model.CreatedAt,
+		&model.UpdatedAt,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
 
 	return &model, nil
 }
@@ -79,12 +157,14 @@ func (r *PGSmartModelRepository) GetWithType(ctx context.Context, modelType mode
 	  SELECT id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at
 	  FROM smart_models
 	  WHERE type = $1
+	  ORDER BY created_at, id
 	`
 
-	rows, err := r.db.Query(ctx, query, modelType)
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query, modelType)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var smartModels []*models.SmartModel
 	for rows.Next() {
@@ -107,19 +187,21 @@ func (r *PGSmartModelRepository) GetWithType(ctx context.Context, modelType mode
 		smartModels = append(smartModels, &model)
 	}
 
-	return smartModels, nil
+	return smartModels, rows.Err()
 }
 
 func (r *PGSmartModelRepository) GetAll(ctx context.Context) ([]*models.SmartModel, error) {
 	query := `
 	  SELECT id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at
 	  FROM smart_models
+	  ORDER BY created_at, id
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var smartModels []*models.SmartModel
 	for rows.Next() {
@@ -142,7 +224,7 @@ func (r *PGSmartModelRepository) GetAll(ctx context.Context) ([]*models.SmartMod
 		smartModels = append(smartModels, &model)
 	}
 
-	return smartModels, nil
+	return smartModels, rows.Err()
 }
 
 func (r *PGSmartModelRepository) Update(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error) {
@@ -155,9 +237,13 @@ func (r *PGSmartModelRepository) Update(ctx context.Context, model *models.Smart
                   metadata, created_at, updated_at
     `
 
+	if err := r.checkModelNumber(ctx, model); err != nil {
+		return nil, err
+	}
+
 	updatedModel := models.SmartModel{}
 
-	err := r.db.QueryRow(ctx, query,
+	err := database.Conn(ctx, r.db).QueryRow(ctx, query,
 		model.ID,
 		model.Name,
 		model.Description,
@@ -181,12 +267,38 @@ func (r *PGSmartModelRepository) Update(ctx context.Context, model *models.Smart
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 
 	return &updatedModel, nil
 }
 
+// checkModelNumber looks for another model with the manufacturer and model
+// number of model, to report which one it is. A unique violation aborts the
+// surrounding transaction, so the conflict cannot be looked up afterwards;
+// the index still catches concurrent writes, without the conflicting ID.
+func (r *PGSmartModelRepository) checkModelNumber(ctx context.Context, model *models.SmartModel) error {
+	if model.ModelNumber == "" {
+		return nil
+	}
+
+	query := `
+		SELECT id FROM smart_models
+		WHERE lower(manufacturer) = lower($1) AND lower(model_number) = lower($2) AND model_number <> '' AND id <> $3
+		LIMIT 1
+	`
+
+	var conflictID uuid.UUID
+	err := database.Conn(ctx, r.db).QueryRow(ctx, query, model.Manufacturer, model.ModelNumber, model.ID).Scan(&conflictID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return &models.AlreadyExistsError{Entity: models.SmartModelAggregate, ID: conflictID}
+}
+
 func (r *PGSmartModelRepository) Delete(ctx context.Context, id string) error {
 	// TODO: Implement soft delete
 
@@ -195,10 +307,13 @@ func (r *PGSmartModelRepository) Delete(ctx context.Context, id string) error {
 		WHERE id = $1
 	`
 
-	_, err := r.db.Exec(ctx, query, id)
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
 
 	return nil
 }
@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,6 +30,10 @@ func (m *mockModelDB) GetPool() database.PgxPool {
 	return m
 }
 
+func (m *mockModelDB) GetReadPool() database.PgxPool {
+	return m
+}
+
 func TestPGSmartModelRepository_Create(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -62,6 +68,8 @@ func TestPGSmartModelRepository_Create(t *testing.T) {
 
 	const expectedSQL = `INSERT INTO smart_models (id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at`
 
+	expectNoModelNumberConflict(mock)
+
 	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
 		WithArgs(
 			model.ID, model.Name, model.Description, model.Type, model.Category,
@@ -127,6 +135,53 @@ func TestPGSmartModelRepository_GetByID(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestPGSmartModelRepository_GetByModelNumber(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGSmartModelRepository(db)
+
+	ctx := context.Background()
+	now := time.Now()
+	model := &models.SmartModel{
+		ID:           uuid.New(),
+		Name:         "Test Model",
+		Description:  "Test Description",
+		Type:         models.DeviceType,
+		Category:     models.WearableCategory,
+		Manufacturer: "Test Manufacturer",
+		ModelNumber:  "TEST123",
+		Metadata:     map[string]interface{}{"key": "value"},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	rows := pgxmock.NewRows([]string{
+		"id", "name", "description", "type", "category",
+		"manufacturer", "model_number", "metadata", "created_at", "updated_at",
+	}).AddRow(
+		model.ID, model.Name, model.Description, model.Type, model.Category,
+		model.Manufacturer, model.ModelNumber, model.Metadata,
+		model.CreatedAt, model.UpdatedAt,
+	)
+
+	const expectedSQL = `SELECT id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at FROM smart_models WHERE lower(manufacturer) = lower($1) AND lower(model_number) = lower($2)`
+
+	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
+		WithArgs(model.Manufacturer, model.ModelNumber).
+		WillReturnRows(rows)
+
+	result, err := repo.GetByModelNumber(ctx, model.Manufacturer, model.ModelNumber)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ID, result.ID)
+	assert.Equal(t, model.ModelNumber, result.ModelNumber)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
 func TestPGSmartModelRepository_GetWithType(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -294,6 +349,8 @@ func TestPGSmartModelRepository_Update(t *testing.T) {
 
 	const expectedSQL = `UPDATE smart_models SET name = $2, description = $3, type = $4, category = $5, manufacturer = $6, model_number = $7, metadata = $8, updated_at = $9 WHERE id = $1 RETURNING id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at`
 
+	expectNoModelNumberConflict(mock)
+
 	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
 		WithArgs(
 			model.ID, model.Name, model.Description, model.Type, model.Category,
@@ -335,6 +392,66 @@ func TestPGSmartModelRepository_Delete(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// replicaModelDB sends reads to a pool of their own, as a database with read
+// replicas does.
+type replicaModelDB struct {
+	*mockModelDB
+	reader pgxmock.PgxPoolIface
+}
+
+func (m *replicaModelDB) GetReadPool() database.PgxPool {
+	return &mockModelDB{m.reader}
+}
+
+func TestPGSmartModelRepository_ReadPool(t *testing.T) {
+	primary, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer primary.Close()
+	reader, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer reader.Close()
+
+	repo := NewPGSmartModelRepository(&replicaModelDB{mockModelDB: &mockModelDB{primary}, reader: reader})
+	ctx := context.Background()
+	modelID := uuid.New()
+	columns := []string{
+		"id", "name", "description", "type", "category",
+		"manufacturer", "model_number", "metadata", "created_at", "updated_at",
+	}
+	const selectSQL = `SELECT id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at FROM smart_models WHERE id = $1`
+
+	// Reads go to the read pool, writes to the primary.
+	reader.ExpectQuery(regexp.QuoteMeta(selectSQL)).
+		WithArgs(modelID.String()).
+		WillReturnRows(pgxmock.NewRows(columns).AddRow(
+			modelID, "Model", "Description", models.DeviceType, models.WearableCategory,
+			"", "", map[string]interface{}{}, time.Now(), time.Now(),
+		))
+	primary.ExpectExec(regexp.QuoteMeta(`DELETE FROM smart_models WHERE id = $1`)).
+		WithArgs(modelID.String()).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	_, err = repo.GetByID(ctx, modelID.String())
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, modelID.String()))
+
+	// Reads inside a transaction stay on the primary.
+	primary.ExpectBegin()
+	primary.ExpectQuery(regexp.QuoteMeta(selectSQL)).
+		WithArgs(modelID.String()).
+		WillReturnRows(pgxmock.NewRows(columns))
+	primary.ExpectRollback()
+
+	tx, err := primary.Begin(ctx)
+	require.NoError(t, err)
+	_, err = repo.GetByID(database.ContextWithTx(ctx, tx), modelID.String())
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	require.NoError(t, tx.Rollback(ctx))
+
+	assert.NoError(t, primary.ExpectationsWereMet())
+	assert.NoError(t, reader.ExpectationsWereMet())
+}
+
 func TestPGSmartModelRepository_Create_Failed(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -360,6 +477,8 @@ func TestPGSmartModelRepository_Create_Failed(t *testing.T) {
 
 	const expectedSQL = `INSERT INTO smart_models (id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at`
 
+	expectNoModelNumberConflict(mock)
+
 	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
 		WithArgs(
 			model.ID, model.Name, model.Description, model.Type, model.Category,
@@ -401,6 +520,30 @@ func TestPGSmartModelRepository_GetByID_Failed(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestPGSmartModelRepository_GetByModelNumber_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGSmartModelRepository(db)
+
+	ctx := context.Background()
+
+	const expectedSQL = `SELECT id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at FROM smart_models WHERE lower(manufacturer) = lower($1) AND lower(model_number) = lower($2)`
+
+	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
+		WithArgs("Acme", "AC100").
+		WillReturnError(pgx.ErrNoRows)
+
+	result, err := repo.GetByModelNumber(ctx, "Acme", "AC100")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.Nil(t, result)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
 func TestPGSmartModelRepository_GetWithType_Failed(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -473,6 +616,8 @@ func TestPGSmartModelRepository_Update_Failed(t *testing.T) {
 
 	const expectedSQL = `UPDATE smart_models SET name = $2, description = $3, type = $4, category = $5, manufacturer = $6, model_number = $7, metadata = $8, updated_at = $9 WHERE id = $1 RETURNING id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at`
 
+	expectNoModelNumberConflict(mock)
+
 	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
 		WithArgs(
 			model.ID, model.Name, model.Description, model.Type, model.Category,
@@ -511,3 +656,84 @@ func TestPGSmartModelRepository_Delete_Failed(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestPGSmartModelRepository_Create_AlreadyExists(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSmartModelRepository(&mockModelDB{mock})
+
+	model := &models.SmartModel{
+		ID:           uuid.New(),
+		Name:         "Duplicate",
+		Type:         models.DeviceType,
+		Category:     models.WearableCategory,
+		Manufacturer: "acme",
+		ModelNumber:  "th200",
+	}
+	conflictID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM smart_models WHERE lower(manufacturer) = lower($1) AND lower(model_number) = lower($2)`)).
+		WithArgs(model.Manufacturer, model.ModelNumber, model.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(conflictID))
+
+	result, err := repo.Create(context.Background(), model)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, models.ErrAlreadyExists)
+
+	var conflict *models.AlreadyExistsError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, conflictID, conflict.ID)
+	assert.Equal(t, models.SmartModelAggregate, conflict.Entity)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSmartModelRepository_Create_UniqueViolation(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSmartModelRepository(&mockModelDB{mock})
+
+	model := &models.SmartModel{ID: uuid.New(), Name: "Raced", Manufacturer: "Acme", ModelNumber: "TH200"}
+
+	expectNoModelNumberConflict(mock)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO smart_models`)).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation, ConstraintName: "uq_smart_models_model_number"})
+
+	_, err = repo.Create(context.Background(), model)
+	assert.ErrorIs(t, err, models.ErrAlreadyExists)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSmartModelRepository_Create_DuplicatePrimaryKey(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGSmartModelRepository(&mockModelDB{mock})
+
+	model := &models.SmartModel{ID: uuid.New(), Name: "Twice"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO smart_models`)).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation, ConstraintName: "smart_models_pkey"})
+
+	_, err = repo.Create(context.Background(), model)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, models.ErrAlreadyExists))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func expectNoModelNumberConflict(mock pgxmock.PgxPoolIface) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM smart_models WHERE lower(manufacturer) = lower($1)`)).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}))
+}
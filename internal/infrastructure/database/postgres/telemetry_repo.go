@@ -0,0 +1,336 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// checkViolation is also raised for a row that no partition accepts.
+	checkViolation = "23514"
+	duplicateTable = "42P07"
+
+	readingPartitionPrefix = "telemetry_readings_"
+	readingPartitionLayout = "20060102"
+	readingPartitionWidth  = 24 * time.Hour
+)
+
+const insertReadingsSQL = `
+	INSERT INTO telemetry_readings (device_id, feature_id, recorded_at, value)
+	SELECT * FROM unnest($1::text[], $2::uuid[], $3::timestamptz[], $4::float8[])
+	ON CONFLICT DO NOTHING
+`
+
+const listReadingPartitionsSQL = `
+	SELECT c.relname
+	FROM pg_inherits i
+	JOIN pg_class c ON c.oid = i.inhrelid
+	WHERE i.inhparent = 'telemetry_readings'::regclass
+`
+
+const retentionPolicyColumns = `feature_id, retention_seconds, updated_at`
+
+// PGTelemetryRepository stores readings in daily partitions of
+// telemetry_readings. Partitions are created on the first write to a day and
+// dropped whole when a delete without feature filters covers them.
+type PGTelemetryRepository struct {
+	db     database.PgxPool
+	reader database.PgxPool
+
+	mu sync.Mutex
+	// partitions are the days known to have a partition.
+	partitions map[time.Time]bool
+}
+
+func NewPGTelemetryRepository(db database.Database) *PGTelemetryRepository {
+	return &PGTelemetryRepository{
+		db:         db.GetPool(),
+		reader:     db.GetReadPool(),
+		partitions: make(map[time.Time]bool),
+	}
+}
+
+// AddReadings inserts all readings with one statement. When another replica
+// dropped a partition this one still knew of, the insert fails; the
+// partitions are then created again and the insert retried once.
+func (r *PGTelemetryRepository) AddReadings(ctx context.Context, readings []*models.TelemetryReading) (int, error) {
+	if len(readings) == 0 {
+		return 0, nil
+	}
+	if err := r.ensurePartitions(ctx, readings); err != nil {
+		return 0, err
+	}
+
+	stored, err := r.insertReadings(ctx, readings)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == checkViolation {
+		r.forgetPartitions()
+		if err := r.ensurePartitions(ctx, readings); err != nil {
+			return 0, err
+		}
+		stored, err = r.insertReadings(ctx, readings)
+	}
+	return stored, err
+}
+
+func (r *PGTelemetryRepository) insertReadings(ctx context.Context, readings []*models.TelemetryReading) (int, error) {
+	deviceIDs := make([]string, len(readings))
+	featureIDs := make([]string, len(readings))
+	timestamps := make([]time.Time, len(readings))
+	values := make([]float64, len(readings))
+	for i, reading := range readings {
+		deviceIDs[i] = reading.DeviceID
+		featureIDs[i] = reading.FeatureID.String()
+		timestamps[i] = reading.Timestamp
+		values[i] = reading.Value
+	}
+
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, insertReadingsSQL, deviceIDs, featureIDs, timestamps, values)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ensurePartitions creates the partitions for the days of readings that are
+// not known to exist. Replicas may race to create the same partition; the
+// loser's error is ignored.
+func (r *PGTelemetryRepository) ensurePartitions(ctx context.Context, readings []*models.TelemetryReading) error {
+	r.mu.Lock()
+	var missing []time.Time
+	for _, reading := range readings {
+		day := reading.Timestamp.UTC().Truncate(readingPartitionWidth)
+		if !r.partitions[day] && !containsTime(missing, day) {
+			missing = append(missing, day)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, day := range missing {
+		query := fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s PARTITION OF telemetry_readings FOR VALUES FROM ('%s') TO ('%s')`,
+			readingPartitionName(day),
+			day.Format(time.RFC3339),
+			day.Add(readingPartitionWidth).Format(time.RFC3339),
+		)
+		_, err := database.Conn(ctx, r.db).Exec(ctx, query)
+		var pgErr *pgconn.PgError
+		if err != nil && !(errors.As(err, &pgErr) && (pgErr.Code == duplicateTable || pgErr.Code == uniqueViolation)) {
+			return fmt.Errorf("create telemetry partition for %s: %w", day.Format(time.DateOnly), err)
+		}
+
+		r.mu.Lock()
+		r.partitions[day] = true
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+func (r *PGTelemetryRepository) forgetPartitions() {
+	r.mu.Lock()
+	r.partitions = make(map[time.Time]bool)
+	r.mu.Unlock()
+}
+
+func readingPartitionName(day time.Time) string {
+	return readingPartitionPrefix + day.Format(readingPartitionLayout)
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, other := range times {
+		if other.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// readingConditions returns the WHERE clause of query with its arguments
+// numbered from 1.
+func readingConditions(query models.ReadingQuery) (string, []interface{}) {
+	args := []interface{}{query.FeatureID, query.From, query.To}
+	conditions := "feature_id = $1 AND recorded_at >= $2 AND recorded_at < $3"
+	if query.DeviceID != "" {
+		args = append(args, query.DeviceID)
+		conditions += " AND device_id = $4"
+	}
+	return conditions, args
+}
+
+func (r *PGTelemetryRepository) ListReadings(ctx context.Context, query models.ReadingQuery) ([]*models.TelemetryReading, error) {
+	conditions, args := readingConditions(query)
+	args = append(args, query.Limit)
+	sql := fmt.Sprintf(`
+		SELECT device_id, feature_id, recorded_at, value
+		FROM telemetry_readings
+		WHERE %s
+		ORDER BY recorded_at, device_id
+		LIMIT $%d`, conditions, len(args))
+
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var readings []*models.TelemetryReading
+	for rows.Next() {
+		var reading models.TelemetryReading
+		if err := rows.Scan(&reading.DeviceID, &reading.FeatureID, &reading.Timestamp, &reading.Value); err != nil {
+			return nil, err
+		}
+		readings = append(readings, &reading)
+	}
+	return readings, rows.Err()
+}
+
+func (r *PGTelemetryRepository) Downsample(ctx context.Context, query models.ReadingQuery, bucket time.Duration) ([]*models.TelemetryBucket, error) {
+	conditions, args := readingConditions(query)
+	args = append(args, int64(bucket/time.Second), query.Limit)
+	sql := fmt.Sprintf(`
+		SELECT to_timestamp(floor(extract(epoch FROM recorded_at) / $%[2]d) * $%[2]d) AS bucket_start,
+			count(*), avg(value), min(value), max(value)
+		FROM telemetry_readings
+		WHERE %[1]s
+		GROUP BY bucket_start
+		ORDER BY bucket_start
+		LIMIT $%[3]d`, conditions, len(args)-1, len(args))
+
+	rows, err := database.Conn(ctx, r.reader).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []*models.TelemetryBucket
+	for rows.Next() {
+		var b models.TelemetryBucket
+		if err := rows.Scan(&b.Start, &b.Count, &b.Avg, &b.Min, &b.Max); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, &b)
+	}
+	return buckets, rows.Err()
+}
+
+// DeleteReadings drops the partitions that lie wholly before filter.Before
+// when the filter does not name features, and deletes the remaining rows.
+func (r *PGTelemetryRepository) DeleteReadings(ctx context.Context, filter models.ReadingDeleteFilter) error {
+	if filter.FeatureID == nil && len(filter.ExceptFeatureIDs) == 0 {
+		if err := r.dropPartitionsBefore(ctx, filter.Before); err != nil {
+			return err
+		}
+	}
+
+	args := []interface{}{filter.Before}
+	query := `DELETE FROM telemetry_readings WHERE recorded_at < $1`
+	if filter.FeatureID != nil {
+		args = append(args, *filter.FeatureID)
+		query += fmt.Sprintf(" AND feature_id = $%d", len(args))
+	}
+	if len(filter.ExceptFeatureIDs) > 0 {
+		except := make([]string, len(filter.ExceptFeatureIDs))
+		for i, id := range filter.ExceptFeatureIDs {
+			except[i] = id.String()
+		}
+		args = append(args, except)
+		query += fmt.Sprintf(" AND feature_id <> ALL($%d::uuid[])", len(args))
+	}
+
+	_, err := database.Conn(ctx, r.db).Exec(ctx, query, args...)
+	return err
+}
+
+func (r *PGTelemetryRepository) dropPartitionsBefore(ctx context.Context, before time.Time) error {
+	rows, err := database.Conn(ctx, r.db).Query(ctx, listReadingPartitionsSQL)
+	if err != nil {
+		return err
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		day, err := time.Parse(readingPartitionLayout, strings.TrimPrefix(name, readingPartitionPrefix))
+		if err != nil || day.Add(readingPartitionWidth).After(before) {
+			continue
+		}
+
+		r.mu.Lock()
+		delete(r.partitions, day)
+		r.mu.Unlock()
+
+		if _, err := database.Conn(ctx, r.db).Exec(ctx, "DROP TABLE IF EXISTS "+pgx.Identifier{name}.Sanitize()); err != nil {
+			return err
+		}
+		logger.FromContext(ctx).Info("Dropped telemetry partition", "partition", name)
+	}
+	return nil
+}
+
+func (r *PGTelemetryRepository) SetRetentionPolicy(ctx context.Context, policy *models.RetentionPolicy) (*models.RetentionPolicy, error) {
+	query := `
+		INSERT INTO telemetry_retention_policies (feature_id, retention_seconds, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (feature_id) DO UPDATE SET retention_seconds = EXCLUDED.retention_seconds, updated_at = EXCLUDED.updated_at
+		RETURNING ` + retentionPolicyColumns
+
+	row := database.Conn(ctx, r.db).QueryRow(ctx, query, policy.FeatureID, int64(policy.Retention/time.Second), policy.UpdatedAt)
+	stored, err := scanRetentionPolicy(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return stored, nil
+}
+
+func (r *PGTelemetryRepository) ListRetentionPolicies(ctx context.Context) ([]*models.RetentionPolicy, error) {
+	query := `SELECT ` + retentionPolicyColumns + ` FROM telemetry_retention_policies ORDER BY feature_id`
+
+	rows, err := database.Conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []*models.RetentionPolicy
+	for rows.Next() {
+		policy, err := scanRetentionPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+func (r *PGTelemetryRepository) DeleteRetentionPolicy(ctx context.Context, featureID uuid.UUID) error {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM telemetry_retention_policies WHERE feature_id = $1`, featureID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func scanRetentionPolicy(row pgx.Row) (*models.RetentionPolicy, error) {
+	var policy models.RetentionPolicy
+	var seconds int64
+	if err := row.Scan(&policy.FeatureID, &seconds, &policy.UpdatedAt); err != nil {
+		return nil, err
+	}
+	policy.Retention = time.Duration(seconds) * time.Second
+	return &policy, nil
+}
@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+const createMarchFirstPartitionSQL = `CREATE TABLE IF NOT EXISTS telemetry_readings_20240301 PARTITION OF telemetry_readings FOR VALUES FROM ('2024-03-01T00:00:00Z') TO ('2024-03-02T00:00:00Z')`
+
+func TestPGTelemetryRepository_AddReadings_CreatesPartitionOnce(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGTelemetryRepository(&mockModelDB{mock})
+
+	featureID := uuid.New()
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	readings := []*models.TelemetryReading{
+		{DeviceID: "dev-1", FeatureID: featureID, Timestamp: at, Value: 1.5},
+		{DeviceID: "dev-2", FeatureID: featureID, Timestamp: at.Add(time.Hour), Value: 2.5},
+	}
+	args := []interface{}{
+		[]string{"dev-1", "dev-2"},
+		[]string{featureID.String(), featureID.String()},
+		[]time.Time{at, at.Add(time.Hour)},
+		[]float64{1.5, 2.5},
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(createMarchFirstPartitionSQL)).
+		WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectExec(regexp.QuoteMeta(insertReadingsSQL)).
+		WithArgs(args...).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mock.ExpectExec(regexp.QuoteMeta(insertReadingsSQL)).
+		WithArgs(args...).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+	stored, err := repo.AddReadings(context.Background(), readings)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored)
+
+	stored, err = repo.AddReadings(context.Background(), readings)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stored)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGTelemetryRepository_AddReadings_PartitionCreatedConcurrently(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGTelemetryRepository(&mockModelDB{mock})
+
+	featureID := uuid.New()
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(createMarchFirstPartitionSQL)).
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation, ConstraintName: "pg_type_typname_nsp_index"})
+	mock.ExpectExec(regexp.QuoteMeta(insertReadingsSQL)).
+		WithArgs([]string{"dev-1"}, []string{featureID.String()}, []time.Time{at}, []float64{1}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	stored, err := repo.AddReadings(context.Background(), []*models.TelemetryReading{
+		{DeviceID: "dev-1", FeatureID: featureID, Timestamp: at, Value: 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGTelemetryRepository_AddReadings_RecreatesDroppedPartition(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGTelemetryRepository(&mockModelDB{mock})
+
+	featureID := uuid.New()
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo.partitions[at.Truncate(24*time.Hour)] = true
+	args := []interface{}{[]string{"dev-1"}, []string{featureID.String()}, []time.Time{at}, []float64{1}}
+
+	mock.ExpectExec(regexp.QuoteMeta(insertReadingsSQL)).
+		WithArgs(args...).
+		WillReturnError(&pgconn.PgError{Code: checkViolation})
+	mock.ExpectExec(regexp.QuoteMeta(createMarchFirstPartitionSQL)).
+		WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectExec(regexp.QuoteMeta(insertReadingsSQL)).
+		WithArgs(args...).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	stored, err := repo.AddReadings(context.Background(), []*models.TelemetryReading{
+		{DeviceID: "dev-1", FeatureID: featureID, Timestamp: at, Value: 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGTelemetryRepository_Downsample(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGTelemetryRepository(&mockModelDB{mock})
+
+	featureID := uuid.New()
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT to_timestamp(floor(extract(epoch FROM recorded_at) / $5) * $5) AS bucket_start`)).
+		WithArgs(featureID, from, to, "dev-1", int64(300), 10).
+		WillReturnRows(pgxmock.NewRows([]string{"bucket_start", "count", "avg", "min", "max"}).
+			AddRow(from, int64(2), 1.5, 1.0, 2.0))
+
+	buckets, err := repo.Downsample(context.Background(), models.ReadingQuery{
+		FeatureID: featureID,
+		DeviceID:  "dev-1",
+		From:      from,
+		To:        to,
+		Limit:     10,
+	}, 5*time.Minute)
+
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	assert.Equal(t, &models.TelemetryBucket{Start: from, Count: 2, Avg: 1.5, Min: 1, Max: 2}, buckets[0])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGTelemetryRepository_DeleteReadings_DropsExpiredPartitions(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGTelemetryRepository(&mockModelDB{mock})
+
+	before := time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC)
+	repo.partitions[time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)] = true
+
+	mock.ExpectQuery(regexp.QuoteMeta(listReadingPartitionsSQL)).
+		WillReturnRows(pgxmock.NewRows([]string{"relname"}).
+			AddRow("telemetry_readings_20240301").
+			AddRow("telemetry_readings_20240302").
+			AddRow("telemetry_readings_default"))
+	mock.ExpectExec(regexp.QuoteMeta(`DROP TABLE IF EXISTS "telemetry_readings_20240301"`)).
+		WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM telemetry_readings WHERE recorded_at < $1`)).
+		WithArgs(before).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+	err = repo.DeleteReadings(context.Background(), models.ReadingDeleteFilter{Before: before})
+
+	require.NoError(t, err)
+	assert.Empty(t, repo.partitions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGTelemetryRepository_DeleteReadings_KeepsPartitionsForFeatureFilters(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGTelemetryRepository(&mockModelDB{mock})
+
+	before := time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC)
+	featureID, kept := uuid.New(), uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM telemetry_readings WHERE recorded_at < $1 AND feature_id = $2 AND feature_id <> ALL($3::uuid[])`)).
+		WithArgs(before, featureID, []string{kept.String()}).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	err = repo.DeleteReadings(context.Background(), models.ReadingDeleteFilter{
+		Before:           before,
+		FeatureID:        &featureID,
+		ExceptFeatureIDs: []uuid.UUID{kept},
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGTelemetryRepository_SetRetentionPolicy_MissingFeature(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPGTelemetryRepository(&mockModelDB{mock})
+
+	policy := &models.RetentionPolicy{FeatureID: uuid.New(), Retention: 48 * time.Hour, UpdatedAt: time.Now()}
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO telemetry_retention_policies`)).
+		WithArgs(policy.FeatureID, int64(172800), policy.UpdatedAt).
+		WillReturnError(&pgconn.PgError{Code: foreignKeyViolation})
+
+	result, err := repo.SetRetentionPolicy(context.Background(), policy)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
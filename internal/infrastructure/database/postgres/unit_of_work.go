@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"smart-hub/internal/common/database"
+)
+
+type PGUnitOfWork struct {
+	db database.PgxPool
+}
+
+func NewPGUnitOfWork(db database.Database) *PGUnitOfWork {
+	return &PGUnitOfWork{
+		db: db.GetPool(),
+	}
+}
+
+func (u *PGUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := database.TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(database.ContextWithTx(ctx, tx)); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"testing"
+)
+
+func TestPGUnitOfWork_Commit(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	uow := NewPGUnitOfWork(&mockModelDB{mock})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM smart_models WHERE id = $1`)).
+		WithArgs("id").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+
+	repo := NewPGSmartModelRepository(&mockModelDB{mock})
+	err = uow.Do(context.Background(), func(ctx context.Context) error {
+		return repo.Delete(ctx, "id")
+	})
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestPGUnitOfWork_Rollback(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	uow := NewPGUnitOfWork(&mockModelDB{mock})
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err = uow.Do(context.Background(), func(ctx context.Context) error {
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestPGUnitOfWork_Nested(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	uow := NewPGUnitOfWork(&mockModelDB{mock})
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	err = uow.Do(context.Background(), func(ctx context.Context) error {
+		return uow.Do(ctx, func(ctx context.Context) error {
+			return nil
+		})
+	})
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
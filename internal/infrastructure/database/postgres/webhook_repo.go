@@ -0,0 +1,282 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+const webhookSubscriptionColumns = `id, url, event_types, secret, COALESCE(description, ''), active, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, COALESCE(last_status_code, 0), COALESCE(last_error, ''), next_attempt_at, delivered_at, created_at`
+
+type PGWebhookRepository struct {
+	db database.PgxPool
+}
+
+func NewPGWebhookRepository(db database.Database) *PGWebhookRepository {
+	return &PGWebhookRepository{
+		db: db.GetPool(),
+	}
+}
+
+func (r *PGWebhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (id, url, event_types, secret, description, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + webhookSubscriptionColumns
+
+	row := database.Conn(ctx, r.db).QueryRow(ctx, query,
+		subscription.ID,
+		subscription.URL,
+		eventTypesToStrings(subscription.EventTypes),
+		subscription.Secret,
+		subscription.Description,
+		subscription.Active,
+		subscription.CreatedAt,
+		subscription.UpdatedAt,
+	)
+	return scanWebhookSubscription(row)
+}
+
+func (r *PGWebhookRepository) GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	return scanWebhookSubscription(database.Conn(ctx, r.db).QueryRow(ctx, query, id))
+}
+
+func (r *PGWebhookRepository) ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions ORDER BY created_at`
+
+	rows, err := database.Conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*models.WebhookSubscription
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (r *PGWebhookRepository) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $2, event_types = $3, description = $4, active = $5, updated_at = $6
+		WHERE id = $1
+		RETURNING ` + webhookSubscriptionColumns
+
+	row := database.Conn(ctx, r.db).QueryRow(ctx, query,
+		subscription.ID,
+		subscription.URL,
+		eventTypesToStrings(subscription.EventTypes),
+		subscription.Description,
+		subscription.Active,
+		subscription.UpdatedAt,
+	)
+	return scanWebhookSubscription(row)
+}
+
+func (r *PGWebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PGWebhookRepository) EnqueueDeliveries(ctx context.Context, deliveries ...*models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`
+
+	conn := database.Conn(ctx, r.db)
+	for _, delivery := range deliveries {
+		_, err := conn.Exec(ctx, query,
+			delivery.ID,
+			delivery.SubscriptionID,
+			delivery.EventID,
+			delivery.EventType,
+			delivery.Payload,
+			delivery.Status,
+			delivery.NextAttemptAt,
+			delivery.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *PGWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, leaseUntil time.Time) ([]*models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id AND s.active
+			WHERE d.status IN ('pending', 'failed') AND d.next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns
+
+	rows, err := database.Conn(ctx, r.db).Query(ctx, query, limit, leaseUntil)
+	if err != nil {
+		return nil, err
+	}
+	return collectWebhookDeliveries(rows)
+}
+
+func (r *PGWebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, last_status_code = NULLIF($4, 0), last_error = NULLIF($5, ''), next_attempt_at = $6, delivered_at = $7
+		WHERE id = $1
+	`
+
+	tag, err := database.Conn(ctx, r.db).Exec(ctx, query,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.DeliveredAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PGWebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	return scanWebhookDelivery(database.Conn(ctx, r.db).QueryRow(ctx, query, id))
+}
+
+func (r *PGWebhookRepository) ListDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.SubscriptionID != nil {
+		args = append(args, *filter.SubscriptionID)
+		conditions = append(conditions, fmt.Sprintf("subscription_id = $%d", len(args)))
+	}
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d`, len(args))
+
+	rows, err := database.Conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectWebhookDeliveries(rows)
+}
+
+func scanWebhookSubscription(row pgx.Row) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	var eventTypes []string
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&eventTypes,
+		&subscription.Secret,
+		&subscription.Description,
+		&subscription.Active,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, eventType := range eventTypes {
+		subscription.EventTypes = append(subscription.EventTypes, models.EventType(eventType))
+	}
+	return &subscription, nil
+}
+
+func scanWebhookDelivery(row pgx.Row) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.NextAttemptAt,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func collectWebhookDeliveries(rows pgx.Rows) ([]*models.WebhookDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func eventTypesToStrings(eventTypes []models.EventType) []string {
+	result := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		result = append(result, string(eventType))
+	}
+	return result
+}
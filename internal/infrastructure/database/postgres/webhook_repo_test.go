@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+var webhookDeliveryRowColumns = []string{
+	"id", "subscription_id", "event_id", "event_type", "payload", "status", "attempts",
+	"last_status_code", "last_error", "next_attempt_at", "delivered_at", "created_at",
+}
+
+func TestPGWebhookRepository_CreateSubscription(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGWebhookRepository(db)
+
+	now := time.Now()
+	subscription := &models.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        "https://example.com/hook",
+		EventTypes: []models.EventType{models.ModelCreatedEvent},
+		Secret:     "0123456789abcdef",
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	const expectedSQL = `INSERT INTO webhook_subscriptions (id, url, event_types, secret, description, active, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING ` + webhookSubscriptionColumns
+
+	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
+		WithArgs(subscription.ID, subscription.URL, []string{"model.created"}, subscription.Secret, "", true, now, now).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "url", "event_types", "secret", "description", "active", "created_at", "updated_at"}).
+			AddRow(subscription.ID, subscription.URL, []string{"model.created"}, subscription.Secret, "", true, now, now))
+
+	result, err := repo.CreateSubscription(context.Background(), subscription)
+
+	require.NoError(t, err)
+	assert.Equal(t, subscription.ID, result.ID)
+	assert.Equal(t, []models.EventType{models.ModelCreatedEvent}, result.EventTypes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGWebhookRepository_GetSubscription_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGWebhookRepository(db)
+
+	id := uuid.New()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnError(pgx.ErrNoRows)
+
+	result, err := repo.GetSubscription(context.Background(), id)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGWebhookRepository_DeleteSubscription_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGWebhookRepository(db)
+
+	id := uuid.New()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM webhook_subscriptions WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	err = repo.DeleteSubscription(context.Background(), id)
+
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGWebhookRepository_EnqueueDeliveries(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGWebhookRepository(db)
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: uuid.New(),
+		EventID:        uuid.New(),
+		EventType:      models.ModelCreatedEvent,
+		Payload:        []byte(`{}`),
+		Status:         models.DeliveryPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}
+
+	const expectedSQL = `INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	mock.ExpectExec(regexp.QuoteMeta(expectedSQL)).
+		WithArgs(delivery.ID, delivery.SubscriptionID, delivery.EventID, delivery.EventType, delivery.Payload, delivery.Status, now, now).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = repo.EnqueueDeliveries(context.Background(), delivery)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGWebhookRepository_ClaimDueDeliveries(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGWebhookRepository(db)
+
+	now := time.Now()
+	leaseUntil := now.Add(time.Minute)
+	deliveryID := uuid.New()
+
+	mock.ExpectQuery(`UPDATE webhook_deliveries SET next_attempt_at = \$2 WHERE id IN \(.*FOR UPDATE OF d SKIP LOCKED \) RETURNING`).
+		WithArgs(10, leaseUntil).
+		WillReturnRows(pgxmock.NewRows(webhookDeliveryRowColumns).
+			AddRow(deliveryID, uuid.New(), uuid.New(), models.ModelCreatedEvent, []byte(`{}`), models.DeliveryFailed, 2, 500, "status 500", leaseUntil, (*time.Time)(nil), now))
+
+	deliveries, err := repo.ClaimDueDeliveries(context.Background(), 10, leaseUntil)
+
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, deliveryID, deliveries[0].ID)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Equal(t, 500, deliveries[0].LastStatusCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGWebhookRepository_ListDeliveries(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	db := &mockModelDB{mock}
+	repo := NewPGWebhookRepository(db)
+
+	subscriptionID := uuid.New()
+	dead := models.DeliveryDead
+
+	const expectedSQL = `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE subscription_id = $1 AND status = $2 ORDER BY created_at DESC LIMIT $3`
+
+	mock.ExpectQuery(regexp.QuoteMeta(expectedSQL)).
+		WithArgs(subscriptionID, dead, 20).
+		WillReturnRows(pgxmock.NewRows(webhookDeliveryRowColumns))
+
+	deliveries, err := repo.ListDeliveries(context.Background(), models.WebhookDeliveryFilter{SubscriptionID: &subscriptionID, Status: &dead, Limit: 20})
+
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,261 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func RunAutomationRepositoryTests(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGetRule", func(t *testing.T) {
+		repos := automationRepositories(t, factory)
+		rule := newRule("Porch light", baseTime)
+		rule.Trigger = models.RuleTrigger{
+			Type:      models.TriggerStateChange,
+			FeatureID: uuid.New(),
+			DeviceID:  "camera-1",
+			Value:     true,
+		}
+
+		created, err := repos.Automations.CreateRule(ctx, rule)
+		require.NoError(t, err)
+		assertRule(t, rule, created)
+
+		fetched, err := repos.Automations.GetRule(ctx, rule.ID)
+		require.NoError(t, err)
+		assertRule(t, rule, fetched)
+		assert.Equal(t, map[string]interface{}{"level": float64(80)}, fetched.Actions[0].Parameters)
+	})
+
+	t.Run("GetRuleNotFound", func(t *testing.T) {
+		repos := automationRepositories(t, factory)
+
+		_, err := repos.Automations.GetRule(ctx, uuid.New())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("ListRulesOrdersByCreatedAt", func(t *testing.T) {
+		repos := automationRepositories(t, factory)
+		later := mustCreateRule(t, repos, newRule("Later", baseTime.Add(time.Minute)))
+		earlier := mustCreateRule(t, repos, newRule("Earlier", baseTime))
+
+		rules, err := repos.Automations.ListRules(ctx)
+		require.NoError(t, err)
+		require.Len(t, rules, 2)
+		assert.Equal(t, earlier.ID, rules[0].ID)
+		assert.Equal(t, later.ID, rules[1].ID)
+	})
+
+	t.Run("UpdateRule", func(t *testing.T) {
+		repos := automationRepositories(t, factory)
+		rule := mustCreateRule(t, repos, newRule("Porch light", baseTime))
+
+		rule.Name = "Porch light at night"
+		rule.Enabled = false
+		rule.Condition = `hour >= 22`
+		rule.Trigger = models.RuleTrigger{Type: models.TriggerSchedule, At: "22:30", Weekdays: []time.Weekday{time.Friday, time.Saturday}}
+		rule.Cooldown = 10 * time.Minute
+		rule.UpdatedAt = baseTime.Add(time.Hour)
+		updated, err := repos.Automations.UpdateRule(ctx, rule)
+		require.NoError(t, err)
+		assertRule(t, rule, updated)
+
+		rule.ID = uuid.New()
+		_, err = repos.Automations.UpdateRule(ctx, rule)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("DeleteRuleRemovesExecutions", func(t *testing.T) {
+		repos := automationRepositories(t, factory)
+		rule := mustCreateRule(t, repos, newRule("Porch light", baseTime))
+		require.NoError(t, repos.Automations.CreateExecution(ctx, newExecution(rule.ID, baseTime)))
+
+		require.NoError(t, repos.Automations.DeleteRule(ctx, rule.ID))
+
+		executions, err := repos.Automations.ListExecutions(ctx, models.ExecutionFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, executions)
+
+		err = repos.Automations.DeleteRule(ctx, rule.ID)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("CreateExecutionChecksRuleAndDedupKey", func(t *testing.T) {
+		repos := automationRepositories(t, factory)
+		rule := mustCreateRule(t, repos, newRule("Porch light", baseTime))
+
+		first := newExecution(rule.ID, baseTime)
+		first.DedupKey = "tick-1"
+		require.NoError(t, repos.Automations.CreateExecution(ctx, first))
+		// Executions without a dedup key never collide.
+		require.NoError(t, repos.Automations.CreateExecution(ctx, newExecution(rule.ID, baseTime)))
+		require.NoError(t, repos.Automations.CreateExecution(ctx, newExecution(rule.ID, baseTime)))
+
+		duplicate := newExecution(rule.ID, baseTime)
+		duplicate.DedupKey = "tick-1"
+		err := repos.Automations.CreateExecution(ctx, duplicate)
+		assert.True(t, errors.Is(err, models.ErrAlreadyExists), "got %v", err)
+
+		err = repos.Automations.CreateExecution(ctx, newExecution(uuid.New(), baseTime))
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("FinishExecution", func(t *testing.T) {
+		repos := automationRepositories(t, factory)
+		rule := mustCreateRule(t, repos, newRule("Porch light", baseTime))
+		execution := newExecution(rule.ID, baseTime)
+		require.NoError(t, repos.Automations.CreateExecution(ctx, execution))
+
+		finishedAt := baseTime.Add(time.Second)
+		execution.Status = models.ExecutionFailed
+		execution.Error = "1 of 2 actions failed"
+		execution.Results = []models.ActionResult{
+			{DeviceID: "light-1", FeatureID: rule.Actions[0].FeatureID},
+			{DeviceID: "light-2", FeatureID: rule.Actions[0].FeatureID, Error: "device offline"},
+		}
+		execution.FinishedAt = &finishedAt
+		require.NoError(t, repos.Automations.FinishExecution(ctx, execution))
+
+		executions, err := repos.Automations.ListExecutions(ctx, models.ExecutionFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, executions, 1)
+		fetched := executions[0]
+		assert.Equal(t, models.ExecutionFailed, fetched.Status)
+		assert.Equal(t, "1 of 2 actions failed", fetched.Error)
+		assert.Equal(t, execution.Results, fetched.Results)
+		assert.Equal(t, map[string]interface{}{"device_id": "camera-1"}, fetched.Trigger)
+		require.NotNil(t, fetched.FinishedAt)
+		assert.True(t, finishedAt.Equal(*fetched.FinishedAt))
+
+		missing := newExecution(rule.ID, baseTime)
+		err = repos.Automations.FinishExecution(ctx, missing)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("ListExecutionsPagesNewestFirst", func(t *testing.T) {
+		repos := automationRepositories(t, factory)
+		rule := mustCreateRule(t, repos, newRule("Porch light", baseTime))
+		other := mustCreateRule(t, repos, newRule("Blinds", baseTime))
+
+		var started []*models.AutomationExecution
+		for i := 0; i < 3; i++ {
+			execution := newExecution(rule.ID, baseTime.Add(time.Duration(i)*time.Minute))
+			require.NoError(t, repos.Automations.CreateExecution(ctx, execution))
+			started = append(started, execution)
+		}
+		require.NoError(t, repos.Automations.CreateExecution(ctx, newExecution(other.ID, baseTime.Add(time.Hour))))
+
+		page, err := repos.Automations.ListExecutions(ctx, models.ExecutionFilter{RuleID: &rule.ID, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, started[2].ID, page[0].ID)
+		assert.Equal(t, started[1].ID, page[1].ID)
+
+		cursor := &models.PageCursor{CreatedAt: page[1].StartedAt, ID: page[1].ID}
+		rest, err := repos.Automations.ListExecutions(ctx, models.ExecutionFilter{RuleID: &rule.ID, After: cursor, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, rest, 1)
+		assert.Equal(t, started[0].ID, rest[0].ID)
+
+		all, err := repos.Automations.ListExecutions(ctx, models.ExecutionFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, all, 4)
+	})
+
+	t.Run("PruneExecutions", func(t *testing.T) {
+		repos := automationRepositories(t, factory)
+		rule := mustCreateRule(t, repos, newRule("Porch light", baseTime))
+		require.NoError(t, repos.Automations.CreateExecution(ctx, newExecution(rule.ID, baseTime)))
+		kept := newExecution(rule.ID, baseTime.Add(time.Hour))
+		require.NoError(t, repos.Automations.CreateExecution(ctx, kept))
+
+		pruned, err := repos.Automations.PruneExecutions(ctx, baseTime.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), pruned)
+
+		executions, err := repos.Automations.ListExecutions(ctx, models.ExecutionFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, executions, 1)
+		assert.Equal(t, kept.ID, executions[0].ID)
+	})
+}
+
+func automationRepositories(t *testing.T, factory Factory) Repositories {
+	t.Helper()
+	repos := factory(t)
+	if repos.Automations == nil {
+		t.Skip("no automation repository")
+	}
+	return repos
+}
+
+func newRule(name string, createdAt time.Time) *models.AutomationRule {
+	return &models.AutomationRule{
+		ID:      uuid.New(),
+		Name:    name,
+		Enabled: true,
+		Trigger: models.RuleTrigger{
+			Type:      models.TriggerTelemetry,
+			FeatureID: uuid.New(),
+			Operator:  models.OperatorGreater,
+			Threshold: 0.5,
+		},
+		Actions: []models.RuleAction{
+			{DeviceID: "light-1", FeatureID: uuid.New(), Parameters: map[string]interface{}{"level": 80}},
+		},
+		Timezone:  "Europe/Berlin",
+		Cooldown:  time.Minute,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func mustCreateRule(t *testing.T, repos Repositories, rule *models.AutomationRule) *models.AutomationRule {
+	t.Helper()
+	created, err := repos.Automations.CreateRule(context.Background(), rule)
+	require.NoError(t, err)
+	return created
+}
+
+func newExecution(ruleID uuid.UUID, startedAt time.Time) *models.AutomationExecution {
+	return &models.AutomationExecution{
+		ID:        uuid.New(),
+		RuleID:    ruleID,
+		Trigger:   map[string]interface{}{"device_id": "camera-1"},
+		Status:    models.ExecutionRunning,
+		StartedAt: startedAt,
+	}
+}
+
+func assertRule(t *testing.T, expected, actual *models.AutomationRule) {
+	t.Helper()
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Description, actual.Description)
+	assert.Equal(t, expected.Enabled, actual.Enabled)
+	assert.Equal(t, expected.Trigger.Type, actual.Trigger.Type)
+	assert.Equal(t, expected.Trigger.FeatureID, actual.Trigger.FeatureID)
+	assert.Equal(t, expected.Trigger.DeviceID, actual.Trigger.DeviceID)
+	assert.Equal(t, expected.Trigger.Operator, actual.Trigger.Operator)
+	assert.Equal(t, expected.Trigger.Threshold, actual.Trigger.Threshold)
+	assert.Equal(t, expected.Trigger.Value, actual.Trigger.Value)
+	assert.Equal(t, expected.Trigger.At, actual.Trigger.At)
+	assert.Equal(t, expected.Trigger.Weekdays, actual.Trigger.Weekdays)
+	assert.Equal(t, expected.Condition, actual.Condition)
+	require.Len(t, actual.Actions, len(expected.Actions))
+	for i := range expected.Actions {
+		assert.Equal(t, expected.Actions[i].DeviceID, actual.Actions[i].DeviceID)
+		assert.Equal(t, expected.Actions[i].FeatureID, actual.Actions[i].FeatureID)
+	}
+	assert.Equal(t, expected.Timezone, actual.Timezone)
+	assert.Equal(t, expected.Cooldown, actual.Cooldown)
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "created_at: %v != %v", expected.CreatedAt, actual.CreatedAt)
+	assert.True(t, expected.UpdatedAt.Equal(actual.UpdatedAt), "updated_at: %v != %v", expected.UpdatedAt, actual.UpdatedAt)
+}
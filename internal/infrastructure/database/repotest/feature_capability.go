@@ -0,0 +1,151 @@
+package repotest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"testing"
+)
+
+func RunFeatureCapabilityRepositoryTests(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	t.Run("SetAndGetFeatureCapability", func(t *testing.T) {
+		repos := capabilityRepositories(t, factory)
+		f := mustCreateFeatures(t, repos, 1)
+
+		require.NoError(t, repos.Capabilities.SetFeatureCapability(ctx, &models.FeatureCapability{
+			FeatureID:        f[0].ID,
+			Capability:       "brightness",
+			ParameterMapping: map[string]string{"level": "dim_level"},
+		}))
+
+		got, err := repos.Capabilities.GetFeatureCapability(ctx, f[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, &models.FeatureCapability{
+			FeatureID:        f[0].ID,
+			ModelID:          f[0].ModelID,
+			Capability:       "brightness",
+			ParameterMapping: map[string]string{"level": "dim_level"},
+		}, got)
+	})
+
+	t.Run("SetFeatureCapabilityReplaces", func(t *testing.T) {
+		repos := capabilityRepositories(t, factory)
+		f := mustCreateFeatures(t, repos, 1)
+		require.NoError(t, repos.Capabilities.SetFeatureCapability(ctx, &models.FeatureCapability{
+			FeatureID:        f[0].ID,
+			Capability:       "brightness",
+			ParameterMapping: map[string]string{"level": "dim_level"},
+		}))
+
+		require.NoError(t, repos.Capabilities.SetFeatureCapability(ctx, &models.FeatureCapability{
+			FeatureID:  f[0].ID,
+			Capability: "switch",
+		}))
+
+		got, err := repos.Capabilities.GetFeatureCapability(ctx, f[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "switch", got.Capability)
+		assert.Empty(t, got.ParameterMapping)
+	})
+
+	t.Run("FeatureCapabilityNotFound", func(t *testing.T) {
+		repos := capabilityRepositories(t, factory)
+		f := mustCreateFeatures(t, repos, 1)
+
+		err := repos.Capabilities.SetFeatureCapability(ctx, &models.FeatureCapability{FeatureID: uuid.New(), Capability: "switch"})
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+
+		_, err = repos.Capabilities.GetFeatureCapability(ctx, f[0].ID)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+
+		err = repos.Capabilities.DeleteFeatureCapability(ctx, f[0].ID)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("DeleteFeatureCapability", func(t *testing.T) {
+		repos := capabilityRepositories(t, factory)
+		f := mustCreateFeatures(t, repos, 1)
+		require.NoError(t, repos.Capabilities.SetFeatureCapability(ctx, &models.FeatureCapability{FeatureID: f[0].ID, Capability: "switch"}))
+
+		require.NoError(t, repos.Capabilities.DeleteFeatureCapability(ctx, f[0].ID))
+
+		_, err := repos.Capabilities.GetFeatureCapability(ctx, f[0].ID)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("ListByCapability", func(t *testing.T) {
+		repos := capabilityRepositories(t, factory)
+		strip := mustCreateFeatures(t, repos, 3)
+		bulb := mustCreateFeatures(t, repos, 2)
+
+		var want []*models.FeatureCapability
+		for _, feature := range []*models.SmartFeature{strip[0], strip[1], bulb[0]} {
+			c := &models.FeatureCapability{
+				FeatureID:        feature.ID,
+				ModelID:          feature.ModelID,
+				Capability:       "switch",
+				ParameterMapping: map[string]string{"on": "power"},
+			}
+			require.NoError(t, repos.Capabilities.SetFeatureCapability(ctx, c))
+			want = append(want, c)
+		}
+		require.NoError(t, repos.Capabilities.SetFeatureCapability(ctx, &models.FeatureCapability{
+			FeatureID:  bulb[1].ID,
+			Capability: "brightness",
+		}))
+
+		listed, err := repos.Capabilities.ListByCapability(ctx, "switch")
+		require.NoError(t, err)
+		assert.Equal(t, sortedCapabilities(want), listed)
+
+		none, err := repos.Capabilities.ListByCapability(ctx, "lock")
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("DeleteFeatureRemovesCapability", func(t *testing.T) {
+		repos := capabilityRepositories(t, factory)
+		f := mustCreateFeatures(t, repos, 2)
+		for _, feature := range f {
+			require.NoError(t, repos.Capabilities.SetFeatureCapability(ctx, &models.FeatureCapability{FeatureID: feature.ID, Capability: "switch"}))
+		}
+
+		require.NoError(t, repos.Features.Delete(ctx, f[0].ID.String()))
+		listed, err := repos.Capabilities.ListByCapability(ctx, "switch")
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, f[1].ID, listed[0].FeatureID)
+
+		require.NoError(t, repos.Models.Delete(ctx, f[1].ModelID.String()))
+		listed, err = repos.Capabilities.ListByCapability(ctx, "switch")
+		require.NoError(t, err)
+		assert.Empty(t, listed)
+	})
+}
+
+func capabilityRepositories(t *testing.T, factory Factory) Repositories {
+	t.Helper()
+	repos := factory(t)
+	if repos.Capabilities == nil {
+		t.Skip("no feature capability repository")
+	}
+	return repos
+}
+
+func sortedCapabilities(capabilities []*models.FeatureCapability) []*models.FeatureCapability {
+	sorted := slices.Clone(capabilities)
+	slices.SortFunc(sorted, func(a, b *models.FeatureCapability) int {
+		if c := bytes.Compare(a.ModelID[:], b.ModelID[:]); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.FeatureID[:], b.FeatureID[:])
+	})
+	return sorted
+}
@@ -0,0 +1,218 @@
+package repotest
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func RunFeatureDependencyRepositoryTests(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	t.Run("SetAndListDependencies", func(t *testing.T) {
+		repos := dependencyRepositories(t, factory)
+		f := mustCreateFeatures(t, repos, 4)
+
+		dependencies := []models.FeatureDependency{
+			{FeatureID: f[0].ID, DependsOnID: f[1].ID, Kind: models.DependencyRequires},
+			{FeatureID: f[0].ID, DependsOnID: f[2].ID, Kind: models.DependencyRequires},
+			{FeatureID: f[0].ID, DependsOnID: f[3].ID, Kind: models.DependencyConflicts},
+		}
+		require.NoError(t, repos.Dependencies.SetDependencies(ctx, f[0].ID, dependencies))
+
+		listed, err := repos.Dependencies.ListDependencies(ctx, f[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, sortedDependencies(dependencies), listed)
+
+		none, err := repos.Dependencies.ListDependencies(ctx, f[1].ID)
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("SetDependenciesReplaces", func(t *testing.T) {
+		repos := dependencyRepositories(t, factory)
+		f := mustCreateFeatures(t, repos, 3)
+		require.NoError(t, repos.Dependencies.SetDependencies(ctx, f[0].ID, []models.FeatureDependency{
+			{FeatureID: f[0].ID, DependsOnID: f[1].ID, Kind: models.DependencyRequires},
+		}))
+
+		replacement := []models.FeatureDependency{{FeatureID: f[0].ID, DependsOnID: f[2].ID, Kind: models.DependencyConflicts}}
+		require.NoError(t, repos.Dependencies.SetDependencies(ctx, f[0].ID, replacement))
+		listed, err := repos.Dependencies.ListDependencies(ctx, f[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, replacement, listed)
+
+		require.NoError(t, repos.Dependencies.SetDependencies(ctx, f[0].ID, nil))
+		listed, err = repos.Dependencies.ListDependencies(ctx, f[0].ID)
+		require.NoError(t, err)
+		assert.Empty(t, listed)
+	})
+
+	t.Run("SetDependenciesUnknownFeature", func(t *testing.T) {
+		repos := dependencyRepositories(t, factory)
+		f := mustCreateFeatures(t, repos, 2)
+		require.NoError(t, repos.Dependencies.SetDependencies(ctx, f[0].ID, []models.FeatureDependency{
+			{FeatureID: f[0].ID, DependsOnID: f[1].ID, Kind: models.DependencyRequires},
+		}))
+
+		err := repos.Dependencies.SetDependencies(ctx, f[0].ID, []models.FeatureDependency{
+			{FeatureID: f[0].ID, DependsOnID: uuid.New(), Kind: models.DependencyRequires},
+		})
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+		listed, err := repos.Dependencies.ListDependencies(ctx, f[0].ID)
+		require.NoError(t, err)
+		assert.Len(t, listed, 1, "a failed write keeps the old declarations")
+
+		err = repos.Dependencies.SetDependencies(ctx, uuid.New(), nil)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("ListModelDependencies", func(t *testing.T) {
+		repos := dependencyRepositories(t, factory)
+		f := mustCreateFeatures(t, repos, 3)
+		other := mustCreateFeatures(t, repos, 2)
+
+		var all []models.FeatureDependency
+		for _, d := range []models.FeatureDependency{
+			{FeatureID: f[0].ID, DependsOnID: f[1].ID, Kind: models.DependencyRequires},
+			{FeatureID: f[1].ID, DependsOnID: f[2].ID, Kind: models.DependencyRequires},
+			{FeatureID: f[2].ID, DependsOnID: f[0].ID, Kind: models.DependencyConflicts},
+		} {
+			require.NoError(t, repos.Dependencies.SetDependencies(ctx, d.FeatureID, []models.FeatureDependency{d}))
+			all = append(all, d)
+		}
+		require.NoError(t, repos.Dependencies.SetDependencies(ctx, other[0].ID, []models.FeatureDependency{
+			{FeatureID: other[0].ID, DependsOnID: other[1].ID, Kind: models.DependencyRequires},
+		}))
+
+		listed, err := repos.Dependencies.ListModelDependencies(ctx, f[0].ModelID)
+		require.NoError(t, err)
+		assert.Equal(t, sortedDependencies(all), listed)
+
+		none, err := repos.Dependencies.ListModelDependencies(ctx, uuid.New())
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("LockModelDependenciesSerialisesUnitsOfWork", func(t *testing.T) {
+		repos := dependencyRepositories(t, factory)
+		if repos.UnitOfWork == nil {
+			t.Skip("no unit of work")
+		}
+		f := mustCreateFeatures(t, repos, 2)
+
+		// Each unit of work only declares its requirement while the model
+		// has none, as a check for cycles would, so only the one that takes
+		// the lock first may succeed.
+		errDeclared := errors.New("model already has dependencies")
+		declare := func(feature, dependsOn *models.SmartFeature) error {
+			return repos.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+				if err := repos.Dependencies.LockModelDependencies(ctx, feature.ModelID); err != nil {
+					return err
+				}
+				existing, err := repos.Dependencies.ListModelDependencies(ctx, feature.ModelID)
+				if err != nil {
+					return err
+				}
+				if len(existing) > 0 {
+					return errDeclared
+				}
+				time.Sleep(20 * time.Millisecond)
+				return repos.Dependencies.SetDependencies(ctx, feature.ID, []models.FeatureDependency{
+					{FeatureID: feature.ID, DependsOnID: dependsOn.ID, Kind: models.DependencyRequires},
+				})
+			})
+		}
+
+		start := make(chan struct{})
+		errs := make(chan error, 2)
+		for _, pair := range [][2]*models.SmartFeature{{f[0], f[1]}, {f[1], f[0]}} {
+			go func() {
+				<-start
+				errs <- declare(pair[0], pair[1])
+			}()
+		}
+		close(start)
+
+		failed := 0
+		for range 2 {
+			if err := <-errs; err != nil {
+				assert.True(t, errors.Is(err, errDeclared), "got %v", err)
+				failed++
+			}
+		}
+		assert.Equal(t, 1, failed)
+
+		listed, err := repos.Dependencies.ListModelDependencies(ctx, f[0].ModelID)
+		require.NoError(t, err)
+		assert.Len(t, listed, 1)
+	})
+
+	t.Run("DeleteFeatureRemovesDependencies", func(t *testing.T) {
+		repos := dependencyRepositories(t, factory)
+		f := mustCreateFeatures(t, repos, 3)
+		require.NoError(t, repos.Dependencies.SetDependencies(ctx, f[0].ID, []models.FeatureDependency{
+			{FeatureID: f[0].ID, DependsOnID: f[1].ID, Kind: models.DependencyRequires},
+			{FeatureID: f[0].ID, DependsOnID: f[2].ID, Kind: models.DependencyConflicts},
+		}))
+		require.NoError(t, repos.Dependencies.SetDependencies(ctx, f[1].ID, []models.FeatureDependency{
+			{FeatureID: f[1].ID, DependsOnID: f[2].ID, Kind: models.DependencyRequires},
+		}))
+
+		require.NoError(t, repos.Features.Delete(ctx, f[2].ID.String()))
+
+		listed, err := repos.Dependencies.ListModelDependencies(ctx, f[0].ModelID)
+		require.NoError(t, err)
+		assert.Equal(t, []models.FeatureDependency{
+			{FeatureID: f[0].ID, DependsOnID: f[1].ID, Kind: models.DependencyRequires},
+		}, listed)
+
+		require.NoError(t, repos.Models.Delete(ctx, f[0].ModelID.String()))
+		listed, err = repos.Dependencies.ListDependencies(ctx, f[0].ID)
+		require.NoError(t, err)
+		assert.Empty(t, listed)
+	})
+}
+
+func dependencyRepositories(t *testing.T, factory Factory) Repositories {
+	t.Helper()
+	repos := factory(t)
+	if repos.Dependencies == nil {
+		t.Skip("no feature dependency repository")
+	}
+	return repos
+}
+
+// mustCreateFeatures creates a model with n features.
+func mustCreateFeatures(t *testing.T, repos Repositories, n int) []*models.SmartFeature {
+	t.Helper()
+	model := mustCreateModel(t, repos, newModel("Camera", models.DeviceType, baseTime))
+	features := make([]*models.SmartFeature, n)
+	for i := range features {
+		features[i] = mustCreateFeature(t, repos, newFeature(model.ID, fmt.Sprintf("feature-%d", i), baseTime))
+	}
+	return features
+}
+
+func sortedDependencies(dependencies []models.FeatureDependency) []models.FeatureDependency {
+	sorted := slices.Clone(dependencies)
+	slices.SortFunc(sorted, func(a, b models.FeatureDependency) int {
+		if c := bytes.Compare(a.FeatureID[:], b.FeatureID[:]); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Kind, b.Kind); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.DependsOnID[:], b.DependsOnID[:])
+	})
+	return sorted
+}
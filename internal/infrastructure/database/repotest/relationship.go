@@ -0,0 +1,262 @@
+package repotest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func RunRelationshipRepositoryTests(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGetRelationship", func(t *testing.T) {
+		repos := relationshipRepositories(t, factory)
+		m := mustCreateModels(t, repos, 2)
+		relationship := newRelationship(m[0].ID, m[1].ID, models.RelationshipRequires, baseTime)
+
+		created, err := repos.Relationships.CreateRelationship(ctx, relationship)
+		require.NoError(t, err)
+		assertRelationship(t, relationship, created)
+
+		fetched, err := repos.Relationships.GetRelationship(ctx, relationship.ID)
+		require.NoError(t, err)
+		assertRelationship(t, relationship, fetched)
+
+		_, err = repos.Relationships.GetRelationship(ctx, uuid.New())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("CreateRelationshipDuplicate", func(t *testing.T) {
+		repos := relationshipRepositories(t, factory)
+		m := mustCreateModels(t, repos, 2)
+		mustCreateRelationship(t, repos, newRelationship(m[0].ID, m[1].ID, models.RelationshipRequires, baseTime))
+
+		_, err := repos.Relationships.CreateRelationship(ctx, newRelationship(m[0].ID, m[1].ID, models.RelationshipRequires, baseTime))
+		assert.True(t, errors.Is(err, models.ErrAlreadyExists), "got %v", err)
+
+		_, err = repos.Relationships.CreateRelationship(ctx, newRelationship(m[0].ID, m[1].ID, models.RelationshipAccessoryOf, baseTime))
+		assert.NoError(t, err, "another type is another relationship")
+		_, err = repos.Relationships.CreateRelationship(ctx, newRelationship(m[1].ID, m[0].ID, models.RelationshipRequires, baseTime))
+		assert.NoError(t, err, "the reverse direction is another relationship")
+	})
+
+	t.Run("LockRelationshipTypeSerialisesUnitsOfWork", func(t *testing.T) {
+		repos := relationshipRepositories(t, factory)
+		if repos.UnitOfWork == nil {
+			t.Skip("no unit of work")
+		}
+		m := mustCreateModels(t, repos, 2)
+
+		// Both units of work look for an existing relationship before
+		// creating theirs, as creating an acyclic one does, so only the one
+		// that takes the lock first may succeed.
+		errRelated := errors.New("models already related")
+		create := func(source, target uuid.UUID) error {
+			return repos.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+				if err := repos.Relationships.LockRelationshipType(ctx, models.RelationshipReplaces); err != nil {
+					return err
+				}
+				existing, err := repos.Relationships.ListRelationships(ctx, models.RelationshipFilter{ModelID: source, Type: models.RelationshipReplaces})
+				if err != nil {
+					return err
+				}
+				if len(existing) > 0 {
+					return errRelated
+				}
+				time.Sleep(20 * time.Millisecond)
+				_, err = repos.Relationships.CreateRelationship(ctx, newRelationship(source, target, models.RelationshipReplaces, baseTime))
+				return err
+			})
+		}
+
+		start := make(chan struct{})
+		errs := make(chan error, 2)
+		for _, pair := range [][2]uuid.UUID{{m[0].ID, m[1].ID}, {m[1].ID, m[0].ID}} {
+			go func() {
+				<-start
+				errs <- create(pair[0], pair[1])
+			}()
+		}
+		close(start)
+
+		failed := 0
+		for range 2 {
+			if err := <-errs; err != nil {
+				assert.True(t, errors.Is(err, errRelated), "got %v", err)
+				failed++
+			}
+		}
+		assert.Equal(t, 1, failed)
+
+		all, err := repos.Relationships.ListRelationships(ctx, models.RelationshipFilter{ModelID: m[0].ID, Type: models.RelationshipReplaces})
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+	})
+
+	t.Run("CreateRelationshipUnknownModel", func(t *testing.T) {
+		repos := relationshipRepositories(t, factory)
+		model := mustCreateModels(t, repos, 1)[0]
+
+		_, err := repos.Relationships.CreateRelationship(ctx, newRelationship(model.ID, uuid.New(), models.RelationshipReplaces, baseTime))
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("ListRelationships", func(t *testing.T) {
+		repos := relationshipRepositories(t, factory)
+		m := mustCreateModels(t, repos, 3)
+		later := mustCreateRelationship(t, repos, newRelationship(m[1].ID, m[0].ID, models.RelationshipRequires, baseTime.Add(time.Minute)))
+		earlier := mustCreateRelationship(t, repos, newRelationship(m[0].ID, m[2].ID, models.RelationshipCompatibleWith, baseTime))
+		mustCreateRelationship(t, repos, newRelationship(m[1].ID, m[2].ID, models.RelationshipRequires, baseTime))
+
+		all, err := repos.Relationships.ListRelationships(ctx, models.RelationshipFilter{ModelID: m[0].ID})
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		assert.Equal(t, earlier.ID, all[0].ID)
+		assert.Equal(t, later.ID, all[1].ID)
+
+		requires, err := repos.Relationships.ListRelationships(ctx, models.RelationshipFilter{ModelID: m[0].ID, Type: models.RelationshipRequires})
+		require.NoError(t, err)
+		require.Len(t, requires, 1)
+		assert.Equal(t, later.ID, requires[0].ID)
+	})
+
+	t.Run("DeleteRelationship", func(t *testing.T) {
+		repos := relationshipRepositories(t, factory)
+		m := mustCreateModels(t, repos, 2)
+		relationship := mustCreateRelationship(t, repos, newRelationship(m[0].ID, m[1].ID, models.RelationshipReplaces, baseTime))
+
+		require.NoError(t, repos.Relationships.DeleteRelationship(ctx, relationship.ID))
+		err := repos.Relationships.DeleteRelationship(ctx, relationship.ID)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("DeleteModelRemovesRelationships", func(t *testing.T) {
+		repos := relationshipRepositories(t, factory)
+		m := mustCreateModels(t, repos, 2)
+		relationship := mustCreateRelationship(t, repos, newRelationship(m[0].ID, m[1].ID, models.RelationshipAccessoryOf, baseTime))
+
+		require.NoError(t, repos.Models.Delete(ctx, m[1].ID.String()))
+
+		_, err := repos.Relationships.GetRelationship(ctx, relationship.ID)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("RelatedModels", func(t *testing.T) {
+		repos := relationshipRepositories(t, factory)
+		// a requires b and c, b and c both require d, d requires e, which
+		// requires a again.
+		m := mustCreateModels(t, repos, 6)
+		a, b, c, d, e, unrelated := m[0].ID, m[1].ID, m[2].ID, m[3].ID, m[4].ID, m[5].ID
+		for _, edge := range [][2]uuid.UUID{{a, b}, {a, c}, {b, d}, {c, d}, {d, e}, {e, a}} {
+			mustCreateRelationship(t, repos, newRelationship(edge[0], edge[1], models.RelationshipRequires, baseTime))
+		}
+		mustCreateRelationship(t, repos, newRelationship(unrelated, a, models.RelationshipCompatibleWith, baseTime))
+
+		tests := []struct {
+			name      string
+			direction models.RelationshipDirection
+			maxDepth  int
+			want      map[uuid.UUID]int
+		}{
+			{"one hop", models.DirectionOutgoing, 1, map[uuid.UUID]int{b: 1, c: 1}},
+			{"transitive", models.DirectionOutgoing, 10, map[uuid.UUID]int{b: 1, c: 1, d: 2, e: 3}},
+			{"incoming", models.DirectionIncoming, 2, map[uuid.UUID]int{e: 1, d: 2}},
+			{"both", models.DirectionBoth, 1, map[uuid.UUID]int{b: 1, c: 1, e: 1}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				related, err := repos.Relationships.RelatedModels(ctx, models.RelationshipQuery{
+					ModelID:   a,
+					Type:      models.RelationshipRequires,
+					Direction: tt.direction,
+					MaxDepth:  tt.maxDepth,
+				})
+				require.NoError(t, err)
+
+				got := make(map[uuid.UUID]int)
+				for _, model := range related {
+					got[model.ModelID] = model.Depth
+				}
+				assert.Equal(t, tt.want, got)
+				assert.True(t, slices.IsSortedFunc(related, func(x, y *models.RelatedModel) int {
+					if x.Depth != y.Depth {
+						return x.Depth - y.Depth
+					}
+					return bytes.Compare(x.ModelID[:], y.ModelID[:])
+				}), "ordered by depth and ID")
+			})
+		}
+
+		compatible, err := repos.Relationships.RelatedModels(ctx, models.RelationshipQuery{
+			ModelID:   a,
+			Type:      models.RelationshipCompatibleWith,
+			Direction: models.DirectionBoth,
+			MaxDepth:  10,
+		})
+		require.NoError(t, err)
+		require.Len(t, compatible, 1)
+		assert.Equal(t, unrelated, compatible[0].ModelID)
+
+		none, err := repos.Relationships.RelatedModels(ctx, models.RelationshipQuery{
+			ModelID:   unrelated,
+			Type:      models.RelationshipRequires,
+			Direction: models.DirectionBoth,
+			MaxDepth:  10,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+}
+
+func relationshipRepositories(t *testing.T, factory Factory) Repositories {
+	t.Helper()
+	repos := factory(t)
+	if repos.Relationships == nil {
+		t.Skip("no relationship repository")
+	}
+	return repos
+}
+
+func mustCreateModels(t *testing.T, repos Repositories, n int) []*models.SmartModel {
+	t.Helper()
+	created := make([]*models.SmartModel, n)
+	for i := range created {
+		model, err := repos.Models.Create(context.Background(), newModel("Model", models.DeviceType, baseTime))
+		require.NoError(t, err)
+		created[i] = model
+	}
+	return created
+}
+
+func newRelationship(sourceID, targetID uuid.UUID, relationshipType models.RelationshipType, createdAt time.Time) *models.ModelRelationship {
+	return &models.ModelRelationship{
+		ID:            uuid.New(),
+		SourceModelID: sourceID,
+		TargetModelID: targetID,
+		Type:          relationshipType,
+		CreatedAt:     createdAt,
+	}
+}
+
+func mustCreateRelationship(t *testing.T, repos Repositories, relationship *models.ModelRelationship) *models.ModelRelationship {
+	t.Helper()
+	created, err := repos.Relationships.CreateRelationship(context.Background(), relationship)
+	require.NoError(t, err)
+	return created
+}
+
+func assertRelationship(t *testing.T, expected, actual *models.ModelRelationship) {
+	t.Helper()
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.SourceModelID, actual.SourceModelID)
+	assert.Equal(t, expected.TargetModelID, actual.TargetModelID)
+	assert.Equal(t, expected.Type, actual.Type)
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "created_at: %v != %v", expected.CreatedAt, actual.CreatedAt)
+}
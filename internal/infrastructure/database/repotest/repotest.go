@@ -0,0 +1,768 @@
+// Package repotest is a conformance suite for SmartModelRepository,
+// SmartFeatureRepository, TelemetryRepository, ShadowRepository,
+// AutomationRepository, ScheduleRepository, SceneRepository,
+// RelationshipRepository, FeatureDependencyRepository and
+// FeatureCapabilityRepository implementations. Every backend runs it from
+// its own tests so they all keep the same semantics.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Repositories are the repositories under test, backed by the same storage.
+// Telemetry, Shadows, Automations, Schedules, Scenes, Relationships,
+// Dependencies and Capabilities are optional; their tests are skipped
+// without them. UnitOfWork runs transactions over the same storage; the
+// tests of locking are skipped without it.
+type Repositories struct {
+	Models        interfaces.SmartModelRepository
+	Features      interfaces.SmartFeatureRepository
+	Telemetry     interfaces.TelemetryRepository
+	Shadows       interfaces.ShadowRepository
+	Automations   interfaces.AutomationRepository
+	Schedules     interfaces.ScheduleRepository
+	Scenes        interfaces.SceneRepository
+	Relationships interfaces.RelationshipRepository
+	Dependencies  interfaces.FeatureDependencyRepository
+	Capabilities  interfaces.FeatureCapabilityRepository
+	UnitOfWork    interfaces.UnitOfWork
+}
+
+// Factory returns repositories over empty storage. It is called once per
+// test case.
+type Factory func(t *testing.T) Repositories
+
+// Run runs the whole suite.
+func Run(t *testing.T, factory Factory) {
+	t.Run("SmartModelRepository", func(t *testing.T) {
+		RunSmartModelRepositoryTests(t, factory)
+	})
+	t.Run("SmartFeatureRepository", func(t *testing.T) {
+		RunSmartFeatureRepositoryTests(t, factory)
+	})
+	t.Run("TelemetryRepository", func(t *testing.T) {
+		RunTelemetryRepositoryTests(t, factory)
+	})
+	t.Run("ShadowRepository", func(t *testing.T) {
+		RunShadowRepositoryTests(t, factory)
+	})
+	t.Run("AutomationRepository", func(t *testing.T) {
+		RunAutomationRepositoryTests(t, factory)
+	})
+	t.Run("ScheduleRepository", func(t *testing.T) {
+		RunScheduleRepositoryTests(t, factory)
+	})
+	t.Run("SceneRepository", func(t *testing.T) {
+		RunSceneRepositoryTests(t, factory)
+	})
+	t.Run("RelationshipRepository", func(t *testing.T) {
+		RunRelationshipRepositoryTests(t, factory)
+	})
+	t.Run("FeatureDependencyRepository", func(t *testing.T) {
+		RunFeatureDependencyRepositoryTests(t, factory)
+	})
+	t.Run("FeatureCapabilityRepository", func(t *testing.T) {
+		RunFeatureCapabilityRepositoryTests(t, factory)
+	})
+}
+
+func RunSmartModelRepositoryTests(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGetByID", func(t *testing.T) {
+		repos := factory(t)
+		model := newModel("Thermostat", models.DeviceType, baseTime)
+
+		created, err := repos.Models.Create(ctx, model)
+		require.NoError(t, err)
+		assertModel(t, model, created)
+
+		fetched, err := repos.Models.GetByID(ctx, model.ID.String())
+		require.NoError(t, err)
+		assertModel(t, model, fetched)
+	})
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		repos := factory(t)
+
+		_, err := repos.Models.GetByID(ctx, uuid.NewString())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("GetByModelNumber", func(t *testing.T) {
+		repos := factory(t)
+		model := newModel("Thermostat", models.DeviceType, baseTime)
+		model.ModelNumber = "TH200"
+		mustCreateModel(t, repos, newModel("Camera", models.DeviceType, baseTime))
+		mustCreateModel(t, repos, model)
+
+		fetched, err := repos.Models.GetByModelNumber(ctx, "Acme", "TH200")
+		require.NoError(t, err)
+		assertModel(t, model, fetched)
+
+		_, err = repos.Models.GetByModelNumber(ctx, "Other", "TH200")
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("GetByIDs", func(t *testing.T) {
+		repos := factory(t)
+		first := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		mustCreateModel(t, repos, newModel("Camera", models.DeviceType, baseTime))
+		second := mustCreateModel(t, repos, newModel("Forecast", models.ServiceType, baseTime.Add(time.Minute)))
+
+		found, err := repos.Models.GetByIDs(ctx, []string{second.ID.String(), uuid.NewString(), first.ID.String()})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{first.ID, second.ID}, modelIDs(found))
+
+		none, err := repos.Models.GetByIDs(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("ReturnsCopies", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+
+		model.Name = "Changed"
+		model.Metadata["firmware"] = "changed"
+
+		fetched, err := repos.Models.GetByID(ctx, model.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, "Thermostat", fetched.Name)
+		assert.Equal(t, "1.0.0", fetched.Metadata["firmware"])
+	})
+
+	t.Run("GetAllOrdersByCreatedAtThenID", func(t *testing.T) {
+		repos := factory(t)
+		third := mustCreateModel(t, repos, newModel("Third", models.DeviceType, baseTime.Add(time.Hour)))
+		first := mustCreateModel(t, repos, newModel("First", models.ServiceType, baseTime))
+		second := mustCreateModel(t, repos, newModel("Second", models.DeviceType, baseTime))
+		if first.ID.String() > second.ID.String() {
+			first, second = second, first
+		}
+
+		all, err := repos.Models.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{first.ID, second.ID, third.ID}, modelIDs(all))
+	})
+
+	t.Run("GetAllEmpty", func(t *testing.T) {
+		repos := factory(t)
+
+		all, err := repos.Models.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, all)
+	})
+
+	t.Run("GetWithType", func(t *testing.T) {
+		repos := factory(t)
+		later := mustCreateModel(t, repos, newModel("Camera", models.DeviceType, baseTime.Add(time.Minute)))
+		mustCreateModel(t, repos, newModel("Forecast", models.ServiceType, baseTime))
+		earlier := mustCreateModel(t, repos, newModel("Watch", models.DeviceType, baseTime))
+
+		devices, err := repos.Models.GetWithType(ctx, models.DeviceType)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{earlier.ID, later.ID}, modelIDs(devices))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+
+		model.Name = "Smart Thermostat"
+		model.Type = models.ServiceType
+		model.Metadata = map[string]interface{}{"firmware": "2.0.0"}
+		model.UpdatedAt = baseTime.Add(time.Hour)
+
+		updated, err := repos.Models.Update(ctx, model)
+		require.NoError(t, err)
+		assertModel(t, model, updated)
+
+		fetched, err := repos.Models.GetByID(ctx, model.ID.String())
+		require.NoError(t, err)
+		assertModel(t, model, fetched)
+	})
+
+	t.Run("UpdateNotFound", func(t *testing.T) {
+		repos := factory(t)
+
+		_, err := repos.Models.Update(ctx, newModel("Missing", models.DeviceType, baseTime))
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+
+		require.NoError(t, repos.Models.Delete(ctx, model.ID.String()))
+
+		_, err := repos.Models.GetByID(ctx, model.ID.String())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("DeleteNotFound", func(t *testing.T) {
+		repos := factory(t)
+
+		err := repos.Models.Delete(ctx, uuid.NewString())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("GetByModelNumberIgnoresCase", func(t *testing.T) {
+		repos := factory(t)
+		model := newModel("Thermostat", models.DeviceType, baseTime)
+		model.ModelNumber = "TH200"
+		mustCreateModel(t, repos, model)
+
+		fetched, err := repos.Models.GetByModelNumber(ctx, "ACME", "th200")
+		require.NoError(t, err)
+		assert.Equal(t, model.ID, fetched.ID)
+	})
+
+	t.Run("CreateDuplicateModelNumber", func(t *testing.T) {
+		repos := factory(t)
+		existing := newModel("Thermostat", models.DeviceType, baseTime)
+		existing.ModelNumber = "TH200"
+		mustCreateModel(t, repos, existing)
+
+		duplicate := newModel("Thermostat Copy", models.DeviceType, baseTime)
+		duplicate.Manufacturer = "ACME"
+		duplicate.ModelNumber = "th200"
+		_, err := repos.Models.Create(ctx, duplicate)
+		assertAlreadyExists(t, err, models.SmartModelAggregate, existing.ID)
+
+		_, err = repos.Models.GetByID(ctx, duplicate.ID.String())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("ModelNumberIsPerManufacturer", func(t *testing.T) {
+		repos := factory(t)
+		first := newModel("Thermostat", models.DeviceType, baseTime)
+		first.ModelNumber = "TH200"
+		second := newModel("Thermostat", models.DeviceType, baseTime)
+		second.Manufacturer = "Globex"
+		second.ModelNumber = "TH200"
+		mustCreateModel(t, repos, first)
+		mustCreateModel(t, repos, second)
+	})
+
+	t.Run("EmptyModelNumberIsNotUnique", func(t *testing.T) {
+		repos := factory(t)
+		first := newModel("Forecast", models.ServiceType, baseTime)
+		first.ModelNumber = ""
+		second := newModel("Radio", models.ServiceType, baseTime)
+		second.ModelNumber = ""
+		mustCreateModel(t, repos, first)
+		mustCreateModel(t, repos, second)
+	})
+
+	t.Run("UpdateToDuplicateModelNumber", func(t *testing.T) {
+		repos := factory(t)
+		existing := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		model := mustCreateModel(t, repos, newModel("Camera", models.DeviceType, baseTime))
+
+		model.ModelNumber = strings.ToUpper(existing.ModelNumber)
+		_, err := repos.Models.Update(ctx, model)
+		assertAlreadyExists(t, err, models.SmartModelAggregate, existing.ID)
+
+		// Keeping its own model number is not a conflict.
+		existing.Name = "Renamed"
+		_, err = repos.Models.Update(ctx, existing)
+		assert.NoError(t, err)
+	})
+
+	t.Run("DeleteCascadesToFeatures", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		other := mustCreateModel(t, repos, newModel("Camera", models.DeviceType, baseTime))
+		feature := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+		kept := mustCreateFeature(t, repos, newFeature(other.ID, "Snapshot", baseTime))
+
+		require.NoError(t, repos.Models.Delete(ctx, model.ID.String()))
+
+		_, err := repos.Features.GetByID(ctx, feature.ID.String())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+
+		all, err := repos.Features.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{kept.ID}, featureIDs(all))
+	})
+}
+
+func RunSmartFeatureRepositoryTests(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGetByID", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		feature := newFeature(model.ID, "Temperature", baseTime)
+
+		created, err := repos.Features.Create(ctx, feature)
+		require.NoError(t, err)
+		assertFeature(t, feature, created)
+
+		fetched, err := repos.Features.GetByID(ctx, feature.ID.String())
+		require.NoError(t, err)
+		assertFeature(t, feature, fetched)
+	})
+
+	t.Run("CreateForMissingModel", func(t *testing.T) {
+		repos := factory(t)
+
+		_, err := repos.Features.Create(ctx, newFeature(uuid.New(), "Temperature", baseTime))
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		repos := factory(t)
+
+		_, err := repos.Features.GetByID(ctx, uuid.NewString())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("GetWithModelID", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		other := mustCreateModel(t, repos, newModel("Camera", models.DeviceType, baseTime))
+		later := mustCreateFeature(t, repos, newFeature(model.ID, "Humidity", baseTime.Add(time.Minute)))
+		mustCreateFeature(t, repos, newFeature(other.ID, "Snapshot", baseTime))
+		earlier := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+
+		features, err := repos.Features.GetWithModelID(ctx, model.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{earlier.ID, later.ID}, featureIDs(features))
+
+		none, err := repos.Features.GetWithModelID(ctx, uuid.NewString())
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("GetAllOrdersByCreatedAtThenID", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		third := mustCreateFeature(t, repos, newFeature(model.ID, "Third", baseTime.Add(time.Hour)))
+		first := mustCreateFeature(t, repos, newFeature(model.ID, "First", baseTime))
+		second := mustCreateFeature(t, repos, newFeature(model.ID, "Second", baseTime))
+		if first.ID.String() > second.ID.String() {
+			first, second = second, first
+		}
+
+		all, err := repos.Features.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{first.ID, second.ID, third.ID}, featureIDs(all))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		feature := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+
+		feature.Name = "Target Temperature"
+		feature.Protocol = models.MqttProtocol
+		feature.InterfacePath = "/target"
+		feature.Parameters = map[string]interface{}{"unit": "fahrenheit"}
+		feature.UpdatedAt = baseTime.Add(time.Hour)
+
+		updated, err := repos.Features.Update(ctx, feature)
+		require.NoError(t, err)
+		assertFeature(t, feature, updated)
+
+		fetched, err := repos.Features.GetByID(ctx, feature.ID.String())
+		require.NoError(t, err)
+		assertFeature(t, feature, fetched)
+	})
+
+	t.Run("UpdateNotFound", func(t *testing.T) {
+		repos := factory(t)
+
+		_, err := repos.Features.Update(ctx, newFeature(uuid.New(), "Missing", baseTime))
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		feature := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+
+		require.NoError(t, repos.Features.Delete(ctx, feature.ID.String()))
+
+		_, err := repos.Features.GetByID(ctx, feature.ID.String())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+
+		_, err = repos.Models.GetByID(ctx, model.ID.String())
+		assert.NoError(t, err)
+	})
+
+	t.Run("DeleteNotFound", func(t *testing.T) {
+		repos := factory(t)
+
+		err := repos.Features.Delete(ctx, uuid.NewString())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("GetByIDs", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		first := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+		mustCreateFeature(t, repos, newFeature(model.ID, "Humidity", baseTime))
+		second := mustCreateFeature(t, repos, newFeature(model.ID, "Pressure", baseTime))
+
+		found, err := repos.Features.GetByIDs(ctx, []string{second.ID.String(), uuid.NewString(), first.ID.String()})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{first.ID, second.ID}, featureIDs(found))
+	})
+
+	t.Run("GetWithModelIDs", func(t *testing.T) {
+		repos := factory(t)
+		thermostat := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		camera := mustCreateModel(t, repos, newModel("Camera", models.DeviceType, baseTime))
+		other := mustCreateModel(t, repos, newModel("Doorbell", models.DeviceType, baseTime))
+		second := mustCreateFeature(t, repos, newFeature(camera.ID, "Stream", baseTime.Add(time.Minute)))
+		first := mustCreateFeature(t, repos, newFeature(thermostat.ID, "Temperature", baseTime))
+		mustCreateFeature(t, repos, newFeature(other.ID, "Ring", baseTime))
+
+		features, err := repos.Features.GetWithModelIDs(ctx, []string{thermostat.ID.String(), camera.ID.String(), uuid.NewString()})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{first.ID, second.ID}, featureIDs(features))
+	})
+
+	t.Run("ListFilters", func(t *testing.T) {
+		repos := factory(t)
+		watch := mustCreateModel(t, repos, newModel("Watch", models.DeviceType, baseTime))
+		camera := newModel("Camera", models.DeviceType, baseTime)
+		camera.Category = models.CameraCategory
+		camera = mustCreateModel(t, repos, camera)
+		forecast := newModel("Forecast", models.ServiceType, baseTime)
+		forecast.Category = models.WeatherCategory
+		forecast = mustCreateModel(t, repos, forecast)
+
+		heartRate := newFeature(watch.ID, "HeartRate", baseTime)
+		heartRate.Protocol = models.MqttProtocol
+		heartRate.InterfacePath = "/sensors/heart_rate"
+		heartRate = mustCreateFeature(t, repos, heartRate)
+		heading := newFeature(watch.ID, "Heading", baseTime.Add(time.Minute))
+		heading.InterfacePath = "/sensors/heading"
+		heading = mustCreateFeature(t, repos, heading)
+		stream := newFeature(camera.ID, "Stream", baseTime.Add(2*time.Minute))
+		stream.Protocol = models.MqttProtocol
+		stream.InterfacePath = "/video/100%"
+		stream = mustCreateFeature(t, repos, stream)
+		hourly := newFeature(forecast.ID, "Hourly", baseTime.Add(3*time.Minute))
+		hourly.InterfacePath = "/forecast/hourly"
+		hourly = mustCreateFeature(t, repos, hourly)
+
+		mqtt := models.MqttProtocol
+		device := models.DeviceType
+		weather := models.WeatherCategory
+		for name, tc := range map[string]struct {
+			filter   models.FeatureFilter
+			expected []uuid.UUID
+		}{
+			"All":                       {models.FeatureFilter{}, []uuid.UUID{heartRate.ID, heading.ID, stream.ID, hourly.ID}},
+			"Protocol":                  {models.FeatureFilter{Protocol: &mqtt}, []uuid.UUID{heartRate.ID, stream.ID}},
+			"ModelType":                 {models.FeatureFilter{ModelType: &device}, []uuid.UUID{heartRate.ID, heading.ID, stream.ID}},
+			"ModelCategory":             {models.FeatureFilter{ModelCategory: &weather}, []uuid.UUID{hourly.ID}},
+			"NamePrefix":                {models.FeatureFilter{NamePrefix: "He"}, []uuid.UUID{heartRate.ID, heading.ID}},
+			"NamePrefixIsCaseSensitive": {models.FeatureFilter{NamePrefix: "he"}, nil},
+			"PathGlob":                  {models.FeatureFilter{InterfacePathPattern: "/sensors/*"}, []uuid.UUID{heartRate.ID, heading.ID}},
+			"PathSingleChar":            {models.FeatureFilter{InterfacePathPattern: "/sensors/headin?"}, []uuid.UUID{heading.ID}},
+			"PathLiterals":              {models.FeatureFilter{InterfacePathPattern: "/video/100%"}, []uuid.UUID{stream.ID}},
+			"PathUnderscoreIsLiteral":   {models.FeatureFilter{InterfacePathPattern: "/sensors/heart?rate"}, []uuid.UUID{heartRate.ID}},
+			"PathWholeMatch":            {models.FeatureFilter{InterfacePathPattern: "/sensors"}, nil},
+			"Combined":                  {models.FeatureFilter{Protocol: &mqtt, ModelType: &device, InterfacePathPattern: "/video/*"}, []uuid.UUID{stream.ID}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				features, err := repos.Features.List(ctx, tc.filter, nil, 10)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, featureIDs(features))
+			})
+		}
+	})
+
+	t.Run("ListPages", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		for i, name := range []string{"Alpha", "Beta", "Gamma", "Delta", "Epsilon"} {
+			// Features share creation times in pairs to exercise the ID tiebreak.
+			mustCreateFeature(t, repos, newFeature(model.ID, name, baseTime.Add(time.Duration(i/2)*time.Minute)))
+		}
+
+		var listed []uuid.UUID
+		var after *models.PageCursor
+		for {
+			page, err := repos.Features.List(ctx, models.FeatureFilter{}, after, 2)
+			require.NoError(t, err)
+			if len(page) == 0 {
+				break
+			}
+			require.LessOrEqual(t, len(page), 2)
+			listed = append(listed, featureIDs(page)...)
+			last := page[len(page)-1]
+			after = &models.PageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+
+		all := featureIDs(mustGetAllFeatures(t, repos))
+		assert.Len(t, all, 5)
+		assert.Equal(t, all, listed)
+	})
+
+	t.Run("CreateBatch", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		features := []*models.SmartFeature{
+			newFeature(model.ID, "Temperature", baseTime),
+			newFeature(model.ID, "Humidity", baseTime.Add(time.Minute)),
+		}
+
+		created, err := repos.Features.CreateBatch(ctx, features)
+		require.NoError(t, err)
+		require.Len(t, created, 2)
+		assertFeature(t, features[0], created[0])
+		assertFeature(t, features[1], created[1])
+
+		all, err := repos.Features.GetWithModelID(ctx, model.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{features[0].ID, features[1].ID}, featureIDs(all))
+	})
+
+	t.Run("CreateBatchIsAllOrNothing", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+
+		_, err := repos.Features.CreateBatch(ctx, []*models.SmartFeature{
+			newFeature(model.ID, "Temperature", baseTime),
+			newFeature(uuid.New(), "Orphan", baseTime),
+		})
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+
+		all, err := repos.Features.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, all)
+	})
+
+	t.Run("CreateDuplicateNamePath", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		existing := mustCreateFeature(t, repos, newFeature(model.ID, "reboot", baseTime))
+
+		duplicate := newFeature(model.ID, "Reboot", baseTime)
+		duplicate.InterfacePath = "/API/reboot"
+		_, err := repos.Features.Create(ctx, duplicate)
+		assertAlreadyExists(t, err, models.SmartFeatureAggregate, existing.ID)
+
+		// The same name on another path or another model is fine.
+		otherPath := newFeature(model.ID, "reboot", baseTime)
+		otherPath.InterfacePath = "/api/restart"
+		mustCreateFeature(t, repos, otherPath)
+		other := mustCreateModel(t, repos, newModel("Camera", models.DeviceType, baseTime))
+		mustCreateFeature(t, repos, newFeature(other.ID, "reboot", baseTime))
+	})
+
+	t.Run("UpdateToDuplicateNamePath", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		existing := mustCreateFeature(t, repos, newFeature(model.ID, "reboot", baseTime))
+		feature := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+
+		feature.Name = "REBOOT"
+		feature.InterfacePath = existing.InterfacePath
+		feature.ModelID = uuid.Nil
+		_, err := repos.Features.Update(ctx, feature)
+		assertAlreadyExists(t, err, models.SmartFeatureAggregate, existing.ID)
+	})
+
+	t.Run("CreateBatchDuplicateNamePath", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		mustCreateFeature(t, repos, newFeature(model.ID, "reboot", baseTime))
+
+		_, err := repos.Features.CreateBatch(ctx, []*models.SmartFeature{
+			newFeature(model.ID, "Temperature", baseTime),
+			newFeature(model.ID, "Reboot", baseTime),
+		})
+		assert.True(t, errors.Is(err, models.ErrAlreadyExists), "got %v", err)
+
+		all, err := repos.Features.GetWithModelID(ctx, model.ID.String())
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+	})
+
+	t.Run("UpdateBatch", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		first := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+		second := mustCreateFeature(t, repos, newFeature(model.ID, "Humidity", baseTime))
+
+		first.Name = "Target Temperature"
+		first.UpdatedAt = baseTime.Add(time.Hour)
+		second.Protocol = models.MqttProtocol
+		second.UpdatedAt = baseTime.Add(time.Hour)
+
+		updated, err := repos.Features.UpdateBatch(ctx, []*models.SmartFeature{first, second})
+		require.NoError(t, err)
+		require.Len(t, updated, 2)
+		assertFeature(t, first, updated[0])
+		assertFeature(t, second, updated[1])
+
+		fetched, err := repos.Features.GetByID(ctx, second.ID.String())
+		require.NoError(t, err)
+		assertFeature(t, second, fetched)
+	})
+
+	t.Run("UpdateBatchIsAllOrNothing", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		feature := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+
+		changed := *feature
+		changed.Name = "Changed"
+		_, err := repos.Features.UpdateBatch(ctx, []*models.SmartFeature{&changed, newFeature(model.ID, "Missing", baseTime)})
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+
+		fetched, err := repos.Features.GetByID(ctx, feature.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, "Temperature", fetched.Name)
+	})
+
+	t.Run("DeleteBatch", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		first := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+		second := mustCreateFeature(t, repos, newFeature(model.ID, "Humidity", baseTime))
+		kept := mustCreateFeature(t, repos, newFeature(model.ID, "Pressure", baseTime))
+
+		err := repos.Features.DeleteBatch(ctx, []string{first.ID.String(), second.ID.String(), first.ID.String()})
+		require.NoError(t, err)
+
+		all, err := repos.Features.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{kept.ID}, featureIDs(all))
+	})
+
+	t.Run("DeleteBatchIsAllOrNothing", func(t *testing.T) {
+		repos := factory(t)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		feature := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+
+		err := repos.Features.DeleteBatch(ctx, []string{feature.ID.String(), uuid.NewString()})
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+
+		_, err = repos.Features.GetByID(ctx, feature.ID.String())
+		assert.NoError(t, err)
+	})
+}
+
+// baseTime has no sub-microsecond part, so it survives a round trip through
+// Postgres unchanged.
+var baseTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newModel(name string, modelType models.ModelType, createdAt time.Time) *models.SmartModel {
+	id := uuid.New()
+	return &models.SmartModel{
+		ID:           id,
+		Name:         name,
+		Description:  name + " description",
+		Type:         modelType,
+		Category:     models.WearableCategory,
+		Manufacturer: "Acme",
+		ModelNumber:  "AC-" + id.String()[:8],
+		Metadata:     map[string]interface{}{"firmware": "1.0.0", "channels": float64(2)},
+		CreatedAt:    createdAt,
+		UpdatedAt:    createdAt,
+	}
+}
+
+func newFeature(modelID uuid.UUID, name string, createdAt time.Time) *models.SmartFeature {
+	return &models.SmartFeature{
+		ID:            uuid.New(),
+		ModelID:       modelID,
+		Name:          name,
+		Description:   name + " description",
+		Protocol:      models.RestProtocol,
+		InterfacePath: "/api/" + name,
+		Parameters:    map[string]interface{}{"unit": "celsius", "precision": float64(1)},
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+	}
+}
+
+func assertAlreadyExists(t *testing.T, err error, entity models.AggregateType, id uuid.UUID) {
+	t.Helper()
+	var conflict *models.AlreadyExistsError
+	require.True(t, errors.As(err, &conflict), "got %v", err)
+	assert.Equal(t, entity, conflict.Entity)
+	assert.Equal(t, id, conflict.ID)
+}
+
+func mustCreateModel(t *testing.T, repos Repositories, model *models.SmartModel) *models.SmartModel {
+	t.Helper()
+	created, err := repos.Models.Create(context.Background(), model)
+	require.NoError(t, err)
+	return created
+}
+
+func mustCreateFeature(t *testing.T, repos Repositories, feature *models.SmartFeature) *models.SmartFeature {
+	t.Helper()
+	created, err := repos.Features.Create(context.Background(), feature)
+	require.NoError(t, err)
+	return created
+}
+
+func assertModel(t *testing.T, expected, actual *models.SmartModel) {
+	t.Helper()
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Description, actual.Description)
+	assert.Equal(t, expected.Type, actual.Type)
+	assert.Equal(t, expected.Category, actual.Category)
+	assert.Equal(t, expected.Manufacturer, actual.Manufacturer)
+	assert.Equal(t, expected.ModelNumber, actual.ModelNumber)
+	assert.Equal(t, expected.Metadata, actual.Metadata)
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "created_at %v, want %v", actual.CreatedAt, expected.CreatedAt)
+	assert.True(t, expected.UpdatedAt.Equal(actual.UpdatedAt), "updated_at %v, want %v", actual.UpdatedAt, expected.UpdatedAt)
+}
+
+func assertFeature(t *testing.T, expected, actual *models.SmartFeature) {
+	t.Helper()
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.ModelID, actual.ModelID)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Description, actual.Description)
+	assert.Equal(t, expected.Protocol, actual.Protocol)
+	assert.Equal(t, expected.InterfacePath, actual.InterfacePath)
+	assert.Equal(t, expected.Parameters, actual.Parameters)
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "created_at %v, want %v", actual.CreatedAt, expected.CreatedAt)
+	assert.True(t, expected.UpdatedAt.Equal(actual.UpdatedAt), "updated_at %v, want %v", actual.UpdatedAt, expected.UpdatedAt)
+}
+
+func modelIDs(smartModels []*models.SmartModel) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, model := range smartModels {
+		ids = append(ids, model.ID)
+	}
+	return ids
+}
+
+func featureIDs(features []*models.SmartFeature) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, feature := range features {
+		ids = append(ids, feature.ID)
+	}
+	return ids
+}
+
+func mustGetAllFeatures(t *testing.T, repos Repositories) []*models.SmartFeature {
+	t.Helper()
+	features, err := repos.Features.GetAll(context.Background())
+	require.NoError(t, err)
+	return features
+}
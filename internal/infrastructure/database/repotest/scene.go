@@ -0,0 +1,129 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func RunSceneRepositoryTests(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGetScene", func(t *testing.T) {
+		repos := sceneRepositories(t, factory)
+		scene := newScene("Movie night", baseTime)
+
+		created, err := repos.Scenes.CreateScene(ctx, scene)
+		require.NoError(t, err)
+		assertScene(t, scene, created)
+
+		fetched, err := repos.Scenes.GetScene(ctx, scene.ID)
+		require.NoError(t, err)
+		assertScene(t, scene, fetched)
+		assert.Equal(t, map[string]interface{}{"brightness": float64(20)}, fetched.Steps[0].Parameters)
+		assert.Nil(t, fetched.Steps[2].Parameters)
+	})
+
+	t.Run("GetSceneNotFound", func(t *testing.T) {
+		repos := sceneRepositories(t, factory)
+
+		_, err := repos.Scenes.GetScene(ctx, uuid.New())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("ListScenesOrdersByCreatedAt", func(t *testing.T) {
+		repos := sceneRepositories(t, factory)
+		later := mustCreateScene(t, repos, newScene("Later", baseTime.Add(time.Minute)))
+		earlier := mustCreateScene(t, repos, newScene("Earlier", baseTime))
+
+		scenes, err := repos.Scenes.ListScenes(ctx)
+		require.NoError(t, err)
+		require.Len(t, scenes, 2)
+		assert.Equal(t, earlier.ID, scenes[0].ID)
+		assert.Equal(t, later.ID, scenes[1].ID)
+	})
+
+	t.Run("UpdateScene", func(t *testing.T) {
+		repos := sceneRepositories(t, factory)
+		scene := mustCreateScene(t, repos, newScene("Movie night", baseTime))
+
+		scene.Name = "Late movie"
+		scene.Description = "Quieter"
+		scene.Steps = scene.Steps[:1]
+		scene.StopOnError = true
+		scene.UpdatedAt = baseTime.Add(time.Hour)
+		updated, err := repos.Scenes.UpdateScene(ctx, scene)
+		require.NoError(t, err)
+		assertScene(t, scene, updated)
+
+		scene.ID = uuid.New()
+		_, err = repos.Scenes.UpdateScene(ctx, scene)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("DeleteScene", func(t *testing.T) {
+		repos := sceneRepositories(t, factory)
+		scene := mustCreateScene(t, repos, newScene("Movie night", baseTime))
+
+		require.NoError(t, repos.Scenes.DeleteScene(ctx, scene.ID))
+		_, err := repos.Scenes.GetScene(ctx, scene.ID)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+
+		err = repos.Scenes.DeleteScene(ctx, scene.ID)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+}
+
+func sceneRepositories(t *testing.T, factory Factory) Repositories {
+	t.Helper()
+	repos := factory(t)
+	if repos.Scenes == nil {
+		t.Skip("no scene repository")
+	}
+	return repos
+}
+
+func newScene(name string, createdAt time.Time) *models.Scene {
+	return &models.Scene{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: "Dim the lights and start the film",
+		Steps: []models.SceneStep{
+			{DeviceID: "light-1", FeatureID: uuid.New(), Parameters: map[string]interface{}{"brightness": 20}, Group: "lights"},
+			{DeviceID: "light-2", FeatureID: uuid.New(), Parameters: map[string]interface{}{"brightness": 10}, Group: "lights"},
+			{DeviceID: "tv-1", FeatureID: uuid.New(), Delay: 2 * time.Second},
+		},
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func mustCreateScene(t *testing.T, repos Repositories, scene *models.Scene) *models.Scene {
+	t.Helper()
+	created, err := repos.Scenes.CreateScene(context.Background(), scene)
+	require.NoError(t, err)
+	return created
+}
+
+func assertScene(t *testing.T, expected, actual *models.Scene) {
+	t.Helper()
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Description, actual.Description)
+	assert.Equal(t, expected.StopOnError, actual.StopOnError)
+	if assert.Len(t, actual.Steps, len(expected.Steps)) {
+		for i, step := range expected.Steps {
+			assert.Equal(t, step.DeviceID, actual.Steps[i].DeviceID)
+			assert.Equal(t, step.FeatureID, actual.Steps[i].FeatureID)
+			assert.Equal(t, step.Delay, actual.Steps[i].Delay)
+			assert.Equal(t, step.Group, actual.Steps[i].Group)
+		}
+	}
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "created_at: %v != %v", expected.CreatedAt, actual.CreatedAt)
+	assert.True(t, expected.UpdatedAt.Equal(actual.UpdatedAt), "updated_at: %v != %v", expected.UpdatedAt, actual.UpdatedAt)
+}
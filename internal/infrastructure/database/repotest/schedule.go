@@ -0,0 +1,292 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func RunScheduleRepositoryTests(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGetSchedule", func(t *testing.T) {
+		repos := scheduleRepositories(t, factory)
+		cron := newSchedule("Morning blinds", baseTime)
+		interval := newSchedule("Poll meter", baseTime)
+		interval.Cron = ""
+		interval.Interval = 15 * time.Minute
+
+		for _, schedule := range []*models.Schedule{cron, interval} {
+			created, err := repos.Schedules.CreateSchedule(ctx, schedule)
+			require.NoError(t, err)
+			assertSchedule(t, schedule, created)
+
+			fetched, err := repos.Schedules.GetSchedule(ctx, schedule.ID)
+			require.NoError(t, err)
+			assertSchedule(t, schedule, fetched)
+			assert.Equal(t, map[string]interface{}{"position": float64(100)}, fetched.Parameters)
+		}
+	})
+
+	t.Run("GetScheduleNotFound", func(t *testing.T) {
+		repos := scheduleRepositories(t, factory)
+
+		_, err := repos.Schedules.GetSchedule(ctx, uuid.New())
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("ListSchedulesOrdersByCreatedAt", func(t *testing.T) {
+		repos := scheduleRepositories(t, factory)
+		later := mustCreateSchedule(t, repos, newSchedule("Later", baseTime.Add(time.Minute)))
+		earlier := mustCreateSchedule(t, repos, newSchedule("Earlier", baseTime))
+
+		schedules, err := repos.Schedules.ListSchedules(ctx)
+		require.NoError(t, err)
+		require.Len(t, schedules, 2)
+		assert.Equal(t, earlier.ID, schedules[0].ID)
+		assert.Equal(t, later.ID, schedules[1].ID)
+	})
+
+	t.Run("UpdateSchedule", func(t *testing.T) {
+		repos := scheduleRepositories(t, factory)
+		schedule := mustCreateSchedule(t, repos, newSchedule("Morning blinds", baseTime))
+
+		lastRunAt := baseTime.Add(time.Hour)
+		schedule.Name = "Weekday blinds"
+		schedule.Cron = ""
+		schedule.Interval = time.Hour
+		schedule.Timezone = "America/New_York"
+		schedule.Paused = true
+		schedule.NextRunAt = baseTime.Add(2 * time.Hour)
+		schedule.LastRunAt = &lastRunAt
+		schedule.UpdatedAt = baseTime.Add(time.Hour)
+		updated, err := repos.Schedules.UpdateSchedule(ctx, schedule)
+		require.NoError(t, err)
+		assertSchedule(t, schedule, updated)
+
+		schedule.ID = uuid.New()
+		_, err = repos.Schedules.UpdateSchedule(ctx, schedule)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("DeleteScheduleRemovesRuns", func(t *testing.T) {
+		repos := scheduleRepositories(t, factory)
+		schedule := mustCreateSchedule(t, repos, newSchedule("Morning blinds", baseTime))
+		require.NoError(t, repos.Schedules.CreateRun(ctx, newScheduleRun(schedule.ID, baseTime)))
+
+		require.NoError(t, repos.Schedules.DeleteSchedule(ctx, schedule.ID))
+
+		runs, err := repos.Schedules.ListRuns(ctx, models.ScheduleRunFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, runs)
+
+		err = repos.Schedules.DeleteSchedule(ctx, schedule.ID)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("ListDueSchedules", func(t *testing.T) {
+		repos := scheduleRepositories(t, factory)
+		second := newSchedule("Second", baseTime)
+		second.NextRunAt = baseTime.Add(-time.Minute)
+		mustCreateSchedule(t, repos, second)
+		first := newSchedule("First", baseTime)
+		first.NextRunAt = baseTime.Add(-time.Hour)
+		mustCreateSchedule(t, repos, first)
+		exact := newSchedule("Exact", baseTime)
+		exact.NextRunAt = baseTime
+		mustCreateSchedule(t, repos, exact)
+		paused := newSchedule("Paused", baseTime)
+		paused.NextRunAt = baseTime.Add(-time.Hour)
+		paused.Paused = true
+		mustCreateSchedule(t, repos, paused)
+		future := newSchedule("Future", baseTime)
+		future.NextRunAt = baseTime.Add(time.Second)
+		mustCreateSchedule(t, repos, future)
+
+		due, err := repos.Schedules.ListDueSchedules(ctx, baseTime, 10)
+		require.NoError(t, err)
+		require.Len(t, due, 3)
+		assert.Equal(t, first.ID, due[0].ID)
+		assert.Equal(t, second.ID, due[1].ID)
+		assert.Equal(t, exact.ID, due[2].ID)
+
+		due, err = repos.Schedules.ListDueSchedules(ctx, baseTime, 1)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		assert.Equal(t, first.ID, due[0].ID)
+	})
+
+	t.Run("AdvanceSchedule", func(t *testing.T) {
+		repos := scheduleRepositories(t, factory)
+		schedule := mustCreateSchedule(t, repos, newSchedule("Morning blinds", baseTime))
+		due := schedule.NextRunAt
+		next := due.Add(24 * time.Hour)
+
+		require.NoError(t, repos.Schedules.AdvanceSchedule(ctx, schedule.ID, due, next))
+
+		fetched, err := repos.Schedules.GetSchedule(ctx, schedule.ID)
+		require.NoError(t, err)
+		assert.True(t, next.Equal(fetched.NextRunAt), "next_run_at: %v != %v", next, fetched.NextRunAt)
+		require.NotNil(t, fetched.LastRunAt)
+		assert.True(t, due.Equal(*fetched.LastRunAt), "last_run_at: %v != %v", due, *fetched.LastRunAt)
+
+		// A second replica that read the schedule before the first advanced
+		// it must not run it again.
+		err = repos.Schedules.AdvanceSchedule(ctx, schedule.ID, due, next)
+		assert.True(t, errors.Is(err, models.ErrVersionConflict), "got %v", err)
+
+		fetched.Paused = true
+		_, err = repos.Schedules.UpdateSchedule(ctx, fetched)
+		require.NoError(t, err)
+		err = repos.Schedules.AdvanceSchedule(ctx, schedule.ID, next, next.Add(24*time.Hour))
+		assert.True(t, errors.Is(err, models.ErrVersionConflict), "got %v", err)
+
+		err = repos.Schedules.AdvanceSchedule(ctx, uuid.New(), due, next)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("CreateAndFinishRun", func(t *testing.T) {
+		repos := scheduleRepositories(t, factory)
+		schedule := mustCreateSchedule(t, repos, newSchedule("Morning blinds", baseTime))
+		run := newScheduleRun(schedule.ID, baseTime)
+		require.NoError(t, repos.Schedules.CreateRun(ctx, run))
+
+		finishedAt := baseTime.Add(time.Second)
+		run.Status = models.ExecutionFailed
+		run.Error = "device offline"
+		run.FinishedAt = &finishedAt
+		require.NoError(t, repos.Schedules.FinishRun(ctx, run))
+
+		runs, err := repos.Schedules.ListRuns(ctx, models.ScheduleRunFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		fetched := runs[0]
+		assert.Equal(t, run.ID, fetched.ID)
+		assert.Equal(t, schedule.ID, fetched.ScheduleID)
+		assert.True(t, run.ScheduledAt.Equal(fetched.ScheduledAt), "scheduled_at: %v != %v", run.ScheduledAt, fetched.ScheduledAt)
+		assert.Equal(t, models.ExecutionFailed, fetched.Status)
+		assert.Equal(t, "device offline", fetched.Error)
+		require.NotNil(t, fetched.FinishedAt)
+		assert.True(t, finishedAt.Equal(*fetched.FinishedAt))
+
+		err = repos.Schedules.FinishRun(ctx, newScheduleRun(schedule.ID, baseTime))
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+
+		err = repos.Schedules.CreateRun(ctx, newScheduleRun(uuid.New(), baseTime))
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("ListRunsPagesNewestFirst", func(t *testing.T) {
+		repos := scheduleRepositories(t, factory)
+		schedule := mustCreateSchedule(t, repos, newSchedule("Morning blinds", baseTime))
+		other := mustCreateSchedule(t, repos, newSchedule("Poll meter", baseTime))
+
+		var started []*models.ScheduleRun
+		for i := 0; i < 3; i++ {
+			run := newScheduleRun(schedule.ID, baseTime.Add(time.Duration(i)*time.Minute))
+			require.NoError(t, repos.Schedules.CreateRun(ctx, run))
+			started = append(started, run)
+		}
+		require.NoError(t, repos.Schedules.CreateRun(ctx, newScheduleRun(other.ID, baseTime.Add(time.Hour))))
+
+		page, err := repos.Schedules.ListRuns(ctx, models.ScheduleRunFilter{ScheduleID: &schedule.ID, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, started[2].ID, page[0].ID)
+		assert.Equal(t, started[1].ID, page[1].ID)
+
+		cursor := &models.PageCursor{CreatedAt: page[1].StartedAt, ID: page[1].ID}
+		rest, err := repos.Schedules.ListRuns(ctx, models.ScheduleRunFilter{ScheduleID: &schedule.ID, After: cursor, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, rest, 1)
+		assert.Equal(t, started[0].ID, rest[0].ID)
+
+		all, err := repos.Schedules.ListRuns(ctx, models.ScheduleRunFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, all, 4)
+	})
+
+	t.Run("PruneRuns", func(t *testing.T) {
+		repos := scheduleRepositories(t, factory)
+		schedule := mustCreateSchedule(t, repos, newSchedule("Morning blinds", baseTime))
+		require.NoError(t, repos.Schedules.CreateRun(ctx, newScheduleRun(schedule.ID, baseTime)))
+		kept := newScheduleRun(schedule.ID, baseTime.Add(time.Hour))
+		require.NoError(t, repos.Schedules.CreateRun(ctx, kept))
+
+		pruned, err := repos.Schedules.PruneRuns(ctx, baseTime.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), pruned)
+
+		runs, err := repos.Schedules.ListRuns(ctx, models.ScheduleRunFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, kept.ID, runs[0].ID)
+	})
+}
+
+func scheduleRepositories(t *testing.T, factory Factory) Repositories {
+	t.Helper()
+	repos := factory(t)
+	if repos.Schedules == nil {
+		t.Skip("no schedule repository")
+	}
+	return repos
+}
+
+func newSchedule(name string, createdAt time.Time) *models.Schedule {
+	return &models.Schedule{
+		ID:         uuid.New(),
+		Name:       name,
+		Cron:       "30 7 * * 1-5",
+		Timezone:   "Europe/Berlin",
+		DeviceID:   "blinds-1",
+		FeatureID:  uuid.New(),
+		Parameters: map[string]interface{}{"position": 100},
+		NextRunAt:  createdAt.Add(time.Hour),
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+}
+
+func mustCreateSchedule(t *testing.T, repos Repositories, schedule *models.Schedule) *models.Schedule {
+	t.Helper()
+	created, err := repos.Schedules.CreateSchedule(context.Background(), schedule)
+	require.NoError(t, err)
+	return created
+}
+
+func newScheduleRun(scheduleID uuid.UUID, startedAt time.Time) *models.ScheduleRun {
+	return &models.ScheduleRun{
+		ID:          uuid.New(),
+		ScheduleID:  scheduleID,
+		ScheduledAt: startedAt.Add(-time.Second),
+		Status:      models.ExecutionRunning,
+		StartedAt:   startedAt,
+	}
+}
+
+func assertSchedule(t *testing.T, expected, actual *models.Schedule) {
+	t.Helper()
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Description, actual.Description)
+	assert.Equal(t, expected.Cron, actual.Cron)
+	assert.Equal(t, expected.Interval, actual.Interval)
+	assert.Equal(t, expected.Timezone, actual.Timezone)
+	assert.Equal(t, expected.DeviceID, actual.DeviceID)
+	assert.Equal(t, expected.FeatureID, actual.FeatureID)
+	assert.Equal(t, expected.Paused, actual.Paused)
+	assert.True(t, expected.NextRunAt.Equal(actual.NextRunAt), "next_run_at: %v != %v", expected.NextRunAt, actual.NextRunAt)
+	if expected.LastRunAt == nil {
+		assert.Nil(t, actual.LastRunAt)
+	} else if assert.NotNil(t, actual.LastRunAt) {
+		assert.True(t, expected.LastRunAt.Equal(*actual.LastRunAt), "last_run_at: %v != %v", *expected.LastRunAt, *actual.LastRunAt)
+	}
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "created_at: %v != %v", expected.CreatedAt, actual.CreatedAt)
+	assert.True(t, expected.UpdatedAt.Equal(actual.UpdatedAt), "updated_at: %v != %v", expected.UpdatedAt, actual.UpdatedAt)
+}
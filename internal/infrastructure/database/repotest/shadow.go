@@ -0,0 +1,156 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func RunShadowRepositoryTests(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	t.Run("SaveAndGetShadow", func(t *testing.T) {
+		repos := shadowRepositories(t, factory)
+		shadow := newShadow("dev-1", 1)
+		shadow.Desired = map[string]interface{}{"power": "on", "level": 3}
+
+		require.NoError(t, repos.Shadows.SaveShadow(ctx, shadow, 0))
+
+		fetched, err := repos.Shadows.GetShadow(ctx, "dev-1")
+		require.NoError(t, err)
+		assert.Equal(t, "dev-1", fetched.DeviceID)
+		assert.Equal(t, map[string]interface{}{"power": "on", "level": float64(3)}, fetched.Desired)
+		assert.Empty(t, fetched.Reported)
+		assert.NotNil(t, fetched.Reported, "an empty section must come back as an empty object")
+		assert.Equal(t, int64(1), fetched.Version)
+		assert.True(t, baseTime.Equal(fetched.CreatedAt))
+	})
+
+	t.Run("GetShadowNotFound", func(t *testing.T) {
+		repos := shadowRepositories(t, factory)
+
+		_, err := repos.Shadows.GetShadow(ctx, "missing")
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("SaveShadowChecksVersion", func(t *testing.T) {
+		repos := shadowRepositories(t, factory)
+		require.NoError(t, repos.Shadows.SaveShadow(ctx, newShadow("dev-1", 1), 0))
+
+		err := repos.Shadows.SaveShadow(ctx, newShadow("dev-1", 1), 0)
+		assert.True(t, errors.Is(err, models.ErrVersionConflict), "insert over an existing shadow: got %v", err)
+
+		err = repos.Shadows.SaveShadow(ctx, newShadow("dev-2", 2), 1)
+		assert.True(t, errors.Is(err, models.ErrVersionConflict), "update of a missing shadow: got %v", err)
+
+		updated := newShadow("dev-1", 2)
+		updated.Reported = map[string]interface{}{"power": "off"}
+		updated.UpdatedAt = baseTime.Add(time.Minute)
+		require.NoError(t, repos.Shadows.SaveShadow(ctx, updated, 1))
+
+		err = repos.Shadows.SaveShadow(ctx, newShadow("dev-1", 2), 1)
+		assert.True(t, errors.Is(err, models.ErrVersionConflict), "stale update: got %v", err)
+
+		fetched, err := repos.Shadows.GetShadow(ctx, "dev-1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), fetched.Version)
+		assert.Equal(t, map[string]interface{}{"power": "off"}, fetched.Reported)
+		assert.True(t, baseTime.Add(time.Minute).Equal(fetched.UpdatedAt))
+	})
+
+	t.Run("ListShadows", func(t *testing.T) {
+		repos := shadowRepositories(t, factory)
+		for _, deviceID := range []string{"dev-3", "dev-1", "dev-2"} {
+			require.NoError(t, repos.Shadows.SaveShadow(ctx, newShadow(deviceID, 1), 0))
+		}
+
+		all, err := repos.Shadows.ListShadows(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+		assert.Equal(t, "dev-1", all[0].DeviceID)
+		assert.Equal(t, "dev-3", all[2].DeviceID)
+
+		some, err := repos.Shadows.ListShadows(ctx, []string{"dev-3", "dev-2", "dev-9"})
+		require.NoError(t, err)
+		require.Len(t, some, 2)
+		assert.Equal(t, "dev-2", some[0].DeviceID)
+	})
+
+	t.Run("DeleteShadow", func(t *testing.T) {
+		repos := shadowRepositories(t, factory)
+		require.NoError(t, repos.Shadows.SaveShadow(ctx, newShadow("dev-1", 1), 0))
+
+		require.NoError(t, repos.Shadows.DeleteShadow(ctx, "dev-1"))
+		_, err := repos.Shadows.GetShadow(ctx, "dev-1")
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+
+		err = repos.Shadows.DeleteShadow(ctx, "dev-1")
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+
+		require.NoError(t, repos.Shadows.SaveShadow(ctx, newShadow("dev-1", 1), 0), "a deleted shadow can be created again")
+	})
+
+	t.Run("DeltaLog", func(t *testing.T) {
+		repos := shadowRepositories(t, factory)
+
+		head, err := repos.Shadows.LatestDeltaSequence(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, head)
+
+		first := &models.ShadowDelta{DeviceID: "dev-1", Version: 1, Delta: map[string]interface{}{"power": "on"}, CreatedAt: baseTime}
+		second := &models.ShadowDelta{DeviceID: "dev-2", Version: 4, Delta: map[string]interface{}{}, CreatedAt: baseTime.Add(time.Hour)}
+		require.NoError(t, repos.Shadows.AddDelta(ctx, first))
+		require.NoError(t, repos.Shadows.AddDelta(ctx, second))
+		assert.Greater(t, second.Sequence, first.Sequence)
+
+		deltas, err := repos.Shadows.ListDeltasSince(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, deltas, 2)
+		assert.Equal(t, first.Sequence, deltas[0].Sequence)
+		assert.Equal(t, "dev-1", deltas[0].DeviceID)
+		assert.Equal(t, map[string]interface{}{"power": "on"}, deltas[0].Delta)
+		assert.Equal(t, int64(4), deltas[1].Version)
+		assert.Empty(t, deltas[1].Delta)
+
+		deltas, err = repos.Shadows.ListDeltasSince(ctx, first.Sequence, 10)
+		require.NoError(t, err)
+		require.Len(t, deltas, 1)
+		assert.Equal(t, second.Sequence, deltas[0].Sequence)
+
+		head, err = repos.Shadows.LatestDeltaSequence(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, second.Sequence, head)
+
+		pruned, err := repos.Shadows.PruneDeltas(ctx, baseTime.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), pruned)
+
+		oldest, err := repos.Shadows.OldestDeltaSequence(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, second.Sequence, oldest)
+	})
+}
+
+func shadowRepositories(t *testing.T, factory Factory) Repositories {
+	t.Helper()
+	repos := factory(t)
+	if repos.Shadows == nil {
+		t.Skip("no shadow repository")
+	}
+	return repos
+}
+
+func newShadow(deviceID string, version int64) *models.DeviceShadow {
+	return &models.DeviceShadow{
+		DeviceID:  deviceID,
+		Desired:   map[string]interface{}{},
+		Reported:  map[string]interface{}{},
+		Version:   version,
+		CreatedAt: baseTime,
+		UpdatedAt: baseTime,
+	}
+}
@@ -0,0 +1,252 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func RunTelemetryRepositoryTests(t *testing.T, factory Factory) {
+	ctx := context.Background()
+
+	t.Run("AddReadingsSkipsDuplicates", func(t *testing.T) {
+		repos := telemetryRepositories(t, factory)
+		featureID := uuid.New()
+
+		stored, err := repos.Telemetry.AddReadings(ctx, []*models.TelemetryReading{
+			newReading("dev-1", featureID, baseTime, 1),
+			newReading("dev-2", featureID, baseTime, 2),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, stored)
+
+		stored, err = repos.Telemetry.AddReadings(ctx, []*models.TelemetryReading{
+			newReading("dev-1", featureID, baseTime, 10),
+			newReading("dev-1", featureID, baseTime.Add(time.Second), 3),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, stored)
+
+		readings := mustListReadings(t, repos, models.ReadingQuery{FeatureID: featureID})
+		require.Len(t, readings, 3)
+		assert.Equal(t, 1.0, readings[0].Value, "a duplicate must not overwrite the stored value")
+	})
+
+	t.Run("AddReadingsAcrossDays", func(t *testing.T) {
+		repos := telemetryRepositories(t, factory)
+		featureID := uuid.New()
+
+		stored, err := repos.Telemetry.AddReadings(ctx, []*models.TelemetryReading{
+			newReading("dev-1", featureID, baseTime.Add(-48*time.Hour), 1),
+			newReading("dev-1", featureID, baseTime, 2),
+			newReading("dev-1", featureID, baseTime.Add(30*time.Hour), 3),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, stored)
+		assert.Len(t, mustListReadings(t, repos, models.ReadingQuery{FeatureID: featureID}), 3)
+	})
+
+	t.Run("ListReadings", func(t *testing.T) {
+		repos := telemetryRepositories(t, factory)
+		featureID := uuid.New()
+		mustAddReadings(t, repos,
+			newReading("dev-2", featureID, baseTime, 2),
+			newReading("dev-1", featureID, baseTime, 1),
+			newReading("dev-1", featureID, baseTime.Add(-time.Second), 0),
+			newReading("dev-1", featureID, baseTime.Add(time.Minute), 3),
+			newReading("dev-1", uuid.New(), baseTime, 9),
+		)
+
+		readings := mustListReadings(t, repos, models.ReadingQuery{
+			FeatureID: featureID,
+			From:      baseTime,
+			To:        baseTime.Add(time.Minute),
+		})
+		require.Len(t, readings, 2)
+		assert.Equal(t, "dev-1", readings[0].DeviceID)
+		assert.Equal(t, featureID, readings[0].FeatureID)
+		assert.True(t, baseTime.Equal(readings[0].Timestamp), "timestamp %v, want %v", readings[0].Timestamp, baseTime)
+		assert.Equal(t, 1.0, readings[0].Value)
+		assert.Equal(t, "dev-2", readings[1].DeviceID)
+
+		readings = mustListReadings(t, repos, models.ReadingQuery{FeatureID: featureID, DeviceID: "dev-1"})
+		assert.Equal(t, []float64{0, 1, 3}, readingValues(readings))
+
+		readings = mustListReadings(t, repos, models.ReadingQuery{FeatureID: featureID, Limit: 2})
+		assert.Equal(t, []float64{0, 1}, readingValues(readings))
+	})
+
+	t.Run("Downsample", func(t *testing.T) {
+		repos := telemetryRepositories(t, factory)
+		featureID := uuid.New()
+		mustAddReadings(t, repos,
+			newReading("dev-1", featureID, baseTime.Add(10*time.Second), 1),
+			newReading("dev-2", featureID, baseTime.Add(20*time.Second), 3),
+			newReading("dev-1", featureID, baseTime.Add(50*time.Second), 8),
+			newReading("dev-1", featureID, baseTime.Add(3*time.Minute), 5),
+			newReading("dev-1", featureID, baseTime.Add(4*time.Minute), 7),
+		)
+		query := models.ReadingQuery{FeatureID: featureID, From: baseTime, To: baseTime.Add(time.Hour), Limit: 10}
+
+		buckets, err := repos.Telemetry.Downsample(ctx, query, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, buckets, 3)
+		assert.True(t, baseTime.Equal(buckets[0].Start), "start %v, want %v", buckets[0].Start, baseTime)
+		assert.Equal(t, int64(3), buckets[0].Count)
+		assert.InDelta(t, 4.0, buckets[0].Avg, 1e-9)
+		assert.Equal(t, 1.0, buckets[0].Min)
+		assert.Equal(t, 8.0, buckets[0].Max)
+		assert.True(t, baseTime.Add(3*time.Minute).Equal(buckets[1].Start), "start %v", buckets[1].Start)
+		assert.True(t, baseTime.Add(4*time.Minute).Equal(buckets[2].Start), "start %v", buckets[2].Start)
+
+		query.DeviceID = "dev-2"
+		buckets, err = repos.Telemetry.Downsample(ctx, query, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		assert.Equal(t, int64(1), buckets[0].Count)
+
+		query.DeviceID = ""
+		query.Limit = 1
+		buckets, err = repos.Telemetry.Downsample(ctx, query, 2*time.Minute)
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		assert.Equal(t, int64(3), buckets[0].Count)
+	})
+
+	t.Run("DeleteReadings", func(t *testing.T) {
+		repos := telemetryRepositories(t, factory)
+		kept, expired := uuid.New(), uuid.New()
+		for _, featureID := range []uuid.UUID{kept, expired} {
+			mustAddReadings(t, repos,
+				newReading("dev-1", featureID, baseTime.Add(-72*time.Hour), 1),
+				newReading("dev-1", featureID, baseTime.Add(-time.Hour), 2),
+				newReading("dev-1", featureID, baseTime, 3),
+			)
+		}
+
+		require.NoError(t, repos.Telemetry.DeleteReadings(ctx, models.ReadingDeleteFilter{Before: baseTime.Add(-48 * time.Hour)}))
+		assert.Equal(t, []float64{2, 3}, readingValues(mustListReadings(t, repos, models.ReadingQuery{FeatureID: kept})))
+
+		require.NoError(t, repos.Telemetry.DeleteReadings(ctx, models.ReadingDeleteFilter{Before: baseTime, FeatureID: &expired}))
+		assert.Equal(t, []float64{3}, readingValues(mustListReadings(t, repos, models.ReadingQuery{FeatureID: expired})))
+		assert.Equal(t, []float64{2, 3}, readingValues(mustListReadings(t, repos, models.ReadingQuery{FeatureID: kept})))
+
+		other := uuid.New()
+		mustAddReadings(t, repos, newReading("dev-1", other, baseTime.Add(-time.Hour), 4))
+		require.NoError(t, repos.Telemetry.DeleteReadings(ctx, models.ReadingDeleteFilter{
+			Before:           baseTime,
+			ExceptFeatureIDs: []uuid.UUID{kept},
+		}))
+		assert.Equal(t, []float64{2, 3}, readingValues(mustListReadings(t, repos, models.ReadingQuery{FeatureID: kept})))
+		assert.Empty(t, mustListReadings(t, repos, models.ReadingQuery{FeatureID: other}))
+	})
+
+	t.Run("RetentionPolicies", func(t *testing.T) {
+		repos := telemetryRepositories(t, factory)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		feature := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+
+		policy := &models.RetentionPolicy{FeatureID: feature.ID, Retention: 48 * time.Hour, UpdatedAt: baseTime}
+		stored, err := repos.Telemetry.SetRetentionPolicy(ctx, policy)
+		require.NoError(t, err)
+		assertRetentionPolicy(t, policy, stored)
+
+		policy = &models.RetentionPolicy{FeatureID: feature.ID, Retention: 7 * 24 * time.Hour, UpdatedAt: baseTime.Add(time.Hour)}
+		stored, err = repos.Telemetry.SetRetentionPolicy(ctx, policy)
+		require.NoError(t, err)
+		assertRetentionPolicy(t, policy, stored)
+
+		policies, err := repos.Telemetry.ListRetentionPolicies(ctx)
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+		assertRetentionPolicy(t, policy, policies[0])
+
+		require.NoError(t, repos.Telemetry.DeleteRetentionPolicy(ctx, feature.ID))
+		policies, err = repos.Telemetry.ListRetentionPolicies(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, policies)
+
+		err = repos.Telemetry.DeleteRetentionPolicy(ctx, feature.ID)
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("RetentionPolicyForMissingFeature", func(t *testing.T) {
+		repos := telemetryRepositories(t, factory)
+
+		_, err := repos.Telemetry.SetRetentionPolicy(ctx, &models.RetentionPolicy{FeatureID: uuid.New(), Retention: time.Hour, UpdatedAt: baseTime})
+		assert.True(t, errors.Is(err, models.ErrNotFound), "got %v", err)
+	})
+
+	t.Run("RetentionPolicyGoesWithFeature", func(t *testing.T) {
+		repos := telemetryRepositories(t, factory)
+		model := mustCreateModel(t, repos, newModel("Thermostat", models.DeviceType, baseTime))
+		feature := mustCreateFeature(t, repos, newFeature(model.ID, "Temperature", baseTime))
+		_, err := repos.Telemetry.SetRetentionPolicy(ctx, &models.RetentionPolicy{FeatureID: feature.ID, Retention: time.Hour, UpdatedAt: baseTime})
+		require.NoError(t, err)
+
+		require.NoError(t, repos.Features.Delete(ctx, feature.ID.String()))
+
+		policies, err := repos.Telemetry.ListRetentionPolicies(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, policies)
+	})
+}
+
+// telemetryRepositories skips the test when the backend has no
+// TelemetryRepository.
+func telemetryRepositories(t *testing.T, factory Factory) Repositories {
+	t.Helper()
+	repos := factory(t)
+	if repos.Telemetry == nil {
+		t.Skip("no telemetry repository")
+	}
+	return repos
+}
+
+func newReading(deviceID string, featureID uuid.UUID, at time.Time, value float64) *models.TelemetryReading {
+	return &models.TelemetryReading{DeviceID: deviceID, FeatureID: featureID, Timestamp: at, Value: value}
+}
+
+func mustAddReadings(t *testing.T, repos Repositories, readings ...*models.TelemetryReading) {
+	t.Helper()
+	_, err := repos.Telemetry.AddReadings(context.Background(), readings)
+	require.NoError(t, err)
+}
+
+// mustListReadings fills in an open range and a generous limit when query
+// leaves them out.
+func mustListReadings(t *testing.T, repos Repositories, query models.ReadingQuery) []*models.TelemetryReading {
+	t.Helper()
+	if query.From.IsZero() {
+		query.From = baseTime.Add(-365 * 24 * time.Hour)
+	}
+	if query.To.IsZero() {
+		query.To = baseTime.Add(365 * 24 * time.Hour)
+	}
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+	readings, err := repos.Telemetry.ListReadings(context.Background(), query)
+	require.NoError(t, err)
+	return readings
+}
+
+func readingValues(readings []*models.TelemetryReading) []float64 {
+	var values []float64
+	for _, reading := range readings {
+		values = append(values, reading.Value)
+	}
+	return values
+}
+
+func assertRetentionPolicy(t *testing.T, expected, actual *models.RetentionPolicy) {
+	t.Helper()
+	assert.Equal(t, expected.FeatureID, actual.FeatureID)
+	assert.Equal(t, expected.Retention, actual.Retention)
+	assert.True(t, expected.UpdatedAt.Equal(actual.UpdatedAt), "updated_at %v, want %v", actual.UpdatedAt, expected.UpdatedAt)
+}
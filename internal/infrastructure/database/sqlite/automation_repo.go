@@ -0,0 +1,268 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+const automationRuleColumns = `id, name, COALESCE(description, ''), enabled, trigger, condition, actions, timezone, cooldown_seconds, created_at, updated_at`
+
+const automationExecutionColumns = `id, rule_id, trigger, COALESCE(dedup_key, ''), status, COALESCE(error, ''), action_results, started_at, finished_at`
+
+type SQLiteAutomationRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAutomationRepository(db *database.SQLiteDB) *SQLiteAutomationRepository {
+	return &SQLiteAutomationRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteAutomationRepository) CreateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	query := `
+		INSERT INTO automation_rules (id, name, description, enabled, trigger, condition, actions, timezone, cooldown_seconds, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + automationRuleColumns
+
+	trigger, actions, err := encodeRuleJSON(rule)
+	if err != nil {
+		return nil, err
+	}
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		rule.ID.String(),
+		rule.Name,
+		rule.Description,
+		rule.Enabled,
+		trigger,
+		rule.Condition,
+		actions,
+		rule.Timezone,
+		int64(rule.Cooldown/time.Second),
+		formatTime(rule.CreatedAt),
+		formatTime(rule.UpdatedAt),
+	)
+	return scanAutomationRule(row)
+}
+
+func (r *SQLiteAutomationRepository) GetRule(ctx context.Context, id uuid.UUID) (*models.AutomationRule, error) {
+	query := `SELECT ` + automationRuleColumns + ` FROM automation_rules WHERE id = ?`
+
+	return scanAutomationRule(database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteAutomationRepository) ListRules(ctx context.Context) ([]*models.AutomationRule, error) {
+	query := `SELECT ` + automationRuleColumns + ` FROM automation_rules ORDER BY created_at, id`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*models.AutomationRule
+	for rows.Next() {
+		rule, err := scanAutomationRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *SQLiteAutomationRepository) UpdateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	query := `
+		UPDATE automation_rules
+		SET name = ?, description = ?, enabled = ?, trigger = ?, condition = ?, actions = ?,
+			timezone = ?, cooldown_seconds = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING ` + automationRuleColumns
+
+	trigger, actions, err := encodeRuleJSON(rule)
+	if err != nil {
+		return nil, err
+	}
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		rule.Name,
+		rule.Description,
+		rule.Enabled,
+		trigger,
+		rule.Condition,
+		actions,
+		rule.Timezone,
+		int64(rule.Cooldown/time.Second),
+		formatTime(rule.UpdatedAt),
+		rule.ID.String(),
+	)
+	return scanAutomationRule(row)
+}
+
+func (r *SQLiteAutomationRepository) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM automation_rules WHERE id = ?`, id.String())
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func (r *SQLiteAutomationRepository) CreateExecution(ctx context.Context, execution *models.AutomationExecution) error {
+	query := `
+		INSERT INTO automation_executions (id, rule_id, trigger, dedup_key, status, error, action_results, started_at, finished_at)
+		VALUES (?, ?, ?, NULLIF(?, ''), ?, NULLIF(?, ''), ?, ?, ?)
+	`
+
+	trigger, err := encodeJSONValue(jsonObjectOrEmpty(execution.Trigger))
+	if err != nil {
+		return err
+	}
+	results, err := encodeActionResults(execution.Results)
+	if err != nil {
+		return err
+	}
+	_, err = database.SQLConn(ctx, r.db).ExecContext(ctx, query,
+		execution.ID.String(),
+		execution.RuleID.String(),
+		trigger,
+		execution.DedupKey,
+		execution.Status,
+		execution.Error,
+		results,
+		formatTime(execution.StartedAt),
+		formatNullTime(execution.FinishedAt),
+	)
+	return mapError(err)
+}
+
+func (r *SQLiteAutomationRepository) FinishExecution(ctx context.Context, execution *models.AutomationExecution) error {
+	query := `
+		UPDATE automation_executions
+		SET status = ?, error = NULLIF(?, ''), action_results = ?, finished_at = ?
+		WHERE id = ?
+	`
+
+	results, err := encodeActionResults(execution.Results)
+	if err != nil {
+		return err
+	}
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, query,
+		execution.Status,
+		execution.Error,
+		results,
+		formatNullTime(execution.FinishedAt),
+		execution.ID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func (r *SQLiteAutomationRepository) ListExecutions(ctx context.Context, filter models.ExecutionFilter) ([]*models.AutomationExecution, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.RuleID != nil {
+		conditions = append(conditions, "rule_id = ?")
+		args = append(args, filter.RuleID.String())
+	}
+	if filter.After != nil {
+		conditions = append(conditions, "(started_at, id) < (?, ?)")
+		args = append(args, formatTime(filter.After.CreatedAt), filter.After.ID.String())
+	}
+
+	query := `SELECT ` + automationExecutionColumns + ` FROM automation_executions`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY started_at DESC, id DESC LIMIT ?`
+	args = append(args, filter.Limit)
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []*models.AutomationExecution
+	for rows.Next() {
+		var execution models.AutomationExecution
+		err = rows.Scan(
+			&execution.ID,
+			&execution.RuleID,
+			jsonObject{&execution.Trigger},
+			&execution.DedupKey,
+			&execution.Status,
+			&execution.Error,
+			jsonValue{&execution.Results},
+			timestamp{&execution.StartedAt},
+			nullTimestamp{&execution.FinishedAt},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(execution.Results) == 0 {
+			execution.Results = nil
+		}
+		executions = append(executions, &execution)
+	}
+
+	return executions, rows.Err()
+}
+
+func (r *SQLiteAutomationRepository) PruneExecutions(ctx context.Context, before time.Time) (int64, error) {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM automation_executions WHERE started_at < ?`, formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanAutomationRule(row rowScanner) (*models.AutomationRule, error) {
+	var rule models.AutomationRule
+	var seconds int64
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.Description,
+		&rule.Enabled,
+		jsonValue{&rule.Trigger},
+		&rule.Condition,
+		jsonValue{&rule.Actions},
+		&rule.Timezone,
+		&seconds,
+		timestamp{&rule.CreatedAt},
+		timestamp{&rule.UpdatedAt},
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	rule.Cooldown = time.Duration(seconds) * time.Second
+	return &rule, nil
+}
+
+func encodeRuleJSON(rule *models.AutomationRule) (string, string, error) {
+	trigger, err := encodeJSONValue(rule.Trigger)
+	if err != nil {
+		return "", "", err
+	}
+	actions := rule.Actions
+	if actions == nil {
+		actions = []models.RuleAction{}
+	}
+	encoded, err := encodeJSONValue(actions)
+	return trigger, encoded, err
+}
+
+func encodeActionResults(results []models.ActionResult) (string, error) {
+	if results == nil {
+		results = []models.ActionResult{}
+	}
+	return encodeJSONValue(results)
+}
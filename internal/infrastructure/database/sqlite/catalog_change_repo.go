@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type SQLiteCatalogChangeRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteCatalogChangeRepository(db *database.SQLiteDB) *SQLiteCatalogChangeRepository {
+	return &SQLiteCatalogChangeRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteCatalogChangeRepository) ListSince(ctx context.Context, after int64, limit int) ([]*models.CatalogChange, error) {
+	query := `
+		SELECT sequence, entity, operation, entity_id, model_id, old_row, new_row, changed_at
+		FROM catalog_changes
+		WHERE sequence > ?
+		ORDER BY sequence
+		LIMIT ?
+	`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*models.CatalogChange
+	for rows.Next() {
+		var change models.CatalogChange
+		var oldRow, newRow sql.NullString
+		err = rows.Scan(
+			&change.Sequence,
+			&change.Entity,
+			&change.Operation,
+			&change.EntityID,
+			&change.ModelID,
+			&oldRow,
+			&newRow,
+			timestamp{&change.ChangedAt},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := decodeChangeRows(&change, oldRow, newRow); err != nil {
+			return nil, err
+		}
+		changes = append(changes, &change)
+	}
+
+	return changes, rows.Err()
+}
+
+func (r *SQLiteCatalogChangeRepository) LatestSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := database.SQLConn(ctx, r.db).QueryRowContext(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM catalog_changes`).Scan(&sequence)
+	return sequence, err
+}
+
+func (r *SQLiteCatalogChangeRepository) OldestSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := database.SQLConn(ctx, r.db).QueryRowContext(ctx, `SELECT COALESCE(MIN(sequence), 0) FROM catalog_changes`).Scan(&sequence)
+	return sequence, err
+}
+
+func (r *SQLiteCatalogChangeRepository) Prune(ctx context.Context, before time.Time) (int64, error) {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM catalog_changes WHERE changed_at < ?`, formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// decodeChangeRows unmarshals the json_object snapshots written by the
+// catalog change triggers into the entity type of the change.
+func decodeChangeRows(change *models.CatalogChange, oldRow, newRow sql.NullString) error {
+	switch change.Entity {
+	case models.SmartModelAggregate:
+		return decodeRows(oldRow, newRow, &change.OldModel, &change.NewModel)
+	case models.SmartFeatureAggregate:
+		return decodeRows(oldRow, newRow, &change.OldFeature, &change.NewFeature)
+	}
+	return nil
+}
+
+func decodeRows[T any](oldRow, newRow sql.NullString, oldValue, newValue **T) error {
+	if oldRow.Valid {
+		*oldValue = new(T)
+		if err := json.Unmarshal([]byte(oldRow.String), *oldValue); err != nil {
+			return err
+		}
+	}
+	if newRow.Valid {
+		*newValue = new(T)
+		if err := json.Unmarshal([]byte(newRow.String), *newValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SQLiteChangeListener polls for new catalog changes, since SQLite has no
+// LISTEN/NOTIFY. It calls onChange when it starts and whenever the latest
+// sequence moves.
+type SQLiteChangeListener struct {
+	changes  *SQLiteCatalogChangeRepository
+	interval time.Duration
+}
+
+func NewSQLiteChangeListener(db *database.SQLiteDB, interval time.Duration) *SQLiteChangeListener {
+	return &SQLiteChangeListener{
+		changes:  NewSQLiteCatalogChangeRepository(db),
+		interval: interval,
+	}
+}
+
+func (l *SQLiteChangeListener) Listen(ctx context.Context, onChange func()) error {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	var last int64 = -1
+	for {
+		latest, err := l.changes.LatestSequence(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			logger.Warn("Catalog change poll failed", err)
+		case err == nil && latest != last:
+			last = latest
+			onChange()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func newTestModel() *models.SmartModel {
+	now := time.Now()
+	return &models.SmartModel{
+		ID:          uuid.New(),
+		Name:        "Thermostat",
+		Description: "Smart thermostat",
+		Type:        models.DeviceType,
+		Category:    models.WearableCategory,
+		Metadata:    map[string]interface{}{"firmware": "1.0.0"},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func newTestFeature(modelID uuid.UUID) *models.SmartFeature {
+	now := time.Now()
+	return &models.SmartFeature{
+		ID:            uuid.New(),
+		ModelID:       modelID,
+		Name:          "Temperature",
+		Description:   "Current temperature",
+		Protocol:      models.RestProtocol,
+		InterfacePath: "/temperature",
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func TestSQLiteCatalogChangeRepository_RecordsChanges(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	modelRepo := NewSQLiteSmartModelRepository(db)
+	featureRepo := NewSQLiteSmartFeatureRepository(db)
+	changes := NewSQLiteCatalogChangeRepository(db)
+
+	model, err := modelRepo.Create(ctx, newTestModel())
+	require.NoError(t, err)
+	feature, err := featureRepo.Create(ctx, newTestFeature(model.ID))
+	require.NoError(t, err)
+	model.Name = "Smart Thermostat"
+	_, err = modelRepo.Update(ctx, model)
+	require.NoError(t, err)
+	require.NoError(t, modelRepo.Delete(ctx, model.ID.String()))
+
+	list, err := changes.ListSince(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 5)
+
+	assert.Equal(t, models.ChangeCreated, list[0].Operation)
+	require.NotNil(t, list[0].NewModel)
+	assert.Equal(t, "Thermostat", list[0].NewModel.Name)
+	assert.Equal(t, "1.0.0", list[0].NewModel.Metadata["firmware"])
+	assert.True(t, model.CreatedAt.Equal(list[0].NewModel.CreatedAt))
+
+	assert.Equal(t, models.SmartFeatureAggregate, list[1].Entity)
+	assert.Equal(t, feature.ID, list[1].EntityID)
+	assert.Equal(t, model.ID, list[1].ModelID)
+
+	assert.Equal(t, models.ChangeUpdated, list[2].Operation)
+	assert.Equal(t, "Thermostat", list[2].OldModel.Name)
+	assert.Equal(t, "Smart Thermostat", list[2].NewModel.Name)
+
+	// Deleting the model also records the cascaded feature delete.
+	operations := map[models.AggregateType]models.ChangeOperation{
+		list[3].Entity: list[3].Operation,
+		list[4].Entity: list[4].Operation,
+	}
+	assert.Equal(t, models.ChangeDeleted, operations[models.SmartModelAggregate])
+	assert.Equal(t, models.ChangeDeleted, operations[models.SmartFeatureAggregate])
+
+	latest, err := changes.LatestSequence(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, list[4].Sequence, latest)
+
+	pruned, err := changes.Prune(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), pruned)
+
+	oldest, err := changes.OldestSequence(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), oldest)
+}
+
+func TestSQLiteChangeListener_CallsOnChange(t *testing.T) {
+	db := setupTestDB(t)
+	listener := NewSQLiteChangeListener(db, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notified := make(chan struct{}, 10)
+	go func() {
+		_ = listener.Listen(ctx, func() { notified <- struct{}{} })
+	}()
+
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("listener did not start")
+	}
+
+	_, err := NewSQLiteSmartModelRepository(db).Create(context.Background(), newTestModel())
+	require.NoError(t, err)
+
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("listener was not notified")
+	}
+}
@@ -0,0 +1,118 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// timeLayout stores timestamps as fixed-width UTC text, so comparing and
+// ordering the column as text matches comparing the times.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func formatNullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+// timestamp scans a column written by formatTime.
+type timestamp struct {
+	dest *time.Time
+}
+
+func (ts timestamp) Scan(value interface{}) error {
+	text, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("sqlite: cannot scan %T into time", value)
+	}
+	parsed, err := time.Parse(timeLayout, text)
+	if err != nil {
+		return err
+	}
+	*ts.dest = parsed
+	return nil
+}
+
+// nullTimestamp scans a nullable column written by formatNullTime.
+type nullTimestamp struct {
+	dest **time.Time
+}
+
+func (ts nullTimestamp) Scan(value interface{}) error {
+	if value == nil {
+		*ts.dest = nil
+		return nil
+	}
+	var parsed time.Time
+	if err := (timestamp{dest: &parsed}).Scan(value); err != nil {
+		return err
+	}
+	*ts.dest = &parsed
+	return nil
+}
+
+// encodeJSON renders a JSON column value; a nil map is stored as NULL like
+// pgx does for JSONB.
+func encodeJSON(value map[string]interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// jsonObject scans a nullable JSON column into a map.
+type jsonObject struct {
+	dest *map[string]interface{}
+}
+
+func (j jsonObject) Scan(value interface{}) error {
+	var text sql.NullString
+	if err := text.Scan(value); err != nil {
+		return err
+	}
+	if !text.Valid {
+		*j.dest = nil
+		return nil
+	}
+	return json.Unmarshal([]byte(text.String), j.dest)
+}
+
+// encodeIDList passes a list of IDs as one JSON array parameter, to be
+// expanded with json_each.
+func encodeIDList(ids []string) (string, error) {
+	if ids == nil {
+		ids = []string{}
+	}
+	data, err := json.Marshal(ids)
+	return string(data), err
+}
+
+// encodeJSONValue renders a NOT NULL JSON column of any shape.
+func encodeJSONValue(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+// jsonValue scans a JSON column into any value json.Unmarshal accepts.
+type jsonValue struct {
+	dest interface{}
+}
+
+func (j jsonValue) Scan(value interface{}) error {
+	text, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("sqlite: cannot scan %T into JSON", value)
+	}
+	return json.Unmarshal([]byte(text), j.dest)
+}
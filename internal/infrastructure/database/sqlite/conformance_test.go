@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/common/database/migrations"
+	"smart-hub/internal/infrastructure/database/repotest"
+	"testing"
+)
+
+// setupTestDB returns a migrated database in a temporary file.
+func setupTestDB(t *testing.T) *database.SQLiteDB {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "smart-hub.db")
+	require.NoError(t, migrations.RunSQLiteMigrations(path))
+
+	db, err := database.NewSQLiteDatabase(context.Background(), &database.SQLiteConfig{Path: path})
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	return db
+}
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		db := setupTestDB(t)
+		return repotest.Repositories{
+			Models:        NewSQLiteSmartModelRepository(db),
+			Features:      NewSQLiteSmartFeatureRepository(db),
+			Telemetry:     NewSQLiteTelemetryRepository(db),
+			Shadows:       NewSQLiteShadowRepository(db),
+			Automations:   NewSQLiteAutomationRepository(db),
+			Schedules:     NewSQLiteScheduleRepository(db),
+			Scenes:        NewSQLiteSceneRepository(db),
+			Relationships: NewSQLiteRelationshipRepository(db),
+			Dependencies:  NewSQLiteFeatureDependencyRepository(db),
+			Capabilities:  NewSQLiteFeatureCapabilityRepository(db),
+			UnitOfWork:    NewSQLiteUnitOfWork(db),
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+	"smart-hub/internal/domain/models"
+)
+
+// mapError translates driver errors into the domain errors the
+// repositories promise: a missing row, or a reference to one, is
+// models.ErrNotFound, and a duplicate unique key is models.ErrAlreadyExists.
+func mapError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrNotFound
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY {
+		return fmt.Errorf("%w: %s", models.ErrNotFound, sqliteErr.Error())
+	}
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return fmt.Errorf("%w: %s", models.ErrAlreadyExists, sqliteErr.Error())
+	}
+
+	return err
+}
+
+// notFoundIfNoRows returns models.ErrNotFound when a statement affected no
+// rows.
+func notFoundIfNoRows(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
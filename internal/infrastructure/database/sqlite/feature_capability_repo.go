@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+)
+
+const featureCapabilityColumns = `c.feature_id, f.model_id, c.capability, c.parameter_mapping`
+
+type SQLiteFeatureCapabilityRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteFeatureCapabilityRepository(db *database.SQLiteDB) *SQLiteFeatureCapabilityRepository {
+	return &SQLiteFeatureCapabilityRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteFeatureCapabilityRepository) SetFeatureCapability(ctx context.Context, capability *models.FeatureCapability) error {
+	query := `
+		INSERT INTO feature_capabilities (feature_id, capability, parameter_mapping)
+		VALUES (?, ?, ?)
+		ON CONFLICT (feature_id) DO UPDATE
+		SET capability = excluded.capability, parameter_mapping = excluded.parameter_mapping
+	`
+
+	mapping := capability.ParameterMapping
+	if mapping == nil {
+		mapping = map[string]string{}
+	}
+	encoded, err := encodeJSONValue(mapping)
+	if err != nil {
+		return err
+	}
+	_, err = database.SQLConn(ctx, r.db).ExecContext(ctx, query, capability.FeatureID.String(), capability.Capability, encoded)
+	return mapError(err)
+}
+
+func (r *SQLiteFeatureCapabilityRepository) GetFeatureCapability(ctx context.Context, featureID uuid.UUID) (*models.FeatureCapability, error) {
+	query := `
+		SELECT ` + featureCapabilityColumns + `
+		FROM feature_capabilities c
+		JOIN smart_features f ON f.id = c.feature_id
+		WHERE c.feature_id = ?
+	`
+
+	capability, err := scanFeatureCapability(database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, featureID.String()))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return capability, nil
+}
+
+func (r *SQLiteFeatureCapabilityRepository) DeleteFeatureCapability(ctx context.Context, featureID uuid.UUID) error {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM feature_capabilities WHERE feature_id = ?`, featureID.String())
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func (r *SQLiteFeatureCapabilityRepository) ListByCapability(ctx context.Context, capability string) ([]*models.FeatureCapability, error) {
+	query := `
+		SELECT ` + featureCapabilityColumns + `
+		FROM feature_capabilities c
+		JOIN smart_features f ON f.id = c.feature_id
+		WHERE c.capability = ?
+		ORDER BY f.model_id, c.feature_id
+	`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, capability)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var capabilities []*models.FeatureCapability
+	for rows.Next() {
+		c, err := scanFeatureCapability(rows)
+		if err != nil {
+			return nil, err
+		}
+		capabilities = append(capabilities, c)
+	}
+	return capabilities, rows.Err()
+}
+
+func scanFeatureCapability(row rowScanner) (*models.FeatureCapability, error) {
+	var capability models.FeatureCapability
+	err := row.Scan(&capability.FeatureID, &capability.ModelID, &capability.Capability, jsonValue{&capability.ParameterMapping})
+	if err != nil {
+		return nil, err
+	}
+	return &capability, nil
+}
@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+)
+
+type SQLiteFeatureDependencyRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteFeatureDependencyRepository(db *database.SQLiteDB) *SQLiteFeatureDependencyRepository {
+	return &SQLiteFeatureDependencyRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteFeatureDependencyRepository) SetDependencies(ctx context.Context, featureID uuid.UUID, dependencies []models.FeatureDependency) error {
+	uow := &SQLiteUnitOfWork{db: r.db}
+	return uow.Do(ctx, func(ctx context.Context) error {
+		conn := database.SQLConn(ctx, r.db)
+
+		var exists bool
+		err := conn.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM smart_features WHERE id = ?)`, featureID.String()).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrNotFound
+		}
+
+		if _, err := conn.ExecContext(ctx, `DELETE FROM feature_dependencies WHERE feature_id = ?`, featureID.String()); err != nil {
+			return err
+		}
+		for _, dependency := range dependencies {
+			_, err := conn.ExecContext(ctx,
+				`INSERT INTO feature_dependencies (feature_id, depends_on_id, kind) VALUES (?, ?, ?)`,
+				featureID.String(), dependency.DependsOnID.String(), dependency.Kind)
+			if err != nil {
+				return mapError(err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *SQLiteFeatureDependencyRepository) ListDependencies(ctx context.Context, featureID uuid.UUID) ([]models.FeatureDependency, error) {
+	query := `
+		SELECT feature_id, depends_on_id, kind
+		FROM feature_dependencies
+		WHERE feature_id = ?
+		ORDER BY kind, depends_on_id
+	`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, featureID.String())
+	if err != nil {
+		return nil, err
+	}
+	return scanFeatureDependencies(rows)
+}
+
+func (r *SQLiteFeatureDependencyRepository) ListModelDependencies(ctx context.Context, modelID uuid.UUID) ([]models.FeatureDependency, error) {
+	query := `
+		SELECT d.feature_id, d.depends_on_id, d.kind
+		FROM feature_dependencies d
+		JOIN smart_features f ON f.id = d.feature_id
+		WHERE f.model_id = ?
+		ORDER BY d.feature_id, d.kind, d.depends_on_id
+	`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, modelID.String())
+	if err != nil {
+		return nil, err
+	}
+	return scanFeatureDependencies(rows)
+}
+
+func scanFeatureDependencies(rows *sql.Rows) ([]models.FeatureDependency, error) {
+	defer rows.Close()
+
+	var dependencies []models.FeatureDependency
+	for rows.Next() {
+		var dependency models.FeatureDependency
+		if err := rows.Scan(&dependency.FeatureID, &dependency.DependsOnID, &dependency.Kind); err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies, rows.Err()
+}
+
+// LockModelDependencies needs no lock: SQLite has a single writer, so units
+// of work never overlap.
+func (r *SQLiteFeatureDependencyRepository) LockModelDependencies(ctx context.Context, modelID uuid.UUID) error {
+	return nil
+}
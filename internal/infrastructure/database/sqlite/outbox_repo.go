@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+type SQLiteOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteOutboxRepository(db *database.SQLiteDB) *SQLiteOutboxRepository {
+	return &SQLiteOutboxRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteOutboxRepository) Add(ctx context.Context, events ...*models.DomainEvent) error {
+	query := `
+		INSERT INTO outbox_events (id, aggregate_type, aggregate_id, event_type, payload, occurred_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	conn := database.SQLConn(ctx, r.db)
+	for _, event := range events {
+		_, err := conn.ExecContext(ctx, query,
+			event.ID.String(),
+			event.AggregateType,
+			event.AggregateID.String(),
+			event.Type,
+			string(event.Payload),
+			formatTime(event.OccurredAt),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FetchPending needs no relay lock: SQLite has a single writer, so the
+// surrounding unit of work already excludes other relays while they claim.
+func (r *SQLiteOutboxRepository) FetchPending(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	query := `
+		SELECT sequence, id, aggregate_type, aggregate_id, event_type, payload, occurred_at, attempts, COALESCE(last_error, ''), claimed_until
+		FROM outbox_events
+		WHERE published_at IS NULL AND dead_at IS NULL
+		ORDER BY sequence
+		LIMIT ?
+	`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.OutboxEvent
+	for rows.Next() {
+		var event models.OutboxEvent
+		var payload string
+		err = rows.Scan(
+			&event.Sequence,
+			&event.ID,
+			&event.AggregateType,
+			&event.AggregateID,
+			&event.Type,
+			&payload,
+			timestamp{&event.OccurredAt},
+			&event.Attempts,
+			&event.LastError,
+			nullTimestamp{&event.ClaimedUntil},
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = []byte(payload)
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *SQLiteOutboxRepository) Claim(ctx context.Context, sequences []int64, until time.Time) error {
+	return r.update(ctx, "claimed_until = ?", []interface{}{formatTime(until)}, sequences)
+}
+
+func (r *SQLiteOutboxRepository) Release(ctx context.Context, sequences []int64) error {
+	return r.update(ctx, "claimed_until = NULL", nil, sequences)
+}
+
+func (r *SQLiteOutboxRepository) MarkPublished(ctx context.Context, sequences []int64) error {
+	return r.update(ctx, "published_at = ?, claimed_until = NULL, last_error = NULL",
+		[]interface{}{formatTime(time.Now())}, sequences)
+}
+
+func (r *SQLiteOutboxRepository) MarkFailed(ctx context.Context, sequence int64, reason string, dead bool) error {
+	var deadAt *time.Time
+	if dead {
+		now := time.Now()
+		deadAt = &now
+	}
+
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, last_error = ?, claimed_until = NULL, dead_at = ?
+		WHERE sequence = ?
+	`
+
+	_, err := database.SQLConn(ctx, r.db).ExecContext(ctx, query, reason, formatNullTime(deadAt), sequence)
+	return err
+}
+
+// update applies set, with its args, to the events with the given sequences.
+func (r *SQLiteOutboxRepository) update(ctx context.Context, set string, args []interface{}, sequences []int64) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(sequences))
+	for i, sequence := range sequences {
+		placeholders[i] = "?"
+		args = append(args, sequence)
+	}
+
+	query := `
+		UPDATE outbox_events
+		SET ` + set + `
+		WHERE sequence IN (` + strings.Join(placeholders, ", ") + `)`
+
+	_, err := database.SQLConn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
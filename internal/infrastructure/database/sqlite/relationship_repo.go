@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+)
+
+const relationshipColumns = `id, source_model_id, target_model_id, type, created_at`
+
+// relatedModelsQuery walks the relationship graph breadth first; see the
+// Postgres repository.
+const relatedModelsQuery = `
+	WITH RECURSIVE edges (from_id, to_id) AS (
+		SELECT source_model_id, target_model_id FROM model_relationships WHERE type = ? AND ?
+		UNION ALL
+		SELECT target_model_id, source_model_id FROM model_relationships WHERE type = ? AND ?
+	),
+	reach (model_id, depth) AS (
+		SELECT ?, 0
+		UNION
+		SELECT edges.to_id, reach.depth + 1
+		FROM reach
+		JOIN edges ON edges.from_id = reach.model_id
+		WHERE reach.depth < ?
+	)
+	SELECT model_id, MIN(depth) AS depth
+	FROM reach
+	WHERE model_id <> ?
+	GROUP BY model_id
+	ORDER BY depth, model_id
+`
+
+type SQLiteRelationshipRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRelationshipRepository(db *database.SQLiteDB) *SQLiteRelationshipRepository {
+	return &SQLiteRelationshipRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteRelationshipRepository) CreateRelationship(ctx context.Context, relationship *models.ModelRelationship) (*models.ModelRelationship, error) {
+	query := `
+		INSERT INTO model_relationships (id, source_model_id, target_model_id, type, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING ` + relationshipColumns
+
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		relationship.ID.String(),
+		relationship.SourceModelID.String(),
+		relationship.TargetModelID.String(),
+		relationship.Type,
+		formatTime(relationship.CreatedAt),
+	)
+	return scanRelationship(row)
+}
+
+func (r *SQLiteRelationshipRepository) GetRelationship(ctx context.Context, id uuid.UUID) (*models.ModelRelationship, error) {
+	query := `SELECT ` + relationshipColumns + ` FROM model_relationships WHERE id = ?`
+
+	return scanRelationship(database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteRelationshipRepository) DeleteRelationship(ctx context.Context, id uuid.UUID) error {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM model_relationships WHERE id = ?`, id.String())
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func (r *SQLiteRelationshipRepository) ListRelationships(ctx context.Context, filter models.RelationshipFilter) ([]*models.ModelRelationship, error) {
+	query := `
+		SELECT ` + relationshipColumns + `
+		FROM model_relationships
+		WHERE (source_model_id = ? OR target_model_id = ?) AND (? = '' OR type = ?)
+		ORDER BY created_at, id
+	`
+
+	modelID := filter.ModelID.String()
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, modelID, modelID, filter.Type, filter.Type)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var relationships []*models.ModelRelationship
+	for rows.Next() {
+		relationship, err := scanRelationship(rows)
+		if err != nil {
+			return nil, err
+		}
+		relationships = append(relationships, relationship)
+	}
+
+	return relationships, rows.Err()
+}
+
+func (r *SQLiteRelationshipRepository) RelatedModels(ctx context.Context, query models.RelationshipQuery) ([]*models.RelatedModel, error) {
+	start := query.ModelID.String()
+	outgoing := query.Direction != models.DirectionIncoming
+	incoming := query.Direction != models.DirectionOutgoing
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, relatedModelsQuery,
+		query.Type, outgoing, query.Type, incoming, start, query.MaxDepth, start)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var related []*models.RelatedModel
+	for rows.Next() {
+		var model models.RelatedModel
+		if err := rows.Scan(&model.ModelID, &model.Depth); err != nil {
+			return nil, err
+		}
+		related = append(related, &model)
+	}
+
+	return related, rows.Err()
+}
+
+func scanRelationship(row rowScanner) (*models.ModelRelationship, error) {
+	var relationship models.ModelRelationship
+	err := row.Scan(
+		&relationship.ID,
+		&relationship.SourceModelID,
+		&relationship.TargetModelID,
+		&relationship.Type,
+		timestamp{&relationship.CreatedAt},
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &relationship, nil
+}
+
+// LockRelationshipType needs no lock: SQLite has a single writer, so units
+// of work never overlap.
+func (r *SQLiteRelationshipRepository) LockRelationshipType(ctx context.Context, relationshipType models.RelationshipType) error {
+	return nil
+}
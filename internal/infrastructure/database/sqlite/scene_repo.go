@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+)
+
+const sceneColumns = `id, name, COALESCE(description, ''), steps, stop_on_error, created_at, updated_at`
+
+type SQLiteSceneRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSceneRepository(db *database.SQLiteDB) *SQLiteSceneRepository {
+	return &SQLiteSceneRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteSceneRepository) CreateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	query := `
+		INSERT INTO scenes (id, name, description, steps, stop_on_error, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + sceneColumns
+
+	steps, err := encodeSceneSteps(scene.Steps)
+	if err != nil {
+		return nil, err
+	}
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		scene.ID.String(),
+		scene.Name,
+		scene.Description,
+		steps,
+		scene.StopOnError,
+		formatTime(scene.CreatedAt),
+		formatTime(scene.UpdatedAt),
+	)
+	return scanScene(row)
+}
+
+func (r *SQLiteSceneRepository) GetScene(ctx context.Context, id uuid.UUID) (*models.Scene, error) {
+	query := `SELECT ` + sceneColumns + ` FROM scenes WHERE id = ?`
+
+	return scanScene(database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteSceneRepository) ListScenes(ctx context.Context) ([]*models.Scene, error) {
+	query := `SELECT ` + sceneColumns + ` FROM scenes ORDER BY created_at, id`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scenes []*models.Scene
+	for rows.Next() {
+		scene, err := scanScene(rows)
+		if err != nil {
+			return nil, err
+		}
+		scenes = append(scenes, scene)
+	}
+
+	return scenes, rows.Err()
+}
+
+func (r *SQLiteSceneRepository) UpdateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	query := `
+		UPDATE scenes
+		SET name = ?, description = ?, steps = ?, stop_on_error = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING ` + sceneColumns
+
+	steps, err := encodeSceneSteps(scene.Steps)
+	if err != nil {
+		return nil, err
+	}
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		scene.Name,
+		scene.Description,
+		steps,
+		scene.StopOnError,
+		formatTime(scene.UpdatedAt),
+		scene.ID.String(),
+	)
+	return scanScene(row)
+}
+
+func (r *SQLiteSceneRepository) DeleteScene(ctx context.Context, id uuid.UUID) error {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM scenes WHERE id = ?`, id.String())
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func scanScene(row rowScanner) (*models.Scene, error) {
+	var scene models.Scene
+	err := row.Scan(
+		&scene.ID,
+		&scene.Name,
+		&scene.Description,
+		jsonValue{&scene.Steps},
+		&scene.StopOnError,
+		timestamp{&scene.CreatedAt},
+		timestamp{&scene.UpdatedAt},
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &scene, nil
+}
+
+func encodeSceneSteps(steps []models.SceneStep) (string, error) {
+	if steps == nil {
+		steps = []models.SceneStep{}
+	}
+	return encodeJSONValue(steps)
+}
@@ -0,0 +1,286 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+const scheduleColumns = `id, name, COALESCE(description, ''), COALESCE(cron, ''), COALESCE(interval_seconds, 0), timezone, device_id, feature_id, parameters, paused, next_run_at, last_run_at, created_at, updated_at`
+
+const scheduleRunColumns = `id, schedule_id, scheduled_at, status, COALESCE(error, ''), started_at, finished_at`
+
+type SQLiteScheduleRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteScheduleRepository(db *database.SQLiteDB) *SQLiteScheduleRepository {
+	return &SQLiteScheduleRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteScheduleRepository) CreateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	query := `
+		INSERT INTO schedules (id, name, description, cron, interval_seconds, timezone, device_id, feature_id, parameters,
+			paused, next_run_at, last_run_at, created_at, updated_at)
+		VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, 0), ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + scheduleColumns
+
+	parameters, err := encodeJSONValue(jsonObjectOrEmpty(schedule.Parameters))
+	if err != nil {
+		return nil, err
+	}
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		schedule.ID.String(),
+		schedule.Name,
+		schedule.Description,
+		schedule.Cron,
+		int64(schedule.Interval/time.Second),
+		schedule.Timezone,
+		schedule.DeviceID,
+		schedule.FeatureID.String(),
+		parameters,
+		schedule.Paused,
+		formatTime(schedule.NextRunAt),
+		formatNullTime(schedule.LastRunAt),
+		formatTime(schedule.CreatedAt),
+		formatTime(schedule.UpdatedAt),
+	)
+	return scanSchedule(row)
+}
+
+func (r *SQLiteScheduleRepository) GetSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE id = ?`
+
+	return scanSchedule(database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteScheduleRepository) ListSchedules(ctx context.Context) ([]*models.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules ORDER BY created_at, id`
+
+	return r.querySchedules(ctx, query)
+}
+
+func (r *SQLiteScheduleRepository) UpdateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	query := `
+		UPDATE schedules
+		SET name = ?, description = ?, cron = NULLIF(?, ''), interval_seconds = NULLIF(?, 0), timezone = ?,
+			device_id = ?, feature_id = ?, parameters = ?, paused = ?, next_run_at = ?, last_run_at = ?,
+			updated_at = ?
+		WHERE id = ?
+		RETURNING ` + scheduleColumns
+
+	parameters, err := encodeJSONValue(jsonObjectOrEmpty(schedule.Parameters))
+	if err != nil {
+		return nil, err
+	}
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		schedule.Name,
+		schedule.Description,
+		schedule.Cron,
+		int64(schedule.Interval/time.Second),
+		schedule.Timezone,
+		schedule.DeviceID,
+		schedule.FeatureID.String(),
+		parameters,
+		schedule.Paused,
+		formatTime(schedule.NextRunAt),
+		formatNullTime(schedule.LastRunAt),
+		formatTime(schedule.UpdatedAt),
+		schedule.ID.String(),
+	)
+	return scanSchedule(row)
+}
+
+func (r *SQLiteScheduleRepository) DeleteSchedule(ctx context.Context, id uuid.UUID) error {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM schedules WHERE id = ?`, id.String())
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func (r *SQLiteScheduleRepository) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]*models.Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		WHERE paused = 0 AND next_run_at <= ?
+		ORDER BY next_run_at, id
+		LIMIT ?
+	`
+
+	return r.querySchedules(ctx, query, formatTime(now), limit)
+}
+
+func (r *SQLiteScheduleRepository) AdvanceSchedule(ctx context.Context, id uuid.UUID, due, next time.Time) error {
+	query := `
+		UPDATE schedules
+		SET next_run_at = ?, last_run_at = ?
+		WHERE id = ? AND next_run_at = ? AND paused = 0
+	`
+
+	conn := database.SQLConn(ctx, r.db)
+	result, err := conn.ExecContext(ctx, query, formatTime(next), formatTime(due), id.String(), formatTime(due))
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := conn.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM schedules WHERE id = ?)`, id.String()).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return models.ErrNotFound
+	}
+	return fmt.Errorf("%w: schedule %s is no longer due at %s", models.ErrVersionConflict, id, due.Format(time.RFC3339))
+}
+
+func (r *SQLiteScheduleRepository) CreateRun(ctx context.Context, run *models.ScheduleRun) error {
+	query := `
+		INSERT INTO schedule_runs (id, schedule_id, scheduled_at, status, error, started_at, finished_at)
+		VALUES (?, ?, ?, ?, NULLIF(?, ''), ?, ?)
+	`
+
+	_, err := database.SQLConn(ctx, r.db).ExecContext(ctx, query,
+		run.ID.String(),
+		run.ScheduleID.String(),
+		formatTime(run.ScheduledAt),
+		run.Status,
+		run.Error,
+		formatTime(run.StartedAt),
+		formatNullTime(run.FinishedAt),
+	)
+	return mapError(err)
+}
+
+func (r *SQLiteScheduleRepository) FinishRun(ctx context.Context, run *models.ScheduleRun) error {
+	query := `
+		UPDATE schedule_runs
+		SET status = ?, error = NULLIF(?, ''), finished_at = ?
+		WHERE id = ?
+	`
+
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, query,
+		run.Status,
+		run.Error,
+		formatNullTime(run.FinishedAt),
+		run.ID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func (r *SQLiteScheduleRepository) ListRuns(ctx context.Context, filter models.ScheduleRunFilter) ([]*models.ScheduleRun, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.ScheduleID != nil {
+		conditions = append(conditions, "schedule_id = ?")
+		args = append(args, filter.ScheduleID.String())
+	}
+	if filter.After != nil {
+		conditions = append(conditions, "(started_at, id) < (?, ?)")
+		args = append(args, formatTime(filter.After.CreatedAt), filter.After.ID.String())
+	}
+
+	query := `SELECT ` + scheduleRunColumns + ` FROM schedule_runs`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY started_at DESC, id DESC LIMIT ?`
+	args = append(args, filter.Limit)
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*models.ScheduleRun
+	for rows.Next() {
+		var run models.ScheduleRun
+		err = rows.Scan(
+			&run.ID,
+			&run.ScheduleID,
+			timestamp{&run.ScheduledAt},
+			&run.Status,
+			&run.Error,
+			timestamp{&run.StartedAt},
+			nullTimestamp{&run.FinishedAt},
+		)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, rows.Err()
+}
+
+func (r *SQLiteScheduleRepository) PruneRuns(ctx context.Context, before time.Time) (int64, error) {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM schedule_runs WHERE started_at < ?`, formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *SQLiteScheduleRepository) querySchedules(ctx context.Context, query string, args ...interface{}) ([]*models.Schedule, error) {
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []*models.Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func scanSchedule(row rowScanner) (*models.Schedule, error) {
+	var schedule models.Schedule
+	var seconds int64
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.Name,
+		&schedule.Description,
+		&schedule.Cron,
+		&seconds,
+		&schedule.Timezone,
+		&schedule.DeviceID,
+		&schedule.FeatureID,
+		jsonObject{&schedule.Parameters},
+		&schedule.Paused,
+		timestamp{&schedule.NextRunAt},
+		nullTimestamp{&schedule.LastRunAt},
+		timestamp{&schedule.CreatedAt},
+		timestamp{&schedule.UpdatedAt},
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	schedule.Interval = time.Duration(seconds) * time.Second
+	return &schedule, nil
+}
@@ -0,0 +1,223 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+const shadowColumns = `device_id, desired, reported, version, created_at, updated_at`
+
+type SQLiteShadowRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteShadowRepository(db *database.SQLiteDB) *SQLiteShadowRepository {
+	return &SQLiteShadowRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteShadowRepository) GetShadow(ctx context.Context, deviceID string) (*models.DeviceShadow, error) {
+	query := `SELECT ` + shadowColumns + ` FROM device_shadows WHERE device_id = ?`
+
+	shadow, err := scanShadow(database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, deviceID))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return shadow, nil
+}
+
+func (r *SQLiteShadowRepository) ListShadows(ctx context.Context, deviceIDs []string) ([]*models.DeviceShadow, error) {
+	query := `
+		SELECT ` + shadowColumns + `
+		FROM device_shadows
+		WHERE json_array_length(?1) = 0 OR device_id IN (SELECT value FROM json_each(?1))
+		ORDER BY device_id
+	`
+
+	idList, err := encodeIDList(deviceIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, idList)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shadows []*models.DeviceShadow
+	for rows.Next() {
+		shadow, err := scanShadow(rows)
+		if err != nil {
+			return nil, err
+		}
+		shadows = append(shadows, shadow)
+	}
+
+	return shadows, rows.Err()
+}
+
+// SaveShadow inserts the shadow when expectedVersion is 0 and otherwise
+// updates it only while it is still at expectedVersion.
+func (r *SQLiteShadowRepository) SaveShadow(ctx context.Context, shadow *models.DeviceShadow, expectedVersion int64) error {
+	desired, err := encodeJSON(jsonObjectOrEmpty(shadow.Desired))
+	if err != nil {
+		return err
+	}
+	reported, err := encodeJSON(jsonObjectOrEmpty(shadow.Reported))
+	if err != nil {
+		return err
+	}
+
+	var result sql.Result
+	if expectedVersion == 0 {
+		query := `
+			INSERT INTO device_shadows (device_id, desired, reported, version, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (device_id) DO NOTHING
+		`
+		result, err = database.SQLConn(ctx, r.db).ExecContext(ctx, query,
+			shadow.DeviceID,
+			desired,
+			reported,
+			shadow.Version,
+			formatTime(shadow.CreatedAt),
+			formatTime(shadow.UpdatedAt),
+		)
+	} else {
+		query := `
+			UPDATE device_shadows
+			SET desired = ?, reported = ?, version = ?, updated_at = ?
+			WHERE device_id = ? AND version = ?
+		`
+		result, err = database.SQLConn(ctx, r.db).ExecContext(ctx, query,
+			desired,
+			reported,
+			shadow.Version,
+			formatTime(shadow.UpdatedAt),
+			shadow.DeviceID,
+			expectedVersion,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *SQLiteShadowRepository) DeleteShadow(ctx context.Context, deviceID string) error {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM device_shadows WHERE device_id = ?`, deviceID)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func (r *SQLiteShadowRepository) AddDelta(ctx context.Context, delta *models.ShadowDelta) error {
+	query := `
+		INSERT INTO device_shadow_deltas (device_id, version, delta, created_at)
+		VALUES (?, ?, ?, ?)
+		RETURNING sequence
+	`
+
+	encoded, err := encodeJSON(jsonObjectOrEmpty(delta.Delta))
+	if err != nil {
+		return err
+	}
+	return database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		delta.DeviceID,
+		delta.Version,
+		encoded,
+		formatTime(delta.CreatedAt),
+	).Scan(&delta.Sequence)
+}
+
+func (r *SQLiteShadowRepository) ListDeltasSince(ctx context.Context, after int64, limit int) ([]*models.ShadowDelta, error) {
+	query := `
+		SELECT sequence, device_id, version, delta, created_at
+		FROM device_shadow_deltas
+		WHERE sequence > ?
+		ORDER BY sequence
+		LIMIT ?
+	`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deltas []*models.ShadowDelta
+	for rows.Next() {
+		var delta models.ShadowDelta
+		err = rows.Scan(
+			&delta.Sequence,
+			&delta.DeviceID,
+			&delta.Version,
+			jsonObject{&delta.Delta},
+			timestamp{&delta.CreatedAt},
+		)
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, &delta)
+	}
+
+	return deltas, rows.Err()
+}
+
+func (r *SQLiteShadowRepository) LatestDeltaSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := database.SQLConn(ctx, r.db).QueryRowContext(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM device_shadow_deltas`).Scan(&sequence)
+	return sequence, err
+}
+
+func (r *SQLiteShadowRepository) OldestDeltaSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := database.SQLConn(ctx, r.db).QueryRowContext(ctx, `SELECT COALESCE(MIN(sequence), 0) FROM device_shadow_deltas`).Scan(&sequence)
+	return sequence, err
+}
+
+func (r *SQLiteShadowRepository) PruneDeltas(ctx context.Context, before time.Time) (int64, error) {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM device_shadow_deltas WHERE created_at < ?`, formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanShadow(row rowScanner) (*models.DeviceShadow, error) {
+	var shadow models.DeviceShadow
+	err := row.Scan(
+		&shadow.DeviceID,
+		jsonObject{&shadow.Desired},
+		jsonObject{&shadow.Reported},
+		&shadow.Version,
+		timestamp{&shadow.CreatedAt},
+		timestamp{&shadow.UpdatedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &shadow, nil
+}
+
+// jsonObjectOrEmpty stores a nil map as an empty object, since the shadow
+// columns are NOT NULL.
+func jsonObjectOrEmpty(value map[string]interface{}) map[string]interface{} {
+	if value == nil {
+		return map[string]interface{}{}
+	}
+	return value
+}
@@ -0,0 +1,293 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"strings"
+)
+
+const smartFeatureColumns = `id, model_id, name, COALESCE(description, ''), protocol, interface_path, parameters, created_at, updated_at`
+
+type SQLiteSmartFeatureRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSmartFeatureRepository(db *database.SQLiteDB) *SQLiteSmartFeatureRepository {
+	return &SQLiteSmartFeatureRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteSmartFeatureRepository) Create(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error) {
+	query := `
+		INSERT INTO smart_features (id, model_id, name, description, protocol, interface_path, parameters, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + smartFeatureColumns
+
+	parameters, err := encodeJSON(feature.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		feature.ID.String(),
+		feature.ModelID.String(),
+		feature.Name,
+		feature.Description,
+		feature.Protocol,
+		feature.InterfacePath,
+		parameters,
+		formatTime(feature.CreatedAt),
+		formatTime(feature.UpdatedAt),
+	)
+	created, err := scanSmartFeature(row)
+	if errors.Is(err, models.ErrAlreadyExists) {
+		return nil, r.namePathConflict(ctx, feature)
+	}
+	return created, err
+}
+
+func (r *SQLiteSmartFeatureRepository) GetByID(ctx context.Context, id string) (*models.SmartFeature, error) {
+	query := `SELECT ` + smartFeatureColumns + ` FROM smart_features WHERE id = ?`
+
+	return scanSmartFeature(database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, id))
+}
+
+func (r *SQLiteSmartFeatureRepository) GetWithModelID(ctx context.Context, modelID string) ([]*models.SmartFeature, error) {
+	query := `SELECT ` + smartFeatureColumns + ` FROM smart_features WHERE model_id = ? ORDER BY created_at, id`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, modelID)
+	if err != nil {
+		return nil, err
+	}
+	return collectSmartFeatures(rows)
+}
+
+func (r *SQLiteSmartFeatureRepository) GetWithModelIDs(ctx context.Context, modelIDs []string) ([]*models.SmartFeature, error) {
+	query := `SELECT ` + smartFeatureColumns + ` FROM smart_features WHERE model_id IN (SELECT value FROM json_each(?)) ORDER BY created_at, id`
+
+	idList, err := encodeIDList(modelIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, idList)
+	if err != nil {
+		return nil, err
+	}
+	return collectSmartFeatures(rows)
+}
+
+func (r *SQLiteSmartFeatureRepository) List(ctx context.Context, filter models.FeatureFilter, after *models.PageCursor, limit int) ([]*models.SmartFeature, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Protocol != nil {
+		conditions = append(conditions, "protocol = ?")
+		args = append(args, *filter.Protocol)
+	}
+	var modelConditions []string
+	if filter.ModelType != nil {
+		modelConditions = append(modelConditions, "type = ?")
+		args = append(args, *filter.ModelType)
+	}
+	if filter.ModelCategory != nil {
+		modelConditions = append(modelConditions, "category = ?")
+		args = append(args, *filter.ModelCategory)
+	}
+	if len(modelConditions) > 0 {
+		conditions = append(conditions, "model_id IN (SELECT id FROM smart_models WHERE "+strings.Join(modelConditions, " AND ")+")")
+	}
+	if filter.NamePrefix != "" {
+		conditions = append(conditions, "substr(name, 1, length(?)) = ?")
+		args = append(args, filter.NamePrefix, filter.NamePrefix)
+	}
+	if filter.InterfacePathPattern != "" {
+		// GLOB already uses * and ?; only [ needs escaping.
+		conditions = append(conditions, "interface_path GLOB ?")
+		args = append(args, strings.ReplaceAll(filter.InterfacePathPattern, "[", "[[]"))
+	}
+	if after != nil {
+		conditions = append(conditions, "(created_at, id) > (?, ?)")
+		args = append(args, formatTime(after.CreatedAt), after.ID.String())
+	}
+
+	query := `SELECT ` + smartFeatureColumns + ` FROM smart_features`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY created_at, id LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectSmartFeatures(rows)
+}
+
+func (r *SQLiteSmartFeatureRepository) GetAll(ctx context.Context) ([]*models.SmartFeature, error) {
+	query := `SELECT ` + smartFeatureColumns + ` FROM smart_features ORDER BY created_at, id`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return collectSmartFeatures(rows)
+}
+
+func (r *SQLiteSmartFeatureRepository) Update(ctx context.Context, feature *models.SmartFeature) (*models.SmartFeature, error) {
+	query := `
+		UPDATE smart_features
+		SET name = ?, description = ?, protocol = ?, interface_path = ?, parameters = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING ` + smartFeatureColumns
+
+	parameters, err := encodeJSON(feature.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		feature.Name,
+		feature.Description,
+		feature.Protocol,
+		feature.InterfacePath,
+		parameters,
+		formatTime(feature.UpdatedAt),
+		feature.ID.String(),
+	)
+	updated, err := scanSmartFeature(row)
+	if errors.Is(err, models.ErrAlreadyExists) {
+		return nil, r.namePathConflict(ctx, feature)
+	}
+	return updated, err
+}
+
+// namePathConflict finds the feature of the same model that took the name
+// and interface path of feature. An update does not carry the model ID, so
+// it is read from the stored feature when there is one.
+func (r *SQLiteSmartFeatureRepository) namePathConflict(ctx context.Context, feature *models.SmartFeature) error {
+	query := `
+		SELECT id FROM smart_features
+		WHERE model_id = COALESCE((SELECT model_id FROM smart_features WHERE id = ?), ?)
+		  AND lower(name) = lower(?) AND lower(interface_path) = lower(?) AND id <> ?`
+
+	conflict := &models.AlreadyExistsError{Entity: models.SmartFeatureAggregate}
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		feature.ID.String(), feature.ModelID.String(), feature.Name, feature.InterfacePath, feature.ID.String())
+	if err := row.Scan(&conflict.ID); err != nil {
+		return fmt.Errorf("%w (%v)", conflict, err)
+	}
+	return conflict
+}
+
+func (r *SQLiteSmartFeatureRepository) Delete(ctx context.Context, id string) error {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM smart_features WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func (r *SQLiteSmartFeatureRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.SmartFeature, error) {
+	query := `SELECT ` + smartFeatureColumns + ` FROM smart_features WHERE id IN (SELECT value FROM json_each(?)) ORDER BY created_at, id`
+
+	idList, err := encodeIDList(ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, idList)
+	if err != nil {
+		return nil, err
+	}
+	return collectSmartFeatures(rows)
+}
+
+// SQLite has no batch protocol; the batch methods run the single-row
+// statements in one transaction, which is cheap for an embedded database.
+
+func (r *SQLiteSmartFeatureRepository) CreateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error) {
+	return r.writeBatch(ctx, features, r.Create)
+}
+
+func (r *SQLiteSmartFeatureRepository) UpdateBatch(ctx context.Context, features []*models.SmartFeature) ([]*models.SmartFeature, error) {
+	return r.writeBatch(ctx, features, r.Update)
+}
+
+func (r *SQLiteSmartFeatureRepository) DeleteBatch(ctx context.Context, ids []string) error {
+	uow := &SQLiteUnitOfWork{db: r.db}
+	return uow.Do(ctx, func(ctx context.Context) error {
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if err := r.Delete(ctx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *SQLiteSmartFeatureRepository) writeBatch(
+	ctx context.Context,
+	features []*models.SmartFeature,
+	write func(context.Context, *models.SmartFeature) (*models.SmartFeature, error),
+) ([]*models.SmartFeature, error) {
+	written := make([]*models.SmartFeature, 0, len(features))
+	uow := &SQLiteUnitOfWork{db: r.db}
+	err := uow.Do(ctx, func(ctx context.Context) error {
+		for i, feature := range features {
+			result, err := write(ctx, feature)
+			if err != nil {
+				return &models.BatchItemError{Index: i, Err: err}
+			}
+			written = append(written, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return written, nil
+}
+
+func scanSmartFeature(row rowScanner) (*models.SmartFeature, error) {
+	var feature models.SmartFeature
+	err := row.Scan(
+		&feature.ID,
+		&feature.ModelID,
+		&feature.Name,
+		&feature.Description,
+		&feature.Protocol,
+		&feature.InterfacePath,
+		jsonObject{&feature.Parameters},
+		timestamp{&feature.CreatedAt},
+		timestamp{&feature.UpdatedAt},
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &feature, nil
+}
+
+func collectSmartFeatures(rows *sql.Rows) ([]*models.SmartFeature, error) {
+	defer rows.Close()
+
+	var features []*models.SmartFeature
+	for rows.Next() {
+		feature, err := scanSmartFeature(rows)
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, feature)
+	}
+
+	return features, rows.Err()
+}
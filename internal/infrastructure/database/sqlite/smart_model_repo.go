@@ -0,0 +1,191 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+)
+
+const smartModelColumns = `id, name, COALESCE(description, ''), type, category, COALESCE(manufacturer, ''), COALESCE(model_number, ''), metadata, created_at, updated_at`
+
+type SQLiteSmartModelRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSmartModelRepository(db *database.SQLiteDB) *SQLiteSmartModelRepository {
+	return &SQLiteSmartModelRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteSmartModelRepository) Create(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error) {
+	query := `
+		INSERT INTO smart_models (id, name, description, type, category, manufacturer, model_number, metadata, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + smartModelColumns
+
+	metadata, err := encodeJSON(model.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		model.ID.String(),
+		model.Name,
+		model.Description,
+		model.Type,
+		model.Category,
+		model.Manufacturer,
+		model.ModelNumber,
+		metadata,
+		formatTime(model.CreatedAt),
+		formatTime(model.UpdatedAt),
+	)
+	created, err := scanSmartModel(row)
+	if errors.Is(err, models.ErrAlreadyExists) {
+		return nil, r.modelNumberConflict(ctx, model)
+	}
+	return created, err
+}
+
+func (r *SQLiteSmartModelRepository) GetByID(ctx context.Context, id string) (*models.SmartModel, error) {
+	query := `SELECT ` + smartModelColumns + ` FROM smart_models WHERE id = ?`
+
+	return scanSmartModel(database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, id))
+}
+
+func (r *SQLiteSmartModelRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.SmartModel, error) {
+	query := `SELECT ` + smartModelColumns + ` FROM smart_models WHERE id IN (SELECT value FROM json_each(?)) ORDER BY created_at, id`
+
+	idList, err := encodeIDList(ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, idList)
+	if err != nil {
+		return nil, err
+	}
+	return collectSmartModels(rows)
+}
+
+func (r *SQLiteSmartModelRepository) GetByModelNumber(ctx context.Context, manufacturer, modelNumber string) (*models.SmartModel, error) {
+	query := `SELECT ` + smartModelColumns + ` FROM smart_models WHERE lower(manufacturer) = lower(?) AND lower(model_number) = lower(?) ORDER BY created_at, id LIMIT 1`
+
+	return scanSmartModel(database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, manufacturer, modelNumber))
+}
+
+func (r *SQLiteSmartModelRepository) GetWithType(ctx context.Context, modelType models.ModelType) ([]*models.SmartModel, error) {
+	query := `SELECT ` + smartModelColumns + ` FROM smart_models WHERE type = ? ORDER BY created_at, id`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, modelType)
+	if err != nil {
+		return nil, err
+	}
+	return collectSmartModels(rows)
+}
+
+func (r *SQLiteSmartModelRepository) GetAll(ctx context.Context) ([]*models.SmartModel, error) {
+	query := `SELECT ` + smartModelColumns + ` FROM smart_models ORDER BY created_at, id`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return collectSmartModels(rows)
+}
+
+func (r *SQLiteSmartModelRepository) Update(ctx context.Context, model *models.SmartModel) (*models.SmartModel, error) {
+	query := `
+		UPDATE smart_models
+		SET name = ?, description = ?, type = ?, category = ?, manufacturer = ?, model_number = ?, metadata = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING ` + smartModelColumns
+
+	metadata, err := encodeJSON(model.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		model.Name,
+		model.Description,
+		model.Type,
+		model.Category,
+		model.Manufacturer,
+		model.ModelNumber,
+		metadata,
+		formatTime(model.UpdatedAt),
+		model.ID.String(),
+	)
+	updated, err := scanSmartModel(row)
+	if errors.Is(err, models.ErrAlreadyExists) {
+		return nil, r.modelNumberConflict(ctx, model)
+	}
+	return updated, err
+}
+
+// modelNumberConflict finds the model that took the manufacturer and model
+// number of model. A failed statement leaves an SQLite transaction usable, so
+// this works inside a unit of work too.
+func (r *SQLiteSmartModelRepository) modelNumberConflict(ctx context.Context, model *models.SmartModel) error {
+	query := `
+		SELECT id FROM smart_models
+		WHERE lower(manufacturer) = lower(?) AND lower(model_number) = lower(?) AND model_number <> '' AND id <> ?`
+
+	conflict := &models.AlreadyExistsError{Entity: models.SmartModelAggregate}
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, model.Manufacturer, model.ModelNumber, model.ID.String())
+	if err := row.Scan(&conflict.ID); err != nil {
+		return fmt.Errorf("%w (%v)", conflict, err)
+	}
+	return conflict
+}
+
+func (r *SQLiteSmartModelRepository) Delete(ctx context.Context, id string) error {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM smart_models WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSmartModel(row rowScanner) (*models.SmartModel, error) {
+	var model models.SmartModel
+	err := row.Scan(
+		&model.ID,
+		&model.Name,
+		&model.Description,
+		&model.Type,
+		&model.Category,
+		&model.Manufacturer,
+		&model.ModelNumber,
+		jsonObject{&model.Metadata},
+		timestamp{&model.CreatedAt},
+		timestamp{&model.UpdatedAt},
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &model, nil
+}
+
+func collectSmartModels(rows *sql.Rows) ([]*models.SmartModel, error) {
+	defer rows.Close()
+
+	var smartModels []*models.SmartModel
+	for rows.Next() {
+		model, err := scanSmartModel(rows)
+		if err != nil {
+			return nil, err
+		}
+		smartModels = append(smartModels, model)
+	}
+
+	return smartModels, rows.Err()
+}
@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+// readingInsertChunk keeps a multi-row insert well below SQLite's limit on
+// bound parameters.
+const readingInsertChunk = 200
+
+const retentionPolicyColumns = `feature_id, retention_seconds, updated_at`
+
+type SQLiteTelemetryRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteTelemetryRepository(db *database.SQLiteDB) *SQLiteTelemetryRepository {
+	return &SQLiteTelemetryRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteTelemetryRepository) AddReadings(ctx context.Context, readings []*models.TelemetryReading) (int, error) {
+	conn := database.SQLConn(ctx, r.db)
+	stored := 0
+	for start := 0; start < len(readings); start += readingInsertChunk {
+		chunk := readings[start:min(start+readingInsertChunk, len(readings))]
+
+		args := make([]interface{}, 0, len(chunk)*4)
+		for _, reading := range chunk {
+			args = append(args, reading.DeviceID, reading.FeatureID.String(), reading.Timestamp.UnixNano(), reading.Value)
+		}
+		query := `INSERT OR IGNORE INTO telemetry_readings (device_id, feature_id, recorded_at, value) VALUES ` +
+			strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?), ", len(chunk)), ", ")
+
+		result, err := conn.ExecContext(ctx, query, args...)
+		if err != nil {
+			return stored, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return stored, err
+		}
+		stored += int(affected)
+	}
+	return stored, nil
+}
+
+// readingConditions returns the WHERE clause of query with its arguments.
+func readingConditions(query models.ReadingQuery) (string, []interface{}) {
+	args := []interface{}{query.FeatureID.String(), query.From.UnixNano(), query.To.UnixNano()}
+	conditions := "feature_id = ? AND recorded_at >= ? AND recorded_at < ?"
+	if query.DeviceID != "" {
+		args = append(args, query.DeviceID)
+		conditions += " AND device_id = ?"
+	}
+	return conditions, args
+}
+
+func (r *SQLiteTelemetryRepository) ListReadings(ctx context.Context, query models.ReadingQuery) ([]*models.TelemetryReading, error) {
+	conditions, args := readingConditions(query)
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, `
+		SELECT device_id, feature_id, recorded_at, value
+		FROM telemetry_readings
+		WHERE `+conditions+`
+		ORDER BY recorded_at, device_id
+		LIMIT ?`, append(args, query.Limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var readings []*models.TelemetryReading
+	for rows.Next() {
+		var reading models.TelemetryReading
+		var featureID string
+		var recordedAt int64
+		if err := rows.Scan(&reading.DeviceID, &featureID, &recordedAt, &reading.Value); err != nil {
+			return nil, err
+		}
+		if reading.FeatureID, err = uuid.Parse(featureID); err != nil {
+			return nil, err
+		}
+		reading.Timestamp = time.Unix(0, recordedAt).UTC()
+		readings = append(readings, &reading)
+	}
+	return readings, rows.Err()
+}
+
+// Downsample relies on recorded_at never being negative, so that integer
+// division rounds down like floor does in Postgres.
+func (r *SQLiteTelemetryRepository) Downsample(ctx context.Context, query models.ReadingQuery, bucket time.Duration) ([]*models.TelemetryBucket, error) {
+	conditions, args := readingConditions(query)
+	width := bucket.Nanoseconds()
+	args = append([]interface{}{width, width}, args...)
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, `
+		SELECT (recorded_at / ?) * ? AS bucket_start, count(*), avg(value), min(value), max(value)
+		FROM telemetry_readings
+		WHERE `+conditions+`
+		GROUP BY bucket_start
+		ORDER BY bucket_start
+		LIMIT ?`, append(args, query.Limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []*models.TelemetryBucket
+	for rows.Next() {
+		var b models.TelemetryBucket
+		var start int64
+		if err := rows.Scan(&start, &b.Count, &b.Avg, &b.Min, &b.Max); err != nil {
+			return nil, err
+		}
+		b.Start = time.Unix(0, start).UTC()
+		buckets = append(buckets, &b)
+	}
+	return buckets, rows.Err()
+}
+
+func (r *SQLiteTelemetryRepository) DeleteReadings(ctx context.Context, filter models.ReadingDeleteFilter) error {
+	args := []interface{}{filter.Before.UnixNano()}
+	query := `DELETE FROM telemetry_readings WHERE recorded_at < ?`
+	if filter.FeatureID != nil {
+		args = append(args, filter.FeatureID.String())
+		query += ` AND feature_id = ?`
+	}
+	if len(filter.ExceptFeatureIDs) > 0 {
+		except := make([]string, len(filter.ExceptFeatureIDs))
+		for i, id := range filter.ExceptFeatureIDs {
+			except[i] = id.String()
+		}
+		encoded, err := encodeIDList(except)
+		if err != nil {
+			return err
+		}
+		args = append(args, encoded)
+		query += ` AND feature_id NOT IN (SELECT value FROM json_each(?))`
+	}
+
+	_, err := database.SQLConn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *SQLiteTelemetryRepository) SetRetentionPolicy(ctx context.Context, policy *models.RetentionPolicy) (*models.RetentionPolicy, error) {
+	query := `
+		INSERT INTO telemetry_retention_policies (feature_id, retention_seconds, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (feature_id) DO UPDATE SET retention_seconds = excluded.retention_seconds, updated_at = excluded.updated_at
+		RETURNING ` + retentionPolicyColumns
+
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		policy.FeatureID.String(),
+		int64(policy.Retention/time.Second),
+		formatTime(policy.UpdatedAt),
+	)
+	stored, err := scanRetentionPolicy(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return stored, nil
+}
+
+func (r *SQLiteTelemetryRepository) ListRetentionPolicies(ctx context.Context) ([]*models.RetentionPolicy, error) {
+	query := `SELECT ` + retentionPolicyColumns + ` FROM telemetry_retention_policies ORDER BY feature_id`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []*models.RetentionPolicy
+	for rows.Next() {
+		policy, err := scanRetentionPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+func (r *SQLiteTelemetryRepository) DeleteRetentionPolicy(ctx context.Context, featureID uuid.UUID) error {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM telemetry_retention_policies WHERE feature_id = ?`, featureID.String())
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func scanRetentionPolicy(row rowScanner) (*models.RetentionPolicy, error) {
+	var policy models.RetentionPolicy
+	var featureID string
+	var seconds int64
+	if err := row.Scan(&featureID, &seconds, timestamp{dest: &policy.UpdatedAt}); err != nil {
+		return nil, err
+	}
+	parsed, err := uuid.Parse(featureID)
+	if err != nil {
+		return nil, err
+	}
+	policy.FeatureID = parsed
+	policy.Retention = time.Duration(seconds) * time.Second
+	return &policy, nil
+}
@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"smart-hub/internal/common/database"
+)
+
+type SQLiteUnitOfWork struct {
+	db *sql.DB
+}
+
+func NewSQLiteUnitOfWork(db *database.SQLiteDB) *SQLiteUnitOfWork {
+	return &SQLiteUnitOfWork{
+		db: db.GetDB(),
+	}
+}
+
+func (u *SQLiteUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := database.SQLTxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(database.ContextWithSQLTx(ctx, tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
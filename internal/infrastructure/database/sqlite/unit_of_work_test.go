@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestSQLiteUnitOfWork_RollbackOnError(t *testing.T) {
+	db := setupTestDB(t)
+	uow := NewSQLiteUnitOfWork(db)
+	modelRepo := NewSQLiteSmartModelRepository(db)
+	outbox := NewSQLiteOutboxRepository(db)
+	failure := errors.New("boom")
+
+	err := uow.Do(context.Background(), func(ctx context.Context) error {
+		model, err := modelRepo.Create(ctx, newTestModel())
+		if err != nil {
+			return err
+		}
+		event, err := models.NewDomainEvent(models.SmartModelAggregate, model.ID, models.ModelCreatedEvent, model)
+		if err != nil {
+			return err
+		}
+		if err := outbox.Add(ctx, event); err != nil {
+			return err
+		}
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	all, err := modelRepo.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, all)
+
+	pending, err := outbox.FetchPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestSQLiteOutboxRepository(t *testing.T) {
+	db := setupTestDB(t)
+	uow := NewSQLiteUnitOfWork(db)
+	outbox := NewSQLiteOutboxRepository(db)
+	ctx := context.Background()
+
+	model := newTestModel()
+	first, err := models.NewDomainEvent(models.SmartModelAggregate, model.ID, models.ModelCreatedEvent, model)
+	require.NoError(t, err)
+	second, err := models.NewDomainEvent(models.SmartModelAggregate, model.ID, models.ModelDeletedEvent, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, uow.Do(ctx, func(ctx context.Context) error {
+		return outbox.Add(ctx, first, second)
+	}))
+
+	pending, err := outbox.FetchPending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, first.ID, pending[0].ID)
+	assert.JSONEq(t, string(first.Payload), string(pending[0].Payload))
+	assert.True(t, first.OccurredAt.Equal(pending[0].OccurredAt))
+
+	until := time.Now().Add(time.Minute)
+	require.NoError(t, outbox.Claim(ctx, []int64{pending[0].Sequence, pending[1].Sequence}, until))
+	pending, err = outbox.FetchPending(ctx, 10)
+	require.NoError(t, err)
+	require.NotNil(t, pending[1].ClaimedUntil)
+	assert.True(t, until.Equal(*pending[1].ClaimedUntil))
+
+	require.NoError(t, outbox.MarkFailed(ctx, pending[1].Sequence, "sink down", false))
+	require.NoError(t, outbox.MarkPublished(ctx, []int64{pending[0].Sequence}))
+
+	pending, err = outbox.FetchPending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, second.ID, pending[0].ID)
+	assert.Equal(t, 1, pending[0].Attempts)
+	assert.Equal(t, "sink down", pending[0].LastError)
+	assert.Nil(t, pending[0].ClaimedUntil)
+
+	require.NoError(t, outbox.MarkFailed(ctx, pending[0].Sequence, "sink down", true))
+	pending, err = outbox.FetchPending(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
@@ -0,0 +1,285 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/google/uuid"
+	"smart-hub/internal/common/database"
+	"smart-hub/internal/domain/models"
+	"strings"
+	"time"
+)
+
+const webhookSubscriptionColumns = `id, url, event_types, secret, COALESCE(description, ''), active, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, COALESCE(last_status_code, 0), COALESCE(last_error, ''), next_attempt_at, delivered_at, created_at`
+
+type SQLiteWebhookRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteWebhookRepository(db *database.SQLiteDB) *SQLiteWebhookRepository {
+	return &SQLiteWebhookRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SQLiteWebhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (id, url, event_types, secret, description, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + webhookSubscriptionColumns
+
+	eventTypes, err := encodeEventTypes(subscription.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		subscription.ID.String(),
+		subscription.URL,
+		eventTypes,
+		subscription.Secret,
+		subscription.Description,
+		subscription.Active,
+		formatTime(subscription.CreatedAt),
+		formatTime(subscription.UpdatedAt),
+	)
+	return scanWebhookSubscription(row)
+}
+
+func (r *SQLiteWebhookRepository) GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = ?`
+
+	return scanWebhookSubscription(database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteWebhookRepository) ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions ORDER BY created_at`
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*models.WebhookSubscription
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (r *SQLiteWebhookRepository) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = ?, event_types = ?, description = ?, active = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING ` + webhookSubscriptionColumns
+
+	eventTypes, err := encodeEventTypes(subscription.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	row := database.SQLConn(ctx, r.db).QueryRowContext(ctx, query,
+		subscription.URL,
+		eventTypes,
+		subscription.Description,
+		subscription.Active,
+		formatTime(subscription.UpdatedAt),
+		subscription.ID.String(),
+	)
+	return scanWebhookSubscription(row)
+}
+
+func (r *SQLiteWebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = ?`, id.String())
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func (r *SQLiteWebhookRepository) EnqueueDeliveries(ctx context.Context, deliveries ...*models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`
+
+	conn := database.SQLConn(ctx, r.db)
+	for _, delivery := range deliveries {
+		_, err := conn.ExecContext(ctx, query,
+			delivery.ID.String(),
+			delivery.SubscriptionID.String(),
+			delivery.EventID.String(),
+			delivery.EventType,
+			string(delivery.Payload),
+			delivery.Status,
+			formatTime(delivery.NextAttemptAt),
+			formatTime(delivery.CreatedAt),
+		)
+		if err != nil {
+			return mapError(err)
+		}
+	}
+
+	return nil
+}
+
+// ClaimDueDeliveries needs no row locks: SQLite has a single writer, so the
+// UPDATE excludes other dispatchers.
+func (r *SQLiteWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, leaseUntil time.Time) ([]*models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id AND s.active = 1
+			WHERE d.status IN ('pending', 'failed') AND d.next_attempt_at <= ?
+			ORDER BY d.next_attempt_at
+			LIMIT ?
+		)
+		RETURNING ` + webhookDeliveryColumns
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, formatTime(leaseUntil), formatTime(time.Now()), limit)
+	if err != nil {
+		return nil, err
+	}
+	return collectWebhookDeliveries(rows)
+}
+
+func (r *SQLiteWebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, last_status_code = NULLIF(?, 0), last_error = NULLIF(?, ''), next_attempt_at = ?, delivered_at = ?
+		WHERE id = ?
+	`
+
+	result, err := database.SQLConn(ctx, r.db).ExecContext(ctx, query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		formatTime(delivery.NextAttemptAt),
+		formatNullTime(delivery.DeliveredAt),
+		delivery.ID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result)
+}
+
+func (r *SQLiteWebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = ?`
+
+	return scanWebhookDelivery(database.SQLConn(ctx, r.db).QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteWebhookRepository) ListDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.SubscriptionID != nil {
+		conditions = append(conditions, "subscription_id = ?")
+		args = append(args, filter.SubscriptionID.String())
+	}
+	if filter.Status != nil {
+		conditions = append(conditions, "status = ?")
+		args = append(args, *filter.Status)
+	}
+
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY created_at DESC LIMIT ?`
+	args = append(args, filter.Limit)
+
+	rows, err := database.SQLConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectWebhookDeliveries(rows)
+}
+
+func scanWebhookSubscription(row rowScanner) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	var eventTypes string
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&eventTypes,
+		&subscription.Secret,
+		&subscription.Description,
+		&subscription.Active,
+		timestamp{&subscription.CreatedAt},
+		timestamp{&subscription.UpdatedAt},
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	if err := json.Unmarshal([]byte(eventTypes), &subscription.EventTypes); err != nil {
+		return nil, err
+	}
+	if len(subscription.EventTypes) == 0 {
+		subscription.EventTypes = nil
+	}
+	return &subscription, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var payload string
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		timestamp{&delivery.NextAttemptAt},
+		nullTimestamp{&delivery.DeliveredAt},
+		timestamp{&delivery.CreatedAt},
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	delivery.Payload = []byte(payload)
+	return &delivery, nil
+}
+
+func collectWebhookDeliveries(rows *sql.Rows) ([]*models.WebhookDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func encodeEventTypes(eventTypes []models.EventType) (string, error) {
+	if eventTypes == nil {
+		eventTypes = []models.EventType{}
+	}
+	data, err := json.Marshal(eventTypes)
+	return string(data), err
+}
@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestSQLiteWebhookRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSQLiteWebhookRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	subscription, err := repo.CreateSubscription(ctx, &models.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        "https://example.com/hook",
+		EventTypes: []models.EventType{models.ModelCreatedEvent},
+		Secret:     "0123456789abcdef",
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []models.EventType{models.ModelCreatedEvent}, subscription.EventTypes)
+	assert.True(t, subscription.Active)
+
+	delivery := &models.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		EventID:        uuid.New(),
+		EventType:      models.ModelCreatedEvent,
+		Payload:        []byte(`{"id":"1"}`),
+		Status:         models.DeliveryPending,
+		NextAttemptAt:  now.Add(-time.Second),
+		CreatedAt:      now,
+	}
+	duplicate := *delivery
+	duplicate.ID = uuid.New()
+	require.NoError(t, repo.EnqueueDeliveries(ctx, delivery, &duplicate))
+
+	lease := now.Add(time.Minute)
+	claimed, err := repo.ClaimDueDeliveries(ctx, 10, lease)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, delivery.ID, claimed[0].ID)
+	assert.True(t, lease.Equal(claimed[0].NextAttemptAt))
+
+	again, err := repo.ClaimDueDeliveries(ctx, 10, lease)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	claimed[0].Status = models.DeliverySucceeded
+	claimed[0].Attempts = 1
+	claimed[0].LastStatusCode = 200
+	claimed[0].DeliveredAt = &now
+	require.NoError(t, repo.UpdateDelivery(ctx, claimed[0]))
+
+	succeeded := models.DeliverySucceeded
+	deliveries, err := repo.ListDeliveries(ctx, models.WebhookDeliveryFilter{SubscriptionID: &subscription.ID, Status: &succeeded, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, 200, deliveries[0].LastStatusCode)
+	require.NotNil(t, deliveries[0].DeliveredAt)
+	assert.True(t, now.Equal(*deliveries[0].DeliveredAt))
+
+	require.NoError(t, repo.DeleteSubscription(ctx, subscription.ID))
+
+	_, err = repo.GetDelivery(ctx, delivery.ID)
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+	assert.True(t, errors.Is(repo.DeleteSubscription(ctx, subscription.ID), models.ErrNotFound))
+}
@@ -0,0 +1,129 @@
+package messaging
+
+import (
+	"context"
+	"smart-hub/internal/domain/models"
+	"sync"
+)
+
+// ChannelPublisher fans events out to in-process subscribers. Publish blocks
+// until every subscriber has received the event or ctx is done, so a slow
+// subscriber holds back the relay rather than losing events. A subscriber
+// that stops reading may unsubscribe at any time, even while Publish is
+// blocked on it.
+type ChannelPublisher struct {
+	mu          sync.RWMutex
+	subscribers map[int]*subscriber
+	nextID      int
+	closed      bool
+}
+
+// subscriber is a subscription channel. mu serialises sends with closing
+// the channel, and done is closed first so that a blocked send gives up and
+// releases mu.
+type subscriber struct {
+	mu     sync.Mutex
+	ch     chan *models.DomainEvent
+	done   chan struct{}
+	closed bool
+}
+
+func (s *subscriber) send(ctx context.Context, event *models.DomainEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	select {
+	case s.ch <- event:
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (s *subscriber) close() {
+	close(s.done)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
+
+func NewChannelPublisher() *ChannelPublisher {
+	return &ChannelPublisher{
+		subscribers: make(map[int]*subscriber),
+	}
+}
+
+// Subscribe returns a channel receiving every published event and a function
+// that removes the subscription.
+func (p *ChannelPublisher) Subscribe(buffer int) (<-chan *models.DomainEvent, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch := make(chan *models.DomainEvent, buffer)
+	if p.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	id := p.nextID
+	p.nextID++
+	p.subscribers[id] = &subscriber{ch: ch, done: make(chan struct{})}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			p.mu.Lock()
+			sub, ok := p.subscribers[id]
+			delete(p.subscribers, id)
+			p.mu.Unlock()
+			if ok {
+				sub.close()
+			}
+		})
+	}
+}
+
+// Subscribers returns the number of current subscriptions.
+func (p *ChannelPublisher) Subscribers() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.subscribers)
+}
+
+func (p *ChannelPublisher) Publish(ctx context.Context, event *models.DomainEvent) error {
+	p.mu.RLock()
+	subscribers := make([]*subscriber, 0, len(p.subscribers))
+	for _, sub := range p.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	p.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		if err := sub.send(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ChannelPublisher) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	subscribers := p.subscribers
+	p.subscribers = make(map[int]*subscriber)
+	p.mu.Unlock()
+
+	for _, sub := range subscribers {
+		sub.close()
+	}
+	return nil
+}
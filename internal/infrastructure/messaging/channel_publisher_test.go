@@ -0,0 +1,96 @@
+package messaging
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func newTestEvent(t *testing.T) *models.DomainEvent {
+	event, err := models.NewDomainEvent(models.SmartModelAggregate, uuid.New(), models.ModelCreatedEvent, map[string]string{"name": "Test"})
+	require.NoError(t, err)
+	return event
+}
+
+func TestChannelPublisher_FanOut(t *testing.T) {
+	publisher := NewChannelPublisher()
+	defer publisher.Close()
+
+	first, cancelFirst := publisher.Subscribe(1)
+	defer cancelFirst()
+	second, cancelSecond := publisher.Subscribe(1)
+	defer cancelSecond()
+
+	event := newTestEvent(t)
+	require.NoError(t, publisher.Publish(context.Background(), event))
+
+	assert.Equal(t, event, <-first)
+	assert.Equal(t, event, <-second)
+}
+
+func TestChannelPublisher_BlocksOnFullSubscriber(t *testing.T) {
+	publisher := NewChannelPublisher()
+	defer publisher.Close()
+
+	_, cancel := publisher.Subscribe(0)
+	defer cancel()
+
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelCtx()
+
+	err := publisher.Publish(ctx, newTestEvent(t))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestChannelPublisher_Unsubscribe(t *testing.T) {
+	publisher := NewChannelPublisher()
+	defer publisher.Close()
+
+	ch, cancel := publisher.Subscribe(0)
+	cancel()
+
+	_, open := <-ch
+	assert.False(t, open)
+	assert.NoError(t, publisher.Publish(context.Background(), newTestEvent(t)))
+}
+
+func TestChannelPublisher_UnsubscribeWhilePublishing(t *testing.T) {
+	publisher := NewChannelPublisher()
+	defer publisher.Close()
+
+	_, cancel := publisher.Subscribe(0)
+	other, cancelOther := publisher.Subscribe(1)
+	defer cancelOther()
+
+	published := make(chan error, 1)
+	go func() {
+		published <- publisher.Publish(context.Background(), newTestEvent(t))
+	}()
+
+	// Publish blocks on the subscriber that never reads until it
+	// unsubscribes, which must not wait for Publish in turn.
+	time.Sleep(20 * time.Millisecond)
+	unsubscribed := make(chan struct{})
+	go func() {
+		cancel()
+		close(unsubscribed)
+	}()
+
+	select {
+	case <-unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("unsubscribe blocked on a pending publish")
+	}
+	select {
+	case err := <-published:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("publish did not return after the subscriber left")
+	}
+	assert.Len(t, other, 1)
+	assert.Equal(t, 1, publisher.Subscribers())
+}
@@ -0,0 +1,45 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/segmentio/kafka-go"
+	"smart-hub/internal/domain/models"
+)
+
+// KafkaPublisher writes events to a single topic keyed by aggregate ID, so all
+// events of one aggregate land on the same partition in order.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+	}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event *models.DomainEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.AggregateID.String()),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "event_id", Value: []byte(event.ID.String())},
+			{Key: "event_type", Value: []byte(event.Type)},
+		},
+	})
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,36 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"smart-hub/internal/domain/interfaces"
+	"smart-hub/internal/domain/models"
+)
+
+// MultiPublisher hands every event to several publishers in order. It stops
+// at the first failure and the relay retries the event, so publishers that
+// already accepted it will see it again.
+type MultiPublisher struct {
+	publishers []interfaces.EventPublisher
+}
+
+func NewMultiPublisher(publishers ...interfaces.EventPublisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+func (p *MultiPublisher) Publish(ctx context.Context, event *models.DomainEvent) error {
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *MultiPublisher) Close() error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		errs = append(errs, publisher.Close())
+	}
+	return errors.Join(errs...)
+}
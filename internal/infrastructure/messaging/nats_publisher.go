@@ -0,0 +1,48 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nats-io/nats.go"
+	"smart-hub/internal/domain/models"
+)
+
+// NatsPublisher publishes each event to "<prefix>.<event type>", e.g.
+// "smart-hub.model.created". The event ID is sent as Nats-Msg-Id so JetStream
+// streams can drop redeliveries.
+type NatsPublisher struct {
+	conn          *nats.Conn
+	subjectPrefix string
+}
+
+func NewNatsPublisher(url string, subjectPrefix string) (*NatsPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("smart-hub-outbox"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &NatsPublisher{
+		conn:          conn,
+		subjectPrefix: subjectPrefix,
+	}, nil
+}
+
+func (p *NatsPublisher) Publish(ctx context.Context, event *models.DomainEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(p.subjectPrefix + "." + string(event.Type))
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, event.ID.String())
+
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return p.conn.FlushWithContext(ctx)
+}
+
+func (p *NatsPublisher) Close() error {
+	return p.conn.Drain()
+}
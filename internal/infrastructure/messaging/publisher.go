@@ -0,0 +1,34 @@
+package messaging
+
+import (
+	"fmt"
+	"smart-hub/config"
+	"smart-hub/internal/domain/interfaces"
+	"strings"
+)
+
+const (
+	SinkChannel = "channel"
+	SinkNats    = "nats"
+	SinkKafka   = "kafka"
+	SinkWebhook = "webhook"
+)
+
+// NewPublisher builds the EventPublisher selected by cfg.Sink.
+func NewPublisher(cfg *config.EventsConfig) (interfaces.EventPublisher, error) {
+	switch strings.ToLower(cfg.Sink) {
+	case SinkChannel, "":
+		return NewChannelPublisher(), nil
+	case SinkNats:
+		return NewNatsPublisher(cfg.NatsURL, cfg.NatsSubject)
+	case SinkKafka:
+		return NewKafkaPublisher(cfg.KafkaBrokers, cfg.KafkaTopic), nil
+	case SinkWebhook:
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("EVENTS_WEBHOOK_URL is required for the webhook sink")
+		}
+		return NewWebhookPublisher(cfg.WebhookURL, cfg.WebhookTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown events sink %q", cfg.Sink)
+	}
+}
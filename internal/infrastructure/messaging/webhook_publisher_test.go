@@ -0,0 +1,46 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestWebhookPublisher_Publish(t *testing.T) {
+	var received models.DomainEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, string(models.ModelCreatedEvent), r.Header.Get("X-Event-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	publisher := NewWebhookPublisher(server.URL, time.Second)
+	event := newTestEvent(t)
+
+	err := publisher.Publish(context.Background(), event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, event.ID, received.ID)
+	assert.Equal(t, event.AggregateID, received.AggregateID)
+}
+
+func TestWebhookPublisher_Publish_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	publisher := NewWebhookPublisher(server.URL, time.Second)
+
+	err := publisher.Publish(context.Background(), newTestEvent(t))
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"smart-hub/internal/domain/models"
+	"strconv"
+	"time"
+)
+
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+)
+
+// HTTPWebhookSender POSTs deliveries to subscriber endpoints. The body is
+// signed with the subscription secret: X-Webhook-Signature carries
+// "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>", where
+// timestamp is the X-Webhook-Timestamp header in Unix seconds.
+type HTTPWebhookSender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewHTTPWebhookSender(timeout time.Duration) *HTTPWebhookSender {
+	return &HTTPWebhookSender{
+		client: &http.Client{Timeout: timeout},
+		now:    time.Now,
+	}
+}
+
+func (s *HTTPWebhookSender) Send(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "smart-hub-webhooks")
+	req.Header.Set("X-Webhook-Id", subscription.ID.String())
+	req.Header.Set("X-Delivery-Id", delivery.ID.String())
+	req.Header.Set("X-Event-Id", delivery.EventID.String())
+	req.Header.Set("X-Event-Type", string(delivery.EventType))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the X-Webhook-Signature value for body. Receivers
+// recompute it with their copy of the secret and compare in constant time.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
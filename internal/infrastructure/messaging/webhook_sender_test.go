@@ -0,0 +1,63 @@
+package messaging
+
+import (
+	"context"
+	"crypto/hmac"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"smart-hub/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestHTTPWebhookSender_Send(t *testing.T) {
+	const secret = "0123456789abcdef"
+	payload := []byte(`{"id":"event"}`)
+
+	var body []byte
+	var signature, timestamp string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+		signature = r.Header.Get(WebhookSignatureHeader)
+		timestamp = r.Header.Get(WebhookTimestampHeader)
+		assert.Equal(t, string(models.ModelCreatedEvent), r.Header.Get("X-Event-Type"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewHTTPWebhookSender(time.Second)
+	sender.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	subscription := &models.WebhookSubscription{ID: uuid.New(), URL: server.URL, Secret: secret}
+	delivery := &models.WebhookDelivery{ID: uuid.New(), EventID: uuid.New(), EventType: models.ModelCreatedEvent, Payload: payload}
+
+	code, err := sender.Send(context.Background(), subscription, delivery)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Equal(t, payload, body)
+	assert.Equal(t, "1700000000", timestamp)
+	assert.True(t, hmac.Equal([]byte(SignWebhookPayload(secret, timestamp, body)), []byte(signature)))
+	assert.NotEqual(t, SignWebhookPayload("another-secret-value", timestamp, body), signature)
+}
+
+func TestHTTPWebhookSender_Send_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sender := NewHTTPWebhookSender(time.Second)
+	subscription := &models.WebhookSubscription{ID: uuid.New(), URL: server.URL, Secret: "0123456789abcdef"}
+
+	code, err := sender.Send(context.Background(), subscription, &models.WebhookDelivery{ID: uuid.New(), Payload: []byte(`{}`)})
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
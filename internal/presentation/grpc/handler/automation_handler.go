@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "smart-hub/gen/proto/automation/v1"
+	"smart-hub/internal/application/interfaces"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+)
+
+type AutomationHandler struct {
+	pb.UnimplementedAutomationServiceServer
+	service interfaces.AutomationService
+	mapper  mapper.AutomationMapper
+}
+
+func NewAutomationHandler(
+	service interfaces.AutomationService,
+	mapper mapper.AutomationMapper,
+) *AutomationHandler {
+	return &AutomationHandler{
+		service: service,
+		mapper:  mapper,
+	}
+}
+
+func (h *AutomationHandler) CreateAutomationRule(ctx context.Context, req *pb.CreateAutomationRuleRequest) (*pb.CreateAutomationRuleResponse, error) {
+	logger.FromContext(ctx).Debug("Creating automation rule", "name", req.GetRule().GetName())
+
+	rule, err := h.mapper.ToDomain(req.Rule)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid rule: "+err.Error())
+	}
+	rule.ID = uuid.Nil
+
+	created, err := h.service.CreateRule(ctx, rule)
+	if err != nil {
+		return nil, automationError(ctx, err, "failed to create automation rule")
+	}
+
+	protoRule, err := h.toProto(ctx, created)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateAutomationRuleResponse{Rule: protoRule}, nil
+}
+
+func (h *AutomationHandler) GetAutomationRule(ctx context.Context, req *pb.GetAutomationRuleRequest) (*pb.GetAutomationRuleResponse, error) {
+	logger.FromContext(ctx).Debug("Getting automation rule", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	rule, err := h.service.GetRule(ctx, id)
+	if err != nil {
+		return nil, automationError(ctx, err, "failed to get automation rule")
+	}
+
+	protoRule, err := h.toProto(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetAutomationRuleResponse{Rule: protoRule}, nil
+}
+
+func (h *AutomationHandler) ListAutomationRules(ctx context.Context, req *pb.ListAutomationRulesRequest) (*pb.ListAutomationRulesResponse, error) {
+	logger.FromContext(ctx).Debug("Listing automation rules")
+
+	rules, err := h.service.ListRules(ctx)
+	if err != nil {
+		return nil, automationError(ctx, err, "failed to list automation rules")
+	}
+
+	protoRules, err := h.mapper.ToProtoList(rules)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to convert automation rules to proto", "error", err)
+		return nil, status.Error(codes.Internal, "failed to convert automation rules to proto")
+	}
+	return &pb.ListAutomationRulesResponse{Rules: protoRules}, nil
+}
+
+func (h *AutomationHandler) UpdateAutomationRule(ctx context.Context, req *pb.UpdateAutomationRuleRequest) (*pb.UpdateAutomationRuleResponse, error) {
+	logger.FromContext(ctx).Debug("Updating automation rule", "id", req.GetRule().GetId())
+
+	rule, err := h.mapper.ToDomain(req.Rule)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid rule: "+err.Error())
+	}
+	if rule.ID == uuid.Nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid rule: id is required")
+	}
+
+	updated, err := h.service.UpdateRule(ctx, rule)
+	if err != nil {
+		return nil, automationError(ctx, err, "failed to update automation rule")
+	}
+
+	protoRule, err := h.toProto(ctx, updated)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateAutomationRuleResponse{Rule: protoRule}, nil
+}
+
+func (h *AutomationHandler) DeleteAutomationRule(ctx context.Context, req *pb.DeleteAutomationRuleRequest) (*pb.DeleteAutomationRuleResponse, error) {
+	logger.FromContext(ctx).Debug("Deleting automation rule", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := h.service.DeleteRule(ctx, id); err != nil {
+		return nil, automationError(ctx, err, "failed to delete automation rule")
+	}
+
+	return &pb.DeleteAutomationRuleResponse{}, nil
+}
+
+func (h *AutomationHandler) ListRuleExecutions(ctx context.Context, req *pb.ListRuleExecutionsRequest) (*pb.ListRuleExecutionsResponse, error) {
+	logger.FromContext(ctx).Debug("Listing automation rule executions", "request", req)
+
+	var ruleID *uuid.UUID
+	if req.RuleId != "" {
+		id, err := uuid.Parse(req.RuleId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		ruleID = &id
+	}
+	pageSize := int(req.PageSize)
+	switch {
+	case pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	executions, nextPageToken, err := h.service.ListExecutions(ctx, ruleID, pageSize, req.PageToken)
+	if err != nil {
+		return nil, automationError(ctx, err, "failed to list automation rule executions")
+	}
+
+	protoExecutions, err := h.mapper.ToExecutionProtoList(executions)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to convert automation executions to proto", "error", err)
+		return nil, status.Error(codes.Internal, "failed to convert automation executions to proto")
+	}
+	return &pb.ListRuleExecutionsResponse{Executions: protoExecutions, NextPageToken: nextPageToken}, nil
+}
+
+func (h *AutomationHandler) toProto(ctx context.Context, rule *models.AutomationRule) (*pb.AutomationRule, error) {
+	protoRule, err := h.mapper.ToProto(rule)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to convert automation rule to proto", "error", err)
+		return nil, status.Error(codes.Internal, "failed to convert automation rule to proto")
+	}
+	return protoRule, nil
+}
+
+func automationError(ctx context.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidAutomationRule), errors.Is(err, models.ErrInvalidPageToken):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, "automation rule not found")
+	}
+	logger.FromContext(ctx).Error(message, "error", err)
+	return status.Error(codes.Internal, message)
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	pb "smart-hub/gen/proto/automation/v1"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+	"testing"
+	"time"
+)
+
+type mockAutomationService struct {
+	mock.Mock
+}
+
+func (m *mockAutomationService) CreateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	args := m.Called(ctx, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AutomationRule), args.Error(1)
+}
+
+func (m *mockAutomationService) GetRule(ctx context.Context, id uuid.UUID) (*models.AutomationRule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AutomationRule), args.Error(1)
+}
+
+func (m *mockAutomationService) ListRules(ctx context.Context) ([]*models.AutomationRule, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AutomationRule), args.Error(1)
+}
+
+func (m *mockAutomationService) UpdateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	args := m.Called(ctx, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AutomationRule), args.Error(1)
+}
+
+func (m *mockAutomationService) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockAutomationService) ListExecutions(ctx context.Context, ruleID *uuid.UUID, pageSize int, pageToken string) ([]*models.AutomationExecution, string, error) {
+	args := m.Called(ctx, ruleID, pageSize, pageToken)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).([]*models.AutomationExecution), args.String(1), args.Error(2)
+}
+
+func TestCreateAutomationRule_Success(t *testing.T) {
+	mockService := new(mockAutomationService)
+	handler := NewAutomationHandler(mockService, mapper.NewAutomationMapper())
+
+	featureID := uuid.New()
+	created := &models.AutomationRule{ID: uuid.New(), Name: "Night light"}
+	mockService.On("CreateRule", mock.Anything, mock.MatchedBy(func(rule *models.AutomationRule) bool {
+		return rule.ID == uuid.Nil &&
+			rule.Name == "Night light" &&
+			rule.Trigger.Type == models.TriggerTelemetry &&
+			rule.Trigger.Operator == models.OperatorLessEqual &&
+			rule.Trigger.Threshold == 10 &&
+			rule.Actions[0].FeatureID == featureID &&
+			rule.Actions[0].Parameters["on"] == true &&
+			rule.Cooldown == 5*time.Minute
+	})).Return(&models.AutomationRule{
+		ID:      created.ID,
+		Name:    "Night light",
+		Trigger: models.RuleTrigger{Type: models.TriggerStateChange, FeatureID: featureID, Value: "motion"},
+		Actions: []models.RuleAction{{DeviceID: "light-1", FeatureID: featureID}},
+	}, nil)
+
+	parameters, err := structpb.NewStruct(map[string]interface{}{"on": true})
+	require.NoError(t, err)
+	resp, err := handler.CreateAutomationRule(context.Background(), &pb.CreateAutomationRuleRequest{Rule: &pb.AutomationRule{
+		Id:   uuid.New().String(),
+		Name: "Night light",
+		Trigger: &pb.Trigger{Kind: &pb.Trigger_TelemetryThreshold{TelemetryThreshold: &pb.TelemetryThreshold{
+			FeatureId: uuid.New().String(),
+			Operator:  pb.ThresholdOperator_LESS_THAN_OR_EQUAL,
+			Threshold: 10,
+		}}},
+		Actions:  []*pb.RuleAction{{DeviceId: "light-1", FeatureId: featureID.String(), Parameters: parameters}},
+		Cooldown: durationpb.New(5 * time.Minute),
+	}})
+
+	require.NoError(t, err)
+	assert.Equal(t, created.ID.String(), resp.Rule.Id)
+	assert.Equal(t, "motion", resp.Rule.Trigger.GetStateChange().GetValue().GetStringValue())
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateAutomationRule_InvalidRequest(t *testing.T) {
+	handler := NewAutomationHandler(new(mockAutomationService), mapper.NewAutomationMapper())
+
+	tests := []struct {
+		name string
+		rule *pb.AutomationRule
+	}{
+		{"missing rule", nil},
+		{"malformed trigger feature", &pb.AutomationRule{Trigger: &pb.Trigger{Kind: &pb.Trigger_StateChange{StateChange: &pb.StateChange{FeatureId: "power"}}}}},
+		{"malformed action feature", &pb.AutomationRule{Actions: []*pb.RuleAction{{DeviceId: "light-1", FeatureId: "power"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := handler.CreateAutomationRule(context.Background(), &pb.CreateAutomationRuleRequest{Rule: tt.rule})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestUpdateAutomationRule_Errors(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"invalid rule", fmt.Errorf("%w: condition: unexpected end of condition", models.ErrInvalidAutomationRule), codes.InvalidArgument},
+		{"not found", models.ErrNotFound, codes.NotFound},
+		{"internal", errors.New("connection reset"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockAutomationService)
+			handler := NewAutomationHandler(mockService, mapper.NewAutomationMapper())
+			mockService.On("UpdateRule", mock.Anything, mock.MatchedBy(func(rule *models.AutomationRule) bool {
+				return rule.ID == id
+			})).Return(nil, tt.err)
+
+			resp, err := handler.UpdateAutomationRule(context.Background(), &pb.UpdateAutomationRuleRequest{Rule: &pb.AutomationRule{Id: id.String()}})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+
+	handler := NewAutomationHandler(new(mockAutomationService), mapper.NewAutomationMapper())
+	_, err := handler.UpdateAutomationRule(context.Background(), &pb.UpdateAutomationRuleRequest{Rule: &pb.AutomationRule{}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestListRuleExecutions_Success(t *testing.T) {
+	mockService := new(mockAutomationService)
+	handler := NewAutomationHandler(mockService, mapper.NewAutomationMapper())
+
+	ruleID := uuid.New()
+	featureID := uuid.New()
+	startedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	finishedAt := startedAt.Add(time.Second)
+	mockService.On("ListExecutions", mock.Anything, &ruleID, defaultPageSize, "token-1").Return([]*models.AutomationExecution{
+		{
+			ID:         uuid.New(),
+			RuleID:     ruleID,
+			Trigger:    map[string]interface{}{"type": "schedule", "at": "2024-03-01T13:00:00+01:00"},
+			Status:     models.ExecutionFailed,
+			Error:      "1 of 1 actions failed",
+			Results:    []models.ActionResult{{DeviceID: "light-1", FeatureID: featureID, Error: "device offline"}},
+			StartedAt:  startedAt,
+			FinishedAt: &finishedAt,
+		},
+		{ID: uuid.New(), RuleID: ruleID, Status: models.ExecutionRunning, StartedAt: startedAt},
+	}, "token-2", nil)
+
+	resp, err := handler.ListRuleExecutions(context.Background(), &pb.ListRuleExecutionsRequest{RuleId: ruleID.String(), PageToken: "token-1"})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Executions, 2)
+	assert.Equal(t, "token-2", resp.NextPageToken)
+	assert.Equal(t, pb.ExecutionStatus_FAILED, resp.Executions[0].Status)
+	assert.Equal(t, "schedule", resp.Executions[0].Trigger.AsMap()["type"])
+	assert.Equal(t, "device offline", resp.Executions[0].Results[0].Error)
+	assert.Equal(t, finishedAt, resp.Executions[0].FinishedAt.AsTime())
+	assert.Equal(t, pb.ExecutionStatus_RUNNING, resp.Executions[1].Status)
+	assert.Nil(t, resp.Executions[1].FinishedAt)
+	mockService.AssertExpectations(t)
+}
+
+func TestListRuleExecutions_InvalidRequest(t *testing.T) {
+	mockService := new(mockAutomationService)
+	handler := NewAutomationHandler(mockService, mapper.NewAutomationMapper())
+	mockService.On("ListExecutions", mock.Anything, (*uuid.UUID)(nil), maxPageSize, "bad").Return(nil, "", models.ErrInvalidPageToken)
+
+	_, err := handler.ListRuleExecutions(context.Background(), &pb.ListRuleExecutionsRequest{PageSize: 5000, PageToken: "bad"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = handler.ListRuleExecutions(context.Background(), &pb.ListRuleExecutionsRequest{RuleId: "rule-1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = handler.ListRuleExecutions(context.Background(), &pb.ListRuleExecutionsRequest{PageSize: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteAutomationRule_NotFound(t *testing.T) {
+	mockService := new(mockAutomationService)
+	handler := NewAutomationHandler(mockService, mapper.NewAutomationMapper())
+	id := uuid.New()
+	mockService.On("DeleteRule", mock.Anything, id).Return(models.ErrNotFound)
+
+	resp, err := handler.DeleteAutomationRule(context.Background(), &pb.DeleteAutomationRuleRequest{Id: id.String()})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
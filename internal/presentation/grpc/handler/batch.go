@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+)
+
+// maxBatchSize caps the items of one batch request, which all go through a
+// single transaction.
+const maxBatchSize = 1000
+
+func validateBatchSize(n int) error {
+	if n == 0 {
+		return status.Error(codes.InvalidArgument, "batch is empty")
+	}
+	if n > maxBatchSize {
+		return status.Errorf(codes.InvalidArgument, "batch has %d items, the limit is %d", n, maxBatchSize)
+	}
+	return nil
+}
+
+// batchValidation tracks which items of a batch request passed validation.
+// Only those reach the service; the results it returns are mapped back to
+// request order with scatter.
+type batchValidation struct {
+	partial bool
+	valid   []int
+	errs    []error
+}
+
+func newBatchValidation(n int, partial bool) *batchValidation {
+	return &batchValidation{
+		partial: partial,
+		valid:   make([]int, 0, n),
+		errs:    make([]error, n),
+	}
+}
+
+// check records the validation outcome of item i. An invalid item ends an
+// all-or-nothing batch with the returned InvalidArgument status.
+func (v *batchValidation) check(i int, err error) error {
+	if err == nil {
+		v.valid = append(v.valid, i)
+		return nil
+	}
+	if !v.partial {
+		return status.Errorf(codes.InvalidArgument, "item %d: %v", i, err)
+	}
+	v.errs[i] = status.Error(codes.InvalidArgument, err.Error())
+	return nil
+}
+
+// merge adds the per-item errors of the service call to the validation
+// errors.
+func (v *batchValidation) merge(serviceErrs []error) []error {
+	for j, err := range serviceErrs {
+		if err != nil {
+			v.errs[v.valid[j]] = err
+		}
+	}
+	return v.errs
+}
+
+// scatter places the service results, one per valid item, at their request
+// index.
+func scatter[T any](v *batchValidation, results []T) []T {
+	scattered := make([]T, len(v.errs))
+	for j, result := range results {
+		scattered[v.valid[j]] = result
+	}
+	return scattered
+}
+
+// fail converts the error of an all-or-nothing batch into a gRPC status
+// naming the request index of the failed item.
+func (v *batchValidation) fail(ctx context.Context, err error, message string) error {
+	var itemErr *models.BatchItemError
+	if !errors.As(err, &itemErr) {
+		return catalogError(ctx, err, message)
+	}
+
+	code, itemMessage := itemStatus(ctx, itemErr.Err)
+	return status.Error(code, fmt.Sprintf("item %d: %s", v.valid[itemErr.Index], itemMessage))
+}
+
+// itemStatus is the code and message reported for one batch item.
+func itemStatus(ctx context.Context, err error) (codes.Code, string) {
+	if err == nil {
+		return codes.OK, ""
+	}
+	if errors.Is(err, models.ErrNotFound) {
+		return codes.NotFound, err.Error()
+	}
+	if errors.Is(err, models.ErrFeatureRequired) {
+		return codes.FailedPrecondition, err.Error()
+	}
+	if errors.Is(err, models.ErrAlreadyExists) {
+		st := alreadyExistsStatus(err)
+		return st.Code(), st.Message()
+	}
+	if st, ok := status.FromError(err); ok {
+		return st.Code(), st.Message()
+	}
+	logger.FromContext(ctx).Error("Batch item failed", "error", err)
+	return codes.Internal, "internal error"
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "smart-hub/gen/proto/capability/v1"
+	"smart-hub/internal/application/interfaces"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+)
+
+type CapabilityHandler struct {
+	pb.UnimplementedCapabilityServiceServer
+	service interfaces.CapabilityService
+	mapper  mapper.CapabilityMapper
+}
+
+func NewCapabilityHandler(
+	service interfaces.CapabilityService,
+	mapper mapper.CapabilityMapper,
+) *CapabilityHandler {
+	return &CapabilityHandler{
+		service: service,
+		mapper:  mapper,
+	}
+}
+
+func (h *CapabilityHandler) ListCapabilities(ctx context.Context, req *pb.ListCapabilitiesRequest) (*pb.ListCapabilitiesResponse, error) {
+	logger.FromContext(ctx).Debug("Listing capabilities")
+
+	return &pb.ListCapabilitiesResponse{Capabilities: h.mapper.ToProtoList(h.service.ListCapabilities(ctx))}, nil
+}
+
+func (h *CapabilityHandler) GetCapability(ctx context.Context, req *pb.GetCapabilityRequest) (*pb.GetCapabilityResponse, error) {
+	logger.FromContext(ctx).Debug("Getting capability", "request", req)
+
+	capability, err := h.service.GetCapability(ctx, req.Name)
+	if err != nil {
+		return nil, capabilityError(ctx, err, "failed to get capability")
+	}
+
+	return &pb.GetCapabilityResponse{Capability: h.mapper.ToProto(capability)}, nil
+}
+
+func (h *CapabilityHandler) SetFeatureCapability(ctx context.Context, req *pb.SetFeatureCapabilityRequest) (*pb.SetFeatureCapabilityResponse, error) {
+	logger.FromContext(ctx).Debug("Setting feature capability", "request", req)
+
+	capability, err := h.mapper.ToFeatureCapabilityDomain(req.Capability)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid capability: "+err.Error())
+	}
+
+	set, err := h.service.SetFeatureCapability(ctx, capability)
+	if err != nil {
+		return nil, capabilityError(ctx, err, "failed to set feature capability")
+	}
+
+	return &pb.SetFeatureCapabilityResponse{Capability: h.mapper.ToFeatureCapabilityProto(set)}, nil
+}
+
+func (h *CapabilityHandler) GetFeatureCapability(ctx context.Context, req *pb.GetFeatureCapabilityRequest) (*pb.GetFeatureCapabilityResponse, error) {
+	logger.FromContext(ctx).Debug("Getting feature capability", "request", req)
+
+	featureID, err := uuid.Parse(req.FeatureId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "feature_id: "+err.Error())
+	}
+
+	capability, err := h.service.GetFeatureCapability(ctx, featureID)
+	if err != nil {
+		return nil, capabilityError(ctx, err, "failed to get feature capability")
+	}
+
+	return &pb.GetFeatureCapabilityResponse{Capability: h.mapper.ToFeatureCapabilityProto(capability)}, nil
+}
+
+func (h *CapabilityHandler) ClearFeatureCapability(ctx context.Context, req *pb.ClearFeatureCapabilityRequest) (*pb.ClearFeatureCapabilityResponse, error) {
+	logger.FromContext(ctx).Debug("Clearing feature capability", "request", req)
+
+	featureID, err := uuid.Parse(req.FeatureId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "feature_id: "+err.Error())
+	}
+
+	if err := h.service.ClearFeatureCapability(ctx, featureID); err != nil {
+		return nil, capabilityError(ctx, err, "failed to clear feature capability")
+	}
+
+	return &pb.ClearFeatureCapabilityResponse{}, nil
+}
+
+func (h *CapabilityHandler) ListModelsByCapability(ctx context.Context, req *pb.ListModelsByCapabilityRequest) (*pb.ListModelsByCapabilityResponse, error) {
+	logger.FromContext(ctx).Debug("Listing models by capability", "request", req)
+
+	capable, err := h.service.ModelsByCapability(ctx, req.Capability)
+	if err != nil {
+		return nil, capabilityError(ctx, err, "failed to list models by capability")
+	}
+
+	return &pb.ListModelsByCapabilityResponse{Models: h.mapper.ToCapableModelsProto(capable)}, nil
+}
+
+func capabilityError(ctx context.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidCapability):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, "not found")
+	}
+	logger.FromContext(ctx).Error(message, "error", err)
+	return status.Error(codes.Internal, message)
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "smart-hub/gen/proto/capability/v1"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+	"testing"
+)
+
+type mockCapabilityService struct {
+	mock.Mock
+}
+
+func (m *mockCapabilityService) ListCapabilities(ctx context.Context) []models.Capability {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Capability)
+}
+
+func (m *mockCapabilityService) GetCapability(ctx context.Context, name string) (models.Capability, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(models.Capability), args.Error(1)
+}
+
+func (m *mockCapabilityService) SetFeatureCapability(ctx context.Context, capability *models.FeatureCapability) (*models.FeatureCapability, error) {
+	args := m.Called(ctx, capability)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FeatureCapability), args.Error(1)
+}
+
+func (m *mockCapabilityService) GetFeatureCapability(ctx context.Context, featureID uuid.UUID) (*models.FeatureCapability, error) {
+	args := m.Called(ctx, featureID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FeatureCapability), args.Error(1)
+}
+
+func (m *mockCapabilityService) ClearFeatureCapability(ctx context.Context, featureID uuid.UUID) error {
+	args := m.Called(ctx, featureID)
+	return args.Error(0)
+}
+
+func (m *mockCapabilityService) ModelsByCapability(ctx context.Context, capability string) ([]*models.CapableModel, error) {
+	args := m.Called(ctx, capability)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CapableModel), args.Error(1)
+}
+
+func TestCapabilityHandler_ListCapabilities(t *testing.T) {
+	service := new(mockCapabilityService)
+	handler := NewCapabilityHandler(service, mapper.NewCapabilityMapper())
+	service.On("ListCapabilities", mock.Anything).Return(models.StandardCapabilities())
+
+	resp, err := handler.ListCapabilities(context.Background(), &pb.ListCapabilitiesRequest{})
+
+	require.NoError(t, err)
+	var brightness *pb.Capability
+	for _, capability := range resp.Capabilities {
+		if capability.Name == "brightness" {
+			brightness = capability
+		}
+	}
+	require.NotNil(t, brightness)
+	level := brightness.Parameters[0]
+	assert.Equal(t, "level", level.Name)
+	assert.Equal(t, pb.ParameterType_INTEGER, level.Type)
+	assert.True(t, level.Required)
+	assert.Equal(t, 100.0, level.GetMaximum())
+}
+
+func TestCapabilityHandler_GetCapability_NotFound(t *testing.T) {
+	service := new(mockCapabilityService)
+	handler := NewCapabilityHandler(service, mapper.NewCapabilityMapper())
+	service.On("GetCapability", mock.Anything, "teleport").
+		Return(models.Capability{}, fmt.Errorf("%w: capability %q", models.ErrNotFound, "teleport"))
+
+	_, err := handler.GetCapability(context.Background(), &pb.GetCapabilityRequest{Name: "teleport"})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestCapabilityHandler_SetFeatureCapability(t *testing.T) {
+	service := new(mockCapabilityService)
+	handler := NewCapabilityHandler(service, mapper.NewCapabilityMapper())
+
+	featureID, modelID := uuid.New(), uuid.New()
+	service.On("SetFeatureCapability", mock.Anything, &models.FeatureCapability{
+		FeatureID:        featureID,
+		Capability:       "switch",
+		ParameterMapping: map[string]string{"on": "power"},
+	}).Return(&models.FeatureCapability{
+		FeatureID:        featureID,
+		ModelID:          modelID,
+		Capability:       "switch",
+		ParameterMapping: map[string]string{"on": "power"},
+	}, nil)
+
+	resp, err := handler.SetFeatureCapability(context.Background(), &pb.SetFeatureCapabilityRequest{
+		Capability: &pb.FeatureCapability{
+			FeatureId:        featureID.String(),
+			Capability:       "switch",
+			ParameterMapping: map[string]string{"on": "power"},
+			ModelId:          uuid.NewString(),
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, modelID.String(), resp.Capability.ModelId)
+	assert.Equal(t, "power", resp.Capability.ParameterMapping["on"])
+	service.AssertExpectations(t)
+}
+
+func TestCapabilityHandler_SetFeatureCapability_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		capability *pb.FeatureCapability
+		serviceErr error
+		code       codes.Code
+	}{
+		{"missing capability", nil, nil, codes.InvalidArgument},
+		{"malformed feature", &pb.FeatureCapability{FeatureId: "dimmer", Capability: "switch"}, nil, codes.InvalidArgument},
+		{"invalid mapping", &pb.FeatureCapability{FeatureId: uuid.NewString(), Capability: "switch"},
+			fmt.Errorf("%w: required parameter \"on\" of switch is not mapped", models.ErrInvalidCapability), codes.InvalidArgument},
+		{"unknown feature", &pb.FeatureCapability{FeatureId: uuid.NewString(), Capability: "switch"}, models.ErrNotFound, codes.NotFound},
+		{"internal", &pb.FeatureCapability{FeatureId: uuid.NewString(), Capability: "switch"}, fmt.Errorf("connection refused"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(mockCapabilityService)
+			handler := NewCapabilityHandler(service, mapper.NewCapabilityMapper())
+			service.On("SetFeatureCapability", mock.Anything, mock.Anything).Return(nil, tt.serviceErr).Maybe()
+
+			_, err := handler.SetFeatureCapability(context.Background(), &pb.SetFeatureCapabilityRequest{Capability: tt.capability})
+
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestCapabilityHandler_ClearFeatureCapability(t *testing.T) {
+	service := new(mockCapabilityService)
+	handler := NewCapabilityHandler(service, mapper.NewCapabilityMapper())
+	featureID := uuid.New()
+	service.On("ClearFeatureCapability", mock.Anything, featureID).Return(models.ErrNotFound)
+
+	_, err := handler.ClearFeatureCapability(context.Background(), &pb.ClearFeatureCapabilityRequest{FeatureId: featureID.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = handler.ClearFeatureCapability(context.Background(), &pb.ClearFeatureCapabilityRequest{FeatureId: "dimmer"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCapabilityHandler_ListModelsByCapability(t *testing.T) {
+	service := new(mockCapabilityService)
+	handler := NewCapabilityHandler(service, mapper.NewCapabilityMapper())
+
+	model := &models.SmartModel{ID: uuid.New(), Name: "Power strip", Manufacturer: "Acme", ModelNumber: "PS-4"}
+	relay := uuid.New()
+	service.On("ModelsByCapability", mock.Anything, "switch").Return([]*models.CapableModel{{
+		Model: model,
+		Features: []*models.FeatureCapability{
+			{FeatureID: relay, ModelID: model.ID, Capability: "switch", ParameterMapping: map[string]string{"on": "relay_1"}},
+		},
+	}}, nil)
+
+	resp, err := handler.ListModelsByCapability(context.Background(), &pb.ListModelsByCapabilityRequest{Capability: "switch"})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Models, 1)
+	assert.Equal(t, "Power strip", resp.Models[0].Name)
+	assert.Equal(t, "PS-4", resp.Models[0].ModelNumber)
+	require.Len(t, resp.Models[0].Features, 1)
+	assert.Equal(t, relay.String(), resp.Models[0].Features[0].FeatureId)
+	assert.Equal(t, "relay_1", resp.Models[0].Features[0].ParameterMapping["on"])
+}
+
+func TestCapabilityHandler_ListModelsByCapability_Unknown(t *testing.T) {
+	service := new(mockCapabilityService)
+	handler := NewCapabilityHandler(service, mapper.NewCapabilityMapper())
+	service.On("ModelsByCapability", mock.Anything, "teleport").
+		Return(nil, fmt.Errorf("%w: unknown capability %q", models.ErrInvalidCapability, "teleport"))
+
+	_, err := handler.ListModelsByCapability(context.Background(), &pb.ListModelsByCapabilityRequest{Capability: "teleport"})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	pb "smart-hub/gen/proto/catalog/v1"
+	"smart-hub/internal/application/interfaces"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+)
+
+type CatalogHandler struct {
+	pb.UnimplementedCatalogServiceServer
+	service interfaces.CatalogService
+	mapper  mapper.CatalogMapper
+}
+
+func NewCatalogHandler(
+	service interfaces.CatalogService,
+	mapper mapper.CatalogMapper,
+) *CatalogHandler {
+	return &CatalogHandler{
+		service: service,
+		mapper:  mapper,
+	}
+}
+
+// ImportCatalog collects the whole stream before importing, so the import
+// can run in a single transaction when no batch size is given.
+func (h *CatalogHandler) ImportCatalog(stream pb.CatalogService_ImportCatalogServer) error {
+	ctx := stream.Context()
+	logger.FromContext(ctx).Debug("Importing catalog")
+
+	var opts models.ImportOptions
+	var entries []*models.CatalogEntry
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch item := req.Item.(type) {
+		case *pb.ImportCatalogRequest_Options:
+			if len(entries) > 0 {
+				return status.Error(codes.InvalidArgument, "import options must be sent before the first model")
+			}
+			opts = h.mapper.ToDomainOptions(item.Options)
+		case *pb.ImportCatalogRequest_Model:
+			entry, err := h.mapper.ToDomain(item.Model)
+			if err != nil {
+				return status.Error(codes.InvalidArgument, "invalid request: model is required")
+			}
+			entries = append(entries, entry)
+		default:
+			return status.Error(codes.InvalidArgument, "invalid request: options or model is required")
+		}
+	}
+
+	report, err := h.service.Import(ctx, entries, opts)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to import catalog", "error", err)
+		return status.Error(codes.Internal, "failed to import catalog")
+	}
+
+	return stream.SendAndClose(h.mapper.ToImportResponse(report))
+}
+
+func (h *CatalogHandler) ExportCatalog(req *pb.ExportCatalogRequest, stream pb.CatalogService_ExportCatalogServer) error {
+	ctx := stream.Context()
+	logger.FromContext(ctx).Debug("Exporting catalog", "request", req)
+
+	err := h.service.Export(ctx, h.mapper.ToExportFilter(req), func(entry *models.CatalogEntry) error {
+		protoModel, err := h.mapper.ToProto(entry)
+		if err != nil {
+			return err
+		}
+		return stream.Send(&pb.ExportCatalogResponse{Model: protoModel})
+	})
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	logger.FromContext(ctx).Error("Failed to export catalog", "error", err)
+	return status.Error(codes.Internal, "failed to export catalog")
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	pb "smart-hub/gen/proto/catalog/v1"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+	"testing"
+)
+
+type mockCatalogService struct {
+	mock.Mock
+}
+
+func (m *mockCatalogService) Import(ctx context.Context, entries []*models.CatalogEntry, opts models.ImportOptions) (*models.ImportReport, error) {
+	args := m.Called(ctx, entries, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportReport), args.Error(1)
+}
+
+func (m *mockCatalogService) Export(ctx context.Context, filter models.ExportFilter, send func(*models.CatalogEntry) error) error {
+	args := m.Called(ctx, filter, send)
+	if entries, ok := args.Get(0).([]*models.CatalogEntry); ok {
+		for _, entry := range entries {
+			if err := send(entry); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+type fakeImportCatalogServer struct {
+	grpc.ServerStream
+	requests []*pb.ImportCatalogRequest
+	response *pb.ImportCatalogResponse
+}
+
+func (s *fakeImportCatalogServer) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeImportCatalogServer) Recv() (*pb.ImportCatalogRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *fakeImportCatalogServer) SendAndClose(resp *pb.ImportCatalogResponse) error {
+	s.response = resp
+	return nil
+}
+
+type fakeExportCatalogServer struct {
+	grpc.ServerStream
+	responses []*pb.ExportCatalogResponse
+}
+
+func (s *fakeExportCatalogServer) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeExportCatalogServer) Send(resp *pb.ExportCatalogResponse) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+func TestImportCatalog_Success(t *testing.T) {
+	mockService := new(mockCatalogService)
+	handler := NewCatalogHandler(mockService, mapper.NewCatalogMapper())
+
+	modelID := uuid.New()
+	opts := models.ImportOptions{DryRun: true, BatchSize: 10}
+	matchEntries := mock.MatchedBy(func(entries []*models.CatalogEntry) bool {
+		return len(entries) == 1 &&
+			entries[0].Model.ModelNumber == "AC100" &&
+			entries[0].Model.Type == models.ServiceType &&
+			len(entries[0].Features) == 1 &&
+			entries[0].Features[0].Protocol == models.MqttProtocol
+	})
+	mockService.On("Import", mock.Anything, matchEntries, opts).Return(&models.ImportReport{
+		DryRun:  true,
+		Created: 1,
+		Rows:    []models.ImportRowResult{{Row: 1, Status: models.ImportCreated, ModelID: modelID}},
+	}, nil)
+
+	stream := &fakeImportCatalogServer{requests: []*pb.ImportCatalogRequest{
+		{Item: &pb.ImportCatalogRequest_Options{Options: &pb.ImportOptions{DryRun: true, BatchSize: 10}}},
+		{Item: &pb.ImportCatalogRequest_Model{Model: &pb.CatalogModel{
+			Name:         "Test Model",
+			Type:         pb.ModelType_SERVICE,
+			Manufacturer: "Acme",
+			ModelNumber:  "AC100",
+			Features: []*pb.CatalogFeature{
+				{Name: "status", Protocol: pb.ProtocolType_MQTT, InterfacePath: "/status"},
+			},
+		}}},
+	}}
+
+	err := handler.ImportCatalog(stream)
+
+	require.NoError(t, err)
+	require.NotNil(t, stream.response)
+	assert.True(t, stream.response.DryRun)
+	assert.Equal(t, int32(1), stream.response.Created)
+	require.Len(t, stream.response.Rows, 1)
+	assert.Equal(t, pb.ImportRowStatus_CREATED, stream.response.Rows[0].Status)
+	assert.Equal(t, modelID.String(), stream.response.Rows[0].ModelId)
+	mockService.AssertExpectations(t)
+}
+
+func TestImportCatalog_OptionsAfterModel(t *testing.T) {
+	handler := NewCatalogHandler(new(mockCatalogService), mapper.NewCatalogMapper())
+
+	stream := &fakeImportCatalogServer{requests: []*pb.ImportCatalogRequest{
+		{Item: &pb.ImportCatalogRequest_Model{Model: &pb.CatalogModel{Name: "Test Model"}}},
+		{Item: &pb.ImportCatalogRequest_Options{Options: &pb.ImportOptions{DryRun: true}}},
+	}}
+
+	err := handler.ImportCatalog(stream)
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Nil(t, stream.response)
+}
+
+func TestImportCatalog_ServiceError(t *testing.T) {
+	mockService := new(mockCatalogService)
+	handler := NewCatalogHandler(mockService, mapper.NewCatalogMapper())
+
+	mockService.On("Import", mock.Anything, mock.Anything, models.ImportOptions{}).Return(nil, assert.AnError)
+
+	err := handler.ImportCatalog(&fakeImportCatalogServer{})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	mockService.AssertExpectations(t)
+}
+
+func TestExportCatalog_Success(t *testing.T) {
+	mockService := new(mockCatalogService)
+	handler := NewCatalogHandler(mockService, mapper.NewCatalogMapper())
+
+	manufacturer := "Acme"
+	modelType := models.ServiceType
+	entry := &models.CatalogEntry{
+		Model: &models.SmartModel{ID: uuid.New(), Name: "Test Model", Type: models.ServiceType, Category: models.CameraCategory, Manufacturer: "Acme"},
+		Features: []*models.SmartFeature{
+			{Name: "status", Protocol: models.WebsocketProtocol, InterfacePath: "/status", Parameters: map[string]interface{}{"interval": 5.0}},
+		},
+	}
+	mockService.On("Export", mock.Anything, models.ExportFilter{Manufacturer: &manufacturer, Type: &modelType}, mock.Anything).
+		Return([]*models.CatalogEntry{entry}, nil)
+
+	manufacturerReq := "Acme"
+	typeReq := pb.ModelType_SERVICE
+	stream := &fakeExportCatalogServer{}
+	err := handler.ExportCatalog(&pb.ExportCatalogRequest{Manufacturer: &manufacturerReq, Type: &typeReq}, stream)
+
+	require.NoError(t, err)
+	require.Len(t, stream.responses, 1)
+	model := stream.responses[0].Model
+	assert.Equal(t, entry.Model.ID.String(), model.Id)
+	assert.Equal(t, pb.ModelType_SERVICE, model.Type)
+	assert.Equal(t, pb.ModelCategory_CAMERA, model.Category)
+	require.Len(t, model.Features, 1)
+	assert.Equal(t, pb.ProtocolType_WEBSOCKET, model.Features[0].Protocol)
+	assert.Equal(t, 5.0, model.Features[0].Parameters.AsMap()["interval"])
+	mockService.AssertExpectations(t)
+}
+
+func TestExportCatalog_ServiceError(t *testing.T) {
+	mockService := new(mockCatalogService)
+	handler := NewCatalogHandler(mockService, mapper.NewCatalogMapper())
+
+	mockService.On("Export", mock.Anything, models.ExportFilter{}, mock.Anything).Return(nil, assert.AnError)
+
+	err := handler.ExportCatalog(&pb.ExportCatalogRequest{}, &fakeExportCatalogServer{})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	mockService.AssertExpectations(t)
+}
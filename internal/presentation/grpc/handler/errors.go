@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+)
+
+// catalogError converts an error of the smart model and feature services
+// into a gRPC status. Missing entities are NotFound, unique key conflicts
+// AlreadyExists and features still required by others FailedPrecondition;
+// anything else is logged and reported as Internal with message.
+func catalogError(ctx context.Context, err error, message string) error {
+	if errors.Is(err, models.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, models.ErrFeatureRequired) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if errors.Is(err, models.ErrAlreadyExists) {
+		return alreadyExistsStatus(err).Err()
+	}
+	logger.FromContext(ctx).Error(message, "error", err)
+	return status.Error(codes.Internal, message)
+}
+
+// alreadyExistsStatus names the conflicting entity in the message and, when
+// it is known, in a ResourceInfo detail so clients need not parse it.
+func alreadyExistsStatus(err error) *status.Status {
+	var conflict *models.AlreadyExistsError
+	if !errors.As(err, &conflict) {
+		return status.New(codes.AlreadyExists, models.ErrAlreadyExists.Error())
+	}
+
+	st := status.New(codes.AlreadyExists, conflict.Error())
+	if conflict.ID == uuid.Nil {
+		return st
+	}
+	detailed, detailErr := st.WithDetails(&errdetails.ResourceInfo{
+		ResourceType: string(conflict.Entity),
+		ResourceName: conflict.ID.String(),
+		Description:  "conflicts on a unique key",
+	})
+	if detailErr != nil {
+		return st
+	}
+	return detailed
+}
@@ -3,26 +3,30 @@ package handler
 import (
 	"context"
 	pb "smart-hub/gen/proto/health/v1"
-	"smart-hub/internal/common/database"
 	"smart-hub/internal/common/logger"
 )
 
+// Pinger reports whether the storage backend is reachable.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
 type HealthHandler struct {
-	db database.Database
+	db Pinger
 }
 
-func NewHealthHandler(db database.Database) *HealthHandler {
+func NewHealthHandler(db Pinger) *HealthHandler {
 	return &HealthHandler{
 		db: db,
 	}
 }
 
 func (h *HealthHandler) Check(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
-	logger.Debug("Health check requested", "service", req.Service)
+	logger.FromContext(ctx).Debug("Health check requested", "service", req.Service)
 
 	err := h.db.Ping(ctx)
 	if err != nil {
-		logger.Error("Database health check failed", "error", err)
+		logger.FromContext(ctx).Error("Database health check failed", "error", err)
 		return &pb.HealthCheckResponse{
 			Status: pb.HealthCheckResponse_SERVING_STATUS_NOT_SERVING,
 		}, nil
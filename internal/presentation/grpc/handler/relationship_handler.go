@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "smart-hub/gen/proto/relationship/v1"
+	"smart-hub/internal/application/interfaces"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+)
+
+type RelationshipHandler struct {
+	pb.UnimplementedRelationshipServiceServer
+	service interfaces.RelationshipService
+	mapper  mapper.RelationshipMapper
+}
+
+func NewRelationshipHandler(
+	service interfaces.RelationshipService,
+	mapper mapper.RelationshipMapper,
+) *RelationshipHandler {
+	return &RelationshipHandler{
+		service: service,
+		mapper:  mapper,
+	}
+}
+
+func (h *RelationshipHandler) CreateRelationship(ctx context.Context, req *pb.CreateRelationshipRequest) (*pb.CreateRelationshipResponse, error) {
+	logger.FromContext(ctx).Debug("Creating relationship", "request", req)
+
+	relationship, err := h.mapper.ToDomain(req.Relationship)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid relationship: "+err.Error())
+	}
+	relationship.ID = uuid.Nil
+
+	created, err := h.service.CreateRelationship(ctx, relationship)
+	if err != nil {
+		return nil, relationshipError(ctx, err, "failed to create relationship")
+	}
+
+	return &pb.CreateRelationshipResponse{Relationship: h.mapper.ToProto(created)}, nil
+}
+
+func (h *RelationshipHandler) GetRelationship(ctx context.Context, req *pb.GetRelationshipRequest) (*pb.GetRelationshipResponse, error) {
+	logger.FromContext(ctx).Debug("Getting relationship", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	relationship, err := h.service.GetRelationship(ctx, id)
+	if err != nil {
+		return nil, relationshipError(ctx, err, "failed to get relationship")
+	}
+
+	return &pb.GetRelationshipResponse{Relationship: h.mapper.ToProto(relationship)}, nil
+}
+
+func (h *RelationshipHandler) DeleteRelationship(ctx context.Context, req *pb.DeleteRelationshipRequest) (*pb.DeleteRelationshipResponse, error) {
+	logger.FromContext(ctx).Debug("Deleting relationship", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := h.service.DeleteRelationship(ctx, id); err != nil {
+		return nil, relationshipError(ctx, err, "failed to delete relationship")
+	}
+
+	return &pb.DeleteRelationshipResponse{}, nil
+}
+
+func (h *RelationshipHandler) ListRelationships(ctx context.Context, req *pb.ListRelationshipsRequest) (*pb.ListRelationshipsResponse, error) {
+	logger.FromContext(ctx).Debug("Listing relationships", "request", req)
+
+	modelID, err := uuid.Parse(req.ModelId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "model_id: "+err.Error())
+	}
+	filter := models.RelationshipFilter{ModelID: modelID}
+	if req.Type != nil {
+		filter.Type = h.mapper.ToDomainType(req.GetType())
+	}
+
+	relationships, err := h.service.ListRelationships(ctx, filter)
+	if err != nil {
+		return nil, relationshipError(ctx, err, "failed to list relationships")
+	}
+
+	return &pb.ListRelationshipsResponse{Relationships: h.mapper.ToProtoList(relationships)}, nil
+}
+
+func (h *RelationshipHandler) ListRelatedModels(ctx context.Context, req *pb.ListRelatedModelsRequest) (*pb.ListRelatedModelsResponse, error) {
+	logger.FromContext(ctx).Debug("Listing related models", "request", req)
+
+	modelID, err := uuid.Parse(req.ModelId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "model_id: "+err.Error())
+	}
+
+	related, err := h.service.RelatedModels(ctx, models.RelationshipQuery{
+		ModelID:   modelID,
+		Type:      h.mapper.ToDomainType(req.Type),
+		Direction: h.mapper.ToDomainDirection(req.Direction),
+		MaxDepth:  int(req.MaxDepth),
+	})
+	if err != nil {
+		return nil, relationshipError(ctx, err, "failed to list related models")
+	}
+
+	return &pb.ListRelatedModelsResponse{Models: h.mapper.ToRelatedModelsProto(related)}, nil
+}
+
+func relationshipError(ctx context.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidRelationship):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, "not found")
+	case errors.Is(err, models.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, "relationship already exists")
+	}
+	logger.FromContext(ctx).Error(message, "error", err)
+	return status.Error(codes.Internal, message)
+}
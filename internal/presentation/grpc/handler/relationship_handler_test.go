@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "smart-hub/gen/proto/relationship/v1"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+	"testing"
+	"time"
+)
+
+type mockRelationshipService struct {
+	mock.Mock
+}
+
+func (m *mockRelationshipService) CreateRelationship(ctx context.Context, relationship *models.ModelRelationship) (*models.ModelRelationship, error) {
+	args := m.Called(ctx, relationship)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ModelRelationship), args.Error(1)
+}
+
+func (m *mockRelationshipService) GetRelationship(ctx context.Context, id uuid.UUID) (*models.ModelRelationship, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ModelRelationship), args.Error(1)
+}
+
+func (m *mockRelationshipService) DeleteRelationship(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockRelationshipService) ListRelationships(ctx context.Context, filter models.RelationshipFilter) ([]*models.ModelRelationship, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ModelRelationship), args.Error(1)
+}
+
+func (m *mockRelationshipService) RelatedModels(ctx context.Context, query models.RelationshipQuery) ([]*models.RelatedModel, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RelatedModel), args.Error(1)
+}
+
+func TestCreateRelationship_Success(t *testing.T) {
+	mockService := new(mockRelationshipService)
+	handler := NewRelationshipHandler(mockService, mapper.NewRelationshipMapper())
+
+	camera, hub := uuid.New(), uuid.New()
+	created := &models.ModelRelationship{
+		ID:            uuid.New(),
+		SourceModelID: camera,
+		TargetModelID: hub,
+		Type:          models.RelationshipRequires,
+		CreatedAt:     time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	mockService.On("CreateRelationship", mock.Anything, &models.ModelRelationship{
+		SourceModelID: camera,
+		TargetModelID: hub,
+		Type:          models.RelationshipRequires,
+	}).Return(created, nil)
+
+	resp, err := handler.CreateRelationship(context.Background(), &pb.CreateRelationshipRequest{Relationship: &pb.Relationship{
+		Id:            uuid.New().String(),
+		SourceModelId: camera.String(),
+		TargetModelId: hub.String(),
+		Type:          pb.RelationshipType_REQUIRES,
+	}})
+
+	require.NoError(t, err)
+	assert.Equal(t, created.ID.String(), resp.Relationship.Id)
+	assert.Equal(t, pb.RelationshipType_REQUIRES, resp.Relationship.Type)
+	assert.Equal(t, created.CreatedAt, resp.Relationship.CreatedAt.AsTime())
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateRelationship_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"cycle", fmt.Errorf("%w: would create a cycle", models.ErrInvalidRelationship), codes.InvalidArgument},
+		{"duplicate", fmt.Errorf("%w: relationship", models.ErrAlreadyExists), codes.AlreadyExists},
+		{"internal", errors.New("connection reset"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockRelationshipService)
+			handler := NewRelationshipHandler(mockService, mapper.NewRelationshipMapper())
+			mockService.On("CreateRelationship", mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			resp, err := handler.CreateRelationship(context.Background(), &pb.CreateRelationshipRequest{Relationship: &pb.Relationship{
+				SourceModelId: uuid.New().String(),
+				TargetModelId: uuid.New().String(),
+				Type:          pb.RelationshipType_REPLACES,
+			}})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+
+	handler := NewRelationshipHandler(new(mockRelationshipService), mapper.NewRelationshipMapper())
+	_, err := handler.CreateRelationship(context.Background(), &pb.CreateRelationshipRequest{Relationship: &pb.Relationship{SourceModelId: "camera"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = handler.CreateRelationship(context.Background(), &pb.CreateRelationshipRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestListRelationships_TypeFilter(t *testing.T) {
+	mockService := new(mockRelationshipService)
+	handler := NewRelationshipHandler(mockService, mapper.NewRelationshipMapper())
+
+	modelID := uuid.New()
+	mockService.On("ListRelationships", mock.Anything, models.RelationshipFilter{ModelID: modelID}).
+		Return([]*models.ModelRelationship{
+			{ID: uuid.New(), SourceModelID: modelID, TargetModelID: uuid.New(), Type: models.RelationshipRequires},
+			{ID: uuid.New(), SourceModelID: uuid.New(), TargetModelID: modelID, Type: models.RelationshipCompatibleWith},
+		}, nil)
+	mockService.On("ListRelationships", mock.Anything, models.RelationshipFilter{ModelID: modelID, Type: models.RelationshipCompatibleWith}).
+		Return([]*models.ModelRelationship{}, nil)
+
+	resp, err := handler.ListRelationships(context.Background(), &pb.ListRelationshipsRequest{ModelId: modelID.String()})
+	require.NoError(t, err)
+	require.Len(t, resp.Relationships, 2)
+	assert.Equal(t, pb.RelationshipType_COMPATIBLE_WITH, resp.Relationships[1].Type)
+
+	compatible := pb.RelationshipType_COMPATIBLE_WITH
+	resp, err = handler.ListRelationships(context.Background(), &pb.ListRelationshipsRequest{ModelId: modelID.String(), Type: &compatible})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Relationships)
+	mockService.AssertExpectations(t)
+}
+
+func TestListRelatedModels_Success(t *testing.T) {
+	mockService := new(mockRelationshipService)
+	handler := NewRelationshipHandler(mockService, mapper.NewRelationshipMapper())
+
+	hubID, bridge := uuid.New(), uuid.New()
+	mockService.On("RelatedModels", mock.Anything, models.RelationshipQuery{
+		ModelID:   hubID,
+		Type:      models.RelationshipRequires,
+		Direction: models.DirectionIncoming,
+		MaxDepth:  3,
+	}).Return([]*models.RelatedModel{
+		{ModelID: bridge, Depth: 1, Model: &models.SmartModel{ID: bridge, Name: "Bridge", Manufacturer: "Acme", ModelNumber: "BR-1"}},
+		{ModelID: uuid.New(), Depth: 2},
+	}, nil)
+
+	resp, err := handler.ListRelatedModels(context.Background(), &pb.ListRelatedModelsRequest{
+		ModelId:   hubID.String(),
+		Type:      pb.RelationshipType_REQUIRES,
+		Direction: pb.Direction_INCOMING,
+		MaxDepth:  3,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Models, 1, "models deleted in the meantime are left out")
+	assert.Equal(t, bridge.String(), resp.Models[0].ModelId)
+	assert.Equal(t, "Bridge", resp.Models[0].Name)
+	assert.Equal(t, "BR-1", resp.Models[0].ModelNumber)
+	assert.Equal(t, int32(1), resp.Models[0].Depth)
+	mockService.AssertExpectations(t)
+}
+
+func TestListRelatedModels_Errors(t *testing.T) {
+	mockService := new(mockRelationshipService)
+	handler := NewRelationshipHandler(mockService, mapper.NewRelationshipMapper())
+	modelID := uuid.New()
+	mockService.On("RelatedModels", mock.Anything, mock.Anything).Return(nil, models.ErrNotFound)
+
+	_, err := handler.ListRelatedModels(context.Background(), &pb.ListRelatedModelsRequest{ModelId: modelID.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = handler.ListRelatedModels(context.Background(), &pb.ListRelatedModelsRequest{ModelId: "hub"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestDeleteRelationship_NotFound(t *testing.T) {
+	mockService := new(mockRelationshipService)
+	handler := NewRelationshipHandler(mockService, mapper.NewRelationshipMapper())
+	id := uuid.New()
+	mockService.On("DeleteRelationship", mock.Anything, id).Return(models.ErrNotFound)
+
+	resp, err := handler.DeleteRelationship(context.Background(), &pb.DeleteRelationshipRequest{Id: id.String()})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
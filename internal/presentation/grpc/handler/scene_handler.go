@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "smart-hub/gen/proto/scene/v1"
+	"smart-hub/internal/application/interfaces"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+)
+
+type SceneHandler struct {
+	pb.UnimplementedSceneServiceServer
+	service interfaces.SceneService
+	mapper  mapper.SceneMapper
+}
+
+func NewSceneHandler(
+	service interfaces.SceneService,
+	mapper mapper.SceneMapper,
+) *SceneHandler {
+	return &SceneHandler{
+		service: service,
+		mapper:  mapper,
+	}
+}
+
+func (h *SceneHandler) CreateScene(ctx context.Context, req *pb.CreateSceneRequest) (*pb.CreateSceneResponse, error) {
+	logger.FromContext(ctx).Debug("Creating scene", "name", req.GetScene().GetName())
+
+	scene, err := h.mapper.ToDomain(req.Scene)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid scene: "+err.Error())
+	}
+	scene.ID = uuid.Nil
+
+	created, err := h.service.CreateScene(ctx, scene)
+	if err != nil {
+		return nil, sceneError(ctx, err, "failed to create scene")
+	}
+
+	protoScene, err := h.toProto(ctx, created)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateSceneResponse{Scene: protoScene}, nil
+}
+
+func (h *SceneHandler) GetScene(ctx context.Context, req *pb.GetSceneRequest) (*pb.GetSceneResponse, error) {
+	logger.FromContext(ctx).Debug("Getting scene", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	scene, err := h.service.GetScene(ctx, id)
+	if err != nil {
+		return nil, sceneError(ctx, err, "failed to get scene")
+	}
+
+	protoScene, err := h.toProto(ctx, scene)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetSceneResponse{Scene: protoScene}, nil
+}
+
+func (h *SceneHandler) ListScenes(ctx context.Context, req *pb.ListScenesRequest) (*pb.ListScenesResponse, error) {
+	logger.FromContext(ctx).Debug("Listing scenes")
+
+	scenes, err := h.service.ListScenes(ctx)
+	if err != nil {
+		return nil, sceneError(ctx, err, "failed to list scenes")
+	}
+
+	protoScenes, err := h.mapper.ToProtoList(scenes)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to convert scenes to proto", "error", err)
+		return nil, status.Error(codes.Internal, "failed to convert scenes to proto")
+	}
+	return &pb.ListScenesResponse{Scenes: protoScenes}, nil
+}
+
+func (h *SceneHandler) UpdateScene(ctx context.Context, req *pb.UpdateSceneRequest) (*pb.UpdateSceneResponse, error) {
+	logger.FromContext(ctx).Debug("Updating scene", "id", req.GetScene().GetId())
+
+	scene, err := h.mapper.ToDomain(req.Scene)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid scene: "+err.Error())
+	}
+	if scene.ID == uuid.Nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid scene: id is required")
+	}
+
+	updated, err := h.service.UpdateScene(ctx, scene)
+	if err != nil {
+		return nil, sceneError(ctx, err, "failed to update scene")
+	}
+
+	protoScene, err := h.toProto(ctx, updated)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateSceneResponse{Scene: protoScene}, nil
+}
+
+func (h *SceneHandler) DeleteScene(ctx context.Context, req *pb.DeleteSceneRequest) (*pb.DeleteSceneResponse, error) {
+	logger.FromContext(ctx).Debug("Deleting scene", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := h.service.DeleteScene(ctx, id); err != nil {
+		return nil, sceneError(ctx, err, "failed to delete scene")
+	}
+
+	return &pb.DeleteSceneResponse{}, nil
+}
+
+func (h *SceneHandler) ActivateScene(ctx context.Context, req *pb.ActivateSceneRequest) (*pb.ActivateSceneResponse, error) {
+	logger.FromContext(ctx).Debug("Activating scene", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	activation, err := h.service.ActivateScene(ctx, id)
+	if err != nil {
+		return nil, sceneError(ctx, err, "failed to activate scene")
+	}
+
+	return h.mapper.ToActivationProto(activation), nil
+}
+
+func (h *SceneHandler) toProto(ctx context.Context, scene *models.Scene) (*pb.Scene, error) {
+	protoScene, err := h.mapper.ToProto(scene)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to convert scene to proto", "error", err)
+		return nil, status.Error(codes.Internal, "failed to convert scene to proto")
+	}
+	return protoScene, nil
+}
+
+func sceneError(ctx context.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidScene):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, "scene not found")
+	}
+	logger.FromContext(ctx).Error(message, "error", err)
+	return status.Error(codes.Internal, message)
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	pb "smart-hub/gen/proto/scene/v1"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+	"testing"
+	"time"
+)
+
+type mockSceneService struct {
+	mock.Mock
+}
+
+func (m *mockSceneService) CreateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	args := m.Called(ctx, scene)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Scene), args.Error(1)
+}
+
+func (m *mockSceneService) GetScene(ctx context.Context, id uuid.UUID) (*models.Scene, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Scene), args.Error(1)
+}
+
+func (m *mockSceneService) ListScenes(ctx context.Context) ([]*models.Scene, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Scene), args.Error(1)
+}
+
+func (m *mockSceneService) UpdateScene(ctx context.Context, scene *models.Scene) (*models.Scene, error) {
+	args := m.Called(ctx, scene)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Scene), args.Error(1)
+}
+
+func (m *mockSceneService) DeleteScene(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockSceneService) ActivateScene(ctx context.Context, id uuid.UUID) (*models.SceneActivation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SceneActivation), args.Error(1)
+}
+
+func TestCreateScene_Success(t *testing.T) {
+	mockService := new(mockSceneService)
+	handler := NewSceneHandler(mockService, mapper.NewSceneMapper())
+
+	dim, play := uuid.New(), uuid.New()
+	created := &models.Scene{
+		ID:   uuid.New(),
+		Name: "Movie night",
+		Steps: []models.SceneStep{
+			{DeviceID: "light-1", FeatureID: dim, Parameters: map[string]interface{}{"brightness": 20.0}, Group: "lights"},
+			{DeviceID: "tv-1", FeatureID: play, Delay: 2 * time.Second},
+		},
+		StopOnError: true,
+	}
+	mockService.On("CreateScene", mock.Anything, mock.MatchedBy(func(scene *models.Scene) bool {
+		return scene.ID == uuid.Nil &&
+			scene.Name == "Movie night" &&
+			scene.StopOnError &&
+			len(scene.Steps) == 2 &&
+			scene.Steps[0].FeatureID == dim &&
+			scene.Steps[0].Group == "lights" &&
+			scene.Steps[0].Parameters["brightness"] == 20.0 &&
+			scene.Steps[0].Delay == 0 &&
+			scene.Steps[1].Delay == 2*time.Second
+	})).Return(created, nil)
+
+	parameters, err := structpb.NewStruct(map[string]interface{}{"brightness": 20})
+	require.NoError(t, err)
+	resp, err := handler.CreateScene(context.Background(), &pb.CreateSceneRequest{Scene: &pb.Scene{
+		Id:   uuid.New().String(),
+		Name: "Movie night",
+		Steps: []*pb.SceneStep{
+			{DeviceId: "light-1", FeatureId: dim.String(), Parameters: parameters, Group: "lights"},
+			{DeviceId: "tv-1", FeatureId: play.String(), Delay: durationpb.New(2 * time.Second)},
+		},
+		StopOnError: true,
+	}})
+
+	require.NoError(t, err)
+	assert.Equal(t, created.ID.String(), resp.Scene.Id)
+	require.Len(t, resp.Scene.Steps, 2)
+	assert.Nil(t, resp.Scene.Steps[0].Delay)
+	assert.Equal(t, 2*time.Second, resp.Scene.Steps[1].Delay.AsDuration())
+	assert.True(t, resp.Scene.StopOnError)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateScene_InvalidRequest(t *testing.T) {
+	handler := NewSceneHandler(new(mockSceneService), mapper.NewSceneMapper())
+
+	tests := []struct {
+		name  string
+		scene *pb.Scene
+	}{
+		{"missing scene", nil},
+		{"malformed feature", &pb.Scene{Name: "Movie night", Steps: []*pb.SceneStep{{DeviceId: "tv-1", FeatureId: "play"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := handler.CreateScene(context.Background(), &pb.CreateSceneRequest{Scene: tt.scene})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestUpdateScene_Errors(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"invalid scene", models.ErrInvalidScene, codes.InvalidArgument},
+		{"not found", models.ErrNotFound, codes.NotFound},
+		{"internal", errors.New("connection reset"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockSceneService)
+			handler := NewSceneHandler(mockService, mapper.NewSceneMapper())
+			mockService.On("UpdateScene", mock.Anything, mock.MatchedBy(func(scene *models.Scene) bool {
+				return scene.ID == id
+			})).Return(nil, tt.err)
+
+			resp, err := handler.UpdateScene(context.Background(), &pb.UpdateSceneRequest{Scene: &pb.Scene{Id: id.String(), Name: "Movie night"}})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+
+	handler := NewSceneHandler(new(mockSceneService), mapper.NewSceneMapper())
+	_, err := handler.UpdateScene(context.Background(), &pb.UpdateSceneRequest{Scene: &pb.Scene{}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestActivateScene_PartialFailure(t *testing.T) {
+	mockService := new(mockSceneService)
+	handler := NewSceneHandler(mockService, mapper.NewSceneMapper())
+
+	id := uuid.New()
+	startedAt := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	finishedAt := startedAt.Add(time.Second)
+	mockService.On("ActivateScene", mock.Anything, id).Return(&models.SceneActivation{
+		SceneID: id,
+		Status:  models.ActivationPartiallyFailed,
+		Results: []models.StepResult{
+			{Index: 0, DeviceID: "light-1", FeatureID: uuid.New(), Status: models.StepSucceeded, StartedAt: &startedAt, FinishedAt: &finishedAt},
+			{Index: 1, DeviceID: "blinds-1", FeatureID: uuid.New(), Status: models.StepFailed, Error: "device offline", StartedAt: &startedAt, FinishedAt: &finishedAt},
+			{Index: 2, DeviceID: "tv-1", FeatureID: uuid.New(), Status: models.StepSkipped},
+		},
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+	}, nil)
+
+	resp, err := handler.ActivateScene(context.Background(), &pb.ActivateSceneRequest{Id: id.String()})
+
+	require.NoError(t, err)
+	assert.Equal(t, id.String(), resp.SceneId)
+	assert.Equal(t, pb.ActivationStatus_PARTIALLY_FAILED, resp.Status)
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, pb.StepStatus_SUCCEEDED, resp.Results[0].Status)
+	assert.Equal(t, pb.StepStatus_FAILED, resp.Results[1].Status)
+	assert.Equal(t, "device offline", resp.Results[1].Error)
+	assert.Equal(t, int32(2), resp.Results[2].Index)
+	assert.Equal(t, pb.StepStatus_SKIPPED, resp.Results[2].Status)
+	assert.Nil(t, resp.Results[2].StartedAt)
+	assert.Equal(t, finishedAt, resp.FinishedAt.AsTime())
+	mockService.AssertExpectations(t)
+}
+
+func TestActivateScene_Errors(t *testing.T) {
+	mockService := new(mockSceneService)
+	handler := NewSceneHandler(mockService, mapper.NewSceneMapper())
+	id := uuid.New()
+	mockService.On("ActivateScene", mock.Anything, id).Return(nil, models.ErrNotFound)
+
+	_, err := handler.ActivateScene(context.Background(), &pb.ActivateSceneRequest{Id: id.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = handler.ActivateScene(context.Background(), &pb.ActivateSceneRequest{Id: "movie-night"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteScene_NotFound(t *testing.T) {
+	mockService := new(mockSceneService)
+	handler := NewSceneHandler(mockService, mapper.NewSceneMapper())
+	id := uuid.New()
+	mockService.On("DeleteScene", mock.Anything, id).Return(models.ErrNotFound)
+
+	resp, err := handler.DeleteScene(context.Background(), &pb.DeleteSceneRequest{Id: id.String()})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "smart-hub/gen/proto/schedule/v1"
+	"smart-hub/internal/application/interfaces"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+)
+
+type ScheduleHandler struct {
+	pb.UnimplementedScheduleServiceServer
+	service interfaces.ScheduleService
+	mapper  mapper.ScheduleMapper
+}
+
+func NewScheduleHandler(
+	service interfaces.ScheduleService,
+	mapper mapper.ScheduleMapper,
+) *ScheduleHandler {
+	return &ScheduleHandler{
+		service: service,
+		mapper:  mapper,
+	}
+}
+
+func (h *ScheduleHandler) CreateSchedule(ctx context.Context, req *pb.CreateScheduleRequest) (*pb.CreateScheduleResponse, error) {
+	logger.FromContext(ctx).Debug("Creating schedule", "name", req.GetSchedule().GetName())
+
+	schedule, err := h.mapper.ToDomain(req.Schedule)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid schedule: "+err.Error())
+	}
+	schedule.ID = uuid.Nil
+
+	created, err := h.service.CreateSchedule(ctx, schedule)
+	if err != nil {
+		return nil, scheduleError(ctx, err, "failed to create schedule")
+	}
+
+	protoSchedule, err := h.toProto(ctx, created)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateScheduleResponse{Schedule: protoSchedule}, nil
+}
+
+func (h *ScheduleHandler) GetSchedule(ctx context.Context, req *pb.GetScheduleRequest) (*pb.GetScheduleResponse, error) {
+	logger.FromContext(ctx).Debug("Getting schedule", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	schedule, err := h.service.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, scheduleError(ctx, err, "failed to get schedule")
+	}
+
+	protoSchedule, err := h.toProto(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetScheduleResponse{Schedule: protoSchedule}, nil
+}
+
+func (h *ScheduleHandler) ListSchedules(ctx context.Context, req *pb.ListSchedulesRequest) (*pb.ListSchedulesResponse, error) {
+	logger.FromContext(ctx).Debug("Listing schedules")
+
+	schedules, err := h.service.ListSchedules(ctx)
+	if err != nil {
+		return nil, scheduleError(ctx, err, "failed to list schedules")
+	}
+
+	protoSchedules, err := h.mapper.ToProtoList(schedules)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to convert schedules to proto", "error", err)
+		return nil, status.Error(codes.Internal, "failed to convert schedules to proto")
+	}
+	return &pb.ListSchedulesResponse{Schedules: protoSchedules}, nil
+}
+
+func (h *ScheduleHandler) UpdateSchedule(ctx context.Context, req *pb.UpdateScheduleRequest) (*pb.UpdateScheduleResponse, error) {
+	logger.FromContext(ctx).Debug("Updating schedule", "id", req.GetSchedule().GetId())
+
+	schedule, err := h.mapper.ToDomain(req.Schedule)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid schedule: "+err.Error())
+	}
+	if schedule.ID == uuid.Nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid schedule: id is required")
+	}
+
+	updated, err := h.service.UpdateSchedule(ctx, schedule)
+	if err != nil {
+		return nil, scheduleError(ctx, err, "failed to update schedule")
+	}
+
+	protoSchedule, err := h.toProto(ctx, updated)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateScheduleResponse{Schedule: protoSchedule}, nil
+}
+
+func (h *ScheduleHandler) DeleteSchedule(ctx context.Context, req *pb.DeleteScheduleRequest) (*pb.DeleteScheduleResponse, error) {
+	logger.FromContext(ctx).Debug("Deleting schedule", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := h.service.DeleteSchedule(ctx, id); err != nil {
+		return nil, scheduleError(ctx, err, "failed to delete schedule")
+	}
+
+	return &pb.DeleteScheduleResponse{}, nil
+}
+
+func (h *ScheduleHandler) PauseSchedule(ctx context.Context, req *pb.PauseScheduleRequest) (*pb.PauseScheduleResponse, error) {
+	logger.FromContext(ctx).Debug("Pausing schedule", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	schedule, err := h.service.PauseSchedule(ctx, id)
+	if err != nil {
+		return nil, scheduleError(ctx, err, "failed to pause schedule")
+	}
+
+	protoSchedule, err := h.toProto(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PauseScheduleResponse{Schedule: protoSchedule}, nil
+}
+
+func (h *ScheduleHandler) ResumeSchedule(ctx context.Context, req *pb.ResumeScheduleRequest) (*pb.ResumeScheduleResponse, error) {
+	logger.FromContext(ctx).Debug("Resuming schedule", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	schedule, err := h.service.ResumeSchedule(ctx, id)
+	if err != nil {
+		return nil, scheduleError(ctx, err, "failed to resume schedule")
+	}
+
+	protoSchedule, err := h.toProto(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ResumeScheduleResponse{Schedule: protoSchedule}, nil
+}
+
+func (h *ScheduleHandler) ListScheduleRuns(ctx context.Context, req *pb.ListScheduleRunsRequest) (*pb.ListScheduleRunsResponse, error) {
+	logger.FromContext(ctx).Debug("Listing schedule runs", "request", req)
+
+	var scheduleID *uuid.UUID
+	if req.ScheduleId != "" {
+		id, err := uuid.Parse(req.ScheduleId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		scheduleID = &id
+	}
+	pageSize := int(req.PageSize)
+	switch {
+	case pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	runs, nextPageToken, err := h.service.ListRuns(ctx, scheduleID, pageSize, req.PageToken)
+	if err != nil {
+		return nil, scheduleError(ctx, err, "failed to list schedule runs")
+	}
+
+	return &pb.ListScheduleRunsResponse{Runs: h.mapper.ToRunProtoList(runs), NextPageToken: nextPageToken}, nil
+}
+
+func (h *ScheduleHandler) toProto(ctx context.Context, schedule *models.Schedule) (*pb.Schedule, error) {
+	protoSchedule, err := h.mapper.ToProto(schedule)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to convert schedule to proto", "error", err)
+		return nil, status.Error(codes.Internal, "failed to convert schedule to proto")
+	}
+	return protoSchedule, nil
+}
+
+func scheduleError(ctx context.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidSchedule), errors.Is(err, models.ErrInvalidPageToken):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, "schedule not found")
+	}
+	logger.FromContext(ctx).Error(message, "error", err)
+	return status.Error(codes.Internal, message)
+}
@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	pb "smart-hub/gen/proto/schedule/v1"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+	"testing"
+	"time"
+)
+
+type mockScheduleService struct {
+	mock.Mock
+}
+
+func (m *mockScheduleService) CreateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	args := m.Called(ctx, schedule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Schedule), args.Error(1)
+}
+
+func (m *mockScheduleService) GetSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Schedule), args.Error(1)
+}
+
+func (m *mockScheduleService) ListSchedules(ctx context.Context) ([]*models.Schedule, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Schedule), args.Error(1)
+}
+
+func (m *mockScheduleService) UpdateSchedule(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	args := m.Called(ctx, schedule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Schedule), args.Error(1)
+}
+
+func (m *mockScheduleService) DeleteSchedule(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockScheduleService) PauseSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Schedule), args.Error(1)
+}
+
+func (m *mockScheduleService) ResumeSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Schedule), args.Error(1)
+}
+
+func (m *mockScheduleService) ListRuns(ctx context.Context, scheduleID *uuid.UUID, pageSize int, pageToken string) ([]*models.ScheduleRun, string, error) {
+	args := m.Called(ctx, scheduleID, pageSize, pageToken)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).([]*models.ScheduleRun), args.String(1), args.Error(2)
+}
+
+func TestCreateSchedule_Success(t *testing.T) {
+	mockService := new(mockScheduleService)
+	handler := NewScheduleHandler(mockService, mapper.NewScheduleMapper())
+
+	featureID := uuid.New()
+	nextRunAt := time.Date(2024, 3, 1, 12, 15, 0, 0, time.UTC)
+	created := &models.Schedule{
+		ID:         uuid.New(),
+		Name:       "Poll meter",
+		Interval:   15 * time.Minute,
+		Timezone:   "UTC",
+		DeviceID:   "meter-1",
+		FeatureID:  featureID,
+		Parameters: map[string]interface{}{"full": true},
+		NextRunAt:  nextRunAt,
+	}
+	mockService.On("CreateSchedule", mock.Anything, mock.MatchedBy(func(schedule *models.Schedule) bool {
+		return schedule.ID == uuid.Nil &&
+			schedule.Name == "Poll meter" &&
+			schedule.Cron == "" &&
+			schedule.Interval == 15*time.Minute &&
+			schedule.FeatureID == featureID &&
+			schedule.Parameters["full"] == true
+	})).Return(created, nil)
+
+	parameters, err := structpb.NewStruct(map[string]interface{}{"full": true})
+	require.NoError(t, err)
+	resp, err := handler.CreateSchedule(context.Background(), &pb.CreateScheduleRequest{Schedule: &pb.Schedule{
+		Id:         uuid.New().String(),
+		Name:       "Poll meter",
+		Timing:     &pb.Schedule_Interval{Interval: durationpb.New(15 * time.Minute)},
+		DeviceId:   "meter-1",
+		FeatureId:  featureID.String(),
+		Parameters: parameters,
+		Paused:     true,
+	}})
+
+	require.NoError(t, err)
+	assert.Equal(t, created.ID.String(), resp.Schedule.Id)
+	assert.Equal(t, 15*time.Minute, resp.Schedule.GetInterval().AsDuration())
+	assert.Empty(t, resp.Schedule.GetCron())
+	assert.Equal(t, nextRunAt, resp.Schedule.NextRunAt.AsTime())
+	assert.Nil(t, resp.Schedule.LastRunAt)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateSchedule_InvalidRequest(t *testing.T) {
+	handler := NewScheduleHandler(new(mockScheduleService), mapper.NewScheduleMapper())
+
+	tests := []struct {
+		name     string
+		schedule *pb.Schedule
+	}{
+		{"missing schedule", nil},
+		{"malformed feature", &pb.Schedule{Timing: &pb.Schedule_Cron{Cron: "@daily"}, FeatureId: "power"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := handler.CreateSchedule(context.Background(), &pb.CreateScheduleRequest{Schedule: tt.schedule})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestUpdateSchedule_Errors(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"invalid schedule", fmt.Errorf("%w: cron: expected 5 fields, got 4", models.ErrInvalidSchedule), codes.InvalidArgument},
+		{"not found", models.ErrNotFound, codes.NotFound},
+		{"internal", errors.New("connection reset"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockScheduleService)
+			handler := NewScheduleHandler(mockService, mapper.NewScheduleMapper())
+			mockService.On("UpdateSchedule", mock.Anything, mock.MatchedBy(func(schedule *models.Schedule) bool {
+				return schedule.ID == id && schedule.Cron == "0 7 * *"
+			})).Return(nil, tt.err)
+
+			resp, err := handler.UpdateSchedule(context.Background(), &pb.UpdateScheduleRequest{Schedule: &pb.Schedule{
+				Id:     id.String(),
+				Timing: &pb.Schedule_Cron{Cron: "0 7 * *"},
+			}})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+
+	handler := NewScheduleHandler(new(mockScheduleService), mapper.NewScheduleMapper())
+	_, err := handler.UpdateSchedule(context.Background(), &pb.UpdateScheduleRequest{Schedule: &pb.Schedule{}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestPauseAndResumeSchedule(t *testing.T) {
+	mockService := new(mockScheduleService)
+	handler := NewScheduleHandler(mockService, mapper.NewScheduleMapper())
+	id := uuid.New()
+	lastRunAt := time.Date(2024, 3, 1, 7, 30, 0, 0, time.UTC)
+	schedule := &models.Schedule{ID: id, Cron: "30 7 * * *", Paused: true, LastRunAt: &lastRunAt}
+	mockService.On("PauseSchedule", mock.Anything, id).Return(schedule, nil)
+	mockService.On("ResumeSchedule", mock.Anything, id).Return(nil, models.ErrNotFound)
+
+	paused, err := handler.PauseSchedule(context.Background(), &pb.PauseScheduleRequest{Id: id.String()})
+	require.NoError(t, err)
+	assert.True(t, paused.Schedule.Paused)
+	assert.Equal(t, "30 7 * * *", paused.Schedule.GetCron())
+	assert.Equal(t, lastRunAt, paused.Schedule.LastRunAt.AsTime())
+
+	_, err = handler.ResumeSchedule(context.Background(), &pb.ResumeScheduleRequest{Id: id.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = handler.PauseSchedule(context.Background(), &pb.PauseScheduleRequest{Id: "schedule-1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockService.AssertExpectations(t)
+}
+
+func TestListScheduleRuns_Success(t *testing.T) {
+	mockService := new(mockScheduleService)
+	handler := NewScheduleHandler(mockService, mapper.NewScheduleMapper())
+
+	scheduleID := uuid.New()
+	scheduledAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := scheduledAt.Add(2 * time.Second)
+	finishedAt := startedAt.Add(time.Second)
+	mockService.On("ListRuns", mock.Anything, &scheduleID, defaultPageSize, "token-1").Return([]*models.ScheduleRun{
+		{
+			ID:          uuid.New(),
+			ScheduleID:  scheduleID,
+			ScheduledAt: scheduledAt,
+			Status:      models.ExecutionFailed,
+			Error:       "device offline",
+			StartedAt:   startedAt,
+			FinishedAt:  &finishedAt,
+		},
+		{ID: uuid.New(), ScheduleID: scheduleID, Status: models.ExecutionRunning, StartedAt: startedAt},
+	}, "token-2", nil)
+
+	resp, err := handler.ListScheduleRuns(context.Background(), &pb.ListScheduleRunsRequest{ScheduleId: scheduleID.String(), PageToken: "token-1"})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Runs, 2)
+	assert.Equal(t, "token-2", resp.NextPageToken)
+	assert.Equal(t, pb.RunStatus_FAILED, resp.Runs[0].Status)
+	assert.Equal(t, "device offline", resp.Runs[0].Error)
+	assert.Equal(t, scheduledAt, resp.Runs[0].ScheduledAt.AsTime())
+	assert.Equal(t, finishedAt, resp.Runs[0].FinishedAt.AsTime())
+	assert.Equal(t, pb.RunStatus_RUNNING, resp.Runs[1].Status)
+	assert.Nil(t, resp.Runs[1].FinishedAt)
+	mockService.AssertExpectations(t)
+}
+
+func TestListScheduleRuns_InvalidRequest(t *testing.T) {
+	mockService := new(mockScheduleService)
+	handler := NewScheduleHandler(mockService, mapper.NewScheduleMapper())
+	mockService.On("ListRuns", mock.Anything, (*uuid.UUID)(nil), maxPageSize, "bad").Return(nil, "", models.ErrInvalidPageToken)
+
+	_, err := handler.ListScheduleRuns(context.Background(), &pb.ListScheduleRunsRequest{PageSize: 5000, PageToken: "bad"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = handler.ListScheduleRuns(context.Background(), &pb.ListScheduleRunsRequest{ScheduleId: "schedule-1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = handler.ListScheduleRuns(context.Background(), &pb.ListScheduleRunsRequest{PageSize: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteSchedule_NotFound(t *testing.T) {
+	mockService := new(mockScheduleService)
+	handler := NewScheduleHandler(mockService, mapper.NewScheduleMapper())
+	id := uuid.New()
+	mockService.On("DeleteSchedule", mock.Anything, id).Return(models.ErrNotFound)
+
+	resp, err := handler.DeleteSchedule(context.Background(), &pb.DeleteScheduleRequest{Id: id.String()})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
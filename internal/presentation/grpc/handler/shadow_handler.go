@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "smart-hub/gen/proto/shadow/v1"
+	"smart-hub/internal/application/interfaces"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+)
+
+type ShadowHandler struct {
+	pb.UnimplementedDeviceShadowServiceServer
+	service interfaces.ShadowService
+	mapper  mapper.ShadowMapper
+}
+
+func NewShadowHandler(
+	service interfaces.ShadowService,
+	mapper mapper.ShadowMapper,
+) *ShadowHandler {
+	return &ShadowHandler{
+		service: service,
+		mapper:  mapper,
+	}
+}
+
+func (h *ShadowHandler) GetDeviceShadow(ctx context.Context, req *pb.GetDeviceShadowRequest) (*pb.GetDeviceShadowResponse, error) {
+	logger.FromContext(ctx).Debug("Getting device shadow", "request", req)
+
+	shadow, err := h.service.GetShadow(ctx, req.DeviceId)
+	if err != nil {
+		return nil, shadowError(ctx, err, "failed to get device shadow")
+	}
+
+	protoShadow, err := h.toProto(ctx, shadow)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetDeviceShadowResponse{Shadow: protoShadow}, nil
+}
+
+func (h *ShadowHandler) UpdateDesiredState(ctx context.Context, req *pb.UpdateDesiredStateRequest) (*pb.UpdateDesiredStateResponse, error) {
+	logger.FromContext(ctx).Debug("Updating desired device state", "request", req)
+
+	shadow, err := h.service.UpdateDesiredState(ctx, req.DeviceId, h.mapper.ToDomainState(req.State), req.ExpectedVersion)
+	if err != nil {
+		return nil, shadowError(ctx, err, "failed to update desired state")
+	}
+
+	protoShadow, err := h.toProto(ctx, shadow)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateDesiredStateResponse{Shadow: protoShadow}, nil
+}
+
+func (h *ShadowHandler) ReportState(ctx context.Context, req *pb.ReportStateRequest) (*pb.ReportStateResponse, error) {
+	logger.FromContext(ctx).Debug("Reporting device state", "request", req)
+
+	shadow, err := h.service.ReportState(ctx, req.DeviceId, h.mapper.ToDomainState(req.State), req.ExpectedVersion)
+	if err != nil {
+		return nil, shadowError(ctx, err, "failed to report state")
+	}
+
+	protoShadow, err := h.toProto(ctx, shadow)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ReportStateResponse{Shadow: protoShadow}, nil
+}
+
+func (h *ShadowHandler) DeleteDeviceShadow(ctx context.Context, req *pb.DeleteDeviceShadowRequest) (*pb.DeleteDeviceShadowResponse, error) {
+	logger.FromContext(ctx).Debug("Deleting device shadow", "request", req)
+
+	if err := h.service.DeleteShadow(ctx, req.DeviceId); err != nil {
+		return nil, shadowError(ctx, err, "failed to delete device shadow")
+	}
+
+	return &pb.DeleteDeviceShadowResponse{}, nil
+}
+
+func (h *ShadowHandler) WatchShadowDeltas(req *pb.WatchShadowDeltasRequest, stream pb.DeviceShadowService_WatchShadowDeltasServer) error {
+	ctx := stream.Context()
+	logger.FromContext(ctx).Debug("Watching shadow deltas", "request", req)
+
+	err := h.service.WatchDeltas(ctx, req.DeviceIds, req.ResumeToken, func(event *models.ShadowDeltaEvent) error {
+		resp, err := h.mapper.ToWatchResponse(event)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to convert shadow delta to proto", "error", err)
+			return status.Error(codes.Internal, "failed to convert shadow delta to proto")
+		}
+		return stream.Send(resp)
+	})
+	return watchError(ctx, err)
+}
+
+func (h *ShadowHandler) toProto(ctx context.Context, shadow *models.DeviceShadow) (*pb.DeviceShadow, error) {
+	protoShadow, err := h.mapper.ToProto(shadow)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to convert device shadow to proto", "error", err)
+		return nil, status.Error(codes.Internal, "failed to convert device shadow to proto")
+	}
+	return protoShadow, nil
+}
+
+func shadowError(ctx context.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidShadowState):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrVersionConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, "device shadow not found")
+	}
+	logger.FromContext(ctx).Error(message, "error", err)
+	return status.Error(codes.Internal, message)
+}
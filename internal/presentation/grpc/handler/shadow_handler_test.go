@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	pb "smart-hub/gen/proto/shadow/v1"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+	"testing"
+	"time"
+)
+
+type mockShadowService struct {
+	mock.Mock
+}
+
+func (m *mockShadowService) GetShadow(ctx context.Context, deviceID string) (*models.DeviceShadow, error) {
+	args := m.Called(ctx, deviceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeviceShadow), args.Error(1)
+}
+
+func (m *mockShadowService) UpdateDesiredState(ctx context.Context, deviceID string, state map[string]interface{}, expectedVersion *int64) (*models.DeviceShadow, error) {
+	args := m.Called(ctx, deviceID, state, expectedVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeviceShadow), args.Error(1)
+}
+
+func (m *mockShadowService) ReportState(ctx context.Context, deviceID string, state map[string]interface{}, expectedVersion *int64) (*models.DeviceShadow, error) {
+	args := m.Called(ctx, deviceID, state, expectedVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeviceShadow), args.Error(1)
+}
+
+func (m *mockShadowService) DeleteShadow(ctx context.Context, deviceID string) error {
+	args := m.Called(ctx, deviceID)
+	return args.Error(0)
+}
+
+// WatchDeltas sends the events given to Return before returning its error.
+func (m *mockShadowService) WatchDeltas(ctx context.Context, deviceIDs []string, resumeToken string, send func(*models.ShadowDeltaEvent) error) error {
+	args := m.Called(ctx, deviceIDs, resumeToken)
+	for _, event := range args.Get(0).([]*models.ShadowDeltaEvent) {
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+type fakeWatchShadowDeltasServer struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*pb.WatchShadowDeltasResponse
+}
+
+func (s *fakeWatchShadowDeltasServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeWatchShadowDeltasServer) Send(resp *pb.WatchShadowDeltasResponse) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+func TestGetDeviceShadow_Success(t *testing.T) {
+	mockService := new(mockShadowService)
+	handler := NewShadowHandler(mockService, mapper.NewShadowMapper())
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	mockService.On("GetShadow", mock.Anything, "dev-1").Return(&models.DeviceShadow{
+		DeviceID:  "dev-1",
+		Desired:   map[string]interface{}{"a": "on", "b": 2.0},
+		Reported:  map[string]interface{}{"a": "on"},
+		Version:   7,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil)
+
+	resp, err := handler.GetDeviceShadow(context.Background(), &pb.GetDeviceShadowRequest{DeviceId: "dev-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), resp.Shadow.Version)
+	assert.Equal(t, map[string]interface{}{"b": 2.0}, resp.Shadow.Delta.AsMap())
+	assert.Equal(t, now, resp.Shadow.UpdatedAt.AsTime())
+	mockService.AssertExpectations(t)
+}
+
+func TestGetDeviceShadow_NotFound(t *testing.T) {
+	mockService := new(mockShadowService)
+	handler := NewShadowHandler(mockService, mapper.NewShadowMapper())
+
+	mockService.On("GetShadow", mock.Anything, "dev-1").Return(nil, models.ErrNotFound)
+
+	resp, err := handler.GetDeviceShadow(context.Background(), &pb.GetDeviceShadowRequest{DeviceId: "dev-1"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestUpdateDesiredState_PassesNullsAndVersion(t *testing.T) {
+	mockService := new(mockShadowService)
+	handler := NewShadowHandler(mockService, mapper.NewShadowMapper())
+
+	version := int64(3)
+	mockService.On("UpdateDesiredState", mock.Anything, "dev-1", map[string]interface{}{"a": "on", "b": nil}, &version).
+		Return(&models.DeviceShadow{DeviceID: "dev-1", Desired: map[string]interface{}{"a": "on"}, Version: 4}, nil)
+
+	state, err := structpb.NewStruct(map[string]interface{}{"a": "on", "b": nil})
+	require.NoError(t, err)
+	resp, err := handler.UpdateDesiredState(context.Background(), &pb.UpdateDesiredStateRequest{
+		DeviceId:        "dev-1",
+		State:           state,
+		ExpectedVersion: &version,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), resp.Shadow.Version)
+	assert.Equal(t, map[string]interface{}{"a": "on"}, resp.Shadow.Delta.AsMap())
+	mockService.AssertExpectations(t)
+}
+
+func TestReportState_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"invalid state", fmt.Errorf("%w: unknown smart features: x", models.ErrInvalidShadowState), codes.InvalidArgument},
+		{"version conflict", models.ErrVersionConflict, codes.Aborted},
+		{"internal", errors.New("connection reset"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockShadowService)
+			handler := NewShadowHandler(mockService, mapper.NewShadowMapper())
+			mockService.On("ReportState", mock.Anything, "dev-1", map[string]interface{}{}, (*int64)(nil)).Return(nil, tt.err)
+
+			resp, err := handler.ReportState(context.Background(), &pb.ReportStateRequest{DeviceId: "dev-1"})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestWatchShadowDeltas_Success(t *testing.T) {
+	mockService := new(mockShadowService)
+	handler := NewShadowHandler(mockService, mapper.NewShadowMapper())
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	mockService.On("WatchDeltas", mock.Anything, []string{"dev-1"}, "4").Return([]*models.ShadowDeltaEvent{
+		{Delta: &models.ShadowDelta{Sequence: 5, DeviceID: "dev-1", Version: 2, Delta: map[string]interface{}{"a": "on"}, CreatedAt: at}, ResumeToken: "5"},
+		{Delta: &models.ShadowDelta{Sequence: 6, DeviceID: "dev-1", Delta: map[string]interface{}{}, CreatedAt: at}, ResumeToken: "6"},
+	}, models.ErrSlowConsumer)
+
+	stream := &fakeWatchShadowDeltasServer{ctx: context.Background()}
+	err := handler.WatchShadowDeltas(&pb.WatchShadowDeltasRequest{DeviceIds: []string{"dev-1"}, ResumeToken: "4"}, stream)
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Len(t, stream.responses, 2)
+	assert.Equal(t, "5", stream.responses[0].ResumeToken)
+	assert.Equal(t, map[string]interface{}{"a": "on"}, stream.responses[0].Delta.AsMap())
+	assert.Equal(t, int64(0), stream.responses[1].Version)
+	assert.Empty(t, stream.responses[1].Delta.AsMap())
+	mockService.AssertExpectations(t)
+}
@@ -2,33 +2,45 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	pb "smart-hub/gen/proto/smart_feature/v1"
 	"smart-hub/internal/application/interfaces"
 	"smart-hub/internal/common/logger"
 	"smart-hub/internal/common/validation"
+	"smart-hub/internal/domain/models"
 	"smart-hub/internal/presentation/grpc/mapper"
 )
 
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
 type SmartFeatureHandler struct {
 	pb.UnimplementedSmartFeatureServiceServer
 	service interfaces.SmartFeatureService
+	watcher interfaces.CatalogWatchService
 	mapper  mapper.SmartFeatureMapper
 }
 
 func NewSmartFeatureHandler(
 	service interfaces.SmartFeatureService,
+	watcher interfaces.CatalogWatchService,
 	mapper mapper.SmartFeatureMapper,
 ) *SmartFeatureHandler {
 	return &SmartFeatureHandler{
 		service: service,
+		watcher: watcher,
 		mapper:  mapper,
 	}
 }
 
 func (h *SmartFeatureHandler) CreateSmartFeature(ctx context.Context, req *pb.CreateSmartFeatureRequest) (*pb.CreateSmartFeatureResponse, error) {
-	logger.Debug("Creating smart feature", "request", req)
+	logger.FromContext(ctx).Debug("Creating smart feature", "request", req)
 
 	smartFeature, err := h.mapper.ToDomain(req)
 	if err != nil {
@@ -45,13 +57,12 @@ func (h *SmartFeatureHandler) CreateSmartFeature(ctx context.Context, req *pb.Cr
 
 	createdFeature, err := h.service.Create(ctx, smartFeature)
 	if err != nil {
-		logger.Error("Failed to create smart feature", "error", err)
-		return nil, status.Error(codes.Internal, "failed to create smart feature")
+		return nil, catalogError(ctx, err, "failed to create smart feature")
 	}
 
 	protoFeature, err := h.mapper.ToProto(createdFeature)
 	if err != nil {
-		logger.Error("Failed to convert smart feature to proto", "error", err)
+		logger.FromContext(ctx).Error("Failed to convert smart feature to proto", "error", err)
 		return nil, status.Error(codes.Internal, "failed to convert smart feature to proto")
 	}
 
@@ -61,7 +72,7 @@ func (h *SmartFeatureHandler) CreateSmartFeature(ctx context.Context, req *pb.Cr
 }
 
 func (h *SmartFeatureHandler) GetSmartFeature(ctx context.Context, req *pb.GetSmartFeatureRequest) (*pb.GetSmartFeatureResponse, error) {
-	logger.Debug("Getting smart feature", "request", req)
+	logger.FromContext(ctx).Debug("Getting smart feature", "request", req)
 
 	if err := validation.ValidateUUID(req.Id); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -69,13 +80,13 @@ func (h *SmartFeatureHandler) GetSmartFeature(ctx context.Context, req *pb.GetSm
 
 	smartFeature, err := h.service.GetByID(ctx, req.Id)
 	if err != nil {
-		logger.Error("Failed to get smart feature", "error", err)
+		logger.FromContext(ctx).Error("Failed to get smart feature", "error", err)
 		return nil, status.Error(codes.Internal, "failed to get smart feature")
 	}
 
 	protoFeature, err := h.mapper.ToProto(smartFeature)
 	if err != nil {
-		logger.Error("Failed to convert smart feature to proto", "error", err)
+		logger.FromContext(ctx).Error("Failed to convert smart feature to proto", "error", err)
 		return nil, status.Error(codes.Internal, "failed to convert smart feature to proto")
 	}
 
@@ -85,7 +96,7 @@ func (h *SmartFeatureHandler) GetSmartFeature(ctx context.Context, req *pb.GetSm
 }
 
 func (h *SmartFeatureHandler) GetFeaturesByModelID(ctx context.Context, req *pb.GetFeaturesByModelIDRequest) (*pb.GetFeaturesByModelIDResponse, error) {
-	logger.Debug("Getting smart features by model ID", "request", req)
+	logger.FromContext(ctx).Debug("Getting smart features by model ID", "request", req)
 
 	err := validation.ValidateUUID(req.ModelId)
 	if err != nil {
@@ -94,13 +105,13 @@ func (h *SmartFeatureHandler) GetFeaturesByModelID(ctx context.Context, req *pb.
 
 	smartFeatures, err := h.service.GetWithModelID(ctx, req.ModelId)
 	if err != nil {
-		logger.Error("Failed to get smart features by model ID", "error", err)
+		logger.FromContext(ctx).Error("Failed to get smart features by model ID", "error", err)
 		return nil, status.Error(codes.Internal, "failed to get smart features by model ID")
 	}
 
 	protoFeatures, err := h.mapper.ToListResponse(smartFeatures)
 	if err != nil {
-		logger.Error("Failed to convert smart features to proto", "error", err)
+		logger.FromContext(ctx).Error("Failed to convert smart features to proto", "error", err)
 		return nil, status.Error(codes.Internal, "failed to convert smart features to proto")
 	}
 
@@ -109,8 +120,42 @@ func (h *SmartFeatureHandler) GetFeaturesByModelID(ctx context.Context, req *pb.
 	}, nil
 }
 
+func (h *SmartFeatureHandler) ListSmartFeatures(ctx context.Context, req *pb.ListSmartFeaturesRequest) (*pb.ListSmartFeaturesResponse, error) {
+	logger.FromContext(ctx).Debug("Listing smart features", "request", req)
+
+	pageSize := int(req.PageSize)
+	switch {
+	case pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	features, nextPageToken, err := h.service.List(ctx, h.mapper.ToListFilter(req), pageSize, req.PageToken)
+	if errors.Is(err, models.ErrInvalidPageToken) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to list smart features", "error", err)
+		return nil, status.Error(codes.Internal, "failed to list smart features")
+	}
+
+	protoFeatures, err := h.mapper.ToListResponse(features)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to convert smart features to proto", "error", err)
+		return nil, status.Error(codes.Internal, "failed to convert smart features to proto")
+	}
+
+	return &pb.ListSmartFeaturesResponse{
+		Features:      protoFeatures.Features,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
 func (h *SmartFeatureHandler) UpdateSmartFeature(ctx context.Context, req *pb.UpdateSmartFeatureRequest) (*pb.UpdateSmartFeatureResponse, error) {
-	logger.Debug("Updating smart feature", "request", req)
+	logger.FromContext(ctx).Debug("Updating smart feature", "request", req)
 
 	smartFeature, err := h.mapper.ToDomainUpdate(req)
 	if err != nil {
@@ -123,13 +168,12 @@ func (h *SmartFeatureHandler) UpdateSmartFeature(ctx context.Context, req *pb.Up
 
 	updatedFeature, err := h.service.Update(ctx, smartFeature)
 	if err != nil {
-		logger.Error("Failed to update smart feature", "error", err)
-		return nil, status.Error(codes.Internal, "failed to update smart feature")
+		return nil, catalogError(ctx, err, "failed to update smart feature")
 	}
 
 	protoFeature, err := h.mapper.ToProto(updatedFeature)
 	if err != nil {
-		logger.Error("Failed to convert smart feature to proto", "error", err)
+		logger.FromContext(ctx).Error("Failed to convert smart feature to proto", "error", err)
 		return nil, status.Error(codes.Internal, "failed to convert smart feature to proto")
 	}
 
@@ -139,7 +183,7 @@ func (h *SmartFeatureHandler) UpdateSmartFeature(ctx context.Context, req *pb.Up
 }
 
 func (h *SmartFeatureHandler) DeleteSmartFeature(ctx context.Context, req *pb.DeleteSmartFeatureRequest) (*pb.DeleteSmartFeatureResponse, error) {
-	logger.Debug("Deleting smart feature", "request", req)
+	logger.FromContext(ctx).Debug("Deleting smart feature", "request", req)
 
 	err := validation.ValidateUUID(req.Id)
 	if err != nil {
@@ -148,9 +192,219 @@ func (h *SmartFeatureHandler) DeleteSmartFeature(ctx context.Context, req *pb.De
 
 	err = h.service.Delete(ctx, req.Id)
 	if err != nil {
-		logger.Error("Failed to delete smart feature", "error", err)
-		return nil, status.Error(codes.Internal, "failed to delete smart feature")
+		return nil, catalogError(ctx, err, "failed to delete smart feature")
 	}
 
 	return &pb.DeleteSmartFeatureResponse{}, nil
 }
+
+func (h *SmartFeatureHandler) WatchSmartFeatures(req *pb.WatchSmartFeaturesRequest, stream pb.SmartFeatureService_WatchSmartFeaturesServer) error {
+	ctx := stream.Context()
+	logger.FromContext(ctx).Debug("Watching smart features", "request", req)
+
+	filter, err := h.mapper.ToWatchFilter(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid model_id")
+	}
+
+	err = h.watcher.WatchFeatures(ctx, filter, req.ResumeToken, func(event *models.WatchEvent) error {
+		resp, err := h.mapper.ToWatchResponse(event)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to convert smart feature to proto", "error", err)
+			return status.Error(codes.Internal, "failed to convert smart feature to proto")
+		}
+		return stream.Send(resp)
+	})
+	return watchError(ctx, err)
+}
+
+func (h *SmartFeatureHandler) BatchCreateSmartFeatures(ctx context.Context, req *pb.BatchCreateSmartFeaturesRequest) (*pb.BatchCreateSmartFeaturesResponse, error) {
+	logger.FromContext(ctx).Debug("Batch creating smart features", "count", len(req.Features), "partial", req.PartialSuccess)
+
+	if err := validateBatchSize(len(req.Features)); err != nil {
+		return nil, err
+	}
+
+	v := newBatchValidation(len(req.Features), req.PartialSuccess)
+	features := make([]*models.SmartFeature, 0, len(req.Features))
+	for i, input := range req.Features {
+		feature, err := h.mapper.ToDomainInput(input)
+		if err == nil {
+			err = validation.ValidateStruct(feature)
+		}
+		if err := v.check(i, err); err != nil {
+			return nil, err
+		}
+		if err == nil {
+			features = append(features, feature)
+		}
+	}
+
+	created, itemErrs, err := h.service.BatchCreate(ctx, features, req.PartialSuccess)
+	if err != nil {
+		return nil, v.fail(ctx, err, "failed to create smart features")
+	}
+
+	results, err := h.batchResults(ctx, scatter(v, created), v.merge(itemErrs))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.BatchCreateSmartFeaturesResponse{Results: results}, nil
+}
+
+func (h *SmartFeatureHandler) BatchUpdateSmartFeatures(ctx context.Context, req *pb.BatchUpdateSmartFeaturesRequest) (*pb.BatchUpdateSmartFeaturesResponse, error) {
+	logger.FromContext(ctx).Debug("Batch updating smart features", "count", len(req.Features), "partial", req.PartialSuccess)
+
+	if err := validateBatchSize(len(req.Features)); err != nil {
+		return nil, err
+	}
+
+	v := newBatchValidation(len(req.Features), req.PartialSuccess)
+	features := make([]*models.SmartFeature, 0, len(req.Features))
+	seen := make(map[uuid.UUID]bool, len(req.Features))
+	for i, input := range req.Features {
+		feature, err := h.mapper.ToDomainUpdateInput(input)
+		if err == nil {
+			err = validation.ValidateStruct(feature)
+		}
+		if err == nil && seen[feature.ID] {
+			err = fmt.Errorf("feature %s appears more than once", feature.ID)
+		}
+		if err := v.check(i, err); err != nil {
+			return nil, err
+		}
+		if err == nil {
+			seen[feature.ID] = true
+			features = append(features, feature)
+		}
+	}
+
+	updated, itemErrs, err := h.service.BatchUpdate(ctx, features, req.PartialSuccess)
+	if err != nil {
+		return nil, v.fail(ctx, err, "failed to update smart features")
+	}
+
+	results, err := h.batchResults(ctx, scatter(v, updated), v.merge(itemErrs))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.BatchUpdateSmartFeaturesResponse{Results: results}, nil
+}
+
+func (h *SmartFeatureHandler) BatchDeleteSmartFeatures(ctx context.Context, req *pb.BatchDeleteSmartFeaturesRequest) (*pb.BatchDeleteSmartFeaturesResponse, error) {
+	logger.FromContext(ctx).Debug("Batch deleting smart features", "count", len(req.Ids), "partial", req.PartialSuccess)
+
+	if err := validateBatchSize(len(req.Ids)); err != nil {
+		return nil, err
+	}
+
+	v := newBatchValidation(len(req.Ids), req.PartialSuccess)
+	ids := make([]string, 0, len(req.Ids))
+	for i, id := range req.Ids {
+		err := validation.ValidateUUID(id)
+		if err := v.check(i, err); err != nil {
+			return nil, err
+		}
+		if err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	itemErrs, err := h.service.BatchDelete(ctx, ids, req.PartialSuccess)
+	if err != nil {
+		return nil, v.fail(ctx, err, "failed to delete smart features")
+	}
+
+	results := make([]*pb.BatchItemStatus, len(req.Ids))
+	for i, itemErr := range v.merge(itemErrs) {
+		code, message := itemStatus(ctx, itemErr)
+		results[i] = &pb.BatchItemStatus{Code: int32(code), Message: message}
+	}
+	return &pb.BatchDeleteSmartFeaturesResponse{Results: results}, nil
+}
+
+func (h *SmartFeatureHandler) batchResults(ctx context.Context, features []*models.SmartFeature, itemErrs []error) ([]*pb.BatchSmartFeatureResult, error) {
+	results := make([]*pb.BatchSmartFeatureResult, len(features))
+	for i, feature := range features {
+		code, message := itemStatus(ctx, itemErrs[i])
+		protoFeature, err := h.mapper.ToProto(feature)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to convert smart feature to proto", "error", err)
+			return nil, status.Error(codes.Internal, "failed to convert smart feature to proto")
+		}
+		results[i] = &pb.BatchSmartFeatureResult{
+			Status:  &pb.BatchItemStatus{Code: int32(code), Message: message},
+			Feature: protoFeature,
+		}
+	}
+	return results, nil
+}
+
+func (h *SmartFeatureHandler) SetFeatureDependencies(ctx context.Context, req *pb.SetFeatureDependenciesRequest) (*pb.SetFeatureDependenciesResponse, error) {
+	logger.FromContext(ctx).Debug("Setting smart feature dependencies", "request", req)
+
+	dependencies, err := h.mapper.ToDependenciesDomain(req.Dependencies)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	set, err := h.service.SetDependencies(ctx, dependencies)
+	if err != nil {
+		return nil, dependencyError(ctx, err, "failed to set smart feature dependencies")
+	}
+
+	return &pb.SetFeatureDependenciesResponse{
+		Dependencies: h.mapper.ToDependenciesProto(set),
+	}, nil
+}
+
+func (h *SmartFeatureHandler) GetFeatureDependencies(ctx context.Context, req *pb.GetFeatureDependenciesRequest) (*pb.GetFeatureDependenciesResponse, error) {
+	logger.FromContext(ctx).Debug("Getting smart feature dependencies", "request", req)
+
+	if err := validation.ValidateUUID(req.FeatureId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	dependencies, err := h.service.GetDependencies(ctx, req.FeatureId)
+	if err != nil {
+		return nil, dependencyError(ctx, err, "failed to get smart feature dependencies")
+	}
+
+	return &pb.GetFeatureDependenciesResponse{
+		Dependencies: h.mapper.ToDependenciesProto(dependencies),
+	}, nil
+}
+
+func (h *SmartFeatureHandler) ResolveFeatureSet(ctx context.Context, req *pb.ResolveFeatureSetRequest) (*pb.ResolveFeatureSetResponse, error) {
+	logger.FromContext(ctx).Debug("Resolving smart feature set", "request", req)
+
+	if err := validation.ValidateUUID(req.ModelId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resolved, err := h.service.ResolveFeatureSet(ctx, req.ModelId, req.FeatureIds)
+	if err != nil {
+		return nil, dependencyError(ctx, err, "failed to resolve smart feature set")
+	}
+
+	protoResolved, err := h.mapper.ToResolvedProto(resolved)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to convert smart feature to proto", "error", err)
+		return nil, status.Error(codes.Internal, "failed to convert smart feature to proto")
+	}
+
+	return &pb.ResolveFeatureSetResponse{Features: protoResolved}, nil
+}
+
+// dependencyError converts an error of the feature dependency methods into a
+// gRPC status.
+func dependencyError(ctx context.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidFeatureDependency):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, "not found")
+	}
+	logger.FromContext(ctx).Error(message, "error", err)
+	return status.Error(codes.Internal, message)
+}
@@ -2,9 +2,11 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	_ "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -13,6 +15,7 @@ import (
 	pb "smart-hub/gen/proto/smart_feature/v1"
 	_ "smart-hub/internal/application/service"
 	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
 	"testing"
 	"time"
 )
@@ -45,6 +48,14 @@ func (m *mockSmartFeatureService) GetWithModelID(ctx context.Context, modelID st
 	return args.Get(0).([]*models.SmartFeature), args.Error(1)
 }
 
+func (m *mockSmartFeatureService) List(ctx context.Context, filter models.FeatureFilter, pageSize int, pageToken string) ([]*models.SmartFeature, string, error) {
+	args := m.Called(ctx, filter, pageSize, pageToken)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).([]*models.SmartFeature), args.String(1), args.Error(2)
+}
+
 func (m *mockSmartFeatureService) GetAll(ctx context.Context) ([]*models.SmartFeature, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -66,6 +77,54 @@ func (m *mockSmartFeatureService) Delete(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *mockSmartFeatureService) BatchCreate(ctx context.Context, features []*models.SmartFeature, partial bool) ([]*models.SmartFeature, []error, error) {
+	args := m.Called(ctx, features, partial)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	itemErrs, _ := args.Get(1).([]error)
+	return args.Get(0).([]*models.SmartFeature), itemErrs, args.Error(2)
+}
+
+func (m *mockSmartFeatureService) BatchUpdate(ctx context.Context, features []*models.SmartFeature, partial bool) ([]*models.SmartFeature, []error, error) {
+	args := m.Called(ctx, features, partial)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	itemErrs, _ := args.Get(1).([]error)
+	return args.Get(0).([]*models.SmartFeature), itemErrs, args.Error(2)
+}
+
+func (m *mockSmartFeatureService) BatchDelete(ctx context.Context, ids []string, partial bool) ([]error, error) {
+	args := m.Called(ctx, ids, partial)
+	itemErrs, _ := args.Get(0).([]error)
+	return itemErrs, args.Error(1)
+}
+
+func (m *mockSmartFeatureService) SetDependencies(ctx context.Context, dependencies *models.FeatureDependencies) (*models.FeatureDependencies, error) {
+	args := m.Called(ctx, dependencies)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FeatureDependencies), args.Error(1)
+}
+
+func (m *mockSmartFeatureService) GetDependencies(ctx context.Context, id string) (*models.FeatureDependencies, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FeatureDependencies), args.Error(1)
+}
+
+func (m *mockSmartFeatureService) ResolveFeatureSet(ctx context.Context, modelID string, featureIDs []string) ([]*models.ResolvedFeature, error) {
+	args := m.Called(ctx, modelID, featureIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ResolvedFeature), args.Error(1)
+}
+
 type mockSmartFeatureMapper struct {
 	mock.Mock
 }
@@ -94,6 +153,32 @@ func (m *mockSmartFeatureMapper) ToDomainUpdate(req *pb.UpdateSmartFeatureReques
 	return args.Get(0).(*models.SmartFeature), args.Error(1)
 }
 
+func (m *mockSmartFeatureMapper) ToDomainInput(input *pb.CreateSmartFeatureInput) (*models.SmartFeature, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SmartFeature), args.Error(1)
+}
+
+func (m *mockSmartFeatureMapper) ToDomainUpdateInput(input *pb.UpdateSmartFeatureInput) (*models.SmartFeature, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SmartFeature), args.Error(1)
+}
+
+func (m *mockSmartFeatureMapper) ToListFilter(req *pb.ListSmartFeaturesRequest) models.FeatureFilter {
+	args := m.Called(req)
+	return args.Get(0).(models.FeatureFilter)
+}
+
+func (m *mockSmartFeatureMapper) ToWatchFilter(req *pb.WatchSmartFeaturesRequest) (models.FeatureWatchFilter, error) {
+	args := m.Called(req)
+	return args.Get(0).(models.FeatureWatchFilter), args.Error(1)
+}
+
 func (m *mockSmartFeatureMapper) ToCreateResponse(feature *models.SmartFeature) (*pb.CreateSmartFeatureResponse, error) {
 	args := m.Called(feature)
 	if args.Get(0) == nil {
@@ -126,10 +211,57 @@ func (m *mockSmartFeatureMapper) ToUpdateResponse(feature *models.SmartFeature)
 	return args.Get(0).(*pb.UpdateSmartFeatureResponse), args.Error(1)
 }
 
+func (m *mockSmartFeatureMapper) ToWatchResponse(event *models.WatchEvent) (*pb.WatchSmartFeaturesResponse, error) {
+	args := m.Called(event)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.WatchSmartFeaturesResponse), args.Error(1)
+}
+
+func (m *mockSmartFeatureMapper) ToDependenciesProto(dependencies *models.FeatureDependencies) *pb.FeatureDependencies {
+	args := m.Called(dependencies)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*pb.FeatureDependencies)
+}
+
+func (m *mockSmartFeatureMapper) ToDependenciesDomain(dependencies *pb.FeatureDependencies) (*models.FeatureDependencies, error) {
+	args := m.Called(dependencies)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FeatureDependencies), args.Error(1)
+}
+
+func (m *mockSmartFeatureMapper) ToResolvedProto(resolved []*models.ResolvedFeature) ([]*pb.ResolvedFeature, error) {
+	args := m.Called(resolved)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*pb.ResolvedFeature), args.Error(1)
+}
+
+type fakeWatchSmartFeaturesServer struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*pb.WatchSmartFeaturesResponse
+}
+
+func (s *fakeWatchSmartFeaturesServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeWatchSmartFeaturesServer) Send(resp *pb.WatchSmartFeaturesResponse) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
 func TestCreateSmartFeature_Success(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	params, _ := structpb.NewStruct(map[string]interface{}{
 		"key": "value",
@@ -184,7 +316,7 @@ func TestCreateSmartFeature_Success(t *testing.T) {
 func TestGetSmartFeature_Success(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	featureID := uuid.New()
 	req := &pb.GetSmartFeatureRequest{
@@ -222,7 +354,7 @@ func TestGetSmartFeature_Success(t *testing.T) {
 func TestGetFeaturesByModelID_Success(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	modelID := uuid.New().String()
 	req := &pb.GetFeaturesByModelIDRequest{
@@ -272,7 +404,7 @@ func TestGetFeaturesByModelID_Success(t *testing.T) {
 func TestUpdateSmartFeature_Success(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	featureID := uuid.New()
 	req := &pb.UpdateSmartFeatureRequest{
@@ -317,7 +449,7 @@ func TestUpdateSmartFeature_Success(t *testing.T) {
 func TestDeleteSmartFeature_Success(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	featureID := uuid.New()
 	req := &pb.DeleteSmartFeatureRequest{
@@ -336,7 +468,7 @@ func TestDeleteSmartFeature_Success(t *testing.T) {
 func TestCreateSmartFeature_ValidationError(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	req := &pb.CreateSmartFeatureRequest{
 		Feature: &pb.CreateSmartFeatureInput{
@@ -358,7 +490,7 @@ func TestCreateSmartFeature_ValidationError(t *testing.T) {
 func TestCreateSmartFeature_ServiceError(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	req := &pb.CreateSmartFeatureRequest{
 		Feature: &pb.CreateSmartFeatureInput{
@@ -395,7 +527,7 @@ func TestCreateSmartFeature_ServiceError(t *testing.T) {
 func TestGetSmartFeature_InvalidID(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	req := &pb.GetSmartFeatureRequest{
 		Id: "invalid-uuid",
@@ -413,7 +545,7 @@ func TestGetSmartFeature_InvalidID(t *testing.T) {
 func TestGetSmartFeature_ServiceError(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	featureID := uuid.New()
 	req := &pb.GetSmartFeatureRequest{
@@ -434,7 +566,7 @@ func TestGetSmartFeature_ServiceError(t *testing.T) {
 func TestGetFeaturesByModelID_InvalidModelID(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	req := &pb.GetFeaturesByModelIDRequest{
 		ModelId: "invalid-uuid",
@@ -452,7 +584,7 @@ func TestGetFeaturesByModelID_InvalidModelID(t *testing.T) {
 func TestGetFeaturesByModelID_ServiceError(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	modelID := uuid.New().String()
 	req := &pb.GetFeaturesByModelIDRequest{
@@ -473,7 +605,7 @@ func TestGetFeaturesByModelID_ServiceError(t *testing.T) {
 func TestUpdateSmartFeature_ValidationError(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	req := &pb.UpdateSmartFeatureRequest{
 		Feature: &pb.UpdateSmartFeatureInput{
@@ -495,7 +627,7 @@ func TestUpdateSmartFeature_ValidationError(t *testing.T) {
 func TestUpdateSmartFeature_ServiceError(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	featureID := uuid.New()
 	req := &pb.UpdateSmartFeatureRequest{
@@ -533,7 +665,7 @@ func TestUpdateSmartFeature_ServiceError(t *testing.T) {
 func TestDeleteSmartFeature_InvalidID(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	req := &pb.DeleteSmartFeatureRequest{
 		Id: "invalid-uuid",
@@ -551,7 +683,7 @@ func TestDeleteSmartFeature_InvalidID(t *testing.T) {
 func TestDeleteSmartFeature_ServiceError(t *testing.T) {
 	mockService := &mockSmartFeatureService{}
 	mockMapper := &mockSmartFeatureMapper{}
-	handler := NewSmartFeatureHandler(mockService, mockMapper)
+	handler := NewSmartFeatureHandler(mockService, nil, mockMapper)
 
 	featureID := uuid.New()
 	req := &pb.DeleteSmartFeatureRequest{
@@ -568,3 +700,444 @@ func TestDeleteSmartFeature_ServiceError(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, codes.Internal, st.Code())
 }
+
+func TestDeleteSmartFeature_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"not found", models.ErrNotFound, codes.NotFound},
+		{"required", fmt.Errorf("%w: %q is required by %q", models.ErrFeatureRequired, "infrared", "night vision"), codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockSmartFeatureService{}
+			handler := NewSmartFeatureHandler(mockService, nil, &mockSmartFeatureMapper{})
+
+			featureID := uuid.New()
+			mockService.On("Delete", mock.Anything, featureID.String()).Return(tt.err)
+
+			resp, err := handler.DeleteSmartFeature(context.Background(), &pb.DeleteSmartFeatureRequest{Id: featureID.String()})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestWatchSmartFeatures_Success(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	mockWatcher := &mockCatalogWatchService{}
+	mockMapper := &mockSmartFeatureMapper{}
+	handler := NewSmartFeatureHandler(mockService, mockWatcher, mockMapper)
+
+	modelID := uuid.New()
+	req := &pb.WatchSmartFeaturesRequest{ModelId: modelID.String(), ResumeToken: "7"}
+	event := &models.WatchEvent{Operation: models.ChangeCreated, ResumeToken: "8", Feature: &models.SmartFeature{ID: uuid.New(), ModelID: modelID}}
+	resp := &pb.WatchSmartFeaturesResponse{ChangeType: pb.ChangeType_CREATED, ResumeToken: "8"}
+
+	mockMapper.On("ToWatchFilter", req).Return(models.FeatureWatchFilter{ModelID: &modelID}, nil)
+	mockWatcher.On("WatchFeatures", mock.Anything, models.FeatureWatchFilter{ModelID: &modelID}, "7").Return([]*models.WatchEvent{event}, nil)
+	mockMapper.On("ToWatchResponse", event).Return(resp, nil)
+
+	stream := &fakeWatchSmartFeaturesServer{ctx: context.Background()}
+	err := handler.WatchSmartFeatures(req, stream)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.WatchSmartFeaturesResponse{resp}, stream.responses)
+	mockWatcher.AssertExpectations(t)
+	mockMapper.AssertExpectations(t)
+}
+
+func TestWatchSmartFeatures_ModelTypeAndCategory(t *testing.T) {
+	mockWatcher := &mockCatalogWatchService{}
+	handler := NewSmartFeatureHandler(&mockSmartFeatureService{}, mockWatcher, mapper.NewSmartFeatureMapper())
+
+	modelType := models.ServiceType
+	category := models.WeatherCategory
+	req := &pb.WatchSmartFeaturesRequest{
+		ModelType:     pb.ModelType_SERVICE.Enum(),
+		ModelCategory: pb.ModelCategory_WEATHER.Enum(),
+	}
+
+	mockWatcher.On("WatchFeatures", mock.Anything, models.FeatureWatchFilter{ModelType: &modelType, ModelCategory: &category}, "").Return([]*models.WatchEvent(nil), nil)
+
+	err := handler.WatchSmartFeatures(req, &fakeWatchSmartFeaturesServer{ctx: context.Background()})
+
+	assert.NoError(t, err)
+	mockWatcher.AssertExpectations(t)
+}
+
+func TestWatchSmartFeatures_InvalidModelID(t *testing.T) {
+	handler := NewSmartFeatureHandler(&mockSmartFeatureService{}, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	stream := &fakeWatchSmartFeaturesServer{ctx: context.Background()}
+	err := handler.WatchSmartFeatures(&pb.WatchSmartFeaturesRequest{ModelId: "invalid"}, stream)
+
+	assert.Error(t, err)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestBatchCreateSmartFeatures_Success(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	modelID := uuid.New()
+	req := &pb.BatchCreateSmartFeaturesRequest{Features: []*pb.CreateSmartFeatureInput{
+		{ModelId: modelID.String(), Name: "Power", Description: "Power", InterfacePath: "/power"},
+		{ModelId: modelID.String(), Name: "Status", Description: "Status", InterfacePath: "/status"},
+	}}
+
+	mockService.On("BatchCreate", mock.Anything, mock.MatchedBy(func(features []*models.SmartFeature) bool {
+		return len(features) == 2 && features[0].Name == "Power" && features[1].ModelID == modelID
+	}), false).Return([]*models.SmartFeature{
+		{ID: uuid.New(), ModelID: modelID, Name: "Power"},
+		{ID: uuid.New(), ModelID: modelID, Name: "Status"},
+	}, []error{nil, nil}, nil)
+
+	resp, err := handler.BatchCreateSmartFeatures(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Results, 2)
+	assert.Equal(t, int32(codes.OK), resp.Results[0].Status.Code)
+	assert.Equal(t, "Power", resp.Results[0].Feature.Name)
+	assert.Equal(t, "Status", resp.Results[1].Feature.Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestBatchCreateSmartFeatures_InvalidItem(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	req := &pb.BatchCreateSmartFeaturesRequest{Features: []*pb.CreateSmartFeatureInput{
+		{ModelId: uuid.NewString(), Name: "Power", Description: "Power", InterfacePath: "/power"},
+		{ModelId: "invalid", Name: "Status", Description: "Status", InterfacePath: "/status"},
+	}}
+
+	resp, err := handler.BatchCreateSmartFeatures(context.Background(), req)
+
+	assert.Nil(t, resp)
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Contains(t, st.Message(), "item 1")
+	mockService.AssertNotCalled(t, "BatchCreate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBatchCreateSmartFeatures_PartialSuccess(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	req := &pb.BatchCreateSmartFeaturesRequest{
+		Features: []*pb.CreateSmartFeatureInput{
+			{ModelId: "invalid", Name: "Broken", Description: "Broken", InterfacePath: "/broken"},
+			{ModelId: uuid.NewString(), Name: "Orphan", Description: "Orphan", InterfacePath: "/orphan"},
+			{ModelId: uuid.NewString(), Name: "Power", Description: "Power", InterfacePath: "/power"},
+		},
+		PartialSuccess: true,
+	}
+
+	mockService.On("BatchCreate", mock.Anything, mock.MatchedBy(func(features []*models.SmartFeature) bool {
+		return len(features) == 2 && features[0].Name == "Orphan"
+	}), true).Return([]*models.SmartFeature{nil, {ID: uuid.New(), Name: "Power"}}, []error{models.ErrNotFound, nil}, nil)
+
+	resp, err := handler.BatchCreateSmartFeatures(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Results, 3)
+	assert.Equal(t, int32(codes.InvalidArgument), resp.Results[0].Status.Code)
+	assert.Nil(t, resp.Results[0].Feature)
+	assert.Equal(t, int32(codes.NotFound), resp.Results[1].Status.Code)
+	assert.Nil(t, resp.Results[1].Feature)
+	assert.Equal(t, int32(codes.OK), resp.Results[2].Status.Code)
+	assert.Equal(t, "Power", resp.Results[2].Feature.Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestBatchCreateSmartFeatures_TooLarge(t *testing.T) {
+	handler := NewSmartFeatureHandler(&mockSmartFeatureService{}, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	req := &pb.BatchCreateSmartFeaturesRequest{Features: make([]*pb.CreateSmartFeatureInput, maxBatchSize+1)}
+	_, err := handler.BatchCreateSmartFeatures(context.Background(), req)
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestBatchUpdateSmartFeatures_NotFound(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	req := &pb.BatchUpdateSmartFeaturesRequest{Features: []*pb.UpdateSmartFeatureInput{
+		{Id: uuid.NewString(), Name: "Power", Description: "Power", InterfacePath: "/power"},
+		{Id: uuid.NewString(), Name: "Status", Description: "Status", InterfacePath: "/status"},
+	}}
+	mockService.On("BatchUpdate", mock.Anything, mock.Anything, false).
+		Return(nil, nil, &models.BatchItemError{Index: 1, Err: models.ErrNotFound})
+
+	resp, err := handler.BatchUpdateSmartFeatures(context.Background(), req)
+
+	assert.Nil(t, resp)
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "item 1: not found", st.Message())
+	mockService.AssertExpectations(t)
+}
+
+func TestBatchUpdateSmartFeatures_DuplicateID(t *testing.T) {
+	handler := NewSmartFeatureHandler(&mockSmartFeatureService{}, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	id := uuid.NewString()
+	req := &pb.BatchUpdateSmartFeaturesRequest{Features: []*pb.UpdateSmartFeatureInput{
+		{Id: id, Name: "Power", Description: "Power", InterfacePath: "/power"},
+		{Id: id, Name: "Status", Description: "Status", InterfacePath: "/status"},
+	}}
+
+	_, err := handler.BatchUpdateSmartFeatures(context.Background(), req)
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Contains(t, st.Message(), "item 1")
+}
+
+func TestBatchDeleteSmartFeatures_PartialSuccess(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	existing, missing := uuid.NewString(), uuid.NewString()
+	req := &pb.BatchDeleteSmartFeaturesRequest{Ids: []string{existing, "invalid", missing}, PartialSuccess: true}
+	mockService.On("BatchDelete", mock.Anything, []string{existing, missing}, true).Return([]error{nil, models.ErrNotFound}, nil)
+
+	resp, err := handler.BatchDeleteSmartFeatures(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Results, 3)
+	assert.Equal(t, int32(codes.OK), resp.Results[0].Code)
+	assert.Equal(t, int32(codes.InvalidArgument), resp.Results[1].Code)
+	assert.Equal(t, int32(codes.NotFound), resp.Results[2].Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestBatchDeleteSmartFeatures_ServiceError(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	ids := []string{uuid.NewString()}
+	mockService.On("BatchDelete", mock.Anything, ids, false).Return(nil, assert.AnError)
+
+	_, err := handler.BatchDeleteSmartFeatures(context.Background(), &pb.BatchDeleteSmartFeaturesRequest{Ids: ids})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.Internal, st.Code())
+	mockService.AssertExpectations(t)
+}
+
+func TestListSmartFeatures_Success(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	protocol := models.MqttProtocol
+	category := models.CameraCategory
+	expectedFilter := models.FeatureFilter{
+		Protocol:             &protocol,
+		ModelCategory:        &category,
+		NamePrefix:           "Str",
+		InterfacePathPattern: "/video/*",
+	}
+	features := []*models.SmartFeature{{ID: uuid.New(), Name: "Stream", Protocol: models.MqttProtocol}}
+	mockService.On("List", mock.Anything, expectedFilter, defaultPageSize, "token").Return(features, "next", nil)
+
+	resp, err := handler.ListSmartFeatures(context.Background(), &pb.ListSmartFeaturesRequest{
+		PageToken:            "token",
+		Protocol:             pb.ProtocolType_MQTT.Enum(),
+		ModelCategory:        pb.ModelCategory_CAMERA.Enum(),
+		NamePrefix:           "Str",
+		InterfacePathPattern: "/video/*",
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Features, 1)
+	assert.Equal(t, "Stream", resp.Features[0].Name)
+	assert.Equal(t, "next", resp.NextPageToken)
+	mockService.AssertExpectations(t)
+}
+
+func TestListSmartFeatures_PageSize(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	mockService.On("List", mock.Anything, models.FeatureFilter{}, maxPageSize, "").Return([]*models.SmartFeature{}, "", nil)
+
+	_, err := handler.ListSmartFeatures(context.Background(), &pb.ListSmartFeaturesRequest{PageSize: 5000})
+	assert.NoError(t, err)
+
+	_, err = handler.ListSmartFeatures(context.Background(), &pb.ListSmartFeaturesRequest{PageSize: -1})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	mockService.AssertExpectations(t)
+}
+
+func TestListSmartFeatures_InvalidPageToken(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	mockService.On("List", mock.Anything, models.FeatureFilter{}, defaultPageSize, "bogus").Return(nil, "", models.ErrInvalidPageToken)
+
+	_, err := handler.ListSmartFeatures(context.Background(), &pb.ListSmartFeaturesRequest{PageToken: "bogus"})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateSmartFeature_AlreadyExists(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, nil, mapper.NewSmartFeatureMapper())
+
+	conflictID := uuid.New()
+	mockService.On("Create", mock.Anything, mock.Anything).
+		Return(nil, &models.AlreadyExistsError{Entity: models.SmartFeatureAggregate, ID: conflictID})
+
+	_, err := handler.CreateSmartFeature(context.Background(), &pb.CreateSmartFeatureRequest{
+		Feature: &pb.CreateSmartFeatureInput{
+			ModelId:       uuid.NewString(),
+			Name:          "Reboot",
+			Description:   "Reboot the device",
+			InterfacePath: "/reboot",
+		},
+	})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.AlreadyExists, st.Code())
+	assert.Contains(t, st.Message(), conflictID.String())
+}
+
+func TestBatchCreateSmartFeatures_AlreadyExists(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartFeatureMapper())
+
+	conflict := &models.AlreadyExistsError{Entity: models.SmartFeatureAggregate, ID: uuid.New()}
+	mockService.On("BatchCreate", mock.Anything, mock.Anything, false).
+		Return(nil, nil, &models.BatchItemError{Index: 0, Err: conflict})
+
+	_, err := handler.BatchCreateSmartFeatures(context.Background(), &pb.BatchCreateSmartFeaturesRequest{
+		Features: []*pb.CreateSmartFeatureInput{
+			{ModelId: uuid.NewString(), Name: "Reboot", Description: "Reboot", InterfacePath: "/reboot"},
+		},
+	})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.AlreadyExists, st.Code())
+	assert.Contains(t, st.Message(), "item 0: ")
+	assert.Contains(t, st.Message(), conflict.ID.String())
+}
+
+func TestSetFeatureDependencies_Success(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, nil, mapper.NewSmartFeatureMapper())
+
+	featureID, requires, conflicts := uuid.New(), uuid.New(), uuid.New()
+	dependencies := &models.FeatureDependencies{
+		FeatureID: featureID,
+		Requires:  []uuid.UUID{requires},
+		Conflicts: []uuid.UUID{conflicts},
+	}
+	mockService.On("SetDependencies", mock.Anything, dependencies).Return(dependencies, nil)
+
+	resp, err := handler.SetFeatureDependencies(context.Background(), &pb.SetFeatureDependenciesRequest{
+		Dependencies: &pb.FeatureDependencies{
+			FeatureId: featureID.String(),
+			Requires:  []string{requires.String()},
+			Conflicts: []string{conflicts.String()},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, featureID.String(), resp.Dependencies.FeatureId)
+	assert.Equal(t, []string{requires.String()}, resp.Dependencies.Requires)
+	assert.Equal(t, []string{conflicts.String()}, resp.Dependencies.Conflicts)
+	mockService.AssertExpectations(t)
+}
+
+func TestSetFeatureDependencies_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		serviceErr error
+		request    *pb.FeatureDependencies
+		code       codes.Code
+	}{
+		{"missing dependencies", nil, nil, codes.InvalidArgument},
+		{"malformed dependency", nil, &pb.FeatureDependencies{FeatureId: uuid.NewString(), Requires: []string{"power"}}, codes.InvalidArgument},
+		{"cycle", fmt.Errorf("%w: requirements form a cycle", models.ErrInvalidFeatureDependency), &pb.FeatureDependencies{FeatureId: uuid.NewString()}, codes.InvalidArgument},
+		{"unknown feature", models.ErrNotFound, &pb.FeatureDependencies{FeatureId: uuid.NewString()}, codes.NotFound},
+		{"internal", fmt.Errorf("connection refused"), &pb.FeatureDependencies{FeatureId: uuid.NewString()}, codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockSmartFeatureService{}
+			handler := NewSmartFeatureHandler(mockService, nil, mapper.NewSmartFeatureMapper())
+			mockService.On("SetDependencies", mock.Anything, mock.Anything).Return(nil, tt.serviceErr).Maybe()
+
+			_, err := handler.SetFeatureDependencies(context.Background(), &pb.SetFeatureDependenciesRequest{Dependencies: tt.request})
+
+			st, _ := status.FromError(err)
+			assert.Equal(t, tt.code, st.Code())
+		})
+	}
+}
+
+func TestGetFeatureDependencies_InvalidID(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, nil, mapper.NewSmartFeatureMapper())
+
+	_, err := handler.GetFeatureDependencies(context.Background(), &pb.GetFeatureDependenciesRequest{FeatureId: "power"})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	mockService.AssertNotCalled(t, "GetDependencies", mock.Anything, mock.Anything)
+}
+
+func TestResolveFeatureSet_Success(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, nil, mapper.NewSmartFeatureMapper())
+
+	modelID := uuid.New()
+	power := &models.SmartFeature{ID: uuid.New(), ModelID: modelID, Name: "Power"}
+	nightVision := &models.SmartFeature{ID: uuid.New(), ModelID: modelID, Name: "Night vision"}
+	mockService.On("ResolveFeatureSet", mock.Anything, modelID.String(), []string{nightVision.ID.String()}).
+		Return([]*models.ResolvedFeature{{Feature: power}, {Feature: nightVision, Requested: true}}, nil)
+
+	resp, err := handler.ResolveFeatureSet(context.Background(), &pb.ResolveFeatureSetRequest{
+		ModelId:    modelID.String(),
+		FeatureIds: []string{nightVision.ID.String()},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Features, 2)
+	assert.Equal(t, "Power", resp.Features[0].Feature.Name)
+	assert.False(t, resp.Features[0].Requested)
+	assert.Equal(t, "Night vision", resp.Features[1].Feature.Name)
+	assert.True(t, resp.Features[1].Requested)
+	mockService.AssertExpectations(t)
+}
+
+func TestResolveFeatureSet_Conflict(t *testing.T) {
+	mockService := &mockSmartFeatureService{}
+	handler := NewSmartFeatureHandler(mockService, nil, mapper.NewSmartFeatureMapper())
+
+	mockService.On("ResolveFeatureSet", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("%w: \"Privacy mode\" conflicts with \"Infrared\"", models.ErrInvalidFeatureDependency))
+
+	_, err := handler.ResolveFeatureSet(context.Background(), &pb.ResolveFeatureSetRequest{
+		ModelId:    uuid.NewString(),
+		FeatureIds: []string{uuid.NewString()},
+	})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Contains(t, st.Message(), "conflicts with")
+}
@@ -2,33 +2,38 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	pb "smart-hub/gen/proto/smart_model/v1"
 	"smart-hub/internal/application/interfaces"
 	"smart-hub/internal/common/logger"
 	"smart-hub/internal/common/validation"
+	"smart-hub/internal/domain/models"
 	"smart-hub/internal/presentation/grpc/mapper"
 )
 
 type SmartModelHandler struct {
 	pb.UnimplementedSmartModelServiceServer
 	service interfaces.SmartModelService
+	watcher interfaces.CatalogWatchService
 	mapper  mapper.SmartModelMapper
 }
 
 func NewSmartModelHandler(
 	service interfaces.SmartModelService,
+	watcher interfaces.CatalogWatchService,
 	mapper mapper.SmartModelMapper,
 ) *SmartModelHandler {
 	return &SmartModelHandler{
 		service: service,
+		watcher: watcher,
 		mapper:  mapper,
 	}
 }
 
 func (h *SmartModelHandler) CreateSmartModel(ctx context.Context, req *pb.CreateSmartModelRequest) (*pb.CreateSmartModelResponse, error) {
-	logger.Debug("Creating smart model", "request", req)
+	logger.FromContext(ctx).Debug("Creating smart model", "request", req)
 
 	smartModel, err := h.mapper.ToDomain(req)
 	if err != nil {
@@ -41,13 +46,12 @@ func (h *SmartModelHandler) CreateSmartModel(ctx context.Context, req *pb.Create
 
 	createdModel, err := h.service.Create(ctx, smartModel)
 	if err != nil {
-		logger.Error("Failed to create smart model", "error", err)
-		return nil, status.Error(codes.Internal, "failed to create smart model")
+		return nil, catalogError(ctx, err, "failed to create smart model")
 	}
 
 	protoModel, err := h.mapper.ToProto(createdModel)
 	if err != nil {
-		logger.Error("Failed to convert smart model to proto", "error", err)
+		logger.FromContext(ctx).Error("Failed to convert smart model to proto", "error", err)
 		return nil, status.Error(codes.Internal, "failed to convert smart model to proto")
 	}
 
@@ -57,7 +61,7 @@ func (h *SmartModelHandler) CreateSmartModel(ctx context.Context, req *pb.Create
 }
 
 func (h *SmartModelHandler) GetSmartModel(ctx context.Context, req *pb.GetSmartModelRequest) (*pb.GetSmartModelResponse, error) {
-	logger.Debug("Getting smart model", "request", req)
+	logger.FromContext(ctx).Debug("Getting smart model", "request", req)
 
 	err := validation.ValidateUUID(req.Id)
 	if err != nil {
@@ -66,13 +70,19 @@ func (h *SmartModelHandler) GetSmartModel(ctx context.Context, req *pb.GetSmartM
 
 	smartModel, err := h.service.GetByID(ctx, req.Id)
 	if err != nil {
-		logger.Error("Failed to get smart model", "error", err)
-		return nil, status.Error(codes.Internal, "failed to get smart model")
+		return nil, catalogError(ctx, err, "failed to get smart model")
+	}
+
+	if req.View == pb.SmartModelView_FULL {
+		if err := h.service.ExpandFeatures(ctx, []*models.SmartModel{smartModel}); err != nil {
+			logger.FromContext(ctx).Error("Failed to load smart model features", "error", err)
+			return nil, status.Error(codes.Internal, "failed to load smart model features")
+		}
 	}
 
 	protoModel, err := h.mapper.ToProto(smartModel)
 	if err != nil {
-		logger.Error("Failed to convert smart model to proto", "error", err)
+		logger.FromContext(ctx).Error("Failed to convert smart model to proto", "error", err)
 		return nil, status.Error(codes.Internal, "failed to convert smart model to proto")
 	}
 
@@ -81,18 +91,59 @@ func (h *SmartModelHandler) GetSmartModel(ctx context.Context, req *pb.GetSmartM
 	}, nil
 }
 
+func (h *SmartModelHandler) GetSmartModelByModelNumber(ctx context.Context, req *pb.GetSmartModelByModelNumberRequest) (*pb.GetSmartModelByModelNumberResponse, error) {
+	logger.FromContext(ctx).Debug("Getting smart model by model number", "request", req)
+
+	if req.Manufacturer == "" || req.ModelNumber == "" {
+		return nil, status.Error(codes.InvalidArgument, "manufacturer and model_number are required")
+	}
+
+	smartModel, err := h.service.GetByModelNumber(ctx, req.Manufacturer, req.ModelNumber)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "no smart model %s %s", req.Manufacturer, req.ModelNumber)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get smart model by model number", "error", err)
+		return nil, status.Error(codes.Internal, "failed to get smart model")
+	}
+
+	if req.View == pb.SmartModelView_FULL {
+		if err := h.service.ExpandFeatures(ctx, []*models.SmartModel{smartModel}); err != nil {
+			logger.FromContext(ctx).Error("Failed to load smart model features", "error", err)
+			return nil, status.Error(codes.Internal, "failed to load smart model features")
+		}
+	}
+
+	protoModel, err := h.mapper.ToProto(smartModel)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to convert smart model to proto", "error", err)
+		return nil, status.Error(codes.Internal, "failed to convert smart model to proto")
+	}
+
+	return &pb.GetSmartModelByModelNumberResponse{
+		Model: protoModel,
+	}, nil
+}
+
 func (h *SmartModelHandler) ListSmartModels(ctx context.Context, req *pb.ListSmartModelsRequest) (*pb.ListSmartModelsResponse, error) {
-	logger.Debug("Listing smart models", "request", req)
+	logger.FromContext(ctx).Debug("Listing smart models", "request", req)
 
-	models, err := h.service.GetAll(ctx)
+	smartModels, err := h.service.GetAll(ctx)
 	if err != nil {
-		logger.Error("Failed to list smart models", "error", err)
+		logger.FromContext(ctx).Error("Failed to list smart models", "error", err)
 		return nil, status.Error(codes.Internal, "failed to list smart models")
 	}
 
-	protoModels, err := h.mapper.ToProtoList(models)
+	if req.View == pb.SmartModelView_FULL {
+		if err := h.service.ExpandFeatures(ctx, smartModels); err != nil {
+			logger.FromContext(ctx).Error("Failed to load smart model features", "error", err)
+			return nil, status.Error(codes.Internal, "failed to load smart model features")
+		}
+	}
+
+	protoModels, err := h.mapper.ToProtoList(smartModels)
 	if err != nil {
-		logger.Error("Failed to convert smart models to proto", "error", err)
+		logger.FromContext(ctx).Error("Failed to convert smart models to proto", "error", err)
 		return nil, status.Error(codes.Internal, "failed to convert smart models to proto")
 	}
 
@@ -102,7 +153,7 @@ func (h *SmartModelHandler) ListSmartModels(ctx context.Context, req *pb.ListSma
 }
 
 func (h *SmartModelHandler) UpdateSmartModel(ctx context.Context, req *pb.UpdateSmartModelRequest) (*pb.UpdateSmartModelResponse, error) {
-	logger.Debug("Updating smart model", "request", req)
+	logger.FromContext(ctx).Debug("Updating smart model", "request", req)
 
 	smartModel, err := h.mapper.ToDomainUpdate(req)
 	if err != nil {
@@ -116,13 +167,12 @@ func (h *SmartModelHandler) UpdateSmartModel(ctx context.Context, req *pb.Update
 
 	updatedModel, err := h.service.Update(ctx, smartModel)
 	if err != nil {
-		logger.Error("Failed to update smart model", "error", err)
-		return nil, status.Error(codes.Internal, "failed to update smart model")
+		return nil, catalogError(ctx, err, "failed to update smart model")
 	}
 
 	protoModel, err := h.mapper.ToProto(updatedModel)
 	if err != nil {
-		logger.Error("Failed to convert smart model to proto", "error", err)
+		logger.FromContext(ctx).Error("Failed to convert smart model to proto", "error", err)
 		return nil, status.Error(codes.Internal, "failed to convert smart model to proto")
 	}
 
@@ -132,7 +182,7 @@ func (h *SmartModelHandler) UpdateSmartModel(ctx context.Context, req *pb.Update
 }
 
 func (h *SmartModelHandler) DeleteSmartModel(ctx context.Context, req *pb.DeleteSmartModelRequest) (*pb.DeleteSmartModelResponse, error) {
-	logger.Debug("Deleting smart model", "request", req)
+	logger.FromContext(ctx).Debug("Deleting smart model", "request", req)
 
 	err := validation.ValidateUUID(req.Id)
 	if err != nil {
@@ -141,9 +191,65 @@ func (h *SmartModelHandler) DeleteSmartModel(ctx context.Context, req *pb.Delete
 
 	err = h.service.Delete(ctx, req.Id)
 	if err != nil {
-		logger.Error("Failed to delete smart model", "error", err)
-		return nil, status.Error(codes.Internal, "failed to delete smart model")
+		return nil, catalogError(ctx, err, "failed to delete smart model")
 	}
 
 	return &pb.DeleteSmartModelResponse{}, nil
 }
+
+func (h *SmartModelHandler) WatchSmartModels(req *pb.WatchSmartModelsRequest, stream pb.SmartModelService_WatchSmartModelsServer) error {
+	ctx := stream.Context()
+	logger.FromContext(ctx).Debug("Watching smart models", "request", req)
+
+	err := h.watcher.WatchModels(ctx, h.mapper.ToWatchFilter(req), req.ResumeToken, func(event *models.WatchEvent) error {
+		resp, err := h.mapper.ToWatchResponse(event)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to convert smart model to proto", "error", err)
+			return status.Error(codes.Internal, "failed to convert smart model to proto")
+		}
+		return stream.Send(resp)
+	})
+	return watchError(ctx, err)
+}
+
+func (h *SmartModelHandler) BatchGetSmartModels(ctx context.Context, req *pb.BatchGetSmartModelsRequest) (*pb.BatchGetSmartModelsResponse, error) {
+	logger.FromContext(ctx).Debug("Batch getting smart models", "count", len(req.Ids), "partial", req.PartialSuccess)
+
+	if err := validateBatchSize(len(req.Ids)); err != nil {
+		return nil, err
+	}
+
+	v := newBatchValidation(len(req.Ids), req.PartialSuccess)
+	ids := make([]string, 0, len(req.Ids))
+	for i, id := range req.Ids {
+		err := validation.ValidateUUID(id)
+		if err := v.check(i, err); err != nil {
+			return nil, err
+		}
+		if err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	found, itemErrs, err := h.service.BatchGet(ctx, ids, req.PartialSuccess)
+	if err != nil {
+		return nil, v.fail(ctx, err, "failed to get smart models")
+	}
+
+	smartModels := scatter(v, found)
+	results := make([]*pb.BatchGetSmartModelResult, len(req.Ids))
+	for i, itemErr := range v.merge(itemErrs) {
+		code, message := itemStatus(ctx, itemErr)
+		protoModel, err := h.mapper.ToProto(smartModels[i])
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to convert smart model to proto", "error", err)
+			return nil, status.Error(codes.Internal, "failed to convert smart model to proto")
+		}
+		results[i] = &pb.BatchGetSmartModelResult{
+			Status: &pb.BatchItemStatus{Code: int32(code), Message: message},
+			Model:  protoModel,
+		}
+	}
+
+	return &pb.BatchGetSmartModelsResponse{Results: results}, nil
+}
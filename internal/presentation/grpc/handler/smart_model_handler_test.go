@@ -2,13 +2,18 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	pb "smart-hub/gen/proto/smart_model/v1"
 	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
 	"testing"
 	"time"
 )
@@ -33,6 +38,14 @@ func (m *mockSmartModelService) GetByID(ctx context.Context, id string) (*models
 	return args.Get(0).(*models.SmartModel), args.Error(1)
 }
 
+func (m *mockSmartModelService) GetByModelNumber(ctx context.Context, manufacturer, modelNumber string) (*models.SmartModel, error) {
+	args := m.Called(ctx, manufacturer, modelNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SmartModel), args.Error(1)
+}
+
 func (m *mockSmartModelService) GetAll(ctx context.Context) ([]*models.SmartModel, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -54,6 +67,20 @@ func (m *mockSmartModelService) Delete(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *mockSmartModelService) ExpandFeatures(ctx context.Context, smartModels []*models.SmartModel) error {
+	args := m.Called(ctx, smartModels)
+	return args.Error(0)
+}
+
+func (m *mockSmartModelService) BatchGet(ctx context.Context, ids []string, partial bool) ([]*models.SmartModel, []error, error) {
+	args := m.Called(ctx, ids, partial)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	itemErrs, _ := args.Get(1).([]error)
+	return args.Get(0).([]*models.SmartModel), itemErrs, args.Error(2)
+}
+
 type mockSmartModelMapper struct {
 	mock.Mock
 }
@@ -122,10 +149,65 @@ func (m *mockSmartModelMapper) ToUpdateResponse(model *models.SmartModel) (*pb.U
 	return args.Get(0).(*pb.UpdateSmartModelResponse), args.Error(1)
 }
 
+func (m *mockSmartModelMapper) ToWatchFilter(req *pb.WatchSmartModelsRequest) models.ModelWatchFilter {
+	args := m.Called(req)
+	return args.Get(0).(models.ModelWatchFilter)
+}
+
+func (m *mockSmartModelMapper) ToWatchResponse(event *models.WatchEvent) (*pb.WatchSmartModelsResponse, error) {
+	args := m.Called(event)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.WatchSmartModelsResponse), args.Error(1)
+}
+
+// mockCatalogWatchService sends the events given as the first return value
+// of the expectation before returning its error.
+type mockCatalogWatchService struct {
+	mock.Mock
+}
+
+func (m *mockCatalogWatchService) WatchModels(ctx context.Context, filter models.ModelWatchFilter, resumeToken string, send func(*models.WatchEvent) error) error {
+	args := m.Called(ctx, filter, resumeToken)
+	return replayWatchEvents(args, send)
+}
+
+func (m *mockCatalogWatchService) WatchFeatures(ctx context.Context, filter models.FeatureWatchFilter, resumeToken string, send func(*models.WatchEvent) error) error {
+	args := m.Called(ctx, filter, resumeToken)
+	return replayWatchEvents(args, send)
+}
+
+func replayWatchEvents(args mock.Arguments, send func(*models.WatchEvent) error) error {
+	if events, ok := args.Get(0).([]*models.WatchEvent); ok {
+		for _, event := range events {
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+type fakeWatchSmartModelsServer struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*pb.WatchSmartModelsResponse
+}
+
+func (s *fakeWatchSmartModelsServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeWatchSmartModelsServer) Send(resp *pb.WatchSmartModelsResponse) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
 func TestCreateSmartModel_Success(t *testing.T) {
 	mockService := &mockSmartModelService{}
 	mockMapper := &mockSmartModelMapper{}
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	req := &pb.CreateSmartModelRequest{
 		Model: &pb.CreateSmartModelInput{
@@ -176,7 +258,7 @@ func TestCreateSmartModel_Success(t *testing.T) {
 func TestGetSmartModel_Success(t *testing.T) {
 	mockService := &mockSmartModelService{}
 	mockMapper := &mockSmartModelMapper{}
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	modelID := uuid.New()
 	req := &pb.GetSmartModelRequest{
@@ -214,7 +296,7 @@ func TestGetSmartModel_Success(t *testing.T) {
 func TestListSmartModels_Success(t *testing.T) {
 	mockService := &mockSmartModelService{}
 	mockMapper := &mockSmartModelMapper{}
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	req := &pb.ListSmartModelsRequest{}
 
@@ -259,7 +341,7 @@ func TestListSmartModels_Success(t *testing.T) {
 func TestUpdateSmartModel_Success(t *testing.T) {
 	mockService := &mockSmartModelService{}
 	mockMapper := &mockSmartModelMapper{}
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	modelID := uuid.New()
 	req := &pb.UpdateSmartModelRequest{
@@ -304,7 +386,7 @@ func TestUpdateSmartModel_Success(t *testing.T) {
 func TestDeleteSmartModel_Success(t *testing.T) {
 	mockService := &mockSmartModelService{}
 	mockMapper := &mockSmartModelMapper{}
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	modelID := uuid.New()
 	req := &pb.DeleteSmartModelRequest{
@@ -323,7 +405,7 @@ func TestDeleteSmartModel_Success(t *testing.T) {
 func TestCreateSmartModel_ValidationError(t *testing.T) {
 	mockService := new(mockSmartModelService)
 	mockMapper := new(mockSmartModelMapper)
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	req := &pb.CreateSmartModelRequest{
 		Model: &pb.CreateSmartModelInput{
@@ -349,7 +431,7 @@ func TestCreateSmartModel_ValidationError(t *testing.T) {
 func TestCreateSmartModel_ServiceError(t *testing.T) {
 	mockService := new(mockSmartModelService)
 	mockMapper := new(mockSmartModelMapper)
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	req := &pb.CreateSmartModelRequest{
 		Model: &pb.CreateSmartModelInput{
@@ -386,7 +468,7 @@ func TestCreateSmartModel_ServiceError(t *testing.T) {
 func TestGetSmartModel_InvalidID(t *testing.T) {
 	mockService := new(mockSmartModelService)
 	mockMapper := new(mockSmartModelMapper)
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	req := &pb.GetSmartModelRequest{
 		Id: "invalid-uuid",
@@ -404,7 +486,7 @@ func TestGetSmartModel_InvalidID(t *testing.T) {
 func TestGetSmartModel_ServiceError(t *testing.T) {
 	mockService := new(mockSmartModelService)
 	mockMapper := new(mockSmartModelMapper)
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	modelID := uuid.New()
 	req := &pb.GetSmartModelRequest{
@@ -422,10 +504,24 @@ func TestGetSmartModel_ServiceError(t *testing.T) {
 	assert.Equal(t, codes.Internal, st.Code())
 }
 
+func TestGetSmartModel_NotFound(t *testing.T) {
+	mockService := new(mockSmartModelService)
+	mockMapper := new(mockSmartModelMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
+
+	modelID := uuid.New()
+	mockService.On("GetByID", mock.Anything, modelID.String()).Return(nil, fmt.Errorf("smart model %s: %w", modelID, models.ErrNotFound))
+
+	resp, err := handler.GetSmartModel(context.Background(), &pb.GetSmartModelRequest{Id: modelID.String()})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
 func TestListSmartModels_ServiceError(t *testing.T) {
 	mockService := new(mockSmartModelService)
 	mockMapper := new(mockSmartModelMapper)
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	mockService.On("GetAll", mock.Anything).Return(nil, assert.AnError)
 
@@ -441,7 +537,7 @@ func TestListSmartModels_ServiceError(t *testing.T) {
 func TestUpdateSmartModel_ValidationError(t *testing.T) {
 	mockService := new(mockSmartModelService)
 	mockMapper := new(mockSmartModelMapper)
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	req := &pb.UpdateSmartModelRequest{
 		Model: &pb.UpdateSmartModelInput{
@@ -463,7 +559,7 @@ func TestUpdateSmartModel_ValidationError(t *testing.T) {
 func TestUpdateSmartModel_ServiceError(t *testing.T) {
 	mockService := new(mockSmartModelService)
 	mockMapper := new(mockSmartModelMapper)
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	modelID := uuid.New()
 	req := &pb.UpdateSmartModelRequest{
@@ -499,7 +595,7 @@ func TestUpdateSmartModel_ServiceError(t *testing.T) {
 func TestDeleteSmartModel_InvalidID(t *testing.T) {
 	mockService := new(mockSmartModelService)
 	mockMapper := new(mockSmartModelMapper)
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	req := &pb.DeleteSmartModelRequest{
 		Id: "invalid-uuid",
@@ -517,7 +613,7 @@ func TestDeleteSmartModel_InvalidID(t *testing.T) {
 func TestDeleteSmartModel_ServiceError(t *testing.T) {
 	mockService := new(mockSmartModelService)
 	mockMapper := new(mockSmartModelMapper)
-	handler := NewSmartModelHandler(mockService, mockMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
 
 	modelID := uuid.New()
 	req := &pb.DeleteSmartModelRequest{
@@ -534,3 +630,336 @@ func TestDeleteSmartModel_ServiceError(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, codes.Internal, st.Code())
 }
+
+func TestDeleteSmartModel_NotFound(t *testing.T) {
+	mockService := new(mockSmartModelService)
+	mockMapper := new(mockSmartModelMapper)
+	handler := NewSmartModelHandler(mockService, nil, mockMapper)
+
+	modelID := uuid.New()
+	mockService.On("Delete", mock.Anything, modelID.String()).Return(models.ErrNotFound)
+
+	resp, err := handler.DeleteSmartModel(context.Background(), &pb.DeleteSmartModelRequest{Id: modelID.String()})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestWatchSmartModels_Success(t *testing.T) {
+	mockService := new(mockSmartModelService)
+	mockWatcher := new(mockCatalogWatchService)
+	mockMapper := new(mockSmartModelMapper)
+	handler := NewSmartModelHandler(mockService, mockWatcher, mockMapper)
+
+	modelType := pb.ModelType_SERVICE
+	req := &pb.WatchSmartModelsRequest{Type: &modelType}
+	domainType := models.ServiceType
+	filter := models.ModelWatchFilter{Type: &domainType}
+
+	existing := &models.WatchEvent{Operation: models.ChangeExisting, ResumeToken: "3", Model: &models.SmartModel{ID: uuid.New()}}
+	synced := &models.WatchEvent{Operation: models.ChangeSynced, ResumeToken: "3"}
+	existingResp := &pb.WatchSmartModelsResponse{ChangeType: pb.ChangeType_EXISTING, ResumeToken: "3"}
+	syncedResp := &pb.WatchSmartModelsResponse{ChangeType: pb.ChangeType_SYNCED, ResumeToken: "3"}
+
+	mockMapper.On("ToWatchFilter", req).Return(filter)
+	mockWatcher.On("WatchModels", mock.Anything, filter, "").Return([]*models.WatchEvent{existing, synced}, nil)
+	mockMapper.On("ToWatchResponse", existing).Return(existingResp, nil)
+	mockMapper.On("ToWatchResponse", synced).Return(syncedResp, nil)
+
+	stream := &fakeWatchSmartModelsServer{ctx: context.Background()}
+	err := handler.WatchSmartModels(req, stream)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.WatchSmartModelsResponse{existingResp, syncedResp}, stream.responses)
+	mockWatcher.AssertExpectations(t)
+	mockMapper.AssertExpectations(t)
+}
+
+func TestWatchSmartModels_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"invalid token", models.ErrInvalidResumeToken, codes.InvalidArgument},
+		{"expired token", models.ErrResumeTokenExpired, codes.OutOfRange},
+		{"slow consumer", models.ErrSlowConsumer, codes.ResourceExhausted},
+		{"shutdown", models.ErrWatchStopped, codes.Unavailable},
+		{"database error", errors.New("db error"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWatcher := new(mockCatalogWatchService)
+			mockMapper := new(mockSmartModelMapper)
+			handler := NewSmartModelHandler(new(mockSmartModelService), mockWatcher, mockMapper)
+
+			req := &pb.WatchSmartModelsRequest{ResumeToken: "1"}
+			mockMapper.On("ToWatchFilter", req).Return(models.ModelWatchFilter{})
+			mockWatcher.On("WatchModels", mock.Anything, models.ModelWatchFilter{}, "1").Return(nil, tt.err)
+
+			err := handler.WatchSmartModels(req, &fakeWatchSmartModelsServer{ctx: context.Background()})
+
+			st, ok := status.FromError(err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.code, st.Code())
+		})
+	}
+}
+
+func TestBatchGetSmartModels_Success(t *testing.T) {
+	mockService := &mockSmartModelService{}
+	handler := NewSmartModelHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartModelMapper())
+
+	first := &models.SmartModel{ID: uuid.New(), Name: "First", Type: models.DeviceType, Category: models.WearableCategory}
+	second := &models.SmartModel{ID: uuid.New(), Name: "Second", Type: models.ServiceType, Category: models.WeatherCategory}
+	ids := []string{first.ID.String(), second.ID.String()}
+	mockService.On("BatchGet", mock.Anything, ids, false).Return([]*models.SmartModel{first, second}, []error{nil, nil}, nil)
+
+	resp, err := handler.BatchGetSmartModels(context.Background(), &pb.BatchGetSmartModelsRequest{Ids: ids})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Results, 2)
+	assert.Equal(t, "First", resp.Results[0].Model.Name)
+	assert.Equal(t, "Second", resp.Results[1].Model.Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestBatchGetSmartModels_NotFound(t *testing.T) {
+	mockService := &mockSmartModelService{}
+	handler := NewSmartModelHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartModelMapper())
+
+	ids := []string{uuid.NewString(), uuid.NewString()}
+	mockService.On("BatchGet", mock.Anything, ids, false).Return(nil, nil, &models.BatchItemError{Index: 1, Err: models.ErrNotFound})
+
+	_, err := handler.BatchGetSmartModels(context.Background(), &pb.BatchGetSmartModelsRequest{Ids: ids})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "item 1: not found", st.Message())
+	mockService.AssertExpectations(t)
+}
+
+func TestBatchGetSmartModels_PartialSuccess(t *testing.T) {
+	mockService := &mockSmartModelService{}
+	handler := NewSmartModelHandler(mockService, &mockCatalogWatchService{}, mapper.NewSmartModelMapper())
+
+	model := &models.SmartModel{ID: uuid.New(), Name: "First"}
+	missing := uuid.NewString()
+	req := &pb.BatchGetSmartModelsRequest{Ids: []string{"invalid", missing, model.ID.String()}, PartialSuccess: true}
+	mockService.On("BatchGet", mock.Anything, []string{missing, model.ID.String()}, true).
+		Return([]*models.SmartModel{nil, model}, []error{models.ErrNotFound, nil}, nil)
+
+	resp, err := handler.BatchGetSmartModels(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Results, 3)
+	assert.Equal(t, int32(codes.InvalidArgument), resp.Results[0].Status.Code)
+	assert.Equal(t, int32(codes.NotFound), resp.Results[1].Status.Code)
+	assert.Nil(t, resp.Results[1].Model)
+	assert.Equal(t, int32(codes.OK), resp.Results[2].Status.Code)
+	assert.Equal(t, "First", resp.Results[2].Model.Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateSmartModel_WithFeatures(t *testing.T) {
+	mockService := &mockSmartModelService{}
+	handler := NewSmartModelHandler(mockService, nil, mapper.NewSmartModelMapper())
+
+	req := &pb.CreateSmartModelRequest{
+		Model: &pb.CreateSmartModelInput{
+			Name:        "Test Model",
+			Description: "Test Description",
+			Type:        pb.ModelType_DEVICE,
+			Category:    pb.ModelCategory_CAMERA,
+			Features: []*pb.CreateSmartFeatureInput{
+				{Name: "Stream", Description: "Video stream", Protocol: pb.ProtocolType_WEBSOCKET, InterfacePath: "/stream"},
+			},
+		},
+	}
+
+	created := &models.SmartModel{ID: uuid.New(), Name: "Test Model"}
+	created.Features = []*models.SmartFeature{
+		{ID: uuid.New(), ModelID: created.ID, Name: "Stream", Protocol: models.WebsocketProtocol, InterfacePath: "/stream"},
+	}
+
+	mockService.On("Create", mock.Anything, mock.MatchedBy(func(model *models.SmartModel) bool {
+		return len(model.Features) == 1 && model.Features[0].ModelID == model.ID &&
+			model.Features[0].Protocol == models.WebsocketProtocol
+	})).Return(created, nil)
+
+	resp, err := handler.CreateSmartModel(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Model.Features, 1)
+	assert.Equal(t, created.ID.String(), resp.Model.Features[0].ModelId)
+	assert.Equal(t, pb.ProtocolType_WEBSOCKET, resp.Model.Features[0].Protocol)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateSmartModel_InvalidFeature(t *testing.T) {
+	mockService := &mockSmartModelService{}
+	handler := NewSmartModelHandler(mockService, nil, mapper.NewSmartModelMapper())
+
+	req := &pb.CreateSmartModelRequest{
+		Model: &pb.CreateSmartModelInput{
+			Name:        "Test Model",
+			Description: "Test Description",
+			Features: []*pb.CreateSmartFeatureInput{
+				{Name: "Stream", Description: "Video stream", InterfacePath: "no-leading-slash"},
+			},
+		},
+	}
+
+	_, err := handler.CreateSmartModel(context.Background(), req)
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestGetSmartModel_FullView(t *testing.T) {
+	mockService := &mockSmartModelService{}
+	handler := NewSmartModelHandler(mockService, nil, mapper.NewSmartModelMapper())
+
+	model := &models.SmartModel{ID: uuid.New(), Name: "Camera"}
+	feature := &models.SmartFeature{ID: uuid.New(), ModelID: model.ID, Name: "Stream"}
+
+	mockService.On("GetByID", mock.Anything, model.ID.String()).Return(model, nil)
+	mockService.On("ExpandFeatures", mock.Anything, []*models.SmartModel{model}).
+		Run(func(args mock.Arguments) {
+			args.Get(1).([]*models.SmartModel)[0].Features = []*models.SmartFeature{feature}
+		}).
+		Return(nil)
+
+	resp, err := handler.GetSmartModel(context.Background(), &pb.GetSmartModelRequest{Id: model.ID.String(), View: pb.SmartModelView_FULL})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Model.Features, 1)
+	assert.Equal(t, "Stream", resp.Model.Features[0].Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetSmartModel_BasicViewSkipsFeatures(t *testing.T) {
+	mockService := &mockSmartModelService{}
+	handler := NewSmartModelHandler(mockService, nil, mapper.NewSmartModelMapper())
+
+	model := &models.SmartModel{ID: uuid.New(), Name: "Camera"}
+	mockService.On("GetByID", mock.Anything, model.ID.String()).Return(model, nil)
+
+	resp, err := handler.GetSmartModel(context.Background(), &pb.GetSmartModelRequest{Id: model.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Model.Features)
+	mockService.AssertNotCalled(t, "ExpandFeatures", mock.Anything, mock.Anything)
+}
+
+func TestListSmartModels_FullView(t *testing.T) {
+	mockService := &mockSmartModelService{}
+	handler := NewSmartModelHandler(mockService, nil, mapper.NewSmartModelMapper())
+
+	smartModels := []*models.SmartModel{{ID: uuid.New(), Name: "Camera"}, {ID: uuid.New(), Name: "Watch"}}
+	mockService.On("GetAll", mock.Anything).Return(smartModels, nil)
+	mockService.On("ExpandFeatures", mock.Anything, smartModels).Return(assert.AnError)
+
+	_, err := handler.ListSmartModels(context.Background(), &pb.ListSmartModelsRequest{View: pb.SmartModelView_FULL})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.Internal, st.Code())
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateSmartModel_AlreadyExists(t *testing.T) {
+	mockService := new(mockSmartModelService)
+	handler := NewSmartModelHandler(mockService, nil, mapper.NewSmartModelMapper())
+
+	conflictID := uuid.New()
+	mockService.On("Create", mock.Anything, mock.Anything).
+		Return(nil, &models.AlreadyExistsError{Entity: models.SmartModelAggregate, ID: conflictID})
+
+	_, err := handler.CreateSmartModel(context.Background(), &pb.CreateSmartModelRequest{
+		Model: &pb.CreateSmartModelInput{
+			Name:         "Thermostat",
+			Description:  "Second copy",
+			Manufacturer: "acme",
+			ModelNumber:  "th200",
+		},
+	})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.AlreadyExists, st.Code())
+	assert.Contains(t, st.Message(), conflictID.String())
+	if assert.Len(t, st.Details(), 1) {
+		info := st.Details()[0].(*errdetails.ResourceInfo)
+		assert.Equal(t, "smart_model", info.ResourceType)
+		assert.Equal(t, conflictID.String(), info.ResourceName)
+	}
+}
+
+func TestUpdateSmartModel_AlreadyExistsWithoutID(t *testing.T) {
+	mockService := new(mockSmartModelService)
+	handler := NewSmartModelHandler(mockService, nil, mapper.NewSmartModelMapper())
+
+	mockService.On("Update", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("%w: Key (lower(manufacturer), lower(model_number)) already exists", models.ErrAlreadyExists))
+
+	_, err := handler.UpdateSmartModel(context.Background(), &pb.UpdateSmartModelRequest{
+		Model: &pb.UpdateSmartModelInput{
+			Id:           uuid.NewString(),
+			Name:         "Thermostat",
+			Description:  "Renamed",
+			Manufacturer: "Acme",
+			ModelNumber:  "TH200",
+		},
+	})
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.AlreadyExists, st.Code())
+	assert.Empty(t, st.Details())
+}
+
+func TestGetSmartModelByModelNumber_Success(t *testing.T) {
+	mockService := new(mockSmartModelService)
+	handler := NewSmartModelHandler(mockService, nil, mapper.NewSmartModelMapper())
+
+	model := &models.SmartModel{ID: uuid.New(), Name: "Thermostat", Manufacturer: "Acme", ModelNumber: "TH200"}
+	mockService.On("GetByModelNumber", mock.Anything, "acme", "th200").Return(model, nil)
+	mockService.On("ExpandFeatures", mock.Anything, []*models.SmartModel{model}).Return(nil)
+
+	resp, err := handler.GetSmartModelByModelNumber(context.Background(), &pb.GetSmartModelByModelNumberRequest{
+		Manufacturer: "acme",
+		ModelNumber:  "th200",
+		View:         pb.SmartModelView_FULL,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.ID.String(), resp.Model.Id)
+	assert.Equal(t, "TH200", resp.Model.ModelNumber)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetSmartModelByModelNumber_Errors(t *testing.T) {
+	mockService := new(mockSmartModelService)
+	handler := NewSmartModelHandler(mockService, nil, mapper.NewSmartModelMapper())
+
+	mockService.On("GetByModelNumber", mock.Anything, "Acme", "MISSING").Return(nil, models.ErrNotFound)
+	mockService.On("GetByModelNumber", mock.Anything, "Acme", "BROKEN").Return(nil, assert.AnError)
+
+	tests := []struct {
+		name string
+		req  *pb.GetSmartModelByModelNumberRequest
+		code codes.Code
+	}{
+		{"missing manufacturer", &pb.GetSmartModelByModelNumberRequest{ModelNumber: "TH200"}, codes.InvalidArgument},
+		{"missing model number", &pb.GetSmartModelByModelNumberRequest{Manufacturer: "Acme"}, codes.InvalidArgument},
+		{"not found", &pb.GetSmartModelByModelNumberRequest{Manufacturer: "Acme", ModelNumber: "MISSING"}, codes.NotFound},
+		{"service error", &pb.GetSmartModelByModelNumberRequest{Manufacturer: "Acme", ModelNumber: "BROKEN"}, codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.GetSmartModelByModelNumber(context.Background(), tt.req)
+			st, _ := status.FromError(err)
+			assert.Equal(t, tt.code, st.Code())
+		})
+	}
+}
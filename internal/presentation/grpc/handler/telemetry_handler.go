@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	pb "smart-hub/gen/proto/telemetry/v1"
+	"smart-hub/internal/application/interfaces"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+)
+
+type TelemetryHandler struct {
+	pb.UnimplementedTelemetryServiceServer
+	service interfaces.TelemetryService
+	mapper  mapper.TelemetryMapper
+}
+
+func NewTelemetryHandler(
+	service interfaces.TelemetryService,
+	mapper mapper.TelemetryMapper,
+) *TelemetryHandler {
+	return &TelemetryHandler{
+		service: service,
+		mapper:  mapper,
+	}
+}
+
+// IngestReadings hands the messages to the service as they arrive rather
+// than collecting the whole stream first.
+func (h *TelemetryHandler) IngestReadings(stream pb.TelemetryService_IngestReadingsServer) error {
+	ctx := stream.Context()
+	logger.FromContext(ctx).Debug("Ingesting readings")
+
+	var streamErr error
+	report, err := h.service.Ingest(ctx, func() ([]*models.TelemetryReading, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			streamErr = err
+			return nil, err
+		}
+		return h.mapper.ToDomainReadings(req.Readings), nil
+	})
+	if err != nil {
+		if streamErr != nil && errors.Is(err, streamErr) {
+			return err
+		}
+		logger.FromContext(ctx).Error("Failed to ingest readings", "error", err)
+		return status.Error(codes.Internal, "failed to ingest readings")
+	}
+
+	return stream.SendAndClose(h.mapper.ToIngestResponse(report))
+}
+
+func (h *TelemetryHandler) QueryReadings(ctx context.Context, req *pb.QueryReadingsRequest) (*pb.QueryReadingsResponse, error) {
+	logger.FromContext(ctx).Debug("Querying readings", "request", req)
+
+	query, bucket, err := h.mapper.ToDomainQuery(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request: feature_id must be a UUID")
+	}
+
+	series, err := h.service.Query(ctx, query, bucket)
+	if err != nil {
+		return nil, telemetryError(ctx, err, "failed to query readings")
+	}
+
+	return h.mapper.ToQueryResponse(series), nil
+}
+
+func (h *TelemetryHandler) SetRetentionPolicy(ctx context.Context, req *pb.SetRetentionPolicyRequest) (*pb.SetRetentionPolicyResponse, error) {
+	logger.FromContext(ctx).Debug("Setting retention policy", "request", req)
+
+	featureID, err := uuid.Parse(req.FeatureId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.Retention == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request: retention is required")
+	}
+
+	policy, err := h.service.SetRetentionPolicy(ctx, featureID, req.Retention.AsDuration())
+	if err != nil {
+		return nil, telemetryError(ctx, err, "failed to set retention policy")
+	}
+
+	return &pb.SetRetentionPolicyResponse{
+		Policy: h.mapper.ToPolicyProto(policy),
+	}, nil
+}
+
+func (h *TelemetryHandler) ListRetentionPolicies(ctx context.Context, req *pb.ListRetentionPoliciesRequest) (*pb.ListRetentionPoliciesResponse, error) {
+	logger.FromContext(ctx).Debug("Listing retention policies")
+
+	policies, defaultRetention, err := h.service.ListRetentionPolicies(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to list retention policies", "error", err)
+		return nil, status.Error(codes.Internal, "failed to list retention policies")
+	}
+
+	return &pb.ListRetentionPoliciesResponse{
+		Policies:         h.mapper.ToPolicyProtoList(policies),
+		DefaultRetention: durationpb.New(defaultRetention),
+	}, nil
+}
+
+func (h *TelemetryHandler) DeleteRetentionPolicy(ctx context.Context, req *pb.DeleteRetentionPolicyRequest) (*pb.DeleteRetentionPolicyResponse, error) {
+	logger.FromContext(ctx).Debug("Deleting retention policy", "request", req)
+
+	featureID, err := uuid.Parse(req.FeatureId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := h.service.DeleteRetentionPolicy(ctx, featureID); err != nil {
+		return nil, telemetryError(ctx, err, "failed to delete retention policy")
+	}
+
+	return &pb.DeleteRetentionPolicyResponse{}, nil
+}
+
+func telemetryError(ctx context.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidTelemetryQuery):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	logger.FromContext(ctx).Error(message, "error", err)
+	return status.Error(codes.Internal, message)
+}
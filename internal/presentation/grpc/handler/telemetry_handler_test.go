@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+	pb "smart-hub/gen/proto/telemetry/v1"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+	"testing"
+	"time"
+)
+
+type mockTelemetryService struct {
+	mock.Mock
+}
+
+// Ingest drains next like the real service, so tests see what the handler
+// passes on, and then returns the mocked report.
+func (m *mockTelemetryService) Ingest(ctx context.Context, next func() ([]*models.TelemetryReading, error)) (*models.IngestReport, error) {
+	var readings []*models.TelemetryReading
+	for {
+		batch, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		readings = append(readings, batch...)
+	}
+	args := m.Called(ctx, readings)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IngestReport), args.Error(1)
+}
+
+func (m *mockTelemetryService) Query(ctx context.Context, query models.ReadingQuery, bucket time.Duration) (*models.ReadingSeries, error) {
+	args := m.Called(ctx, query, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReadingSeries), args.Error(1)
+}
+
+func (m *mockTelemetryService) SetRetentionPolicy(ctx context.Context, featureID uuid.UUID, retention time.Duration) (*models.RetentionPolicy, error) {
+	args := m.Called(ctx, featureID, retention)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RetentionPolicy), args.Error(1)
+}
+
+func (m *mockTelemetryService) ListRetentionPolicies(ctx context.Context) ([]*models.RetentionPolicy, time.Duration, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(time.Duration), args.Error(2)
+	}
+	return args.Get(0).([]*models.RetentionPolicy), args.Get(1).(time.Duration), args.Error(2)
+}
+
+func (m *mockTelemetryService) DeleteRetentionPolicy(ctx context.Context, featureID uuid.UUID) error {
+	args := m.Called(ctx, featureID)
+	return args.Error(0)
+}
+
+type fakeIngestReadingsServer struct {
+	grpc.ServerStream
+	requests []*pb.IngestReadingsRequest
+	err      error
+	response *pb.IngestReadingsResponse
+}
+
+func (s *fakeIngestReadingsServer) Context() context.Context {
+	return context.Background()
+}
+
+// Recv returns the requests and then err, or io.EOF when err is nil.
+func (s *fakeIngestReadingsServer) Recv() (*pb.IngestReadingsRequest, error) {
+	if len(s.requests) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *fakeIngestReadingsServer) SendAndClose(resp *pb.IngestReadingsResponse) error {
+	s.response = resp
+	return nil
+}
+
+func TestIngestReadings_Success(t *testing.T) {
+	mockService := new(mockTelemetryService)
+	handler := NewTelemetryHandler(mockService, mapper.NewTelemetryMapper())
+
+	featureID := uuid.New()
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	mockService.On("Ingest", mock.Anything, []*models.TelemetryReading{
+		{DeviceID: "dev-1", FeatureID: featureID, Timestamp: at, Value: 1.5},
+		{DeviceID: "dev-2", FeatureID: uuid.Nil, Timestamp: at, Value: 2},
+		{DeviceID: "dev-3", FeatureID: featureID, Value: 3},
+	}).Return(&models.IngestReport{
+		Received:   3,
+		Stored:     1,
+		Rejected:   2,
+		Rejections: []models.ReadingRejection{{Index: 1, Reason: "feature_id must be a UUID of a smart feature"}, {Index: 2, Reason: "timestamp is required"}},
+	}, nil)
+
+	stream := &fakeIngestReadingsServer{requests: []*pb.IngestReadingsRequest{
+		{Readings: []*pb.Reading{
+			{DeviceId: "dev-1", FeatureId: featureID.String(), Timestamp: timestamppb.New(at), Value: 1.5},
+			{DeviceId: "dev-2", FeatureId: "not-a-uuid", Timestamp: timestamppb.New(at), Value: 2},
+		}},
+		{Readings: []*pb.Reading{
+			{DeviceId: "dev-3", FeatureId: featureID.String(), Value: 3},
+		}},
+	}}
+
+	err := handler.IngestReadings(stream)
+
+	require.NoError(t, err)
+	require.NotNil(t, stream.response)
+	assert.Equal(t, int64(3), stream.response.Received)
+	assert.Equal(t, int64(1), stream.response.Stored)
+	assert.Equal(t, int64(2), stream.response.Rejected)
+	require.Len(t, stream.response.Rejections, 2)
+	assert.Equal(t, int64(1), stream.response.Rejections[0].Index)
+	mockService.AssertExpectations(t)
+}
+
+func TestIngestReadings_StreamError(t *testing.T) {
+	mockService := new(mockTelemetryService)
+	handler := NewTelemetryHandler(mockService, mapper.NewTelemetryMapper())
+
+	streamErr := status.Error(codes.Canceled, "context canceled")
+	stream := &fakeIngestReadingsServer{err: streamErr}
+
+	err := handler.IngestReadings(stream)
+
+	assert.Equal(t, streamErr, err)
+	assert.Nil(t, stream.response)
+}
+
+func TestQueryReadings_Success(t *testing.T) {
+	mockService := new(mockTelemetryService)
+	handler := NewTelemetryHandler(mockService, mapper.NewTelemetryMapper())
+
+	featureID := uuid.New()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	mockService.On("Query", mock.Anything, models.ReadingQuery{FeatureID: featureID, DeviceID: "dev-1", From: start, Limit: 50}, time.Hour).
+		Return(&models.ReadingSeries{
+			Buckets:   []*models.TelemetryBucket{{Start: start, Count: 4, Avg: 2, Min: 1, Max: 3}},
+			Truncated: true,
+		}, nil)
+
+	resp, err := handler.QueryReadings(context.Background(), &pb.QueryReadingsRequest{
+		FeatureId: featureID.String(),
+		DeviceId:  "dev-1",
+		Start:     timestamppb.New(start),
+		Bucket:    durationpb.New(time.Hour),
+		Limit:     50,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Truncated)
+	require.Len(t, resp.Buckets, 1)
+	assert.Equal(t, int64(4), resp.Buckets[0].Count)
+	assert.Equal(t, start, resp.Buckets[0].Start.AsTime())
+	mockService.AssertExpectations(t)
+}
+
+func TestQueryReadings_InvalidQuery(t *testing.T) {
+	mockService := new(mockTelemetryService)
+	handler := NewTelemetryHandler(mockService, mapper.NewTelemetryMapper())
+
+	featureID := uuid.New()
+	mockService.On("Query", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.Join(models.ErrInvalidTelemetryQuery, errors.New("start is required")))
+
+	resp, err := handler.QueryReadings(context.Background(), &pb.QueryReadingsRequest{FeatureId: featureID.String()})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestQueryReadings_InvalidFeatureID(t *testing.T) {
+	mockService := new(mockTelemetryService)
+	handler := NewTelemetryHandler(mockService, mapper.NewTelemetryMapper())
+
+	resp, err := handler.QueryReadings(context.Background(), &pb.QueryReadingsRequest{FeatureId: "nope"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockService.AssertNotCalled(t, "Query", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSetRetentionPolicy_FeatureNotFound(t *testing.T) {
+	mockService := new(mockTelemetryService)
+	handler := NewTelemetryHandler(mockService, mapper.NewTelemetryMapper())
+
+	featureID := uuid.New()
+	mockService.On("SetRetentionPolicy", mock.Anything, featureID, 48*time.Hour).Return(nil, models.ErrNotFound)
+
+	resp, err := handler.SetRetentionPolicy(context.Background(), &pb.SetRetentionPolicyRequest{
+		FeatureId: featureID.String(),
+		Retention: durationpb.New(48 * time.Hour),
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	mockService.AssertExpectations(t)
+}
+
+func TestListRetentionPolicies_Success(t *testing.T) {
+	mockService := new(mockTelemetryService)
+	handler := NewTelemetryHandler(mockService, mapper.NewTelemetryMapper())
+
+	featureID := uuid.New()
+	mockService.On("ListRetentionPolicies", mock.Anything).Return([]*models.RetentionPolicy{
+		{FeatureID: featureID, Retention: 48 * time.Hour, UpdatedAt: time.Now()},
+	}, 720*time.Hour, nil)
+
+	resp, err := handler.ListRetentionPolicies(context.Background(), &pb.ListRetentionPoliciesRequest{})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Policies, 1)
+	assert.Equal(t, featureID.String(), resp.Policies[0].FeatureId)
+	assert.Equal(t, 48*time.Hour, resp.Policies[0].Retention.AsDuration())
+	assert.Equal(t, 720*time.Hour, resp.DefaultRetention.AsDuration())
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/domain/models"
+)
+
+// watchError converts the error that ended a watch into a gRPC status.
+// Errors that already carry a status, such as a failed Send, pass through.
+func watchError(ctx context.Context, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return status.FromContextError(ctx.Err()).Err()
+	case errors.Is(err, models.ErrInvalidResumeToken):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrResumeTokenExpired):
+		return status.Error(codes.OutOfRange, "resume token expired, restart the watch without a token")
+	case errors.Is(err, models.ErrSlowConsumer):
+		return status.Error(codes.ResourceExhausted, "watcher fell behind, resume with the last token")
+	case errors.Is(err, models.ErrWatchStopped):
+		return status.Error(codes.Unavailable, "server is shutting down, resume with the last token")
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	logger.FromContext(ctx).Error("Watch failed", "error", err)
+	return status.Error(codes.Internal, "watch failed")
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "smart-hub/gen/proto/webhook/v1"
+	"smart-hub/internal/application/interfaces"
+	"smart-hub/internal/common/logger"
+	"smart-hub/internal/common/validation"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+)
+
+type WebhookHandler struct {
+	pb.UnimplementedWebhookServiceServer
+	service interfaces.WebhookService
+	mapper  mapper.WebhookMapper
+}
+
+func NewWebhookHandler(
+	service interfaces.WebhookService,
+	mapper mapper.WebhookMapper,
+) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		mapper:  mapper,
+	}
+}
+
+func (h *WebhookHandler) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.CreateWebhookResponse, error) {
+	logger.FromContext(ctx).Debug("Creating webhook", "url", req.GetWebhook().GetUrl())
+
+	subscription, err := h.mapper.ToDomain(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request: webhook is required")
+	}
+
+	if err := validation.ValidateStruct(subscription); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	created, err := h.service.CreateSubscription(ctx, subscription)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to create webhook", "error", err)
+		return nil, status.Error(codes.Internal, "failed to create webhook")
+	}
+
+	protoWebhook := h.mapper.ToProto(created)
+	protoWebhook.Secret = created.Secret
+
+	return &pb.CreateWebhookResponse{
+		Webhook: protoWebhook,
+	}, nil
+}
+
+func (h *WebhookHandler) GetWebhook(ctx context.Context, req *pb.GetWebhookRequest) (*pb.GetWebhookResponse, error) {
+	logger.FromContext(ctx).Debug("Getting webhook", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	subscription, err := h.service.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, webhookError(ctx, err, "failed to get webhook")
+	}
+
+	return &pb.GetWebhookResponse{
+		Webhook: h.mapper.ToProto(subscription),
+	}, nil
+}
+
+func (h *WebhookHandler) ListWebhooks(ctx context.Context, req *pb.ListWebhooksRequest) (*pb.ListWebhooksResponse, error) {
+	logger.FromContext(ctx).Debug("Listing webhooks")
+
+	subscriptions, err := h.service.ListSubscriptions(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to list webhooks", "error", err)
+		return nil, status.Error(codes.Internal, "failed to list webhooks")
+	}
+
+	return &pb.ListWebhooksResponse{
+		Webhooks: h.mapper.ToProtoList(subscriptions),
+	}, nil
+}
+
+func (h *WebhookHandler) UpdateWebhook(ctx context.Context, req *pb.UpdateWebhookRequest) (*pb.UpdateWebhookResponse, error) {
+	logger.FromContext(ctx).Debug("Updating webhook", "id", req.GetWebhook().GetId())
+
+	subscription, err := h.mapper.ToDomainUpdate(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request: webhook with a valid id is required")
+	}
+
+	if err := validation.ValidateStruct(subscription); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	updated, err := h.service.UpdateSubscription(ctx, subscription)
+	if err != nil {
+		return nil, webhookError(ctx, err, "failed to update webhook")
+	}
+
+	return &pb.UpdateWebhookResponse{
+		Webhook: h.mapper.ToProto(updated),
+	}, nil
+}
+
+func (h *WebhookHandler) DeleteWebhook(ctx context.Context, req *pb.DeleteWebhookRequest) (*pb.DeleteWebhookResponse, error) {
+	logger.FromContext(ctx).Debug("Deleting webhook", "request", req)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := h.service.DeleteSubscription(ctx, id); err != nil {
+		return nil, webhookError(ctx, err, "failed to delete webhook")
+	}
+
+	return &pb.DeleteWebhookResponse{}, nil
+}
+
+func (h *WebhookHandler) ListWebhookDeliveries(ctx context.Context, req *pb.ListWebhookDeliveriesRequest) (*pb.ListWebhookDeliveriesResponse, error) {
+	logger.FromContext(ctx).Debug("Listing webhook deliveries", "request", req)
+
+	id, err := uuid.Parse(req.WebhookId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	filter := models.WebhookDeliveryFilter{SubscriptionID: &id, Limit: int(req.Limit)}
+	if req.Status != nil {
+		deliveryStatus := h.mapper.ToDeliveryStatusDomain(req.GetStatus())
+		filter.Status = &deliveryStatus
+	}
+
+	deliveries, err := h.service.ListDeliveries(ctx, filter)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to list webhook deliveries", "error", err)
+		return nil, status.Error(codes.Internal, "failed to list webhook deliveries")
+	}
+
+	return &pb.ListWebhookDeliveriesResponse{
+		Deliveries: h.mapper.ToDeliveryProtoList(deliveries),
+	}, nil
+}
+
+func (h *WebhookHandler) ListDeadLetters(ctx context.Context, req *pb.ListDeadLettersRequest) (*pb.ListDeadLettersResponse, error) {
+	logger.FromContext(ctx).Debug("Listing webhook dead letters", "request", req)
+
+	dead := models.DeliveryDead
+	filter := models.WebhookDeliveryFilter{Status: &dead, Limit: int(req.Limit)}
+	if req.WebhookId != "" {
+		id, err := uuid.Parse(req.WebhookId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		filter.SubscriptionID = &id
+	}
+
+	deliveries, err := h.service.ListDeliveries(ctx, filter)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to list webhook dead letters", "error", err)
+		return nil, status.Error(codes.Internal, "failed to list webhook dead letters")
+	}
+
+	return &pb.ListDeadLettersResponse{
+		Deliveries: h.mapper.ToDeliveryProtoList(deliveries),
+	}, nil
+}
+
+func (h *WebhookHandler) RedeliverWebhook(ctx context.Context, req *pb.RedeliverWebhookRequest) (*pb.RedeliverWebhookResponse, error) {
+	logger.FromContext(ctx).Debug("Redelivering webhook", "request", req)
+
+	id, err := uuid.Parse(req.DeliveryId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	delivery, err := h.service.Redeliver(ctx, id)
+	if err != nil {
+		return nil, webhookError(ctx, err, "failed to redeliver webhook")
+	}
+
+	return &pb.RedeliverWebhookResponse{
+		Delivery: h.mapper.ToDeliveryProto(delivery),
+	}, nil
+}
+
+func webhookError(ctx context.Context, err error, message string) error {
+	if errors.Is(err, models.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	logger.FromContext(ctx).Error(message, "error", err)
+	return status.Error(codes.Internal, message)
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "smart-hub/gen/proto/webhook/v1"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/presentation/grpc/mapper"
+	"testing"
+)
+
+type mockWebhookService struct {
+	mock.Mock
+}
+
+func (m *mockWebhookService) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, subscription)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookService) GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookService) ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookService) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, subscription)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookService) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockWebhookService) ListDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func TestCreateWebhook_ReturnsSecretOnce(t *testing.T) {
+	mockService := new(mockWebhookService)
+	handler := NewWebhookHandler(mockService, mapper.NewWebhookMapper())
+
+	created := &models.WebhookSubscription{ID: uuid.New(), URL: "https://example.com/hook", Secret: "generated-secret-value", Active: true}
+	mockService.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(s *models.WebhookSubscription) bool {
+		return s.URL == "https://example.com/hook" && len(s.EventTypes) == 1
+	})).Return(created, nil)
+	mockService.On("GetSubscription", mock.Anything, created.ID).Return(created, nil)
+
+	resp, err := handler.CreateWebhook(context.Background(), &pb.CreateWebhookRequest{
+		Webhook: &pb.CreateWebhookInput{Url: "https://example.com/hook", EventTypes: []string{"model.created"}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "generated-secret-value", resp.Webhook.Secret)
+
+	getResp, err := handler.GetWebhook(context.Background(), &pb.GetWebhookRequest{Id: created.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Empty(t, getResp.Webhook.Secret)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateWebhook_ValidationError(t *testing.T) {
+	handler := NewWebhookHandler(new(mockWebhookService), mapper.NewWebhookMapper())
+
+	tests := []*pb.CreateWebhookRequest{
+		{},
+		{Webhook: &pb.CreateWebhookInput{Url: "not a url"}},
+		{Webhook: &pb.CreateWebhookInput{Url: "https://example.com", EventTypes: []string{"model.exploded"}}},
+		{Webhook: &pb.CreateWebhookInput{Url: "https://example.com", Secret: "short"}},
+	}
+
+	for _, req := range tests {
+		_, err := handler.CreateWebhook(context.Background(), req)
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+	}
+}
+
+func TestGetWebhook_NotFound(t *testing.T) {
+	mockService := new(mockWebhookService)
+	handler := NewWebhookHandler(mockService, mapper.NewWebhookMapper())
+
+	id := uuid.New()
+	mockService.On("GetSubscription", mock.Anything, id).Return(nil, models.ErrNotFound)
+
+	_, err := handler.GetWebhook(context.Background(), &pb.GetWebhookRequest{Id: id.String()})
+
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+	mockService.AssertExpectations(t)
+}
+
+func TestListWebhookDeliveries_Filter(t *testing.T) {
+	mockService := new(mockWebhookService)
+	handler := NewWebhookHandler(mockService, mapper.NewWebhookMapper())
+
+	id := uuid.New()
+	failed := models.DeliveryFailed
+	delivery := &models.WebhookDelivery{ID: uuid.New(), SubscriptionID: id, Status: models.DeliveryFailed, Attempts: 2}
+	mockService.On("ListDeliveries", mock.Anything, models.WebhookDeliveryFilter{SubscriptionID: &id, Status: &failed, Limit: 10}).
+		Return([]*models.WebhookDelivery{delivery}, nil)
+
+	statusFilter := pb.DeliveryStatus_FAILED
+	resp, err := handler.ListWebhookDeliveries(context.Background(), &pb.ListWebhookDeliveriesRequest{
+		WebhookId: id.String(),
+		Status:    &statusFilter,
+		Limit:     10,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Deliveries, 1)
+	assert.Equal(t, pb.DeliveryStatus_FAILED, resp.Deliveries[0].Status)
+	assert.Equal(t, int32(2), resp.Deliveries[0].Attempts)
+	mockService.AssertExpectations(t)
+}
+
+func TestListDeadLetters(t *testing.T) {
+	mockService := new(mockWebhookService)
+	handler := NewWebhookHandler(mockService, mapper.NewWebhookMapper())
+
+	dead := models.DeliveryDead
+	mockService.On("ListDeliveries", mock.Anything, models.WebhookDeliveryFilter{Status: &dead}).
+		Return([]*models.WebhookDelivery{{ID: uuid.New(), Status: models.DeliveryDead}}, nil)
+
+	resp, err := handler.ListDeadLetters(context.Background(), &pb.ListDeadLettersRequest{})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Deliveries, 1)
+	assert.Equal(t, pb.DeliveryStatus_DEAD, resp.Deliveries[0].Status)
+	mockService.AssertExpectations(t)
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	mockService := new(mockWebhookService)
+	handler := NewWebhookHandler(mockService, mapper.NewWebhookMapper())
+
+	delivery := &models.WebhookDelivery{ID: uuid.New(), Status: models.DeliveryPending}
+	mockService.On("Redeliver", mock.Anything, delivery.ID).Return(delivery, nil)
+
+	resp, err := handler.RedeliverWebhook(context.Background(), &pb.RedeliverWebhookRequest{DeliveryId: delivery.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, delivery.ID.String(), resp.Delivery.Id)
+	assert.Equal(t, pb.DeliveryStatus_PENDING, resp.Delivery.Status)
+
+	_, err = handler.RedeliverWebhook(context.Background(), &pb.RedeliverWebhookRequest{DeliveryId: "invalid"})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	mockService.AssertExpectations(t)
+}
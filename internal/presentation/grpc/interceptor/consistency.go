@@ -0,0 +1,43 @@
+package interceptor
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"smart-hub/internal/common/database"
+	"strings"
+)
+
+// ReadConsistencyHeader lets a caller that has just written ask for reads
+// from the primary database instead of a replica that may lag behind.
+const ReadConsistencyHeader = "x-read-consistency"
+
+// ReadConsistencyPrimary is the ReadConsistencyHeader value that forces
+// primary reads.
+const ReadConsistencyPrimary = "primary"
+
+func withReadConsistency(ctx context.Context) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get(ReadConsistencyHeader) {
+			if strings.EqualFold(value, ReadConsistencyPrimary) {
+				return database.ForcePrimary(ctx)
+			}
+		}
+	}
+	return ctx
+}
+
+// UnaryReadConsistency routes the reads of a call to the primary when it
+// carries "x-read-consistency: primary".
+func UnaryReadConsistency() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withReadConsistency(ctx), req)
+	}
+}
+
+// StreamReadConsistency is the streaming counterpart of UnaryReadConsistency.
+func StreamReadConsistency() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: withReadConsistency(ss.Context())})
+	}
+}
@@ -0,0 +1,36 @@
+package interceptor
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"smart-hub/internal/common/database"
+	"testing"
+)
+
+func TestUnaryReadConsistency(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/smart_hub.smart_model.v1.SmartModelService/GetSmartModel"}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		primary bool
+	}{
+		{"no metadata", context.Background(), false},
+		{"primary", metadata.NewIncomingContext(context.Background(), metadata.Pairs(ReadConsistencyHeader, "PRIMARY")), true},
+		{"other value", metadata.NewIncomingContext(context.Background(), metadata.Pairs(ReadConsistencyHeader, "replica")), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var forced bool
+			_, err := UnaryReadConsistency()(tt.ctx, "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				forced = database.PrimaryForced(ctx)
+				return nil, nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.primary, forced)
+		})
+	}
+}
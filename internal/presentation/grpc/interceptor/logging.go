@@ -0,0 +1,102 @@
+package interceptor
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"smart-hub/internal/common/logger"
+	"time"
+)
+
+// RequestIDHeader is read from incoming metadata and echoed back in the
+// response header. A new ID is generated when the caller does not send one.
+const RequestIDHeader = "x-request-id"
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID assigned by the logging
+// interceptor, or an empty string outside of a gRPC call.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	return uuid.NewString()
+}
+
+func peerAddress(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// withRequest attaches the request ID to ctx both as a value and as a logger
+// field, and returns it so it can be sent back to the caller.
+func withRequest(ctx context.Context, method string) (context.Context, string) {
+	id := requestID(ctx)
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	ctx = logger.ContextWithFields(ctx, "request_id", id, "grpc.method", method)
+	return ctx, id
+}
+
+func logCall(ctx context.Context, start time.Time, err error) {
+	code := status.Code(err)
+	fields := []interface{}{
+		"grpc.peer", peerAddress(ctx),
+		"grpc.code", code.String(),
+		"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	log := logger.FromContext(ctx)
+	if err != nil {
+		log.Warn("gRPC call finished", append(fields, err)...)
+		return
+	}
+	log.Info("gRPC call finished", fields...)
+}
+
+// UnaryLogging assigns or propagates x-request-id and logs method, peer,
+// duration and status code for every unary call.
+func UnaryLogging() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx, id := withRequest(ctx, info.FullMethod)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+
+		resp, err := handler(ctx, req)
+		logCall(ctx, start, err)
+		return resp, err
+	}
+}
+
+// StreamLogging is the streaming counterpart of UnaryLogging.
+func StreamLogging() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, id := withRequest(ss.Context(), info.FullMethod)
+		_ = ss.SetHeader(metadata.Pairs(RequestIDHeader, id))
+
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+		logCall(ctx, start, err)
+		return err
+	}
+}
+
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
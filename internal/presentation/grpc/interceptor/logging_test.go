@@ -0,0 +1,106 @@
+package interceptor
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestUnaryLogging_PropagatesRequestID(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDHeader, "req-123"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/smart_hub.smart_model.v1.SmartModelService/GetSmartModel"}
+
+	var seen string
+	resp, err := UnaryLogging()(ctx, "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		seen = RequestIDFromContext(ctx)
+		return "response", nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "response", resp)
+	assert.Equal(t, "req-123", seen)
+}
+
+func TestUnaryLogging_GeneratesRequestID(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/smart_hub.smart_model.v1.SmartModelService/GetSmartModel"}
+
+	var seen string
+	_, err := UnaryLogging()(context.Background(), "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		seen = RequestIDFromContext(ctx)
+		return nil, nil
+	})
+
+	assert.NoError(t, err)
+	_, parseErr := uuid.Parse(seen)
+	assert.NoError(t, parseErr)
+}
+
+func TestUnaryLogging_ReturnsHandlerError(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/smart_hub.smart_model.v1.SmartModelService/GetSmartModel"}
+	handlerErr := status.Error(codes.NotFound, "not found")
+
+	resp, err := UnaryLogging()(context.Background(), "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, handlerErr
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestStreamLogging(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/smart_hub.smart_model.v1.SmartModelService/WatchSmartModels", IsServerStream: true}
+
+	tests := []struct {
+		name       string
+		incoming   metadata.MD
+		handlerErr error
+	}{
+		{name: "propagates request ID", incoming: metadata.Pairs(RequestIDHeader, "req-123")},
+		{name: "generates request ID", incoming: metadata.MD{}},
+		{name: "returns handler error", incoming: metadata.MD{}, handlerErr: status.Error(codes.Unavailable, "watch stopped")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &fakeServerStream{ctx: metadata.NewIncomingContext(context.Background(), tt.incoming)}
+
+			var seen string
+			var srv interface{}
+			err := StreamLogging()("server", stream, info, func(s interface{}, ss grpc.ServerStream) error {
+				srv = s
+				seen = RequestIDFromContext(ss.Context())
+				return tt.handlerErr
+			})
+
+			assert.Equal(t, "server", srv)
+			assert.Equal(t, status.Code(tt.handlerErr), status.Code(err))
+			assert.Equal(t, []string{seen}, stream.header.Get(RequestIDHeader))
+			if ids := tt.incoming.Get(RequestIDHeader); len(ids) > 0 {
+				assert.Equal(t, ids[0], seen)
+			} else {
+				_, parseErr := uuid.Parse(seen)
+				assert.NoError(t, parseErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package mapper
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "smart-hub/gen/proto/automation/v1"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type AutomationMapper interface {
+	ToProto(*models.AutomationRule) (*pb.AutomationRule, error)
+	ToProtoList([]*models.AutomationRule) ([]*pb.AutomationRule, error)
+	ToDomain(*pb.AutomationRule) (*models.AutomationRule, error)
+	ToExecutionProto(*models.AutomationExecution) (*pb.RuleExecution, error)
+	ToExecutionProtoList([]*models.AutomationExecution) ([]*pb.RuleExecution, error)
+}
+
+type automationMapper struct{}
+
+func NewAutomationMapper() AutomationMapper {
+	return &automationMapper{}
+}
+
+var (
+	operatorToProto = map[models.ThresholdOperator]pb.ThresholdOperator{
+		models.OperatorGreater:      pb.ThresholdOperator_GREATER_THAN,
+		models.OperatorGreaterEqual: pb.ThresholdOperator_GREATER_THAN_OR_EQUAL,
+		models.OperatorLess:         pb.ThresholdOperator_LESS_THAN,
+		models.OperatorLessEqual:    pb.ThresholdOperator_LESS_THAN_OR_EQUAL,
+		models.OperatorEqual:        pb.ThresholdOperator_EQUAL,
+		models.OperatorNotEqual:     pb.ThresholdOperator_NOT_EQUAL,
+	}
+	operatorToDomain = map[pb.ThresholdOperator]models.ThresholdOperator{
+		pb.ThresholdOperator_GREATER_THAN:          models.OperatorGreater,
+		pb.ThresholdOperator_GREATER_THAN_OR_EQUAL: models.OperatorGreaterEqual,
+		pb.ThresholdOperator_LESS_THAN:             models.OperatorLess,
+		pb.ThresholdOperator_LESS_THAN_OR_EQUAL:    models.OperatorLessEqual,
+		pb.ThresholdOperator_EQUAL:                 models.OperatorEqual,
+		pb.ThresholdOperator_NOT_EQUAL:             models.OperatorNotEqual,
+	}
+	executionStatusToProto = map[models.ExecutionStatus]pb.ExecutionStatus{
+		models.ExecutionRunning:   pb.ExecutionStatus_RUNNING,
+		models.ExecutionSucceeded: pb.ExecutionStatus_SUCCEEDED,
+		models.ExecutionFailed:    pb.ExecutionStatus_FAILED,
+	}
+)
+
+func (m *automationMapper) ToProto(rule *models.AutomationRule) (*pb.AutomationRule, error) {
+	if rule == nil {
+		return nil, nil
+	}
+
+	trigger, err := m.toTriggerProto(rule.Trigger)
+	if err != nil {
+		return nil, err
+	}
+	actions := make([]*pb.RuleAction, len(rule.Actions))
+	for i, action := range rule.Actions {
+		parameters, err := structpb.NewStruct(action.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		actions[i] = &pb.RuleAction{
+			DeviceId:   action.DeviceID,
+			FeatureId:  action.FeatureID.String(),
+			Parameters: parameters,
+		}
+	}
+
+	return &pb.AutomationRule{
+		Id:          rule.ID.String(),
+		Name:        rule.Name,
+		Description: rule.Description,
+		Enabled:     rule.Enabled,
+		Trigger:     trigger,
+		Condition:   rule.Condition,
+		Actions:     actions,
+		Timezone:    rule.Timezone,
+		Cooldown:    durationpb.New(rule.Cooldown),
+		CreatedAt:   timestamppb.New(rule.CreatedAt),
+		UpdatedAt:   timestamppb.New(rule.UpdatedAt),
+	}, nil
+}
+
+func (m *automationMapper) toTriggerProto(trigger models.RuleTrigger) (*pb.Trigger, error) {
+	switch trigger.Type {
+	case models.TriggerTelemetry:
+		return &pb.Trigger{Kind: &pb.Trigger_TelemetryThreshold{TelemetryThreshold: &pb.TelemetryThreshold{
+			FeatureId: trigger.FeatureID.String(),
+			DeviceId:  trigger.DeviceID,
+			Operator:  operatorToProto[trigger.Operator],
+			Threshold: trigger.Threshold,
+		}}}, nil
+	case models.TriggerStateChange:
+		stateChange := &pb.StateChange{
+			FeatureId: trigger.FeatureID.String(),
+			DeviceId:  trigger.DeviceID,
+		}
+		if trigger.Value != nil {
+			value, err := structpb.NewValue(trigger.Value)
+			if err != nil {
+				return nil, err
+			}
+			stateChange.Value = value
+		}
+		return &pb.Trigger{Kind: &pb.Trigger_StateChange{StateChange: stateChange}}, nil
+	case models.TriggerSchedule:
+		weekdays := make([]int32, len(trigger.Weekdays))
+		for i, day := range trigger.Weekdays {
+			weekdays[i] = int32(day)
+		}
+		return &pb.Trigger{Kind: &pb.Trigger_Schedule{Schedule: &pb.Schedule{
+			At:       trigger.At,
+			Weekdays: weekdays,
+		}}}, nil
+	}
+	return nil, fmt.Errorf("unknown trigger type %q", trigger.Type)
+}
+
+func (m *automationMapper) ToProtoList(rules []*models.AutomationRule) ([]*pb.AutomationRule, error) {
+	protoRules := make([]*pb.AutomationRule, len(rules))
+	for i, rule := range rules {
+		protoRule, err := m.ToProto(rule)
+		if err != nil {
+			return nil, err
+		}
+		protoRules[i] = protoRule
+	}
+	return protoRules, nil
+}
+
+// ToDomain fails on malformed IDs. A rule without an ID gets uuid.Nil, and a
+// trigger without a kind an empty type, which the service rejects.
+func (m *automationMapper) ToDomain(rule *pb.AutomationRule) (*models.AutomationRule, error) {
+	if rule == nil {
+		return nil, errMissingInput
+	}
+
+	var id uuid.UUID
+	if rule.Id != "" {
+		var err error
+		if id, err = uuid.Parse(rule.Id); err != nil {
+			return nil, fmt.Errorf("id: %w", err)
+		}
+	}
+	trigger, err := m.toTriggerDomain(rule.Trigger)
+	if err != nil {
+		return nil, err
+	}
+	actions := make([]models.RuleAction, len(rule.Actions))
+	for i, action := range rule.Actions {
+		featureID, err := uuid.Parse(action.FeatureId)
+		if err != nil {
+			return nil, fmt.Errorf("action %d: feature_id: %w", i, err)
+		}
+		actions[i] = models.RuleAction{
+			DeviceID:   action.DeviceId,
+			FeatureID:  featureID,
+			Parameters: action.Parameters.AsMap(),
+		}
+	}
+
+	domainRule := &models.AutomationRule{
+		ID:          id,
+		Name:        rule.Name,
+		Description: rule.Description,
+		Enabled:     rule.Enabled,
+		Trigger:     trigger,
+		Condition:   rule.Condition,
+		Actions:     actions,
+		Timezone:    rule.Timezone,
+	}
+	if rule.Cooldown != nil {
+		domainRule.Cooldown = rule.Cooldown.AsDuration()
+	}
+	return domainRule, nil
+}
+
+func (m *automationMapper) toTriggerDomain(trigger *pb.Trigger) (models.RuleTrigger, error) {
+	parseFeatureID := func(featureID string) (uuid.UUID, error) {
+		id, err := uuid.Parse(featureID)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("trigger feature_id: %w", err)
+		}
+		return id, nil
+	}
+
+	switch kind := trigger.GetKind().(type) {
+	case *pb.Trigger_TelemetryThreshold:
+		featureID, err := parseFeatureID(kind.TelemetryThreshold.FeatureId)
+		if err != nil {
+			return models.RuleTrigger{}, err
+		}
+		return models.RuleTrigger{
+			Type:      models.TriggerTelemetry,
+			FeatureID: featureID,
+			DeviceID:  kind.TelemetryThreshold.DeviceId,
+			Operator:  operatorToDomain[kind.TelemetryThreshold.Operator],
+			Threshold: kind.TelemetryThreshold.Threshold,
+		}, nil
+	case *pb.Trigger_StateChange:
+		featureID, err := parseFeatureID(kind.StateChange.FeatureId)
+		if err != nil {
+			return models.RuleTrigger{}, err
+		}
+		return models.RuleTrigger{
+			Type:      models.TriggerStateChange,
+			FeatureID: featureID,
+			DeviceID:  kind.StateChange.DeviceId,
+			Value:     kind.StateChange.Value.AsInterface(),
+		}, nil
+	case *pb.Trigger_Schedule:
+		var weekdays []time.Weekday
+		for _, day := range kind.Schedule.Weekdays {
+			weekdays = append(weekdays, time.Weekday(day))
+		}
+		return models.RuleTrigger{
+			Type:     models.TriggerSchedule,
+			At:       kind.Schedule.At,
+			Weekdays: weekdays,
+		}, nil
+	}
+	return models.RuleTrigger{}, nil
+}
+
+func (m *automationMapper) ToExecutionProto(execution *models.AutomationExecution) (*pb.RuleExecution, error) {
+	if execution == nil {
+		return nil, nil
+	}
+
+	trigger, err := structpb.NewStruct(execution.Trigger)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*pb.ActionResult, len(execution.Results))
+	for i, result := range execution.Results {
+		results[i] = &pb.ActionResult{
+			DeviceId:  result.DeviceID,
+			FeatureId: result.FeatureID.String(),
+			Error:     result.Error,
+		}
+	}
+
+	protoExecution := &pb.RuleExecution{
+		Id:        execution.ID.String(),
+		RuleId:    execution.RuleID.String(),
+		Trigger:   trigger,
+		Status:    executionStatusToProto[execution.Status],
+		Error:     execution.Error,
+		Results:   results,
+		StartedAt: timestamppb.New(execution.StartedAt),
+	}
+	if execution.FinishedAt != nil {
+		protoExecution.FinishedAt = timestamppb.New(*execution.FinishedAt)
+	}
+	return protoExecution, nil
+}
+
+func (m *automationMapper) ToExecutionProtoList(executions []*models.AutomationExecution) ([]*pb.RuleExecution, error) {
+	protoExecutions := make([]*pb.RuleExecution, len(executions))
+	for i, execution := range executions {
+		protoExecution, err := m.ToExecutionProto(execution)
+		if err != nil {
+			return nil, err
+		}
+		protoExecutions[i] = protoExecution
+	}
+	return protoExecutions, nil
+}
@@ -0,0 +1,112 @@
+package mapper
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"maps"
+	pb "smart-hub/gen/proto/capability/v1"
+	"smart-hub/internal/domain/models"
+)
+
+type CapabilityMapper interface {
+	ToProto(models.Capability) *pb.Capability
+	ToProtoList([]models.Capability) []*pb.Capability
+	ToFeatureCapabilityProto(*models.FeatureCapability) *pb.FeatureCapability
+	ToFeatureCapabilityDomain(*pb.FeatureCapability) (*models.FeatureCapability, error)
+	ToCapableModelsProto([]*models.CapableModel) []*pb.CapableModel
+}
+
+type capabilityMapper struct{}
+
+func NewCapabilityMapper() CapabilityMapper {
+	return &capabilityMapper{}
+}
+
+var parameterTypeToProto = map[models.ParameterType]pb.ParameterType{
+	models.ParameterBoolean: pb.ParameterType_BOOLEAN,
+	models.ParameterInteger: pb.ParameterType_INTEGER,
+	models.ParameterNumber:  pb.ParameterType_NUMBER,
+	models.ParameterString:  pb.ParameterType_STRING,
+}
+
+func (m *capabilityMapper) ToProto(capability models.Capability) *pb.Capability {
+	parameters := make([]*pb.CapabilityParameter, len(capability.Parameters))
+	for i, parameter := range capability.Parameters {
+		parameters[i] = &pb.CapabilityParameter{
+			Name:        parameter.Name,
+			Type:        parameterTypeToProto[parameter.Type],
+			Required:    parameter.Required,
+			Minimum:     parameter.Minimum,
+			Maximum:     parameter.Maximum,
+			Unit:        parameter.Unit,
+			Values:      parameter.Values,
+			Description: parameter.Description,
+		}
+	}
+	return &pb.Capability{
+		Name:        capability.Name,
+		Description: capability.Description,
+		Parameters:  parameters,
+	}
+}
+
+func (m *capabilityMapper) ToProtoList(capabilities []models.Capability) []*pb.Capability {
+	protoCapabilities := make([]*pb.Capability, len(capabilities))
+	for i, capability := range capabilities {
+		protoCapabilities[i] = m.ToProto(capability)
+	}
+	return protoCapabilities
+}
+
+func (m *capabilityMapper) ToFeatureCapabilityProto(capability *models.FeatureCapability) *pb.FeatureCapability {
+	if capability == nil {
+		return nil
+	}
+	return &pb.FeatureCapability{
+		FeatureId:        capability.FeatureID.String(),
+		Capability:       capability.Capability,
+		ParameterMapping: maps.Clone(capability.ParameterMapping),
+		ModelId:          capability.ModelID.String(),
+	}
+}
+
+// ToFeatureCapabilityDomain fails on a malformed feature_id. A missing one
+// becomes uuid.Nil and is left for the service to reject; model_id is
+// ignored.
+func (m *capabilityMapper) ToFeatureCapabilityDomain(capability *pb.FeatureCapability) (*models.FeatureCapability, error) {
+	if capability == nil {
+		return nil, errMissingInput
+	}
+
+	var featureID uuid.UUID
+	if capability.FeatureId != "" {
+		id, err := uuid.Parse(capability.FeatureId)
+		if err != nil {
+			return nil, fmt.Errorf("feature_id: %w", err)
+		}
+		featureID = id
+	}
+	return &models.FeatureCapability{
+		FeatureID:        featureID,
+		Capability:       capability.Capability,
+		ParameterMapping: maps.Clone(capability.ParameterMapping),
+	}, nil
+}
+
+func (m *capabilityMapper) ToCapableModelsProto(capable []*models.CapableModel) []*pb.CapableModel {
+	protoCapable := make([]*pb.CapableModel, len(capable))
+	for i, c := range capable {
+		features := make([]*pb.FeatureCapability, len(c.Features))
+		for j, feature := range c.Features {
+			features[j] = m.ToFeatureCapabilityProto(feature)
+		}
+		protoCapable[i] = &pb.CapableModel{
+			ModelId:      c.Model.ID.String(),
+			Name:         c.Model.Name,
+			Manufacturer: c.Model.Manufacturer,
+			ModelNumber:  c.Model.ModelNumber,
+			Features:     features,
+		}
+	}
+	return protoCapable
+}
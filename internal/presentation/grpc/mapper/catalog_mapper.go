@@ -0,0 +1,158 @@
+package mapper
+
+import (
+	"google.golang.org/protobuf/types/known/structpb"
+	pb "smart-hub/gen/proto/catalog/v1"
+	"smart-hub/internal/domain/models"
+	"strings"
+)
+
+type CatalogMapper interface {
+	ToProto(*models.CatalogEntry) (*pb.CatalogModel, error)
+	ToDomain(*pb.CatalogModel) (*models.CatalogEntry, error)
+	ToDomainOptions(*pb.ImportOptions) models.ImportOptions
+	ToImportResponse(*models.ImportReport) *pb.ImportCatalogResponse
+	ToExportFilter(*pb.ExportCatalogRequest) models.ExportFilter
+}
+
+type catalogMapper struct{}
+
+func NewCatalogMapper() CatalogMapper {
+	return &catalogMapper{}
+}
+
+func (m *catalogMapper) ToProto(entry *models.CatalogEntry) (*pb.CatalogModel, error) {
+	metadata, err := structpb.NewStruct(entry.Model.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	features := make([]*pb.CatalogFeature, len(entry.Features))
+	for i, feature := range entry.Features {
+		parameters, err := structpb.NewStruct(feature.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		features[i] = &pb.CatalogFeature{
+			Name:          feature.Name,
+			Description:   feature.Description,
+			Protocol:      mapDomainProtocolToCatalogProto(feature.Protocol),
+			InterfacePath: feature.InterfacePath,
+			Parameters:    parameters,
+		}
+	}
+
+	return &pb.CatalogModel{
+		Id:           entry.Model.ID.String(),
+		Name:         entry.Model.Name,
+		Description:  entry.Model.Description,
+		Type:         mapDomainTypeToCatalogProto(entry.Model.Type),
+		Category:     mapDomainCategoryToCatalogProto(entry.Model.Category),
+		Manufacturer: entry.Model.Manufacturer,
+		ModelNumber:  entry.Model.ModelNumber,
+		Metadata:     metadata,
+		Features:     features,
+	}, nil
+}
+
+// ToDomain converts an imported model. IDs and timestamps are left for the
+// catalog service to fill in, since an import may update an existing model.
+func (m *catalogMapper) ToDomain(model *pb.CatalogModel) (*models.CatalogEntry, error) {
+	if model == nil {
+		return nil, errMissingInput
+	}
+
+	metadata := make(map[string]interface{})
+	if model.Metadata != nil {
+		metadata = model.Metadata.AsMap()
+	}
+
+	features := make([]*models.SmartFeature, len(model.Features))
+	for i, feature := range model.Features {
+		parameters := make(map[string]interface{})
+		if feature.Parameters != nil {
+			parameters = feature.Parameters.AsMap()
+		}
+		features[i] = &models.SmartFeature{
+			Name:          feature.Name,
+			Description:   feature.Description,
+			Protocol:      models.ProtocolType(strings.ToLower(feature.Protocol.String())),
+			InterfacePath: feature.InterfacePath,
+			Parameters:    parameters,
+		}
+	}
+
+	return &models.CatalogEntry{
+		Model: &models.SmartModel{
+			Name:         model.Name,
+			Description:  model.Description,
+			Type:         models.ModelType(strings.ToLower(model.Type.String())),
+			Category:     models.ModelCategory(strings.ToLower(model.Category.String())),
+			Manufacturer: model.Manufacturer,
+			ModelNumber:  model.ModelNumber,
+			Metadata:     metadata,
+		},
+		Features: features,
+	}, nil
+}
+
+func (m *catalogMapper) ToDomainOptions(opts *pb.ImportOptions) models.ImportOptions {
+	return models.ImportOptions{
+		DryRun:    opts.GetDryRun(),
+		BatchSize: int(opts.GetBatchSize()),
+	}
+}
+
+func (m *catalogMapper) ToImportResponse(report *models.ImportReport) *pb.ImportCatalogResponse {
+	rows := make([]*pb.ImportRowResult, len(report.Rows))
+	for i, row := range report.Rows {
+		rows[i] = &pb.ImportRowResult{
+			Row:    int32(row.Row),
+			Status: mapDomainImportStatusToProto(row.Status),
+			Errors: row.Errors,
+		}
+		if row.Status == models.ImportCreated || row.Status == models.ImportUpdated {
+			rows[i].ModelId = row.ModelID.String()
+		}
+	}
+
+	return &pb.ImportCatalogResponse{
+		DryRun:     report.DryRun,
+		Created:    int32(report.Created),
+		Updated:    int32(report.Updated),
+		Failed:     int32(report.Failed),
+		RolledBack: int32(report.RolledBack),
+		Rows:       rows,
+	}
+}
+
+func (m *catalogMapper) ToExportFilter(req *pb.ExportCatalogRequest) models.ExportFilter {
+	var filter models.ExportFilter
+	if req.Manufacturer != nil {
+		manufacturer := req.GetManufacturer()
+		filter.Manufacturer = &manufacturer
+	}
+	if req.Type != nil {
+		modelType := models.ModelType(strings.ToLower(req.GetType().String()))
+		filter.Type = &modelType
+	}
+	return filter
+}
+
+// The catalog enums use the upper-cased domain values as names.
+
+func mapDomainTypeToCatalogProto(t models.ModelType) pb.ModelType {
+	return pb.ModelType(pb.ModelType_value[strings.ToUpper(string(t))])
+}
+
+func mapDomainCategoryToCatalogProto(c models.ModelCategory) pb.ModelCategory {
+	return pb.ModelCategory(pb.ModelCategory_value[strings.ToUpper(string(c))])
+}
+
+func mapDomainProtocolToCatalogProto(p models.ProtocolType) pb.ProtocolType {
+	return pb.ProtocolType(pb.ProtocolType_value[strings.ToUpper(string(p))])
+}
+
+func mapDomainImportStatusToProto(s models.ImportRowStatus) pb.ImportRowStatus {
+	return pb.ImportRowStatus(pb.ImportRowStatus_value[strings.ToUpper(string(s))])
+}
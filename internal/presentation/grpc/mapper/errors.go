@@ -0,0 +1,5 @@
+package mapper
+
+import "errors"
+
+var errMissingInput = errors.New("request input is required")
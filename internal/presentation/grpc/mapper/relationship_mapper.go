@@ -0,0 +1,123 @@
+package mapper
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "smart-hub/gen/proto/relationship/v1"
+	"smart-hub/internal/domain/models"
+)
+
+type RelationshipMapper interface {
+	ToProto(*models.ModelRelationship) *pb.Relationship
+	ToProtoList([]*models.ModelRelationship) []*pb.Relationship
+	ToDomain(*pb.Relationship) (*models.ModelRelationship, error)
+	ToDomainType(pb.RelationshipType) models.RelationshipType
+	ToDomainDirection(pb.Direction) models.RelationshipDirection
+	ToRelatedModelsProto([]*models.RelatedModel) []*pb.RelatedModel
+}
+
+type relationshipMapper struct{}
+
+func NewRelationshipMapper() RelationshipMapper {
+	return &relationshipMapper{}
+}
+
+var (
+	relationshipTypeToProto = map[models.RelationshipType]pb.RelationshipType{
+		models.RelationshipCompatibleWith: pb.RelationshipType_COMPATIBLE_WITH,
+		models.RelationshipRequires:       pb.RelationshipType_REQUIRES,
+		models.RelationshipReplaces:       pb.RelationshipType_REPLACES,
+		models.RelationshipAccessoryOf:    pb.RelationshipType_ACCESSORY_OF,
+	}
+	relationshipTypeToDomain = map[pb.RelationshipType]models.RelationshipType{
+		pb.RelationshipType_COMPATIBLE_WITH: models.RelationshipCompatibleWith,
+		pb.RelationshipType_REQUIRES:        models.RelationshipRequires,
+		pb.RelationshipType_REPLACES:        models.RelationshipReplaces,
+		pb.RelationshipType_ACCESSORY_OF:    models.RelationshipAccessoryOf,
+	}
+	directionToDomain = map[pb.Direction]models.RelationshipDirection{
+		pb.Direction_OUTGOING: models.DirectionOutgoing,
+		pb.Direction_INCOMING: models.DirectionIncoming,
+		pb.Direction_BOTH:     models.DirectionBoth,
+	}
+)
+
+func (m *relationshipMapper) ToProto(relationship *models.ModelRelationship) *pb.Relationship {
+	if relationship == nil {
+		return nil
+	}
+	return &pb.Relationship{
+		Id:            relationship.ID.String(),
+		SourceModelId: relationship.SourceModelID.String(),
+		TargetModelId: relationship.TargetModelID.String(),
+		Type:          relationshipTypeToProto[relationship.Type],
+		CreatedAt:     timestamppb.New(relationship.CreatedAt),
+	}
+}
+
+func (m *relationshipMapper) ToProtoList(relationships []*models.ModelRelationship) []*pb.Relationship {
+	protoRelationships := make([]*pb.Relationship, len(relationships))
+	for i, relationship := range relationships {
+		protoRelationships[i] = m.ToProto(relationship)
+	}
+	return protoRelationships
+}
+
+// ToDomain fails on malformed IDs. Missing IDs become uuid.Nil and are left
+// for the service to reject. An unknown type maps to the empty type, which
+// the service rejects as well.
+func (m *relationshipMapper) ToDomain(relationship *pb.Relationship) (*models.ModelRelationship, error) {
+	if relationship == nil {
+		return nil, errMissingInput
+	}
+
+	ids := make([]uuid.UUID, 3)
+	for i, field := range []struct{ name, value string }{
+		{"id", relationship.Id},
+		{"source_model_id", relationship.SourceModelId},
+		{"target_model_id", relationship.TargetModelId},
+	} {
+		if field.value == "" {
+			continue
+		}
+		id, err := uuid.Parse(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field.name, err)
+		}
+		ids[i] = id
+	}
+	return &models.ModelRelationship{
+		ID:            ids[0],
+		SourceModelID: ids[1],
+		TargetModelID: ids[2],
+		Type:          m.ToDomainType(relationship.Type),
+	}, nil
+}
+
+func (m *relationshipMapper) ToDomainType(t pb.RelationshipType) models.RelationshipType {
+	return relationshipTypeToDomain[t]
+}
+
+func (m *relationshipMapper) ToDomainDirection(direction pb.Direction) models.RelationshipDirection {
+	return directionToDomain[direction]
+}
+
+// ToRelatedModelsProto skips models that were deleted after the graph was
+// read.
+func (m *relationshipMapper) ToRelatedModelsProto(related []*models.RelatedModel) []*pb.RelatedModel {
+	protoRelated := make([]*pb.RelatedModel, 0, len(related))
+	for _, r := range related {
+		if r.Model == nil {
+			continue
+		}
+		protoRelated = append(protoRelated, &pb.RelatedModel{
+			ModelId:      r.ModelID.String(),
+			Name:         r.Model.Name,
+			Manufacturer: r.Model.Manufacturer,
+			ModelNumber:  r.Model.ModelNumber,
+			Depth:        int32(r.Depth),
+		})
+	}
+	return protoRelated
+}
@@ -0,0 +1,150 @@
+package mapper
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "smart-hub/gen/proto/scene/v1"
+	"smart-hub/internal/domain/models"
+)
+
+type SceneMapper interface {
+	ToProto(*models.Scene) (*pb.Scene, error)
+	ToProtoList([]*models.Scene) ([]*pb.Scene, error)
+	ToDomain(*pb.Scene) (*models.Scene, error)
+	ToActivationProto(*models.SceneActivation) *pb.ActivateSceneResponse
+}
+
+type sceneMapper struct{}
+
+func NewSceneMapper() SceneMapper {
+	return &sceneMapper{}
+}
+
+var stepStatusToProto = map[models.StepStatus]pb.StepStatus{
+	models.StepSucceeded: pb.StepStatus_SUCCEEDED,
+	models.StepFailed:    pb.StepStatus_FAILED,
+	models.StepSkipped:   pb.StepStatus_SKIPPED,
+}
+
+var activationStatusToProto = map[models.ActivationStatus]pb.ActivationStatus{
+	models.ActivationSucceeded:       pb.ActivationStatus_ALL_SUCCEEDED,
+	models.ActivationPartiallyFailed: pb.ActivationStatus_PARTIALLY_FAILED,
+	models.ActivationFailed:          pb.ActivationStatus_ALL_FAILED,
+}
+
+func (m *sceneMapper) ToProto(scene *models.Scene) (*pb.Scene, error) {
+	if scene == nil {
+		return nil, nil
+	}
+
+	steps := make([]*pb.SceneStep, len(scene.Steps))
+	for i, step := range scene.Steps {
+		parameters, err := structpb.NewStruct(step.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		steps[i] = &pb.SceneStep{
+			DeviceId:   step.DeviceID,
+			FeatureId:  step.FeatureID.String(),
+			Parameters: parameters,
+			Group:      step.Group,
+		}
+		if step.Delay > 0 {
+			steps[i].Delay = durationpb.New(step.Delay)
+		}
+	}
+	return &pb.Scene{
+		Id:          scene.ID.String(),
+		Name:        scene.Name,
+		Description: scene.Description,
+		Steps:       steps,
+		StopOnError: scene.StopOnError,
+		CreatedAt:   timestamppb.New(scene.CreatedAt),
+		UpdatedAt:   timestamppb.New(scene.UpdatedAt),
+	}, nil
+}
+
+func (m *sceneMapper) ToProtoList(scenes []*models.Scene) ([]*pb.Scene, error) {
+	protoScenes := make([]*pb.Scene, len(scenes))
+	for i, scene := range scenes {
+		protoScene, err := m.ToProto(scene)
+		if err != nil {
+			return nil, err
+		}
+		protoScenes[i] = protoScene
+	}
+	return protoScenes, nil
+}
+
+// ToDomain fails on malformed IDs. A scene without an ID gets uuid.Nil, and
+// steps without a feature ID are left for the service to reject.
+func (m *sceneMapper) ToDomain(scene *pb.Scene) (*models.Scene, error) {
+	if scene == nil {
+		return nil, errMissingInput
+	}
+
+	var id uuid.UUID
+	var err error
+	if scene.Id != "" {
+		if id, err = uuid.Parse(scene.Id); err != nil {
+			return nil, fmt.Errorf("id: %w", err)
+		}
+	}
+
+	steps := make([]models.SceneStep, len(scene.Steps))
+	for i, step := range scene.Steps {
+		var featureID uuid.UUID
+		if step.FeatureId != "" {
+			if featureID, err = uuid.Parse(step.FeatureId); err != nil {
+				return nil, fmt.Errorf("steps[%d].feature_id: %w", i, err)
+			}
+		}
+		steps[i] = models.SceneStep{
+			DeviceID:   step.DeviceId,
+			FeatureID:  featureID,
+			Parameters: step.Parameters.AsMap(),
+			Delay:      step.Delay.AsDuration(),
+			Group:      step.Group,
+		}
+	}
+	return &models.Scene{
+		ID:          id,
+		Name:        scene.Name,
+		Description: scene.Description,
+		Steps:       steps,
+		StopOnError: scene.StopOnError,
+	}, nil
+}
+
+func (m *sceneMapper) ToActivationProto(activation *models.SceneActivation) *pb.ActivateSceneResponse {
+	if activation == nil {
+		return nil
+	}
+
+	results := make([]*pb.StepResult, len(activation.Results))
+	for i, result := range activation.Results {
+		results[i] = &pb.StepResult{
+			Index:     int32(result.Index),
+			DeviceId:  result.DeviceID,
+			FeatureId: result.FeatureID.String(),
+			Status:    stepStatusToProto[result.Status],
+			Error:     result.Error,
+		}
+		if result.StartedAt != nil {
+			results[i].StartedAt = timestamppb.New(*result.StartedAt)
+		}
+		if result.FinishedAt != nil {
+			results[i].FinishedAt = timestamppb.New(*result.FinishedAt)
+		}
+	}
+	return &pb.ActivateSceneResponse{
+		SceneId:    activation.SceneID.String(),
+		Status:     activationStatusToProto[activation.Status],
+		Results:    results,
+		StartedAt:  timestamppb.New(activation.StartedAt),
+		FinishedAt: timestamppb.New(activation.FinishedAt),
+	}
+}
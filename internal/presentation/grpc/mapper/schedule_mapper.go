@@ -0,0 +1,142 @@
+package mapper
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "smart-hub/gen/proto/schedule/v1"
+	"smart-hub/internal/domain/models"
+)
+
+type ScheduleMapper interface {
+	ToProto(*models.Schedule) (*pb.Schedule, error)
+	ToProtoList([]*models.Schedule) ([]*pb.Schedule, error)
+	ToDomain(*pb.Schedule) (*models.Schedule, error)
+	ToRunProto(*models.ScheduleRun) *pb.ScheduleRun
+	ToRunProtoList([]*models.ScheduleRun) []*pb.ScheduleRun
+}
+
+type scheduleMapper struct{}
+
+func NewScheduleMapper() ScheduleMapper {
+	return &scheduleMapper{}
+}
+
+var runStatusToProto = map[models.ExecutionStatus]pb.RunStatus{
+	models.ExecutionRunning:   pb.RunStatus_RUNNING,
+	models.ExecutionSucceeded: pb.RunStatus_SUCCEEDED,
+	models.ExecutionFailed:    pb.RunStatus_FAILED,
+}
+
+func (m *scheduleMapper) ToProto(schedule *models.Schedule) (*pb.Schedule, error) {
+	if schedule == nil {
+		return nil, nil
+	}
+
+	parameters, err := structpb.NewStruct(schedule.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	protoSchedule := &pb.Schedule{
+		Id:          schedule.ID.String(),
+		Name:        schedule.Name,
+		Description: schedule.Description,
+		Timezone:    schedule.Timezone,
+		DeviceId:    schedule.DeviceID,
+		FeatureId:   schedule.FeatureID.String(),
+		Parameters:  parameters,
+		Paused:      schedule.Paused,
+		NextRunAt:   timestamppb.New(schedule.NextRunAt),
+		CreatedAt:   timestamppb.New(schedule.CreatedAt),
+		UpdatedAt:   timestamppb.New(schedule.UpdatedAt),
+	}
+	if schedule.Cron != "" {
+		protoSchedule.Timing = &pb.Schedule_Cron{Cron: schedule.Cron}
+	} else {
+		protoSchedule.Timing = &pb.Schedule_Interval{Interval: durationpb.New(schedule.Interval)}
+	}
+	if schedule.LastRunAt != nil {
+		protoSchedule.LastRunAt = timestamppb.New(*schedule.LastRunAt)
+	}
+	return protoSchedule, nil
+}
+
+func (m *scheduleMapper) ToProtoList(schedules []*models.Schedule) ([]*pb.Schedule, error) {
+	protoSchedules := make([]*pb.Schedule, len(schedules))
+	for i, schedule := range schedules {
+		protoSchedule, err := m.ToProto(schedule)
+		if err != nil {
+			return nil, err
+		}
+		protoSchedules[i] = protoSchedule
+	}
+	return protoSchedules, nil
+}
+
+// ToDomain fails on malformed IDs. A schedule without an ID gets uuid.Nil,
+// and one without a feature ID or timing is left for the service to reject.
+// The output only fields are ignored.
+func (m *scheduleMapper) ToDomain(schedule *pb.Schedule) (*models.Schedule, error) {
+	if schedule == nil {
+		return nil, errMissingInput
+	}
+
+	var id, featureID uuid.UUID
+	var err error
+	if schedule.Id != "" {
+		if id, err = uuid.Parse(schedule.Id); err != nil {
+			return nil, fmt.Errorf("id: %w", err)
+		}
+	}
+	if schedule.FeatureId != "" {
+		if featureID, err = uuid.Parse(schedule.FeatureId); err != nil {
+			return nil, fmt.Errorf("feature_id: %w", err)
+		}
+	}
+
+	domainSchedule := &models.Schedule{
+		ID:          id,
+		Name:        schedule.Name,
+		Description: schedule.Description,
+		Timezone:    schedule.Timezone,
+		DeviceID:    schedule.DeviceId,
+		FeatureID:   featureID,
+		Parameters:  schedule.Parameters.AsMap(),
+	}
+	switch timing := schedule.Timing.(type) {
+	case *pb.Schedule_Cron:
+		domainSchedule.Cron = timing.Cron
+	case *pb.Schedule_Interval:
+		domainSchedule.Interval = timing.Interval.AsDuration()
+	}
+	return domainSchedule, nil
+}
+
+func (m *scheduleMapper) ToRunProto(run *models.ScheduleRun) *pb.ScheduleRun {
+	if run == nil {
+		return nil
+	}
+
+	protoRun := &pb.ScheduleRun{
+		Id:          run.ID.String(),
+		ScheduleId:  run.ScheduleID.String(),
+		ScheduledAt: timestamppb.New(run.ScheduledAt),
+		Status:      runStatusToProto[run.Status],
+		Error:       run.Error,
+		StartedAt:   timestamppb.New(run.StartedAt),
+	}
+	if run.FinishedAt != nil {
+		protoRun.FinishedAt = timestamppb.New(*run.FinishedAt)
+	}
+	return protoRun
+}
+
+func (m *scheduleMapper) ToRunProtoList(runs []*models.ScheduleRun) []*pb.ScheduleRun {
+	protoRuns := make([]*pb.ScheduleRun, len(runs))
+	for i, run := range runs {
+		protoRuns[i] = m.ToRunProto(run)
+	}
+	return protoRuns
+}
@@ -0,0 +1,73 @@
+package mapper
+
+import (
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "smart-hub/gen/proto/shadow/v1"
+	"smart-hub/internal/domain/models"
+)
+
+type ShadowMapper interface {
+	ToProto(*models.DeviceShadow) (*pb.DeviceShadow, error)
+	ToDomainState(*structpb.Struct) map[string]interface{}
+	ToWatchResponse(*models.ShadowDeltaEvent) (*pb.WatchShadowDeltasResponse, error)
+}
+
+type shadowMapper struct{}
+
+func NewShadowMapper() ShadowMapper {
+	return &shadowMapper{}
+}
+
+func (m *shadowMapper) ToProto(shadow *models.DeviceShadow) (*pb.DeviceShadow, error) {
+	if shadow == nil {
+		return nil, nil
+	}
+
+	desired, err := structpb.NewStruct(shadow.Desired)
+	if err != nil {
+		return nil, err
+	}
+	reported, err := structpb.NewStruct(shadow.Reported)
+	if err != nil {
+		return nil, err
+	}
+	delta, err := structpb.NewStruct(shadow.Delta())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeviceShadow{
+		DeviceId:  shadow.DeviceID,
+		Desired:   desired,
+		Reported:  reported,
+		Delta:     delta,
+		Version:   shadow.Version,
+		CreatedAt: timestamppb.New(shadow.CreatedAt),
+		UpdatedAt: timestamppb.New(shadow.UpdatedAt),
+	}, nil
+}
+
+// ToDomainState keeps null values as nil, which the service reads as a
+// removal.
+func (m *shadowMapper) ToDomainState(state *structpb.Struct) map[string]interface{} {
+	if state == nil {
+		return map[string]interface{}{}
+	}
+	return state.AsMap()
+}
+
+func (m *shadowMapper) ToWatchResponse(event *models.ShadowDeltaEvent) (*pb.WatchShadowDeltasResponse, error) {
+	delta, err := structpb.NewStruct(event.Delta.Delta)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.WatchShadowDeltasResponse{
+		DeviceId:    event.Delta.DeviceID,
+		Version:     event.Delta.Version,
+		Delta:       delta,
+		UpdatedAt:   timestamppb.New(event.Delta.CreatedAt),
+		ResumeToken: event.ResumeToken,
+	}, nil
+}
@@ -1,11 +1,14 @@
 package mapper
 
 import (
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	pb "smart-hub/gen/proto/smart_feature/v1"
 	"smart-hub/internal/domain/models"
+	"strings"
 	"time"
 )
 
@@ -13,10 +16,18 @@ type SmartFeatureMapper interface {
 	ToProto(*models.SmartFeature) (*pb.SmartFeature, error)
 	ToDomain(*pb.CreateSmartFeatureRequest) (*models.SmartFeature, error)
 	ToDomainUpdate(*pb.UpdateSmartFeatureRequest) (*models.SmartFeature, error)
+	ToDomainInput(*pb.CreateSmartFeatureInput) (*models.SmartFeature, error)
+	ToDomainUpdateInput(*pb.UpdateSmartFeatureInput) (*models.SmartFeature, error)
 	ToCreateResponse(*models.SmartFeature) (*pb.CreateSmartFeatureResponse, error)
 	ToGetResponse(*models.SmartFeature) (*pb.GetSmartFeatureResponse, error)
 	ToListResponse([]*models.SmartFeature) (*pb.GetFeaturesByModelIDResponse, error)
 	ToUpdateResponse(*models.SmartFeature) (*pb.UpdateSmartFeatureResponse, error)
+	ToWatchResponse(*models.WatchEvent) (*pb.WatchSmartFeaturesResponse, error)
+	ToListFilter(*pb.ListSmartFeaturesRequest) models.FeatureFilter
+	ToWatchFilter(*pb.WatchSmartFeaturesRequest) (models.FeatureWatchFilter, error)
+	ToDependenciesProto(*models.FeatureDependencies) *pb.FeatureDependencies
+	ToDependenciesDomain(*pb.FeatureDependencies) (*models.FeatureDependencies, error)
+	ToResolvedProto([]*models.ResolvedFeature) ([]*pb.ResolvedFeature, error)
 }
 
 type smartFeatureMapper struct{}
@@ -49,13 +60,20 @@ func (m *smartFeatureMapper) ToProto(model *models.SmartFeature) (*pb.SmartFeatu
 }
 
 func (m *smartFeatureMapper) ToDomain(req *pb.CreateSmartFeatureRequest) (*models.SmartFeature, error) {
+	return m.ToDomainInput(req.GetFeature())
+}
+
+func (m *smartFeatureMapper) ToDomainInput(input *pb.CreateSmartFeatureInput) (*models.SmartFeature, error) {
+	if input == nil {
+		return nil, errors.New("feature is required")
+	}
 
 	parameters := make(map[string]interface{})
-	if req.Feature.Parameters != nil {
-		parameters = req.Feature.Parameters.AsMap()
+	if input.Parameters != nil {
+		parameters = input.Parameters.AsMap()
 	}
 
-	modelId, err := uuid.Parse(req.Feature.ModelId)
+	modelId, err := uuid.Parse(input.ModelId)
 	if err != nil {
 		return nil, err
 	}
@@ -65,11 +83,11 @@ func (m *smartFeatureMapper) ToDomain(req *pb.CreateSmartFeatureRequest) (*model
 
 	return &models.SmartFeature{
 		ID:            id,
-		Name:          req.Feature.Name,
+		Name:          input.Name,
 		ModelID:       modelId,
-		Description:   req.Feature.Description,
-		Protocol:      mapProtoProtocolToDomain(req.Feature.Protocol),
-		InterfacePath: req.Feature.InterfacePath,
+		Description:   input.Description,
+		Protocol:      mapProtoProtocolToDomain(input.Protocol),
+		InterfacePath: input.InterfacePath,
 		Parameters:    parameters,
 		CreatedAt:     now,
 		UpdatedAt:     now,
@@ -80,23 +98,30 @@ func (m *smartFeatureMapper) ToDomainUpdate(req *pb.UpdateSmartFeatureRequest) (
 	if req == nil || req.Feature == nil {
 		return nil, nil
 	}
+	return m.ToDomainUpdateInput(req.Feature)
+}
+
+func (m *smartFeatureMapper) ToDomainUpdateInput(input *pb.UpdateSmartFeatureInput) (*models.SmartFeature, error) {
+	if input == nil {
+		return nil, errors.New("feature is required")
+	}
 
 	parameters := make(map[string]interface{})
-	if req.Feature.Parameters != nil {
-		parameters = req.Feature.Parameters.AsMap()
+	if input.Parameters != nil {
+		parameters = input.Parameters.AsMap()
 	}
 
-	id, err := uuid.Parse(req.Feature.Id)
+	id, err := uuid.Parse(input.Id)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.SmartFeature{
 		ID:            id,
-		Name:          req.Feature.Name,
-		Description:   req.Feature.Description,
-		Protocol:      mapProtoProtocolToDomain(req.Feature.Protocol),
-		InterfacePath: req.Feature.InterfacePath,
+		Name:          input.Name,
+		Description:   input.Description,
+		Protocol:      mapProtoProtocolToDomain(input.Protocol),
+		InterfacePath: input.InterfacePath,
 		Parameters:    parameters,
 	}, nil
 }
@@ -149,6 +174,128 @@ func (m *smartFeatureMapper) ToUpdateResponse(model *models.SmartFeature) (*pb.U
 	}, nil
 }
 
+func (m *smartFeatureMapper) ToWatchResponse(event *models.WatchEvent) (*pb.WatchSmartFeaturesResponse, error) {
+	protoFeature, err := m.ToProto(event.Feature)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.WatchSmartFeaturesResponse{
+		ChangeType:  mapDomainChangeTypeToFeatureProto(event.Operation),
+		Feature:     protoFeature,
+		ResumeToken: event.ResumeToken,
+	}, nil
+}
+
+func (m *smartFeatureMapper) ToListFilter(req *pb.ListSmartFeaturesRequest) models.FeatureFilter {
+	filter := models.FeatureFilter{
+		NamePrefix:           req.NamePrefix,
+		InterfacePathPattern: req.InterfacePathPattern,
+	}
+	if req.Protocol != nil {
+		protocol := mapProtoProtocolToDomain(req.GetProtocol())
+		filter.Protocol = &protocol
+	}
+	if req.ModelType != nil {
+		modelType := models.ModelType(strings.ToLower(req.GetModelType().String()))
+		filter.ModelType = &modelType
+	}
+	if req.ModelCategory != nil {
+		category := models.ModelCategory(strings.ToLower(req.GetModelCategory().String()))
+		filter.ModelCategory = &category
+	}
+	return filter
+}
+
+// ToWatchFilter fails on a malformed model_id.
+func (m *smartFeatureMapper) ToWatchFilter(req *pb.WatchSmartFeaturesRequest) (models.FeatureWatchFilter, error) {
+	var filter models.FeatureWatchFilter
+	if req.ModelId != "" {
+		modelID, err := uuid.Parse(req.ModelId)
+		if err != nil {
+			return models.FeatureWatchFilter{}, fmt.Errorf("invalid model_id: %w", err)
+		}
+		filter.ModelID = &modelID
+	}
+	if req.ModelType != nil {
+		modelType := models.ModelType(strings.ToLower(req.GetModelType().String()))
+		filter.ModelType = &modelType
+	}
+	if req.ModelCategory != nil {
+		category := models.ModelCategory(strings.ToLower(req.GetModelCategory().String()))
+		filter.ModelCategory = &category
+	}
+	return filter, nil
+}
+
+func (m *smartFeatureMapper) ToDependenciesProto(dependencies *models.FeatureDependencies) *pb.FeatureDependencies {
+	if dependencies == nil {
+		return nil
+	}
+	return &pb.FeatureDependencies{
+		FeatureId: dependencies.FeatureID.String(),
+		Requires:  uuidStrings(dependencies.Requires),
+		Conflicts: uuidStrings(dependencies.Conflicts),
+	}
+}
+
+// ToDependenciesDomain fails on malformed IDs. A missing feature_id becomes
+// uuid.Nil and is left for the service to reject.
+func (m *smartFeatureMapper) ToDependenciesDomain(dependencies *pb.FeatureDependencies) (*models.FeatureDependencies, error) {
+	if dependencies == nil {
+		return nil, errMissingInput
+	}
+
+	result := &models.FeatureDependencies{}
+	if dependencies.FeatureId != "" {
+		id, err := uuid.Parse(dependencies.FeatureId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid feature_id: %w", err)
+		}
+		result.FeatureID = id
+	}
+	var err error
+	if result.Requires, err = parseUUIDs("requires", dependencies.Requires); err != nil {
+		return nil, err
+	}
+	if result.Conflicts, err = parseUUIDs("conflicts", dependencies.Conflicts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (m *smartFeatureMapper) ToResolvedProto(resolved []*models.ResolvedFeature) ([]*pb.ResolvedFeature, error) {
+	protoResolved := make([]*pb.ResolvedFeature, len(resolved))
+	for i, r := range resolved {
+		feature, err := m.ToProto(r.Feature)
+		if err != nil {
+			return nil, err
+		}
+		protoResolved[i] = &pb.ResolvedFeature{Feature: feature, Requested: r.Requested}
+	}
+	return protoResolved, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	return strs
+}
+
+func parseUUIDs(field string, strs []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, len(strs))
+	for i, str := range strs {
+		id, err := uuid.Parse(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s[%d]: %w", field, i, err)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
 func mapProtoProtocolToDomain(p pb.ProtocolType) models.ProtocolType {
 	switch p {
 	case pb.ProtocolType_REST:
@@ -178,3 +325,18 @@ func mapDomainProtocolToProto(p models.ProtocolType) pb.ProtocolType {
 		return pb.ProtocolType_REST
 	}
 }
+
+func mapDomainChangeTypeToFeatureProto(o models.ChangeOperation) pb.ChangeType {
+	switch o {
+	case models.ChangeCreated:
+		return pb.ChangeType_CREATED
+	case models.ChangeUpdated:
+		return pb.ChangeType_UPDATED
+	case models.ChangeDeleted:
+		return pb.ChangeType_DELETED
+	case models.ChangeSynced:
+		return pb.ChangeType_SYNCED
+	default:
+		return pb.ChangeType_EXISTING
+	}
+}
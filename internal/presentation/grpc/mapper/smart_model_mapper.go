@@ -18,6 +18,8 @@ type SmartModelMapper interface {
 	ToGetResponse(*models.SmartModel) (*pb.GetSmartModelResponse, error)
 	ToListResponse([]*models.SmartModel) (*pb.ListSmartModelsResponse, error)
 	ToUpdateResponse(*models.SmartModel) (*pb.UpdateSmartModelResponse, error)
+	ToWatchFilter(*pb.WatchSmartModelsRequest) models.ModelWatchFilter
+	ToWatchResponse(*models.WatchEvent) (*pb.WatchSmartModelsResponse, error)
 }
 
 type smartModelMapper struct{}
@@ -36,6 +38,11 @@ func (m *smartModelMapper) ToProto(model *models.SmartModel) (*pb.SmartModel, er
 		return nil, err
 	}
 
+	features, err := mapDomainFeaturesToModelProto(model.Features)
+	if err != nil {
+		return nil, err
+	}
+
 	return &pb.SmartModel{
 		Id:           model.ID.String(),
 		Name:         model.Name,
@@ -47,6 +54,7 @@ func (m *smartModelMapper) ToProto(model *models.SmartModel) (*pb.SmartModel, er
 		Metadata:     metadata,
 		CreatedAt:    timestamppb.New(model.CreatedAt),
 		UpdatedAt:    timestamppb.New(model.UpdatedAt),
+		Features:     features,
 	}, nil
 }
 
@@ -108,6 +116,25 @@ func (m *smartModelMapper) ToDomain(req *pb.CreateSmartModelRequest) (*models.Sm
 
 	now := time.Now()
 
+	var features []*models.SmartFeature
+	for _, input := range req.Model.Features {
+		parameters := make(map[string]interface{})
+		if input.Parameters != nil {
+			parameters = input.Parameters.AsMap()
+		}
+		features = append(features, &models.SmartFeature{
+			ID:            uuid.New(),
+			ModelID:       id,
+			Name:          input.Name,
+			Description:   input.Description,
+			Protocol:      mapModelProtoProtocolToDomain(input.Protocol),
+			InterfacePath: input.InterfacePath,
+			Parameters:    parameters,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+
 	return &models.SmartModel{
 		ID:           id,
 		Name:         req.Model.Name,
@@ -119,6 +146,7 @@ func (m *smartModelMapper) ToDomain(req *pb.CreateSmartModelRequest) (*models.Sm
 		Metadata:     metadata,
 		CreatedAt:    now,
 		UpdatedAt:    now,
+		Features:     features,
 	}, nil
 }
 
@@ -170,6 +198,32 @@ func (m *smartModelMapper) ToUpdateResponse(model *models.SmartModel) (*pb.Updat
 	}, nil
 }
 
+func (m *smartModelMapper) ToWatchFilter(req *pb.WatchSmartModelsRequest) models.ModelWatchFilter {
+	var filter models.ModelWatchFilter
+	if req.Type != nil {
+		modelType := mapProtoTypeToDomain(req.GetType())
+		filter.Type = &modelType
+	}
+	if req.Category != nil {
+		category := mapProtoCategoryToDomain(req.GetCategory())
+		filter.Category = &category
+	}
+	return filter
+}
+
+func (m *smartModelMapper) ToWatchResponse(event *models.WatchEvent) (*pb.WatchSmartModelsResponse, error) {
+	protoModel, err := m.ToProto(event.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.WatchSmartModelsResponse{
+		ChangeType:  mapDomainChangeTypeToModelProto(event.Operation),
+		Model:       protoModel,
+		ResumeToken: event.ResumeToken,
+	}, nil
+}
+
 func mapProtoTypeToDomain(t pb.ModelType) models.ModelType {
 	switch t {
 	case pb.ModelType_DEVICE:
@@ -221,3 +275,70 @@ func mapDomainCategoryToProto(c models.ModelCategory) pb.ModelCategory {
 		return pb.ModelCategory_WEARABLE
 	}
 }
+
+func mapDomainChangeTypeToModelProto(o models.ChangeOperation) pb.ChangeType {
+	switch o {
+	case models.ChangeCreated:
+		return pb.ChangeType_CREATED
+	case models.ChangeUpdated:
+		return pb.ChangeType_UPDATED
+	case models.ChangeDeleted:
+		return pb.ChangeType_DELETED
+	case models.ChangeSynced:
+		return pb.ChangeType_SYNCED
+	default:
+		return pb.ChangeType_EXISTING
+	}
+}
+
+func mapDomainFeaturesToModelProto(features []*models.SmartFeature) ([]*pb.SmartFeature, error) {
+	var protoFeatures []*pb.SmartFeature
+	for _, feature := range features {
+		parameters, err := structpb.NewStruct(feature.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		protoFeatures = append(protoFeatures, &pb.SmartFeature{
+			Id:            feature.ID.String(),
+			ModelId:       feature.ModelID.String(),
+			Name:          feature.Name,
+			Description:   feature.Description,
+			Protocol:      mapDomainProtocolToModelProto(feature.Protocol),
+			InterfacePath: feature.InterfacePath,
+			Parameters:    parameters,
+			CreatedAt:     timestamppb.New(feature.CreatedAt),
+			UpdatedAt:     timestamppb.New(feature.UpdatedAt),
+		})
+	}
+	return protoFeatures, nil
+}
+
+func mapModelProtoProtocolToDomain(p pb.ProtocolType) models.ProtocolType {
+	switch p {
+	case pb.ProtocolType_REST:
+		return models.RestProtocol
+	case pb.ProtocolType_GRPC:
+		return models.GrpcProtocol
+	case pb.ProtocolType_MQTT:
+		return models.MqttProtocol
+	case pb.ProtocolType_WEBSOCKET:
+		return models.WebsocketProtocol
+	default:
+		return models.RestProtocol
+	}
+}
+
+func mapDomainProtocolToModelProto(p models.ProtocolType) pb.ProtocolType {
+	switch p {
+	case models.RestProtocol:
+		return pb.ProtocolType_REST
+	case models.GrpcProtocol:
+		return pb.ProtocolType_GRPC
+	case models.MqttProtocol:
+		return pb.ProtocolType_MQTT
+	case models.WebsocketProtocol:
+		return pb.ProtocolType_WEBSOCKET
+	default:
+		return pb.ProtocolType_REST
+	}
+}
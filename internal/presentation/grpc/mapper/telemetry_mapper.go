@@ -0,0 +1,126 @@
+package mapper
+
+import (
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "smart-hub/gen/proto/telemetry/v1"
+	"smart-hub/internal/domain/models"
+	"time"
+)
+
+type TelemetryMapper interface {
+	ToDomainReadings([]*pb.Reading) []*models.TelemetryReading
+	ToIngestResponse(*models.IngestReport) *pb.IngestReadingsResponse
+	ToDomainQuery(*pb.QueryReadingsRequest) (models.ReadingQuery, time.Duration, error)
+	ToQueryResponse(*models.ReadingSeries) *pb.QueryReadingsResponse
+	ToPolicyProto(*models.RetentionPolicy) *pb.RetentionPolicy
+	ToPolicyProtoList([]*models.RetentionPolicy) []*pb.RetentionPolicy
+}
+
+type telemetryMapper struct{}
+
+func NewTelemetryMapper() TelemetryMapper {
+	return &telemetryMapper{}
+}
+
+// ToDomainReadings never fails, so that one bad reading does not end an
+// ingestion: a malformed feature ID becomes uuid.Nil and a missing timestamp
+// the zero time, which the service rejects.
+func (m *telemetryMapper) ToDomainReadings(readings []*pb.Reading) []*models.TelemetryReading {
+	domainReadings := make([]*models.TelemetryReading, len(readings))
+	for i, reading := range readings {
+		featureID, _ := uuid.Parse(reading.GetFeatureId())
+		domainReading := &models.TelemetryReading{
+			DeviceID:  reading.GetDeviceId(),
+			FeatureID: featureID,
+			Value:     reading.GetValue(),
+		}
+		if reading.GetTimestamp() != nil {
+			domainReading.Timestamp = reading.Timestamp.AsTime()
+		}
+		domainReadings[i] = domainReading
+	}
+	return domainReadings
+}
+
+func (m *telemetryMapper) ToIngestResponse(report *models.IngestReport) *pb.IngestReadingsResponse {
+	rejections := make([]*pb.RejectedReading, len(report.Rejections))
+	for i, rejection := range report.Rejections {
+		rejections[i] = &pb.RejectedReading{Index: rejection.Index, Reason: rejection.Reason}
+	}
+
+	return &pb.IngestReadingsResponse{
+		Received:   report.Received,
+		Stored:     report.Stored,
+		Duplicates: report.Duplicates,
+		Rejected:   report.Rejected,
+		Rejections: rejections,
+	}
+}
+
+func (m *telemetryMapper) ToDomainQuery(req *pb.QueryReadingsRequest) (models.ReadingQuery, time.Duration, error) {
+	featureID, err := uuid.Parse(req.GetFeatureId())
+	if err != nil {
+		return models.ReadingQuery{}, 0, err
+	}
+
+	query := models.ReadingQuery{
+		FeatureID: featureID,
+		DeviceID:  req.DeviceId,
+		Limit:     int(req.Limit),
+	}
+	if req.Start != nil {
+		query.From = req.Start.AsTime()
+	}
+	if req.End != nil {
+		query.To = req.End.AsTime()
+	}
+	var bucket time.Duration
+	if req.Bucket != nil {
+		bucket = req.Bucket.AsDuration()
+	}
+	return query, bucket, nil
+}
+
+func (m *telemetryMapper) ToQueryResponse(series *models.ReadingSeries) *pb.QueryReadingsResponse {
+	resp := &pb.QueryReadingsResponse{Truncated: series.Truncated}
+	for _, reading := range series.Readings {
+		resp.Readings = append(resp.Readings, &pb.Reading{
+			DeviceId:  reading.DeviceID,
+			FeatureId: reading.FeatureID.String(),
+			Timestamp: timestamppb.New(reading.Timestamp),
+			Value:     reading.Value,
+		})
+	}
+	for _, bucket := range series.Buckets {
+		resp.Buckets = append(resp.Buckets, &pb.Bucket{
+			Start: timestamppb.New(bucket.Start),
+			Count: bucket.Count,
+			Avg:   bucket.Avg,
+			Min:   bucket.Min,
+			Max:   bucket.Max,
+		})
+	}
+	return resp
+}
+
+func (m *telemetryMapper) ToPolicyProto(policy *models.RetentionPolicy) *pb.RetentionPolicy {
+	if policy == nil {
+		return nil
+	}
+
+	return &pb.RetentionPolicy{
+		FeatureId: policy.FeatureID.String(),
+		Retention: durationpb.New(policy.Retention),
+		UpdatedAt: timestamppb.New(policy.UpdatedAt),
+	}
+}
+
+func (m *telemetryMapper) ToPolicyProtoList(policies []*models.RetentionPolicy) []*pb.RetentionPolicy {
+	protoPolicies := make([]*pb.RetentionPolicy, len(policies))
+	for i, policy := range policies {
+		protoPolicies[i] = m.ToPolicyProto(policy)
+	}
+	return protoPolicies
+}
@@ -0,0 +1,153 @@
+package mapper
+
+import (
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "smart-hub/gen/proto/webhook/v1"
+	"smart-hub/internal/domain/models"
+)
+
+type WebhookMapper interface {
+	ToProto(*models.WebhookSubscription) *pb.Webhook
+	ToProtoList([]*models.WebhookSubscription) []*pb.Webhook
+	ToDomain(*pb.CreateWebhookRequest) (*models.WebhookSubscription, error)
+	ToDomainUpdate(*pb.UpdateWebhookRequest) (*models.WebhookSubscription, error)
+	ToDeliveryProto(*models.WebhookDelivery) *pb.WebhookDelivery
+	ToDeliveryProtoList([]*models.WebhookDelivery) []*pb.WebhookDelivery
+	ToDeliveryStatusDomain(pb.DeliveryStatus) models.DeliveryStatus
+}
+
+type webhookMapper struct{}
+
+func NewWebhookMapper() WebhookMapper {
+	return &webhookMapper{}
+}
+
+// ToProto leaves out the secret, which is only handed out on creation.
+func (m *webhookMapper) ToProto(subscription *models.WebhookSubscription) *pb.Webhook {
+	if subscription == nil {
+		return nil
+	}
+
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	return &pb.Webhook{
+		Id:          subscription.ID.String(),
+		Url:         subscription.URL,
+		EventTypes:  eventTypes,
+		Description: subscription.Description,
+		Active:      subscription.Active,
+		CreatedAt:   timestamppb.New(subscription.CreatedAt),
+		UpdatedAt:   timestamppb.New(subscription.UpdatedAt),
+	}
+}
+
+func (m *webhookMapper) ToProtoList(subscriptions []*models.WebhookSubscription) []*pb.Webhook {
+	protoWebhooks := make([]*pb.Webhook, len(subscriptions))
+	for i, subscription := range subscriptions {
+		protoWebhooks[i] = m.ToProto(subscription)
+	}
+	return protoWebhooks
+}
+
+func (m *webhookMapper) ToDomain(req *pb.CreateWebhookRequest) (*models.WebhookSubscription, error) {
+	if req == nil || req.Webhook == nil {
+		return nil, errMissingInput
+	}
+
+	return &models.WebhookSubscription{
+		ID:          uuid.New(),
+		URL:         req.Webhook.Url,
+		EventTypes:  toEventTypes(req.Webhook.EventTypes),
+		Secret:      req.Webhook.Secret,
+		Description: req.Webhook.Description,
+		Active:      true,
+	}, nil
+}
+
+func (m *webhookMapper) ToDomainUpdate(req *pb.UpdateWebhookRequest) (*models.WebhookSubscription, error) {
+	if req == nil || req.Webhook == nil {
+		return nil, errMissingInput
+	}
+
+	id, err := uuid.Parse(req.Webhook.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookSubscription{
+		ID:          id,
+		URL:         req.Webhook.Url,
+		EventTypes:  toEventTypes(req.Webhook.EventTypes),
+		Description: req.Webhook.Description,
+		Active:      req.Webhook.Active,
+	}, nil
+}
+
+func (m *webhookMapper) ToDeliveryProto(delivery *models.WebhookDelivery) *pb.WebhookDelivery {
+	if delivery == nil {
+		return nil
+	}
+
+	protoDelivery := &pb.WebhookDelivery{
+		Id:             delivery.ID.String(),
+		WebhookId:      delivery.SubscriptionID.String(),
+		EventId:        delivery.EventID.String(),
+		EventType:      string(delivery.EventType),
+		Status:         mapDomainDeliveryStatusToProto(delivery.Status),
+		Attempts:       int32(delivery.Attempts),
+		LastStatusCode: int32(delivery.LastStatusCode),
+		LastError:      delivery.LastError,
+		NextAttemptAt:  timestamppb.New(delivery.NextAttemptAt),
+		CreatedAt:      timestamppb.New(delivery.CreatedAt),
+	}
+	if delivery.DeliveredAt != nil {
+		protoDelivery.DeliveredAt = timestamppb.New(*delivery.DeliveredAt)
+	}
+	return protoDelivery
+}
+
+func (m *webhookMapper) ToDeliveryProtoList(deliveries []*models.WebhookDelivery) []*pb.WebhookDelivery {
+	protoDeliveries := make([]*pb.WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		protoDeliveries[i] = m.ToDeliveryProto(delivery)
+	}
+	return protoDeliveries
+}
+
+func (m *webhookMapper) ToDeliveryStatusDomain(s pb.DeliveryStatus) models.DeliveryStatus {
+	switch s {
+	case pb.DeliveryStatus_SUCCEEDED:
+		return models.DeliverySucceeded
+	case pb.DeliveryStatus_FAILED:
+		return models.DeliveryFailed
+	case pb.DeliveryStatus_DEAD:
+		return models.DeliveryDead
+	default:
+		return models.DeliveryPending
+	}
+}
+
+func toEventTypes(values []string) []models.EventType {
+	eventTypes := make([]models.EventType, 0, len(values))
+	for _, value := range values {
+		eventTypes = append(eventTypes, models.EventType(value))
+	}
+	return eventTypes
+}
+
+func mapDomainDeliveryStatusToProto(s models.DeliveryStatus) pb.DeliveryStatus {
+	switch s {
+	case models.DeliverySucceeded:
+		return pb.DeliveryStatus_SUCCEEDED
+	case models.DeliveryFailed:
+		return pb.DeliveryStatus_FAILED
+	case models.DeliveryDead:
+		return pb.DeliveryStatus_DEAD
+	default:
+		return pb.DeliveryStatus_PENDING
+	}
+}
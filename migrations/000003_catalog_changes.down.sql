@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS smart_features_catalog_change ON smart_features;
+DROP TRIGGER IF EXISTS smart_models_catalog_change ON smart_models;
+DROP FUNCTION IF EXISTS record_catalog_change();
+DROP TABLE IF EXISTS catalog_changes;
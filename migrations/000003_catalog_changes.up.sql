@@ -0,0 +1,64 @@
+CREATE TABLE catalog_changes (
+    sequence BIGSERIAL PRIMARY KEY,
+    entity VARCHAR(50) NOT NULL,
+    operation VARCHAR(20) NOT NULL,
+    entity_id UUID NOT NULL,
+    model_id UUID NOT NULL,
+    old_row JSONB,
+    new_row JSONB,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_catalog_changes_changed_at ON catalog_changes(changed_at);
+
+-- Records every row change of smart_models and smart_features in
+-- catalog_changes and wakes up listeners with the new sequence.
+CREATE OR REPLACE FUNCTION record_catalog_change() RETURNS TRIGGER AS $$
+DECLARE
+    change_entity TEXT;
+    change_operation TEXT;
+    change_entity_id UUID;
+    change_model_id UUID;
+    change_old JSONB;
+    change_new JSONB;
+    change_sequence BIGINT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        change_operation := 'created';
+        change_new := to_jsonb(NEW);
+        change_entity_id := NEW.id;
+    ELSIF TG_OP = 'UPDATE' THEN
+        change_operation := 'updated';
+        change_old := to_jsonb(OLD);
+        change_new := to_jsonb(NEW);
+        change_entity_id := NEW.id;
+    ELSE
+        change_operation := 'deleted';
+        change_old := to_jsonb(OLD);
+        change_entity_id := OLD.id;
+    END IF;
+
+    IF TG_TABLE_NAME = 'smart_models' THEN
+        change_entity := 'smart_model';
+        change_model_id := change_entity_id;
+    ELSE
+        change_entity := 'smart_feature';
+        change_model_id := COALESCE(change_new, change_old)->>'model_id';
+    END IF;
+
+    INSERT INTO catalog_changes (entity, operation, entity_id, model_id, old_row, new_row)
+    VALUES (change_entity, change_operation, change_entity_id, change_model_id, change_old, change_new)
+    RETURNING sequence INTO change_sequence;
+
+    PERFORM pg_notify('catalog_changes', change_sequence::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER smart_models_catalog_change
+    AFTER INSERT OR UPDATE OR DELETE ON smart_models
+    FOR EACH ROW EXECUTE FUNCTION record_catalog_change();
+
+CREATE TRIGGER smart_features_catalog_change
+    AFTER INSERT OR UPDATE OR DELETE ON smart_features
+    FOR EACH ROW EXECUTE FUNCTION record_catalog_change();
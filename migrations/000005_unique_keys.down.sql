@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uq_smart_features_name_path;
+DROP INDEX IF EXISTS uq_smart_models_model_number;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS telemetry_retention_policies;
+DROP TABLE IF EXISTS telemetry_readings;
@@ -0,0 +1,18 @@
+-- Readings are partitioned by day. The partitions are created by the
+-- application as readings arrive, and whole partitions are dropped once they
+-- fall out of the retention window.
+CREATE TABLE telemetry_readings (
+    device_id VARCHAR(255) NOT NULL,
+    feature_id UUID NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (feature_id, device_id, recorded_at)
+) PARTITION BY RANGE (recorded_at);
+
+CREATE INDEX idx_telemetry_readings_feature_time ON telemetry_readings(feature_id, recorded_at);
+
+CREATE TABLE telemetry_retention_policies (
+    feature_id UUID PRIMARY KEY REFERENCES smart_features(id) ON DELETE CASCADE,
+    retention_seconds BIGINT NOT NULL CHECK (retention_seconds > 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
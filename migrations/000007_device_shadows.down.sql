@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS device_shadow_deltas_notify ON device_shadow_deltas;
+DROP FUNCTION IF EXISTS notify_shadow_delta();
+DROP TABLE IF EXISTS device_shadow_deltas;
+DROP TABLE IF EXISTS device_shadows;
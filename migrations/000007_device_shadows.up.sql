@@ -0,0 +1,31 @@
+CREATE TABLE device_shadows (
+    device_id VARCHAR(255) PRIMARY KEY,
+    desired JSONB NOT NULL DEFAULT '{}',
+    reported JSONB NOT NULL DEFAULT '{}',
+    version BIGINT NOT NULL CHECK (version > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE device_shadow_deltas (
+    sequence BIGSERIAL PRIMARY KEY,
+    device_id VARCHAR(255) NOT NULL,
+    version BIGINT NOT NULL,
+    delta JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_device_shadow_deltas_created_at ON device_shadow_deltas(created_at);
+
+-- Wakes up delta watchers with the new sequence, like record_catalog_change
+-- does for the catalog.
+CREATE OR REPLACE FUNCTION notify_shadow_delta() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('device_shadow_deltas', NEW.sequence::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER device_shadow_deltas_notify
+    AFTER INSERT ON device_shadow_deltas
+    FOR EACH ROW EXECUTE FUNCTION notify_shadow_delta();
@@ -0,0 +1,32 @@
+-- Triggers and actions are kept as JSON: their shape depends on the trigger
+-- type and they are only ever read together with the rule.
+CREATE TABLE automation_rules (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    trigger JSONB NOT NULL,
+    condition TEXT NOT NULL DEFAULT '',
+    actions JSONB NOT NULL DEFAULT '[]',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    cooldown_seconds BIGINT NOT NULL DEFAULT 0 CHECK (cooldown_seconds >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE automation_executions (
+    id UUID PRIMARY KEY,
+    rule_id UUID NOT NULL REFERENCES automation_rules(id) ON DELETE CASCADE,
+    trigger JSONB NOT NULL DEFAULT '{}',
+    dedup_key VARCHAR(255),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    error TEXT,
+    action_results JSONB NOT NULL DEFAULT '[]',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Replicas claim a schedule tick by inserting its execution first.
+CREATE UNIQUE INDEX uq_automation_executions_dedup_key ON automation_executions(dedup_key) WHERE dedup_key IS NOT NULL;
+CREATE INDEX idx_automation_executions_started ON automation_executions(started_at DESC, id DESC);
+CREATE INDEX idx_automation_executions_rule ON automation_executions(rule_id, started_at DESC, id DESC);
@@ -0,0 +1,33 @@
+-- A schedule has either a cron expression or an interval.
+CREATE TABLE schedules (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    cron VARCHAR(255),
+    interval_seconds BIGINT CHECK (interval_seconds > 0),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    device_id VARCHAR(255) NOT NULL,
+    feature_id UUID NOT NULL,
+    parameters JSONB NOT NULL DEFAULT '{}',
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((cron IS NULL) <> (interval_seconds IS NULL))
+);
+
+CREATE INDEX idx_schedules_due ON schedules(next_run_at, id) WHERE NOT paused;
+
+CREATE TABLE schedule_runs (
+    id UUID PRIMARY KEY,
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    scheduled_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_schedule_runs_started ON schedule_runs(started_at DESC, id DESC);
+CREATE INDEX idx_schedule_runs_schedule ON schedule_runs(schedule_id, started_at DESC, id DESC);
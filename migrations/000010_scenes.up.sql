@@ -0,0 +1,10 @@
+-- Steps are kept as JSON: they are only ever read together with the scene.
+CREATE TABLE scenes (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    steps JSONB NOT NULL DEFAULT '[]',
+    stop_on_error BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS model_relationships;
+DROP TYPE IF EXISTS relationship_type;
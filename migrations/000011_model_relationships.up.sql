@@ -0,0 +1,16 @@
+CREATE TYPE relationship_type AS ENUM ('compatible_with', 'requires', 'replaces', 'accessory_of');
+
+-- Relationships go away with either of their models. compatible_with is
+-- symmetric and stored once, with the smaller model ID as the source.
+CREATE TABLE model_relationships (
+    id UUID PRIMARY KEY,
+    source_model_id UUID NOT NULL REFERENCES smart_models(id) ON DELETE CASCADE,
+    target_model_id UUID NOT NULL REFERENCES smart_models(id) ON DELETE CASCADE,
+    type relationship_type NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (source_model_id <> target_model_id)
+);
+
+-- The graph queries walk the edges of one type from either end.
+CREATE UNIQUE INDEX uq_model_relationships_edge ON model_relationships(type, source_model_id, target_model_id);
+CREATE INDEX idx_model_relationships_target ON model_relationships(type, target_model_id);
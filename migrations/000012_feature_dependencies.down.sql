@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS feature_dependencies;
+DROP TYPE IF EXISTS feature_dependency_kind;
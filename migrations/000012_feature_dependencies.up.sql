@@ -0,0 +1,13 @@
+CREATE TYPE feature_dependency_kind AS ENUM ('requires', 'conflicts');
+
+-- A feature declares which other features of its model it requires or
+-- conflicts with. Declarations go away with either feature.
+CREATE TABLE feature_dependencies (
+    feature_id UUID NOT NULL REFERENCES smart_features(id) ON DELETE CASCADE,
+    depends_on_id UUID NOT NULL REFERENCES smart_features(id) ON DELETE CASCADE,
+    kind feature_dependency_kind NOT NULL,
+    PRIMARY KEY (feature_id, depends_on_id),
+    CHECK (feature_id <> depends_on_id)
+);
+
+CREATE INDEX idx_feature_dependencies_depends_on ON feature_dependencies(depends_on_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_outbox_events_pending;
+CREATE INDEX idx_outbox_events_pending ON outbox_events(sequence) WHERE published_at IS NULL;
+
+ALTER TABLE outbox_events
+    DROP COLUMN IF EXISTS claimed_until,
+    DROP COLUMN IF EXISTS dead_at;
@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL migrations into the binary, so they are
+// found wherever it runs. The Postgres set is at the root and the SQLite set
+// under sqlite/.
+package migrations
+
+import "embed"
+
+//go:embed *.sql sqlite/*.sql
+var Files embed.FS
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS smart_features;
+DROP TABLE IF EXISTS smart_models;
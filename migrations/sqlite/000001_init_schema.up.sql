@@ -0,0 +1,31 @@
+-- SQLite has no enum types; CHECK constraints restrict the same values as
+-- the Postgres model_type, model_category and protocol_type enums. UUIDs and
+-- timestamps are TEXT, JSON columns hold JSON text.
+CREATE TABLE smart_models (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    type TEXT NOT NULL CHECK (type IN ('device', 'service')),
+    category TEXT NOT NULL CHECK (category IN ('wearable', 'camera', 'weather', 'entertainment')),
+    manufacturer TEXT,
+    model_number TEXT,
+    metadata TEXT CHECK (metadata IS NULL OR json_valid(metadata)),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE smart_features (
+    id TEXT PRIMARY KEY,
+    model_id TEXT NOT NULL REFERENCES smart_models(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    protocol TEXT NOT NULL CHECK (protocol IN ('rest', 'grpc', 'mqtt', 'websocket')),
+    interface_path TEXT NOT NULL,
+    parameters TEXT CHECK (parameters IS NULL OR json_valid(parameters)),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_smart_models_type_category ON smart_models(type, category);
+CREATE INDEX idx_smart_models_created_at ON smart_models(created_at, id);
+CREATE INDEX idx_smart_features_model_id ON smart_features(model_id, created_at, id);
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS smart_features_catalog_delete;
+DROP TRIGGER IF EXISTS smart_features_catalog_update;
+DROP TRIGGER IF EXISTS smart_features_catalog_insert;
+DROP TRIGGER IF EXISTS smart_models_catalog_delete;
+DROP TRIGGER IF EXISTS smart_models_catalog_update;
+DROP TRIGGER IF EXISTS smart_models_catalog_insert;
+DROP TABLE IF EXISTS catalog_changes;
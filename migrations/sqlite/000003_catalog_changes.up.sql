@@ -0,0 +1,77 @@
+CREATE TABLE catalog_changes (
+    sequence INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    model_id TEXT NOT NULL,
+    old_row TEXT,
+    new_row TEXT,
+    changed_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f', 'now') || '000000Z')
+);
+
+CREATE INDEX idx_catalog_changes_changed_at ON catalog_changes(changed_at);
+
+-- The triggers record every row change of smart_models and smart_features,
+-- like record_catalog_change() in the Postgres schema. Rows are stored as
+-- JSON objects with the column names as keys.
+CREATE TRIGGER smart_models_catalog_insert AFTER INSERT ON smart_models
+BEGIN
+    INSERT INTO catalog_changes (entity, operation, entity_id, model_id, new_row)
+    VALUES ('smart_model', 'created', NEW.id, NEW.id, json_object(
+        'id', NEW.id, 'name', NEW.name, 'description', NEW.description, 'type', NEW.type,
+        'category', NEW.category, 'manufacturer', NEW.manufacturer, 'model_number', NEW.model_number,
+        'metadata', json(NEW.metadata), 'created_at', NEW.created_at, 'updated_at', NEW.updated_at));
+END;
+
+CREATE TRIGGER smart_models_catalog_update AFTER UPDATE ON smart_models
+BEGIN
+    INSERT INTO catalog_changes (entity, operation, entity_id, model_id, old_row, new_row)
+    VALUES ('smart_model', 'updated', NEW.id, NEW.id, json_object(
+        'id', OLD.id, 'name', OLD.name, 'description', OLD.description, 'type', OLD.type,
+        'category', OLD.category, 'manufacturer', OLD.manufacturer, 'model_number', OLD.model_number,
+        'metadata', json(OLD.metadata), 'created_at', OLD.created_at, 'updated_at', OLD.updated_at
+    ), json_object(
+        'id', NEW.id, 'name', NEW.name, 'description', NEW.description, 'type', NEW.type,
+        'category', NEW.category, 'manufacturer', NEW.manufacturer, 'model_number', NEW.model_number,
+        'metadata', json(NEW.metadata), 'created_at', NEW.created_at, 'updated_at', NEW.updated_at));
+END;
+
+CREATE TRIGGER smart_models_catalog_delete AFTER DELETE ON smart_models
+BEGIN
+    INSERT INTO catalog_changes (entity, operation, entity_id, model_id, old_row)
+    VALUES ('smart_model', 'deleted', OLD.id, OLD.id, json_object(
+        'id', OLD.id, 'name', OLD.name, 'description', OLD.description, 'type', OLD.type,
+        'category', OLD.category, 'manufacturer', OLD.manufacturer, 'model_number', OLD.model_number,
+        'metadata', json(OLD.metadata), 'created_at', OLD.created_at, 'updated_at', OLD.updated_at));
+END;
+
+CREATE TRIGGER smart_features_catalog_insert AFTER INSERT ON smart_features
+BEGIN
+    INSERT INTO catalog_changes (entity, operation, entity_id, model_id, new_row)
+    VALUES ('smart_feature', 'created', NEW.id, NEW.model_id, json_object(
+        'id', NEW.id, 'model_id', NEW.model_id, 'name', NEW.name, 'description', NEW.description,
+        'protocol', NEW.protocol, 'interface_path', NEW.interface_path,
+        'parameters', json(NEW.parameters), 'created_at', NEW.created_at, 'updated_at', NEW.updated_at));
+END;
+
+CREATE TRIGGER smart_features_catalog_update AFTER UPDATE ON smart_features
+BEGIN
+    INSERT INTO catalog_changes (entity, operation, entity_id, model_id, old_row, new_row)
+    VALUES ('smart_feature', 'updated', NEW.id, NEW.model_id, json_object(
+        'id', OLD.id, 'model_id', OLD.model_id, 'name', OLD.name, 'description', OLD.description,
+        'protocol', OLD.protocol, 'interface_path', OLD.interface_path,
+        'parameters', json(OLD.parameters), 'created_at', OLD.created_at, 'updated_at', OLD.updated_at
+    ), json_object(
+        'id', NEW.id, 'model_id', NEW.model_id, 'name', NEW.name, 'description', NEW.description,
+        'protocol', NEW.protocol, 'interface_path', NEW.interface_path,
+        'parameters', json(NEW.parameters), 'created_at', NEW.created_at, 'updated_at', NEW.updated_at));
+END;
+
+CREATE TRIGGER smart_features_catalog_delete AFTER DELETE ON smart_features
+BEGIN
+    INSERT INTO catalog_changes (entity, operation, entity_id, model_id, old_row)
+    VALUES ('smart_feature', 'deleted', OLD.id, OLD.model_id, json_object(
+        'id', OLD.id, 'model_id', OLD.model_id, 'name', OLD.name, 'description', OLD.description,
+        'protocol', OLD.protocol, 'interface_path', OLD.interface_path,
+        'parameters', json(OLD.parameters), 'created_at', OLD.created_at, 'updated_at', OLD.updated_at));
+END;
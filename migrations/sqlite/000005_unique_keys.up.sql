@@ -0,0 +1,10 @@
+-- A manufacturer's model number identifies one model, and a feature's name
+-- and interface path are unique within its model. Both keys compare
+-- case-insensitively. Models without a model number are not constrained.
+-- Existing duplicates have to be resolved before this migration can run.
+CREATE UNIQUE INDEX uq_smart_models_model_number
+    ON smart_models (lower(manufacturer), lower(model_number))
+    WHERE model_number <> '';
+
+CREATE UNIQUE INDEX uq_smart_features_name_path
+    ON smart_features (model_id, lower(name), lower(interface_path));
@@ -0,0 +1,17 @@
+-- recorded_at is Unix nanoseconds rather than text like the other
+-- timestamps, so that readings can be bucketed with integer arithmetic.
+CREATE TABLE telemetry_readings (
+    device_id TEXT NOT NULL,
+    feature_id TEXT NOT NULL,
+    recorded_at INTEGER NOT NULL,
+    value REAL NOT NULL,
+    PRIMARY KEY (feature_id, device_id, recorded_at)
+) WITHOUT ROWID;
+
+CREATE INDEX idx_telemetry_readings_feature_time ON telemetry_readings(feature_id, recorded_at);
+
+CREATE TABLE telemetry_retention_policies (
+    feature_id TEXT PRIMARY KEY REFERENCES smart_features(id) ON DELETE CASCADE,
+    retention_seconds INTEGER NOT NULL CHECK (retention_seconds > 0),
+    updated_at TEXT NOT NULL
+);
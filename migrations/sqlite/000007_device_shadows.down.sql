@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS device_shadow_deltas;
+DROP TABLE IF EXISTS device_shadows;
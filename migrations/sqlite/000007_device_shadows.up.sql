@@ -0,0 +1,18 @@
+CREATE TABLE device_shadows (
+    device_id TEXT PRIMARY KEY,
+    desired TEXT NOT NULL DEFAULT '{}',
+    reported TEXT NOT NULL DEFAULT '{}',
+    version INTEGER NOT NULL CHECK (version > 0),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE device_shadow_deltas (
+    sequence INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    delta TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_device_shadow_deltas_created_at ON device_shadow_deltas(created_at);
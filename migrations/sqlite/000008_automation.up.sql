@@ -0,0 +1,32 @@
+-- Triggers and actions are kept as JSON: their shape depends on the trigger
+-- type and they are only ever read together with the rule.
+CREATE TABLE automation_rules (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    enabled INTEGER NOT NULL DEFAULT 1 CHECK (enabled IN (0, 1)),
+    trigger TEXT NOT NULL CHECK (json_valid(trigger)),
+    condition TEXT NOT NULL DEFAULT '',
+    actions TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(actions)),
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    cooldown_seconds INTEGER NOT NULL DEFAULT 0 CHECK (cooldown_seconds >= 0),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE automation_executions (
+    id TEXT PRIMARY KEY,
+    rule_id TEXT NOT NULL REFERENCES automation_rules(id) ON DELETE CASCADE,
+    trigger TEXT NOT NULL DEFAULT '{}' CHECK (json_valid(trigger)),
+    dedup_key TEXT,
+    status TEXT NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    error TEXT,
+    action_results TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(action_results)),
+    started_at TEXT NOT NULL,
+    finished_at TEXT
+);
+
+-- Replicas claim a schedule tick by inserting its execution first.
+CREATE UNIQUE INDEX uq_automation_executions_dedup_key ON automation_executions(dedup_key) WHERE dedup_key IS NOT NULL;
+CREATE INDEX idx_automation_executions_started ON automation_executions(started_at DESC, id DESC);
+CREATE INDEX idx_automation_executions_rule ON automation_executions(rule_id, started_at DESC, id DESC);
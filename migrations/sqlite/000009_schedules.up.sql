@@ -0,0 +1,33 @@
+-- A schedule has either a cron expression or an interval.
+CREATE TABLE schedules (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    cron TEXT,
+    interval_seconds INTEGER CHECK (interval_seconds > 0),
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    device_id TEXT NOT NULL,
+    feature_id TEXT NOT NULL,
+    parameters TEXT NOT NULL DEFAULT '{}' CHECK (json_valid(parameters)),
+    paused INTEGER NOT NULL DEFAULT 0 CHECK (paused IN (0, 1)),
+    next_run_at TEXT NOT NULL,
+    last_run_at TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    CHECK ((cron IS NULL) <> (interval_seconds IS NULL))
+);
+
+CREATE INDEX idx_schedules_due ON schedules(next_run_at, id) WHERE paused = 0;
+
+CREATE TABLE schedule_runs (
+    id TEXT PRIMARY KEY,
+    schedule_id TEXT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    scheduled_at TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    error TEXT,
+    started_at TEXT NOT NULL,
+    finished_at TEXT
+);
+
+CREATE INDEX idx_schedule_runs_started ON schedule_runs(started_at DESC, id DESC);
+CREATE INDEX idx_schedule_runs_schedule ON schedule_runs(schedule_id, started_at DESC, id DESC);
@@ -0,0 +1,10 @@
+-- Steps are kept as JSON: they are only ever read together with the scene.
+CREATE TABLE scenes (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    steps TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(steps)),
+    stop_on_error INTEGER NOT NULL DEFAULT 0 CHECK (stop_on_error IN (0, 1)),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
@@ -0,0 +1,14 @@
+-- Relationships go away with either of their models. compatible_with is
+-- symmetric and stored once, with the smaller model ID as the source.
+CREATE TABLE model_relationships (
+    id TEXT PRIMARY KEY,
+    source_model_id TEXT NOT NULL REFERENCES smart_models(id) ON DELETE CASCADE,
+    target_model_id TEXT NOT NULL REFERENCES smart_models(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('compatible_with', 'requires', 'replaces', 'accessory_of')),
+    created_at TEXT NOT NULL,
+    CHECK (source_model_id <> target_model_id)
+);
+
+-- The graph queries walk the edges of one type from either end.
+CREATE UNIQUE INDEX uq_model_relationships_edge ON model_relationships(type, source_model_id, target_model_id);
+CREATE INDEX idx_model_relationships_target ON model_relationships(type, target_model_id);
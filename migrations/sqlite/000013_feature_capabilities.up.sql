@@ -0,0 +1,9 @@
+-- A feature implements at most one standard capability. Capability names
+-- come from the library in the application, so they are plain text here.
+CREATE TABLE feature_capabilities (
+    feature_id TEXT PRIMARY KEY REFERENCES smart_features(id) ON DELETE CASCADE,
+    capability TEXT NOT NULL,
+    parameter_mapping TEXT NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_feature_capabilities_capability ON feature_capabilities(capability);
@@ -0,0 +1,7 @@
+-- A relay claims events until claimed_until while it publishes them outside
+-- of any transaction. An event that keeps failing is given up at dead_at.
+ALTER TABLE outbox_events ADD COLUMN claimed_until TEXT;
+ALTER TABLE outbox_events ADD COLUMN dead_at TEXT;
+
+DROP INDEX IF EXISTS idx_outbox_events_pending;
+CREATE INDEX idx_outbox_events_pending ON outbox_events(sequence) WHERE published_at IS NULL AND dead_at IS NULL;
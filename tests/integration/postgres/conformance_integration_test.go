@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"smart-hub/internal/infrastructure/database/postgres"
+	"smart-hub/internal/infrastructure/database/repotest"
+	"testing"
+)
+
+func TestRepositoryConformanceIntegration(t *testing.T) {
+	db := SetupTestDB(t)
+	defer CleanupTestDB(t, db)
+
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		TruncateTestDB(t, db)
+		return repotest.Repositories{
+			Models:        postgres.NewPGSmartModelRepository(db),
+			Features:      postgres.NewPGSmartFeatureRepository(db),
+			Telemetry:     postgres.NewPGTelemetryRepository(db),
+			Shadows:       postgres.NewPGShadowRepository(db),
+			Automations:   postgres.NewPGAutomationRepository(db),
+			Schedules:     postgres.NewPGScheduleRepository(db),
+			Scenes:        postgres.NewPGSceneRepository(db),
+			Relationships: postgres.NewPGRelationshipRepository(db),
+			Dependencies:  postgres.NewPGFeatureDependencyRepository(db),
+			Capabilities:  postgres.NewPGFeatureCapabilityRepository(db),
+			UnitOfWork:    postgres.NewPGUnitOfWork(db),
+		}
+	})
+}
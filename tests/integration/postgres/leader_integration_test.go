@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"smart-hub/internal/infrastructure/database/postgres"
+	"testing"
+	"time"
+)
+
+func TestPGLeaderElector_Integration(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	dsn := DefaultTestConfig().GetDSN()
+
+	leading := make(chan string, 2)
+	lead := func(ctx context.Context, name string) <-chan error {
+		result := make(chan error, 1)
+		go func() {
+			result <- postgres.NewPGSchedulerElector(dsn).Lead(ctx, func(leaderCtx context.Context) {
+				leading <- name
+				<-leaderCtx.Done()
+			})
+		}()
+		return result
+	}
+	receive := func(timeout time.Duration) string {
+		select {
+		case name := <-leading:
+			return name
+		case <-time.After(timeout):
+			return ""
+		}
+	}
+
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	defer stopFirst()
+	first := lead(firstCtx, "first")
+	assert.Equal(t, "first", receive(5*time.Second))
+
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	second := lead(secondCtx, "second")
+	assert.Empty(t, receive(time.Second), "only one replica leads at a time")
+
+	stopFirst()
+	assert.ErrorIs(t, <-first, context.Canceled)
+	assert.Equal(t, "second", receive(10*time.Second), "the other replica takes over")
+
+	stopSecond()
+	assert.ErrorIs(t, <-second, context.Canceled)
+}
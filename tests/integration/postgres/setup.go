@@ -4,8 +4,6 @@ import (
 	"context"
 	"github.com/stretchr/testify/require"
 	"os"
-	"path/filepath"
-	"runtime"
 	"smart-hub/internal/common/database"
 	"smart-hub/internal/common/database/migrations"
 	"testing"
@@ -30,12 +28,6 @@ func DefaultTestConfig() TestConfig {
 	}
 }
 
-func ProjectRoot() string {
-	_, b, _, _ := runtime.Caller(0)
-	projectRoot := filepath.Join(filepath.Dir(b), "../../..")
-	return projectRoot
-}
-
 func (c TestConfig) GetDSN() string {
 	return "postgresql://" + c.DBUser + ":" + c.DBPassword + "@" + c.DBHost + ":" + c.DBPort + "/" + c.DBName + "?sslmode=disable"
 }
@@ -58,20 +50,22 @@ func SetupTestDB(t *testing.T) database.Database {
 	}
 	require.NoError(t, err)
 
-	sourceUrl := "file://" + ProjectRoot() + "/migrations"
-
-	err = migrations.RunMigrationsWithSourceUrl(config.GetDSN(), sourceUrl)
+	err = migrations.RunMigrations(context.Background(), config.GetDSN())
 	require.NoError(t, err)
 
 	return db
 }
 
 func CleanupTestDB(t *testing.T, db database.Database) {
-	_, err := db.GetPool().Exec(context.Background(), "TRUNCATE TABLE smart_models, smart_features CASCADE")
-	require.NoError(t, err)
+	TruncateTestDB(t, db)
 	db.Close()
 }
 
+func TruncateTestDB(t *testing.T, db database.Database) {
+	_, err := db.GetPool().Exec(context.Background(), "TRUNCATE TABLE smart_models, smart_features, outbox_events, catalog_changes, webhook_subscriptions, webhook_deliveries, telemetry_readings, telemetry_retention_policies, device_shadows, device_shadow_deltas, automation_rules, automation_executions, schedules, schedule_runs, scenes, model_relationships, feature_dependencies, feature_capabilities CASCADE")
+	require.NoError(t, err)
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
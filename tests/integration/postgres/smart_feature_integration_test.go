@@ -19,15 +19,20 @@ func TestSmartFeatureIntegration(t *testing.T) {
 	db := SetupTestDB(t)
 	defer CleanupTestDB(t, db)
 
+	uow := postgres.NewPGUnitOfWork(db)
+	outbox := postgres.NewPGOutboxRepository(db)
+
 	modelRepo := postgres.NewPGSmartModelRepository(db)
-	modelSvc := service.NewSmartModelService(modelRepo)
+	featureRepo := postgres.NewPGSmartFeatureRepository(db)
+	dependencyRepo := postgres.NewPGFeatureDependencyRepository(db)
+
+	modelSvc := service.NewSmartModelService(modelRepo, featureRepo, uow, outbox)
 	modelMapper := mapper.NewSmartModelMapper()
-	modelHandler := handler.NewSmartModelHandler(modelSvc, modelMapper)
+	modelHandler := handler.NewSmartModelHandler(modelSvc, nil, modelMapper)
 
-	featureRepo := postgres.NewPGSmartFeatureRepository(db)
-	featureSvc := service.NewSmartFeatureService(featureRepo)
+	featureSvc := service.NewSmartFeatureService(featureRepo, modelRepo, uow, outbox, dependencyRepo)
 	featureMapper := mapper.NewSmartFeatureMapper()
-	featureHandler := handler.NewSmartFeatureHandler(featureSvc, featureMapper)
+	featureHandler := handler.NewSmartFeatureHandler(featureSvc, nil, featureMapper)
 
 	ctx := context.Background()
 
@@ -5,13 +5,18 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 	pb "smart-hub/gen/proto/smart_model/v1"
 	"smart-hub/internal/application/service"
+	"smart-hub/internal/domain/models"
 	"smart-hub/internal/infrastructure/database/postgres"
+	"smart-hub/internal/infrastructure/messaging"
 	"smart-hub/internal/presentation/grpc/handler"
 	"smart-hub/internal/presentation/grpc/mapper"
 	"testing"
+	"time"
 )
 
 func TestSmartModelIntegration(t *testing.T) {
@@ -19,9 +24,11 @@ func TestSmartModelIntegration(t *testing.T) {
 	defer CleanupTestDB(t, db)
 
 	repo := postgres.NewPGSmartModelRepository(db)
-	svc := service.NewSmartModelService(repo)
+	uow := postgres.NewPGUnitOfWork(db)
+	outbox := postgres.NewPGOutboxRepository(db)
+	svc := service.NewSmartModelService(repo, postgres.NewPGSmartFeatureRepository(db), uow, outbox)
 	modelMapper := mapper.NewSmartModelMapper()
-	handler := handler.NewSmartModelHandler(svc, modelMapper)
+	handler := handler.NewSmartModelHandler(svc, nil, modelMapper)
 
 	ctx := context.Background()
 
@@ -92,6 +99,156 @@ func TestSmartModelIntegration(t *testing.T) {
 
 		_, err = handler.GetSmartModel(ctx, getReq)
 		require.Error(t, err)
+
+		var eventTypes []string
+		rows, err := db.GetPool().Query(ctx, `SELECT event_type FROM outbox_events WHERE aggregate_id = $1 ORDER BY sequence`, modelID)
+		require.NoError(t, err)
+		for rows.Next() {
+			var eventType string
+			require.NoError(t, rows.Scan(&eventType))
+			eventTypes = append(eventTypes, eventType)
+		}
+		assert.Equal(t, []string{"model.created", "model.updated", "model.deleted"}, eventTypes)
+	})
+
+	t.Run("Outbox Relay", func(t *testing.T) {
+		publisher := messaging.NewChannelPublisher()
+		defer publisher.Close()
+		events, unsubscribe := publisher.Subscribe(100)
+		defer unsubscribe()
+
+		relay := service.NewOutboxRelay(uow, outbox, publisher, time.Second, 100, 10, time.Minute)
+		published, err := relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, published)
+		assert.Len(t, events, 3)
+
+		published, err = relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, published)
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		changes := postgres.NewPGCatalogChangeRepository(db)
+		listener := postgres.NewPGChangeListener(DefaultTestConfig().GetDSN())
+		watcher := service.NewCatalogWatchService(changes, listener, repo, postgres.NewPGSmartFeatureRepository(db), 16, time.Second, time.Hour)
+
+		watchCtx, stopWatcher := context.WithCancel(ctx)
+		defer stopWatcher()
+		go watcher.Run(watchCtx)
+
+		camera := models.CameraCategory
+		events := make(chan *models.WatchEvent, 16)
+		go func() {
+			_ = watcher.WatchModels(watchCtx, models.ModelWatchFilter{Category: &camera}, "", func(event *models.WatchEvent) error {
+				events <- event
+				return nil
+			})
+		}()
+
+		receive := func() *models.WatchEvent {
+			select {
+			case event := <-events:
+				return event
+			case <-time.After(5 * time.Second):
+				t.Fatal("no watch event received")
+				return nil
+			}
+		}
+
+		assert.Equal(t, models.ChangeSynced, receive().Operation)
+
+		created, err := svc.Create(ctx, &models.SmartModel{
+			ID:          uuid.New(),
+			Name:        "Watched Camera",
+			Description: "Watched Camera",
+			Type:        models.DeviceType,
+			Category:    models.CameraCategory,
+			Metadata:    map[string]interface{}{},
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		})
+		require.NoError(t, err)
+
+		event := receive()
+		assert.Equal(t, models.ChangeCreated, event.Operation)
+		assert.Equal(t, created.ID, event.Model.ID)
+		assert.NotEmpty(t, event.ResumeToken)
+	})
+
+	t.Run("Create With Features", func(t *testing.T) {
+		createResp, err := handler.CreateSmartModel(ctx, &pb.CreateSmartModelRequest{
+			Model: &pb.CreateSmartModelInput{
+				Name:        "Model With Features",
+				Description: "Created with its features",
+				Type:        pb.ModelType_DEVICE,
+				Category:    pb.ModelCategory_CAMERA,
+				Features: []*pb.CreateSmartFeatureInput{
+					{Name: "Power", Description: "Power switch", Protocol: pb.ProtocolType_REST, InterfacePath: "/power"},
+					{Name: "Stream", Description: "Video stream", Protocol: pb.ProtocolType_WEBSOCKET, InterfacePath: "/stream"},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, createResp.Model.Features, 2)
+		assert.Equal(t, createResp.Model.Id, createResp.Model.Features[0].ModelId)
+		assert.Equal(t, "Stream", createResp.Model.Features[1].Name)
+
+		features, err := postgres.NewPGSmartFeatureRepository(db).GetWithModelID(ctx, createResp.Model.Id)
+		require.NoError(t, err)
+		assert.Len(t, features, 2)
+	})
+
+	t.Run("Create With Failing Feature Rolls Back", func(t *testing.T) {
+		featureID := uuid.New()
+		model := &models.SmartModel{
+			ID:          uuid.New(),
+			Name:        "Half Configured",
+			Description: "Must not be left behind",
+			Type:        models.DeviceType,
+			Category:    models.CameraCategory,
+			Features: []*models.SmartFeature{
+				{ID: featureID, Name: "Power", Description: "Power switch", Protocol: models.RestProtocol, InterfacePath: "/power"},
+				{ID: featureID, Name: "Duplicate", Description: "Same ID", Protocol: models.RestProtocol, InterfacePath: "/dup"},
+			},
+		}
+
+		_, err := svc.Create(ctx, model)
+		require.Error(t, err)
+
+		_, err = repo.GetByID(ctx, model.ID.String())
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("Duplicate Model Number", func(t *testing.T) {
+		createResp, err := handler.CreateSmartModel(ctx, &pb.CreateSmartModelRequest{
+			Model: &pb.CreateSmartModelInput{
+				Name:         "Unique Thermostat",
+				Description:  "First of its model number",
+				Manufacturer: "Acme",
+				ModelNumber:  "UNIQ-1",
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = handler.CreateSmartModel(ctx, &pb.CreateSmartModelRequest{
+			Model: &pb.CreateSmartModelInput{
+				Name:         "Copied Thermostat",
+				Description:  "Same model number in another case",
+				Manufacturer: "ACME",
+				ModelNumber:  "uniq-1",
+			},
+		})
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.AlreadyExists, st.Code())
+		assert.Contains(t, st.Message(), createResp.Model.Id)
+
+		getResp, err := handler.GetSmartModelByModelNumber(ctx, &pb.GetSmartModelByModelNumberRequest{
+			Manufacturer: "acme",
+			ModelNumber:  "Uniq-1",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, createResp.Model.Id, getResp.Model.Id)
 	})
 
 	t.Run("Error Cases", func(t *testing.T) {
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"smart-hub/internal/application/service"
+	"smart-hub/internal/domain/models"
+	"smart-hub/internal/infrastructure/database/postgres"
+	"smart-hub/internal/infrastructure/messaging"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookIntegration(t *testing.T) {
+	db := SetupTestDB(t)
+	defer CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	repo := postgres.NewPGWebhookRepository(db)
+	svc := service.NewWebhookService(repo)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get(messaging.WebhookSignatureHeader))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscription, err := svc.CreateSubscription(ctx, &models.WebhookSubscription{
+		URL:        server.URL,
+		EventTypes: []models.EventType{models.ModelCreatedEvent},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, subscription.Secret)
+
+	dispatcher := service.NewWebhookDispatcher(repo, messaging.NewHTTPWebhookSender(time.Second), time.Second, 10, time.Second, 3, time.Millisecond, time.Millisecond)
+
+	event, err := models.NewDomainEvent(models.SmartModelAggregate, uuid.New(), models.ModelCreatedEvent, map[string]string{"name": "Test"})
+	require.NoError(t, err)
+	require.NoError(t, dispatcher.Publish(ctx, event))
+	// Publishing the same event again must not queue a second delivery.
+	require.NoError(t, dispatcher.Publish(ctx, event))
+
+	claimed, err := dispatcher.DispatchOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+
+	deliveries, err := svc.ListDeliveries(ctx, models.WebhookDeliveryFilter{SubscriptionID: &subscription.ID})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, models.DeliveryFailed, deliveries[0].Status)
+	assert.Equal(t, http.StatusInternalServerError, deliveries[0].LastStatusCode)
+
+	time.Sleep(10 * time.Millisecond)
+	claimed, err = dispatcher.DispatchOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+
+	delivery, err := repo.GetDelivery(ctx, deliveries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.NotNil(t, delivery.DeliveredAt)
+
+	redelivered, err := svc.Redeliver(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, redelivered.Status)
+}